@@ -66,7 +66,7 @@ func main() {
 	if cfg.Telegram.Enabled && telegramClient != nil {
 		if cfg.Telegram.Webhook.Enabled {
 			// Webhook 模式：自动设置 webhook
-			if err := telegramClient.SetWebhook(cfg.Telegram.Webhook.URL); err != nil {
+			if err := telegramClient.SetWebhook(cfg.Telegram.Webhook.URL, cfg.Telegram.Webhook.SecretToken); err != nil {
 				logger.Error("Failed to set telegram webhook", "error", err)
 			} else {
 				logger.Info("Telegram webhook mode enabled")
@@ -107,5 +107,8 @@ func main() {
 		logger.Info("Telegram polling stopped")
 	}
 
+	// 停止后台分批提交，未提交的文件保留在目录下载断点中
+	container.Shutdown()
+
 	logger.Info("Server stopped")
 }
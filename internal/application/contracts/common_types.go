@@ -108,6 +108,7 @@ type SystemHealth struct {
 	Timestamp  time.Time         `json:"timestamp"`
 	Uptime     time.Duration     `json:"uptime"`
 	Version    string            `json:"version"`
+	ReadOnly   bool              `json:"read_only"` // 是否处于只读模式（破坏性操作已禁用）
 }
 
 // Metrics 系统指标
@@ -2,11 +2,34 @@ package contracts
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
 	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
 )
 
+// ErrAria2Unavailable aria2 连接不可用（服务未运行或网络不通）
+var ErrAria2Unavailable = errors.New("aria2 当前不可用")
+
+// ErrAria2Unauthorized aria2 拒绝了配置的 RPC 密钥（aria2.token 与 aria2 的 --rpc-secret 不一致）
+var ErrAria2Unauthorized = errors.New("aria2 RPC 密钥错误")
+
+// ErrDownloadNotFound 下载任务不存在（GID 无效或结果已被清除）
+var ErrDownloadNotFound = errors.New("下载任务不存在")
+
+// ErrDownloadNotWaiting 任务不在等待队列中，无法调整队列位置
+var ErrDownloadNotWaiting = errors.New("任务不在等待队列中，下载中或已结束的任务无法调整位置")
+
+// ErrDownloadOutputMissing 已完成下载的本地文件不存在（已被移动、删除，或下载目录不在本机）
+var ErrDownloadOutputMissing = errors.New("下载文件不存在")
+
+// ErrBatchNotFound 批量下载记录不存在
+var ErrBatchNotFound = errors.New("批量下载记录不存在")
+
+// ErrBatchRetryExpired 批量下载已超过允许重试的期限
+var ErrBatchRetryExpired = errors.New("批量下载已超过重试期限")
+
 // DownloadRequest 下载请求统一参数
 type DownloadRequest struct {
 	URL          string                 `json:"url" validate:"required,url"`
@@ -16,6 +39,24 @@ type DownloadRequest struct {
 	VideoOnly    bool                   `json:"video_only,omitempty"`
 	AutoClassify bool                   `json:"auto_classify,omitempty"`
 	FileSize     int64                  `json:"file_size,omitempty"` // 文件大小，用于磁盘空间检查
+
+	// SourcePath Alist 源文件路径，用于下载完成后的后续处理，只能由服务端根据解析出的 Alist 文件设置
+	SourcePath string `json:"-"`
+	// DeleteAfterDownload 下载完成且校验大小一致后删除 Alist 源文件，仅 Telegram 管理员可开启，HTTP 接口不接受
+	DeleteAfterDownload bool `json:"-"`
+	// DownloadSubtitles 同时下载源目录中与视频同名的字幕文件（需要 SourcePath），字幕保存为与视频一致的文件名
+	DownloadSubtitles bool `json:"download_subtitles,omitempty"`
+	// Priority 批量下载的提交优先级，数值大的先提交（aria2 按提交顺序下载），相同优先级保持原顺序
+	Priority int `json:"priority,omitempty"`
+
+	// Headers 自定义请求头（如 Authorization），通过 aria2 的 header 选项传递
+	Headers map[string]string `json:"headers,omitempty"`
+	// Cookie 自定义 Cookie，例如 "a=1; b=2"
+	Cookie string `json:"cookie,omitempty"`
+	// MaxDownloadSpeed 单任务限速，例如 "500K"、"2M"（K/M/G），通过 aria2 的 max-download-limit 选项传递
+	MaxDownloadSpeed string `json:"max_download_speed,omitempty"`
+	// TaskID 创建该下载的定时任务ID，aria2 下载出错时记入任务的失败文件（仅内部使用）
+	TaskID string `json:"-"`
 }
 
 // DownloadResponse 下载响应统一格式
@@ -30,11 +71,107 @@ type DownloadResponse struct {
 	TotalSize     int64                       `json:"total_size"`
 	CompletedSize int64                       `json:"completed_size"`
 	ErrorMessage  string                      `json:"error_message,omitempty"`
+	ErrorCode     string                      `json:"error_code,omitempty"`  // aria2 错误码
+	DiskFull      bool                        `json:"disk_full,omitempty"`   // 因磁盘空间不足失败
+	SpeedLimit    int64                       `json:"speed_limit,omitempty"` // 单任务限速(B/s)，0 表示不限速；仅活动任务和任务详情返回
+	Subtitles     []string                    `json:"subtitles,omitempty"`   // 随视频一起创建下载任务的字幕文件名
 	CreatedAt     time.Time                   `json:"created_at"`
 	UpdatedAt     time.Time                   `json:"updated_at"`
 }
 
+// DownloadDetail 单个下载任务的完整信息，用于排查卡住或缓慢的下载
+type DownloadDetail struct {
+	DownloadResponse
+	Connections     int                  `json:"connections"`
+	UploadSpeed     int64                `json:"upload_speed"`
+	NumPieces       int                  `json:"num_pieces"`
+	PieceLength     int64                `json:"piece_length"`
+	CompletedPieces int                  `json:"completed_pieces"`
+	Files           []DownloadFileDetail `json:"files"`
+	BatchID         string               `json:"batch_id,omitempty"`   // 所属批量下载（批次中每个文件是独立的 aria2 任务）
+	BatchSize       int                  `json:"batch_size,omitempty"` // 批次中的文件数
+	BitTorrent      bool                 `json:"bittorrent"`           // BT 任务，连接数和分段设置不适用
+	// 任务的连接设置（aria2 max-connection-per-server 和 split），已结束或读取失败时为0
+	MaxConnectionPerServer int `json:"max_connection_per_server,omitempty"`
+	Split                  int `json:"split,omitempty"`
+}
+
+// DownloadFileDetail 下载任务中的单个文件
+type DownloadFileDetail struct {
+	Index           int      `json:"index"` // aria2 文件序号（从 1 开始），用于 select-file
+	Path            string   `json:"path"`
+	Length          int64    `json:"length"`
+	CompletedLength int64    `json:"completed_length"`
+	Selected        bool     `json:"selected"`
+	URIs            []string `json:"uris,omitempty"` // 正在使用的下载地址
+}
+
+// DownloadFileCancelResult 取消任务中单个文件的结果
+type DownloadFileCancelResult struct {
+	ID             string `json:"id"`
+	FilePath       string `json:"file_path"`
+	TaskStopped    bool   `json:"task_stopped"`       // 整个 aria2 任务已停止（单文件任务，或取消的是多文件任务中最后一个选中的文件）
+	RemainingFiles int    `json:"remaining_files"`    // 多文件任务（如种子）中仍选中的文件数
+	BatchID        string `json:"batch_id,omitempty"` // 所属批量下载，批次中其余文件继续下载
+}
+
+// 批量取消中单个任务的结果状态
+const (
+	CancelStatusCancelled = "cancelled" // 已取消
+	CancelStatusNotFound  = "not_found" // 任务不存在（GID 无效或已结束并被清理）
+	CancelStatusFailed    = "failed"    // 取消失败（如 aria2 拒绝或连接错误）
+)
+
+// BatchCancelRequest 批量取消下载请求
+type BatchCancelRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1"`
+}
+
+// BatchCancelItem 批量取消中单个任务的结果
+type BatchCancelItem struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // cancelled, not_found, failed
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchCancelResult 批量取消下载的结果，单个任务失败不影响其余任务
+type BatchCancelResult struct {
+	Total     int               `json:"total"`
+	Cancelled int               `json:"cancelled"`
+	NotFound  int               `json:"not_found"`
+	Failed    int               `json:"failed"`
+	Items     []BatchCancelItem `json:"items"`
+}
+
+// 队列位置调整方式（ChangePosition 的 how 参数，与 aria2.changePosition 一致）
+const (
+	PositionFromStart   = "POS_SET" // pos 相对队首，不能为负数
+	PositionFromCurrent = "POS_CUR" // pos 相对当前位置，可为负数
+	PositionFromEnd     = "POS_END" // pos 相对队尾，不能为正数
+)
+
+// PrioritizeResult "立即下载"（插队）的结果
+type PrioritizeResult struct {
+	ID        string             `json:"id"`
+	Filename  string             `json:"filename"`
+	Resumed   bool               `json:"resumed"`          // 任务原为暂停状态，已恢复
+	QueueFull bool               `json:"queue_full"`       // 同时下载数已满且未暂停其他任务，需等待一个任务结束
+	Paused    []DownloadResponse `json:"paused,omitempty"` // 为腾出下载槽位而暂停的任务
+}
+
+// BoostResult "加速"（提高任务连接数和分段数）的结果
+type BoostResult struct {
+	ID                string `json:"id"`
+	Filename          string `json:"filename"`
+	ConnectionsBefore int    `json:"connections_before"` // max-connection-per-server
+	ConnectionsAfter  int    `json:"connections_after"`
+	SplitBefore       int    `json:"split_before"`
+	SplitAfter        int    `json:"split_after"`
+	AtLimit           bool   `json:"at_limit"` // 已达到配置的上限，未做修改
+}
+
 // DownloadListRequest 下载列表查询参数
+// 列表按 下载中 → 等待中 → 已停止 排序，Offset/Limit 作用于合并后的列表，Limit<=0 时返回全部
 type DownloadListRequest struct {
 	Status    valueobjects.DownloadStatus `json:"status,omitempty"`
 	Limit     int                         `json:"limit,omitempty"`
@@ -46,9 +183,22 @@ type DownloadListRequest struct {
 // DownloadListResponse 下载列表响应
 type DownloadListResponse struct {
 	Downloads   []DownloadResponse     `json:"downloads"`
-	TotalCount  int                    `json:"total_count"`
+	TotalCount  int                    `json:"total_count"` // 分页前符合条件的任务总数
 	ActiveCount int                    `json:"active_count"`
+	PausedCount int                    `json:"paused_count"`
+	AllPaused   bool                   `json:"all_paused"` // 所有未完成的任务都已暂停
 	GlobalStats map[string]interface{} `json:"global_stats"`
+	// Categories 配置了同时下载数上限的分类的任务数（未配置 download.category_limits 时为空）
+	Categories []CategoryConcurrency `json:"categories,omitempty"`
+}
+
+// CategoryConcurrency 单个分类的并发情况
+type CategoryConcurrency struct {
+	Category string `json:"category"`
+	Limit    int    `json:"limit"`   // 同时下载数上限
+	Active   int    `json:"active"`  // 下载中
+	Waiting  int    `json:"waiting"` // 已放行、等待 aria2 空位
+	Held     int    `json:"held"`    // 超出上限被暂停，同分类有空位时自动恢复
 }
 
 // BatchDownloadRequest 批量下载请求
@@ -57,14 +207,35 @@ type BatchDownloadRequest struct {
 	Directory    string            `json:"directory,omitempty"`
 	VideoOnly    bool              `json:"video_only,omitempty"`
 	AutoClassify bool              `json:"auto_classify,omitempty"`
+
+	// DeleteAfterDownload 仅 Telegram 管理员可开启，HTTP 接口不接受
+	DeleteAfterDownload bool `json:"-"`
+
+	// Archive 全部文件下载完成后打包为一个 zip 文件（放在下载目录中），ArchiveRemoveOriginals 打包后删除原文件
+	Archive                bool `json:"archive,omitempty"`
+	ArchiveRemoveOriginals bool `json:"archive_remove_originals,omitempty"`
+
+	// CheckpointID 目录下载断点ID，每个文件提交成功后记录到断点
+	CheckpointID string `json:"checkpoint_id,omitempty"`
+
+	// ChunkSize 文件数超过该值时分批提交，下载队列空出后在后台提交下一批，0表示一次全部提交
+	ChunkSize int `json:"chunk_size,omitempty"`
+	// SourceDir 来源目录，用于分批提交的进度通知
+	SourceDir string `json:"source_dir,omitempty"`
+	// NotifyChatID 分批提交进度通知发送到的聊天（发起下载的聊天），为0时作为系统事件通知
+	NotifyChatID int64 `json:"-"`
 }
 
 // BatchDownloadResponse 批量下载响应
 type BatchDownloadResponse struct {
+	BatchID      string           `json:"batch_id,omitempty"` // 批量记录ID，用于重试全部失败的文件
 	SuccessCount int              `json:"success_count"`
 	FailureCount int              `json:"failure_count"`
 	Results      []DownloadResult `json:"results"`
 	Summary      DownloadSummary  `json:"summary"`
+	ResumedCount int              `json:"resumed_count,omitempty"` // 从断点继续时跳过的已提交文件数
+	PendingCount int              `json:"pending_count,omitempty"` // 分批提交时尚未提交、将在后台自动提交的文件数
+	ChunkCount   int              `json:"chunk_count,omitempty"`   // 分批提交的总批数
 }
 
 // DownloadResult 单个下载结果
@@ -83,27 +254,178 @@ type DownloadSummary struct {
 	MovieFiles int   `json:"movie_files"`
 	TVFiles    int   `json:"tv_files"`
 	OtherFiles int   `json:"other_files"`
+	// 音乐和文档分类需在配置中启用，未启用时计入 OtherFiles
+	MusicFiles    int `json:"music_files,omitempty"`
+	DocumentFiles int `json:"document_files,omitempty"`
 }
 
+// BandwidthStats 带宽统计（基于 aria2 全局下载速度采样）
+type BandwidthStats struct {
+	Window      time.Duration `json:"window"`       // 统计时间窗口
+	Interval    time.Duration `json:"interval"`     // 采样间隔
+	SampleCount int           `json:"sample_count"` // 窗口内样本数
+	AvgSpeed    int64         `json:"avg_speed"`    // 平均速度(B/s)
+	MinSpeed    int64         `json:"min_speed"`    // 最低速度(B/s)
+	PeakSpeed   int64         `json:"peak_speed"`   // 峰值速度(B/s)
+	TotalBytes  int64         `json:"total_bytes"`  // 估算下载总量
+	Buckets     []int64       `json:"buckets"`      // 按时间分桶的平均速度，用于绘制趋势
+}
+
+// MoveDownloadResult 移动已完成下载的结果
+type MoveDownloadResult struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// 已下载文件查找来源
+const (
+	FindSourceHistory = "history" // 下载历史记录
+	FindSourceDisk    = "disk"    // 扫描下载目录
+)
+
+// DownloadedFileMatch 按关键词找到的已下载本地文件
+type DownloadedFileMatch struct {
+	Filename string    `json:"filename"`
+	Path     string    `json:"path"`               // 本地完整路径
+	Category string    `json:"category,omitempty"` // 所在分类目录对应的分类（tv/movie/variety/video/music/document/other），无法判断时为空
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Source   string    `json:"source"` // FindSourceHistory 或 FindSourceDisk
+}
+
+// DownloadHistoryStats 一段时间内完成的下载统计（来自下载历史）
+type DownloadHistoryStats struct {
+	Since      time.Time               `json:"since"`
+	TotalFiles int                     `json:"total_files"`
+	TotalSize  int64                   `json:"total_size"`
+	Categories []CategoryDownloadStats `json:"categories"` // 按总大小降序
+}
+
+// CategoryDownloadStats 单个分类的下载统计，无法判断分类的下载 Category 为空
+type CategoryDownloadStats struct {
+	Category string `json:"category"`
+	Files    int    `json:"files"`
+	Size     int64  `json:"size"`
+}
+
+// FindDownloadedFilesResult 查找已下载文件的结果
+type FindDownloadedFilesResult struct {
+	Keyword      string                `json:"keyword"`
+	Matches      []DownloadedFileMatch `json:"matches"`
+	DiskSearched bool                  `json:"disk_searched"` // 下载历史中没有匹配时扫描了下载目录
+	Truncated    bool                  `json:"truncated"`     // 匹配数或扫描条目数达到上限，结果可能不完整
+}
+
+// BatchRetryResult 批量重试失败文件的结果
+type BatchRetryResult struct {
+	BatchID      string           `json:"batch_id"`
+	FailedCount  int              `json:"failed_count"`  // 批次中失败的文件数
+	SkippedCount int              `json:"skipped_count"` // 已有成功或进行中下载而跳过的文件数
+	SuccessCount int              `json:"success_count"` // 重新提交成功的文件数
+	FailureCount int              `json:"failure_count"` // 重新提交仍失败的文件数
+	Results      []DownloadResult `json:"results"`
+}
+
+// DownloadEventType 下载事件类型
+type DownloadEventType string
+
+const (
+	DownloadEventCreated   DownloadEventType = "created"
+	DownloadEventCompleted DownloadEventType = "completed"
+	DownloadEventFailed    DownloadEventType = "failed"
+	DownloadEventRemoved   DownloadEventType = "removed"
+)
+
+// DownloadEvent 下载状态变化事件（任务创建时以及下载监控器发现 aria2 任务结束时发出）
+type DownloadEvent struct {
+	Type     DownloadEventType `json:"type"`
+	Download DownloadResponse  `json:"download"`
+	// Request 创建下载时的原始请求，非本进程创建的任务为 nil
+	Request *DownloadRequest `json:"request,omitempty"`
+}
+
+// DownloadEventListener 下载事件监听器
+type DownloadEventListener func(ctx context.Context, event DownloadEvent)
+
 // DownloadService 下载服务业务契约
 type DownloadService interface {
 	// 基础下载操作
 	CreateDownload(ctx context.Context, req DownloadRequest) (*DownloadResponse, error)
 	GetDownload(ctx context.Context, id string) (*DownloadResponse, error)
+	GetDownloadDetail(ctx context.Context, id string) (*DownloadDetail, error)
 	ListDownloads(ctx context.Context, req DownloadListRequest) (*DownloadListResponse, error)
 
 	// 下载控制
 	PauseDownload(ctx context.Context, id string) error
 	ResumeDownload(ctx context.Context, id string) error
 	CancelDownload(ctx context.Context, id string) error
+	// PrioritizeDownload 将等待中或已暂停的任务移到队首立即下载，按配置暂停其他活动任务腾出槽位
+	PrioritizeDownload(ctx context.Context, id string) (*PrioritizeResult, error)
+	// ChangePosition 调整等待中任务的队列位置（how 为 Position* 常量），返回调整后的位置（0 为队首）
+	ChangePosition(ctx context.Context, id string, pos int, how string) (int, error)
+	// BoostDownload 在配置的上限内翻倍进行中任务的每服务器连接数和分段数（BT 任务不支持）
+	BoostDownload(ctx context.Context, id string) (*BoostResult, error)
+	// CancelDownloadFile 只停止任务中的一个文件：多文件任务（如种子）通过 select-file 取消选择，单文件任务直接取消
+	CancelDownloadFile(ctx context.Context, id string, index int) (*DownloadFileCancelResult, error)
 	RetryDownload(ctx context.Context, id string) (*DownloadResponse, error)
+	RemoveDownloadResult(ctx context.Context, id string) error
+
+	// 已完成下载的本地文件（基于下载历史）
+	GetRecentCompletedDownloads(ctx context.Context, limit int) ([]*entities.DownloadRecord, error)
+	MoveCompletedDownload(ctx context.Context, id, targetDir string) (*MoveDownloadResult, error)
+	// FindDownloadedFiles 按文件名关键词查找已下载文件的本地位置：优先查下载历史，没有匹配时有限扫描下载目录
+	FindDownloadedFiles(ctx context.Context, keyword string) (*FindDownloadedFilesResult, error)
+	// GetDownloadHistoryStats 按分类汇总 since 之后完成的下载数量和大小
+	GetDownloadHistoryStats(ctx context.Context, since time.Time) (*DownloadHistoryStats, error)
 
 	// 批量操作
 	CreateBatchDownload(ctx context.Context, req BatchDownloadRequest) (*BatchDownloadResponse, error)
-	PauseAllDownloads(ctx context.Context) error
-	ResumeAllDownloads(ctx context.Context) error
+	// SummarizeBatch 按与 CreateBatchDownload 相同的规则统计将要下载的文件，不创建任务
+	SummarizeBatch(ctx context.Context, req BatchDownloadRequest) DownloadSummary
+	// RetryFailedBatch 重新提交批次中所有失败的文件，batchID 为空时使用最近一次批量下载
+	RetryFailedBatch(ctx context.Context, batchID string) (*BatchRetryResult, error)
+	// RetryBatchItem 按记录重新提交一个文件（有源文件路径时刷新下载链接），并将结果写回记录
+	RetryBatchItem(ctx context.Context, item *entities.DownloadBatchItem) DownloadResult
+	// CancelDownloads 逐个取消任务，单个失败不中断，返回每个任务的结果
+	CancelDownloads(ctx context.Context, ids []string) *BatchCancelResult
+	// CancelAllDownloads 取消所有活动和等待中的任务（已暂停的任务不受影响）
+	CancelAllDownloads(ctx context.Context) (*BatchCancelResult, error)
+	// PauseAllDownloads/ResumeAllDownloads 全局暂停/恢复整个队列，返回受影响的任务数
+	PauseAllDownloads(ctx context.Context) (int, error)
+	ResumeAllDownloads(ctx context.Context) (int, error)
 
 	// 系统状态
 	GetSystemStatus(ctx context.Context) (map[string]interface{}, error)
 	GetDownloadStatistics(ctx context.Context) (map[string]interface{}, error)
+	GetBandwidthStats(ctx context.Context, window time.Duration) (*BandwidthStats, error)
+	GetCurrentSpeed(ctx context.Context) (int64, error)
+	StartBandwidthSampling()
+	// StartCategoryLimits 启动按分类的同时下载数限制（download.category_limits）
+	StartCategoryLimits()
+
+	// aria2 连接健康检查
+	GetAria2Health() ComponentHealth
+	StartHealthCheck()
+	VerifyAria2() error
+	// PingAria2 单次 getVersion 测量 aria2 RPC 延迟
+	PingAria2(ctx context.Context) (time.Duration, error)
+
+	// 事件监听（首次注册时启动下载监控）
+	AddEventListener(listener DownloadEventListener)
+}
+
+// userIDKey 发起下载的 Telegram 用户 context key
+type userIDKey struct{}
+
+// WithUserID 返回带发起用户ID的 context，下载服务据此应用用户专属下载目录
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext 获取发起下载的用户ID，未设置时返回0
+func UserIDFromContext(ctx context.Context) int64 {
+	userID, _ := ctx.Value(userIDKey{}).(int64)
+	return userID
 }
@@ -2,9 +2,11 @@ package contracts
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/domain/models/rename"
+	fileutil "github.com/easayliu/alist-aria2-download/pkg/utils/file"
 )
 
 // FileListRequest 文件列表请求参数
@@ -16,6 +18,8 @@ type FileListRequest struct {
 	VideoOnly bool   `json:"video_only,omitempty"`
 	SortBy    string `json:"sort_by,omitempty" validate:"omitempty,oneof=name size modified"`
 	SortOrder string `json:"sort_order,omitempty" validate:"omitempty,oneof=asc desc"`
+	// IncludeHidden 是否包含隐藏文件和目录（名称以 . 开头），默认过滤
+	IncludeHidden bool `json:"include_hidden,omitempty"`
 }
 
 // FileResponse 文件响应信息
@@ -43,6 +47,7 @@ type FileListResponse struct {
 	TotalCount  int            `json:"total_count"`
 	Summary     FileSummary    `json:"summary"`
 	Pagination  Pagination     `json:"pagination"`
+	Truncated   bool           `json:"truncated,omitempty"` // 目录条目超过读取上限，结果不完整
 }
 
 // FileSummary 文件摘要信息
@@ -55,6 +60,9 @@ type FileSummary struct {
 	MovieFiles         int    `json:"movie_files"`
 	TVFiles            int    `json:"tv_files"`
 	OtherFiles         int    `json:"other_files"`
+	MusicFiles         int    `json:"music_files,omitempty"`    // 音乐文件数（需启用 download.music）
+	DocumentFiles      int    `json:"document_files,omitempty"` // 文档文件数（需启用 download.documents）
+	HiddenItems        int    `json:"hidden_items,omitempty"`   // 被过滤的隐藏文件和目录数
 }
 
 // Pagination 分页信息
@@ -73,6 +81,9 @@ type TimeRangeFileRequest struct {
 	EndTime   time.Time `json:"end_time" validate:"required"`
 	VideoOnly bool      `json:"video_only,omitempty"`
 	HoursAgo  int       `json:"hours_ago,omitempty" validate:"min=1,max=8760"`
+	// MinFileSize/MaxFileSize 文件大小范围（字节），0为不限
+	MinFileSize int64 `json:"min_file_size,omitempty"`
+	MaxFileSize int64 `json:"max_file_size,omitempty"`
 }
 
 // TimeRangeFileResponse 时间范围文件响应
@@ -90,12 +101,38 @@ type RecentFilesRequest struct {
 	Limit     int    `json:"limit,omitempty" validate:"min=1,max=1000"`
 }
 
+// MaxLatestFilesCount 按数量选择最新文件时的数量上限
+const MaxLatestFilesCount = 50
+
+// LatestFilesRequest 最新文件请求：按修改时间倒序取前 Count 个文件（递归子目录）
+type LatestFilesRequest struct {
+	Path      string `json:"path" validate:"required"`
+	Count     int    `json:"count" validate:"required,min=1,max=50"`
+	VideoOnly bool   `json:"video_only,omitempty"`
+}
+
+// LatestFilesResponse 最新文件响应，Files 按修改时间倒序
+type LatestFilesResponse struct {
+	Path         string         `json:"path"`
+	Files        []FileResponse `json:"files"`
+	Summary      FileSummary    `json:"summary"`
+	ScannedFiles int            `json:"scanned_files"`
+	Truncated    bool           `json:"truncated"` // 目录文件过多，仅扫描了部分文件
+}
+
 // FileDownloadRequest 文件下载请求
 type FileDownloadRequest struct {
 	FilePath     string                 `json:"file_path" validate:"required"`
 	TargetDir    string                 `json:"target_dir,omitempty"`
 	AutoClassify bool                   `json:"auto_classify,omitempty"`
 	Options      map[string]interface{} `json:"options,omitempty"`
+
+	// DeleteAfterDownload 仅 Telegram 管理员可开启，HTTP 接口不接受
+	DeleteAfterDownload bool `json:"-"`
+	// Filename 指定保存的文件名（已清理），为空时使用源文件名
+	Filename string `json:"filename,omitempty"`
+	// DownloadSubtitles 同时下载同目录中与视频同名的字幕文件
+	DownloadSubtitles bool `json:"download_subtitles,omitempty"`
 }
 
 // BatchFileDownloadRequest 批量文件下载请求
@@ -104,6 +141,9 @@ type BatchFileDownloadRequest struct {
 	TargetDir    string                `json:"target_dir,omitempty"`
 	VideoOnly    bool                  `json:"video_only,omitempty"`
 	AutoClassify bool                  `json:"auto_classify,omitempty"`
+
+	// DeleteAfterDownload 仅 Telegram 管理员可开启，HTTP 接口不接受
+	DeleteAfterDownload bool `json:"-"`
 }
 
 // DirectoryDownloadRequest 目录下载请求
@@ -113,6 +153,46 @@ type DirectoryDownloadRequest struct {
 	VideoOnly     bool   `json:"video_only,omitempty"`
 	AutoClassify  bool   `json:"auto_classify,omitempty"`
 	TargetDir     string `json:"target_dir,omitempty"`
+	IncludeHidden bool   `json:"include_hidden,omitempty"` // 是否下载隐藏文件和目录，默认排除
+
+	// DeleteAfterDownload 仅 Telegram 管理员可开启，HTTP 接口不接受
+	DeleteAfterDownload bool `json:"-"`
+
+	// Resume 从上次中断处继续：跳过断点中已提交的文件，沿用上次的下载后删除设置
+	Resume bool `json:"resume,omitempty"`
+
+	// Preview 只统计将要下载的文件数和总大小（结果在 Summary 中），不创建下载任务也不记录断点
+	Preview bool `json:"preview,omitempty"`
+
+	// ModifiedAfter 只下载修改时间不早于该时间的文件（浏览中的日期筛选），筛选下载不记录断点
+	ModifiedAfter time.Time `json:"modified_after,omitempty"`
+	// Extensions 只下载这些扩展名的文件（不带点号，不区分大小写），为空时不筛选
+	Extensions []string `json:"extensions,omitempty"`
+
+	// NotifyChatID 分批提交进度通知发送到的聊天（由 Telegram 发起时设置），为0时作为系统事件通知
+	NotifyChatID int64 `json:"-"`
+}
+
+// Filtered 是否按修改时间或扩展名筛选
+func (r DirectoryDownloadRequest) Filtered() bool {
+	return !r.ModifiedAfter.IsZero() || len(r.Extensions) > 0
+}
+
+// Matches 文件是否符合修改时间和扩展名筛选
+func (r DirectoryDownloadRequest) Matches(file FileResponse) bool {
+	if !r.ModifiedAfter.IsZero() && file.Modified.Before(r.ModifiedAfter) {
+		return false
+	}
+	return len(r.Extensions) == 0 || fileutil.HasExtension(file.Name, r.Extensions)
+}
+
+// DirectoryCheckpoint 目录下载断点（上次下载中途中断，部分文件已提交）
+type DirectoryCheckpoint struct {
+	Path                string    `json:"path"`
+	QueuedFiles         int       `json:"queued_files"` // 已提交的文件数
+	TotalFiles          int       `json:"total_files"`  // 上次开始时待提交的文件数
+	DeleteAfterDownload bool      `json:"delete_after_download,omitempty"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
 // FileClassificationRequest 文件分类请求
@@ -144,6 +224,9 @@ type FileSearchRequest struct {
 	ModifiedAfter  *time.Time `json:"modified_after,omitempty"`
 	ModifiedBefore *time.Time `json:"modified_before,omitempty"`
 	Limit          int        `json:"limit,omitempty" validate:"min=1,max=1000"`
+	VideoOnly      bool       `json:"video_only,omitempty"`
+	// MaxDepth 最多递归的子目录层数，0 使用默认值
+	MaxDepth int `json:"max_depth,omitempty"`
 }
 
 // RenameSuggestion 重命名建议（领域模型的别名）
@@ -173,6 +256,8 @@ type MediaInfo struct {
 	Year    int    `json:"year"`
 	Season  *int   `json:"season"`  // 季度（仅剧集）
 	Episode *int   `json:"episode"` // 集数（仅剧集）
+
+	EpisodeTitle string `json:"episode_title,omitempty"` // 单集标题（仅剧集，来自 Emby 格式文件名）
 }
 
 // HybridStrategy 混合策略类型（新增）
@@ -215,6 +300,7 @@ type FileService interface {
 	// 时间范围文件查询
 	GetFilesByTimeRange(ctx context.Context, req TimeRangeFileRequest) (*TimeRangeFileResponse, error)
 	GetRecentFiles(ctx context.Context, req RecentFilesRequest) (*FileListResponse, error)
+	GetLatestFiles(ctx context.Context, req LatestFilesRequest) (*LatestFilesResponse, error)
 	GetYesterdayFiles(ctx context.Context, path string) (*FileListResponse, error)
 
 	// 文件分类
@@ -235,6 +321,8 @@ type FileService interface {
 
 	// 系统功能
 	GetStorageInfo(ctx context.Context, path string) (map[string]interface{}, error)
+	// PingAlist 单次轻量请求测量 Alist 延迟
+	PingAlist(ctx context.Context) (time.Duration, error)
 
 	// 文件重命名
 	RenameFile(ctx context.Context, path, newName string) error
@@ -243,12 +331,258 @@ type FileService interface {
 	BatchRenameAndMoveFilesOptimized(ctx context.Context, tasks []RenameTask) []RenameResult
 	GetRenameSuggestions(ctx context.Context, path string) ([]RenameSuggestion, error)
 	GetBatchRenameSuggestions(ctx context.Context, paths []string) (map[string][]RenameSuggestion, error)
+	// GetEpisodeTag 仅解析文件名（不请求TMDB），返回季集标记如 S02E05，非剧集文件返回空字符串
+	GetEpisodeTag(path string) string
 
 	// 批量重命名(统一使用TMDB批量模式,单文件也通过批量接口处理)
 	// 返回: suggestionsMap[文件路径] = 建议列表, usedLLM(已废弃,始终为false), error
 	GetBatchRenameSuggestionsWithLLM(ctx context.Context, paths []string) (map[string][]RenameSuggestion, bool, error)
 
-	// 文件删除
+	// 文件删除（ctx 带试运行标记时只记录不删除，见 WithDryRun）
 	DeleteFile(ctx context.Context, path string) error
 	DeleteFiles(ctx context.Context, paths []string) error
+	PreviewDelete(ctx context.Context, paths []string) ([]DeleteTarget, error)
+
+	// 目录清点（只扫描不下载）
+	ScanInventory(ctx context.Context, req InventoryRequest) (*InventoryReport, error)
+
+	// 片段预览（Range 请求文件开头，仅在内存中探测，不落盘）
+	SampleFile(ctx context.Context, path string, maxBytes int64) (*FileSample, error)
+
+	// 视频时长和分辨率（Range 读取容器头部，无法解析时使用 ffprobe；按路径和文件大小缓存）
+	ProbeVideoMetadata(ctx context.Context, path string, size int64) (*VideoMetadata, error)
+
+	// 目录直链（仅当前目录的文件，不递归）
+	GetDirectoryLinks(ctx context.Context, dirPath string) (*DirectoryLinksResponse, error)
+
+	// 目录打包下载（需配置 alist.zip_download_url，不支持时返回 ErrDirectoryZipUnsupported，调用方改为逐个文件下载）
+	ProbeDirectoryZip(ctx context.Context, dirPath string) (*DirectoryZip, error)
+	DownloadDirectoryZip(ctx context.Context, dirPath string) (*DownloadResponse, error)
+
+	// 分类纠正（记录覆盖规则，已下载的文件移动到新分类目录）
+	ReclassifyFile(ctx context.Context, req ReclassifyRequest) (*ReclassifyResult, error)
+
+	// 下载目录整理（按当前分类规则移动本地已下载的文件；ctx 带试运行标记时只生成计划，见 WithDryRun）
+	ReorganizeDownloads(ctx context.Context) (*ReorganizeReport, error)
+
+	// 目录下载断点（上次中断的目录下载，可通过 DirectoryDownloadRequest.Resume 继续）
+	GetDirectoryCheckpoint(path string) (*DirectoryCheckpoint, bool)
+
+	// 规则测试（纯本地计算，不访问 Alist/aria2）
+	TestClassification(input string) ClassificationTestResult
+
+	// 媒体信息解析（纯本地计算；已按 TMDB 重命名为 Emby 格式的文件可直接取得标题、年份和季集）
+	ParseMediaInfo(fullPath string) MediaInfo
+
+	// 目录快照（记录当前文件列表，之后对比新增/删除/变化的文件；ctx 中的用户记为快照创建者）
+	SnapshotDirectory(ctx context.Context, path string) (*DirectorySnapshotResult, error)
+	DiffDirectory(ctx context.Context, path string) (*DirectoryDiff, error)
+}
+
+// FileLink 文件下载直链
+type FileLink struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	URL  string `json:"url"`
+}
+
+// DirectoryLinksResponse 目录直链列表
+type DirectoryLinksResponse struct {
+	Path      string     `json:"path"`
+	Links     []FileLink `json:"links"`
+	Truncated bool       `json:"truncated"` // 文件数超过上限，仅返回前面的部分
+}
+
+// ReclassifyCategories 可纠正为的分类
+var ReclassifyCategories = []string{"movie", "tv", "variety", "video"}
+
+// ReclassifyRequest 纠正文件分类请求
+type ReclassifyRequest struct {
+	Path     string `json:"path" validate:"required"`
+	Category string `json:"category" validate:"required"`
+	UserID   int64  `json:"user_id,omitempty"`
+}
+
+// ReclassifyResult 纠正文件分类结果
+type ReclassifyResult struct {
+	Pattern    string `json:"pattern"` // 记录的匹配键，之后文件名包含该键的文件都使用新分类
+	Title      string `json:"title"`
+	Category   string `json:"category"`
+	Downloaded bool   `json:"downloaded"` // 是否有已完成的下载记录
+	Moved      bool   `json:"moved"`
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	MoveError  string `json:"move_error,omitempty"`
+}
+
+// 整理操作类型
+const (
+	ReorganizeActionMove      = "move"      // 移动到目标目录
+	ReorganizeActionRename    = "rename"    // 目标文件已存在，添加序号后移动
+	ReorganizeActionOverwrite = "overwrite" // 目标文件已存在，覆盖
+	ReorganizeActionSkip      = "skip"      // 目标文件已存在，按策略跳过
+)
+
+// ReorganizeReport 下载目录整理报告
+type ReorganizeReport struct {
+	Root        string           `json:"root"`
+	DryRun      bool             `json:"dry_run"`
+	Policy      string           `json:"policy"`      // 目标文件已存在时的处理策略
+	Scanned     int              `json:"scanned"`     // 扫描到的媒体文件数
+	InPlace     int              `json:"in_place"`    // 已在正确目录的文件数
+	Downloading int              `json:"downloading"` // 仍在下载（存在 .aria2 控制文件）而跳过的文件数
+	Moves       []ReorganizeMove `json:"moves"`
+}
+
+// ReorganizeMove 单个文件的整理操作
+type ReorganizeMove struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Category string `json:"category"`
+	Action   string `json:"action"`
+	Moved    bool   `json:"moved"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ClassificationTestResult 分类规则测试结果
+type ClassificationTestResult struct {
+	Input        string    `json:"input"`
+	Path         string    `json:"path"`
+	IsVideo      bool      `json:"is_video"`
+	Category     string    `json:"category"`
+	Rule         string    `json:"rule"` // 命中的规则说明
+	DownloadPath string    `json:"download_path"`
+	PathSource   string    `json:"path_source"` // 下载路径来源（路径模板/默认目录规则等）
+	Media        MediaInfo `json:"media"`       // 仅从文件名解析的媒体信息
+}
+
+// ErrDirectoryZipUnsupported Alist 部署不支持目录打包下载
+var ErrDirectoryZipUnsupported = errors.New("不支持目录打包下载")
+
+// DirectoryZip 服务端生成的目录压缩包
+type DirectoryZip struct {
+	Path     string `json:"path"`     // 目录路径
+	URL      string `json:"url"`      // 压缩包下载地址
+	FileName string `json:"filename"` // 保存的文件名
+	Size     int64  `json:"size"`     // 压缩包大小，服务端实时打包无法预知时为 -1
+}
+
+// ErrSnapshotNotFound 目录还没有快照
+var ErrSnapshotNotFound = errors.New("目录还没有快照")
+
+// DirectorySnapshotResult 目录快照结果
+type DirectorySnapshotResult struct {
+	Path       string     `json:"path"`
+	FileCount  int        `json:"file_count"`
+	TotalSize  int64      `json:"total_size"`
+	Truncated  bool       `json:"truncated,omitempty"` // 目录过大，快照只包含部分文件
+	CreatedAt  time.Time  `json:"created_at"`
+	PreviousAt *time.Time `json:"previous_at,omitempty"` // 被替换的上一次快照时间
+}
+
+// DirectoryDiff 目录当前内容与上次快照的差异
+type DirectoryDiff struct {
+	Path       string         `json:"path"`
+	SnapshotAt time.Time      `json:"snapshot_at"`
+	Added      []FileResponse `json:"added"`
+	Removed    []FileResponse `json:"removed"` // 只有路径、名称和快照时的大小
+	Changed    []FileResponse `json:"changed"` // 大小或修改时间变化的文件（当前信息）
+	Unchanged  int            `json:"unchanged"`
+	Truncated  bool           `json:"truncated,omitempty"` // 快照或本次扫描不完整，差异可能不准确
+}
+
+// FileSample 文件片段探测结果
+type FileSample struct {
+	Path           string   `json:"path"`
+	BytesRead      int64    `json:"bytes_read"`
+	TotalSize      int64    `json:"total_size"`      // 文件总大小，服务器未返回时为0
+	RangeSupported bool     `json:"range_supported"` // 服务器是否支持 Range 请求
+	Container      string   `json:"container"`       // 容器格式，无法识别时为空
+	Detail         string   `json:"detail,omitempty"`
+	Codecs         []string `json:"codecs,omitempty"`
+	// Video 片段中解析到的时长和分辨率，无法解析时为 nil
+	Video *VideoMetadata `json:"video,omitempty"`
+}
+
+// VideoMetadata 视频时长和分辨率
+type VideoMetadata struct {
+	Duration time.Duration `json:"duration,omitempty"` // 时长，未知时为0
+	Width    int           `json:"width,omitempty"`
+	Height   int           `json:"height,omitempty"`
+	Source   string        `json:"source"` // 信息来源：header（容器头部）或 ffprobe
+}
+
+// InventoryRequest 目录媒体清单请求（只扫描，不创建下载任务）
+type InventoryRequest struct {
+	Path     string `json:"path" validate:"required"`
+	MaxFiles int    `json:"max_files,omitempty"` // 扫描文件数上限，0使用默认值
+	MaxDepth int    `json:"max_depth,omitempty"` // 递归的最大子目录层数，0使用默认值
+}
+
+// InventoryDirectory 一级子目录对清单的贡献
+type InventoryDirectory struct {
+	Name  string `json:"name"` // 一级子目录名，直接位于扫描目录下的文件为空
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// InventoryCategory 分类统计
+type InventoryCategory struct {
+	Count int   `json:"count"`
+	Size  int64 `json:"size"`
+}
+
+// InventoryShow 识别出的剧集
+type InventoryShow struct {
+	Title    string `json:"title"`
+	Seasons  []int  `json:"seasons"`
+	Episodes int    `json:"episodes"`
+	Size     int64  `json:"size"`
+}
+
+// InventoryMovie 识别出的电影
+type InventoryMovie struct {
+	Title string `json:"title"`
+	Year  int    `json:"year,omitempty"`
+	Files int    `json:"files"`
+	Size  int64  `json:"size"`
+}
+
+// InventoryReport 目录媒体清单
+type InventoryReport struct {
+	Path         string                       `json:"path"`
+	ScannedAt    time.Time                    `json:"scanned_at"`
+	TotalFiles   int                          `json:"total_files"`
+	TotalDirs    int                          `json:"total_dirs"`
+	TotalSize    int64                        `json:"total_size"`
+	Categories   map[string]InventoryCategory `json:"categories"`
+	TopLevel     []InventoryDirectory         `json:"top_level"` // 按大小降序
+	Shows        []InventoryShow              `json:"shows"`
+	Movies       []InventoryMovie             `json:"movies"`
+	Unclassified []FileResponse               `json:"unclassified"` // 无法识别分类或标题的视频文件
+	FailedDirs   []string                     `json:"failed_dirs,omitempty"`
+	Truncated    bool                         `json:"truncated"`     // 达到扫描上限，结果不完整
+	DepthLimited bool                         `json:"depth_limited"` // 达到最大目录层数，更深的目录未扫描
+	Cached       bool                         `json:"cached"`        // 结果来自最近一次扫描的缓存
+}
+
+// DeleteTarget 删除目标 - 同一目录下的文件在一次 Alist 调用中删除
+type DeleteTarget struct {
+	Dir   string   `json:"dir"`
+	Names []string `json:"names"`
+}
+
+// dryRunKey 试运行标记的 context key
+type dryRunKey struct{}
+
+// WithDryRun 返回带试运行标记的 context，删除操作只记录将要删除的内容而不调用 Alist
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun 判断 context 是否带试运行标记
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
 }
@@ -105,6 +105,8 @@ type TaskNotificationRequest struct {
 	Duration     time.Duration          `json:"duration"`
 	ErrorMessage string                 `json:"error_message,omitempty"`
 	Extra        map[string]interface{} `json:"extra,omitempty"`
+	TargetID     string                 `json:"target_id,omitempty"` // 接收通知的Telegram聊天ID，为空时发送给所有授权用户
+	Message      string                 `json:"message,omitempty"`   // 已格式化的通知内容（HTML），为空时使用默认格式
 }
 
 // SystemNotificationRequest 系统通知请求
@@ -0,0 +1,19 @@
+package contracts
+
+import (
+	"errors"
+
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// ErrReadOnly 只读模式（server.read_only）下拒绝删除、移动、重命名等破坏性操作
+var ErrReadOnly = errors.New("只读模式，操作已禁用")
+
+// CheckWritable 所有破坏性操作的统一入口检查，只读模式下返回 ErrReadOnly
+func CheckWritable(readOnly bool, operation string) error {
+	if !readOnly {
+		return nil
+	}
+	logger.Warn("Destructive operation blocked in read-only mode", "operation", operation)
+	return ErrReadOnly
+}
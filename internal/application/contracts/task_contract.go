@@ -7,6 +7,9 @@ import (
 	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
 )
 
+// ErrTaskNotFound 定时任务不存在
+var ErrTaskNotFound = entities.ErrTaskNotFound
+
 // TaskRequest 任务请求统一参数
 type TaskRequest struct {
 	Name        string `json:"name" validate:"required,min=1,max=100"`
@@ -17,6 +20,17 @@ type TaskRequest struct {
 	AutoPreview bool   `json:"auto_preview"`
 	Enabled     bool   `json:"enabled"`
 	CreatedBy   int64  `json:"created_by"`
+	// DeleteAfterDownload 下载完成并校验后删除 Alist 源文件（需配置开启），仅 Telegram 管理员可设置，HTTP 接口不接受
+	DeleteAfterDownload bool `json:"-"`
+	// NotifyChatID 运行结果通知的聊天/频道ID，为0时通知创建者
+	NotifyChatID int64 `json:"notify_chat_id,omitempty"`
+	// NotifyOnComplete 运行下载结束后发送下载汇总，默认不发送
+	NotifyOnComplete bool `json:"notify_on_complete,omitempty"`
+	// MinFileSize/MaxFileSize 只下载该大小范围内的文件（字节），0为不限
+	MinFileSize int64 `json:"min_file_size,omitempty" validate:"min=0"`
+	MaxFileSize int64 `json:"max_file_size,omitempty" validate:"min=0"`
+	// Timezone 计算触发时间使用的 IANA 时区（如 Asia/Shanghai），为空时使用调度器时区
+	Timezone string `json:"timezone,omitempty"`
 }
 
 // TaskUpdateRequest 任务更新请求
@@ -28,27 +42,45 @@ type TaskUpdateRequest struct {
 	VideoOnly   *bool   `json:"video_only,omitempty"`
 	AutoPreview *bool   `json:"auto_preview,omitempty"`
 	Enabled     *bool   `json:"enabled,omitempty"`
+	// DeleteAfterDownload 下载完成并校验后删除 Alist 源文件（需配置开启），仅 Telegram 管理员可设置，HTTP 接口不接受
+	DeleteAfterDownload *bool `json:"-"`
+	// NotifyChatID 运行结果通知的聊天/频道ID，设为0恢复通知创建者
+	NotifyChatID *int64 `json:"notify_chat_id,omitempty"`
+	// NotifyOnComplete 运行下载结束后发送下载汇总
+	NotifyOnComplete *bool `json:"notify_on_complete,omitempty"`
+	// MinFileSize/MaxFileSize 文件大小范围（字节），设为0取消限制
+	MinFileSize *int64 `json:"min_file_size,omitempty" validate:"omitempty,min=0"`
+	MaxFileSize *int64 `json:"max_file_size,omitempty" validate:"omitempty,min=0"`
+	// Timezone IANA 时区，设为空字符串恢复使用调度器时区
+	Timezone *string `json:"timezone,omitempty"`
 }
 
 // TaskResponse 任务响应统一格式
 type TaskResponse struct {
-	ID           string              `json:"id"`
-	Name         string              `json:"name"`
-	Path         string              `json:"path"`
-	CronExpr     string              `json:"cron_expr"`
-	HoursAgo     int                 `json:"hours_ago"`
-	VideoOnly    bool                `json:"video_only"`
-	AutoPreview  bool                `json:"auto_preview"`
-	Enabled      bool                `json:"enabled"`
-	CreatedBy    int64               `json:"created_by"`
-	Status       entities.TaskStatus `json:"status"`
-	LastRunAt    *time.Time          `json:"last_run_at,omitempty"`
-	NextRunAt    *time.Time          `json:"next_run_at,omitempty"`
-	RunCount     int                 `json:"run_count"`
-	SuccessCount int                 `json:"success_count"`
-	FailureCount int                 `json:"failure_count"`
-	CreatedAt    time.Time           `json:"created_at"`
-	UpdatedAt    time.Time           `json:"updated_at"`
+	ID                  string                   `json:"id"`
+	Name                string                   `json:"name"`
+	Path                string                   `json:"path"`
+	CronExpr            string                   `json:"cron_expr"`
+	Timezone            string                   `json:"timezone,omitempty"`
+	Window              *entities.ScheduleWindow `json:"window,omitempty"` // 时间窗口调度，cron_expr 为空时按窗口运行
+	HoursAgo            int                      `json:"hours_ago"`
+	VideoOnly           bool                     `json:"video_only"`
+	AutoPreview         bool                     `json:"auto_preview"`
+	DeleteAfterDownload bool                     `json:"delete_after_download"`
+	Enabled             bool                     `json:"enabled"`
+	CreatedBy           int64                    `json:"created_by"`
+	NotifyChatID        int64                    `json:"notify_chat_id,omitempty"`
+	NotifyOnComplete    bool                     `json:"notify_on_complete"`
+	MinFileSize         int64                    `json:"min_file_size,omitempty"`
+	MaxFileSize         int64                    `json:"max_file_size,omitempty"`
+	Status              entities.TaskStatus      `json:"status"`
+	LastRunAt           *time.Time               `json:"last_run_at,omitempty"`
+	NextRunAt           *time.Time               `json:"next_run_at,omitempty"`
+	RunCount            int                      `json:"run_count"`
+	SuccessCount        int                      `json:"success_count"`
+	FailureCount        int                      `json:"failure_count"`
+	CreatedAt           time.Time                `json:"created_at"`
+	UpdatedAt           time.Time                `json:"updated_at"`
 }
 
 // TaskListRequest 任务列表查询参数
@@ -111,6 +143,9 @@ type PreviewSummary struct {
 	MovieFiles int    `json:"movie_files"`
 	TVFiles    int    `json:"tv_files"`
 	OtherFiles int    `json:"other_files"`
+	// 音乐和文档分类需在配置中启用，未启用时计入 OtherFiles
+	MusicFiles    int `json:"music_files,omitempty"`
+	DocumentFiles int `json:"document_files,omitempty"`
 }
 
 // TaskRunRequest 任务执行请求
@@ -131,6 +166,53 @@ type TaskRunResponse struct {
 	DownloadIDs []string             `json:"download_ids,omitempty"`
 }
 
+// TaskRunSummary 定时任务一次运行的下载汇总，任务开启 NotifyOnComplete 时发送给通知目标
+type TaskRunSummary struct {
+	TaskID       string        `json:"task_id"`
+	TaskName     string        `json:"task_name"`
+	Path         string        `json:"path"`
+	FilesMatched int           `json:"files_matched"` // 时间范围内匹配的文件数
+	SuccessCount int           `json:"success_count"` // 成功创建下载的文件数
+	FailureCount int           `json:"failure_count"` // 创建下载失败的文件数
+	TotalSize    int64         `json:"total_size"`    // 成功创建下载的文件总大小
+	Duration     time.Duration `json:"duration"`
+}
+
+// TaskDigest 某一天定时任务运行汇总（按配置的时区划分日界）
+type TaskDigest struct {
+	Date            time.Time        `json:"date"` // 当天零点（配置时区）
+	Tasks           []TaskDigestItem `json:"tasks"`
+	TotalRuns       int              `json:"total_runs"`
+	FilesDownloaded int              `json:"files_downloaded"`
+	DownloadedSize  int64            `json:"downloaded_size"`
+	FailedRuns      int              `json:"failed_runs"`
+}
+
+// TaskDigestItem 单个任务当天的运行汇总
+type TaskDigestItem struct {
+	TaskID          string    `json:"task_id"`
+	TaskName        string    `json:"task_name"`
+	Runs            int       `json:"runs"`
+	EmptyRuns       int       `json:"empty_runs"`   // 触发但没有新文件的次数
+	PreviewRuns     int       `json:"preview_runs"` // 预览模式运行次数
+	FailedRuns      int       `json:"failed_runs"`
+	FilesDownloaded int       `json:"files_downloaded"`
+	DownloadedSize  int64     `json:"downloaded_size"`
+	FailedDownloads int       `json:"failed_downloads"` // 创建下载失败的文件数
+	LastRunAt       time.Time `json:"last_run_at"`
+	LastError       string    `json:"last_error,omitempty"`
+}
+
+// TaskRetryResult 重试定时任务失败文件的结果
+type TaskRetryResult struct {
+	TaskID       string           `json:"task_id"`
+	TaskName     string           `json:"task_name"`
+	FailedCount  int              `json:"failed_count"`  // 重试前记录的失败文件数
+	SuccessCount int              `json:"success_count"` // 重新提交成功（已从失败列表移除）的文件数
+	FailureCount int              `json:"failure_count"` // 重新提交仍失败的文件数
+	Results      []DownloadResult `json:"results"`
+}
+
 // QuickTaskRequest 快捷任务请求
 type QuickTaskRequest struct {
 	Type      string `json:"type" validate:"required,oneof=daily recent weekly realtime"`
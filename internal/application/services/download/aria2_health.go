@@ -0,0 +1,212 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// defaultHealthInterval 连接正常时的检查间隔
+	defaultHealthInterval = 30 * time.Second
+	// minReconnectBackoff 断开后首次重连等待时间
+	minReconnectBackoff = 5 * time.Second
+	// maxReconnectBackoff 断开后重连等待的上限
+	maxReconnectBackoff = 2 * time.Minute
+)
+
+// aria2 RPC 密钥校验状态，连接失败时无法判断
+const (
+	aria2AuthUnknown = "unknown"
+	aria2AuthOK      = "ok"
+	aria2AuthFailed  = "failed"
+)
+
+// Aria2HealthChecker aria2 连接健康检查 - 定期调用 getVersion，断开后按指数退避重连
+type Aria2HealthChecker struct {
+	aria2Client *aria2.Client
+	interval    time.Duration
+
+	mu        sync.RWMutex
+	health    contracts.ComponentHealth
+	since     time.Time // 当前状态开始时间
+	version   string    // 最近一次获取到的 aria2 版本
+	auth      string    // RPC 密钥校验状态
+	backoff   time.Duration
+	wake      chan struct{}
+	startOnce sync.Once
+}
+
+// NewAria2HealthChecker 创建 aria2 健康检查器（初始状态为未知）
+func NewAria2HealthChecker(aria2Client *aria2.Client, interval time.Duration) *Aria2HealthChecker {
+	if interval <= 0 {
+		interval = defaultHealthInterval
+	}
+	return &Aria2HealthChecker{
+		aria2Client: aria2Client,
+		interval:    interval,
+		health: contracts.ComponentHealth{
+			Name:   "aria2",
+			Status: contracts.HealthStatusUnknown,
+		},
+		auth: aria2AuthUnknown,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Start 启动后台健康检查（重复调用无效）
+func (h *Aria2HealthChecker) Start() {
+	h.startOnce.Do(func() {
+		go h.run()
+		logger.Info("Aria2 health checker started", "interval", h.interval)
+	})
+}
+
+// run 检查主循环：正常时按固定间隔，断开时按退避间隔重试
+func (h *Aria2HealthChecker) run() {
+	for {
+		wait := h.interval
+		if !h.check() {
+			wait = h.nextBackoff()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-h.wake:
+			timer.Stop()
+		}
+	}
+}
+
+// Verify 立即调用 getVersion 检查连接和 RPC 密钥（用于启动校验），返回分类后的错误
+func (h *Aria2HealthChecker) Verify() error {
+	version, err := h.aria2Client.GetVersion()
+	if err != nil {
+		h.setDisconnected(err)
+		return classifyAria2Error(err)
+	}
+	h.setConnected(version.Version)
+	return nil
+}
+
+// check 调用 getVersion 检查连接，返回是否连接正常
+func (h *Aria2HealthChecker) check() bool {
+	version, err := h.aria2Client.GetVersion()
+	if err != nil {
+		h.setDisconnected(err)
+		return false
+	}
+	h.setConnected(version.Version)
+	return true
+}
+
+// nextBackoff 计算下一次重连等待时间
+func (h *Aria2HealthChecker) nextBackoff() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.backoff == 0 {
+		h.backoff = minReconnectBackoff
+	} else {
+		h.backoff = min(h.backoff*2, maxReconnectBackoff)
+	}
+	return h.backoff
+}
+
+// setConnected 标记为已连接，状态变化时记录日志
+func (h *Aria2HealthChecker) setConnected(version string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if h.health.Status != contracts.HealthStatusHealthy {
+		if h.health.Status == contracts.HealthStatusUnhealthy {
+			logger.Info("Aria2 connection restored", "version", version, "downtime", now.Sub(h.since))
+		}
+		h.since = now
+	}
+	h.health.Status = contracts.HealthStatusHealthy
+	h.health.Message = ""
+	h.health.LastCheck = now
+	h.version = version
+	h.auth = aria2AuthOK
+	h.backoff = 0
+}
+
+// setDisconnected 标记为断开，状态变化时记录日志，返回状态是否发生变化
+func (h *Aria2HealthChecker) setDisconnected(err error) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	unauthorized := errors.Is(err, aria2.ErrUnauthorized)
+	changed := h.health.Status != contracts.HealthStatusUnhealthy
+	if changed {
+		if unauthorized {
+			logger.Error("Aria2 rejected RPC secret, check aria2.token", "error", err)
+		} else {
+			logger.Warn("Aria2 connection lost", "error", err)
+		}
+		h.since = now
+	}
+	h.health.Status = contracts.HealthStatusUnhealthy
+	h.health.Message = err.Error()
+	h.health.LastCheck = now
+	if unauthorized {
+		h.auth = aria2AuthFailed
+	} else if isConnectionError(err) {
+		h.auth = aria2AuthUnknown
+	}
+	return changed
+}
+
+// Health 获取当前健康状态
+func (h *Aria2HealthChecker) Health() contracts.ComponentHealth {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	health := h.health
+	health.Details = map[string]interface{}{
+		"since":   h.since,
+		"version": h.version,
+		"auth":    h.auth,
+	}
+	return health
+}
+
+// WrapError 将连接类错误标记为 aria2 不可用、密钥错误标记为 RPC 密钥错误，其他错误原样返回
+// 首次发现断开时唤醒检查循环，立即进入退避重连
+func (h *Aria2HealthChecker) WrapError(err error) error {
+	if err == nil || (!isConnectionError(err) && !errors.Is(err, aria2.ErrUnauthorized)) {
+		return err
+	}
+
+	if h.setDisconnected(err) {
+		select {
+		case h.wake <- struct{}{}:
+		default:
+		}
+	}
+	return classifyAria2Error(err)
+}
+
+// classifyAria2Error 区分 RPC 密钥错误和连接失败
+func classifyAria2Error(err error) error {
+	if errors.Is(err, aria2.ErrUnauthorized) {
+		return fmt.Errorf("%w: %v", contracts.ErrAria2Unauthorized, err)
+	}
+	return fmt.Errorf("%w: %v", contracts.ErrAria2Unavailable, err)
+}
+
+// isConnectionError 判断是否为网络连接错误（连接拒绝、超时等）
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,99 @@
+package download
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+)
+
+func TestAria2HealthChecker_WrapError(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		wantUnavailable bool
+		wantStatus      contracts.HealthStatus
+	}{
+		{
+			name:       "无错误",
+			err:        nil,
+			wantStatus: contracts.HealthStatusUnknown,
+		},
+		{
+			name:       "RPC业务错误",
+			err:        errors.New("aria2 error 1: GID not found"),
+			wantStatus: contracts.HealthStatusUnknown,
+		},
+		{
+			name:            "连接拒绝",
+			err:             &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			wantUnavailable: true,
+			wantStatus:      contracts.HealthStatusUnhealthy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewAria2HealthChecker(nil, 0)
+			err := h.WrapError(tt.err)
+			if got := errors.Is(err, contracts.ErrAria2Unavailable); got != tt.wantUnavailable {
+				t.Errorf("errors.Is(ErrAria2Unavailable) = %v, want %v", got, tt.wantUnavailable)
+			}
+			if got := h.Health().Status; got != tt.wantStatus {
+				t.Errorf("Health().Status = %v, want %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAria2HealthChecker_Verify(t *testing.T) {
+	// 模拟 aria2：密钥正确时返回版本，错误时与 aria2 一样返回 HTTP 400 和 Unauthorized
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req aria2.RPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Params) == 0 || req.Params[0] != "token:secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "error": map[string]any{"code": 1, "message": "Unauthorized"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": map[string]any{"version": "1.37.0"}})
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		url      string
+		token    string
+		wantErr  error
+		wantAuth string
+	}{
+		{name: "密钥正确", url: server.URL, token: "secret", wantAuth: aria2AuthOK},
+		{name: "密钥错误", url: server.URL, token: "wrong", wantErr: contracts.ErrAria2Unauthorized, wantAuth: aria2AuthFailed},
+		{name: "未配置密钥", url: server.URL, wantErr: contracts.ErrAria2Unauthorized, wantAuth: aria2AuthFailed},
+		{name: "连接失败", url: closed.URL, token: "secret", wantErr: contracts.ErrAria2Unavailable, wantAuth: aria2AuthUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewAria2HealthChecker(aria2.NewClient(tt.url, tt.token), 0)
+			err := h.Verify()
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Verify() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := h.Health().Details["auth"]; got != tt.wantAuth {
+				t.Errorf("Health().Details[auth] = %v, want %v", got, tt.wantAuth)
+			}
+		})
+	}
+}
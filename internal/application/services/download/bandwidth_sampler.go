@@ -0,0 +1,154 @@
+package download
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// bandwidthRetention 样本保留时长
+	bandwidthRetention = 24 * time.Hour
+	// bandwidthBuckets 趋势分桶数量
+	bandwidthBuckets = 24
+	// defaultSampleInterval 默认采样间隔
+	defaultSampleInterval = 30 * time.Second
+)
+
+// bandwidthSample 单个速度样本
+type bandwidthSample struct {
+	at    time.Time
+	speed int64 // B/s
+}
+
+// BandwidthSampler 带宽采样器 - 定期读取 aria2 全局下载速度并保存在环形缓冲区中
+type BandwidthSampler struct {
+	aria2Client *aria2.Client
+	interval    time.Duration
+
+	mu        sync.RWMutex
+	samples   []bandwidthSample // 环形缓冲区
+	next      int               // 下一个写入位置
+	count     int               // 已写入样本数
+	startOnce sync.Once
+}
+
+// NewBandwidthSampler 创建带宽采样器，缓冲区大小按保留时长/采样间隔计算
+func NewBandwidthSampler(aria2Client *aria2.Client, interval time.Duration) *BandwidthSampler {
+	if interval <= 0 {
+		interval = defaultSampleInterval
+	}
+	capacity := int(bandwidthRetention/interval) + 1
+
+	return &BandwidthSampler{
+		aria2Client: aria2Client,
+		interval:    interval,
+		samples:     make([]bandwidthSample, capacity),
+	}
+}
+
+// Start 启动后台采样（重复调用无效）
+func (b *BandwidthSampler) Start() {
+	b.startOnce.Do(func() {
+		go b.run()
+		logger.Info("Bandwidth sampler started", "interval", b.interval)
+	})
+}
+
+// run 采样主循环
+func (b *BandwidthSampler) run() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		b.sample()
+	}
+}
+
+// sample 读取一次全局下载速度
+func (b *BandwidthSampler) sample() {
+	stat, err := b.aria2Client.GetGlobalStat()
+	if err != nil {
+		logger.Debug("Bandwidth sample failed", "error", err)
+		return
+	}
+
+	speed, err := strconv.ParseInt(fmt.Sprint(stat["downloadSpeed"]), 10, 64)
+	if err != nil {
+		return
+	}
+	b.add(time.Now(), speed)
+}
+
+// add 写入样本，缓冲区满时覆盖最旧的样本
+func (b *BandwidthSampler) add(at time.Time, speed int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.samples[b.next] = bandwidthSample{at: at, speed: speed}
+	b.next = (b.next + 1) % len(b.samples)
+	if b.count < len(b.samples) {
+		b.count++
+	}
+}
+
+// Stats 统计最近 window 时间内的带宽
+func (b *BandwidthSampler) Stats(window time.Duration) *contracts.BandwidthStats {
+	now := time.Now()
+	since := now.Add(-window)
+	bucketSize := window / bandwidthBuckets
+
+	stats := &contracts.BandwidthStats{
+		Window:   window,
+		Interval: b.interval,
+		Buckets:  make([]int64, bandwidthBuckets),
+	}
+	bucketSums := make([]int64, bandwidthBuckets)
+	bucketCounts := make([]int64, bandwidthBuckets)
+	var sum int64
+
+	b.mu.RLock()
+	for i := 0; i < b.count; i++ {
+		s := b.samples[(b.next-b.count+i+len(b.samples))%len(b.samples)]
+		if s.at.Before(since) {
+			continue
+		}
+
+		if stats.SampleCount == 0 || s.speed < stats.MinSpeed {
+			stats.MinSpeed = s.speed
+		}
+		if s.speed > stats.PeakSpeed {
+			stats.PeakSpeed = s.speed
+		}
+		sum += s.speed
+		stats.SampleCount++
+		// 每个样本代表一个采样间隔内的平均速度
+		stats.TotalBytes += s.speed * int64(b.interval/time.Second)
+
+		if bucketSize > 0 {
+			idx := int(s.at.Sub(since) / bucketSize)
+			if idx >= bandwidthBuckets {
+				idx = bandwidthBuckets - 1
+			}
+			bucketSums[idx] += s.speed
+			bucketCounts[idx]++
+		}
+	}
+	b.mu.RUnlock()
+
+	if stats.SampleCount > 0 {
+		stats.AvgSpeed = sum / int64(stats.SampleCount)
+	}
+	for i := range stats.Buckets {
+		if bucketCounts[i] > 0 {
+			stats.Buckets[i] = bucketSums[i] / bucketCounts[i]
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,252 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// BatchArchiver 批次中所有文件下载完成后打包为一个 zip 文件
+// 仅处理创建时开启 Archive 的批次；有文件失败时不打包，重试成功后会再次触发
+type BatchArchiver struct {
+	config              *config.Config
+	batches             *repository.DownloadBatchRepository
+	history             *repository.DownloadHistoryRepository
+	downloadService     contracts.DownloadService
+	notificationService contracts.NotificationService
+	mu                  sync.Mutex // 打包串行执行，避免同一批次被重复打包
+}
+
+// NewBatchArchiver 创建批次打包器
+func NewBatchArchiver(cfg *config.Config, batches *repository.DownloadBatchRepository, history *repository.DownloadHistoryRepository,
+	downloadService contracts.DownloadService, notificationService contracts.NotificationService) *BatchArchiver {
+	return &BatchArchiver{
+		config:              cfg,
+		batches:             batches,
+		history:             history,
+		downloadService:     downloadService,
+		notificationService: notificationService,
+	}
+}
+
+// batchItemState 批次中单个文件的下载结果
+type batchItemState int
+
+const (
+	batchItemPending batchItemState = iota
+	batchItemComplete
+	batchItemFailed
+)
+
+// HandleEvent 处理下载事件（实现 contracts.DownloadEventListener）
+// 需注册在 HistoryRecorder 之后，以便从下载历史中读取其他文件的最新状态
+// 打包可能耗时较长，在后台 goroutine 中串行执行，不阻塞下载监控分发其他事件
+func (a *BatchArchiver) HandleEvent(ctx context.Context, event contracts.DownloadEvent) {
+	if event.Type == contracts.DownloadEventCreated {
+		return
+	}
+	if batch, ok := a.batches.FindByGID(event.Download.ID); !ok || batch.Archive == nil || batch.Archive.Path != "" {
+		return
+	}
+
+	go func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.archiveBatch(context.WithoutCancel(ctx), event)
+	}()
+}
+
+// archiveBatch 批次中所有文件都有结果时打包；等待锁期间批次可能已被打包，需重新读取
+func (a *BatchArchiver) archiveBatch(ctx context.Context, event contracts.DownloadEvent) {
+	batch, ok := a.batches.FindByGID(event.Download.ID)
+	if !ok || batch.Archive == nil || batch.Archive.Path != "" {
+		return
+	}
+
+	var files []string
+	failed := 0
+	for _, item := range batch.Items {
+		state, file := a.itemState(ctx, item, event.Download)
+		switch state {
+		case batchItemPending:
+			return
+		case batchItemFailed:
+			failed++
+		default:
+			files = append(files, file)
+		}
+	}
+
+	archive := *batch.Archive
+	if failed > 0 {
+		archive.Error = fmt.Sprintf("%d 个文件下载失败", failed)
+		a.saveArchive(batch.ID, archive)
+		logger.Warn("Batch finished with failures, skipping archive", "batchID", batch.ID, "failed", failed)
+		a.notify(ctx, contracts.NotificationLevelWarning, "batch_archive_skipped",
+			fmt.Sprintf("批次 %s 有 %d 个文件下载失败，未打包（重试成功后会自动打包）", shortBatchID(batch.ID), failed))
+		return
+	}
+
+	baseDir, err := a.archiveBaseDir(files)
+	if err != nil {
+		archive.Error = err.Error()
+		a.saveArchive(batch.ID, archive)
+		logger.Warn("Refusing to archive batch outside download directory", "batchID", batch.ID, "error", err)
+		a.notify(ctx, contracts.NotificationLevelError, "batch_archive_failed",
+			fmt.Sprintf("批次 %s 未打包 (%v)", shortBatchID(batch.ID), err))
+		return
+	}
+	archivePath := filepath.Join(baseDir, fmt.Sprintf("batch-%s-%s.zip", time.Now().Format("20060102-150405"), shortBatchID(batch.ID)))
+	if err := filesystem.CreateZipArchive(archivePath, baseDir, files); err != nil {
+		archive.Error = err.Error()
+		a.saveArchive(batch.ID, archive)
+		logger.Error("Failed to archive batch", "batchID", batch.ID, "path", archivePath, "error", err)
+		a.notify(ctx, contracts.NotificationLevelError, "batch_archive_failed",
+			fmt.Sprintf("批次 %s 打包失败 (%v)", shortBatchID(batch.ID), err))
+		return
+	}
+
+	removed := 0
+	if archive.RemoveOriginals {
+		// 只读模式下保留原文件
+		if err := a.checkWritable("archive_remove_originals"); err != nil {
+			archive.RemoveOriginals = false
+		}
+	}
+	if archive.RemoveOriginals {
+		for _, file := range files {
+			if err := os.Remove(file); err != nil {
+				logger.Warn("Failed to remove archived file", "path", file, "error", err)
+				continue
+			}
+			removed++
+		}
+	}
+
+	archive.Path = archivePath
+	archive.ArchivedAt = time.Now()
+	archive.Error = ""
+	a.saveArchive(batch.ID, archive)
+
+	logger.Info("Batch archived", "batchID", batch.ID, "path", archivePath, "files", len(files), "removed", removed)
+	message := fmt.Sprintf("批次 %s 的 %d 个文件已打包：%s", shortBatchID(batch.ID), len(files), archivePath)
+	if archive.RemoveOriginals {
+		message += fmt.Sprintf("（已删除 %d 个原文件）", removed)
+	}
+	a.notify(ctx, contracts.NotificationLevelInfo, "batch_archived", message)
+}
+
+// archiveBaseDir 返回压缩包的保存目录（所有文件共同的上级目录），该目录必须位于 aria2.download_dir 内
+// 文件分散在不同位置时共同目录可能退化为 "/"，此时拒绝打包
+func (a *BatchArchiver) archiveBaseDir(files []string) (string, error) {
+	if a.config == nil || a.config.Aria2.DownloadDir == "" {
+		return "", fmt.Errorf("未配置下载目录 aria2.download_dir")
+	}
+	root := filepath.Clean(a.config.Aria2.DownloadDir)
+	baseDir := filepath.Clean(commonParentDir(files))
+	if !isUnderDir(baseDir, root) {
+		return "", fmt.Errorf("文件所在目录 %s 不在下载目录 %s 内", baseDir, root)
+	}
+	return baseDir, nil
+}
+
+// checkWritable 删除文件前检查是否处于只读模式
+func (a *BatchArchiver) checkWritable(operation string) error {
+	return contracts.CheckWritable(a.config != nil && a.config.Server.ReadOnly, operation)
+}
+
+// itemState 判断批次中文件的下载结果，完成时返回本地文件路径
+// 触发事件的任务直接使用事件中的状态，其他任务优先读取下载历史，没有历史记录时查询 aria2
+func (a *BatchArchiver) itemState(ctx context.Context, item entities.DownloadBatchItem, current contracts.DownloadResponse) (batchItemState, string) {
+	if item.GID == "" {
+		return batchItemFailed, ""
+	}
+
+	status, directory, filename := current.Status, current.Directory, current.Filename
+	if item.GID != current.ID {
+		if record, ok := a.lookupHistory(item.GID); ok {
+			status, directory, filename = record.Status, record.Directory, record.Filename
+		} else if download, err := a.downloadService.GetDownload(ctx, item.GID); err == nil {
+			status, directory, filename = download.Status, download.Directory, download.Filename
+		} else {
+			// 任务结果已被清除且没有历史记录，无法判断是否完成
+			logger.Debug("Failed to get batch item status", "gid", item.GID, "error", err)
+			return batchItemFailed, ""
+		}
+	}
+
+	switch status {
+	case valueobjects.DownloadStatusComplete:
+		return batchItemComplete, filepath.Join(directory, filename)
+	case valueobjects.DownloadStatusError, valueobjects.DownloadStatusRemoved:
+		return batchItemFailed, ""
+	default:
+		return batchItemPending, ""
+	}
+}
+
+// lookupHistory 从下载历史读取任务记录
+func (a *BatchArchiver) lookupHistory(gid string) (*entities.DownloadRecord, bool) {
+	if a.history == nil {
+		return nil, false
+	}
+	return a.history.GetByID(gid)
+}
+
+// saveArchive 保存打包结果
+func (a *BatchArchiver) saveArchive(batchID string, archive entities.DownloadBatchArchive) {
+	if err := a.batches.SetArchive(batchID, archive); err != nil {
+		logger.Warn("Failed to save batch archive result", "batchID", batchID, "error", err)
+	}
+}
+
+// notify 发送打包结果通知
+func (a *BatchArchiver) notify(ctx context.Context, level contracts.NotificationLevel, event, message string) {
+	if a.notificationService == nil {
+		return
+	}
+	if err := a.notificationService.NotifySystemEvent(ctx, contracts.SystemNotificationRequest{
+		Component: "download",
+		Event:     event,
+		Level:     level,
+		Message:   message,
+	}); err != nil {
+		logger.Warn("Failed to send batch archive notification", "event", event, "error", err)
+	}
+}
+
+// commonParentDir 返回所有文件共同的上级目录，压缩包保存在该目录并以其为包内路径的根
+func commonParentDir(files []string) string {
+	common := filepath.Dir(files[0])
+	for _, file := range files[1:] {
+		dir := filepath.Dir(file)
+		for common != dir && !strings.HasPrefix(dir, common+string(filepath.Separator)) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				break
+			}
+			common = parent
+		}
+	}
+	return common
+}
+
+// shortBatchID 返回批次ID前8位，用于文件名和通知
+func shortBatchID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
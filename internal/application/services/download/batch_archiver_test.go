@@ -0,0 +1,103 @@
+package download
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
+)
+
+func TestCommonParentDir(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"single file", []string{"/downloads/tvs/a.mkv"}, "/downloads/tvs"},
+		{"same directory", []string{"/downloads/tvs/a.mkv", "/downloads/tvs/b.mkv"}, "/downloads/tvs"},
+		{"sibling directories", []string{"/downloads/tvs/s1/a.mkv", "/downloads/tvs/s2/b.mkv"}, "/downloads/tvs"},
+		{"nested directory", []string{"/downloads/a.mkv", "/downloads/tvs/b.mkv"}, "/downloads"},
+		{"similar prefix", []string{"/downloads/tv/a.mkv", "/downloads/tvs/b.mkv"}, "/downloads"},
+		{"no common directory", []string{"/a/x.mkv", "/b/y.mkv"}, "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commonParentDir(tt.files); got != tt.want {
+				t.Errorf("commonParentDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchiveBaseDir(t *testing.T) {
+	tests := []struct {
+		name        string
+		downloadDir string
+		files       []string
+		want        string
+		wantErr     bool
+	}{
+		{"inside download dir", "/downloads", []string{"/downloads/tvs/a.mkv", "/downloads/tvs/b.mkv"}, "/downloads/tvs", false},
+		{"download dir itself", "/downloads/", []string{"/downloads/a.mkv", "/downloads/tvs/b.mkv"}, "/downloads", false},
+		{"common dir falls back to root", "/downloads", []string{"/downloads/a.mkv", "/media/b.mkv"}, "", true},
+		{"outside download dir", "/downloads", []string{"/media/tvs/a.mkv"}, "", true},
+		{"similar prefix", "/downloads", []string{"/downloads2/a.mkv"}, "", true},
+		{"download dir not configured", "", []string{"/downloads/a.mkv"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Aria2.DownloadDir = tt.downloadDir
+			a := NewBatchArchiver(cfg, nil, nil, nil, nil)
+
+			got, err := a.archiveBaseDir(tt.files)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("archiveBaseDir() = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateZipArchive(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "sub", "b.txt")}
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(filepath.Base(file)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archivePath := filepath.Join(dir, "batch.zip")
+	if err := filesystem.CreateZipArchive(archivePath, dir, files); err != nil {
+		t.Fatalf("CreateZipArchive() error = %v", err)
+	}
+
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var names []string
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+	if len(names) != 2 || names[0] != "a.txt" || names[1] != "sub/b.txt" {
+		t.Errorf("archive entries = %v, want [a.txt sub/b.txt]", names)
+	}
+
+	// 源文件缺失时不留下不完整的压缩包
+	missingPath := filepath.Join(dir, "missing.zip")
+	if err := filesystem.CreateZipArchive(missingPath, dir, []string{filepath.Join(dir, "none.txt")}); err == nil {
+		t.Fatal("CreateZipArchive() expected error for missing file")
+	}
+	if _, err := os.Stat(missingPath + ".part"); !os.IsNotExist(err) {
+		t.Errorf("partial archive left behind: %v", err)
+	}
+}
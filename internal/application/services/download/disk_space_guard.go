@@ -0,0 +1,187 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
+)
+
+const (
+	// defaultDiskCheckInterval 默认磁盘空间检查间隔
+	defaultDiskCheckInterval = 60 * time.Second
+	// diskFullNotifyCooldown 磁盘写满提醒的最短间隔，多个任务同时失败时只提醒一次
+	diskFullNotifyCooldown = 10 * time.Minute
+	// diskFreedMinBytes 磁盘写满后，可用空间比失败时至少多出该值才视为已释放
+	diskFreedMinBytes int64 = 1 << 30
+)
+
+// DiskSpaceGuard 磁盘空间保护 - 识别磁盘写满导致的下载失败并给出处理建议，
+// 可用空间低于阈值时自动暂停全部下载，空间释放后提示恢复
+type DiskSpaceGuard struct {
+	downloadDir         string
+	minFree             int64 // 自动暂停阈值（字节），0 为不自动暂停
+	interval            time.Duration
+	downloadService     contracts.DownloadService
+	notificationService contracts.NotificationService
+	freeSpace           func(path string) (int64, error)
+
+	mu            sync.Mutex
+	lowSpace      bool      // 磁盘已写满或低于阈值，等待空间释放
+	releasedAt    int64     // 可用空间达到该值（字节）时视为已释放
+	pausedByGuard bool      // 全部下载是由本保护自动暂停的
+	lastNotify    time.Time // 最近一次磁盘写满提醒时间
+	suppressed    int       // 冷却期内因磁盘写满失败、未单独提醒的任务数
+	startOnce     sync.Once
+}
+
+// NewDiskSpaceGuard 创建磁盘空间保护，检查 aria2.download_dir 所在的文件系统
+func NewDiskSpaceGuard(cfg *config.Config, downloadService contracts.DownloadService, notificationService contracts.NotificationService) *DiskSpaceGuard {
+	interval := time.Duration(cfg.Download.DiskGuard.CheckInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultDiskCheckInterval
+	}
+	return &DiskSpaceGuard{
+		downloadDir:         cfg.Aria2.DownloadDir,
+		minFree:             int64(cfg.Download.DiskGuard.MinFreeGB) << 30,
+		interval:            interval,
+		downloadService:     downloadService,
+		notificationService: notificationService,
+		freeSpace:           filesystem.AvailableSpace,
+	}
+}
+
+// Start 启动后台空间检查（重复调用无效）；下载目录不在本机时不启动
+func (g *DiskSpaceGuard) Start() {
+	if _, err := g.freeSpace(g.downloadDir); err != nil {
+		logger.Warn("Disk space guard disabled, download directory not accessible", "dir", g.downloadDir, "error", err)
+		return
+	}
+	g.startOnce.Do(func() {
+		go g.run()
+		logger.Info("Disk space guard started", "dir", g.downloadDir, "minFreeBytes", g.minFree, "interval", g.interval)
+	})
+}
+
+// run 检查主循环
+func (g *DiskSpaceGuard) run() {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		g.check(context.Background())
+	}
+}
+
+// HandleEvent 处理下载事件（实现 contracts.DownloadEventListener），只关注因磁盘空间不足失败的任务
+func (g *DiskSpaceGuard) HandleEvent(ctx context.Context, event contracts.DownloadEvent) {
+	if event.Type != contracts.DownloadEventFailed || !event.Download.DiskFull {
+		return
+	}
+
+	free, freeErr := g.freeSpace(g.downloadDir)
+
+	g.mu.Lock()
+	g.lowSpace = true
+	g.releasedAt = max(g.releasedAt, g.minFree, free+diskFreedMinBytes)
+	if time.Since(g.lastNotify) < diskFullNotifyCooldown {
+		g.suppressed++
+		g.mu.Unlock()
+		logger.Warn("Download failed: disk full (notification suppressed)", "gid", event.Download.ID, "file", event.Download.Filename)
+		return
+	}
+	g.lastNotify = time.Now()
+	suppressed := g.suppressed
+	g.suppressed = 0
+	g.mu.Unlock()
+
+	logger.Error("Download failed: disk full", "gid", event.Download.ID, "file", event.Download.Filename, "error", event.Download.ErrorMessage)
+
+	message := fmt.Sprintf("磁盘空间不足，任务 %s 下载失败", event.Download.Filename)
+	if suppressed > 0 {
+		message += fmt.Sprintf("（此前还有 %d 个任务因同样原因失败）", suppressed)
+	}
+	if freeErr != nil {
+		message += fmt.Sprintf("；无法获取 %s 的可用空间（aria2 可能不在本机）", g.downloadDir)
+	} else {
+		message += fmt.Sprintf("；%s 当前可用空间 %s", g.downloadDir, strutil.FormatFileSize(free))
+	}
+	message += "。请清理下载磁盘，空间释放后会再次提醒，届时可用 /retryfailed 重试失败的批量下载"
+	g.notify(ctx, contracts.NotificationLevelError, "disk_full", message)
+}
+
+// check 检查可用空间：低于阈值时暂停全部下载，空间释放后提示恢复
+func (g *DiskSpaceGuard) check(ctx context.Context) {
+	free, err := g.freeSpace(g.downloadDir)
+	if err != nil {
+		logger.Debug("Disk space check failed", "dir", g.downloadDir, "error", err)
+		return
+	}
+
+	g.mu.Lock()
+	switch {
+	case g.minFree > 0 && free < g.minFree && !g.pausedByGuard:
+		g.pausedByGuard = true
+		g.lowSpace = true
+		g.releasedAt = max(g.releasedAt, g.minFree)
+		g.mu.Unlock()
+		g.pauseAll(ctx, free)
+
+	case g.lowSpace && free >= g.releasedAt:
+		paused := g.pausedByGuard
+		g.lowSpace = false
+		g.pausedByGuard = false
+		g.releasedAt = 0
+		g.mu.Unlock()
+
+		logger.Info("Disk space freed", "dir", g.downloadDir, "free", free)
+		message := fmt.Sprintf("下载磁盘空间已释放，当前可用 %s", strutil.FormatFileSize(free))
+		if paused {
+			message += "。发送 /resumeall 恢复自动暂停的下载"
+		} else {
+			message += "。可用 /retryfailed 重试因空间不足失败的批量下载"
+		}
+		g.notify(ctx, contracts.NotificationLevelInfo, "disk_freed", message)
+
+	default:
+		g.mu.Unlock()
+	}
+}
+
+// pauseAll 可用空间低于阈值时暂停全部下载并提醒
+func (g *DiskSpaceGuard) pauseAll(ctx context.Context, free int64) {
+	count, err := g.downloadService.PauseAllDownloads(ctx)
+	if err != nil {
+		logger.Error("Failed to pause downloads on low disk space", "free", free, "error", err)
+		g.mu.Lock()
+		g.pausedByGuard = false // 下次检查时重试
+		g.mu.Unlock()
+		return
+	}
+
+	logger.Warn("Low disk space, all downloads paused", "dir", g.downloadDir, "free", free, "paused", count)
+	g.notify(ctx, contracts.NotificationLevelWarning, "disk_low",
+		fmt.Sprintf("下载磁盘可用空间 %s 低于 %s，已自动暂停全部 %d 个下载。请清理磁盘，空间释放后会再次提醒",
+			strutil.FormatFileSize(free), strutil.FormatFileSize(g.minFree), count))
+}
+
+// notify 发送磁盘空间通知
+func (g *DiskSpaceGuard) notify(ctx context.Context, level contracts.NotificationLevel, event, message string) {
+	if g.notificationService == nil {
+		return
+	}
+	if err := g.notificationService.NotifySystemEvent(ctx, contracts.SystemNotificationRequest{
+		Component: "aria2",
+		Event:     event,
+		Level:     level,
+		Message:   message,
+	}); err != nil {
+		logger.Warn("Failed to send disk space notification", "event", event, "error", err)
+	}
+}
@@ -0,0 +1,41 @@
+package download
+
+import (
+	"context"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+)
+
+func TestDiskSpaceGuardRelease(t *testing.T) {
+	free := int64(100 << 20)
+	guard := &DiskSpaceGuard{
+		downloadDir: "/downloads",
+		freeSpace:   func(string) (int64, error) { return free, nil },
+	}
+	ctx := context.Background()
+
+	// Failures for other reasons are ignored
+	guard.HandleEvent(ctx, contracts.DownloadEvent{Type: contracts.DownloadEventFailed, Download: contracts.DownloadResponse{ErrorCode: "3"}})
+	if guard.lowSpace {
+		t.Fatal("non disk-full failure marked low space")
+	}
+
+	guard.HandleEvent(ctx, contracts.DownloadEvent{Type: contracts.DownloadEventFailed, Download: contracts.DownloadResponse{DiskFull: true}})
+	if !guard.lowSpace || guard.releasedAt != free+diskFreedMinBytes {
+		t.Fatalf("after disk-full failure: lowSpace=%v releasedAt=%d", guard.lowSpace, guard.releasedAt)
+	}
+
+	// A little more space is not enough to count as released
+	free += 10 << 20
+	guard.check(ctx)
+	if !guard.lowSpace {
+		t.Fatal("released before enough space was freed")
+	}
+
+	free += diskFreedMinBytes
+	guard.check(ctx)
+	if guard.lowSpace || guard.releasedAt != 0 {
+		t.Fatalf("after freeing space: lowSpace=%v releasedAt=%d", guard.lowSpace, guard.releasedAt)
+	}
+}
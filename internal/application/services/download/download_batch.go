@@ -0,0 +1,205 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// SetDownloadBatches 设置批量下载记录存储（用于批量重试失败的文件）
+func (s *AppDownloadService) SetDownloadBatches(batches *repository.DownloadBatchRepository) {
+	s.batches = batches
+}
+
+// SetDirectoryCheckpoints 设置目录下载断点存储（批量下载中每提交一个文件记录一次）
+func (s *AppDownloadService) SetDirectoryCheckpoints(checkpoints *repository.DirectoryCheckpointRepository) {
+	s.checkpoints = checkpoints
+}
+
+// markCheckpoint 记录断点中一批已成功提交的源文件，失败只记录日志
+func (s *AppDownloadService) markCheckpoint(checkpointID string, sourcePaths []string) {
+	if s.checkpoints == nil || checkpointID == "" || len(sourcePaths) == 0 {
+		return
+	}
+	if err := s.checkpoints.MarkQueued(checkpointID, sourcePaths); err != nil {
+		logger.Warn("Failed to save directory download checkpoint", "checkpoint", checkpointID, "files", len(sourcePaths), "error", err)
+	}
+}
+
+// recordBatch 保存批量下载的原始请求、打包设置和创建结果，返回批量记录ID（未配置存储时返回空）
+func (s *AppDownloadService) recordBatch(req contracts.BatchDownloadRequest, results []contracts.DownloadResult) string {
+	if s.batches == nil || len(results) == 0 {
+		if req.Archive {
+			logger.Warn("Batch archive requested but download batch history not available, skipping archive")
+		}
+		return ""
+	}
+
+	batch := &entities.DownloadBatch{Items: make([]entities.DownloadBatchItem, 0, len(results))}
+	if req.Archive {
+		batch.Archive = &entities.DownloadBatchArchive{RemoveOriginals: req.ArchiveRemoveOriginals}
+	}
+	for _, result := range results {
+		item := batchItemFromRequest(result.Request)
+		applyBatchItemResult(&item, result)
+		batch.Items = append(batch.Items, item)
+	}
+
+	if err := s.batches.Save(batch); err != nil {
+		logger.Warn("Failed to save download batch", "error", err)
+		return ""
+	}
+	return batch.ID
+}
+
+// RetryFailedBatch 重新提交批次中所有失败的文件
+// 失败包括创建任务失败和 aria2 下载出错；同一源文件已有完成或进行中的下载时跳过
+func (s *AppDownloadService) RetryFailedBatch(ctx context.Context, batchID string) (*contracts.BatchRetryResult, error) {
+	if s.batches == nil {
+		return nil, fmt.Errorf("download batch history not available")
+	}
+
+	batch, ok := s.batches.GetLatest()
+	if batchID != "" {
+		batch, ok = s.batches.GetByID(batchID)
+	}
+	if !ok {
+		return nil, contracts.ErrBatchNotFound
+	}
+
+	window := time.Duration(s.config.Download.BatchRetryWindowHours) * time.Hour
+	if window > 0 && time.Since(batch.CreatedAt) > window {
+		return nil, fmt.Errorf("%w（创建于 %s）", contracts.ErrBatchRetryExpired, batch.CreatedAt.Format("2006-01-02 15:04"))
+	}
+
+	result := &contracts.BatchRetryResult{BatchID: batch.ID}
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		if !s.isBatchItemFailed(item) {
+			continue
+		}
+		result.FailedCount++
+
+		if s.hasSucceededOrActiveDownload(item) {
+			result.SkippedCount++
+			continue
+		}
+
+		retryResult := s.RetryBatchItem(ctx, item)
+		if retryResult.Success {
+			result.SuccessCount++
+		} else {
+			result.FailureCount++
+		}
+		result.Results = append(result.Results, retryResult)
+	}
+
+	if len(result.Results) > 0 {
+		if err := s.batches.Save(batch); err != nil {
+			logger.Warn("Failed to update download batch after retry", "batchID", batch.ID, "error", err)
+		}
+	}
+
+	logger.Info("Batch failures retried",
+		"batchID", batch.ID,
+		"failed", result.FailedCount,
+		"skipped", result.SkippedCount,
+		"success", result.SuccessCount,
+		"failure", result.FailureCount)
+	return result, nil
+}
+
+// isBatchItemFailed 判断批次中的文件是否失败：创建失败，或已创建的任务下载出错
+func (s *AppDownloadService) isBatchItemFailed(item *entities.DownloadBatchItem) bool {
+	if item.GID == "" {
+		return item.Error != ""
+	}
+
+	if s.history != nil {
+		if record, ok := s.history.GetByID(item.GID); ok {
+			return record.Status == valueobjects.DownloadStatusError
+		}
+	}
+
+	status, err := s.aria2Client.GetStatus(item.GID)
+	if err != nil {
+		// 任务结果已被清除且没有历史记录，无法判断是否失败
+		logger.Debug("Failed to get batch item status", "gid", item.GID, "error", err)
+		return false
+	}
+	return s.convertAriaStatus(status.Status) == valueobjects.DownloadStatusError
+}
+
+// hasSucceededOrActiveDownload 同一源文件是否已有完成或进行中的下载（例如已单独重试过）
+func (s *AppDownloadService) hasSucceededOrActiveDownload(item *entities.DownloadBatchItem) bool {
+	if s.history == nil || item.SourcePath == "" {
+		return false
+	}
+	for _, record := range s.history.GetBySourcePath(item.SourcePath) {
+		switch record.Status {
+		case valueobjects.DownloadStatusComplete, valueobjects.DownloadStatusActive,
+			valueobjects.DownloadStatusPending, valueobjects.DownloadStatusPaused:
+			return true
+		}
+	}
+	return false
+}
+
+// RetryBatchItem 按记录重新提交一个文件（有源文件路径时刷新下载链接），并将结果写回记录
+func (s *AppDownloadService) RetryBatchItem(ctx context.Context, item *entities.DownloadBatchItem) contracts.DownloadResult {
+	req := s.batchItemRequest(ctx, item)
+	download, err := s.CreateDownload(ctx, req)
+	result := contracts.DownloadResult{Request: req, Success: err == nil, Download: download}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	applyBatchItemResult(item, result)
+	return result
+}
+
+// batchItemRequest 根据批次记录重建下载请求，有源文件路径时刷新下载链接（Alist 签名链接可能已过期）
+func (s *AppDownloadService) batchItemRequest(ctx context.Context, item *entities.DownloadBatchItem) contracts.DownloadRequest {
+	return s.refreshDownloadURL(ctx, contracts.DownloadRequest{
+		URL:                 item.URL,
+		Filename:            item.Filename,
+		Directory:           item.Directory,
+		SourcePath:          item.SourcePath,
+		FileSize:            item.FileSize,
+		VideoOnly:           item.VideoOnly,
+		AutoClassify:        item.AutoClassify,
+		DeleteAfterDownload: item.DeleteAfterDownload,
+		TaskID:              item.TaskID,
+	})
+}
+
+// batchItemFromRequest 从下载请求构建批次记录项
+func batchItemFromRequest(req contracts.DownloadRequest) entities.DownloadBatchItem {
+	return entities.DownloadBatchItem{
+		URL:                 req.URL,
+		Filename:            req.Filename,
+		Directory:           req.Directory,
+		SourcePath:          req.SourcePath,
+		FileSize:            req.FileSize,
+		VideoOnly:           req.VideoOnly,
+		AutoClassify:        req.AutoClassify,
+		DeleteAfterDownload: req.DeleteAfterDownload,
+		TaskID:              req.TaskID,
+	}
+}
+
+// applyBatchItemResult 记录最近一次创建结果
+func applyBatchItemResult(item *entities.DownloadBatchItem, result contracts.DownloadResult) {
+	if result.Success && result.Download != nil {
+		item.GID = result.Download.ID
+		item.Error = ""
+		return
+	}
+	item.GID = ""
+	item.Error = result.Error
+}
@@ -0,0 +1,65 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// CancelDownloads 逐个取消任务，单个任务失败时继续取消其余任务，并区分“不存在”和“取消失败”
+func (s *AppDownloadService) CancelDownloads(ctx context.Context, ids []string) *contracts.BatchCancelResult {
+	result := &contracts.BatchCancelResult{Items: make([]contracts.BatchCancelItem, 0, len(ids))}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		item := contracts.BatchCancelItem{ID: id, Status: contracts.CancelStatusCancelled}
+		if err := s.CancelDownload(ctx, id); err != nil {
+			item.Error = err.Error()
+			if errors.Is(err, contracts.ErrDownloadNotFound) {
+				item.Status = contracts.CancelStatusNotFound
+				result.NotFound++
+			} else {
+				item.Status = contracts.CancelStatusFailed
+				result.Failed++
+			}
+		} else {
+			result.Cancelled++
+		}
+		result.Items = append(result.Items, item)
+	}
+	result.Total = len(result.Items)
+
+	logger.Info("Batch cancel completed", "total", result.Total, "cancelled", result.Cancelled,
+		"notFound", result.NotFound, "failed", result.Failed)
+	return result
+}
+
+// CancelAllDownloads 取消所有活动和等待中的任务，已暂停的任务保留
+func (s *AppDownloadService) CancelAllDownloads(ctx context.Context) (*contracts.BatchCancelResult, error) {
+	active, err := s.aria2Client.GetActive()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active downloads: %w", s.health.WrapError(err))
+	}
+	waiting, err := s.aria2Client.GetWaiting(0, maxQueueScan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get waiting downloads: %w", s.health.WrapError(err))
+	}
+
+	ids := make([]string, 0, len(active)+len(waiting))
+	for _, d := range active {
+		ids = append(ids, d.GID)
+	}
+	for _, d := range waiting {
+		if d.Status != "paused" {
+			ids = append(ids, d.GID)
+		}
+	}
+	return s.CancelDownloads(ctx, ids), nil
+}
@@ -0,0 +1,197 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// defaultChunkConcurrency aria2 同时下载数未知时使用的默认值（与 aria2 的 max-concurrent-downloads 默认值一致）
+	defaultChunkConcurrency = 5
+	// maxQueueCheckFailures 连续查询下载队列失败达到该次数后放弃提交剩余批次
+	maxQueueCheckFailures = 10
+)
+
+// chunkPollInterval 分批提交时检查下载队列是否空出的间隔
+var chunkPollInterval = 30 * time.Second
+
+// errChunkSubmissionStopped 服务关闭，后台分批提交停止（断点保留，重启后可继续）
+var errChunkSubmissionStopped = errors.New("download service is shutting down")
+
+// SetNotificationService 设置通知服务（用于分批提交的进度通知）
+func (s *AppDownloadService) SetNotificationService(notificationService contracts.NotificationService) {
+	s.notifier = notificationService
+}
+
+// Shutdown 停止后台分批提交（进程退出时调用），未提交的文件保留在目录下载断点中
+func (s *AppDownloadService) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		if s.shutdown != nil {
+			close(s.shutdown)
+		}
+	})
+}
+
+// submitRemainingChunks 在后台逐批提交剩余文件：下载队列（进行中+等待中）少于同时下载数后再提交下一批
+// 全部提交后完成目录下载断点；服务关闭时停止并保留断点，可从断点继续
+// 下载队列持续无法查询时放弃剩余文件并记为失败，可通过 /retryfailed 重试
+func (s *AppDownloadService) submitRemainingChunks(ctx context.Context, req contracts.BatchDownloadRequest, batchID string, pending []contracts.DownloadRequest) {
+	total := len(req.Items)
+	submitted := total - len(pending)
+	chunkCount := (total + req.ChunkSize - 1) / req.ChunkSize
+	failed := 0
+
+	for chunk := 2; len(pending) > 0; chunk++ {
+		if err := s.waitForQueueSlot(); err != nil {
+			if errors.Is(err, errChunkSubmissionStopped) {
+				logger.Info("Batch chunk submission stopped, checkpoint kept for resume", "source", req.SourceDir, "remaining", len(pending))
+				return
+			}
+			logger.Error("Giving up batch chunk submission", "source", req.SourceDir, "remaining", len(pending), "error", err)
+			s.failPendingItems(req, batchID, pending, err)
+			message := fmt.Sprintf("%s：无法查询下载队列，已停止分批提交，剩余 %d 个文件记为失败", req.SourceDir, len(pending))
+			if batchID != "" {
+				message += fmt.Sprintf("，可用 /retryfailed %s 重试", batchID)
+			}
+			s.notifyChunk(ctx, req, contracts.NotificationLevelError, message)
+			break
+		}
+
+		items := pending[:min(req.ChunkSize, len(pending))]
+		pending = pending[len(items):]
+		// 前一批下载期间链接可能已过期，提交前刷新
+		for i := range items {
+			items[i] = s.refreshDownloadURL(ctx, items[i])
+		}
+
+		results, _, _, failureCount := s.submitBatchItems(ctx, req, items)
+		s.appendBatchResults(batchID, results)
+		submitted += len(items)
+		failed += failureCount
+
+		logger.Info("Batch chunk submitted", "source", req.SourceDir, "chunk", chunk, "chunks", chunkCount, "submitted", submitted, "total", total, "failed", failureCount)
+		message := fmt.Sprintf("%s：已提交第 %d/%d 批，共 %d/%d 个文件", req.SourceDir, chunk, chunkCount, submitted, total)
+		if len(pending) == 0 {
+			message = fmt.Sprintf("%s：全部 %d 批共 %d 个文件已提交", req.SourceDir, chunkCount, total)
+			if failed > 0 && batchID != "" {
+				message += fmt.Sprintf("，其中 %d 个创建失败，可用 /retryfailed %s 重试", failed, batchID)
+			}
+		}
+		s.notifyChunk(ctx, req, contracts.NotificationLevelInfo, message)
+	}
+
+	if s.checkpoints != nil && req.CheckpointID != "" {
+		if err := s.checkpoints.Complete(req.CheckpointID); err != nil {
+			logger.Warn("Failed to remove directory download checkpoint", "checkpoint", req.CheckpointID, "error", err)
+		}
+	}
+}
+
+// waitForQueueSlot 等待下载队列中进行中和等待中的任务少于同时下载数
+// 服务关闭时返回 errChunkSubmissionStopped，连续 maxQueueCheckFailures 次查询失败时返回最后一次错误
+func (s *AppDownloadService) waitForQueueSlot() error {
+	limit := s.maxConcurrentDownloads()
+	if limit <= 0 {
+		limit = defaultChunkConcurrency
+	}
+	failures := 0
+	for {
+		running, _, err := s.countQueue()
+		if err != nil {
+			failures++
+			logger.Debug("Failed to check download queue for next chunk", "attempt", failures, "error", err)
+			if failures >= maxQueueCheckFailures {
+				return fmt.Errorf("failed to check download queue %d times: %w", failures, err)
+			}
+		} else if running < limit {
+			return nil
+		} else {
+			failures = 0
+		}
+
+		select {
+		case <-s.shutdown:
+			return errChunkSubmissionStopped
+		case <-time.After(chunkPollInterval):
+		}
+	}
+}
+
+// failPendingItems 将放弃提交的文件记为失败追加到批量下载记录
+func (s *AppDownloadService) failPendingItems(req contracts.BatchDownloadRequest, batchID string, pending []contracts.DownloadRequest, err error) {
+	results := make([]contracts.DownloadResult, 0, len(pending))
+	for _, item := range pending {
+		results = append(results, contracts.DownloadResult{
+			Request: applyBatchSettings(req, item),
+			Error:   err.Error(),
+		})
+	}
+	s.appendBatchResults(batchID, results)
+}
+
+// refreshDownloadURL 按源文件路径重新获取下载链接，失败时沿用原链接
+func (s *AppDownloadService) refreshDownloadURL(ctx context.Context, req contracts.DownloadRequest) contracts.DownloadRequest {
+	if req.SourcePath == "" || s.fileService == nil {
+		return req
+	}
+
+	fileInfo, err := s.fileService.GetFileInfo(ctx, req.SourcePath)
+	if err != nil {
+		logger.Warn("Failed to refresh download URL, using stored URL", "path", req.SourcePath, "error", err)
+		return req
+	}
+	if fileInfo.InternalURL != "" {
+		req.URL = fileInfo.InternalURL
+	}
+	return req
+}
+
+// appendBatchResults 将后续批次的结果追加到批量下载记录，使 /retryfailed 覆盖全部批次
+func (s *AppDownloadService) appendBatchResults(batchID string, results []contracts.DownloadResult) {
+	if s.batches == nil || batchID == "" || len(results) == 0 {
+		return
+	}
+	items := make([]entities.DownloadBatchItem, 0, len(results))
+	for _, result := range results {
+		item := batchItemFromRequest(result.Request)
+		applyBatchItemResult(&item, result)
+		items = append(items, item)
+	}
+	if err := s.batches.AppendItems(batchID, items); err != nil {
+		logger.Warn("Failed to append chunk to download batch", "batchID", batchID, "error", err)
+	}
+}
+
+// notifyChunk 发送分批提交进度通知：发给发起下载的聊天，没有时（如 HTTP 调用）作为系统事件通知
+func (s *AppDownloadService) notifyChunk(ctx context.Context, req contracts.BatchDownloadRequest, level contracts.NotificationLevel, message string) {
+	if s.notifier == nil {
+		return
+	}
+	var err error
+	if req.NotifyChatID != 0 {
+		_, err = s.notifier.SendNotification(ctx, contracts.NotificationRequest{
+			Channel:  contracts.ChannelTelegram,
+			Level:    level,
+			Title:    "分批下载",
+			Message:  message,
+			TargetID: strconv.FormatInt(req.NotifyChatID, 10),
+		})
+	} else {
+		err = s.notifier.NotifySystemEvent(ctx, contracts.SystemNotificationRequest{
+			Component: "aria2",
+			Event:     "batch_chunk",
+			Level:     level,
+			Message:   message,
+		})
+	}
+	if err != nil {
+		logger.Warn("Failed to send batch chunk notification", "error", err)
+	}
+}
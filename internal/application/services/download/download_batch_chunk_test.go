@@ -0,0 +1,195 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+)
+
+// recordingNotifier 记录分批提交发出的通知
+type recordingNotifier struct {
+	contracts.NotificationService
+	mu       sync.Mutex
+	targets  []string
+	messages []string
+}
+
+func (n *recordingNotifier) SendNotification(ctx context.Context, req contracts.NotificationRequest) (*contracts.NotificationResponse, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.targets = append(n.targets, req.TargetID)
+	n.messages = append(n.messages, req.Message)
+	return &contracts.NotificationResponse{}, nil
+}
+
+func (n *recordingNotifier) NotifySystemEvent(ctx context.Context, req contracts.SystemNotificationRequest) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.targets = append(n.targets, "system")
+	n.messages = append(n.messages, req.Message)
+	return nil
+}
+
+func TestSubmitRemainingChunks(t *testing.T) {
+	oldInterval := chunkPollInterval
+	chunkPollInterval = time.Millisecond
+	defer func() { chunkPollInterval = oldInterval }()
+
+	tests := []struct {
+		name          string
+		queueFails    bool // 第一批之后查询下载队列一直失败
+		wantAdded     int
+		wantFailed    int
+		wantLastMatch string
+	}{
+		{name: "逐批提交全部文件", wantAdded: 5, wantLastMatch: "全部 3 批共 5 个文件已提交"},
+		{name: "下载队列持续不可用时放弃剩余文件", queueFails: true, wantAdded: 2, wantFailed: 3, wantLastMatch: "剩余 3 个文件记为失败"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req aria2.RPCRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				var result any
+				switch req.Method {
+				case "aria2.addUri":
+					result = fmt.Sprintf("gid%d", added.Add(1))
+				case "aria2.getGlobalOption":
+					result = map[string]string{"max-concurrent-downloads": "5"}
+				case "aria2.tellActive", "aria2.tellWaiting":
+					if tt.queueFails {
+						_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "error": map[string]any{"code": 1, "message": "internal error"}})
+						return
+					}
+					result = []any{}
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": result})
+			}))
+			defer server.Close()
+
+			batches, err := repository.NewDownloadBatchRepository(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := &config.Config{}
+			cfg.Aria2.RpcURL = server.URL
+			cfg.Aria2.DownloadDir = "/downloads"
+			s := NewAppDownloadService(cfg, nil).(*AppDownloadService)
+			s.SetDownloadBatches(batches)
+			notifier := &recordingNotifier{}
+			s.SetNotificationService(notifier)
+			defer s.Shutdown()
+
+			var items []contracts.DownloadRequest
+			for i := 1; i <= 5; i++ {
+				name := fmt.Sprintf("E%02d.mkv", i)
+				items = append(items, contracts.DownloadRequest{URL: "http://example.com/" + name, Filename: name, Directory: "/downloads/tvs"})
+			}
+			resp, err := s.CreateBatchDownload(context.Background(), contracts.BatchDownloadRequest{
+				Items:        items,
+				ChunkSize:    2,
+				SourceDir:    "/tvs/Show",
+				NotifyChatID: -100123,
+			})
+			if err != nil {
+				t.Fatalf("CreateBatchDownload() error = %v", err)
+			}
+			if resp.SuccessCount != 2 || resp.PendingCount != 3 || resp.ChunkCount != 3 {
+				t.Fatalf("response = success %d pending %d chunks %d, want 2, 3, 3", resp.SuccessCount, resp.PendingCount, resp.ChunkCount)
+			}
+
+			// 等待后台提交结束：批量记录包含全部文件
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				batch, _ := batches.GetByID(resp.BatchID)
+				if batch != nil && len(batch.Items) == len(items) {
+					failed := 0
+					for _, item := range batch.Items {
+						if item.GID == "" {
+							failed++
+						}
+					}
+					if failed != tt.wantFailed {
+						t.Errorf("failed items = %d, want %d", failed, tt.wantFailed)
+					}
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("background submission did not finish, batch = %+v", batch)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			if got := int(added.Load()); got != tt.wantAdded {
+				t.Errorf("aria2.addUri calls = %d, want %d", got, tt.wantAdded)
+			}
+
+			// 最后一条进度通知发给发起下载的聊天
+			deadline = time.Now().Add(5 * time.Second)
+			for {
+				notifier.mu.Lock()
+				n := len(notifier.messages)
+				var target, message string
+				if n > 0 {
+					target, message = notifier.targets[n-1], notifier.messages[n-1]
+				}
+				notifier.mu.Unlock()
+				if n > 0 && strings.Contains(message, tt.wantLastMatch) {
+					if target != "-100123" {
+						t.Errorf("notification target = %s, want -100123", target)
+					}
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("last notification = %q, want it to contain %q", message, tt.wantLastMatch)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestWaitForQueueSlotShutdown(t *testing.T) {
+	oldInterval := chunkPollInterval
+	chunkPollInterval = time.Hour
+	defer func() { chunkPollInterval = oldInterval }()
+
+	// 下载队列已满，只能等待关闭信号
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req aria2.RPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var result any = []any{map[string]any{"gid": "a", "status": "active"}}
+		if req.Method == "aria2.getGlobalOption" {
+			result = map[string]string{"max-concurrent-downloads": "1"}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": result})
+	}))
+	defer server.Close()
+
+	s := &AppDownloadService{aria2Client: aria2.NewClient(server.URL, ""), shutdown: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() { done <- s.waitForQueueSlot() }()
+
+	s.Shutdown()
+	select {
+	case err := <-done:
+		if err != errChunkSubmissionStopped {
+			t.Errorf("waitForQueueSlot() error = %v, want errChunkSubmissionStopped", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waitForQueueSlot() did not return after Shutdown")
+	}
+}
@@ -0,0 +1,116 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+)
+
+func TestRetryFailedBatch(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "download_history.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history, err := repository.NewDownloadHistoryRepository(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches, err := repository.NewDownloadBatchRepository(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Download.BatchRetryWindowHours = 24
+	s := &AppDownloadService{config: cfg, history: history, batches: batches}
+
+	for _, record := range []*entities.DownloadRecord{
+		{ID: "gid-ok", SourcePath: "/a/E01.mkv", Status: valueobjects.DownloadStatusComplete},
+		{ID: "gid-retried", SourcePath: "/a/E02.mkv", Status: valueobjects.DownloadStatusActive},
+	} {
+		if err := history.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expired := &entities.DownloadBatch{CreatedAt: time.Now().Add(-48 * time.Hour)}
+	if err := batches.Save(expired); err != nil {
+		t.Fatal(err)
+	}
+	batch := &entities.DownloadBatch{Items: []entities.DownloadBatchItem{
+		{SourcePath: "/a/E01.mkv", GID: "gid-ok"},
+		// 创建失败，但同一源文件已被单独重试并在下载中
+		{SourcePath: "/a/E02.mkv", Error: "aria2 当前不可用"},
+	}}
+	if err := batches.Save(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.RetryFailedBatch(context.Background(), "")
+	if err != nil {
+		t.Fatalf("RetryFailedBatch() error = %v", err)
+	}
+	if result.BatchID != batch.ID {
+		t.Errorf("BatchID = %s, want latest batch %s", result.BatchID, batch.ID)
+	}
+	if result.FailedCount != 1 || result.SkippedCount != 1 || len(result.Results) != 0 {
+		t.Errorf("result = %+v, want 1 failed file skipped without resubmitting", result)
+	}
+
+	tests := []struct {
+		name    string
+		batchID string
+		wantErr error
+	}{
+		{name: "批次不存在", batchID: "missing", wantErr: contracts.ErrBatchNotFound},
+		{name: "超过重试期限", batchID: expired.ID, wantErr: contracts.ErrBatchRetryExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.RetryFailedBatch(context.Background(), tt.batchID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RetryFailedBatch() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSummarizeBatch(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Aria2.DownloadDir = "/downloads"
+	s := &AppDownloadService{config: cfg}
+
+	req := contracts.BatchDownloadRequest{
+		Items: []contracts.DownloadRequest{
+			{URL: "http://alist/d/a.mkv", Filename: "Movie.2024.mkv", Directory: "/downloads/movies/Movie", FileSize: 100},
+			{URL: "http://alist/d/b.mkv", Filename: "Show.S01E01.mkv", Directory: "/downloads/tvs/Show/S01", FileSize: 50},
+			{URL: "http://alist/d/c.nfo", Filename: "Movie.2024.nfo", FileSize: 1},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		videoOnly bool
+		want      contracts.DownloadSummary
+	}{
+		{name: "全部文件", want: contracts.DownloadSummary{TotalFiles: 3, TotalSize: 151, VideoFiles: 2, MovieFiles: 1, TVFiles: 1, OtherFiles: 1}},
+		{name: "只下载视频时不计入其他文件", videoOnly: true, want: contracts.DownloadSummary{TotalFiles: 2, TotalSize: 150, VideoFiles: 2, MovieFiles: 1, TVFiles: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req.VideoOnly = tt.videoOnly
+			if got := s.SummarizeBatch(context.Background(), req); got != tt.want {
+				t.Errorf("SummarizeBatch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// aria2MaxConnectionPerServer aria2 允许的 max-connection-per-server 最大值
+	aria2MaxConnectionPerServer = 16
+	// defaultBoostMaxSplit 未配置 download.boost.max_split 时的分段数上限
+	defaultBoostMaxSplit = 32
+)
+
+// BoostDownload 在配置的上限内翻倍任务的 max-connection-per-server 和 split，返回修改前后的值
+// 修改进行中任务的选项时 aria2 会短暂重启该任务，已下载的数据保留；BT 任务的速度取决于对端，不做修改
+func (s *AppDownloadService) BoostDownload(ctx context.Context, id string) (*contracts.BoostResult, error) {
+	status, err := s.aria2Client.GetStatus(id)
+	if err != nil {
+		if errors.Is(err, aria2.ErrGIDNotFound) {
+			return nil, fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get download status: %w", s.health.WrapError(err))
+	}
+
+	download := s.convertToDownloadResponse(status)
+	switch download.Status {
+	case valueobjects.DownloadStatusActive, valueobjects.DownloadStatusPending, valueobjects.DownloadStatusPaused:
+	default:
+		return nil, fmt.Errorf("任务已结束（%s），无法加速", status.Status)
+	}
+	if status.InfoHash != "" {
+		return nil, fmt.Errorf("BT 任务的速度取决于做种的对端，不支持调整连接数和分段数")
+	}
+
+	options, err := s.aria2Client.GetOption(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get download options: %w", s.health.WrapError(err))
+	}
+
+	maxConnections, maxSplit := s.boostLimits()
+	result := &contracts.BoostResult{ID: id, Filename: download.Filename}
+	result.ConnectionsBefore, _ = strconv.Atoi(options["max-connection-per-server"])
+	result.SplitBefore, _ = strconv.Atoi(options["split"])
+	result.ConnectionsAfter = boostValue(result.ConnectionsBefore, maxConnections)
+	result.SplitAfter = boostValue(result.SplitBefore, maxSplit)
+
+	if result.ConnectionsAfter == result.ConnectionsBefore && result.SplitAfter == result.SplitBefore {
+		result.AtLimit = true
+		return result, nil
+	}
+
+	// aria2 的选项值必须为字符串
+	if err := s.aria2Client.ChangeOption(id, map[string]interface{}{
+		"max-connection-per-server": strconv.Itoa(result.ConnectionsAfter),
+		"split":                     strconv.Itoa(result.SplitAfter),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to change download options: %w", s.health.WrapError(err))
+	}
+
+	// 以 aria2 实际生效的值为准
+	if applied, err := s.aria2Client.GetOption(id); err == nil {
+		result.ConnectionsAfter, _ = strconv.Atoi(applied["max-connection-per-server"])
+		result.SplitAfter, _ = strconv.Atoi(applied["split"])
+	}
+
+	logger.Info("Download boosted", "id", id,
+		"connections", fmt.Sprintf("%d->%d", result.ConnectionsBefore, result.ConnectionsAfter),
+		"split", fmt.Sprintf("%d->%d", result.SplitBefore, result.SplitAfter))
+	return result, nil
+}
+
+// boostLimits 返回配置的连接数和分段数上限，连接数不超过 aria2 允许的最大值
+func (s *AppDownloadService) boostLimits() (maxConnections, maxSplit int) {
+	maxConnections, maxSplit = aria2MaxConnectionPerServer, defaultBoostMaxSplit
+	if s.config == nil {
+		return maxConnections, maxSplit
+	}
+	if limit := s.config.Download.Boost.MaxConnections; limit > 0 {
+		maxConnections = min(limit, aria2MaxConnectionPerServer)
+	}
+	if limit := s.config.Download.Boost.MaxSplit; limit > 0 {
+		maxSplit = limit
+	}
+	return maxConnections, maxSplit
+}
+
+// boostValue 将当前值翻倍，不超过上限；当前值已达到或超过上限时保持不变
+func boostValue(current, limit int) int {
+	current = max(current, 1)
+	if current >= limit {
+		return current
+	}
+	return min(current*2, limit)
+}
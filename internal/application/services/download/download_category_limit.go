@@ -0,0 +1,213 @@
+package download
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// categoryLimitInterval 分类并发检查间隔（创建任务后会立即检查一次）
+const categoryLimitInterval = 5 * time.Second
+
+// queuedTask 队列中未结束的任务
+type queuedTask struct {
+	gid      string
+	category string
+	paused   bool
+}
+
+// CategoryLimiter 分类并发限制 - 定期检查队列，超出分类上限的任务暂停等待，同分类有空位时按队列顺序恢复
+// 只恢复由限制暂停的任务，用户手动暂停的任务不受影响
+type CategoryLimiter struct {
+	aria2Client *aria2.Client
+	limits      map[string]int
+	categoryOf  func(dir string) string
+
+	mu        sync.Mutex
+	held      map[string]bool // 由限制暂停的 gid
+	trigger   chan struct{}
+	startOnce sync.Once
+}
+
+// NewCategoryLimiter 创建分类并发限制器，categoryOf 根据下载目录识别分类
+func NewCategoryLimiter(aria2Client *aria2.Client, limits map[string]int, categoryOf func(dir string) string) *CategoryLimiter {
+	return &CategoryLimiter{
+		aria2Client: aria2Client,
+		limits:      limits,
+		categoryOf:  categoryOf,
+		held:        make(map[string]bool),
+		trigger:     make(chan struct{}, 1),
+	}
+}
+
+// Start 启动后台检查（重复调用无效）
+func (l *CategoryLimiter) Start() {
+	l.startOnce.Do(func() {
+		go l.run()
+		logger.Info("Category limiter started", "limits", l.limits)
+	})
+}
+
+// Trigger 请求尽快检查一次（新任务入队后调用，避免超限任务先开始下载）
+func (l *CategoryLimiter) Trigger() {
+	select {
+	case l.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Release 放弃所有由限制暂停的任务（全部暂停后这些任务视为用户暂停，不再自动恢复）
+func (l *CategoryLimiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	clear(l.held)
+}
+
+// run 检查主循环
+func (l *CategoryLimiter) run() {
+	ticker := time.NewTicker(categoryLimitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-l.trigger:
+		}
+		l.enforce()
+	}
+}
+
+// enforce 读取队列并暂停超限的任务、恢复有空位的任务
+func (l *CategoryLimiter) enforce() {
+	tasks, err := l.queue()
+	if err != nil {
+		logger.Debug("Category limiter failed to read queue", "error", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 已不在暂停状态的任务（被取消、用户手动恢复）不再由限制管理
+	paused := make(map[string]bool)
+	for _, task := range tasks {
+		if task.paused {
+			paused[task.gid] = true
+		}
+	}
+	maps.DeleteFunc(l.held, func(gid string, _ bool) bool { return !paused[gid] })
+
+	pause, resume := planCategoryLimits(tasks, l.held, l.limits)
+	for _, gid := range pause {
+		if err := l.aria2Client.Pause(gid); err != nil {
+			logger.Warn("Failed to pause download over category limit", "id", gid, "error", err)
+			continue
+		}
+		l.held[gid] = true
+	}
+	for _, gid := range resume {
+		if err := l.aria2Client.Resume(gid); err != nil {
+			logger.Warn("Failed to resume download held by category limit", "id", gid, "error", err)
+			continue
+		}
+		delete(l.held, gid)
+	}
+	if len(pause) > 0 || len(resume) > 0 {
+		logger.Info("Category limits applied", "paused", len(pause), "resumed", len(resume), "held", len(l.held))
+	}
+}
+
+// queue 返回未结束的任务，活动任务在前，等待中的任务按队列顺序排列
+func (l *CategoryLimiter) queue() ([]queuedTask, error) {
+	active, err := l.aria2Client.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	waiting, err := l.aria2Client.GetWaiting(0, maxQueueScan)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]queuedTask, 0, len(active)+len(waiting))
+	for _, status := range slices.Concat(active, waiting) {
+		tasks = append(tasks, queuedTask{
+			gid:      status.GID,
+			category: l.categoryOf(status.Dir),
+			paused:   status.Status == "paused",
+		})
+	}
+	return tasks, nil
+}
+
+// Stats 统计各受限分类的活动、等待和限制暂停的任务数
+func (l *CategoryLimiter) Stats(active, waiting []aria2.StatusResult) []contracts.CategoryConcurrency {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[string]*contracts.CategoryConcurrency)
+	for category, limit := range l.limits {
+		if limit > 0 {
+			stats[category] = &contracts.CategoryConcurrency{Category: category, Limit: limit}
+		}
+	}
+	count := func(status aria2.StatusResult, isActive bool) {
+		stat, ok := stats[l.categoryOf(status.Dir)]
+		switch {
+		case !ok:
+		case isActive:
+			stat.Active++
+		case l.held[status.GID]:
+			stat.Held++
+		case status.Status != "paused":
+			stat.Waiting++
+		}
+	}
+	for _, status := range active {
+		count(status, true)
+	}
+	for _, status := range waiting {
+		count(status, false)
+	}
+
+	result := make([]contracts.CategoryConcurrency, 0, len(stats))
+	for _, category := range slices.Sorted(maps.Keys(stats)) {
+		result = append(result, *stats[category])
+	}
+	return result
+}
+
+// planCategoryLimits 计算需要暂停和恢复的任务
+// 按顺序为每个受限分类放行最多 limit 个未暂停的任务（活动任务优先），其余暂停；
+// 放行数未满的分类按队列顺序恢复由限制暂停的任务
+func planCategoryLimits(tasks []queuedTask, held map[string]bool, limits map[string]int) (pause, resume []string) {
+	admitted := make(map[string]int)
+	for _, task := range tasks {
+		limit := limits[task.category]
+		if task.paused || limit <= 0 {
+			continue
+		}
+		if admitted[task.category] < limit {
+			admitted[task.category]++
+			continue
+		}
+		pause = append(pause, task.gid)
+	}
+
+	for _, task := range tasks {
+		if !task.paused || !held[task.gid] {
+			continue
+		}
+		limit := limits[task.category]
+		if limit > 0 && admitted[task.category] >= limit {
+			continue
+		}
+		admitted[task.category]++
+		resume = append(resume, task.gid)
+	}
+	return pause, resume
+}
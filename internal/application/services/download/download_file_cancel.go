@@ -0,0 +1,89 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// CancelDownloadFile 只停止任务中的一个文件
+// 多文件任务（如种子）：通过 select-file 取消选择该文件，其余文件继续下载；取消的是最后一个选中的文件时停止整个任务
+// 单文件任务（如批量下载中的一个文件）：批次中每个文件是独立任务，直接取消该任务，不影响批次中的其他文件
+func (s *AppDownloadService) CancelDownloadFile(ctx context.Context, id string, index int) (*contracts.DownloadFileCancelResult, error) {
+	status, err := s.aria2Client.GetStatus(id)
+	if err != nil {
+		if errors.Is(err, aria2.ErrGIDNotFound) {
+			return nil, fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get download status: %w", s.health.WrapError(err))
+	}
+
+	switch s.convertAriaStatus(status.Status) {
+	case valueobjects.DownloadStatusComplete, valueobjects.DownloadStatusError, valueobjects.DownloadStatusRemoved:
+		return nil, fmt.Errorf("任务已结束（%s），无需取消", status.Status)
+	}
+
+	detail := convertToDownloadDetail(status, contracts.DownloadResponse{ID: id})
+	remaining, target, err := deselectFile(detail.Files, index)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &contracts.DownloadFileCancelResult{ID: id, FilePath: target.Path}
+	if s.batches != nil {
+		if batch, ok := s.batches.FindByGID(id); ok {
+			result.BatchID = batch.ID
+		}
+	}
+
+	if len(remaining) == 0 {
+		if err := s.CancelDownload(ctx, id); err != nil {
+			return nil, err
+		}
+		result.TaskStopped = true
+		logger.Info("Download file cancelled, task stopped", "id", id, "index", index, "batchID", result.BatchID)
+		return result, nil
+	}
+
+	selectFile := strings.Join(remaining, ",")
+	if err := s.aria2Client.ChangeOption(id, map[string]interface{}{"select-file": selectFile}); err != nil {
+		return nil, fmt.Errorf("failed to deselect file: %w", s.health.WrapError(err))
+	}
+	result.RemainingFiles = len(remaining)
+	logger.Info("Download file deselected", "id", id, "index", index, "selectFile", selectFile)
+	return result, nil
+}
+
+// deselectFile 计算取消选择 index 后仍选中的文件序号（aria2 select-file 格式），返回被取消的文件
+// 单文件任务返回空列表，表示需要停止整个任务
+func deselectFile(files []contracts.DownloadFileDetail, index int) ([]string, contracts.DownloadFileDetail, error) {
+	var target *contracts.DownloadFileDetail
+	var remaining []string
+	for i := range files {
+		file := &files[i]
+		if file.Index == index {
+			target = file
+			continue
+		}
+		if file.Selected {
+			remaining = append(remaining, strconv.Itoa(file.Index))
+		}
+	}
+
+	switch {
+	case target == nil:
+		return nil, contracts.DownloadFileDetail{}, fmt.Errorf("任务中没有序号为 %d 的文件", index)
+	case !target.Selected:
+		return nil, contracts.DownloadFileDetail{}, fmt.Errorf("文件 %d 已经取消选择", index)
+	case target.Length > 0 && target.CompletedLength >= target.Length:
+		return nil, contracts.DownloadFileDetail{}, fmt.Errorf("文件 %d 已下载完成", index)
+	}
+	return remaining, *target, nil
+}
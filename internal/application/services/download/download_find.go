@@ -0,0 +1,195 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	pathservices "github.com/easayliu/alist-aria2-download/internal/application/services/path"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// maxFindMatches 查找已下载文件时最多返回的匹配数
+	maxFindMatches = 20
+	// maxFindWalkEntries 扫描下载目录时最多访问的文件和目录数，避免大目录扫描过久
+	maxFindWalkEntries = 50000
+)
+
+// errFindLimitReached 扫描达到上限时终止遍历
+var errFindLimitReached = errors.New("find limit reached")
+
+// FindDownloadedFiles 按文件名关键词（不区分大小写）查找已下载文件的本地位置
+// 先查下载历史（快，只返回本地仍存在的文件），没有匹配时再有限扫描下载根目录和用户专属目录
+func (s *AppDownloadService) FindDownloadedFiles(ctx context.Context, keyword string) (*contracts.FindDownloadedFilesResult, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil, fmt.Errorf("keyword is required")
+	}
+
+	result := &contracts.FindDownloadedFilesResult{Keyword: keyword}
+	needle := strings.ToLower(keyword)
+
+	if s.history != nil {
+		for _, record := range s.history.GetRecentCompleted(0) {
+			if !strings.Contains(strings.ToLower(record.Filename), needle) {
+				continue
+			}
+			localPath := filepath.Join(record.Directory, record.Filename)
+			if _, err := os.Stat(localPath); err != nil {
+				// 下载后已被移动或删除
+				logger.Debug("Downloaded file no longer on disk", "path", localPath, "error", err)
+				continue
+			}
+			if len(result.Matches) == maxFindMatches {
+				result.Truncated = true
+				break
+			}
+			result.Matches = append(result.Matches, contracts.DownloadedFileMatch{
+				Filename: record.Filename,
+				Path:     localPath,
+				Category: s.CategoryForDirectory(record.Directory),
+				Size:     record.TotalSize,
+				Modified: record.UpdatedAt,
+				Source:   contracts.FindSourceHistory,
+			})
+		}
+	}
+	if len(result.Matches) > 0 {
+		return result, nil
+	}
+
+	result.DiskSearched = true
+	visited := 0
+	for _, root := range s.downloadRoots() {
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// 无权限等错误只跳过该条目
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			visited++
+			if visited > maxFindWalkEntries {
+				return errFindLimitReached
+			}
+			if strings.HasPrefix(d.Name(), ".") && p != root {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() || !strings.Contains(strings.ToLower(d.Name()), needle) {
+				return nil
+			}
+			if len(result.Matches) == maxFindMatches {
+				return errFindLimitReached
+			}
+
+			match := contracts.DownloadedFileMatch{
+				Filename: d.Name(),
+				Path:     p,
+				Category: s.CategoryForDirectory(filepath.Dir(p)),
+				Source:   contracts.FindSourceDisk,
+			}
+			if info, err := d.Info(); err == nil {
+				match.Size = info.Size()
+				match.Modified = info.ModTime()
+			}
+			result.Matches = append(result.Matches, match)
+			return nil
+		})
+		if errors.Is(err, errFindLimitReached) {
+			result.Truncated = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("Downloaded files searched on disk", "keyword", keyword, "matches", len(result.Matches), "visited", visited, "truncated", result.Truncated)
+	return result, nil
+}
+
+// configuredRoots 返回配置的下载根目录（aria2.download_dir 和用户专属目录）
+func (s *AppDownloadService) configuredRoots() []string {
+	candidates := []string{s.config.Aria2.DownloadDir}
+	for _, p := range s.config.Download.UserPaths {
+		candidates = append(candidates, p.BasePath)
+	}
+
+	var roots []string
+	for _, root := range candidates {
+		if root = strings.TrimRight(root, "/"); root != "" {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// downloadRoots 返回存在的下载根目录，嵌套的目录只保留外层
+func (s *AppDownloadService) downloadRoots() []string {
+	var roots []string
+	for _, root := range s.configuredRoots() {
+		if coveredByRoots(root, roots) {
+			continue
+		}
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			logger.Debug("Download root not available for find", "root", root, "error", err)
+			continue
+		}
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+// coveredByRoots 判断目录是否已包含在某个根目录中
+func coveredByRoots(dir string, roots []string) bool {
+	for _, root := range roots {
+		if isUnderDir(dir, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryForDirectory 根据文件所在目录推断将其分到该目录的分类
+// 匹配音乐/文档配置的目录和自动分类的一级目录（tvs、movies 等），无法判断时返回空
+func (s *AppDownloadService) CategoryForDirectory(dir string) string {
+	cfg := s.config.Download
+	for _, root := range s.configuredRoots() {
+		if !isUnderDir(dir, root) {
+			continue
+		}
+		switch {
+		case inCategoryDir(dir, root, cfg.Music):
+			return "music"
+		case inCategoryDir(dir, root, cfg.Documents):
+			return "document"
+		}
+		first, _, _ := strings.Cut(strings.TrimPrefix(dir, root+"/"), "/")
+		return pathservices.CategoryForDirName(first)
+	}
+	return ""
+}
+
+// inCategoryDir 判断目录是否位于已启用的扩展名分类目录中（相对路径基于下载根目录）
+func inCategoryDir(dir, root string, category config.ExtensionCategoryConfig) bool {
+	if !category.Enabled || category.Path == "" {
+		return false
+	}
+	categoryPath := category.Path
+	if !path.IsAbs(categoryPath) {
+		categoryPath = path.Join(root, categoryPath)
+	}
+	return isUnderDir(dir, strings.TrimRight(categoryPath, "/"))
+}
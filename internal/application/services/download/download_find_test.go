@@ -0,0 +1,98 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+)
+
+func TestFindDownloadedFiles(t *testing.T) {
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "data")
+	downloadDir := filepath.Join(root, "downloads")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "download_history.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history, err := repository.NewDownloadHistoryRepository(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Aria2.DownloadDir = downloadDir
+	cfg.Download.Music = config.ExtensionCategoryConfig{Enabled: true, Path: "music"}
+	s := &AppDownloadService{config: cfg, history: history}
+
+	tvDir := filepath.Join(downloadDir, "tvs", "庆余年", "S01")
+	if err := history.Save(&entities.DownloadRecord{
+		ID: "gid1", SourcePath: "/a/庆余年.S01E01.mkv", Filename: "庆余年.S01E01.mkv",
+		Directory: tvDir, Status: valueobjects.DownloadStatusComplete,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// 历史中记录的位置已不存在（下载后被移动），应回退到扫描磁盘
+	if err := history.Save(&entities.DownloadRecord{
+		ID: "gid2", SourcePath: "/a/繁花.S01E01.mkv", Filename: "繁花.S01E01.mkv",
+		Directory: tvDir, Status: valueobjects.DownloadStatusComplete,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{
+		filepath.Join(tvDir, "庆余年.S01E01.mkv"),
+		filepath.Join(downloadDir, "Others", "繁花.S01E01.mkv"),
+		filepath.Join(downloadDir, "music", "Album", "Song.flac"),
+		filepath.Join(downloadDir, ".trash", "Song.flac"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		keyword      string
+		wantPath     string
+		wantCategory string
+		wantSource   string
+	}{
+		{"history match", "庆余年", filepath.Join(tvDir, "庆余年.S01E01.mkv"), "tv", contracts.FindSourceHistory},
+		{"moved file found on disk", "繁花", filepath.Join(downloadDir, "Others", "繁花.S01E01.mkv"), "other", contracts.FindSourceDisk},
+		{"disk fallback skips hidden dirs", "song", filepath.Join(downloadDir, "music", "Album", "Song.flac"), "music", contracts.FindSourceDisk},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.FindDownloadedFiles(context.Background(), tt.keyword)
+			if err != nil {
+				t.Fatalf("FindDownloadedFiles() error = %v", err)
+			}
+			if len(result.Matches) != 1 {
+				t.Fatalf("got %d matches, want 1: %+v", len(result.Matches), result.Matches)
+			}
+			match := result.Matches[0]
+			if match.Path != tt.wantPath || match.Category != tt.wantCategory || match.Source != tt.wantSource {
+				t.Errorf("match = %+v, want path %s category %s source %s", match, tt.wantPath, tt.wantCategory, tt.wantSource)
+			}
+		})
+	}
+
+	result, err := s.FindDownloadedFiles(context.Background(), "不存在")
+	if err != nil {
+		t.Fatalf("FindDownloadedFiles() error = %v", err)
+	}
+	if len(result.Matches) != 0 || !result.DiskSearched {
+		t.Errorf("no-match result = %+v, want empty disk search", result)
+	}
+}
@@ -0,0 +1,151 @@
+package download
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// monitorInterval 下载状态轮询间隔
+	monitorInterval = 10 * time.Second
+	// monitorStoppedLimit 每次轮询获取的已停止任务数量
+	monitorStoppedLimit = 1000
+)
+
+// DownloadMonitor 下载监控器 - 轮询 aria2 已停止的任务并向监听器派发完成/失败事件
+type DownloadMonitor struct {
+	aria2Client *aria2.Client
+	convert     func(*aria2.StatusResult) *contracts.DownloadResponse
+
+	mu        sync.RWMutex
+	listeners []contracts.DownloadEventListener
+	tracked   map[string]contracts.DownloadRequest // gid -> 创建时的请求
+	seen      map[string]bool                      // 已派发过事件的 gid
+	primed    bool                                 // 是否已完成首次同步（启动前已结束的任务不派发事件）
+	startOnce sync.Once
+}
+
+// NewDownloadMonitor 创建下载监控器
+func NewDownloadMonitor(aria2Client *aria2.Client, convert func(*aria2.StatusResult) *contracts.DownloadResponse) *DownloadMonitor {
+	return &DownloadMonitor{
+		aria2Client: aria2Client,
+		convert:     convert,
+		tracked:     make(map[string]contracts.DownloadRequest),
+		seen:        make(map[string]bool),
+	}
+}
+
+// Track 记录由本进程创建的下载任务，事件派发时附带原始请求
+func (m *DownloadMonitor) Track(gid string, req contracts.DownloadRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tracked[gid] = req
+}
+
+// Emit 立即向所有监听器派发事件（用于任务创建等非轮询事件）
+func (m *DownloadMonitor) Emit(ctx context.Context, event contracts.DownloadEvent) {
+	m.mu.RLock()
+	listeners := append([]contracts.DownloadEventListener(nil), m.listeners...)
+	m.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(ctx, event)
+	}
+}
+
+// AddListener 注册事件监听器，首次注册时启动轮询
+func (m *DownloadMonitor) AddListener(listener contracts.DownloadEventListener) {
+	m.mu.Lock()
+	m.listeners = append(m.listeners, listener)
+	m.mu.Unlock()
+
+	m.startOnce.Do(func() {
+		go m.run()
+		logger.Info("Download monitor started", "interval", monitorInterval)
+	})
+}
+
+// run 轮询主循环
+func (m *DownloadMonitor) run() {
+	ticker := time.NewTicker(monitorInterval)
+	defer ticker.Stop()
+
+	m.poll()
+	for range ticker.C {
+		m.poll()
+	}
+}
+
+// poll 拉取已停止的任务并派发新事件
+func (m *DownloadMonitor) poll() {
+	stopped, err := m.aria2Client.GetStopped(0, monitorStoppedLimit)
+	if err != nil {
+		logger.Debug("Download monitor poll failed", "error", err)
+		return
+	}
+
+	var events []contracts.DownloadEvent
+
+	m.mu.Lock()
+	current := make(map[string]bool, len(stopped))
+	for i := range stopped {
+		status := &stopped[i]
+		current[status.GID] = true
+		if m.seen[status.GID] {
+			continue
+		}
+		m.seen[status.GID] = true
+
+		req, isTracked := m.tracked[status.GID]
+		delete(m.tracked, status.GID)
+
+		// 首次同步时跳过启动前已结束的历史任务
+		if !m.primed && !isTracked {
+			continue
+		}
+
+		event := contracts.DownloadEvent{
+			Type:     eventTypeFromStatus(status.Status),
+			Download: *m.convert(status),
+		}
+		if isTracked {
+			reqCopy := req
+			event.Request = &reqCopy
+		}
+		events = append(events, event)
+	}
+
+	// 清理已从 aria2 结果中移除的记录，防止无限增长
+	for gid := range m.seen {
+		if !current[gid] {
+			delete(m.seen, gid)
+		}
+	}
+	m.primed = true
+	listeners := append([]contracts.DownloadEventListener(nil), m.listeners...)
+	m.mu.Unlock()
+
+	ctx := context.Background()
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener(ctx, event)
+		}
+	}
+}
+
+// eventTypeFromStatus 将 aria2 停止状态映射为事件类型
+func eventTypeFromStatus(status string) contracts.DownloadEventType {
+	switch status {
+	case "complete":
+		return contracts.DownloadEventCompleted
+	case "removed":
+		return contracts.DownloadEventRemoved
+	default:
+		return contracts.DownloadEventFailed
+	}
+}
@@ -0,0 +1,115 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// SetDownloadHistory 设置下载历史存储（查找和移动已完成下载的本地文件）
+func (s *AppDownloadService) SetDownloadHistory(history *repository.DownloadHistoryRepository) {
+	s.history = history
+}
+
+// GetRecentCompletedDownloads 获取最近完成的下载记录
+func (s *AppDownloadService) GetRecentCompletedDownloads(ctx context.Context, limit int) ([]*entities.DownloadRecord, error) {
+	if s.history == nil {
+		return nil, fmt.Errorf("download history not available")
+	}
+	return s.history.GetRecentCompleted(limit), nil
+}
+
+// GetDownloadHistoryStats 按分类汇总 since 之后完成的下载，早期没有记录分类的下载按保存目录推断
+func (s *AppDownloadService) GetDownloadHistoryStats(ctx context.Context, since time.Time) (*contracts.DownloadHistoryStats, error) {
+	if s.history == nil {
+		return nil, fmt.Errorf("download history not available")
+	}
+
+	stats := &contracts.DownloadHistoryStats{Since: since, Categories: []contracts.CategoryDownloadStats{}}
+	index := make(map[string]int)
+	for _, record := range s.history.GetCompletedSince(since) {
+		category := record.Category
+		if category == "" && record.Directory != "" {
+			category = s.CategoryForDirectory(record.Directory)
+		}
+		i, ok := index[category]
+		if !ok {
+			i = len(stats.Categories)
+			index[category] = i
+			stats.Categories = append(stats.Categories, contracts.CategoryDownloadStats{Category: category})
+		}
+		stats.Categories[i].Files++
+		stats.Categories[i].Size += record.TotalSize
+		stats.TotalFiles++
+		stats.TotalSize += record.TotalSize
+	}
+	sort.SliceStable(stats.Categories, func(i, j int) bool {
+		return stats.Categories[i].Size > stats.Categories[j].Size
+	})
+	return stats, nil
+}
+
+// MoveCompletedDownload 将已完成下载的本地文件移动到新目录，并更新下载记录中的保存目录
+// 跨文件系统时回退为复制后删除
+func (s *AppDownloadService) MoveCompletedDownload(ctx context.Context, id, targetDir string) (*contracts.MoveDownloadResult, error) {
+	if err := s.checkWritable("move_download"); err != nil {
+		return nil, err
+	}
+	if s.history == nil {
+		return nil, fmt.Errorf("download history not available")
+	}
+
+	record, ok := s.history.GetByID(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+	}
+	if record.Status != valueobjects.DownloadStatusComplete {
+		return nil, fmt.Errorf("download is not completed (status: %s)", record.Status)
+	}
+
+	targetDir = filepath.Clean(targetDir)
+	if !filepath.IsAbs(targetDir) {
+		return nil, fmt.Errorf("target directory must be an absolute path: %s", targetDir)
+	}
+	// 只允许移动到下载目录内，避免把文件移到主机上任意位置
+	if s.config == nil || s.config.Aria2.DownloadDir == "" {
+		return nil, fmt.Errorf("download directory is not configured")
+	}
+	if base := filepath.Clean(s.config.Aria2.DownloadDir); !isUnderDir(targetDir, base) {
+		return nil, fmt.Errorf("target directory must be inside the download directory %s: %s", base, targetDir)
+	}
+	if targetDir == filepath.Clean(record.Directory) {
+		return nil, fmt.Errorf("file is already in %s", targetDir)
+	}
+
+	result := &contracts.MoveDownloadResult{
+		ID:       record.ID,
+		Filename: record.Filename,
+		From:     filepath.Join(record.Directory, record.Filename),
+		To:       filepath.Join(targetDir, record.Filename),
+	}
+	if err := filesystem.MoveFile(result.From, result.To); err != nil {
+		if errors.Is(err, filesystem.ErrSourceNotFound) {
+			return nil, fmt.Errorf("%w: %s", contracts.ErrDownloadOutputMissing, result.From)
+		}
+		return nil, fmt.Errorf("failed to move download output: %w", err)
+	}
+
+	record.Directory = targetDir
+	if err := s.history.Save(record); err != nil {
+		logger.Warn("Failed to update download record after move", "id", record.ID, "error", err)
+	}
+
+	logger.Info("Download output moved", "id", record.ID, "from", result.From, "to", result.To)
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+)
+
+func TestMoveCompletedDownload(t *testing.T) {
+	root := t.TempDir()
+	dataDir := filepath.Join(root, "data")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "download_history.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history, err := repository.NewDownloadHistoryRepository(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Aria2.DownloadDir = root
+	s := &AppDownloadService{config: cfg, history: history}
+
+	oldDir := filepath.Join(root, "movies")
+	newDir := filepath.Join(root, "tvs", "庆余年")
+	if err := os.MkdirAll(oldDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(oldDir, "E01.mkv"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range []*entities.DownloadRecord{
+		{ID: "done", SourcePath: "/a/E01.mkv", Filename: "E01.mkv", Directory: oldDir, Status: valueobjects.DownloadStatusComplete},
+		{ID: "gone", SourcePath: "/a/E02.mkv", Filename: "E02.mkv", Directory: oldDir, Status: valueobjects.DownloadStatusComplete},
+		{ID: "active", SourcePath: "/a/E03.mkv", Filename: "E03.mkv", Directory: oldDir, Status: valueobjects.DownloadStatusActive},
+	} {
+		if err := history.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := s.MoveCompletedDownload(context.Background(), "done", newDir)
+	if err != nil {
+		t.Fatalf("MoveCompletedDownload() error = %v", err)
+	}
+	if _, err := os.Stat(result.To); err != nil {
+		t.Errorf("moved file not found at %s: %v", result.To, err)
+	}
+	if record, _ := history.GetByID("done"); record.Directory != newDir {
+		t.Errorf("record directory = %s, want %s", record.Directory, newDir)
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		targetDir string
+		wantErr   error
+	}{
+		{name: "记录不存在", id: "missing", targetDir: newDir, wantErr: contracts.ErrDownloadNotFound},
+		{name: "文件已不存在", id: "gone", targetDir: newDir, wantErr: contracts.ErrDownloadOutputMissing},
+		{name: "未完成的下载", id: "active", targetDir: newDir},
+		{name: "目标在下载目录之外", id: "done", targetDir: filepath.Join(t.TempDir(), "elsewhere")},
+		{name: "相对路径逃出下载目录", id: "done", targetDir: filepath.Join(root, "..", "escape")},
+		{name: "相对路径", id: "done", targetDir: "movies"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.MoveCompletedDownload(context.Background(), tt.id, tt.targetDir)
+			if err == nil {
+				t.Fatal("MoveCompletedDownload() error = nil, want error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("MoveCompletedDownload() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetDownloadHistoryStats(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "download_history.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history, err := repository.NewDownloadHistoryRepository(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{}
+	cfg.Aria2.DownloadDir = "/downloads"
+	s := &AppDownloadService{config: cfg, history: history}
+
+	since := time.Now().Add(-time.Hour)
+	for _, record := range []*entities.DownloadRecord{
+		{ID: "tv1", SourcePath: "/a/E01.mkv", Status: valueobjects.DownloadStatusComplete, TotalSize: 300, Category: "tv", CompletedAt: time.Now()},
+		{ID: "tv2", SourcePath: "/a/E02.mkv", Status: valueobjects.DownloadStatusComplete, TotalSize: 200, Category: "tv", CompletedAt: time.Now()},
+		{ID: "movie", SourcePath: "/b/movie.mkv", Status: valueobjects.DownloadStatusComplete, TotalSize: 1000, Category: "movie", CompletedAt: time.Now()},
+		// 早期记录没有分类，按保存目录推断
+		{ID: "legacy", SourcePath: "/b/old.mkv", Directory: "/downloads/movies/old", Status: valueobjects.DownloadStatusComplete, TotalSize: 100},
+		{ID: "before", SourcePath: "/c/old.mkv", Status: valueobjects.DownloadStatusComplete, TotalSize: 50, Category: "tv", CompletedAt: since.Add(-time.Minute)},
+		{ID: "active", SourcePath: "/c/E03.mkv", Status: valueobjects.DownloadStatusActive, TotalSize: 70},
+	} {
+		if err := history.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := s.GetDownloadHistoryStats(context.Background(), since)
+	if err != nil {
+		t.Fatalf("GetDownloadHistoryStats() error = %v", err)
+	}
+	if stats.TotalFiles != 4 || stats.TotalSize != 1600 {
+		t.Errorf("total = %d files / %d bytes, want 4 / 1600", stats.TotalFiles, stats.TotalSize)
+	}
+	want := []contracts.CategoryDownloadStats{
+		{Category: "movie", Files: 2, Size: 1100},
+		{Category: "tv", Files: 2, Size: 500},
+	}
+	if len(stats.Categories) != len(want) {
+		t.Fatalf("categories = %+v, want %+v", stats.Categories, want)
+	}
+	for i := range want {
+		if stats.Categories[i] != want[i] {
+			t.Errorf("categories[%d] = %+v, want %+v", i, stats.Categories[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// PrioritizeDownload 将等待中或已暂停的任务移到队首立即下载
+// 同时下载数已满时：开启 download.jump_queue_pause_others 则暂停剩余最多的活动任务腾出槽位，否则只移到队首等待
+func (s *AppDownloadService) PrioritizeDownload(ctx context.Context, id string) (*contracts.PrioritizeResult, error) {
+	status, err := s.aria2Client.GetStatus(id)
+	if err != nil {
+		if errors.Is(err, aria2.ErrGIDNotFound) {
+			return nil, fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get download status: %w", s.health.WrapError(err))
+	}
+
+	download := s.convertToDownloadResponse(status)
+	result := &contracts.PrioritizeResult{ID: id, Filename: download.Filename}
+	switch download.Status {
+	case valueobjects.DownloadStatusActive:
+		return nil, fmt.Errorf("任务已在下载中")
+	case valueobjects.DownloadStatusPending, valueobjects.DownloadStatusPaused:
+	default:
+		return nil, fmt.Errorf("任务已结束（%s），无法插队", status.Status)
+	}
+
+	// 先移到队首，腾出槽位后 aria2 会优先启动它
+	if _, err := s.aria2Client.ChangePosition(id, 0, aria2.PositionSet); err != nil {
+		return nil, fmt.Errorf("failed to move download to front: %w", s.health.WrapError(err))
+	}
+	if download.Status == valueobjects.DownloadStatusPaused {
+		if err := s.aria2Client.Resume(id); err != nil {
+			return nil, fmt.Errorf("failed to resume download: %w", s.health.WrapError(err))
+		}
+		result.Resumed = true
+	}
+
+	active, err := s.aria2Client.GetActive()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active downloads: %w", s.health.WrapError(err))
+	}
+	maxConcurrent := s.maxConcurrentDownloads()
+	if maxConcurrent == 0 || len(active) < maxConcurrent {
+		logger.Info("Download moved to front of queue", "id", id, "resumed", result.Resumed)
+		return result, nil
+	}
+
+	if !s.config.Download.JumpQueuePauseOthers {
+		result.QueueFull = true
+		logger.Info("Download moved to front of full queue", "id", id, "active", len(active), "max", maxConcurrent)
+		return result, nil
+	}
+
+	activeDownloads := make([]contracts.DownloadResponse, 0, len(active))
+	for i := range active {
+		activeDownloads = append(activeDownloads, *s.convertToDownloadResponse(&active[i]))
+	}
+	for _, victim := range preemptionVictims(activeDownloads, len(active)-maxConcurrent+1) {
+		if err := s.aria2Client.Pause(victim.ID); err != nil {
+			logger.Warn("Failed to pause download for jump queue", "id", victim.ID, "error", err)
+			continue
+		}
+		result.Paused = append(result.Paused, victim)
+	}
+	if len(result.Paused) == 0 {
+		result.QueueFull = true
+	}
+
+	logger.Info("Download jumped the queue", "id", id, "paused", len(result.Paused))
+	return result, nil
+}
+
+// ChangePosition 调整等待中（含已暂停）任务的队列位置，返回调整后的位置（0 为队首）
+// 下载中或已结束的任务无法调整，返回 ErrDownloadNotWaiting 并附带 aria2 的错误信息
+func (s *AppDownloadService) ChangePosition(ctx context.Context, id string, pos int, how string) (int, error) {
+	switch how {
+	case contracts.PositionFromStart:
+		if pos < 0 {
+			return 0, fmt.Errorf("相对队首的位置不能为负数: %d", pos)
+		}
+	case contracts.PositionFromEnd:
+		if pos > 0 {
+			return 0, fmt.Errorf("相对队尾的位置不能为正数: %d", pos)
+		}
+	case contracts.PositionFromCurrent:
+	default:
+		return 0, fmt.Errorf("无效的位置调整方式: %q", how)
+	}
+
+	newPos, err := s.aria2Client.ChangePosition(id, pos, how)
+	if err != nil {
+		switch {
+		case errors.Is(err, aria2.ErrGIDNotFound):
+			return 0, fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		case errors.Is(err, aria2.ErrNotWaiting):
+			return 0, fmt.Errorf("%w: %v", contracts.ErrDownloadNotWaiting, err)
+		}
+		return 0, fmt.Errorf("failed to change download position: %w", s.health.WrapError(err))
+	}
+	logger.Info("Download position changed", "id", id, "pos", pos, "how", how, "new_position", newPos)
+	return newPos, nil
+}
+
+// maxConcurrentDownloads 读取 aria2 的同时下载数上限，读取失败时返回 0（视为未知）
+func (s *AppDownloadService) maxConcurrentDownloads() int {
+	options, err := s.aria2Client.GetGlobalOption()
+	if err != nil {
+		logger.Warn("Failed to get aria2 global options", "error", err)
+		return 0
+	}
+	maxConcurrent, _ := strconv.Atoi(options["max-concurrent-downloads"])
+	return maxConcurrent
+}
+
+// preemptionVictims 选出为插队而暂停的活动任务：剩余下载量最多的优先（最晚才能完成）
+func preemptionVictims(active []contracts.DownloadResponse, count int) []contracts.DownloadResponse {
+	if count <= 0 {
+		return nil
+	}
+	victims := append([]contracts.DownloadResponse(nil), active...)
+	sort.SliceStable(victims, func(i, j int) bool {
+		return victims[i].TotalSize-victims[i].CompletedSize > victims[j].TotalSize-victims[j].CompletedSize
+	})
+	return victims[:min(count, len(victims))]
+}
@@ -1,16 +1,26 @@
 package download
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"math/bits"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	pathservices "github.com/easayliu/alist-aria2-download/internal/application/services/path"
+	mediaservices "github.com/easayliu/alist-aria2-download/internal/domain/services/media"
 	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 	fileutil "github.com/easayliu/alist-aria2-download/pkg/utils/file"
 	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
@@ -21,7 +31,19 @@ type AppDownloadService struct {
 	config       *config.Config
 	aria2Client  *aria2.Client
 	fileService  contracts.FileService
-	pathStrategy *pathservices.PathStrategyService // 路径策略服务
+	pathStrategy *pathservices.PathStrategyService         // 路径策略服务
+	monitor      *DownloadMonitor                          // 下载事件监控
+	bandwidth    *BandwidthSampler                         // 带宽采样（未启用时为nil）
+	health       *Aria2HealthChecker                       // aria2 连接健康检查
+	history      *repository.DownloadHistoryRepository     // 下载历史（移动已完成的文件）
+	batches      *repository.DownloadBatchRepository       // 批量下载记录（批量重试失败的文件）
+	checkpoints  *repository.DirectoryCheckpointRepository // 目录下载断点（中断后从断点继续）
+	notifier     contracts.NotificationService             // 分批提交进度通知
+	limiter      *CategoryLimiter                          // 分类并发限制（未配置时为nil）
+	rules        *mediaservices.ClassificationRules        // 用户配置的分类规则（视频识别）
+
+	shutdown     chan struct{} // 关闭后停止后台分批提交
+	shutdownOnce sync.Once
 }
 
 // NewAppDownloadService 创建应用下载服务
@@ -30,6 +52,19 @@ func NewAppDownloadService(cfg *config.Config, fileService contracts.FileService
 		config:      cfg,
 		aria2Client: aria2.NewClient(cfg.Aria2.RpcURL, cfg.Aria2.Token),
 		fileService: fileService,
+		shutdown:    make(chan struct{}),
+	}
+	service.monitor = NewDownloadMonitor(service.aria2Client, service.convertToDownloadResponse)
+	service.health = NewAria2HealthChecker(service.aria2Client, defaultHealthInterval)
+	if cfg.Download.Bandwidth.Enabled {
+		interval := time.Duration(cfg.Download.Bandwidth.SampleInterval) * time.Second
+		service.bandwidth = NewBandwidthSampler(service.aria2Client, interval)
+	}
+	for _, limit := range cfg.Download.CategoryLimits {
+		if limit > 0 {
+			service.limiter = NewCategoryLimiter(service.aria2Client, cfg.Download.CategoryLimits, service.CategoryForDirectory)
+			break
+		}
 	}
 
 	// 初始化路径策略服务（需要fileService）
@@ -45,16 +80,37 @@ func (s *AppDownloadService) CreateDownload(ctx context.Context, req contracts.D
 	logger.Debug("Creating download", "url", req.URL, "filename", req.Filename, "directory", req.Directory)
 
 	// 1. 参数验证
+	if req.DeleteAfterDownload {
+		// 下载后删除源文件属于破坏性操作
+		if err := s.checkWritable("download_and_delete"); err != nil {
+			return nil, err
+		}
+	}
 	if err := s.validateDownloadRequest(req); err != nil {
 		logger.Error("Download request validation failed", "url", req.URL, "filename", req.Filename, "error", err)
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
 	// 2. 应用业务规则
+	s.applyUserBasePath(ctx, &req)
 	if err := s.applyBusinessRules(&req); err != nil {
 		return nil, fmt.Errorf("business rule violation: %w", err)
 	}
 
+	response, err := s.submitDownload(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同名字幕跟随视频下载，字幕失败不影响视频任务
+	if req.DownloadSubtitles {
+		response.Subtitles = s.enqueueSubtitles(ctx, req, response)
+	}
+	return response, nil
+}
+
+// submitDownload 提交已通过校验的下载请求到 aria2，记录请求并发出创建事件
+func (s *AppDownloadService) submitDownload(ctx context.Context, req contracts.DownloadRequest) (*contracts.DownloadResponse, error) {
 	// 3. 准备下载选项
 	options := s.prepareDownloadOptions(req)
 
@@ -62,10 +118,16 @@ func (s *AppDownloadService) CreateDownload(ctx context.Context, req contracts.D
 	gid, err := s.aria2Client.AddURI(req.URL, options)
 	if err != nil {
 		logger.Error("Failed to create aria2 download", "error", err, "url", req.URL)
-		return nil, fmt.Errorf("failed to create download: %w", err)
+		return nil, fmt.Errorf("failed to create download: %w", s.health.WrapError(err))
+	}
+
+	// 5. 记录原始请求，供完成事件使用；所属分类已满时尽快暂停新任务
+	s.monitor.Track(gid, req)
+	if s.limiter != nil {
+		s.limiter.Trigger()
 	}
 
-	// 5. 构建响应
+	// 6. 构建响应
 	response := &contracts.DownloadResponse{
 		ID:        gid,
 		URL:       req.URL,
@@ -76,6 +138,12 @@ func (s *AppDownloadService) CreateDownload(ctx context.Context, req contracts.D
 		UpdatedAt: time.Now(),
 	}
 
+	s.monitor.Emit(ctx, contracts.DownloadEvent{
+		Type:     contracts.DownloadEventCreated,
+		Download: *response,
+		Request:  &req,
+	})
+
 	logger.Info("Download created successfully", "id", gid, "filename", response.Filename)
 	return response, nil
 }
@@ -84,28 +152,99 @@ func (s *AppDownloadService) CreateDownload(ctx context.Context, req contracts.D
 func (s *AppDownloadService) GetDownload(ctx context.Context, id string) (*contracts.DownloadResponse, error) {
 	status, err := s.aria2Client.GetStatus(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get download status: %w", err)
+		if errors.Is(err, aria2.ErrGIDNotFound) {
+			return nil, fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get download status: %w", s.health.WrapError(err))
 	}
 
 	return s.convertToDownloadResponse(status), nil
 }
 
-// ListDownloads 获取下载列表
-func (s *AppDownloadService) ListDownloads(ctx context.Context, req contracts.DownloadListRequest) (*contracts.DownloadListResponse, error) {
-	// 并行获取各种状态的下载
-	active, err := s.aria2Client.GetActive()
+// GetDownloadDetail 获取单个下载任务的完整信息（连接数、分片、文件列表等）
+func (s *AppDownloadService) GetDownloadDetail(ctx context.Context, id string) (*contracts.DownloadDetail, error) {
+	status, err := s.aria2Client.GetStatus(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active downloads: %w", err)
+		if errors.Is(err, aria2.ErrGIDNotFound) {
+			return nil, fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to get download status: %w", s.health.WrapError(err))
+	}
+
+	detail := convertToDownloadDetail(status, *s.convertToDownloadResponse(status))
+	if s.batches != nil {
+		if batch, ok := s.batches.FindByGID(id); ok {
+			detail.BatchID = batch.ID
+			detail.BatchSize = len(batch.Items)
+		}
+	}
+
+	switch detail.Status {
+	case valueobjects.DownloadStatusActive, valueobjects.DownloadStatusPending, valueobjects.DownloadStatusPaused:
+		if options, err := s.aria2Client.GetOption(id); err == nil {
+			detail.MaxConnectionPerServer, _ = strconv.Atoi(options["max-connection-per-server"])
+			detail.Split, _ = strconv.Atoi(options["split"])
+			detail.SpeedLimit, _ = strutil.ParseInt64(options["max-download-limit"])
+		} else {
+			logger.Debug("Failed to get download options", "id", id, "error", err)
+		}
 	}
+	return detail, nil
+}
+
+// convertToDownloadDetail 转换 aria2 状态为下载详情
+func convertToDownloadDetail(status *aria2.StatusResult, base contracts.DownloadResponse) *contracts.DownloadDetail {
+	detail := &contracts.DownloadDetail{
+		DownloadResponse: base,
+		CompletedPieces:  countCompletedPieces(status.Bitfield),
+		BitTorrent:       status.InfoHash != "",
+	}
+	detail.Connections, _ = strconv.Atoi(status.Connections)
+	detail.NumPieces, _ = strconv.Atoi(status.NumPieces)
+	detail.UploadSpeed, _ = strutil.ParseInt64(status.UploadSpeed)
+	detail.PieceLength, _ = strutil.ParseInt64(status.PieceLength)
+
+	for _, file := range status.Files {
+		fileDetail := contracts.DownloadFileDetail{
+			Path:     file.Path,
+			Selected: file.Selected != "false",
+		}
+		fileDetail.Index, _ = strconv.Atoi(file.Index)
+		fileDetail.Length, _ = strutil.ParseInt64(file.Length)
+		fileDetail.CompletedLength, _ = strutil.ParseInt64(file.CompletedLength)
+		for _, uri := range file.URI {
+			if uri.Status == "used" && !slices.Contains(fileDetail.URIs, uri.URI) {
+				fileDetail.URIs = append(fileDetail.URIs, uri.URI)
+			}
+		}
+		detail.Files = append(detail.Files, fileDetail)
+	}
+	return detail
+}
 
-	waiting, err := s.aria2Client.GetWaiting(req.Offset, req.Limit)
+// countCompletedPieces 统计 bitfield（十六进制）中已完成的分片数
+func countCompletedPieces(bitfield string) int {
+	count := 0
+	for _, c := range bitfield {
+		if v, err := strconv.ParseUint(string(c), 16, 8); err == nil {
+			count += bits.OnesCount8(uint8(v))
+		}
+	}
+	return count
+}
+
+// ListDownloads 获取下载列表，按 下载中 → 等待中 → 已停止 排序
+// Offset/Limit 作用于合并后的列表（Limit<=0 时返回全部），已停止的任务只向 aria2 获取当前页需要的部分
+func (s *AppDownloadService) ListDownloads(ctx context.Context, req contracts.DownloadListRequest) (*contracts.DownloadListResponse, error) {
+	active, err := s.aria2Client.GetActive()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get waiting downloads: %w", err)
+		return nil, fmt.Errorf("failed to get active downloads: %w", s.health.WrapError(err))
 	}
 
-	stopped, err := s.aria2Client.GetStopped(req.Offset, req.Limit)
+	// 等待队列还用于统计暂停数和分类并发，整体获取
+	waiting, err := s.aria2Client.GetWaiting(0, maxQueueScan)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stopped downloads: %w", err)
+		return nil, fmt.Errorf("failed to get waiting downloads: %w", s.health.WrapError(err))
 	}
 
 	globalStats, err := s.aria2Client.GetGlobalStat()
@@ -114,52 +253,144 @@ func (s *AppDownloadService) ListDownloads(ctx context.Context, req contracts.Do
 		globalStats = make(map[string]interface{})
 	}
 
-	// 转换并合并数据
-	var downloads []contracts.DownloadResponse
-	for _, d := range active {
-		downloads = append(downloads, s.convertAriaDownloadToResponse(d))
+	// 下载中和等待中的任务在内存中过滤
+	listed := make([]contracts.DownloadResponse, 0, len(active)+len(waiting))
+	for i := range active {
+		listed = append(listed, s.convertAriaDownloadToResponse(&active[i]))
 	}
-	for _, d := range waiting {
-		downloads = append(downloads, s.convertAriaDownloadToResponse(d))
+	pausedCount := 0
+	for i := range waiting {
+		if waiting[i].Status == "paused" {
+			pausedCount++
+		}
+		listed = append(listed, s.convertAriaDownloadToResponse(&waiting[i]))
 	}
-	for _, d := range stopped {
-		downloads = append(downloads, s.convertAriaDownloadToResponse(d))
+
+	stoppedTotal := 0
+	switch req.Status {
+	case "":
+		stoppedTotal = s.countStopped(globalStats)
+	case valueobjects.DownloadStatusComplete, valueobjects.DownloadStatusError, valueobjects.DownloadStatusRemoved:
+		// 按结束状态过滤时无法按页获取，取全部已停止的任务后过滤
+		stopped, err := s.aria2Client.GetStopped(0, s.countStopped(globalStats))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stopped downloads: %w", s.health.WrapError(err))
+		}
+		for i := range stopped {
+			listed = append(listed, s.convertAriaDownloadToResponse(&stopped[i]))
+		}
 	}
+	listed = s.filterDownloads(listed, req)
 
-	// 应用过滤和排序
-	downloads = s.filterDownloads(downloads, req)
+	total := len(listed) + stoppedTotal
+	start := min(max(req.Offset, 0), total)
+	end := total
+	if req.Limit > 0 {
+		end = min(start+req.Limit, total)
+	}
+
+	var downloads []contracts.DownloadResponse
+	if start < len(listed) {
+		downloads = append(downloads, listed[start:min(end, len(listed))]...)
+	}
+	if stoppedStart, stoppedEnd := max(start-len(listed), 0), end-len(listed); stoppedEnd > stoppedStart {
+		stopped, err := s.aria2Client.GetStopped(stoppedStart, stoppedEnd-stoppedStart)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stopped downloads: %w", s.health.WrapError(err))
+		}
+		for i := range stopped {
+			downloads = append(downloads, s.convertAriaDownloadToResponse(&stopped[i]))
+		}
+	}
+	s.fillSpeedLimits(downloads)
 	downloads = s.sortDownloads(downloads, req.SortBy, req.SortOrder)
 
-	return &contracts.DownloadListResponse{
+	resp := &contracts.DownloadListResponse{
 		Downloads:   downloads,
-		TotalCount:  len(downloads),
+		TotalCount:  total,
 		ActiveCount: len(active),
+		PausedCount: pausedCount,
+		// 队列中只剩暂停的任务（如执行了全部暂停）
+		AllPaused:   pausedCount > 0 && len(active) == 0 && pausedCount == len(waiting),
 		GlobalStats: globalStats,
-	}, nil
+	}
+	if s.limiter != nil {
+		resp.Categories = s.limiter.Stats(active, waiting)
+	}
+	return resp, nil
+}
+
+// countStopped 已停止的任务数，优先使用 aria2 全局统计，不可用时扫描已停止列表
+func (s *AppDownloadService) countStopped(globalStats map[string]interface{}) int {
+	if value, ok := globalStats["numStopped"].(string); ok {
+		if count, err := strutil.ParseInt64(value); err == nil {
+			return int(count)
+		}
+	}
+	stopped, err := s.aria2Client.GetStopped(0, maxQueueScan)
+	if err != nil {
+		logger.Warn("Failed to count stopped downloads", "error", err)
+		return 0
+	}
+	return len(stopped)
 }
 
-// PauseDownload 暂停下载
+// fillSpeedLimits 用一次 multicall 读取活动任务的 max-download-limit（B/s），
+// 未限速或读取失败的任务保持为0
+func (s *AppDownloadService) fillSpeedLimits(downloads []contracts.DownloadResponse) {
+	var gids []string
+	for _, d := range downloads {
+		if d.Status == valueobjects.DownloadStatusActive {
+			gids = append(gids, d.ID)
+		}
+	}
+	if len(gids) == 0 {
+		return
+	}
+
+	options, err := s.aria2Client.GetOptions(gids)
+	if err != nil {
+		logger.Debug("Failed to get download options", "count", len(gids), "error", err)
+		return
+	}
+	for i := range downloads {
+		if opts, ok := options[downloads[i].ID]; ok {
+			downloads[i].SpeedLimit, _ = strutil.ParseInt64(opts["max-download-limit"])
+		}
+	}
+}
+
+// PauseDownload 暂停下载，任务不存在时返回 ErrDownloadNotFound
 func (s *AppDownloadService) PauseDownload(ctx context.Context, id string) error {
 	if err := s.aria2Client.Pause(id); err != nil {
-		return fmt.Errorf("failed to pause download: %w", err)
+		if errors.Is(err, aria2.ErrGIDNotFound) {
+			return fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		}
+		return fmt.Errorf("failed to pause download: %w", s.health.WrapError(err))
 	}
 	logger.Info("Download paused", "id", id)
 	return nil
 }
 
-// ResumeDownload 恢复下载
+// ResumeDownload 恢复下载，任务不存在时返回 ErrDownloadNotFound
 func (s *AppDownloadService) ResumeDownload(ctx context.Context, id string) error {
 	if err := s.aria2Client.Resume(id); err != nil {
-		return fmt.Errorf("failed to resume download: %w", err)
+		if errors.Is(err, aria2.ErrGIDNotFound) {
+			return fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		}
+		return fmt.Errorf("failed to resume download: %w", s.health.WrapError(err))
 	}
 	logger.Info("Download resumed", "id", id)
 	return nil
 }
 
-// CancelDownload 取消下载
+// CancelDownload 取消下载，任务不存在时返回 ErrDownloadNotFound
 func (s *AppDownloadService) CancelDownload(ctx context.Context, id string) error {
 	if err := s.aria2Client.Remove(id); err != nil {
-		return fmt.Errorf("failed to cancel download: %w", err)
+		if errors.Is(err, aria2.ErrGIDNotFound) {
+			return fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, id)
+		}
+		return fmt.Errorf("failed to cancel download: %w", s.health.WrapError(err))
 	}
 	logger.Info("Download cancelled", "id", id)
 	return nil
@@ -170,7 +401,7 @@ func (s *AppDownloadService) RetryDownload(ctx context.Context, id string) (*con
 	// 获取原始下载信息
 	originalStatus, err := s.aria2Client.GetStatus(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get original download: %w", err)
+		return nil, fmt.Errorf("failed to get original download: %w", s.health.WrapError(err))
 	}
 
 	// 提取URL和选项
@@ -189,25 +420,52 @@ func (s *AppDownloadService) RetryDownload(ctx context.Context, id string) (*con
 	return s.CreateDownload(ctx, req)
 }
 
-// CreateBatchDownload 批量创建下载
-func (s *AppDownloadService) CreateBatchDownload(ctx context.Context, req contracts.BatchDownloadRequest) (*contracts.BatchDownloadResponse, error) {
-	var results []contracts.DownloadResult
-	var successCount, failureCount int
-	summary := contracts.DownloadSummary{}
+// RemoveDownloadResult 移除已结束任务的记录
+func (s *AppDownloadService) RemoveDownloadResult(ctx context.Context, id string) error {
+	if err := s.aria2Client.RemoveDownloadResult(id); err != nil {
+		return fmt.Errorf("failed to remove download result: %w", s.health.WrapError(err))
+	}
+	return nil
+}
 
+// CreateBatchDownload 批量创建下载；设置 ChunkSize 且文件数超过时只提交第一批，其余在后台分批提交
+func (s *AppDownloadService) CreateBatchDownload(ctx context.Context, req contracts.BatchDownloadRequest) (*contracts.BatchDownloadResponse, error) {
 	// 磁盘空间预检功能已移除，交由 Aria2 处理
 
-	for _, item := range req.Items {
-		// 应用批量下载的全局设置
-		if req.Directory != "" && item.Directory == "" {
-			item.Directory = req.Directory
-		}
-		if req.VideoOnly {
-			item.VideoOnly = true
-		}
-		if req.AutoClassify {
-			item.AutoClassify = true
-		}
+	// aria2 按提交顺序下载，优先级高的文件先提交（分批提交时也排在前面的批次）
+	req.Items = slices.Clone(req.Items)
+	slices.SortStableFunc(req.Items, func(a, b contracts.DownloadRequest) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+
+	items, pending := req.Items, []contracts.DownloadRequest(nil)
+	if req.ChunkSize > 0 && len(items) > req.ChunkSize {
+		items, pending = items[:req.ChunkSize], items[req.ChunkSize:]
+	}
+
+	results, summary, successCount, failureCount := s.submitBatchItems(ctx, req, items)
+	response := &contracts.BatchDownloadResponse{
+		BatchID:      s.recordBatch(req, results),
+		SuccessCount: successCount,
+		FailureCount: failureCount,
+		Results:      results,
+		Summary:      summary,
+	}
+
+	if len(pending) > 0 {
+		response.PendingCount = len(pending)
+		response.ChunkCount = (len(req.Items) + req.ChunkSize - 1) / req.ChunkSize
+		go s.submitRemainingChunks(context.WithoutCancel(ctx), req, response.BatchID, pending)
+	}
+	return response, nil
+}
+
+// submitBatchItems 逐个创建下载，返回每个文件的结果和成功文件的统计
+// 成功提交的源文件在整批提交后一次性记入断点
+func (s *AppDownloadService) submitBatchItems(ctx context.Context, req contracts.BatchDownloadRequest, items []contracts.DownloadRequest) (results []contracts.DownloadResult, summary contracts.DownloadSummary, successCount, failureCount int) {
+	var queued []string
+	for _, item := range items {
+		item = applyBatchSettings(req, item)
 
 		// 创建单个下载
 		download, err := s.CreateDownload(ctx, item)
@@ -223,55 +481,124 @@ func (s *AppDownloadService) CreateBatchDownload(ctx context.Context, req contra
 			result.Success = true
 			result.Download = download
 			successCount++
-
-			// 更新摘要统计 - 使用最终下载目录路径进行正确分类
-			summary.TotalFiles++
-			summary.TotalSize += item.FileSize // 累加文件大小
-			logger.Debug("Batch download: added file to summary", "file", download.Filename, "fileSize", item.FileSize, "totalSize", summary.TotalSize)
-
-			if s.isVideoFile(download.Filename) {
-				summary.VideoFiles++
-				// 使用最终的下载目录路径来判断分类
-				downloadDir := strings.ToLower(download.Directory)
-				if strings.Contains(downloadDir, "movies") {
-					summary.MovieFiles++
-				} else if strings.Contains(downloadDir, "tvs") {
-					summary.TVFiles++
-				} else {
-					summary.OtherFiles++
-				}
-			} else {
-				summary.OtherFiles++
+			if item.SourcePath != "" {
+				queued = append(queued, item.SourcePath)
 			}
+
+			s.addToSummary(&summary, download.Filename, download.Directory, item.FileSize)
 		}
 
 		results = append(results, result)
 	}
+	s.markCheckpoint(req.CheckpointID, queued)
 
-	return &contracts.BatchDownloadResponse{
-		SuccessCount: successCount,
-		FailureCount: failureCount,
-		Results:      results,
-		Summary:      summary,
-	}, nil
+	return results, summary, successCount, failureCount
 }
 
-// PauseAllDownloads 暂停所有下载
-func (s *AppDownloadService) PauseAllDownloads(ctx context.Context) error {
+// SummarizeBatch 按与 CreateBatchDownload 相同的规则统计将要下载的文件，不创建任务（用于预览）
+// 不会通过业务规则（如只下载视频）的文件不计入
+func (s *AppDownloadService) SummarizeBatch(ctx context.Context, req contracts.BatchDownloadRequest) contracts.DownloadSummary {
+	var summary contracts.DownloadSummary
+	for _, item := range req.Items {
+		item = applyBatchSettings(req, item)
+		s.applyUserBasePath(ctx, &item)
+		if err := s.applyBusinessRules(&item); err != nil {
+			continue
+		}
+		s.addToSummary(&summary, s.extractFilename(item.Filename, item.URL), s.resolveDirectory(item.Directory), item.FileSize)
+	}
+	return summary
+}
+
+// applyBatchSettings 应用批量下载的全局设置
+func applyBatchSettings(req contracts.BatchDownloadRequest, item contracts.DownloadRequest) contracts.DownloadRequest {
+	if req.Directory != "" && item.Directory == "" {
+		item.Directory = req.Directory
+	}
+	if req.VideoOnly {
+		item.VideoOnly = true
+	}
+	if req.AutoClassify {
+		item.AutoClassify = true
+	}
+	return item
+}
+
+// addToSummary 将文件计入批量下载摘要，视频按最终下载目录区分电影/电视剧
+func (s *AppDownloadService) addToSummary(summary *contracts.DownloadSummary, filename, directory string, size int64) {
+	summary.TotalFiles++
+	summary.TotalSize += size
+	logger.Debug("Batch download: added file to summary", "file", filename, "fileSize", size, "totalSize", summary.TotalSize)
+
+	if !s.isVideoFile(filename) {
+		s.countNonVideoFile(summary, filename)
+		return
+	}
+	summary.VideoFiles++
+	downloadDir := strings.ToLower(directory)
+	switch {
+	case strings.Contains(downloadDir, "movies"):
+		summary.MovieFiles++
+	case strings.Contains(downloadDir, "tvs"):
+		summary.TVFiles++
+	default:
+		summary.OtherFiles++
+	}
+}
+
+// maxQueueScan 统计全局暂停/恢复影响的任务数时最多扫描的等待队列长度
+const maxQueueScan = 1000
+
+// PauseAllDownloads 暂停所有活动和等待中的下载，返回受影响的任务数
+func (s *AppDownloadService) PauseAllDownloads(ctx context.Context) (int, error) {
+	running, _, err := s.countQueue()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get download queue: %w", s.health.WrapError(err))
+	}
 	if err := s.aria2Client.PauseAll(); err != nil {
-		return fmt.Errorf("failed to pause all downloads: %w", err)
+		return 0, fmt.Errorf("failed to pause all downloads: %w", s.health.WrapError(err))
 	}
-	logger.Info("All downloads paused")
-	return nil
+	// 全部暂停后由用户决定何时恢复，分类限制不再自动恢复它暂停的任务
+	if s.limiter != nil {
+		s.limiter.Release()
+	}
+	logger.Info("All downloads paused", "affected", running)
+	return running, nil
 }
 
-// ResumeAllDownloads 恢复所有下载
-func (s *AppDownloadService) ResumeAllDownloads(ctx context.Context) error {
+// ResumeAllDownloads 恢复所有已暂停的下载，返回受影响的任务数
+func (s *AppDownloadService) ResumeAllDownloads(ctx context.Context) (int, error) {
+	_, paused, err := s.countQueue()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get download queue: %w", s.health.WrapError(err))
+	}
 	if err := s.aria2Client.UnpauseAll(); err != nil {
-		return fmt.Errorf("failed to resume all downloads: %w", err)
+		return 0, fmt.Errorf("failed to resume all downloads: %w", s.health.WrapError(err))
 	}
-	logger.Info("All downloads resumed")
-	return nil
+	logger.Info("All downloads resumed", "affected", paused)
+	return paused, nil
+}
+
+// countQueue 统计未结束的任务：running 为活动和等待中的任务数，paused 为已暂停的任务数
+func (s *AppDownloadService) countQueue() (running, paused int, err error) {
+	active, err := s.aria2Client.GetActive()
+	if err != nil {
+		return 0, 0, err
+	}
+	waiting, err := s.aria2Client.GetWaiting(0, maxQueueScan)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	running = len(active)
+	for _, d := range waiting {
+		if d.Status == "paused" {
+			paused++
+		} else {
+			running++
+		}
+	}
+	return running, paused, nil
 }
 
 // GetSystemStatus 获取系统状态
@@ -281,7 +608,11 @@ func (s *AppDownloadService) GetSystemStatus(ctx context.Context) (map[string]in
 	aria2Status := "offline"
 	if err == nil {
 		aria2Status = "online"
+	} else {
+		// 同步健康状态，断开时立即触发重连
+		s.health.WrapError(err)
 	}
+	health := s.health.Health()
 
 	// 获取版本信息
 	version, err := s.aria2Client.GetVersion()
@@ -295,6 +626,9 @@ func (s *AppDownloadService) GetSystemStatus(ctx context.Context) (map[string]in
 			"status":      aria2Status,
 			"version":     versionStr,
 			"global_stat": globalStat,
+			"health":      health.Status,
+			"since":       health.Details["since"],
+			"last_check":  health.LastCheck,
 		},
 		"telegram": map[string]interface{}{
 			"status": "online",
@@ -353,6 +687,81 @@ func (s *AppDownloadService) GetDownloadStatistics(ctx context.Context) (map[str
 	}, nil
 }
 
+// StartBandwidthSampling 启动带宽采样（配置未启用时无操作）
+func (s *AppDownloadService) StartBandwidthSampling() {
+	if s.bandwidth != nil {
+		s.bandwidth.Start()
+	}
+}
+
+// StartCategoryLimits 启动分类并发限制（未配置 download.category_limits 时无操作）
+func (s *AppDownloadService) StartCategoryLimits() {
+	if s.limiter != nil {
+		s.limiter.Start()
+	}
+}
+
+// GetBandwidthStats 获取最近一段时间的带宽统计
+func (s *AppDownloadService) GetBandwidthStats(ctx context.Context, window time.Duration) (*contracts.BandwidthStats, error) {
+	if s.bandwidth == nil {
+		return nil, fmt.Errorf("bandwidth sampling is disabled")
+	}
+	if window <= 0 || window > bandwidthRetention {
+		window = bandwidthRetention
+	}
+	return s.bandwidth.Stats(window), nil
+}
+
+// GetCurrentSpeed 获取 aria2 当前全局下载速度(B/s)
+func (s *AppDownloadService) GetCurrentSpeed(ctx context.Context) (int64, error) {
+	globalStat, err := s.aria2Client.GetGlobalStat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get global stat: %w", s.health.WrapError(err))
+	}
+	speed, err := strutil.ParseInt64(fmt.Sprint(globalStat["downloadSpeed"]))
+	if err != nil {
+		return 0, fmt.Errorf("invalid download speed: %w", err)
+	}
+	return speed, nil
+}
+
+// StartHealthCheck 启动 aria2 连接健康检查
+func (s *AppDownloadService) StartHealthCheck() {
+	s.health.Start()
+}
+
+// VerifyAria2 立即检查 aria2 连接和 RPC 密钥，密钥错误返回 ErrAria2Unauthorized，连接失败返回 ErrAria2Unavailable
+func (s *AppDownloadService) VerifyAria2() error {
+	return s.health.Verify()
+}
+
+// PingAria2 调用一次 getVersion 测量 aria2 RPC 往返延迟，同时刷新连接健康状态
+// RPC 客户端不支持 context，ctx 结束时不再等待响应直接返回
+func (s *AppDownloadService) PingAria2(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.health.Verify()
+	}()
+
+	select {
+	case err := <-done:
+		return time.Since(start), err
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	}
+}
+
+// GetAria2Health 获取 aria2 连接健康状态
+func (s *AppDownloadService) GetAria2Health() contracts.ComponentHealth {
+	return s.health.Health()
+}
+
+// AddEventListener 注册下载事件监听器（首次注册时启动下载监控）
+func (s *AppDownloadService) AddEventListener(listener contracts.DownloadEventListener) {
+	s.monitor.AddListener(listener)
+}
+
 // ========== 私有方法 ==========
 
 // validateDownloadRequest 验证下载请求
@@ -363,6 +772,11 @@ func (s *AppDownloadService) validateDownloadRequest(req contracts.DownloadReque
 	if !strings.HasPrefix(req.URL, "http") {
 		return fmt.Errorf("invalid URL format")
 	}
+	if req.MaxDownloadSpeed != "" {
+		if _, err := strutil.ParseSpeedLimit(req.MaxDownloadSpeed); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -377,6 +791,42 @@ func (s *AppDownloadService) applyBusinessRules(req *contracts.DownloadRequest)
 	return nil
 }
 
+// applyUserBasePath 应用发起用户的专属下载基础目录
+// 自动分类/路径模板生成的目录位于 aria2.download_dir 下，将该前缀替换为用户基础目录；
+// 显式指定在 download_dir 之外的目录保持不变
+func (s *AppDownloadService) applyUserBasePath(ctx context.Context, req *contracts.DownloadRequest) {
+	userBase := s.config.Download.UserBasePath(contracts.UserIDFromContext(ctx))
+	if userBase == "" {
+		return
+	}
+
+	directory := resolveUserDirectory(req.Directory, s.config.Aria2.DownloadDir, userBase)
+	if directory != req.Directory {
+		logger.Debug("Applied user download base path", "original", req.Directory, "directory", directory)
+		req.Directory = directory
+	}
+}
+
+// resolveUserDirectory 将目录中的全局基础目录前缀替换为用户基础目录
+func resolveUserDirectory(directory, globalBase, userBase string) string {
+	globalBase = strings.TrimRight(globalBase, "/")
+	switch {
+	case directory == "":
+		return userBase
+	case isUnderDir(directory, userBase):
+		return directory
+	case globalBase != "" && isUnderDir(directory, globalBase):
+		return path.Join(userBase, strings.TrimPrefix(directory, globalBase))
+	default:
+		return directory
+	}
+}
+
+// isUnderDir 判断 p 是否为 dir 本身或其子路径
+func isUnderDir(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
+
 // prepareDownloadOptions 准备下载选项
 func (s *AppDownloadService) prepareDownloadOptions(req contracts.DownloadRequest) map[string]interface{} {
 	options := make(map[string]interface{})
@@ -411,11 +861,64 @@ func (s *AppDownloadService) prepareDownloadOptions(req contracts.DownloadReques
 		options["out"] = req.Filename
 	}
 
-	logger.Debug("Download options prepared", "dir", options["dir"], "out", options["out"])
+	// 设置单任务限速（已在参数验证中校验），统一换算为字节数传给 aria2
+	if limit, err := strutil.ParseSpeedLimit(req.MaxDownloadSpeed); err == nil {
+		options["max-download-limit"] = strconv.FormatInt(limit, 10)
+	}
+
+	// 设置自定义请求头和Cookie
+	if headers := buildHeaderOption(req.Headers, req.Cookie); len(headers) > 0 {
+		switch existing := options["header"].(type) {
+		case []string:
+			headers = append(existing, headers...)
+		case []interface{}:
+			for i := len(existing) - 1; i >= 0; i-- {
+				if h, ok := existing[i].(string); ok {
+					headers = append([]string{h}, headers...)
+				}
+			}
+		case string:
+			headers = append([]string{existing}, headers...)
+		}
+		options["header"] = headers
+	}
+
+	logger.Debug("Download options prepared", "dir", options["dir"], "out", options["out"], "headers", redactHeaderNames(req.Headers, req.Cookie))
 
 	return options
 }
 
+// buildHeaderOption 构建 aria2 header 选项（"Name: value" 列表），按名称排序保证稳定
+func buildHeaderOption(headers map[string]string, cookie string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]string, 0, len(names)+1)
+	for _, name := range names {
+		result = append(result, fmt.Sprintf("%s: %s", name, headers[name]))
+	}
+	if cookie != "" {
+		result = append(result, "Cookie: "+cookie)
+	}
+	return result
+}
+
+// redactHeaderNames 返回仅包含请求头名称的描述，值一律隐藏，用于日志
+func redactHeaderNames(headers map[string]string, cookie string) []string {
+	names := make([]string, 0, len(headers)+1)
+	for name := range headers {
+		names = append(names, name+": ***")
+	}
+	sort.Strings(names)
+	if cookie != "" {
+		names = append(names, "Cookie: ***")
+	}
+	return names
+}
+
 // resolveDirectory 解析目录路径
 func (s *AppDownloadService) resolveDirectory(directory string) string {
 	if directory != "" {
@@ -440,11 +943,35 @@ func (s *AppDownloadService) extractFilename(filename, url string) string {
 	return "unknown_file"
 }
 
+// SetClassificationRules 设置用户配置的分类规则（追加的视频扩展名、始终归为"其他"的扩展名）
+func (s *AppDownloadService) SetClassificationRules(rules *mediaservices.ClassificationRules) {
+	s.rules = rules
+}
+
 // isVideoFile 检查是否为视频文件
 func (s *AppDownloadService) isVideoFile(filename string) bool {
+	if s.rules != nil {
+		return s.rules.IsVideoFile(filename)
+	}
 	return fileutil.IsVideoFile(filename, s.config.Download.VideoExts)
 }
 
+// countNonVideoFile 统计非视频文件（启用音乐/文档分类时单独计数）
+func (s *AppDownloadService) countNonVideoFile(summary *contracts.DownloadSummary, filename string) {
+	category := ""
+	if s.fileService != nil {
+		category = s.fileService.GetFileCategory(filename)
+	}
+	switch category {
+	case "music":
+		summary.MusicFiles++
+	case "document":
+		summary.DocumentFiles++
+	default:
+		summary.OtherFiles++
+	}
+}
+
 // isMovieFile 检查是否为电影文件 - 使用智能路径分类
 func (s *AppDownloadService) isMovieFile(filepath string) bool {
 	if filepath == "" {
@@ -474,6 +1001,8 @@ func (s *AppDownloadService) convertToDownloadResponse(status *aria2.StatusResul
 		ID:           status.GID,
 		Status:       s.convertAriaStatus(status.Status),
 		ErrorMessage: status.ErrorMessage,
+		ErrorCode:    status.ErrorCode,
+		DiskFull:     aria2.IsDiskFullError(status.ErrorCode, status.ErrorMessage),
 		UpdatedAt:    time.Now(),
 	}
 
@@ -494,21 +1023,23 @@ func (s *AppDownloadService) convertToDownloadResponse(status *aria2.StatusResul
 	}
 
 	// 提取文件信息
+	response.Directory = status.Dir
 	if len(status.Files) > 0 {
 		response.Filename = status.Files[0].Path
 		if idx := strings.LastIndex(response.Filename, "/"); idx != -1 {
 			response.Filename = response.Filename[idx+1:]
 		}
+		if len(status.Files[0].URI) > 0 {
+			response.URL = status.Files[0].URI[0].URI
+		}
 	}
 
 	return response
 }
 
 // convertAriaDownloadToResponse 转换Aria2下载对象到响应格式
-func (s *AppDownloadService) convertAriaDownloadToResponse(download interface{}) contracts.DownloadResponse {
-	// 这里需要根据实际的aria2下载对象结构进行转换
-	// 临时实现，需要根据实际结构调整
-	return contracts.DownloadResponse{}
+func (s *AppDownloadService) convertAriaDownloadToResponse(download *aria2.StatusResult) contracts.DownloadResponse {
+	return *s.convertToDownloadResponse(download)
 }
 
 // convertAriaStatus 转换Aria2状态
@@ -549,3 +1080,8 @@ func (s *AppDownloadService) sortDownloads(downloads []contracts.DownloadRespons
 	// 简单实现，实际可以使用更复杂的排序逻辑
 	return downloads
 }
+
+// checkWritable 破坏性操作前检查是否处于只读模式
+func (s *AppDownloadService) checkWritable(operation string) error {
+	return contracts.CheckWritable(s.config != nil && s.config.Server.ReadOnly, operation)
+}
@@ -0,0 +1,489 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
+)
+
+func TestResolveUserDirectory(t *testing.T) {
+	tests := []struct {
+		name      string
+		directory string
+		expected  string
+	}{
+		{
+			name:      "未指定目录使用用户基础目录",
+			directory: "",
+			expected:  "/data/alice",
+		},
+		{
+			name:      "自动分类目录替换基础目录前缀",
+			directory: "/downloads/tvs/庆余年/S02",
+			expected:  "/data/alice/tvs/庆余年/S02",
+		},
+		{
+			name:      "全局基础目录本身",
+			directory: "/downloads",
+			expected:  "/data/alice",
+		},
+		{
+			name:      "已在用户目录下不重复替换",
+			directory: "/data/alice/movies",
+			expected:  "/data/alice/movies",
+		},
+		{
+			name:      "前缀相似但非子目录",
+			directory: "/downloads2/movies",
+			expected:  "/downloads2/movies",
+		},
+		{
+			name:      "显式指定的其他目录保持不变",
+			directory: "/mnt/nas/manual",
+			expected:  "/mnt/nas/manual",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveUserDirectory(tt.directory, "/downloads/", "/data/alice"); got != tt.expected {
+				t.Errorf("resolveUserDirectory(%q) = %q, want %q", tt.directory, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCountCompletedPieces(t *testing.T) {
+	tests := []struct {
+		bitfield string
+		expected int
+	}{
+		{bitfield: "", expected: 0},
+		{bitfield: "00", expected: 0},
+		{bitfield: "ff", expected: 8},
+		{bitfield: "f8", expected: 5},
+		{bitfield: "A0", expected: 2},
+	}
+
+	for _, tt := range tests {
+		if got := countCompletedPieces(tt.bitfield); got != tt.expected {
+			t.Errorf("countCompletedPieces(%q) = %d, want %d", tt.bitfield, got, tt.expected)
+		}
+	}
+}
+
+func TestDeselectFile(t *testing.T) {
+	files := []contracts.DownloadFileDetail{
+		{Index: 1, Path: "/dl/a.mkv", Length: 100, CompletedLength: 10, Selected: true},
+		{Index: 2, Path: "/dl/b.nfo", Length: 10, Selected: false},
+		{Index: 3, Path: "/dl/c.mkv", Length: 100, CompletedLength: 100, Selected: true},
+		{Index: 4, Path: "/dl/d.mkv", Length: 100, Selected: true},
+	}
+
+	tests := []struct {
+		name      string
+		files     []contracts.DownloadFileDetail
+		index     int
+		remaining []string
+		wantErr   bool
+	}{
+		{name: "keeps other selected files", files: files, index: 1, remaining: []string{"3", "4"}},
+		{name: "already deselected", files: files, index: 2, wantErr: true},
+		{name: "already complete", files: files, index: 3, wantErr: true},
+		{name: "unknown index", files: files, index: 9, wantErr: true},
+		{name: "single file stops task", files: files[:1], index: 1, remaining: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining, target, err := deselectFile(tt.files, tt.index)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deselectFile(%d) error = %v, wantErr %v", tt.index, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if target.Index != tt.index || !slices.Equal(remaining, tt.remaining) {
+				t.Errorf("deselectFile(%d) = %v, #%d, want %v", tt.index, remaining, target.Index, tt.remaining)
+			}
+		})
+	}
+}
+
+func TestPreemptionVictims(t *testing.T) {
+	active := []contracts.DownloadResponse{
+		{ID: "almost-done", TotalSize: 100, CompletedSize: 90},
+		{ID: "just-started", TotalSize: 100, CompletedSize: 5},
+		{ID: "halfway", TotalSize: 200, CompletedSize: 100},
+	}
+
+	tests := []struct {
+		count int
+		want  []string
+	}{
+		{count: 0, want: nil},
+		{count: 1, want: []string{"halfway"}},
+		{count: 2, want: []string{"halfway", "just-started"}},
+		{count: 5, want: []string{"halfway", "just-started", "almost-done"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, d := range preemptionVictims(active, tt.count) {
+			got = append(got, d.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("preemptionVictims(%d) = %v, want %v", tt.count, got, tt.want)
+		}
+	}
+	if active[0].ID != "almost-done" {
+		t.Errorf("preemptionVictims modified its input")
+	}
+}
+
+func TestBoostValue(t *testing.T) {
+	tests := []struct {
+		current, limit, want int
+	}{
+		{current: 1, limit: 16, want: 2},
+		{current: 5, limit: 32, want: 10},
+		{current: 12, limit: 16, want: 16},
+		{current: 16, limit: 16, want: 16},
+		{current: 20, limit: 16, want: 20}, // 手动设置的值高于上限时不降低
+		{current: 0, limit: 16, want: 2},   // 读取不到当前值时按1计算
+	}
+
+	for _, tt := range tests {
+		if got := boostValue(tt.current, tt.limit); got != tt.want {
+			t.Errorf("boostValue(%d, %d) = %d, want %d", tt.current, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestPlanCategoryLimits(t *testing.T) {
+	limits := map[string]int{"movie": 2, "tv": 4}
+	tests := []struct {
+		name       string
+		tasks      []queuedTask
+		held       map[string]bool
+		wantPause  []string
+		wantResume []string
+	}{
+		{
+			name: "超出上限的电影暂停，剧集和未限制的分类不受影响",
+			tasks: []queuedTask{
+				{gid: "m1", category: "movie"},
+				{gid: "t1", category: "tv"},
+				{gid: "m2", category: "movie"},
+				{gid: "m3", category: "movie"},
+				{gid: "o1", category: "other"},
+				{gid: "", category: ""},
+			},
+			wantPause: []string{"m3"},
+		},
+		{
+			name: "有空位时按队列顺序恢复限制暂停的任务",
+			tasks: []queuedTask{
+				{gid: "m1", category: "movie"},
+				{gid: "m2", category: "movie", paused: true},
+				{gid: "m3", category: "movie", paused: true},
+			},
+			held:       map[string]bool{"m2": true, "m3": true},
+			wantResume: []string{"m2"},
+		},
+		{
+			name: "用户手动暂停的任务不恢复",
+			tasks: []queuedTask{
+				{gid: "m1", category: "movie", paused: true},
+				{gid: "m2", category: "movie", paused: true},
+			},
+			held:       map[string]bool{"m2": true},
+			wantResume: []string{"m2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pause, resume := planCategoryLimits(tt.tasks, tt.held, limits)
+			if !slices.Equal(pause, tt.wantPause) || !slices.Equal(resume, tt.wantResume) {
+				t.Errorf("planCategoryLimits() = pause %v resume %v, want pause %v resume %v", pause, resume, tt.wantPause, tt.wantResume)
+			}
+		})
+	}
+}
+
+func TestPauseResumeDownload(t *testing.T) {
+	// 模拟 aria2：记录调用的方法，未知 GID 与 aria2 一样返回 "GID xxx is not found"
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req aria2.RPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gid, _ := req.Params[0].(string)
+		calls = append(calls, req.Method+":"+gid)
+		if gid != "2089b05ecca3d829" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "error": map[string]any{"code": 1, "message": "GID " + gid + " is not found"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": gid})
+	}))
+	defer server.Close()
+
+	s := &AppDownloadService{aria2Client: aria2.NewClient(server.URL, ""), health: NewAria2HealthChecker(nil, 0)}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		call     func(context.Context, string) error
+		gid      string
+		wantCall string
+		wantErr  error
+	}{
+		{name: "暂停", call: s.PauseDownload, gid: "2089b05ecca3d829", wantCall: "aria2.pause:2089b05ecca3d829"},
+		{name: "恢复", call: s.ResumeDownload, gid: "2089b05ecca3d829", wantCall: "aria2.unpause:2089b05ecca3d829"},
+		{name: "暂停不存在的任务", call: s.PauseDownload, gid: "ffffffffffffffff", wantCall: "aria2.pause:ffffffffffffffff", wantErr: contracts.ErrDownloadNotFound},
+		{name: "恢复不存在的任务", call: s.ResumeDownload, gid: "ffffffffffffffff", wantCall: "aria2.unpause:ffffffffffffffff", wantErr: contracts.ErrDownloadNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			err := tt.call(ctx, tt.gid)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if len(calls) != 1 || calls[0] != tt.wantCall {
+				t.Errorf("RPC calls = %v, want [%s]", calls, tt.wantCall)
+			}
+		})
+	}
+}
+
+func TestCancelDownloads(t *testing.T) {
+	// 模拟 aria2：a 取消成功，b 不存在，c 返回其他错误
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req aria2.RPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gid, _ := req.Params[0].(string)
+		calls = append(calls, req.Method+":"+gid)
+		switch gid {
+		case "a":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": gid})
+		case "b":
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "error": map[string]any{"code": 1, "message": "GID b is not found"}})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "error": map[string]any{"code": 1, "message": "Cannot remove GID " + gid}})
+		}
+	}))
+	defer server.Close()
+
+	s := &AppDownloadService{aria2Client: aria2.NewClient(server.URL, ""), health: NewAria2HealthChecker(nil, 0)}
+	result := s.CancelDownloads(context.Background(), []string{"a", "b", "c", "a", ""})
+
+	if len(calls) != 3 {
+		t.Errorf("RPC calls = %v, want one aria2.remove per unique GID", calls)
+	}
+	if result.Total != 3 || result.Cancelled != 1 || result.NotFound != 1 || result.Failed != 1 {
+		t.Errorf("result = %+v, want total 3, cancelled 1, not found 1, failed 1", result)
+	}
+	want := []string{contracts.CancelStatusCancelled, contracts.CancelStatusNotFound, contracts.CancelStatusFailed}
+	for i, item := range result.Items {
+		if item.Status != want[i] {
+			t.Errorf("item %s status = %s, want %s", item.ID, item.Status, want[i])
+		}
+		if (item.Status == contracts.CancelStatusCancelled) != (item.Error == "") {
+			t.Errorf("item %s error = %q with status %s", item.ID, item.Error, item.Status)
+		}
+	}
+}
+
+func TestListDownloadsPagination(t *testing.T) {
+	// 模拟 aria2：2 个下载中、3 个等待中（1 个已暂停）、5 个已停止，记录 tellStopped 的分页参数
+	task := func(gid, status string) map[string]any { return map[string]any{"gid": gid, "status": status} }
+	var stoppedCalls [][]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req aria2.RPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var result any
+		switch req.Method {
+		case "aria2.tellActive":
+			result = []any{task("a1", "active"), task("a2", "active")}
+		case "aria2.tellWaiting":
+			result = []any{task("w1", "waiting"), task("w2", "paused"), task("w3", "waiting")}
+		case "aria2.tellStopped":
+			stoppedCalls = append(stoppedCalls, req.Params)
+			offset, num := int(req.Params[0].(float64)), int(req.Params[1].(float64))
+			var stopped []any
+			for i := offset; i < min(offset+num, 5); i++ {
+				stopped = append(stopped, task(fmt.Sprintf("s%d", i+1), "complete"))
+			}
+			result = stopped
+		case "aria2.getGlobalStat":
+			result = map[string]any{"numActive": "2", "numWaiting": "3", "numStopped": "5"}
+		default:
+			result = map[string]any{}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": result})
+	}))
+	defer server.Close()
+
+	s := &AppDownloadService{aria2Client: aria2.NewClient(server.URL, ""), health: NewAria2HealthChecker(nil, 0)}
+
+	tests := []struct {
+		name        string
+		req         contracts.DownloadListRequest
+		wantIDs     []string
+		wantTotal   int
+		wantStopped [][]any
+	}{
+		{name: "第一页不获取已停止任务", req: contracts.DownloadListRequest{Limit: 3}, wantIDs: []string{"a1", "a2", "w1"}, wantTotal: 10},
+		{name: "跨越等待和已停止", req: contracts.DownloadListRequest{Offset: 4, Limit: 3}, wantIDs: []string{"w3", "s1", "s2"}, wantTotal: 10, wantStopped: [][]any{{float64(0), float64(2)}}},
+		{name: "只含已停止", req: contracts.DownloadListRequest{Offset: 8, Limit: 8}, wantIDs: []string{"s4", "s5"}, wantTotal: 10, wantStopped: [][]any{{float64(3), float64(2)}}},
+		{name: "超出末尾", req: contracts.DownloadListRequest{Offset: 20, Limit: 8}, wantTotal: 10},
+		{name: "按暂停状态过滤", req: contracts.DownloadListRequest{Status: "paused", Limit: 8}, wantIDs: []string{"w2"}, wantTotal: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stoppedCalls = nil
+			resp, err := s.ListDownloads(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("ListDownloads() error = %v", err)
+			}
+			var ids []string
+			for _, d := range resp.Downloads {
+				ids = append(ids, d.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) || resp.TotalCount != tt.wantTotal {
+				t.Errorf("ids = %v, total = %d, want %v, %d", ids, resp.TotalCount, tt.wantIDs, tt.wantTotal)
+			}
+			if fmt.Sprint(stoppedCalls) != fmt.Sprint(tt.wantStopped) {
+				t.Errorf("tellStopped calls = %v, want %v", stoppedCalls, tt.wantStopped)
+			}
+			if resp.PausedCount != 1 || resp.ActiveCount != 2 {
+				t.Errorf("paused = %d, active = %d, want 1, 2", resp.PausedCount, resp.ActiveCount)
+			}
+		})
+	}
+}
+
+func TestListDownloadsSpeedLimits(t *testing.T) {
+	// 模拟 aria2：3 个下载中的任务，a1 限速 1M，a3 已被清除；限速应通过一次 system.multicall 读取
+	methods := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req aria2.RPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		methods[req.Method]++
+		var result any
+		switch req.Method {
+		case "aria2.tellActive":
+			result = []any{
+				map[string]any{"gid": "a1", "status": "active"},
+				map[string]any{"gid": "a2", "status": "active"},
+				map[string]any{"gid": "a3", "status": "active"},
+			}
+		case "system.multicall":
+			var results []any
+			for _, call := range req.Params[0].([]any) {
+				params := call.(map[string]any)["params"].([]any)
+				if params[0] != "token:secret" {
+					t.Errorf("multicall params = %v, want token first", params)
+				}
+				switch params[1] {
+				case "a1":
+					results = append(results, []any{map[string]any{"max-download-limit": "1048576"}})
+				case "a2":
+					results = append(results, []any{map[string]any{"max-download-limit": "0"}})
+				default:
+					results = append(results, map[string]any{"code": 1, "message": "GID a3 is not found"})
+				}
+			}
+			result = results
+		case "aria2.tellWaiting", "aria2.tellStopped":
+			result = []any{}
+		default:
+			result = map[string]any{"numStopped": "0"}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": result})
+	}))
+	defer server.Close()
+
+	s := &AppDownloadService{aria2Client: aria2.NewClient(server.URL, "secret"), health: NewAria2HealthChecker(nil, 0)}
+	resp, err := s.ListDownloads(context.Background(), contracts.DownloadListRequest{})
+	if err != nil {
+		t.Fatalf("ListDownloads() error = %v", err)
+	}
+
+	limits := map[string]int64{}
+	for _, d := range resp.Downloads {
+		limits[d.ID] = d.SpeedLimit
+	}
+	want := map[string]int64{"a1": 1048576, "a2": 0, "a3": 0}
+	if fmt.Sprint(limits) != fmt.Sprint(want) {
+		t.Errorf("speed limits = %v, want %v", limits, want)
+	}
+	if methods["system.multicall"] != 1 || methods["aria2.getOption"] != 0 {
+		t.Errorf("multicall = %d, getOption = %d, want 1, 0", methods["system.multicall"], methods["aria2.getOption"])
+	}
+}
+
+func TestChangePosition(t *testing.T) {
+	// 模拟 aria2：w 在等待队列中，a 正在下载（aria2 对不在等待队列中的任务返回 "not found in the waiting queue"）
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req aria2.RPCRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		calls++
+		gid, _ := req.Params[0].(string)
+		if gid != "w" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "error": map[string]any{"code": 1, "message": "GID#" + gid + " not found in the waiting queue."}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": req.ID, "jsonrpc": "2.0", "result": 0})
+	}))
+	defer server.Close()
+
+	s := &AppDownloadService{aria2Client: aria2.NewClient(server.URL, ""), health: NewAria2HealthChecker(nil, 0)}
+
+	tests := []struct {
+		name     string
+		gid      string
+		pos      int
+		how      string
+		wantCall bool
+		wantErr  error
+	}{
+		{name: "置顶", gid: "w", pos: 0, how: contracts.PositionFromStart, wantCall: true},
+		{name: "相对当前位置前移", gid: "w", pos: -2, how: contracts.PositionFromCurrent, wantCall: true},
+		{name: "下载中的任务", gid: "a", pos: 0, how: contracts.PositionFromEnd, wantCall: true, wantErr: contracts.ErrDownloadNotWaiting},
+		{name: "相对队首为负数", gid: "w", pos: -1, how: contracts.PositionFromStart},
+		{name: "相对队尾为正数", gid: "w", pos: 1, how: contracts.PositionFromEnd},
+		{name: "未知调整方式", gid: "w", pos: 0, how: "POS_TOP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			_, err := s.ChangePosition(context.Background(), tt.gid, tt.pos, tt.how)
+			switch {
+			case !tt.wantCall && err == nil:
+				t.Fatalf("ChangePosition() error = nil, want validation error")
+			case tt.wantCall && tt.wantErr == nil && err != nil:
+				t.Fatalf("ChangePosition() error = %v", err)
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Fatalf("ChangePosition() error = %v, want %v", err, tt.wantErr)
+			}
+			if (calls > 0) != tt.wantCall {
+				t.Errorf("RPC calls = %d, want call %v", calls, tt.wantCall)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package download
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	fileutil "github.com/easayliu/alist-aria2-download/pkg/utils/file"
+)
+
+// maxSubtitleListSize 查找字幕时读取源目录的最多条目数
+const maxSubtitleListSize = 1000
+
+// enqueueSubtitles 在视频的 Alist 源目录中查找同名字幕（含语言后缀），下载到视频所在目录
+// 字幕文件名按视频保存的文件名重命名（Movie.en.srt -> 视频名.en.srt），返回创建了任务的字幕文件名
+func (s *AppDownloadService) enqueueSubtitles(ctx context.Context, video contracts.DownloadRequest, response *contracts.DownloadResponse) []string {
+	if s.fileService == nil || video.SourcePath == "" {
+		logger.Debug("Subtitle download skipped, no source directory", "filename", response.Filename)
+		return nil
+	}
+
+	sourceDir := path.Dir(video.SourcePath)
+	listing, err := s.fileService.ListFiles(ctx, contracts.FileListRequest{
+		Path:     sourceDir,
+		Page:     1,
+		PageSize: maxSubtitleListSize,
+	})
+	if err != nil {
+		logger.Warn("Failed to list source directory for subtitles", "path", sourceDir, "error", err)
+		return nil
+	}
+
+	videoBase := strings.TrimSuffix(response.Filename, path.Ext(response.Filename))
+	var subtitles []string
+	for _, file := range listing.Files {
+		suffix, ok := fileutil.MatchSubtitle(video.SourcePath, file.Name)
+		if !ok {
+			continue
+		}
+
+		// ListFiles 不返回下载链接，逐个获取
+		info, err := s.fileService.GetFileInfo(ctx, file.Path)
+		if err != nil || info.InternalURL == "" {
+			logger.Warn("Failed to get subtitle download URL", "path", file.Path, "error", err)
+			continue
+		}
+
+		req := contracts.DownloadRequest{
+			URL:              info.InternalURL,
+			Filename:         videoBase + suffix,
+			Directory:        video.Directory,
+			Options:          video.Options,
+			FileSize:         file.Size,
+			SourcePath:       file.Path,
+			Headers:          video.Headers,
+			Cookie:           video.Cookie,
+			MaxDownloadSpeed: video.MaxDownloadSpeed,
+		}
+		if _, err := s.submitDownload(ctx, req); err != nil {
+			logger.Warn("Failed to create subtitle download", "path", file.Path, "error", err)
+			continue
+		}
+		subtitles = append(subtitles, req.Filename)
+	}
+
+	logger.Info("Subtitle downloads created", "video", response.Filename, "subtitles", len(subtitles))
+	return subtitles
+}
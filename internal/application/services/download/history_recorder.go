@@ -0,0 +1,63 @@
+package download
+
+import (
+	"context"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// HistoryRecorder 下载历史记录器 - 按 GID 记录每次下载尝试及其结果，带源文件路径的记录可按源文件查询
+type HistoryRecorder struct {
+	repo       *repository.DownloadHistoryRepository
+	categoryOf func(dir string) string // 根据保存目录推断分类，为空时不记录分类
+}
+
+// NewHistoryRecorder 创建下载历史记录器
+func NewHistoryRecorder(repo *repository.DownloadHistoryRepository) *HistoryRecorder {
+	return &HistoryRecorder{repo: repo}
+}
+
+// SetCategoryResolver 设置根据保存目录推断分类的方法，下载完成时记录分类用于统计
+func (r *HistoryRecorder) SetCategoryResolver(categoryOf func(dir string) string) {
+	r.categoryOf = categoryOf
+}
+
+// HandleEvent 处理下载事件（实现 contracts.DownloadEventListener）
+// 已完成的下载全部记录（用于统计），其它事件只记录带源文件路径的任务（来自 Alist 的下载）
+func (r *HistoryRecorder) HandleEvent(ctx context.Context, event contracts.DownloadEvent) {
+	var sourcePath string
+	download := event.Download
+	totalSize := download.TotalSize
+	if req := event.Request; req != nil {
+		sourcePath = req.SourcePath
+		if totalSize == 0 {
+			totalSize = req.FileSize
+		}
+	}
+	if sourcePath == "" && event.Type != contracts.DownloadEventCompleted {
+		return
+	}
+
+	record := &entities.DownloadRecord{
+		ID:           download.ID,
+		SourcePath:   sourcePath,
+		Filename:     download.Filename,
+		Directory:    download.Directory,
+		Status:       download.Status,
+		TotalSize:    totalSize,
+		ErrorMessage: download.ErrorMessage,
+	}
+	if event.Type == contracts.DownloadEventCompleted {
+		record.CompletedAt = time.Now()
+		if r.categoryOf != nil {
+			record.Category = r.categoryOf(download.Directory)
+		}
+	}
+	if err := r.repo.Save(record); err != nil {
+		logger.Warn("Failed to save download history", "gid", download.ID, "path", sourcePath, "error", err)
+	}
+}
@@ -0,0 +1,73 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+)
+
+func TestHistoryRecorderHandleEvent(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "download_history.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	history, err := repository.NewDownloadHistoryRepository(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := NewHistoryRecorder(history)
+
+	tests := []struct {
+		name   string
+		event  contracts.DownloadEvent
+		stored bool
+	}{
+		{
+			name: "Alist 下载失败",
+			event: contracts.DownloadEvent{
+				Type:     contracts.DownloadEventFailed,
+				Download: contracts.DownloadResponse{ID: "alist", Status: valueobjects.DownloadStatusError},
+				Request:  &contracts.DownloadRequest{SourcePath: "/a/E01.mkv", FileSize: 100},
+			},
+			stored: true,
+		},
+		{
+			name: "非本进程创建的下载完成",
+			event: contracts.DownloadEvent{
+				Type:     contracts.DownloadEventCompleted,
+				Download: contracts.DownloadResponse{ID: "external", Status: valueobjects.DownloadStatusComplete, TotalSize: 200},
+			},
+			stored: true,
+		},
+		{
+			name: "没有源文件路径的下载失败",
+			event: contracts.DownloadEvent{
+				Type:     contracts.DownloadEventFailed,
+				Download: contracts.DownloadResponse{ID: "magnet", Status: valueobjects.DownloadStatusError},
+				Request:  &contracts.DownloadRequest{URL: "magnet:?xt=urn:btih:abc"},
+			},
+			stored: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.HandleEvent(context.Background(), tt.event)
+			if _, ok := history.GetByID(tt.event.Download.ID); ok != tt.stored {
+				t.Errorf("stored = %v, want %v", ok, tt.stored)
+			}
+		})
+	}
+
+	if records := history.GetBySourcePath("/a/E01.mkv"); len(records) != 1 || records[0].TotalSize != 100 {
+		t.Errorf("GetBySourcePath() = %+v, want the Alist record with the request size", records)
+	}
+	if records := history.GetBySourcePath(""); len(records) != 0 {
+		t.Errorf("GetBySourcePath(\"\") = %d records, want none", len(records))
+	}
+}
@@ -0,0 +1,123 @@
+package download
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// movieNFO Emby/Jellyfin 电影 NFO
+type movieNFO struct {
+	XMLName xml.Name `xml:"movie"`
+	Title   string   `xml:"title"`
+	Year    int      `xml:"year,omitempty"`
+}
+
+// episodeNFO Emby/Jellyfin 剧集单集 NFO
+type episodeNFO struct {
+	XMLName   xml.Name `xml:"episodedetails"`
+	Title     string   `xml:"title,omitempty"`
+	ShowTitle string   `xml:"showtitle"`
+	Season    int      `xml:"season"`
+	Episode   int      `xml:"episode"`
+}
+
+// NFOWriter 下载完成后在媒体文件旁生成 .nfo 元数据文件
+// 媒体信息取自源文件路径（已按 TMDB 重命名的文件直接使用其中的名称），生成失败只记录日志
+type NFOWriter struct {
+	fileService contracts.FileService
+}
+
+// NewNFOWriter 创建 NFO 生成器
+func NewNFOWriter(fileService contracts.FileService) *NFOWriter {
+	return &NFOWriter{fileService: fileService}
+}
+
+// HandleEvent 处理下载事件（实现 contracts.DownloadEventListener）
+func (w *NFOWriter) HandleEvent(ctx context.Context, event contracts.DownloadEvent) {
+	if event.Type != contracts.DownloadEventCompleted || event.Download.Filename == "" {
+		return
+	}
+	if !w.fileService.IsVideoFile(event.Download.Filename) {
+		return
+	}
+
+	mediaPath := filepath.Join(event.Download.Directory, event.Download.Filename)
+	// 源路径保留了 Alist 中的目录结构（剧名/季度），优先用它解析
+	sourcePath := filepath.ToSlash(mediaPath)
+	if event.Request != nil && event.Request.SourcePath != "" {
+		sourcePath = event.Request.SourcePath
+	}
+
+	content, err := renderNFO(w.fileService.ParseMediaInfo(sourcePath))
+	if err != nil {
+		logger.Debug("NFO skipped", "path", sourcePath, "reason", err)
+		return
+	}
+
+	nfoPath := strings.TrimSuffix(mediaPath, filepath.Ext(mediaPath)) + ".nfo"
+	if err := writeNFO(nfoPath, content); err != nil {
+		logger.Warn("Failed to write NFO", "path", nfoPath, "gid", event.Download.ID, "error", err)
+		return
+	}
+	logger.Info("NFO written", "path", nfoPath, "gid", event.Download.ID)
+}
+
+// renderNFO 按媒体类型生成电影或剧集 NFO 内容，信息不足时返回错误
+func renderNFO(media contracts.MediaInfo) ([]byte, error) {
+	title := media.Title
+	if media.TitleCN != "" {
+		title = media.TitleCN
+	}
+	if title == "" {
+		return nil, fmt.Errorf("no title")
+	}
+
+	var doc any
+	switch tmdb.MediaType(media.Type) {
+	case tmdb.MediaTypeMovie:
+		doc = movieNFO{Title: title, Year: media.Year}
+	case tmdb.MediaTypeTV:
+		if media.Season == nil || media.Episode == nil {
+			return nil, fmt.Errorf("no season/episode")
+		}
+		doc = episodeNFO{Title: media.EpisodeTitle, ShowTitle: title, Season: *media.Season, Episode: *media.Episode}
+	default:
+		return nil, fmt.Errorf("unsupported media type %q", media.Type)
+	}
+
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+// writeNFO 写入 NFO 文件；媒体文件不在本机时跳过，已存在的 NFO（可能由媒体服务器或用户维护）不覆盖
+func writeNFO(nfoPath string, content []byte) error {
+	if _, err := os.Stat(filepath.Dir(nfoPath)); err != nil {
+		return fmt.Errorf("download directory not accessible: %w", err)
+	}
+	f, err := os.OpenFile(nfoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			logger.Debug("NFO already exists, skipped", "path", nfoPath)
+			return nil
+		}
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(nfoPath)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,60 @@
+package download
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+)
+
+func TestRenderNFO(t *testing.T) {
+	season, episode := 2, 6
+
+	tests := []struct {
+		name    string
+		media   contracts.MediaInfo
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "电影",
+			media: contracts.MediaInfo{Type: "movie", Title: "Inception", Year: 2010},
+			want:  []string{"<movie>", "<title>Inception</title>", "<year>2010</year>"},
+		},
+		{
+			name:  "剧集",
+			media: contracts.MediaInfo{Type: "tv", Title: "新闻女王", Season: &season, Episode: &episode, EpisodeTitle: "A & B"},
+			want:  []string{"<episodedetails>", "<title>A &amp; B</title>", "<showtitle>新闻女王</showtitle>", "<season>2</season>", "<episode>6</episode>"},
+		},
+		{
+			name:    "剧集缺少集数",
+			media:   contracts.MediaInfo{Type: "tv", Title: "新闻女王", Season: &season},
+			wantErr: true,
+		},
+		{
+			name:    "没有标题",
+			media:   contracts.MediaInfo{Type: "movie"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderNFO(tt.media)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("renderNFO() = %s, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderNFO() error = %v", err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(string(got), w) {
+					t.Errorf("renderNFO() missing %q in:\n%s", w, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package download
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// SourceCleanup 下载完成后删除 Alist 源文件
+// 仅处理显式开启 DeleteAfterDownload 的任务，且只有在下载完整、大小校验一致时才删除
+type SourceCleanup struct {
+	config              *config.Config
+	fileService         contracts.FileService
+	notificationService contracts.NotificationService
+}
+
+// NewSourceCleanup 创建源文件清理器
+func NewSourceCleanup(cfg *config.Config, fileService contracts.FileService, notificationService contracts.NotificationService) *SourceCleanup {
+	return &SourceCleanup{
+		config:              cfg,
+		fileService:         fileService,
+		notificationService: notificationService,
+	}
+}
+
+// HandleEvent 处理下载事件（实现 contracts.DownloadEventListener）
+func (c *SourceCleanup) HandleEvent(ctx context.Context, event contracts.DownloadEvent) {
+	req := event.Request
+	if req == nil || !req.DeleteAfterDownload || req.SourcePath == "" || event.Type == contracts.DownloadEventCreated {
+		return
+	}
+
+	// 配置未开启时一律不删除
+	if !c.config.Download.AllowDeleteAfterDownload {
+		logger.Warn("Delete after download requested but disabled in config", "path", req.SourcePath, "gid", event.Download.ID)
+		return
+	}
+
+	// 失败/被移除的下载绝不删除源文件
+	if event.Type != contracts.DownloadEventCompleted {
+		logger.Info("Download not completed, keeping source file", "path", req.SourcePath, "gid", event.Download.ID, "event", event.Type)
+		return
+	}
+
+	if err := verifyDownloadedSize(event.Download, req.FileSize); err != nil {
+		logger.Warn("Download size verification failed, keeping source file", "path", req.SourcePath, "gid", event.Download.ID, "error", err)
+		c.notify(ctx, contracts.NotificationLevelWarning, "source_delete_skipped",
+			fmt.Sprintf("%s: 大小校验未通过，未删除源文件 (%v)", req.SourcePath, err))
+		return
+	}
+
+	if err := c.fileService.DeleteFile(ctx, req.SourcePath); err != nil {
+		logger.Error("Failed to delete source file after download", "path", req.SourcePath, "gid", event.Download.ID, "error", err)
+		c.notify(ctx, contracts.NotificationLevelError, "source_delete_failed",
+			fmt.Sprintf("%s: 删除源文件失败 (%v)", req.SourcePath, err))
+		return
+	}
+
+	logger.Info("Source file deleted after download", "path", req.SourcePath, "gid", event.Download.ID, "size", event.Download.TotalSize)
+	c.notify(ctx, contracts.NotificationLevelInfo, "source_deleted",
+		fmt.Sprintf("%s: 下载完成并校验通过，已删除源文件", req.SourcePath))
+}
+
+// notify 发送删除结果通知
+func (c *SourceCleanup) notify(ctx context.Context, level contracts.NotificationLevel, event, message string) {
+	if c.notificationService == nil {
+		return
+	}
+	if err := c.notificationService.NotifySystemEvent(ctx, contracts.SystemNotificationRequest{
+		Component: "alist",
+		Event:     event,
+		Level:     level,
+		Message:   message,
+	}); err != nil {
+		logger.Warn("Failed to send source cleanup notification", "event", event, "error", err)
+	}
+}
+
+// verifyDownloadedSize 校验下载大小：必须完整下载，且与源文件大小一致；源文件大小未知时拒绝删除
+func verifyDownloadedSize(download contracts.DownloadResponse, expectedSize int64) error {
+	if download.TotalSize <= 0 {
+		return fmt.Errorf("unknown total size")
+	}
+	if download.CompletedSize != download.TotalSize {
+		return fmt.Errorf("incomplete download: %d/%d bytes", download.CompletedSize, download.TotalSize)
+	}
+	if expectedSize <= 0 {
+		return fmt.Errorf("unknown source size")
+	}
+	if download.TotalSize != expectedSize {
+		return fmt.Errorf("size mismatch: downloaded %d bytes, source %d bytes", download.TotalSize, expectedSize)
+	}
+	return nil
+}
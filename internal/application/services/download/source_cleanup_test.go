@@ -0,0 +1,31 @@
+package download
+
+import (
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+)
+
+func TestVerifyDownloadedSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		download contracts.DownloadResponse
+		expected int64
+		wantErr  bool
+	}{
+		{"完整且大小一致", contracts.DownloadResponse{TotalSize: 100, CompletedSize: 100}, 100, false},
+		{"未下载完整", contracts.DownloadResponse{TotalSize: 100, CompletedSize: 90}, 100, true},
+		{"大小不一致", contracts.DownloadResponse{TotalSize: 100, CompletedSize: 100}, 120, true},
+		{"下载大小未知", contracts.DownloadResponse{}, 100, true},
+		{"源文件大小未知时拒绝删除", contracts.DownloadResponse{TotalSize: 100, CompletedSize: 100}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyDownloadedSize(tt.download, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyDownloadedSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
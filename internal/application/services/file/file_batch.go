@@ -3,8 +3,11 @@ package file
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 )
 
@@ -26,6 +29,7 @@ func (s *AppFileService) DownloadFiles(ctx context.Context, req contracts.BatchF
 
 		// 使用统一的方法构建下载请求
 		downloadReq := s.buildDownloadRequest(*fileInfo, fileReq.TargetDir, fileReq.AutoClassify, fileReq.Options)
+		downloadReq.DeleteAfterDownload = fileReq.DeleteAfterDownload
 
 		// 应用全局设置
 		if req.TargetDir != "" && downloadReq.Directory == fileReq.TargetDir {
@@ -39,10 +43,11 @@ func (s *AppFileService) DownloadFiles(ctx context.Context, req contracts.BatchF
 	}
 
 	batchReq := contracts.BatchDownloadRequest{
-		Items:        downloadRequests,
-		Directory:    req.TargetDir,
-		VideoOnly:    req.VideoOnly,
-		AutoClassify: req.AutoClassify,
+		Items:               downloadRequests,
+		Directory:           req.TargetDir,
+		VideoOnly:           req.VideoOnly,
+		AutoClassify:        req.AutoClassify,
+		DeleteAfterDownload: req.DeleteAfterDownload,
 	}
 
 	return s.downloadService.CreateBatchDownload(ctx, batchReq)
@@ -57,20 +62,61 @@ func (s *AppFileService) DownloadDirectory(ctx context.Context, req contracts.Di
 
 	// 获取目录下的所有文件
 	listReq := contracts.FileListRequest{
-		Path:      req.DirectoryPath,
-		Recursive: req.Recursive,
-		VideoOnly: req.VideoOnly,
-		PageSize:  10000,
+		Path:          req.DirectoryPath,
+		Recursive:     req.Recursive,
+		VideoOnly:     req.VideoOnly,
+		PageSize:      10000,
+		IncludeHidden: req.IncludeHidden,
 	}
 
 	listResp, err := s.ListFiles(ctx, listReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list directory: %w", err)
 	}
+	if listResp.Truncated {
+		logger.Warn("Directory too large, downloading only the listed files", "path", req.DirectoryPath, "files", len(listResp.Files))
+	}
+
+	files := listResp.Files
+	if req.Filtered() {
+		files = slices.DeleteFunc(slices.Clone(files), func(file contracts.FileResponse) bool {
+			return !req.Matches(file)
+		})
+		logger.Info("Directory download filtered", "path", req.DirectoryPath, "after", req.ModifiedAfter, "extensions", req.Extensions, "matched", len(files), "listed", len(listResp.Files))
+	}
+
+	// 继续上次中断的下载时跳过断点中已提交的文件，否则开始新的断点（筛选下载只提交部分文件，不记录断点）
+	resumed := 0
+	var checkpoint *entities.DirectoryDownloadCheckpoint
+	if req.Resume && s.checkpoints != nil {
+		if previous, ok := s.checkpoints.Get(req.DirectoryPath); ok {
+			checkpoint = previous
+			req.DeleteAfterDownload = previous.DeleteAfterDownload
+			queued := make(map[string]struct{}, len(previous.Queued))
+			for _, path := range previous.Queued {
+				queued[path] = struct{}{}
+			}
+			listed := len(files)
+			files = slices.DeleteFunc(slices.Clone(files), func(file contracts.FileResponse) bool {
+				_, done := queued[file.Path]
+				return done
+			})
+			resumed = listed - len(files)
+			logger.Info("Resuming directory download from checkpoint", "path", req.DirectoryPath, "queued", len(previous.Queued), "remaining", len(files))
+		}
+	}
+	if req.Preview {
+		return s.previewDirectoryDownload(ctx, req, files, resumed), nil
+	}
+	if checkpoint == nil && s.checkpoints != nil && !req.Filtered() {
+		if checkpoint, err = s.checkpoints.Start(req.DirectoryPath, len(files), req.DeleteAfterDownload); err != nil {
+			logger.Warn("Failed to save directory download checkpoint", "path", req.DirectoryPath, "error", err)
+		}
+	}
 
 	// 转换为下载请求
 	var downloadRequests []contracts.DownloadRequest
-	for _, file := range listResp.Files {
+	for _, file := range files {
 		// 动态获取真实的下载URL（ListFiles返回的文件InternalURL为空，采用延迟加载）
 		logger.Debug("Getting download URL for file in directory", "file", file.Name, "path", file.Path, "size", file.Size)
 		internalURL, _ := s.getRealDownloadURLs(file.Path)
@@ -80,17 +126,88 @@ func (s *AppFileService) DownloadDirectory(ctx context.Context, req contracts.Di
 
 		// 使用统一的方法构建下载请求
 		downloadReq := s.buildDownloadRequest(file, req.TargetDir, req.AutoClassify, nil)
+		downloadReq.DeleteAfterDownload = req.DeleteAfterDownload
+		downloadReq.Priority = s.directoryDownloadPriority(file)
 
 		downloadRequests = append(downloadRequests, downloadReq)
 		logger.Debug("Download request created", "file", file.Name, "fileSize", downloadReq.FileSize)
 	}
 
-	batchReq := contracts.BatchDownloadRequest{
-		Items:        downloadRequests,
-		Directory:    req.TargetDir,
-		VideoOnly:    req.VideoOnly,
-		AutoClassify: req.AutoClassify,
+	batchReq := directoryBatchRequest(req, downloadRequests)
+	if checkpoint != nil {
+		batchReq.CheckpointID = checkpoint.ID
 	}
+	// 文件过多时分批提交，避免一次性塞满 aria2 队列
+	batchReq.ChunkSize = s.config.Aria2.BatchChunkSize
+	batchReq.SourceDir = req.DirectoryPath
+	batchReq.NotifyChatID = req.NotifyChatID
 
-	return s.downloadService.CreateBatchDownload(ctx, batchReq)
+	resp, err := s.downloadService.CreateBatchDownload(ctx, batchReq)
+	if err != nil {
+		return nil, err
+	}
+	resp.ResumedCount = resumed
+
+	// 全部文件已提交（失败的文件由批量重试处理），断点不再需要；分批提交时由最后一批完成断点
+	if checkpoint != nil && resp.PendingCount == 0 {
+		if err := s.checkpoints.Complete(checkpoint.ID); err != nil {
+			logger.Warn("Failed to remove directory download checkpoint", "path", req.DirectoryPath, "error", err)
+		}
+	}
+	return resp, nil
+}
+
+// previewDirectoryDownload 按与实际下载相同的规则统计将要提交的文件数、总大小和分类，不创建任务
+func (s *AppFileService) previewDirectoryDownload(ctx context.Context, req contracts.DirectoryDownloadRequest, files []contracts.FileResponse, resumed int) *contracts.BatchDownloadResponse {
+	// 下载链接不影响统计，预览时不逐个获取
+	items := make([]contracts.DownloadRequest, 0, len(files))
+	for _, file := range files {
+		items = append(items, s.buildDownloadRequest(file, req.TargetDir, req.AutoClassify, nil))
+	}
+	return &contracts.BatchDownloadResponse{
+		Summary:      s.downloadService.SummarizeBatch(ctx, directoryBatchRequest(req, items)),
+		ResumedCount: resumed,
+	}
+}
+
+// directoryBatchRequest 目录下载对应的批量下载请求
+func directoryBatchRequest(req contracts.DirectoryDownloadRequest, items []contracts.DownloadRequest) contracts.BatchDownloadRequest {
+	return contracts.BatchDownloadRequest{
+		Items:               items,
+		Directory:           req.TargetDir,
+		VideoOnly:           req.VideoOnly,
+		AutoClassify:        req.AutoClassify,
+		DeleteAfterDownload: req.DeleteAfterDownload,
+	}
+}
+
+// directoryDownloadPriority 目录下载的提交优先级：视频正片先于字幕、图片等附属文件
+func (s *AppFileService) directoryDownloadPriority(file contracts.FileResponse) int {
+	if s.IsVideoFile(file.Name) {
+		return 1
+	}
+	return 0
+}
+
+// SetDirectoryCheckpoints 设置目录下载断点存储
+func (s *AppFileService) SetDirectoryCheckpoints(checkpoints *repository.DirectoryCheckpointRepository) {
+	s.checkpoints = checkpoints
+}
+
+// GetDirectoryCheckpoint 获取目录上次中断的下载断点
+func (s *AppFileService) GetDirectoryCheckpoint(path string) (*contracts.DirectoryCheckpoint, bool) {
+	if s.checkpoints == nil {
+		return nil, false
+	}
+	checkpoint, ok := s.checkpoints.Get(path)
+	if !ok {
+		return nil, false
+	}
+	return &contracts.DirectoryCheckpoint{
+		Path:                checkpoint.Path,
+		QueuedFiles:         len(checkpoint.Queued),
+		TotalFiles:          checkpoint.TotalFiles,
+		DeleteAfterDownload: checkpoint.DeleteAfterDownload,
+		UpdatedAt:           checkpoint.UpdatedAt,
+	}, true
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sort"
 
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 )
 
@@ -13,12 +15,19 @@ func (s *AppFileService) DeleteFile(ctx context.Context, path string) error {
 		return fmt.Errorf("alist client not initialized")
 	}
 
-	logger.Info("Deleting file", "path", path)
+	target := resolveDeleteTargets([]string{path})[0]
 
-	dir := filepath.Dir(path)
-	fileName := filepath.Base(path)
+	if contracts.IsDryRun(ctx) {
+		logger.Info("Dry run: would delete file", "path", path, "dir", target.Dir, "name", target.Names[0])
+		return nil
+	}
+	if err := s.checkWritable("delete"); err != nil {
+		return err
+	}
 
-	if err := s.alistClient.Remove(ctx, dir, []string{fileName}); err != nil {
+	logger.Info("Deleting file", "path", path)
+
+	if err := s.alistClient.Remove(ctx, target.Dir, target.Names); err != nil {
 		logger.Error("Failed to delete file", "path", path, "error", err)
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
@@ -36,25 +45,31 @@ func (s *AppFileService) DeleteFiles(ctx context.Context, paths []string) error
 		return nil
 	}
 
-	logger.Info("Deleting files", "count", len(paths))
+	targets := resolveDeleteTargets(paths)
 
-	pathMap := make(map[string][]string)
-	for _, path := range paths {
-		dir := filepath.Dir(path)
-		fileName := filepath.Base(path)
-		pathMap[dir] = append(pathMap[dir], fileName)
+	if contracts.IsDryRun(ctx) {
+		for _, target := range targets {
+			logger.Info("Dry run: would delete files in directory", "dir", target.Dir, "files", target.Names)
+		}
+		logger.Info("Dry run: delete skipped", "count", len(paths))
+		return nil
+	}
+	if err := s.checkWritable("delete"); err != nil {
+		return err
 	}
 
+	logger.Info("Deleting files", "count", len(paths))
+
 	var lastErr error
 	successCount := 0
 
-	for dir, fileNames := range pathMap {
-		if err := s.alistClient.Remove(ctx, dir, fileNames); err != nil {
-			logger.Error("Failed to delete files in directory", "dir", dir, "files", fileNames, "error", err)
+	for _, target := range targets {
+		if err := s.alistClient.Remove(ctx, target.Dir, target.Names); err != nil {
+			logger.Error("Failed to delete files in directory", "dir", target.Dir, "files", target.Names, "error", err)
 			lastErr = err
 		} else {
-			successCount += len(fileNames)
-			logger.Info("Files deleted successfully", "dir", dir, "count", len(fileNames))
+			successCount += len(target.Names)
+			logger.Info("Files deleted successfully", "dir", target.Dir, "count", len(target.Names))
 		}
 	}
 
@@ -65,3 +80,39 @@ func (s *AppFileService) DeleteFiles(ctx context.Context, paths []string) error
 	logger.Info("All files deleted successfully", "count", len(paths))
 	return nil
 }
+
+// PreviewDelete 返回将要删除的内容，不调用 Alist 删除接口
+func (s *AppFileService) PreviewDelete(ctx context.Context, paths []string) ([]contracts.DeleteTarget, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	targets := resolveDeleteTargets(paths)
+	if err := s.DeleteFiles(contracts.WithDryRun(ctx), paths); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// resolveDeleteTargets 按所在目录分组删除路径（真实删除与试运行共用），按目录排序保证结果稳定
+func resolveDeleteTargets(paths []string) []contracts.DeleteTarget {
+	index := make(map[string]int)
+	var targets []contracts.DeleteTarget
+	for _, path := range paths {
+		// 先规范化，避免目录路径末尾的 "/" 导致解析出错误的父目录
+		path = filepath.Clean(path)
+		dir := filepath.Dir(path)
+		fileName := filepath.Base(path)
+		i, exists := index[dir]
+		if !exists {
+			i = len(targets)
+			index[dir] = i
+			targets = append(targets, contracts.DeleteTarget{Dir: dir})
+		}
+		targets[i].Names = append(targets[i].Names, fileName)
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Dir < targets[j].Dir
+	})
+	return targets
+}
@@ -0,0 +1,315 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// defaultInventoryMaxFiles 目录清点默认扫描文件数上限
+	defaultInventoryMaxFiles = 5000
+	// defaultInventoryMaxDepth 目录清点默认递归的子目录层数
+	defaultInventoryMaxDepth = 8
+	// inventoryConcurrency 同时列出的子目录数
+	inventoryConcurrency = 4
+	// inventoryCacheTTL 清单缓存时间，短时间内重复查询同一目录时不再扫描
+	inventoryCacheTTL = 5 * time.Minute
+)
+
+// inventoryCache 最近一次清单扫描结果
+type inventoryCache struct {
+	mu       sync.Mutex
+	request  contracts.InventoryRequest
+	report   *contracts.InventoryReport
+	cachedAt time.Time
+}
+
+// get 返回未过期且参数相同的缓存清单副本
+func (c *inventoryCache) get(req contracts.InventoryRequest) (*contracts.InventoryReport, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.report == nil || c.request != req || time.Since(c.cachedAt) > inventoryCacheTTL {
+		return nil, false
+	}
+	report := *c.report
+	report.Cached = true
+	return &report, true
+}
+
+// set 保存最近一次清单
+func (c *inventoryCache) set(req contracts.InventoryRequest, report *contracts.InventoryReport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.request = req
+	c.report = report
+	c.cachedAt = time.Now()
+}
+
+// inventoryScan 目录扫描中间结果
+type inventoryScan struct {
+	files      []contracts.FileResponse
+	totalDirs  int
+	failedDirs []string
+	truncated  bool
+	// depthLimited 达到最大层数时还有未扫描的子目录
+	depthLimited bool
+	visited      map[string]bool
+}
+
+// addFiles 追加文件，超过上限时截断并标记
+func (scan *inventoryScan) addFiles(files []contracts.FileResponse, maxFiles int) {
+	remaining := maxFiles - len(scan.files)
+	if len(files) > remaining {
+		files = files[:max(remaining, 0)]
+		scan.truncated = true
+	}
+	scan.files = append(scan.files, files...)
+}
+
+// ScanInventory 递归扫描目录并生成媒体清单，不创建任何下载任务
+func (s *AppFileService) ScanInventory(ctx context.Context, req contracts.InventoryRequest) (*contracts.InventoryReport, error) {
+	if s.alistClient == nil {
+		return nil, fmt.Errorf("alist client not initialized")
+	}
+
+	if req.MaxFiles <= 0 {
+		req.MaxFiles = defaultInventoryMaxFiles
+	}
+	if req.MaxDepth <= 0 {
+		req.MaxDepth = defaultInventoryMaxDepth
+	}
+
+	if report, ok := s.inventoryCache.get(req); ok {
+		logger.Debug("Directory inventory served from cache", "path", req.Path, "scannedAt", report.ScannedAt)
+		return report, nil
+	}
+
+	logger.Info("Scanning directory inventory", "path", req.Path, "maxFiles", req.MaxFiles, "maxDepth", req.MaxDepth)
+
+	scan, err := s.scanInventoryTree(ctx, req.Path, req.MaxFiles, req.MaxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	report := s.buildInventoryReport(req.Path, scan)
+	s.inventoryCache.set(req, report)
+	logger.Info("Directory inventory completed",
+		"path", req.Path,
+		"files", report.TotalFiles,
+		"dirs", report.TotalDirs,
+		"shows", len(report.Shows),
+		"movies", len(report.Movies),
+		"unclassified", len(report.Unclassified),
+		"truncated", report.Truncated,
+		"depthLimited", report.DepthLimited)
+	return report, nil
+}
+
+// scanInventoryTree 按层并发列出子目录，达到文件数上限或最大层数（maxDepth > 0）后停止
+func (s *AppFileService) scanInventoryTree(ctx context.Context, root string, maxFiles, maxDepth int) (*inventoryScan, error) {
+	scan := &inventoryScan{visited: map[string]bool{root: true}}
+
+	// 根目录列出失败直接返回错误
+	files, level, err := s.listInventoryDir(ctx, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory: %w", err)
+	}
+	scan.addFiles(files, maxFiles)
+
+	for depth := 1; len(level) > 0 && !scan.truncated; depth++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if maxDepth > 0 && depth > maxDepth {
+			scan.depthLimited = true
+			break
+		}
+
+		var (
+			next []contracts.FileResponse
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+			sem  = make(chan struct{}, inventoryConcurrency)
+		)
+		for _, dir := range level {
+			if scan.visited[dir.Path] {
+				continue
+			}
+			scan.visited[dir.Path] = true
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(dirPath string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				files, subDirs, err := s.listInventoryDir(ctx, dirPath)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					logger.Warn("Failed to list directory for inventory", "path", dirPath, "error", err)
+					scan.failedDirs = append(scan.failedDirs, dirPath)
+					return
+				}
+				scan.totalDirs++
+				scan.addFiles(files, maxFiles)
+				next = append(next, subDirs...)
+			}(dir.Path)
+		}
+		wg.Wait()
+		level = next
+	}
+
+	return scan, nil
+}
+
+// listInventoryDir 列出单个目录（不递归，跳过隐藏文件和目录），返回文件和子目录
+func (s *AppFileService) listInventoryDir(ctx context.Context, dirPath string) ([]contracts.FileResponse, []contracts.FileResponse, error) {
+	items, err := s.listDirItems(ctx, dirPath, newListBudget(0))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var files, dirs []contracts.FileResponse
+	for _, item := range items {
+		item = normalizeFileItem(item)
+		if isHiddenName(item.Name) {
+			continue
+		}
+		fileResp := s.convertToFileResponse(item, dirPath)
+		if item.IsDir {
+			dirs = append(dirs, fileResp)
+		} else {
+			files = append(files, fileResp)
+		}
+	}
+	return files, dirs, nil
+}
+
+// buildInventoryReport 汇总分类统计，并通过文件名解析识别剧集和电影
+func (s *AppFileService) buildInventoryReport(root string, scan *inventoryScan) *contracts.InventoryReport {
+	report := &contracts.InventoryReport{
+		Path:         root,
+		ScannedAt:    time.Now(),
+		TotalDirs:    scan.totalDirs,
+		Categories:   make(map[string]contracts.InventoryCategory),
+		FailedDirs:   scan.failedDirs,
+		Truncated:    scan.truncated,
+		DepthLimited: scan.depthLimited,
+	}
+	topLevel := make(map[string]*contracts.InventoryDirectory)
+
+	parser := s.fileNameParser()
+	shows := make(map[string]*contracts.InventoryShow)
+	showSeasons := make(map[string]map[int]bool)
+	movies := make(map[string]*contracts.InventoryMovie)
+
+	for _, file := range scan.files {
+		report.TotalFiles++
+		report.TotalSize += file.Size
+
+		category := file.Category
+		isVideo := s.IsVideoFile(file.Name)
+		if !isVideo {
+			// 非视频文件归为 other，启用时区分 music/document
+			category = s.GetFileCategory(file.Name)
+		}
+		stat := report.Categories[category]
+		stat.Count++
+		stat.Size += file.Size
+		report.Categories[category] = stat
+
+		dirName := topLevelDir(root, file.Path)
+		dir, ok := topLevel[dirName]
+		if !ok {
+			dir = &contracts.InventoryDirectory{Name: dirName}
+			topLevel[dirName] = dir
+		}
+		dir.Count++
+		dir.Size += file.Size
+
+		if !isVideo {
+			continue
+		}
+
+		info := parser.ParseFileName(file.Path)
+		switch {
+		case info.Title == "":
+			report.Unclassified = append(report.Unclassified, file)
+		case info.MediaType == tmdb.MediaTypeTV:
+			show, ok := shows[info.Title]
+			if !ok {
+				show = &contracts.InventoryShow{Title: info.Title}
+				shows[info.Title] = show
+				showSeasons[info.Title] = make(map[int]bool)
+			}
+			show.Episodes++
+			show.Size += file.Size
+			if info.Season > 0 && !showSeasons[info.Title][info.Season] {
+				showSeasons[info.Title][info.Season] = true
+				show.Seasons = append(show.Seasons, info.Season)
+			}
+		case category == "movie":
+			key := fmt.Sprintf("%s|%d", info.Title, info.Year)
+			movie, ok := movies[key]
+			if !ok {
+				movie = &contracts.InventoryMovie{Title: info.Title, Year: info.Year}
+				movies[key] = movie
+			}
+			movie.Files++
+			movie.Size += file.Size
+		default:
+			report.Unclassified = append(report.Unclassified, file)
+		}
+	}
+
+	for _, dir := range topLevel {
+		report.TopLevel = append(report.TopLevel, *dir)
+	}
+	sort.Slice(report.TopLevel, func(i, j int) bool {
+		if report.TopLevel[i].Size != report.TopLevel[j].Size {
+			return report.TopLevel[i].Size > report.TopLevel[j].Size
+		}
+		return report.TopLevel[i].Name < report.TopLevel[j].Name
+	})
+
+	for _, show := range shows {
+		sort.Ints(show.Seasons)
+		report.Shows = append(report.Shows, *show)
+	}
+	sort.Slice(report.Shows, func(i, j int) bool { return report.Shows[i].Title < report.Shows[j].Title })
+
+	for _, movie := range movies {
+		report.Movies = append(report.Movies, *movie)
+	}
+	sort.Slice(report.Movies, func(i, j int) bool {
+		if report.Movies[i].Title != report.Movies[j].Title {
+			return report.Movies[i].Title < report.Movies[j].Title
+		}
+		return report.Movies[i].Year < report.Movies[j].Year
+	})
+
+	sort.Slice(report.Unclassified, func(i, j int) bool { return report.Unclassified[i].Path < report.Unclassified[j].Path })
+	sort.Strings(report.FailedDirs)
+
+	return report
+}
+
+// topLevelDir 返回文件所在的一级子目录名（相对扫描目录），直接位于扫描目录下的文件返回空
+func topLevelDir(root, filePath string) string {
+	rel := strings.TrimPrefix(filePath, strings.TrimSuffix(root, "/")+"/")
+	name, _, found := strings.Cut(rel, "/")
+	if !found {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// maxDirectoryLinks 单次获取直链的文件数上限
+	maxDirectoryLinks = 500
+	// directoryLinksConcurrency 同时获取直链的文件数
+	directoryLinksConcurrency = 4
+)
+
+// GetDirectoryLinks 获取目录下所有文件的下载直链（不递归子目录），按文件名排序
+func (s *AppFileService) GetDirectoryLinks(ctx context.Context, dirPath string) (*contracts.DirectoryLinksResponse, error) {
+	if s.alistClient == nil {
+		return nil, fmt.Errorf("alist client not initialized")
+	}
+
+	files, _, err := s.listInventoryDir(ctx, dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	resp := &contracts.DirectoryLinksResponse{Path: dirPath}
+	if len(files) > maxDirectoryLinks {
+		files = files[:maxDirectoryLinks]
+		resp.Truncated = true
+	}
+
+	links := make([]contracts.FileLink, len(files))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, directoryLinksConcurrency)
+	for i, file := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, file contracts.FileResponse) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			internalURL, _ := s.getRealDownloadURLs(file.Path)
+			links[i] = contracts.FileLink{Name: file.Name, Path: file.Path, Size: file.Size, URL: internalURL}
+		}(i, file)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	resp.Links = links
+	logger.Info("Directory links collected", "path", dirPath, "files", len(links), "truncated", resp.Truncated)
+	return resp, nil
+}
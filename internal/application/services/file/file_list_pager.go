@@ -0,0 +1,79 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/alist"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// maxPageSize ListFiles 单页最大条目数，超过时视为读取整个目录
+	maxPageSize = 1000
+	// alistListPageSize 逐页读取目录时每次向 Alist 请求的条目数
+	alistListPageSize = 500
+	// maxListItems 单次列目录（含递归）最多读取的条目数，超过后截断，避免超大目录占满内存或请求超时
+	maxListItems = 20000
+)
+
+// listBudget 列目录的条目预算，多个目录（递归）共享同一上限
+type listBudget struct {
+	remaining int
+	truncated bool
+}
+
+// newListBudget 创建条目预算，limit <= 0 时使用 maxListItems
+func newListBudget(limit int) *listBudget {
+	if limit <= 0 || limit > maxListItems {
+		limit = maxListItems
+	}
+	return &listBudget{remaining: limit}
+}
+
+// exhausted 预算是否已用完
+func (b *listBudget) exhausted() bool {
+	return b.remaining <= 0
+}
+
+// listDirItems 逐页读取单个目录的全部条目（不递归），读取量受 budget 限制；
+// 达到上限时停止请求后续页面并标记 budget.truncated
+func (s *AppFileService) listDirItems(ctx context.Context, dirPath string, budget *listBudget) ([]alist.FileItem, error) {
+	var items []alist.FileItem
+	for page := 1; ; page++ {
+		if budget.exhausted() {
+			budget.truncated = true
+			logger.Warn("Directory listing truncated", "path", dirPath, "loaded", len(items))
+			return items, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return items, err
+		}
+
+		resp, err := s.alistClient.ListFilesWithContext(ctx, dirPath, page, alistListPageSize)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			return items, fmt.Errorf("failed to list page %d of %s: %w", page, dirPath, err)
+		}
+
+		content := resp.Data.Content
+		if len(content) > budget.remaining {
+			content = content[:budget.remaining]
+			budget.truncated = true
+		}
+		items = append(items, content...)
+		budget.remaining -= len(content)
+
+		if budget.truncated {
+			logger.Warn("Directory listing truncated", "path", dirPath, "total", resp.Data.Total, "loaded", len(items))
+			return items, nil
+		}
+
+		// 最后一页：返回条目不足一页，或已读取到 Alist 报告的总数
+		if len(resp.Data.Content) < alistListPageSize || (resp.Data.Total > 0 && page*alistListPageSize >= resp.Data.Total) {
+			return items, nil
+		}
+	}
+}
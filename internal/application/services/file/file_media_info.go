@@ -0,0 +1,63 @@
+package file
+
+import (
+	"path"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
+)
+
+// ParseMediaInfo 从路径解析媒体信息，纯本地计算
+// 已按 TMDB 重命名的 Emby 格式文件名（剧名 - S01E01 - 标题.ext、电影名 (年份).ext）直接使用其中的名称；
+// 其他剧集文件与重命名一致，优先使用目录中的剧名和季度
+func (s *AppFileService) ParseMediaInfo(fullPath string) contracts.MediaInfo {
+	parser := s.fileNameParser()
+	info := parser.ParseFileName(fullPath)
+	fileName := path.Base(fullPath)
+
+	media := contracts.MediaInfo{
+		Type:  string(info.MediaType),
+		Title: info.Title,
+		Year:  info.Year,
+	}
+
+	if info.MediaType == tmdb.MediaTypeTV {
+		if showName, episodeTitle, ok := splitEmbyTVName(fileName); ok {
+			media.Title = showName
+			media.EpisodeTitle = episodeTitle
+		} else if showName, pathSeason := parser.getPathInfo(info, fullPath); showName != "" {
+			media.Title = showName
+			if pathSeason > 0 {
+				info.Season = pathSeason
+			}
+		}
+		// 剧集年份来自首播时间，文件名中的年份不可靠
+		media.Year = 0
+	}
+
+	if info.Season > 0 {
+		season := info.Season
+		media.Season = &season
+	}
+	if info.Episode > 0 {
+		episode := info.Episode
+		media.Episode = &episode
+	}
+	return media
+}
+
+// splitEmbyTVName 拆分 Emby 剧集文件名为剧名和单集标题（没有标题时为空）
+func splitEmbyTVName(fileName string) (showName, episodeTitle string, ok bool) {
+	if !embyTVPattern.MatchString(fileName) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimSuffix(fileName, path.Ext(fileName)), " - ", 3)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	if len(parts) == 3 {
+		episodeTitle = strings.TrimSpace(parts[2])
+	}
+	return strings.TrimSpace(parts[0]), episodeTitle, true
+}
@@ -31,6 +31,13 @@ func (s *AppFileService) DownloadFile(ctx context.Context, req contracts.FileDow
 
 	// 使用统一的方法构建下载请求
 	downloadReq := s.buildDownloadRequest(*fileInfo, req.TargetDir, req.AutoClassify, req.Options)
+	downloadReq.DeleteAfterDownload = req.DeleteAfterDownload
+	downloadReq.DownloadSubtitles = req.DownloadSubtitles
+	if req.Filename != "" {
+		// 用户指定了文件名，不再根据源文件名自动整理
+		downloadReq.Filename = req.Filename
+		downloadReq.AutoClassify = false
+	}
 
 	logger.Debug("Creating download task",
 		"url", downloadReq.URL,
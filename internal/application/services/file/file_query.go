@@ -8,8 +8,8 @@ import (
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/alist"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
-	pathutil "github.com/easayliu/alist-aria2-download/pkg/utils/path"
 	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
 	timeutil "github.com/easayliu/alist-aria2-download/pkg/utils/time"
 )
@@ -24,22 +24,47 @@ func (s *AppFileService) ListFiles(ctx context.Context, req contracts.FileListRe
 	}
 	if req.PageSize <= 0 {
 		req.PageSize = 50
-	} else if req.PageSize > 1000 {
-		req.PageSize = 1000
+	}
+	// 页大小超过单页上限（如 10000）视为读取整个目录，不再截断为一页
+	fullListing := req.PageSize > maxPageSize
+	if fullListing {
+		req.PageSize = maxPageSize
 	}
 
-	// 2. AList客户端将自动处理token验证和刷新
-
-	alistResp, err := s.alistClient.ListFiles(req.Path, req.Page, req.PageSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list files: %w", err)
+	// 2. 读取目录（AList客户端将自动处理token验证和刷新）
+	// 递归或整目录读取时逐页请求并受 maxListItems 限制；否则直接请求 Alist 对应的一页
+	var items []alist.FileItem
+	var budget *listBudget
+	listTotal := 0
+	if req.Recursive || fullListing {
+		budget = newListBudget(0)
+		var err error
+		items, err = s.listDirItems(ctx, req.Path, budget)
+		if err != nil && len(items) == 0 {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		} else if err != nil {
+			logger.Warn("Directory listing incomplete", "path", req.Path, "error", err)
+			budget.truncated = true
+		}
+	} else {
+		alistResp, err := s.alistClient.ListFilesWithContext(ctx, req.Path, req.Page, req.PageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", err)
+		}
+		items = alistResp.Data.Content
+		listTotal = alistResp.Data.Total
 	}
 
 	// 3. 转换并分类文件
 	var files, directories []contracts.FileResponse
 	summary := contracts.FileSummary{}
 
-	for _, item := range alistResp.Data.Content {
+	for _, item := range items {
+		item = normalizeFileItem(item)
+		if !req.IncludeHidden && isHiddenName(item.Name) {
+			summary.HiddenItems++
+			continue
+		}
 		fileResp := s.convertToFileResponse(item, req.Path)
 
 		if item.IsDir {
@@ -56,7 +81,7 @@ func (s *AppFileService) ListFiles(ctx context.Context, req contracts.FileListRe
 			// 如果是递归模式，需要获取真实Size（用于下载统计）
 			if req.Recursive {
 				logger.Debug("Getting file info for recursive mode", "file", item.Name, "initialSize", fileResp.Size)
-				filePath := pathutil.JoinPath(req.Path, item.Name)
+				filePath := fileResp.Path
 				fileInfo, err := s.alistClient.GetFileInfo(filePath)
 				if err != nil {
 					logger.Warn("Failed to get file info in recursive mode", "file", item.Name, "error", err)
@@ -96,14 +121,14 @@ func (s *AppFileService) ListFiles(ctx context.Context, req contracts.FileListRe
 	if req.Recursive {
 		visited := make(map[string]bool)
 		visited[req.Path] = true
-		s.collectFilesRecursive(ctx, directories, req.VideoOnly, visited, &files, &summary)
+		s.collectFilesRecursive(ctx, directories, req.VideoOnly, req.IncludeHidden, visited, budget, &files, &summary)
 	}
 
 	// 5. 应用排序
 	s.sortFiles(files, req.SortBy, req.SortOrder)
 
-	// 6. 应用分页（对于递归结果）
-	if req.Recursive {
+	// 6. 应用分页（对于递归结果，整目录读取时返回全部）
+	if req.Recursive && !fullListing {
 		start := (req.Page - 1) * req.PageSize
 		end := start + req.PageSize
 		if start >= len(files) {
@@ -118,6 +143,9 @@ func (s *AppFileService) ListFiles(ctx context.Context, req contracts.FileListRe
 	// 7. 构建响应
 	summary.TotalSizeFormatted = strutil.FormatFileSize(summary.TotalSize)
 	parentPath := s.getParentPath(req.Path)
+	if listTotal == 0 {
+		listTotal = summary.TotalFiles
+	}
 
 	return &contracts.FileListResponse{
 		Files:       files,
@@ -126,77 +154,17 @@ func (s *AppFileService) ListFiles(ctx context.Context, req contracts.FileListRe
 		ParentPath:  parentPath,
 		TotalCount:  summary.TotalFiles,
 		Summary:     summary,
+		Truncated:   budget != nil && budget.truncated,
 		Pagination: contracts.Pagination{
 			Page:     req.Page,
 			PageSize: req.PageSize,
-			Total:    summary.TotalFiles,
-			HasNext:  req.Page*req.PageSize < summary.TotalFiles,
+			Total:    listTotal,
+			HasNext:  !fullListing && req.Page*req.PageSize < listTotal,
 			HasPrev:  req.Page > 1,
 		},
 	}, nil
 }
 
-// SearchFiles 搜索文件
-func (s *AppFileService) SearchFiles(ctx context.Context, req contracts.FileSearchRequest) (*contracts.FileListResponse, error) {
-	// 简单实现：在指定路径下递归搜索
-	searchPath := req.Path
-	if searchPath == "" {
-		searchPath = s.config.Alist.DefaultPath
-		if searchPath == "" {
-			searchPath = "/"
-		}
-	}
-
-	listReq := contracts.FileListRequest{
-		Path:      searchPath,
-		Recursive: true,
-		PageSize:  req.Limit,
-	}
-
-	listResp, err := s.ListFiles(ctx, listReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search files: %w", err)
-	}
-
-	// 应用搜索过滤
-	var filteredFiles []contracts.FileResponse
-	query := strings.ToLower(req.Query)
-
-	for _, file := range listResp.Files {
-		// 文件名匹配
-		if !strings.Contains(strings.ToLower(file.Name), query) {
-			continue
-		}
-
-		// 文件类型过滤
-		if req.FileType != "" && s.GetFileCategory(file.Name) != req.FileType {
-			continue
-		}
-
-		// 文件大小过滤
-		if req.MinSize > 0 && file.Size < req.MinSize {
-			continue
-		}
-		if req.MaxSize > 0 && file.Size > req.MaxSize {
-			continue
-		}
-
-		// 修改时间过滤
-		if req.ModifiedAfter != nil && file.Modified.Before(*req.ModifiedAfter) {
-			continue
-		}
-		if req.ModifiedBefore != nil && file.Modified.After(*req.ModifiedBefore) {
-			continue
-		}
-
-		filteredFiles = append(filteredFiles, file)
-	}
-
-	listResp.Files = filteredFiles
-	listResp.TotalCount = len(filteredFiles)
-	return listResp, nil
-}
-
 // GetFilesByTimeRange 根据时间范围获取文件
 func (s *AppFileService) GetFilesByTimeRange(ctx context.Context, req contracts.TimeRangeFileRequest) (*contracts.TimeRangeFileResponse, error) {
 	logger.Debug("GetFilesByTimeRange called",
@@ -205,7 +173,9 @@ func (s *AppFileService) GetFilesByTimeRange(ctx context.Context, req contracts.
 		"endTime", req.EndTime.Format("2006-01-02 15:04:05 -07:00"),
 		"startUnix", req.StartTime.Unix(),
 		"endUnix", req.EndTime.Unix(),
-		"videoOnly", req.VideoOnly)
+		"videoOnly", req.VideoOnly,
+		"minFileSize", req.MinFileSize,
+		"maxFileSize", req.MaxFileSize)
 
 	// 使用自定义递归逻辑，先检查目录时间再决定是否递归
 	var filteredFiles []contracts.FileResponse
@@ -214,6 +184,7 @@ func (s *AppFileService) GetFilesByTimeRange(ctx context.Context, req contracts.
 		return nil, fmt.Errorf("failed to collect files: %w", err)
 	}
 
+	filteredFiles = filterBySize(filteredFiles, req.MinFileSize, req.MaxFileSize)
 	logger.Debug("Time range filtering completed", "filteredCount", len(filteredFiles))
 
 	// 重新计算摘要
@@ -229,23 +200,51 @@ func (s *AppFileService) GetFilesByTimeRange(ctx context.Context, req contracts.
 	}, nil
 }
 
+// filterBySize 按文件大小范围过滤（0 表示不限）
+func filterBySize(files []contracts.FileResponse, minSize, maxSize int64) []contracts.FileResponse {
+	if minSize <= 0 && maxSize <= 0 {
+		return files
+	}
+	filtered := files[:0]
+	for _, file := range files {
+		if (minSize > 0 && file.Size < minSize) || (maxSize > 0 && file.Size > maxSize) {
+			continue
+		}
+		filtered = append(filtered, file)
+	}
+	return filtered
+}
+
 // collectFilesRecursive 递归收集所有子目录的文件
-func (s *AppFileService) collectFilesRecursive(ctx context.Context, directories []contracts.FileResponse, videoOnly bool, visited map[string]bool, files *[]contracts.FileResponse, summary *contracts.FileSummary) {
+// includeHidden 为 false 时跳过隐藏文件，隐藏目录整体不进入递归
+// budget 为整个递归共享的条目上限，用完后停止读取剩余目录
+func (s *AppFileService) collectFilesRecursive(ctx context.Context, directories []contracts.FileResponse, videoOnly, includeHidden bool, visited map[string]bool, budget *listBudget, files *[]contracts.FileResponse, summary *contracts.FileSummary) {
 	for _, dir := range directories {
 		if visited[dir.Path] {
 			logger.Debug("Directory already visited, skipping", "path", dir.Path)
 			continue
 		}
+		if budget.exhausted() {
+			budget.truncated = true
+			return
+		}
 		visited[dir.Path] = true
 
-		alistResp, err := s.alistClient.ListFiles(dir.Path, 1, 1000)
+		items, err := s.listDirItems(ctx, dir.Path, budget)
 		if err != nil {
 			logger.Warn("Failed to list subdirectory", "path", dir.Path, "error", err)
-			continue
+			if len(items) == 0 {
+				continue
+			}
 		}
 
 		var subDirs []contracts.FileResponse
-		for _, item := range alistResp.Data.Content {
+		for _, item := range items {
+			item = normalizeFileItem(item)
+			if !includeHidden && isHiddenName(item.Name) {
+				summary.HiddenItems++
+				continue
+			}
 			fileResp := s.convertToFileResponse(item, dir.Path)
 
 			if item.IsDir {
@@ -258,7 +257,7 @@ func (s *AppFileService) collectFilesRecursive(ctx context.Context, directories
 
 				// 获取文件详细信息（包含真实Size和下载URL）
 				logger.Debug("Getting file info for recursive collection", "file", item.Name, "initialSize", fileResp.Size)
-				filePath := pathutil.JoinPath(dir.Path, item.Name)
+				filePath := fileResp.Path
 				fileInfo, err := s.alistClient.GetFileInfo(filePath)
 				if err != nil {
 					logger.Warn("Failed to get file info in recursive collection", "file", item.Name, "error", err)
@@ -292,7 +291,7 @@ func (s *AppFileService) collectFilesRecursive(ctx context.Context, directories
 		}
 
 		if len(subDirs) > 0 {
-			s.collectFilesRecursive(ctx, subDirs, videoOnly, visited, files, summary)
+			s.collectFilesRecursive(ctx, subDirs, videoOnly, includeHidden, visited, budget, files, summary)
 		}
 	}
 }
@@ -301,13 +300,18 @@ func (s *AppFileService) collectFilesRecursive(ctx context.Context, directories
 func (s *AppFileService) collectFilesInTimeRange(ctx context.Context, path string, startTime, endTime time.Time, videoOnly bool, result *[]contracts.FileResponse) error {
 	logger.Debug("Collecting files in path", "path", path)
 
-	// 获取当前目录的文件列表（非递归）
-	alistResp, err := s.alistClient.ListFiles(path, 1, 1000)
+	// 获取当前目录的文件列表（非递归，逐页读取）
+	items, err := s.listDirItems(ctx, path, newListBudget(0))
 	if err != nil {
 		return fmt.Errorf("failed to list files in %s: %w", path, err)
 	}
 
-	for _, item := range alistResp.Data.Content {
+	for _, item := range items {
+		item = normalizeFileItem(item)
+		// 隐藏文件和目录不参与按时间范围下载
+		if isHiddenName(item.Name) {
+			continue
+		}
 		fileResp := s.convertToFileResponse(item, path)
 
 		// 检查时间范围
@@ -324,7 +328,7 @@ func (s *AppFileService) collectFilesInTimeRange(ctx context.Context, path strin
 			// 对于目录，如果目录修改时间在范围内，则递归搜索
 			if inTimeRange {
 				logger.Debug("Directory in time range, recursing", "dir", item.Name)
-				subPath := pathutil.JoinPath(path, item.Name)
+				subPath := fileResp.Path
 				err := s.collectFilesInTimeRange(ctx, subPath, startTime, endTime, videoOnly, result)
 				if err != nil {
 					logger.Warn("Failed to recurse into directory", "dir", item.Name, "error", err)
@@ -340,7 +344,7 @@ func (s *AppFileService) collectFilesInTimeRange(ctx context.Context, path strin
 					logger.Debug("File matches criteria", "file", item.Name, "initialSize", fileResp.Size)
 
 					// 为符合条件的文件获取详细信息（包含真实Size和下载URL）
-					filePath := pathutil.JoinPath(path, item.Name)
+					filePath := fileResp.Path
 					fileInfo, err := s.alistClient.GetFileInfo(filePath)
 					if err != nil {
 						logger.Warn("Failed to get file info, using basic info", "file", item.Name, "error", err)
@@ -0,0 +1,108 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	"github.com/easayliu/alist-aria2-download/pkg/utils/media"
+)
+
+// ReclassifyFile 纠正文件分类：记录文件名匹配键到分类覆盖存储，之后同名系列的文件都使用新分类；
+// 文件已下载完成时，将本地文件移动到新分类对应的下载目录
+func (s *AppFileService) ReclassifyFile(ctx context.Context, req contracts.ReclassifyRequest) (*contracts.ReclassifyResult, error) {
+	if s.categoryOverrides == nil {
+		return nil, fmt.Errorf("category override store not initialized")
+	}
+	if !slices.Contains(contracts.ReclassifyCategories, req.Category) {
+		return nil, fmt.Errorf("invalid category: %s", req.Category)
+	}
+
+	fileName := path.Base(req.Path)
+	if !s.IsVideoFile(fileName) {
+		return nil, fmt.Errorf("only video files can be reclassified")
+	}
+
+	key, title := media.OverrideKey(fileName)
+	if key == "" {
+		return nil, fmt.Errorf("cannot extract a title from %s, rename the file to include the show or movie name first", fileName)
+	}
+	override := &entities.CategoryOverride{
+		Pattern:   key,
+		Title:     title,
+		Category:  req.Category,
+		CreatedBy: req.UserID,
+	}
+	if err := s.categoryOverrides.Save(override); err != nil {
+		return nil, fmt.Errorf("failed to save category override: %w", err)
+	}
+	logger.Info("Category override saved", "file", req.Path, "pattern", override.Pattern, "category", req.Category)
+
+	result := &contracts.ReclassifyResult{
+		Pattern:  override.Pattern,
+		Title:    title,
+		Category: req.Category,
+	}
+	s.moveDownloadedFile(req.Path, fileName, result)
+	return result, nil
+}
+
+// moveDownloadedFile 查找最近一次完成的下载，将文件移动到按新分类生成的目录
+// 移动失败不影响分类纠正本身，错误记录在结果中
+func (s *AppFileService) moveDownloadedFile(sourcePath, fileName string, result *contracts.ReclassifyResult) {
+	if s.downloadHistory == nil {
+		return
+	}
+
+	var record *entities.DownloadRecord
+	for _, r := range s.downloadHistory.GetBySourcePath(sourcePath) {
+		if r.Status == valueobjects.DownloadStatusComplete && r.Directory != "" {
+			record = r
+			break
+		}
+	}
+	if record == nil {
+		return
+	}
+	result.Downloaded = true
+
+	targetDir := s.GenerateDownloadPath(contracts.FileResponse{
+		Name:     fileName,
+		Path:     sourcePath,
+		Category: result.Category,
+	})
+	if filepath.Clean(targetDir) == filepath.Clean(record.Directory) {
+		return
+	}
+
+	name := record.Filename
+	if name == "" {
+		name = fileName
+	}
+	result.From = filepath.Join(record.Directory, name)
+	result.To = filepath.Join(targetDir, name)
+
+	if err := s.checkWritable("reclassify_move"); err != nil {
+		result.MoveError = err.Error()
+		return
+	}
+	if err := filesystem.MoveFile(result.From, result.To); err != nil {
+		logger.Warn("Failed to move reclassified file", "from", result.From, "to", result.To, "error", err)
+		result.MoveError = err.Error()
+		return
+	}
+	result.Moved = true
+
+	record.Directory = targetDir
+	record.Category = result.Category
+	if err := s.downloadHistory.Save(record); err != nil {
+		logger.Warn("Failed to update download record after move", "id", record.ID, "error", err)
+	}
+}
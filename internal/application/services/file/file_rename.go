@@ -13,6 +13,7 @@ import (
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/domain/models/rename"
 	"github.com/easayliu/alist-aria2-download/internal/domain/services/filename"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
 	fileutil "github.com/easayliu/alist-aria2-download/pkg/utils/file"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 )
@@ -21,6 +22,9 @@ func (s *AppFileService) RenameFile(ctx context.Context, path, newName string) e
 	if s.alistClient == nil {
 		return fmt.Errorf("alist client not initialized")
 	}
+	if err := s.checkWritable("rename"); err != nil {
+		return err
+	}
 
 	logger.Debug("Renaming file", "path", path, "newName", newName)
 
@@ -44,6 +48,9 @@ func (s *AppFileService) renameAndMoveFileInternal(ctx context.Context, oldPath,
 	if s.alistClient == nil {
 		return fmt.Errorf("alist client not initialized")
 	}
+	if err := s.checkWritable("rename_move"); err != nil {
+		return err
+	}
 
 	logger.Debug("Renaming and moving file", "oldPath", oldPath, "newPath", newPath)
 
@@ -104,6 +111,9 @@ func (s *AppFileService) BatchRenameAndMoveFiles(ctx context.Context, tasks []co
 	if len(tasks) == 0 {
 		return []contracts.RenameResult{}
 	}
+	if err := s.checkWritable("batch_rename"); err != nil {
+		return failedRenameResults(tasks, err)
+	}
 
 	// 使用 Alist QPS 配置作为最大并发数，默认 10
 	maxConcurrent := 10
@@ -379,7 +389,11 @@ func (s *AppFileService) GetRenameSuggestions(ctx context.Context, path string)
 
 	logger.Debug("Getting rename suggestions", "path", path)
 
-	suggestions, err := s.renameSuggester.SearchAndSuggest(ctx, path)
+	suggest := s.renameSuggester.SearchAndSuggest
+	if s.isMovieForRename(path) {
+		suggest = s.renameSuggester.SuggestMovie
+	}
+	suggestions, err := suggest(ctx, path)
 	if err != nil {
 		logger.Error("Failed to get rename suggestions", "path", path, "error", err)
 		return nil, fmt.Errorf("failed to get rename suggestions: %w", err)
@@ -389,6 +403,46 @@ func (s *AppFileService) GetRenameSuggestions(ctx context.Context, path string)
 	return suggestions, nil
 }
 
+// isMovieForRename 文件分类为电影且文件名中没有季集编号（SxxEyy）时按电影重命名，
+// 避免剧集因文件名包含 1080p 等电影关键词被当作电影
+func (s *AppFileService) isMovieForRename(path string) bool {
+	name := filepath.Base(path)
+	return s.GetFileCategory(name) == "movie" && !seasonEpisodeTagRegex.MatchString(name)
+}
+
+// seasonEpisodeTagRegex 文件名中的季集编号（如 S01E02）
+var seasonEpisodeTagRegex = regexp.MustCompile(`(?i)S\d+E\d+`)
+
+// episodeHintRegex 可能包含集数的文件名特征（数字或"第X集"），不匹配的文件名直接跳过解析
+var episodeHintRegex = regexp.MustCompile(`\d|第`)
+
+// fileNameParser 获取文件名解析器，未配置TMDB时使用仅解析的实例
+func (s *AppFileService) fileNameParser() *RenameSuggester {
+	if s.renameSuggester != nil {
+		return s.renameSuggester
+	}
+	return NewRenameSuggester(nil, s.config.TMDB.QualityDirPatterns)
+}
+
+// GetEpisodeTag 解析文件名中的季集信息，用于文件浏览时展示；未配置TMDB时同样可用
+func (s *AppFileService) GetEpisodeTag(path string) string {
+	if !episodeHintRegex.MatchString(filepath.Base(path)) {
+		return ""
+	}
+
+	info := s.fileNameParser().ParseFileName(path)
+	if info.MediaType != tmdb.MediaTypeTV || info.Episode <= 0 {
+		return ""
+	}
+	if info.Season <= 0 {
+		if info.EndEpisode > info.Episode {
+			return fmt.Sprintf("E%02d-E%02d", info.Episode, info.EndEpisode)
+		}
+		return fmt.Sprintf("E%02d", info.Episode)
+	}
+	return formatEpisodeTag(info.Season, info.Episode, info.EndEpisode)
+}
+
 func (s *AppFileService) GetBatchRenameSuggestions(ctx context.Context, paths []string) (map[string][]contracts.RenameSuggestion, error) {
 	if s.renameSuggester == nil {
 		return nil, fmt.Errorf("TMDB not configured, please set TMDB API key in config")
@@ -666,6 +720,9 @@ func (s *AppFileService) BatchRenameAndMoveFilesOptimized(
 	if len(tasks) == 0 {
 		return []contracts.RenameResult{}
 	}
+	if err := s.checkWritable("batch_rename"); err != nil {
+		return failedRenameResults(tasks, err)
+	}
 
 	logger.Info("开始优化的批量重命名", "taskCount", len(tasks))
 
@@ -0,0 +1,201 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// reorganizeCandidate 不在目标目录中的本地文件
+type reorganizeCandidate struct {
+	from      string
+	targetDir string
+	category  string
+}
+
+// ReorganizeDownloads 按当前分类规则整理本地下载目录：遍历 aria2.download_dir 中已下载的媒体文件，
+// 重新生成每个文件的目标目录，不在目标目录的文件移动过去（一次性迁移，用于配置分类之前下载的文件）
+// ctx 带试运行标记时只生成计划；目标文件已存在时按 download.path_config.conflict_policy 处理
+func (s *AppFileService) ReorganizeDownloads(ctx context.Context) (*contracts.ReorganizeReport, error) {
+	dryRun := contracts.IsDryRun(ctx)
+	if !dryRun {
+		if err := s.checkWritable("reorganize"); err != nil {
+			return nil, err
+		}
+	}
+	if s.pathGenerator == nil {
+		return nil, fmt.Errorf("path generator not initialized")
+	}
+
+	root := filepath.Clean(s.config.Aria2.DownloadDir)
+	if s.config.Aria2.DownloadDir == "" {
+		return nil, fmt.Errorf("aria2.download_dir not configured")
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("download directory not accessible (must be on this host): %s", root)
+	}
+
+	policy := filesystem.ConfiguredConflictPolicy(s.config)
+	report := &contracts.ReorganizeReport{
+		Root:   root,
+		DryRun: dryRun,
+		Policy: string(policy),
+	}
+
+	candidates, err := s.scanReorganizeCandidates(ctx, root, report)
+	if err != nil {
+		return nil, err
+	}
+	report.Moves = planReorganize(candidates, policy, fileExists)
+
+	if dryRun {
+		logger.Info("Reorganize planned", "root", root, "scanned", report.Scanned, "moves", len(report.Moves))
+		return report, nil
+	}
+
+	for i := range report.Moves {
+		move := &report.Moves[i]
+		if move.Action == contracts.ReorganizeActionSkip {
+			continue
+		}
+		if err := applyReorganizeMove(*move); err != nil {
+			logger.Warn("Failed to move file while reorganizing", "from", move.From, "to", move.To, "error", err)
+			move.Error = err.Error()
+			continue
+		}
+		move.Moved = true
+	}
+	logger.Info("Reorganize applied", "root", root, "scanned", report.Scanned, "moves", len(report.Moves))
+	return report, nil
+}
+
+// scanReorganizeCandidates 遍历下载目录，返回需要移动的文件
+// 跳过隐藏文件、非媒体文件、仍在下载的文件，以及用户专属下载目录（download.user_paths）
+func (s *AppFileService) scanReorganizeCandidates(ctx context.Context, root string, report *contracts.ReorganizeReport) ([]reorganizeCandidate, error) {
+	userDirs := make(map[string]bool)
+	for _, p := range s.config.Download.UserPaths {
+		userDirs[filepath.Clean(p.BasePath)] = true
+	}
+
+	var candidates []reorganizeCandidate
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			logger.Warn("Failed to read path while reorganizing", "path", path, "error", err)
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if path == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || (d.IsDir() && userDirs[path]) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		category := s.GetFileCategory(d.Name())
+		if category == "other" {
+			return nil
+		}
+		report.Scanned++
+		if fileExists(path + ".aria2") {
+			report.Downloading++
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		targetDir, _ := s.pathGenerator.PreviewDownloadPath(contracts.FileResponse{
+			Name:     d.Name(),
+			Path:     "/" + filepath.ToSlash(rel),
+			Category: category,
+		})
+		if filepath.Clean(targetDir) == filepath.Dir(path) {
+			report.InPlace++
+			return nil
+		}
+		candidates = append(candidates, reorganizeCandidate{from: path, targetDir: targetDir, category: category})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan download directory: %w", err)
+	}
+	return candidates, nil
+}
+
+// planReorganize 为每个文件生成整理操作；目标文件已存在（或已被本次整理中的其他文件占用）时按策略处理
+// 覆盖只针对磁盘上原有的文件，本次整理中移入或待移走的文件不会被覆盖，改为跳过
+func planReorganize(candidates []reorganizeCandidate, policy filesystem.ConflictPolicy, exists func(string) bool) []contracts.ReorganizeMove {
+	planned := make(map[string]bool, len(candidates))
+	sources := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		sources[c.from] = true
+	}
+	taken := func(path string) bool { return planned[path] || exists(path) }
+
+	moves := make([]contracts.ReorganizeMove, 0, len(candidates))
+	for _, c := range candidates {
+		move := contracts.ReorganizeMove{
+			From:     c.from,
+			To:       filepath.Join(c.targetDir, filepath.Base(c.from)),
+			Category: c.category,
+			Action:   contracts.ReorganizeActionMove,
+		}
+		if taken(move.To) {
+			switch {
+			case policy == filesystem.ConflictPolicyRename:
+				move.To = uniqueFilePath(move.To, taken)
+				move.Action = contracts.ReorganizeActionRename
+			case policy == filesystem.ConflictPolicyOverwrite && !planned[move.To] && !sources[move.To]:
+				move.Action = contracts.ReorganizeActionOverwrite
+			default:
+				move.Action = contracts.ReorganizeActionSkip
+			}
+		}
+		if move.Action != contracts.ReorganizeActionSkip {
+			planned[move.To] = true
+		}
+		moves = append(moves, move)
+	}
+	return moves
+}
+
+// uniqueFilePath 依次尝试 name_1.ext、name_2.ext ...，返回未被占用的路径
+func uniqueFilePath(path string, taken func(string) bool) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// applyReorganizeMove 执行整理操作，覆盖时先删除目标文件
+func applyReorganizeMove(move contracts.ReorganizeMove) error {
+	if move.Action == contracts.ReorganizeActionOverwrite {
+		if err := os.Remove(move.To); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove existing file: %w", err)
+		}
+	}
+	return filesystem.MoveFile(move.From, move.To)
+}
+
+// fileExists 本地路径是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,78 @@
+package file
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+)
+
+// TestClassification 对示例文件名（或完整路径）按与列目录相同的顺序执行分类规则，
+// 返回命中的规则、分类、解析出的媒体信息和最终下载路径；纯本地计算，不访问 Alist/aria2
+func (s *AppFileService) TestClassification(input string) contracts.ClassificationTestResult {
+	input = strings.TrimSpace(input)
+	fullPath := input
+	if !strings.HasPrefix(fullPath, "/") {
+		fullPath = "/" + fullPath
+	}
+	fileName := path.Base(fullPath)
+
+	result := contracts.ClassificationTestResult{
+		Input:   input,
+		Path:    fullPath,
+		IsVideo: s.IsVideoFile(fileName),
+	}
+
+	// 与 convertToFileResponse 一致：用户纠正 > 扩展名（音乐/文档） > 路径关键词 > 文件名关键词
+	switch override, ok := s.mediaClassifier.OverrideFor(fileName); {
+	case ok:
+		result.Category = override.Category
+		result.Rule = fmt.Sprintf("分类纠正记录（匹配 %s）", override.Pattern)
+	case s.mediaClassifier.ExtensionCategory(fileName) != "":
+		result.Category = s.mediaClassifier.ExtensionCategory(fileName)
+		result.Rule = "扩展名分类"
+	default:
+		if category, keyword := s.pathCategory.MatchCategoryFromPath(fullPath); category != "" {
+			result.Category = category
+			result.Rule = fmt.Sprintf("路径关键词 %q", keyword)
+			break
+		}
+		category, keyword := s.mediaClassifier.MatchFileCategory(fileName)
+		result.Category = category
+		switch {
+		case keyword != "":
+			result.Rule = fmt.Sprintf("文件名关键词 %q", keyword)
+		case result.IsVideo:
+			result.Rule = "未命中关键词，默认视频"
+		default:
+			result.Rule = "非视频文件"
+		}
+	}
+
+	info := s.fileNameParser().ParseFileName(fullPath)
+	result.Media = contracts.MediaInfo{
+		Type:  string(info.MediaType),
+		Title: info.Title,
+		Year:  info.Year,
+	}
+	if info.Season > 0 {
+		season := info.Season
+		result.Media.Season = &season
+	}
+	if info.Episode > 0 {
+		episode := info.Episode
+		result.Media.Episode = &episode
+	}
+
+	if s.pathGenerator != nil {
+		result.DownloadPath, result.PathSource = s.pathGenerator.PreviewDownloadPath(contracts.FileResponse{
+			Name:      fileName,
+			Path:      fullPath,
+			MediaType: result.Category,
+			Category:  result.Category,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,141 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// defaultSearchLimit 搜索默认返回的最多结果数
+	defaultSearchLimit = 200
+	// defaultSearchMaxDepth 搜索默认递归的子目录层数
+	defaultSearchMaxDepth = 6
+	// maxSearchEntries 单次搜索最多读取的目录条目数，关键词过于宽泛时避免长时间遍历
+	maxSearchEntries = 5000
+)
+
+// SearchFiles 从指定路径（默认 alist.default_path）逐层遍历 Alist 目录，按文件名搜索文件
+// 关键词按空白拆分，文件名（不区分大小写）包含全部关键词即匹配；
+// 达到结果数、目录层数或读取条目上限时停止遍历并标记 Truncated，返回已找到的结果
+func (s *AppFileService) SearchFiles(ctx context.Context, req contracts.FileSearchRequest) (*contracts.FileListResponse, error) {
+	keywords := strings.Fields(strings.ToLower(req.Query))
+	if len(keywords) == 0 {
+		return nil, fmt.Errorf("search keyword is required")
+	}
+
+	searchPath := req.Path
+	if searchPath == "" {
+		searchPath = s.config.Alist.DefaultPath
+		if searchPath == "" {
+			searchPath = "/"
+		}
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultSearchLimit
+	}
+	if req.MaxDepth <= 0 {
+		req.MaxDepth = defaultSearchMaxDepth
+	}
+
+	budget := newListBudget(maxSearchEntries)
+	visited := map[string]bool{searchPath: true}
+	var files []contracts.FileResponse
+	summary := contracts.FileSummary{}
+	truncated := false
+
+	level := []string{searchPath}
+	for depth := 0; len(level) > 0 && !truncated; depth++ {
+		if depth > req.MaxDepth {
+			truncated = true
+			break
+		}
+
+		var next []string
+		for _, dirPath := range level {
+			if budget.exhausted() || len(files) >= req.Limit {
+				truncated = true
+				break
+			}
+
+			items, err := s.listDirItems(ctx, dirPath, budget)
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+				if dirPath == searchPath && len(items) == 0 {
+					return nil, fmt.Errorf("failed to search files: %w", err)
+				}
+				logger.Warn("Failed to list directory while searching", "path", dirPath, "error", err)
+			}
+
+			for _, item := range items {
+				item = normalizeFileItem(item)
+				if isHiddenName(item.Name) {
+					continue
+				}
+				file := s.convertToFileResponse(item, dirPath)
+				if item.IsDir {
+					if !visited[file.Path] {
+						visited[file.Path] = true
+						next = append(next, file.Path)
+					}
+					continue
+				}
+				if !s.matchesSearch(file, keywords, req) {
+					continue
+				}
+				if len(files) >= req.Limit {
+					truncated = true
+					break
+				}
+				files = append(files, file)
+				summary.TotalFiles++
+				summary.TotalSize += file.Size
+			}
+		}
+		level = next
+	}
+	truncated = truncated || budget.truncated
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	logger.Info("File search completed", "query", req.Query, "path", searchPath, "matches", len(files), "truncated", truncated)
+
+	return &contracts.FileListResponse{
+		Files:       files,
+		CurrentPath: searchPath,
+		TotalCount:  len(files),
+		Summary:     summary,
+		Truncated:   truncated,
+	}, nil
+}
+
+// matchesSearch 文件名包含全部关键词，且满足类型、大小和修改时间过滤条件
+func (s *AppFileService) matchesSearch(file contracts.FileResponse, keywords []string, req contracts.FileSearchRequest) bool {
+	name := strings.ToLower(file.Name)
+	for _, keyword := range keywords {
+		if !strings.Contains(name, keyword) {
+			return false
+		}
+	}
+
+	switch {
+	case req.VideoOnly && !s.IsVideoFile(file.Name):
+		return false
+	case req.FileType != "" && s.GetFileCategory(file.Name) != req.FileType:
+		return false
+	case req.MinSize > 0 && file.Size < req.MinSize:
+		return false
+	case req.MaxSize > 0 && file.Size > req.MaxSize:
+		return false
+	case req.ModifiedAfter != nil && file.Modified.Before(*req.ModifiedAfter):
+		return false
+	case req.ModifiedBefore != nil && file.Modified.After(*req.ModifiedBefore):
+		return false
+	}
+	return true
+}
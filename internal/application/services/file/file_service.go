@@ -3,6 +3,8 @@ package file
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"path"
 	"strings"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 	domainpathservices "github.com/easayliu/alist-aria2-download/internal/domain/services/path"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/alist"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 	pathutil "github.com/easayliu/alist-aria2-download/pkg/utils/path"
@@ -35,8 +38,24 @@ type AppFileService struct {
 	tmdbClient      *tmdb.Client
 	renameSuggester *RenameSuggester
 
+	// 分类纠正
+	categoryOverrides *repository.CategoryOverrideRepository
+	downloadHistory   *repository.DownloadHistoryRepository
+
+	// 目录快照
+	snapshots *repository.SnapshotRepository
+
+	// 目录下载断点
+	checkpoints *repository.DirectoryCheckpointRepository
+
 	// LLM相关
 	llmSuggester *filename.LLMSuggester // LLM文件名推断器
+
+	// 最近一次目录清单
+	inventoryCache inventoryCache
+
+	// 视频时长和分辨率
+	videoProbeCache videoProbeCache
 }
 
 // NewAppFileService 创建应用文件服务
@@ -68,7 +87,10 @@ func NewAppFileService(cfg *config.Config, llmService contracts.LLMService, down
 		if cfg.TMDB.QPS > 0 {
 			service.tmdbClient.SetQPS(cfg.TMDB.QPS)
 		}
+		service.tmdbClient.SetTimeout(time.Duration(cfg.TMDB.Timeout) * time.Second)
+		service.tmdbClient.SetRetry(cfg.TMDB.MaxRetries, 0)
 		service.renameSuggester = NewRenameSuggester(service.tmdbClient, cfg.TMDB.QualityDirPatterns)
+		service.renameSuggester.SetConcurrency(cfg.TMDB.BatchRenameConcurrency)
 		logger.Debug("TMDB Client and RenameSuggester initialized")
 	}
 
@@ -113,27 +135,47 @@ func (s *AppFileService) SetDownloadService(downloadService contracts.DownloadSe
 	}
 }
 
+// SetCategoryOverrides 设置用户纠正的分类存储，文件分类和下载路径生成优先使用纠正后的分类
+func (s *AppFileService) SetCategoryOverrides(overrides *repository.CategoryOverrideRepository) {
+	s.categoryOverrides = overrides
+	s.mediaClassifier.SetOverrides(overrides)
+	if s.pathStrategy != nil {
+		s.pathStrategy.SetOverrides(overrides)
+	}
+}
+
+// SetClassificationRules 设置用户配置的分类规则，文件分类和视频识别优先使用这些规则
+func (s *AppFileService) SetClassificationRules(rules *mediaservices.ClassificationRules) {
+	s.mediaClassifier.SetClassificationRules(rules)
+}
+
+// SetDownloadHistory 设置下载历史存储（纠正分类时查找已下载的文件）
+func (s *AppFileService) SetDownloadHistory(history *repository.DownloadHistoryRepository) {
+	s.downloadHistory = history
+}
+
 // GetFileInfo 获取文件详细信息
 func (s *AppFileService) GetFileInfo(ctx context.Context, path string) (*contracts.FileResponse, error) {
 	// 从路径中提取目录和文件名
 	parentDir := pathutil.GetParentPath(path)
 	fileName := pathutil.GetFileName(path)
 
-	// 获取父目录列表
-	listResp, err := s.alistClient.ListFiles(parentDir, 1, 1000)
-	if err != nil {
+	// 获取父目录列表（逐页读取，避免超过一页的目录中找不到文件）
+	items, err := s.listDirItems(ctx, parentDir, newListBudget(0))
+	if err != nil && len(items) == 0 {
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
 
 	// 查找目标文件
-	for _, item := range listResp.Data.Content {
+	for _, item := range items {
+		item = normalizeFileItem(item)
 		if item.Name == fileName {
 			fileResp := s.convertToFileResponse(item, parentDir)
 
 			// 如果不是目录，获取实际的raw_url用于下载
 			if !item.IsDir {
 				logger.Debug("Getting real download URL", "file", fileName, "path", path)
-				internalURL, externalURL := s.getRealDownloadURLs(path)
+				internalURL, externalURL := s.getRealDownloadURLs(fileResp.Path)
 				fileResp.InternalURL = internalURL
 				fileResp.ExternalURL = externalURL
 				logger.Debug("File response URLs updated")
@@ -170,12 +212,24 @@ func (s *AppFileService) GetStorageInfo(ctx context.Context, path string) (map[s
 		"movie_files":          listResp.Summary.MovieFiles,
 		"tv_files":             listResp.Summary.TVFiles,
 		"other_files":          listResp.Summary.OtherFiles,
+		"music_files":          listResp.Summary.MusicFiles,
+		"document_files":       listResp.Summary.DocumentFiles,
 	}, nil
 }
 
+// PingAlist 获取一次根目录信息测量 Alist 往返延迟（token 过期时包含重新登录的耗时）
+func (s *AppFileService) PingAlist(ctx context.Context) (time.Duration, error) {
+	if s.alistClient == nil {
+		return 0, fmt.Errorf("alist client not initialized")
+	}
+	start := time.Now()
+	_, err := s.alistClient.GetFileInfoWithContext(ctx, "/")
+	return time.Since(start), err
+}
+
 // convertToFileResponse 转换AList文件对象到响应格式
 func (s *AppFileService) convertToFileResponse(item alist.FileItem, basePath string) contracts.FileResponse {
-	fullPath := pathutil.JoinPath(basePath, item.Name)
+	fullPath := resolveItemPath(item, basePath)
 
 	// 解析修改时间
 	logger.Debug("Parsing time", "file", item.Name, "modifiedString", item.Modified)
@@ -198,8 +252,15 @@ func (s *AppFileService) convertToFileResponse(item alist.FileItem, basePath str
 	}
 
 	if !item.IsDir {
-		// 使用统一的路径分类服务（优先路径，回退文件名）
+		// 使用统一的路径分类服务（优先路径，回退文件名），用户纠正过的分类优先
 		category := s.pathCategory.GetCategoryFromPathWithFallback(fullPath, item.Name, s.GetFileCategory)
+		// 音乐和文档按扩展名识别，优先于路径中的视频分类（如剧集目录中的原声音乐）
+		if extCategory := s.mediaClassifier.ExtensionCategory(item.Name); extCategory != "" {
+			category = extCategory
+		}
+		if override, ok := s.mediaClassifier.OverrideFor(item.Name); ok {
+			category = override.Category
+		}
 		resp.MediaType = category
 		resp.Category = category
 		logger.Debug("File classification completed", "file", item.Name, "category", category)
@@ -215,6 +276,46 @@ func (s *AppFileService) convertToFileResponse(item alist.FileItem, basePath str
 	return resp
 }
 
+// isHiddenName 判断是否为隐藏文件或目录（名称以 . 开头）
+func isHiddenName(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+// normalizeFileItem 规范化 Alist 返回的条目
+// 部分存储返回的 is_dir 不可靠（或目录名带有结尾斜杠），以类型标记重新判定是否为目录
+func normalizeFileItem(item alist.FileItem) alist.FileItem {
+	if trimmed := strings.TrimRight(item.Name, "/"); trimmed != item.Name && trimmed != "" {
+		item.Name = trimmed
+		item.IsDir = true
+		return item
+	}
+
+	switch item.Type {
+	case alist.FileTypeFolder:
+		item.IsDir = true
+	case alist.FileTypeVideo, alist.FileTypeAudio, alist.FileTypeText, alist.FileTypeImage:
+		item.IsDir = false
+	}
+	// 未知类型保留 is_dir 原值
+	return item
+}
+
+// resolveItemPath 解析条目的真实路径
+// 聚合/别名存储中 Alist 会返回条目自身的 path，它可能与浏览路径不同，
+// 此时以 Alist 返回的 path 为准，避免拼接出错误的下载地址
+func resolveItemPath(item alist.FileItem, basePath string) string {
+	if item.Path == "" || !strings.HasPrefix(item.Path, "/") {
+		return pathutil.JoinPath(basePath, item.Name)
+	}
+
+	itemPath := path.Clean(item.Path)
+	// path 可能是条目完整路径，也可能是其所在目录
+	if path.Base(itemPath) == item.Name {
+		return itemPath
+	}
+	return path.Join(itemPath, item.Name)
+}
+
 // getRealDownloadURLs 获取实际的下载URL（参考旧实现的简单有效方法）
 func (s *AppFileService) getRealDownloadURLs(filePath string) (internalURL, externalURL string) {
 	logger.Debug("Getting raw URL", "path", filePath)
@@ -264,6 +365,49 @@ func (s *AppFileService) getRealDownloadURLs(filePath string) (internalURL, exte
 	return internalURL, externalURL
 }
 
+// applyAlistAuthHeader 为指向 Alist 服务本身的下载链接添加鉴权头
+// 仅在链接与 Alist BaseURL 同源时添加，避免 token 泄露给第三方存储
+func (s *AppFileService) applyAlistAuthHeader(req *contracts.DownloadRequest) {
+	token := s.alistAuthToken(req.URL, req.SourcePath)
+	if token == "" {
+		return
+	}
+
+	if req.Headers == nil {
+		req.Headers = make(map[string]string)
+	}
+	if _, exists := req.Headers["Authorization"]; !exists {
+		req.Headers["Authorization"] = token
+	}
+}
+
+// alistAuthToken 链接与 Alist BaseURL 同源时返回 Alist token，否则返回空字符串
+func (s *AppFileService) alistAuthToken(rawURL, sourcePath string) string {
+	if s.alistClient == nil || !isSameOrigin(rawURL, s.config.Alist.BaseURL) {
+		return ""
+	}
+
+	token, err := s.alistClient.GetValidToken(context.Background())
+	if err != nil || token == "" {
+		logger.Warn("Failed to get alist token for download", "path", sourcePath, "error", err)
+		return ""
+	}
+	return token
+}
+
+// isSameOrigin 判断两个URL的协议和主机是否一致
+func isSameOrigin(rawURL, baseURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(baseURL)
+	if err != nil || b.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, b.Scheme) && strings.EqualFold(u.Host, b.Host)
+}
+
 // generateInternalURL 生成内部下载URL（回退方法）
 func (s *AppFileService) generateInternalURL(path string) string {
 	url := fmt.Sprintf("%s/d%s", s.config.Alist.BaseURL, path)
@@ -300,8 +444,12 @@ func (s *AppFileService) buildDownloadRequest(
 		Options:      options,
 		AutoClassify: autoClassify,
 		FileSize:     fileResp.Size,
+		SourcePath:   fileResp.Path,
 	}
 
+	// Alist 自身的直链需要携带 token 才能访问受保护的存储
+	s.applyAlistAuthHeader(&downloadReq)
+
 	// 如果没有指定目标目录，使用自动生成的下载路径
 	if downloadReq.Directory == "" {
 		downloadReq.Directory = s.GenerateDownloadPath(fileResp)
@@ -0,0 +1,610 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/alist"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
+)
+
+// TestResolveItemPath 测试条目真实路径解析（含聚合/别名存储）
+func TestResolveItemPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     alist.FileItem
+		basePath string
+		expected string
+	}{
+		{
+			name:     "普通条目使用浏览路径拼接",
+			item:     alist.FileItem{Name: "movie.mkv"},
+			basePath: "/media/movies",
+			expected: "/media/movies/movie.mkv",
+		},
+		{
+			name:     "别名条目path为完整路径",
+			item:     alist.FileItem{Name: "movie.mkv", Path: "/storage-b/movies/movie.mkv"},
+			basePath: "/alias/movies",
+			expected: "/storage-b/movies/movie.mkv",
+		},
+		{
+			name:     "别名条目path为所在目录",
+			item:     alist.FileItem{Name: "movie.mkv", Path: "/storage-b/movies/"},
+			basePath: "/alias/movies",
+			expected: "/storage-b/movies/movie.mkv",
+		},
+		{
+			name:     "path与浏览路径一致",
+			item:     alist.FileItem{Name: "S01E01.mkv", Path: "/tvs/Show/S01/S01E01.mkv"},
+			basePath: "/tvs/Show/S01",
+			expected: "/tvs/Show/S01/S01E01.mkv",
+		},
+		{
+			name:     "非绝对path被忽略",
+			item:     alist.FileItem{Name: "movie.mkv", Path: "movie.mkv"},
+			basePath: "/media",
+			expected: "/media/movie.mkv",
+		},
+		{
+			name:     "path需要规范化",
+			item:     alist.FileItem{Name: "movie.mkv", Path: "/storage-b//movies/./movie.mkv"},
+			basePath: "/alias",
+			expected: "/storage-b/movies/movie.mkv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveItemPath(tt.item, tt.basePath)
+			if got != tt.expected {
+				t.Errorf("resolveItemPath() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNormalizeFileItem 测试 Alist 条目目录/文件判定的规范化
+func TestNormalizeFileItem(t *testing.T) {
+	tests := []struct {
+		name         string
+		item         alist.FileItem
+		expectedName string
+		expectedDir  bool
+	}{
+		{
+			name:         "目录无结尾斜杠且is_dir缺失",
+			item:         alist.FileItem{Name: "Season 1", Type: alist.FileTypeFolder},
+			expectedName: "Season 1",
+			expectedDir:  true,
+		},
+		{
+			name:         "目录名带结尾斜杠",
+			item:         alist.FileItem{Name: "Movies/", Type: alist.FileTypeUnknown},
+			expectedName: "Movies",
+			expectedDir:  true,
+		},
+		{
+			name:         "视频文件被误标为目录",
+			item:         alist.FileItem{Name: "S01E01.mkv", IsDir: true, Type: alist.FileTypeVideo},
+			expectedName: "S01E01.mkv",
+			expectedDir:  false,
+		},
+		{
+			name:         "未知类型保留is_dir",
+			item:         alist.FileItem{Name: "archive", IsDir: true, Type: alist.FileTypeUnknown},
+			expectedName: "archive",
+			expectedDir:  true,
+		},
+		{
+			name:         "未知类型普通文件",
+			item:         alist.FileItem{Name: "movie.iso", Type: alist.FileTypeUnknown},
+			expectedName: "movie.iso",
+			expectedDir:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeFileItem(tt.item)
+			if got.Name != tt.expectedName || got.IsDir != tt.expectedDir {
+				t.Errorf("normalizeFileItem() = {Name: %q, IsDir: %v}, want {Name: %q, IsDir: %v}",
+					got.Name, got.IsDir, tt.expectedName, tt.expectedDir)
+			}
+		})
+	}
+}
+
+// TestPreviewDelete 测试删除试运行只返回目标而不调用 Alist
+func TestPreviewDelete(t *testing.T) {
+	// 指向不可用地址，若试运行误调用删除接口会返回错误
+	s := &AppFileService{alistClient: alist.NewClient("http://127.0.0.1:1", "", "")}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected []contracts.DeleteTarget
+	}{
+		{
+			name:     "单个文件",
+			paths:    []string{"/movies/movie.mkv"},
+			expected: []contracts.DeleteTarget{{Dir: "/movies", Names: []string{"movie.mkv"}}},
+		},
+		{
+			name:  "按目录分组并排序",
+			paths: []string{"/tvs/Show/S01/E02.mkv", "/movies/movie.mkv", "/tvs/Show/S01/E01.mkv"},
+			expected: []contracts.DeleteTarget{
+				{Dir: "/movies", Names: []string{"movie.mkv"}},
+				{Dir: "/tvs/Show/S01", Names: []string{"E02.mkv", "E01.mkv"}},
+			},
+		},
+		{
+			name:     "目录",
+			paths:    []string{"/tvs/Show/"},
+			expected: []contracts.DeleteTarget{{Dir: "/tvs", Names: []string{"Show"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.PreviewDelete(context.Background(), tt.paths)
+			if err != nil {
+				t.Fatalf("PreviewDelete() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("PreviewDelete() = %+v, want %+v", got, tt.expected)
+			}
+			if err := s.DeleteFile(contracts.WithDryRun(context.Background()), tt.paths[0]); err != nil {
+				t.Errorf("DeleteFile() dry run error = %v", err)
+			}
+		})
+	}
+}
+
+// TestGetEpisodeTag 测试文件浏览时的季集标记解析（无需TMDB）
+func TestGetEpisodeTag(t *testing.T) {
+	s := &AppFileService{config: &config.Config{}}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{
+			name:     "标准SxxEyy",
+			path:     "/data/tvs/Friends/Season 02/Friends.S02E05.1080p.mkv",
+			expected: "S02E05",
+		},
+		{
+			name:     "多集文件",
+			path:     "/data/tvs/Friends/Season 01/Friends.S01E01-E03.mkv",
+			expected: "S01E01-E03",
+		},
+		{
+			name:     "电影不显示",
+			path:     "/data/movies/Inception.2010.1080p.mkv",
+			expected: "",
+		},
+		{
+			name:     "不含数字的文件名跳过",
+			path:     "/data/tvs/Friends/Season 01/trailer.mkv",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.GetEpisodeTag(tt.path); got != tt.expected {
+				t.Errorf("GetEpisodeTag(%q) = %q, want %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseMediaInfo(t *testing.T) {
+	s := &AppFileService{config: &config.Config{}}
+
+	tests := []struct {
+		name         string
+		path         string
+		title        string
+		episodeTitle string
+		year         int
+		season       int
+		episode      int
+	}{
+		{
+			name:         "Emby剧集格式",
+			path:         "/tvs/新闻女王/Season 2/新闻女王 - S02E06 - 第六集.mp4",
+			title:        "新闻女王",
+			episodeTitle: "第六集",
+			season:       2,
+			episode:      6,
+		},
+		{
+			name:    "目录中的剧名",
+			path:    "/tvs/Friends/Season 01/Friends.S01E03.1080p.WEB-DL.mkv",
+			title:   "Friends",
+			season:  1,
+			episode: 3,
+		},
+		{
+			name:  "Emby电影格式",
+			path:  "/movies/Inception (2010).mkv",
+			title: "Inception",
+			year:  2010,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.ParseMediaInfo(tt.path)
+			if got.Title != tt.title || got.EpisodeTitle != tt.episodeTitle || got.Year != tt.year {
+				t.Errorf("ParseMediaInfo(%q) = %+v", tt.path, got)
+			}
+			if tt.season > 0 && (got.Season == nil || *got.Season != tt.season || got.Episode == nil || *got.Episode != tt.episode) {
+				t.Errorf("ParseMediaInfo(%q) season/episode = %v/%v, want %d/%d", tt.path, got.Season, got.Episode, tt.season, tt.episode)
+			}
+		})
+	}
+}
+
+// TestInventoryScanAddFiles 测试目录清点达到文件数上限时截断
+func TestInventoryScanAddFiles(t *testing.T) {
+	files := func(n int) []contracts.FileResponse { return make([]contracts.FileResponse, n) }
+
+	tests := []struct {
+		name          string
+		batches       []int
+		maxFiles      int
+		wantCount     int
+		wantTruncated bool
+	}{
+		{name: "未达上限", batches: []int{3, 4}, maxFiles: 10, wantCount: 7},
+		{name: "恰好达到上限", batches: []int{5, 5}, maxFiles: 10, wantCount: 10},
+		{name: "超过上限截断", batches: []int{6, 6}, maxFiles: 10, wantCount: 10, wantTruncated: true},
+		{name: "已满后继续追加", batches: []int{10, 1}, maxFiles: 10, wantCount: 10, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scan := &inventoryScan{}
+			for _, n := range tt.batches {
+				scan.addFiles(files(n), tt.maxFiles)
+			}
+			if len(scan.files) != tt.wantCount || scan.truncated != tt.wantTruncated {
+				t.Errorf("files = %d, truncated = %v, want %d, %v", len(scan.files), scan.truncated, tt.wantCount, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+// TestTopLevelDir 测试按扫描目录的一级子目录归类文件
+func TestTopLevelDir(t *testing.T) {
+	tests := []struct {
+		root, path, want string
+	}{
+		{root: "/media", path: "/media/tvs/Show/S01E01.mkv", want: "tvs"},
+		{root: "/media/", path: "/media/movies/a.mkv", want: "movies"},
+		{root: "/media", path: "/media/a.mkv", want: ""},
+		{root: "/", path: "/downloads/a.mkv", want: "downloads"},
+	}
+
+	for _, tt := range tests {
+		if got := topLevelDir(tt.root, tt.path); got != tt.want {
+			t.Errorf("topLevelDir(%q, %q) = %q, want %q", tt.root, tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestSortFilesByModifiedDesc 测试最新文件按修改时间倒序排序
+func TestSortFilesByModifiedDesc(t *testing.T) {
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	files := []contracts.FileResponse{
+		{Path: "/a/old.mkv", Modified: base.Add(-time.Hour)},
+		{Path: "/b/new.mkv", Modified: base},
+		{Path: "/a/new.mkv", Modified: base},
+		{Path: "/c/unknown.mkv"},
+	}
+
+	sortFilesByModifiedDesc(files)
+
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	want := []string{"/a/new.mkv", "/b/new.mkv", "/a/old.mkv", "/c/unknown.mkv"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortFilesByModifiedDesc() = %v, want %v", got, want)
+	}
+}
+
+// TestListDirItems 测试超大目录逐页读取，超过上限时截断并停止请求后续页面
+func TestListDirItems(t *testing.T) {
+	const dirSize = 1200
+
+	tests := []struct {
+		name          string
+		limit         int
+		wantItems     int
+		wantTruncated bool
+		wantPages     int
+	}{
+		{name: "目录小于上限", limit: 2000, wantItems: dirSize, wantTruncated: false, wantPages: 3},
+		{name: "目录超过上限", limit: 700, wantItems: 700, wantTruncated: true, wantPages: 2},
+		{name: "上限为整页", limit: alistListPageSize, wantItems: alistListPageSize, wantTruncated: true, wantPages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var resp any
+				switch r.URL.Path {
+				case "/api/auth/login":
+					resp = map[string]any{"code": 200, "data": map[string]any{"token": "test-token"}}
+				case "/api/fs/list":
+					var req alist.FileListRequest
+					_ = json.NewDecoder(r.Body).Decode(&req)
+					pages++
+
+					content := []map[string]any{}
+					for i := (req.Page - 1) * req.PerPage; i < min(req.Page*req.PerPage, dirSize); i++ {
+						content = append(content, map[string]any{"name": fmt.Sprintf("file%04d.mkv", i)})
+					}
+					resp = map[string]any{"code": 200, "data": map[string]any{"content": content, "total": dirSize}}
+				default:
+					http.NotFound(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(resp)
+			}))
+			defer server.Close()
+
+			s := &AppFileService{alistClient: alist.NewClient(server.URL, "user", "pass")}
+			budget := newListBudget(tt.limit)
+			items, err := s.listDirItems(context.Background(), "/huge", budget)
+			if err != nil {
+				t.Fatalf("listDirItems() error = %v", err)
+			}
+			if len(items) != tt.wantItems || budget.truncated != tt.wantTruncated || pages != tt.wantPages {
+				t.Errorf("listDirItems() = %d items, truncated %v, %d pages; want %d, %v, %d",
+					len(items), budget.truncated, pages, tt.wantItems, tt.wantTruncated, tt.wantPages)
+			}
+			if len(items) > 0 && items[len(items)-1].Name != fmt.Sprintf("file%04d.mkv", len(items)-1) {
+				t.Errorf("last item = %s, want pages in order", items[len(items)-1].Name)
+			}
+		})
+	}
+}
+
+// TestDiffSnapshot 测试目录快照对比：按路径区分新增、删除、变化和未变文件
+func TestDiffSnapshot(t *testing.T) {
+	base := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := &entities.DirectorySnapshot{
+		Path: "/tvs",
+		Files: []entities.SnapshotFile{
+			{Path: "/tvs/same.mkv", Size: 100, Modified: base},
+			{Path: "/tvs/resized.mkv", Size: 100, Modified: base},
+			{Path: "/tvs/touched.mkv", Size: 100, Modified: base},
+			{Path: "/tvs/gone.mkv", Size: 100, Modified: base},
+		},
+		CreatedAt: base,
+	}
+	current := []contracts.FileResponse{
+		{Path: "/tvs/same.mkv", Size: 100, Modified: base},
+		{Path: "/tvs/resized.mkv", Size: 200, Modified: base},
+		{Path: "/tvs/touched.mkv", Size: 100, Modified: base.Add(time.Hour)},
+		{Path: "/tvs/b-new.mkv", Size: 50, Modified: base},
+		{Path: "/tvs/a-new.mkv", Size: 50, Modified: base},
+	}
+
+	diff := diffSnapshot(snapshot, current)
+
+	paths := func(files []contracts.FileResponse) []string {
+		var got []string
+		for _, f := range files {
+			got = append(got, f.Path)
+		}
+		return got
+	}
+	if got, want := paths(diff.Added), []string{"/tvs/a-new.mkv", "/tvs/b-new.mkv"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Added = %v, want %v", got, want)
+	}
+	if got, want := paths(diff.Removed), []string{"/tvs/gone.mkv"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Removed = %v, want %v", got, want)
+	}
+	if got, want := paths(diff.Changed), []string{"/tvs/resized.mkv", "/tvs/touched.mkv"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed = %v, want %v", got, want)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", diff.Unchanged)
+	}
+}
+
+// TestProbeDirectoryZip 测试目录打包下载能力探测
+func TestProbeDirectoryZip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("path") {
+		case "/电影/Big Show":
+			w.Header().Set("Content-Type", "application/zip")
+			w.Header().Set("Content-Range", "bytes 0-0/2048")
+			w.WriteHeader(http.StatusPartialContent)
+		case "/streamed":
+			// 实时打包，分块传输大小未知
+			w.Header().Set("Content-Type", "application/zip")
+			w.(http.Flusher).Flush()
+		case "/spa":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html></html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		template        string
+		path            string
+		wantSize        int64
+		wantName        string
+		wantUnsupported bool
+	}{
+		{name: "支持 Range 返回大小", template: server.URL + "/zip?path={path}", path: "/电影/Big Show", wantSize: 2048, wantName: "Big Show.zip"},
+		{name: "实时打包大小未知", template: server.URL + "/zip?path={path}", path: "/streamed", wantSize: -1, wantName: "streamed.zip"},
+		{name: "返回前端页面视为不支持", template: server.URL + "/zip?path={path}", path: "/spa", wantUnsupported: true},
+		{name: "404 视为不支持", template: server.URL + "/zip?path={path}", path: "/missing", wantUnsupported: true},
+		{name: "未配置", template: "", path: "/电影", wantUnsupported: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AppFileService{config: &config.Config{Alist: config.AlistConfig{ZipDownloadURL: tt.template}}}
+			zip, err := s.ProbeDirectoryZip(context.Background(), tt.path)
+			if tt.wantUnsupported {
+				if !errors.Is(err, contracts.ErrDirectoryZipUnsupported) {
+					t.Fatalf("ProbeDirectoryZip() error = %v, want ErrDirectoryZipUnsupported", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProbeDirectoryZip() error = %v", err)
+			}
+			if zip.Size != tt.wantSize || zip.FileName != tt.wantName {
+				t.Errorf("ProbeDirectoryZip() = size %d name %q, want size %d name %q", zip.Size, zip.FileName, tt.wantSize, tt.wantName)
+			}
+		})
+	}
+}
+
+// TestPlanReorganize 测试整理计划中目标文件已存在时按冲突策略处理
+func TestPlanReorganize(t *testing.T) {
+	candidates := []reorganizeCandidate{
+		{from: "/d/a.mkv", targetDir: "/d/movies/A", category: "movie"},
+		{from: "/d/old/b.mkv", targetDir: "/d/tvs/B/S01", category: "tv"},
+		{from: "/d/new/b.mkv", targetDir: "/d/tvs/B/S01", category: "tv"},
+	}
+	existing := map[string]bool{"/d/movies/A/a.mkv": true, "/d/movies/A/a_1.mkv": true}
+	exists := func(path string) bool { return existing[path] }
+
+	tests := []struct {
+		policy      filesystem.ConflictPolicy
+		wantTo      []string
+		wantActions []string
+	}{
+		{
+			policy:      filesystem.ConflictPolicyRename,
+			wantTo:      []string{"/d/movies/A/a_2.mkv", "/d/tvs/B/S01/b.mkv", "/d/tvs/B/S01/b_1.mkv"},
+			wantActions: []string{"rename", "move", "rename"},
+		},
+		{
+			policy:      filesystem.ConflictPolicySkip,
+			wantTo:      []string{"/d/movies/A/a.mkv", "/d/tvs/B/S01/b.mkv", "/d/tvs/B/S01/b.mkv"},
+			wantActions: []string{"skip", "move", "skip"},
+		},
+		{
+			// 本次整理中移入的文件不会被覆盖
+			policy:      filesystem.ConflictPolicyOverwrite,
+			wantTo:      []string{"/d/movies/A/a.mkv", "/d/tvs/B/S01/b.mkv", "/d/tvs/B/S01/b.mkv"},
+			wantActions: []string{"overwrite", "move", "skip"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			moves := planReorganize(candidates, tt.policy, exists)
+			var gotTo, gotActions []string
+			for _, m := range moves {
+				gotTo = append(gotTo, m.To)
+				gotActions = append(gotActions, m.Action)
+			}
+			if !reflect.DeepEqual(gotTo, tt.wantTo) {
+				t.Errorf("targets = %v, want %v", gotTo, tt.wantTo)
+			}
+			if !reflect.DeepEqual(gotActions, tt.wantActions) {
+				t.Errorf("actions = %v, want %v", gotActions, tt.wantActions)
+			}
+		})
+	}
+
+	// 目标是另一个待整理文件的源文件时不能覆盖，否则该文件会在移走前被删除
+	chained := []reorganizeCandidate{
+		{from: "/d/new/b.mkv", targetDir: "/d/tvs/B/S01", category: "tv"},
+		{from: "/d/tvs/B/S01/b.mkv", targetDir: "/d/movies/B", category: "movie"},
+	}
+	chainedExists := func(path string) bool { return path == "/d/new/b.mkv" || path == "/d/tvs/B/S01/b.mkv" }
+	moves := planReorganize(chained, filesystem.ConflictPolicyOverwrite, chainedExists)
+	if moves[0].Action != contracts.ReorganizeActionSkip || moves[1].Action != contracts.ReorganizeActionMove {
+		t.Errorf("chained overwrite actions = [%s %s], want [skip move]", moves[0].Action, moves[1].Action)
+	}
+}
+
+// recordingDownloadService 记录提交的批量下载请求
+type recordingDownloadService struct {
+	contracts.DownloadService
+	batch contracts.BatchDownloadRequest
+}
+
+func (r *recordingDownloadService) CreateBatchDownload(ctx context.Context, req contracts.BatchDownloadRequest) (*contracts.BatchDownloadResponse, error) {
+	r.batch = req
+	return &contracts.BatchDownloadResponse{SuccessCount: len(req.Items)}, nil
+}
+
+// TestDownloadDirectoryDeleteAfterDownload 测试目录下载的每个文件都带上下载后删除源文件标记
+func TestDownloadDirectoryDeleteAfterDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp any
+		switch r.URL.Path {
+		case "/api/auth/login":
+			resp = map[string]any{"code": 200, "data": map[string]any{"token": "test-token"}}
+		case "/api/fs/list":
+			content := []map[string]any{{"name": "a.mkv", "size": 100}, {"name": "b.srt", "size": 1}}
+			resp = map[string]any{"code": 200, "data": map[string]any{"content": content, "total": len(content)}}
+		case "/api/fs/get":
+			resp = map[string]any{"code": 200, "data": map[string]any{"raw_url": "http://example.com/raw"}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	for _, deleteAfter := range []bool{true, false} {
+		t.Run(fmt.Sprint(deleteAfter), func(t *testing.T) {
+			downloads := &recordingDownloadService{}
+			cfg := &config.Config{Alist: config.AlistConfig{BaseURL: server.URL, Username: "user", Password: "pass"}}
+			s := NewAppFileService(cfg, nil, downloads)
+
+			_, err := s.DownloadDirectory(context.Background(), contracts.DirectoryDownloadRequest{
+				DirectoryPath:       "/media",
+				TargetDir:           "/downloads",
+				DeleteAfterDownload: deleteAfter,
+			})
+			if err != nil {
+				t.Fatalf("DownloadDirectory() error = %v", err)
+			}
+			if len(downloads.batch.Items) != 2 {
+				t.Fatalf("submitted %d items, want 2", len(downloads.batch.Items))
+			}
+			for _, item := range downloads.batch.Items {
+				if item.DeleteAfterDownload != deleteAfter {
+					t.Errorf("item %s DeleteAfterDownload = %v, want %v", item.Filename, item.DeleteAfterDownload, deleteAfter)
+				}
+			}
+		})
+	}
+}
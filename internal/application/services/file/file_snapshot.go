@@ -0,0 +1,125 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
+)
+
+// SetSnapshots 设置目录快照存储
+func (s *AppFileService) SetSnapshots(snapshots *repository.SnapshotRepository) {
+	s.snapshots = snapshots
+}
+
+// SnapshotDirectory 递归扫描目录并保存文件快照（替换该目录之前的快照）
+func (s *AppFileService) SnapshotDirectory(ctx context.Context, dirPath string) (*contracts.DirectorySnapshotResult, error) {
+	if s.snapshots == nil {
+		return nil, fmt.Errorf("snapshot store not initialized")
+	}
+
+	scan, err := s.scanSnapshotFiles(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &entities.DirectorySnapshot{
+		Path:      dirPath,
+		Files:     make([]entities.SnapshotFile, 0, len(scan.files)),
+		Truncated: scan.truncated || scan.depthLimited || len(scan.failedDirs) > 0,
+		CreatedBy: contracts.UserIDFromContext(ctx),
+	}
+	result := &contracts.DirectorySnapshotResult{Path: dirPath, Truncated: snapshot.Truncated}
+	for _, file := range scan.files {
+		snapshot.Files = append(snapshot.Files, entities.SnapshotFile{Path: file.Path, Size: file.Size, Modified: file.Modified})
+		result.TotalSize += file.Size
+	}
+	result.FileCount = len(snapshot.Files)
+
+	if previous, ok := s.snapshots.Get(dirPath); ok {
+		result.PreviousAt = &previous.CreatedAt
+	}
+	if err := s.snapshots.Save(snapshot); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	result.CreatedAt = snapshot.CreatedAt
+
+	logger.Info("Directory snapshot saved", "path", dirPath, "files", result.FileCount, "truncated", result.Truncated)
+	return result, nil
+}
+
+// DiffDirectory 对比目录当前内容与上次快照，返回新增、删除和变化（大小或修改时间不同）的文件
+func (s *AppFileService) DiffDirectory(ctx context.Context, dirPath string) (*contracts.DirectoryDiff, error) {
+	if s.snapshots == nil {
+		return nil, fmt.Errorf("snapshot store not initialized")
+	}
+
+	snapshot, ok := s.snapshots.Get(dirPath)
+	if !ok {
+		return nil, contracts.ErrSnapshotNotFound
+	}
+
+	scan, err := s.scanSnapshotFiles(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffSnapshot(snapshot, scan.files)
+	diff.Truncated = snapshot.Truncated || scan.truncated || scan.depthLimited || len(scan.failedDirs) > 0
+	return diff, nil
+}
+
+// scanSnapshotFiles 递归列出目录下的文件（跳过隐藏文件），复用目录清点的扫描和上限
+func (s *AppFileService) scanSnapshotFiles(ctx context.Context, dirPath string) (*inventoryScan, error) {
+	if s.alistClient == nil {
+		return nil, fmt.Errorf("alist client not initialized")
+	}
+	return s.scanInventoryTree(ctx, dirPath, defaultInventoryMaxFiles, defaultInventoryMaxDepth)
+}
+
+// diffSnapshot 按文件路径对比快照和当前文件，结果按路径排序
+func diffSnapshot(snapshot *entities.DirectorySnapshot, current []contracts.FileResponse) *contracts.DirectoryDiff {
+	diff := &contracts.DirectoryDiff{
+		Path:       snapshot.Path,
+		SnapshotAt: snapshot.CreatedAt,
+	}
+
+	previous := make(map[string]entities.SnapshotFile, len(snapshot.Files))
+	for _, file := range snapshot.Files {
+		previous[file.Path] = file
+	}
+
+	for _, file := range current {
+		old, existed := previous[file.Path]
+		switch {
+		case !existed:
+			diff.Added = append(diff.Added, file)
+		case old.Size != file.Size || !old.Modified.Equal(file.Modified):
+			diff.Changed = append(diff.Changed, file)
+		default:
+			diff.Unchanged++
+		}
+		delete(previous, file.Path)
+	}
+
+	for _, old := range previous {
+		diff.Removed = append(diff.Removed, contracts.FileResponse{
+			Name:          path.Base(old.Path),
+			Path:          old.Path,
+			Size:          old.Size,
+			SizeFormatted: strutil.FormatFileSize(old.Size),
+			Modified:      old.Modified,
+		})
+	}
+
+	for _, files := range [][]contracts.FileResponse{diff.Added, diff.Removed, diff.Changed} {
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	}
+	return diff
+}
@@ -53,3 +53,17 @@ func (s *AppFileService) cleanShowName(showName string) string {
 func (s *AppFileService) FormatFileSize(size int64) string {
 	return strutil.FormatFileSize(size)
 }
+
+// checkWritable 破坏性操作前检查是否处于只读模式
+func (s *AppFileService) checkWritable(operation string) error {
+	return contracts.CheckWritable(s.config != nil && s.config.Server.ReadOnly, operation)
+}
+
+// failedRenameResults 为所有重命名任务生成相同错误的失败结果
+func failedRenameResults(tasks []contracts.RenameTask, err error) []contracts.RenameResult {
+	results := make([]contracts.RenameResult, len(tasks))
+	for i, task := range tasks {
+		results[i] = contracts.RenameResult{OldPath: task.OldPath, NewPath: task.NewPath, Error: err}
+	}
+	return results
+}
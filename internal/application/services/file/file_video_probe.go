@@ -0,0 +1,121 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	"github.com/easayliu/alist-aria2-download/pkg/utils/media"
+)
+
+const (
+	// videoHeaderBytes 解析容器头部读取的字节数（Matroska 的 Info/Tracks、faststart MP4 的 moov 都在开头）
+	videoHeaderBytes = 1 << 20
+	// videoTailBytes moov 位于文件末尾的 MP4 读取的末尾字节数
+	videoTailBytes = 4 << 20
+	// ffprobeTimeout ffprobe 探测超时时间
+	ffprobeTimeout = 30 * time.Second
+	// videoProbeCacheSize 视频信息缓存的最大条目数，超出后清空重建
+	videoProbeCacheSize = 1000
+)
+
+// videoProbeEntry 单个文件的视频信息缓存，文件大小变化后失效
+type videoProbeEntry struct {
+	size     int64
+	metadata contracts.VideoMetadata
+}
+
+// videoProbeCache 按路径缓存视频信息，避免重复读取远程文件
+type videoProbeCache struct {
+	mu      sync.Mutex
+	entries map[string]videoProbeEntry
+}
+
+// get 返回与文件大小匹配的缓存副本
+func (c *videoProbeCache) get(path string, size int64) (*contracts.VideoMetadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok || entry.size != size {
+		return nil, false
+	}
+	metadata := entry.metadata
+	return &metadata, true
+}
+
+// set 写入缓存
+func (c *videoProbeCache) set(path string, size int64, metadata contracts.VideoMetadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil || len(c.entries) >= videoProbeCacheSize {
+		c.entries = make(map[string]videoProbeEntry)
+	}
+	c.entries[path] = videoProbeEntry{size: size, metadata: metadata}
+}
+
+// ProbeVideoMetadata 获取视频时长和分辨率
+// 先用 Range 请求读取容器头部解析（moov 在末尾的 MP4 再读取文件末尾），解析不到时调用 ffprobe（未安装则返回错误）
+func (s *AppFileService) ProbeVideoMetadata(ctx context.Context, path string, size int64) (*contracts.VideoMetadata, error) {
+	if s.alistClient == nil {
+		return nil, fmt.Errorf("alist client not initialized")
+	}
+	if metadata, ok := s.videoProbeCache.get(path, size); ok {
+		return metadata, nil
+	}
+
+	data, rangeSupported, totalSize, err := s.readFileRange(ctx, path, fmt.Sprintf("bytes=0-%d", videoHeaderBytes-1), videoHeaderBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	source := "header"
+	info := media.ProbeVideoInfo(data)
+	if info.IsZero() && rangeSupported && totalSize > int64(len(data)) && media.NeedsMP4Tail(data) {
+		tail, _, _, err := s.readFileRange(ctx, path, fmt.Sprintf("bytes=-%d", videoTailBytes), videoTailBytes)
+		if err != nil {
+			logger.Debug("Failed to read MP4 tail", "path", path, "error", err)
+		} else {
+			info = media.ProbeMP4Tail(tail)
+		}
+	}
+
+	if info.IsZero() {
+		probeCtx, cancel := context.WithTimeout(ctx, ffprobeTimeout)
+		defer cancel()
+
+		internalURL, _ := s.getRealDownloadURLs(path)
+		info, err = media.FFProbe(probeCtx, internalURL, s.alistAuthToken(internalURL, path))
+		if errors.Is(err, media.ErrFFProbeUnavailable) {
+			return nil, fmt.Errorf("no duration or resolution in file header and ffprobe is not installed")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info.IsZero() {
+			return nil, fmt.Errorf("no video stream found")
+		}
+		source = "ffprobe"
+	}
+
+	metadata := videoMetadata(info, source)
+	s.videoProbeCache.set(path, size, *metadata)
+	logger.Debug("Video metadata probed", "path", path, "source", source,
+		"duration", metadata.Duration, "width", metadata.Width, "height", metadata.Height)
+	return metadata, nil
+}
+
+// videoMetadata 转换为契约层的视频信息
+func videoMetadata(info media.VideoInfo, source string) *contracts.VideoMetadata {
+	return &contracts.VideoMetadata{
+		Duration: info.Duration.Round(time.Second),
+		Width:    info.Width,
+		Height:   info.Height,
+		Source:   source,
+	}
+}
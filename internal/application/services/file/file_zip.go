@@ -0,0 +1,121 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// zipProbeTimeout 探测打包下载地址的超时时间（服务端实时打包时首字节可能较慢）
+const zipProbeTimeout = 30 * time.Second
+
+// zipHTTPClient 探测打包下载使用的 HTTP 客户端
+var zipHTTPClient = &http.Client{Timeout: zipProbeTimeout}
+
+// ProbeDirectoryZip 检查 Alist 部署能否为目录生成压缩包，并获取压缩包大小
+// 未配置 alist.zip_download_url 或服务端拒绝（404/405/501、返回网页或 API 错误）时返回 ErrDirectoryZipUnsupported
+func (s *AppFileService) ProbeDirectoryZip(ctx context.Context, dirPath string) (*contracts.DirectoryZip, error) {
+	zipURL := s.directoryZipURL(dirPath)
+	if zipURL == "" {
+		return nil, contracts.ErrDirectoryZipUnsupported
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, zipURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zip probe request: %w", err)
+	}
+	// 只请求第一个字节，支持 Range 时可从 Content-Range 得到总大小
+	req.Header.Set("Range", "bytes=0-0")
+	if token := s.alistAuthToken(zipURL, dirPath); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := zipHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe directory zip: %w", err)
+	}
+	// 提前关闭响应体即中止传输，不会下载完整压缩包
+	defer resp.Body.Close()
+
+	size := int64(-1)
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if total := parseContentRangeTotal(resp.Header.Get("Content-Range")); total > 0 {
+			size = total
+		}
+	case http.StatusOK:
+		// 服务器忽略了 Range 头；实时打包通常是分块传输，大小未知
+		if resp.ContentLength >= 0 {
+			size = resp.ContentLength
+		}
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return nil, fmt.Errorf("%w: HTTP %d", contracts.ErrDirectoryZipUnsupported, resp.StatusCode)
+	default:
+		return nil, fmt.Errorf("unexpected status code %d from zip download URL", resp.StatusCode)
+	}
+
+	// Alist 对未知路径返回前端页面，API 错误以 JSON 返回，均表示不支持
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/html" || mediaType == "application/json" {
+		return nil, fmt.Errorf("%w: 服务端返回 %s", contracts.ErrDirectoryZipUnsupported, mediaType)
+	}
+
+	return &contracts.DirectoryZip{
+		Path:     dirPath,
+		URL:      zipURL,
+		FileName: directoryZipName(dirPath),
+		Size:     size,
+	}, nil
+}
+
+// DownloadDirectoryZip 将目录压缩包作为单个任务提交到 aria2
+func (s *AppFileService) DownloadDirectoryZip(ctx context.Context, dirPath string) (*contracts.DownloadResponse, error) {
+	if s.downloadService == nil {
+		return nil, fmt.Errorf("download service not available")
+	}
+
+	zip, err := s.ProbeDirectoryZip(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按目录中的位置生成下载路径，压缩包本身不做重命名整理；不记录源路径，避免与目录中的文件混淆
+	downloadReq := contracts.DownloadRequest{
+		URL:       zip.URL,
+		Filename:  zip.FileName,
+		Directory: s.GenerateDownloadPath(contracts.FileResponse{Name: zip.FileName, Path: path.Join(dirPath, zip.FileName)}),
+		FileSize:  max(zip.Size, 0),
+	}
+	if token := s.alistAuthToken(zip.URL, dirPath); token != "" {
+		downloadReq.Headers = map[string]string{"Authorization": token}
+	}
+
+	logger.Info("Downloading directory as zip", "path", dirPath, "url", zip.URL, "size", zip.Size, "directory", downloadReq.Directory)
+	return s.downloadService.CreateDownload(ctx, downloadReq)
+}
+
+// directoryZipURL 根据配置的地址模板生成目录压缩包地址，未配置时返回空字符串
+// 路径按查询参数编码，空格编码为 %20，{path} 位于路径或查询中均可使用
+func (s *AppFileService) directoryZipURL(dirPath string) string {
+	if s.config == nil || s.config.Alist.ZipDownloadURL == "" {
+		return ""
+	}
+	encoded := strings.ReplaceAll(url.QueryEscape(dirPath), "+", "%20")
+	return strings.ReplaceAll(s.config.Alist.ZipDownloadURL, "{path}", encoded)
+}
+
+// directoryZipName 压缩包文件名，根目录使用 root.zip
+func directoryZipName(dirPath string) string {
+	name := path.Base(path.Clean("/" + dirPath))
+	if name == "/" || name == "." {
+		name = "root"
+	}
+	return name + ".zip"
+}
@@ -4,14 +4,64 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
-	"strconv"
+	"sort"
+	"strings"
 
 	"github.com/easayliu/alist-aria2-download/internal/domain/models/rename"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 )
 
-// suggestMovieName 为电影生成重命名建议
+// SuggestMovie 按电影生成重命名建议（文件分类为电影时使用，不按路径识别剧集）
+// 已符合 Emby 电影格式（标题 (年份).ext）的文件返回单个跳过建议
+func (rs *RenameSuggester) SuggestMovie(ctx context.Context, fullPath string) ([]rename.Suggestion, error) {
+	filename := filepath.Base(fullPath)
+	if rs.IsAlreadyEmbyMovieFormat(filename) {
+		logger.Info("电影文件已符合 Emby 标准格式，跳过", "path", fullPath)
+		return []rename.Suggestion{rs.BuildSkippedSuggestion(fullPath, skipReasonEmbyFormat)}, nil
+	}
+
+	info := rs.ParseFileName(fullPath)
+	if info.MediaType != tmdb.MediaTypeMovie {
+		// 路径像剧集目录时解析器使用目录名作为标题，电影需要从文件名重新提取
+		info.MediaType = tmdb.MediaTypeMovie
+		info.Season, info.Episode, info.EndEpisode, info.Part = 0, 0, 0, ""
+		info.Title = rs.cleanFileName(strings.TrimSuffix(filename, info.Extension), info.Year)
+	}
+
+	logger.Info("TMDB movie search started", "path", fullPath, "title", info.Title, "year", info.Year)
+	return rs.suggestMovieName(ctx, fullPath, info)
+}
+
+// movieConfidence 计算电影搜索结果的置信度：按 TMDB 排序递减，
+// 标题（或原始标题）与文件名标题一致时加分，年份一致加分、相差一年（地区上映差异）少量加分、相差更多减分
+func (rs *RenameSuggester) movieConfidence(index int, query string, result tmdb.MovieResult, infoYear, resultYear int) float64 {
+	confidence := 1.0 - (float64(index) * 0.1)
+	if rs.matchOriginalName(query, result.Title) || rs.matchOriginalName(query, result.OriginalTitle) {
+		confidence += 0.1
+	}
+	if infoYear > 0 && resultYear > 0 {
+		switch diff := infoYear - resultYear; {
+		case diff == 0:
+			confidence += 0.2
+		case diff == 1 || diff == -1:
+			confidence += 0.1
+		default:
+			confidence -= 0.3
+		}
+	}
+	return max(confidence, 0)
+}
+
+// movieFileName 生成 Emby 电影文件名：标题 (年份).ext，年份未知时省略
+func movieFileName(title string, year int, ext string) string {
+	if year <= 0 {
+		return title + ext
+	}
+	return fmt.Sprintf("%s (%d)%s", title, year, ext)
+}
+
+// suggestMovieName 为电影生成重命名建议，按置信度从高到低排序
 func (rs *RenameSuggester) suggestMovieName(ctx context.Context, fullPath string, info *MediaInfo) ([]rename.Suggestion, error) {
 	resp, err := rs.tmdbClient.SearchMovie(ctx, info.Title, info.Year)
 	if err != nil {
@@ -24,22 +74,13 @@ func (rs *RenameSuggester) suggestMovieName(ctx context.Context, fullPath string
 
 	suggestions := make([]rename.Suggestion, 0, len(resp.Results))
 	for i, result := range resp.Results {
-		year := 0
-		if result.ReleaseDate != "" {
-			if parsedYear, err := strconv.Atoi(result.ReleaseDate[:4]); err == nil {
-				year = parsedYear
-			}
-		}
-
-		confidence := 1.0 - (float64(i) * 0.1)
-		if info.Year > 0 && year == info.Year {
-			confidence += 0.2
-		}
+		year := rs.extractYear(result.ReleaseDate)
+		confidence := rs.movieConfidence(i, info.Title, result, info.Year, year)
 
 		details, err := rs.tmdbClient.GetMovieDetails(ctx, result.ID)
 		if err != nil {
 			logger.Warn("Failed to get movie details", "movieID", result.ID, "title", result.Title, "error", err)
-			newName := fmt.Sprintf("%s (%d)%s", result.Title, year, info.Extension)
+			newName := movieFileName(result.Title, year, info.Extension)
 			newPath := rs.buildMoviePath(fullPath, result.Title, year, newName)
 
 			suggestions = append(suggestions, rename.Suggestion{
@@ -60,7 +101,7 @@ func (rs *RenameSuggester) suggestMovieName(ctx context.Context, fullPath string
 			title = details.OriginalTitle
 		}
 
-		newName := fmt.Sprintf("%s (%d)%s", title, year, info.Extension)
+		newName := movieFileName(title, year, info.Extension)
 		newPath := rs.buildMoviePath(fullPath, title, year, newName)
 
 		logger.Info("Generated movie rename suggestion",
@@ -86,6 +127,9 @@ func (rs *RenameSuggester) suggestMovieName(ctx context.Context, fullPath string
 		suggestions = append(suggestions, sug)
 	}
 
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
 	return suggestions, nil
 }
 
@@ -106,7 +150,7 @@ func (rs *RenameSuggester) BatchSuggestMovieNames(ctx context.Context, paths []s
 			logger.Info("电影文件已符合 Emby 标准格式，跳过",
 				"path", path,
 				"filename", filename)
-			result[path] = []rename.Suggestion{rs.BuildSkippedSuggestion(path, "已符合 Emby 标准格式")}
+			result[path] = []rename.Suggestion{rs.BuildSkippedSuggestion(path, skipReasonEmbyFormat)}
 			skippedCount++
 			continue
 		}
@@ -40,6 +40,7 @@ func (rs *RenameSuggester) ParseFileName(fullPath string) *MediaInfo {
 	if match := seasonEpisodeRegex.FindStringSubmatch(nameWithoutExt); len(match) > 2 {
 		info.Season, _ = strconv.Atoi(match[1])
 		info.Episode, _ = strconv.Atoi(match[2])
+		info.EndEpisode = rs.extractEndEpisode(nameWithoutExt, info.Episode)
 		info.MediaType = tmdb.MediaTypeTV
 		rs.cachePathInfo(info, fullPath)
 	} else if isTVPath {
@@ -54,6 +55,7 @@ func (rs *RenameSuggester) ParseFileName(fullPath string) *MediaInfo {
 
 		// 尝试提取集数
 		info.Episode = rs.extractEpisodeNumber(nameWithoutExt)
+		info.EndEpisode = rs.extractEndEpisode(nameWithoutExt, info.Episode)
 
 		if info.Episode == 0 {
 			episode, part := rs.extractEpisodeAndPart(nameWithoutExt)
@@ -96,6 +98,27 @@ func (rs *RenameSuggester) extractEpisodeNumber(nameWithoutExt string) int {
 	return 0
 }
 
+// episodeRangeRegex 多集范围（E01-E03、E01E02、E01-03），结尾不能紧跟数字或分辨率标记
+var episodeRangeRegex = regexp.MustCompile(`[Ee](\d{1,3})(?:-[Ee]?|[Ee])(\d{1,3})(?:[^\dpPkK]|$)`)
+
+// extractEndEpisode 提取多集文件的最后一集，起始集需与已解析的集数一致，否则返回0
+func (rs *RenameSuggester) extractEndEpisode(nameWithoutExt string, startEpisode int) int {
+	if startEpisode <= 0 {
+		return 0
+	}
+	match := episodeRangeRegex.FindStringSubmatch(nameWithoutExt)
+	if len(match) < 3 {
+		return 0
+	}
+	start, _ := strconv.Atoi(match[1])
+	end, _ := strconv.Atoi(match[2])
+	if start != startEpisode || end <= start {
+		return 0
+	}
+	logger.Debug("Extracted multi-episode range", "start", start, "end", end)
+	return end
+}
+
 // extractNumericEpisode 提取纯数字集数
 func (rs *RenameSuggester) extractNumericEpisode(fileName string) int {
 	// 尝试匹配文件名开头的数字
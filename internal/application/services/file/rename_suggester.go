@@ -4,6 +4,7 @@ import (
 	"context"
 	"path/filepath"
 	"regexp"
+	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/domain/models/rename"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
@@ -29,6 +30,8 @@ var chineseNumMap = map[string]int{
 type RenameSuggester struct {
 	tmdbClient         *tmdb.Client
 	qualityDirPatterns []string
+	concurrency        int           // 批量重命名时并发处理的剧集数
+	retryDelay         time.Duration // TMDB暂时不可达时的重试间隔，每次重试递增
 }
 
 // NewRenameSuggester 创建重命名建议器
@@ -36,9 +39,15 @@ func NewRenameSuggester(tmdbClient *tmdb.Client, qualityDirPatterns []string) *R
 	return &RenameSuggester{
 		tmdbClient:         tmdbClient,
 		qualityDirPatterns: qualityDirPatterns,
+		retryDelay:         defaultTMDBRetryDelay,
 	}
 }
 
+// SetConcurrency 设置批量重命名时并发处理的剧集数，<=0 使用默认值
+func (rs *RenameSuggester) SetConcurrency(concurrency int) {
+	rs.concurrency = concurrency
+}
+
 // MediaInfo 媒体信息
 type MediaInfo struct {
 	OriginalName string
@@ -47,6 +56,7 @@ type MediaInfo struct {
 	Year         int
 	Season       int
 	Episode      int
+	EndEpisode   int // 多集文件的最后一集（如 E01-E03 中的 3），单集文件为0
 	Part         string
 	Extension    string
 	AirDate      string
@@ -102,9 +112,9 @@ func (rs *RenameSuggester) SearchAndSuggest(ctx context.Context, fullPath string
 }
 
 // embyTVPattern Emby TV 剧集标准格式正则
-// 格式：剧名 - S01E01 - 标题.ext 或 剧名 - S01E01.ext
+// 格式：剧名 - S01E01 - 标题.ext 或 剧名 - S01E01.ext，多集文件为 剧名 - S01E01-E03.ext
 // 支持 1-2 位季度号和 1-3 位集数号（如 S01E100）
-var embyTVPattern = regexp.MustCompile(`^.+\s-\sS\d{1,2}E\d{1,3}(\s-\s.+)?\.\w+$`)
+var embyTVPattern = regexp.MustCompile(`^.+\s-\sS\d{1,2}E\d{1,3}(-E\d{1,3})?(\s-\s.+)?\.\w+$`)
 
 // embyMoviePattern Emby 电影标准格式正则
 // 格式：电影名 (年份).ext
@@ -132,3 +142,11 @@ func (rs *RenameSuggester) BuildSkippedSuggestion(fullPath string, reason string
 		Confidence:   1.0,
 	}
 }
+
+// buildErrorSkippedSuggestion 构建因TMDB请求失败而跳过的建议，与无匹配的跳过区分，用户可稍后重试
+func (rs *RenameSuggester) buildErrorSkippedSuggestion(fullPath string) rename.Suggestion {
+	sug := rs.BuildSkippedSuggestion(fullPath, skipReasonTMDBError)
+	sug.Retryable = true
+	sug.Confidence = 0
+	return sug
+}
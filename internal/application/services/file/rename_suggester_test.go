@@ -1,7 +1,16 @@
 package file
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/models/rename"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
 )
 
 // TestExtractTVInfoFromPath_CombinedShowAndSeason 测试从"剧集名+季度"组合目录中提取信息
@@ -203,3 +212,499 @@ func TestBuildEmbyPath(t *testing.T) {
 		})
 	}
 }
+
+// TestParseFileName_MultiEpisode 测试多集文件的集数范围解析
+func TestParseFileName_MultiEpisode(t *testing.T) {
+	rs := &RenameSuggester{
+		tmdbClient: nil,
+	}
+
+	tests := []struct {
+		name            string
+		path            string
+		expectedEpisode int
+		expectedEnd     int
+	}{
+		{
+			name:            "连字符范围 E01-E03",
+			path:            "/data/tvs/Friends/Season 01/Friends.S01E01-E03.1080p.WEB-DL.mkv",
+			expectedEpisode: 1,
+			expectedEnd:     3,
+		},
+		{
+			name:            "连续标记 E01E02",
+			path:            "/data/tvs/Friends/Season 01/Friends.S01E01E02.1080p.mkv",
+			expectedEpisode: 1,
+			expectedEnd:     2,
+		},
+		{
+			name:            "省略E的范围 E05-06",
+			path:            "/data/tvs/Friends/Season 01/Friends.S01E05-06.mkv",
+			expectedEpisode: 5,
+			expectedEnd:     6,
+		},
+		{
+			name:            "单集文件",
+			path:            "/data/tvs/Friends/Season 01/Friends.S01E04.1080p.mkv",
+			expectedEpisode: 4,
+			expectedEnd:     0,
+		},
+		{
+			name:            "分辨率不视为范围",
+			path:            "/data/tvs/Friends/Season 01/Friends.S01E07-1080p.mkv",
+			expectedEpisode: 7,
+			expectedEnd:     0,
+		},
+		{
+			name:            "结束集小于起始集",
+			path:            "/data/tvs/Friends/Season 01/Friends.S01E05-E02.mkv",
+			expectedEpisode: 5,
+			expectedEnd:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := rs.ParseFileName(tt.path)
+
+			if info.Episode != tt.expectedEpisode {
+				t.Errorf("ParseFileName() Episode = %v, want %v", info.Episode, tt.expectedEpisode)
+			}
+
+			if info.EndEpisode != tt.expectedEnd {
+				t.Errorf("ParseFileName() EndEpisode = %v, want %v", info.EndEpisode, tt.expectedEnd)
+			}
+		})
+	}
+}
+
+// TestResolveEndEpisode 测试多集范围校验及命名
+func TestResolveEndEpisode(t *testing.T) {
+	rs := &RenameSuggester{
+		tmdbClient: nil,
+	}
+
+	tests := []struct {
+		name         string
+		info         *MediaInfo
+		startEpisode int
+		episodeCount int
+		expectedEnd  int
+		expectedOK   bool
+		expectedTag  string
+	}{
+		{
+			name:         "范围在季内",
+			info:         &MediaInfo{Episode: 1, EndEpisode: 3},
+			startEpisode: 1,
+			episodeCount: 10,
+			expectedEnd:  3,
+			expectedOK:   true,
+			expectedTag:  "S01E01-E03",
+		},
+		{
+			name:         "按匹配后的起始集平移",
+			info:         &MediaInfo{Episode: 11, EndEpisode: 12},
+			startEpisode: 1,
+			episodeCount: 10,
+			expectedEnd:  2,
+			expectedOK:   true,
+			expectedTag:  "S01E01-E02",
+		},
+		{
+			name:         "超出季集数报告为未匹配",
+			info:         &MediaInfo{Episode: 9, EndEpisode: 12},
+			startEpisode: 9,
+			episodeCount: 10,
+			expectedEnd:  0,
+			expectedOK:   false,
+		},
+		{
+			name:         "单集文件",
+			info:         &MediaInfo{Episode: 4},
+			startEpisode: 4,
+			episodeCount: 10,
+			expectedEnd:  0,
+			expectedOK:   true,
+			expectedTag:  "S01E04",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, ok := rs.resolveEndEpisode(tt.info, tt.startEpisode, tt.episodeCount)
+			if end != tt.expectedEnd || ok != tt.expectedOK {
+				t.Errorf("resolveEndEpisode() = %v, %v, want %v, %v", end, ok, tt.expectedEnd, tt.expectedOK)
+			}
+			if !ok {
+				return
+			}
+
+			tag := formatEpisodeTag(1, tt.startEpisode, end)
+			if tag != tt.expectedTag {
+				t.Errorf("formatEpisodeTag() = %v, want %v", tag, tt.expectedTag)
+			}
+
+			if !rs.IsAlreadyEmbyTVFormat("Show - " + tag + ".mkv") {
+				t.Errorf("IsAlreadyEmbyTVFormat(%q) = false, want true", tag)
+			}
+		})
+	}
+}
+
+// TestFillParsedTVSuggestions 测试TMDB不可达时仅凭文件名生成的未验证建议
+func TestFillParsedTVSuggestions(t *testing.T) {
+	rs := &RenameSuggester{
+		tmdbClient: nil,
+	}
+
+	pathInfoMap := map[string]*MediaInfo{
+		"/data/tvs/繁花/S01E03.mkv":     {Season: 1, Episode: 3, Extension: ".mkv"},
+		"/data/tvs/繁花/S01E04-E05.mkv": {Season: 1, Episode: 4, EndEpisode: 5, Extension: ".mkv"},
+		"/data/tvs/繁花/花絮.mkv":         {Season: 1, Extension: ".mkv"},
+	}
+	paths := []string{
+		"/data/tvs/繁花/S01E03.mkv",
+		"/data/tvs/繁花/S01E04-E05.mkv",
+		"/data/tvs/繁花/花絮.mkv",
+	}
+
+	result := make(map[string][]rename.Suggestion)
+	count := rs.fillParsedTVSuggestions("繁花", 0, 0, paths, pathInfoMap, result)
+	if count != 2 {
+		t.Fatalf("fillParsedTVSuggestions() count = %d, want 2", count)
+	}
+
+	tests := []struct {
+		path         string
+		expectedPath string
+		skipped      bool
+	}{
+		{path: paths[0], expectedPath: "/data/tvs/繁花/Season 01/繁花 - S01E03.mkv"},
+		{path: paths[1], expectedPath: "/data/tvs/繁花/Season 01/繁花 - S01E04-E05.mkv"},
+		{path: paths[2], skipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			suggestions := result[tt.path]
+			if len(suggestions) != 1 {
+				t.Fatalf("got %d suggestions, want 1", len(suggestions))
+			}
+			sug := suggestions[0]
+			if sug.Skipped != tt.skipped {
+				t.Fatalf("Skipped = %v, want %v", sug.Skipped, tt.skipped)
+			}
+			if tt.skipped {
+				return
+			}
+			if sug.NewPath != tt.expectedPath {
+				t.Errorf("NewPath = %q, want %q", sug.NewPath, tt.expectedPath)
+			}
+			if sug.Source != rename.SourceParsed {
+				t.Errorf("Source = %q, want %q", sug.Source, rename.SourceParsed)
+			}
+			if sug.Confidence >= 0.5 {
+				t.Errorf("Confidence = %v, want low confidence", sug.Confidence)
+			}
+		})
+	}
+}
+
+// TestBatchSuggestTVNames_MultipleShows 测试同一批次中包含多部剧集时分别搜索TMDB并合并结果
+func TestBatchSuggestTVNames_MultipleShows(t *testing.T) {
+	shows := map[string]tmdb.TVResult{
+		"繁花": {ID: 1, Name: "繁花", OriginalName: "繁花", FirstAirDate: "2023-12-27"},
+		"狂飙": {ID: 2, Name: "狂飙", OriginalName: "狂飙", FirstAirDate: "2023-01-14"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		switch r.URL.Path {
+		case "/search/tv":
+			resp := tmdb.SearchTVResponse{}
+			if show, ok := shows[r.URL.Query().Get("query")]; ok {
+				resp.Results = []tmdb.TVResult{show}
+			}
+			body = resp
+		case "/tv/1/season/1", "/tv/2/season/1":
+			body = tmdb.Season{SeasonNumber: 1, EpisodeCount: 2, Episodes: []tmdb.Episode{
+				{EpisodeNumber: 1, SeasonNumber: 1, Name: "第1集"},
+				{EpisodeNumber: 2, SeasonNumber: 1, Name: "第2集"},
+			}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	client := tmdb.NewClient("test-key")
+	client.BaseURL = server.URL
+	rs := NewRenameSuggester(client, nil)
+	rs.SetConcurrency(2)
+
+	paths := []string{
+		"/data/tvs/繁花/Season 1/繁花.S01E01.mkv",
+		"/data/tvs/狂飙/Season 1/狂飙.S01E02.mkv",
+		"/data/tvs/繁花/Season 1/繁花.S01E02.mkv",
+	}
+	result, err := rs.BatchSuggestTVNames(context.Background(), paths)
+	if err != nil {
+		t.Fatalf("BatchSuggestTVNames() error = %v", err)
+	}
+
+	expected := map[string]struct {
+		newPath string
+		tmdbID  int
+	}{
+		paths[0]: {newPath: "/data/tvs/繁花/Season 01/繁花 - S01E01 - 第1集.mkv", tmdbID: 1},
+		paths[1]: {newPath: "/data/tvs/狂飙/Season 01/狂飙 - S01E02 - 第2集.mkv", tmdbID: 2},
+		paths[2]: {newPath: "/data/tvs/繁花/Season 01/繁花 - S01E02 - 第2集.mkv", tmdbID: 1},
+	}
+	for path, want := range expected {
+		suggestions := result[path]
+		if len(suggestions) != 1 {
+			t.Fatalf("%s: got %d suggestions, want 1", path, len(suggestions))
+		}
+		if suggestions[0].NewPath != want.newPath {
+			t.Errorf("%s: NewPath = %q, want %q", path, suggestions[0].NewPath, want.newPath)
+		}
+		if suggestions[0].TMDBID != want.tmdbID {
+			t.Errorf("%s: TMDBID = %d, want %d", path, suggestions[0].TMDBID, want.tmdbID)
+		}
+	}
+}
+
+// TestBatchSuggestTVNames_UnlabeledSeasonPack 测试没有季度范围标记的两季合集按集数累加分配到后续季度
+func TestBatchSuggestTVNames_UnlabeledSeasonPack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		switch r.URL.Path {
+		case "/search/tv":
+			body = tmdb.SearchTVResponse{Results: []tmdb.TVResult{
+				{ID: 1, Name: "鬼吹灯", OriginalName: "鬼吹灯", FirstAirDate: "2016-12-19"},
+			}}
+		case "/tv/1/season/1", "/tv/1/season/2":
+			season := int(r.URL.Path[len(r.URL.Path)-1] - '0')
+			body = tmdb.Season{SeasonNumber: season, EpisodeCount: 2, Episodes: []tmdb.Episode{
+				{EpisodeNumber: 1, SeasonNumber: season, Name: fmt.Sprintf("S%d第1集", season)},
+				{EpisodeNumber: 2, SeasonNumber: season, Name: fmt.Sprintf("S%d第2集", season)},
+			}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	client := tmdb.NewClient("test-key")
+	client.BaseURL = server.URL
+	rs := NewRenameSuggester(client, nil)
+
+	var paths []string
+	for ep := 1; ep <= 4; ep++ {
+		paths = append(paths, fmt.Sprintf("/data/tvs/鬼吹灯/鬼吹灯.E%02d.mkv", ep))
+	}
+	result, err := rs.BatchSuggestTVNames(context.Background(), paths)
+	if err != nil {
+		t.Fatalf("BatchSuggestTVNames() error = %v", err)
+	}
+
+	expected := map[string]string{
+		paths[0]: "/data/tvs/鬼吹灯/Season 01/鬼吹灯 - S01E01 - S1第1集.mkv",
+		paths[1]: "/data/tvs/鬼吹灯/Season 01/鬼吹灯 - S01E02 - S1第2集.mkv",
+		paths[2]: "/data/tvs/鬼吹灯/Season 02/鬼吹灯 - S02E01 - S2第1集.mkv",
+		paths[3]: "/data/tvs/鬼吹灯/Season 02/鬼吹灯 - S02E02 - S2第2集.mkv",
+	}
+	for path, want := range expected {
+		suggestions := result[path]
+		if len(suggestions) != 1 {
+			t.Fatalf("%s: got %d suggestions, want 1", path, len(suggestions))
+		}
+		if suggestions[0].NewPath != want {
+			t.Errorf("%s: NewPath = %q, want %q", path, suggestions[0].NewPath, want)
+		}
+	}
+}
+
+// TestBatchSuggestTVNames_PartialFailure 测试部分季度请求失败时区分请求失败与无匹配，并重试暂时性错误
+func TestBatchSuggestTVNames_PartialFailure(t *testing.T) {
+	var season3Calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		switch r.URL.Path {
+		case "/search/tv":
+			body = tmdb.SearchTVResponse{Results: []tmdb.TVResult{
+				{ID: 1, Name: "繁花", OriginalName: "繁花", FirstAirDate: "2023-12-27"},
+			}}
+		case "/tv/1/season/1":
+			body = tmdb.Season{SeasonNumber: 1, EpisodeCount: 1, Episodes: []tmdb.Episode{
+				{EpisodeNumber: 1, SeasonNumber: 1, Name: "第1集"},
+			}}
+		case "/tv/1/season/2":
+			// 非暂时性错误：不重试，文件标记为可重试
+			http.Error(w, "invalid api key", http.StatusUnauthorized)
+			return
+		case "/tv/1/season/3":
+			// 暂时性错误：第一次失败，重试后成功
+			if season3Calls.Add(1) == 1 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			body = tmdb.Season{SeasonNumber: 3, EpisodeCount: 1, Episodes: []tmdb.Episode{
+				{EpisodeNumber: 1, SeasonNumber: 3, Name: "第1集"},
+			}}
+		default:
+			// 季度不存在：真正的无匹配
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	client := tmdb.NewClient("test-key")
+	client.BaseURL = server.URL
+	rs := NewRenameSuggester(client, nil)
+	rs.retryDelay = 0
+
+	paths := []string{
+		"/data/tvs/繁花/Season 1/繁花.S01E01.mkv",
+		"/data/tvs/繁花/Season 2/繁花.S02E01.mkv",
+		"/data/tvs/繁花/Season 3/繁花.S03E01.mkv",
+		"/data/tvs/繁花/Season 4/繁花.S04E01.mkv",
+	}
+	result, err := rs.BatchSuggestTVNames(context.Background(), paths)
+	if err != nil {
+		t.Fatalf("BatchSuggestTVNames() error = %v", err)
+	}
+
+	if sug := result[paths[0]]; len(sug) != 1 || sug[0].Skipped {
+		t.Errorf("season 1: got %+v, want renamed suggestion", sug)
+	}
+	if sug := result[paths[1]]; len(sug) != 1 || !sug[0].Skipped || !sug[0].Retryable {
+		t.Errorf("season 2: got %+v, want retryable skipped suggestion", sug)
+	}
+	if sug := result[paths[2]]; len(sug) != 1 || sug[0].Skipped {
+		t.Errorf("season 3: got %+v, want renamed suggestion after retry", sug)
+	}
+	if got := season3Calls.Load(); got != 2 {
+		t.Errorf("season 3 requests = %d, want 2", got)
+	}
+	for _, sug := range result[paths[3]] {
+		if sug.Retryable {
+			t.Errorf("season 4: got retryable suggestion for missing season, want no-match")
+		}
+	}
+}
+
+// TestParseFileName_MovieYear 测试电影文件名的年份提取（分辨率标记不应被识别为年份）
+func TestParseFileName_MovieYear(t *testing.T) {
+	rs := &RenameSuggester{}
+
+	tests := []struct {
+		name     string
+		path     string
+		wantYear int
+	}{
+		{name: "点分隔年份", path: "/movies/Inception.2010.1080p.BluRay.x264.mkv", wantYear: 2010},
+		{name: "括号年份", path: "/movies/Parasite (2019).mkv", wantYear: 2019},
+		{name: "方括号年份", path: "/movies/[流浪地球][2019][4K].mp4", wantYear: 2019},
+		{name: "2160p不是年份", path: "/movies/Dune.Part.Two.2160p.WEB-DL.mkv", wantYear: 0},
+		{name: "没有年份", path: "/movies/让子弹飞.mkv", wantYear: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := rs.ParseFileName(tt.path)
+			if info.MediaType != tmdb.MediaTypeMovie {
+				t.Fatalf("MediaType = %v, want movie", info.MediaType)
+			}
+			if info.Year != tt.wantYear {
+				t.Errorf("Year = %d, want %d", info.Year, tt.wantYear)
+			}
+		})
+	}
+}
+
+// TestMovieConfidence 测试电影搜索结果的置信度：排序、标题和年份匹配
+func TestMovieConfidence(t *testing.T) {
+	rs := &RenameSuggester{}
+	result := tmdb.MovieResult{Title: "寄生虫", OriginalTitle: "기생충"}
+
+	tests := []struct {
+		name       string
+		index      int
+		query      string
+		infoYear   int
+		resultYear int
+		want       float64
+	}{
+		{name: "首个结果标题和年份一致", index: 0, query: "寄生虫", infoYear: 2019, resultYear: 2019, want: 1.3},
+		{name: "原始标题一致", index: 0, query: "기생충", infoYear: 0, resultYear: 2019, want: 1.1},
+		{name: "年份相差一年", index: 1, query: "Parasite", infoYear: 2020, resultYear: 2019, want: 1.0},
+		{name: "年份不一致", index: 0, query: "Parasite", infoYear: 2009, resultYear: 2019, want: 0.7},
+		{name: "文件名无年份", index: 2, query: "Parasite", infoYear: 0, resultYear: 2019, want: 0.8},
+		{name: "不低于0", index: 9, query: "Parasite", infoYear: 1990, resultYear: 2019, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rs.movieConfidence(tt.index, tt.query, result, tt.infoYear, tt.resultYear)
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("movieConfidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSuggestMovie 测试电影重命名：按年份匹配的结果排在前面，已符合 Emby 格式的文件跳过
+func TestSuggestMovie(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body any
+		switch r.URL.Path {
+		case "/search/movie":
+			body = tmdb.SearchMovieResponse{Results: []tmdb.MovieResult{
+				{ID: 1, Title: "Dune", ReleaseDate: "1984-12-14"},
+				{ID: 2, Title: "Dune", ReleaseDate: "2021-09-15"},
+			}}
+		case "/movie/1":
+			body = tmdb.MovieDetails{ID: 1, Title: "沙丘", OriginalTitle: "Dune", OriginalLanguage: "en"}
+		case "/movie/2":
+			body = tmdb.MovieDetails{ID: 2, Title: "沙丘", OriginalTitle: "Dune", OriginalLanguage: "en"}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	client := tmdb.NewClient("test-key")
+	client.BaseURL = server.URL
+	rs := NewRenameSuggester(client, nil)
+
+	suggestions, err := rs.SuggestMovie(context.Background(), "/movies/Dune.2021.1080p.BluRay.mkv")
+	if err != nil {
+		t.Fatalf("SuggestMovie() error = %v", err)
+	}
+	if len(suggestions) != 2 {
+		t.Fatalf("got %d suggestions, want 2", len(suggestions))
+	}
+	if suggestions[0].TMDBID != 2 || suggestions[0].NewPath != "/movies/沙丘 (2021).mkv" {
+		t.Errorf("first suggestion = %d %q, want 2 %q", suggestions[0].TMDBID, suggestions[0].NewPath, "/movies/沙丘 (2021).mkv")
+	}
+	if suggestions[0].Confidence <= suggestions[1].Confidence {
+		t.Errorf("suggestions not sorted by confidence: %v <= %v", suggestions[0].Confidence, suggestions[1].Confidence)
+	}
+
+	skipped, err := rs.SuggestMovie(context.Background(), "/movies/沙丘 (2021).mkv")
+	if err != nil {
+		t.Fatalf("SuggestMovie() error = %v", err)
+	}
+	if len(skipped) != 1 || !skipped[0].Skipped {
+		t.Errorf("SuggestMovie() on Emby movie name = %+v, want one skipped suggestion", skipped)
+	}
+}
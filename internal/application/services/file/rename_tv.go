@@ -2,12 +2,15 @@ package file
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/domain/models/rename"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/tmdb"
@@ -20,8 +23,26 @@ const (
 	skipReasonEmbyFormat      = "已符合 Emby 标准格式"
 	skipReasonSpecialContent  = "特殊内容（先导片/加更/花絮等），无法匹配标准剧集"
 	skipReasonEpisodeNotFound = "无法从文件名中识别剧集编号"
+	skipReasonTMDBError       = "TMDB 请求失败，可稍后重试"
+	skipReasonEpisodeRange    = "多集范围超出该季总集数，无法匹配"
 )
 
+const (
+	// tmdbRetryAttempts 批量重命名时TMDB暂时不可达（网络错误、5xx、限流）的最大尝试次数
+	tmdbRetryAttempts = 3
+	// defaultTMDBRetryDelay 首次重试前的等待时间，之后按次数递增
+	defaultTMDBRetryDelay = 500 * time.Millisecond
+)
+
+// errTVNotFound TMDB中没有匹配的剧集（真正的无匹配，区别于请求失败）
+var errTVNotFound = errors.New("TMDB数据库中未找到剧集")
+
+// defaultBatchRenameConcurrency 未配置时批量重命名并发处理的剧集数
+const defaultBatchRenameConcurrency = 4
+
+// parsedConfidence TMDB不可达时仅凭文件名解析生成建议的置信度（未经验证）
+const parsedConfidence = 0.3
+
 // suggestTVName 为TV剧集生成重命名建议
 func (rs *RenameSuggester) suggestTVName(ctx context.Context, fullPath string, info *MediaInfo) ([]rename.Suggestion, error) {
 	searchQuery := info.Title
@@ -40,6 +61,10 @@ func (rs *RenameSuggester) searchTVByQuery(ctx context.Context, fullPath string,
 	resp, err := rs.tmdbClient.SearchTV(ctx, query, info.Year)
 	if err != nil {
 		logger.Error("TMDB API call failed", "query", query, "error", err)
+		if tmdb.IsUnavailable(err) && info.Episode > 0 {
+			logger.Warn("TMDB unavailable, falling back to parsed suggestion", "path", fullPath)
+			return []rename.Suggestion{rs.buildParsedTVSuggestion(fullPath, query, info, info.Season, 0)}, nil
+		}
 		return nil, fmt.Errorf("TMDB搜索失败: %w", err)
 	}
 
@@ -50,6 +75,8 @@ func (rs *RenameSuggester) searchTVByQuery(ctx context.Context, fullPath string,
 	}
 
 	suggestions := make([]rename.Suggestion, 0, len(resp.Results))
+	tmdbUnavailable := false
+	rangeOutOfSeason := false
 	for i, result := range resp.Results {
 		// 检查 name 或 original_name 是否匹配（处理简繁体差异）
 		nameMatch := rs.matchOriginalName(query, result.Name)
@@ -69,6 +96,7 @@ func (rs *RenameSuggester) searchTVByQuery(ctx context.Context, fullPath string,
 		seasonDetails, err := rs.tmdbClient.GetSeasonDetails(ctx, result.ID, info.Season)
 		if err != nil {
 			logger.Warn("Failed to get season details", "tvID", result.ID, "name", result.Name, "season", info.Season, "error", err)
+			tmdbUnavailable = tmdbUnavailable || tmdb.IsUnavailable(err)
 			continue
 		}
 
@@ -80,10 +108,23 @@ func (rs *RenameSuggester) searchTVByQuery(ctx context.Context, fullPath string,
 			continue
 		}
 
-		sug := rs.buildTVSuggestion(fullPath, query, info, result.ID, year, matchedEpisode, seasonDetails.Episodes, confidence)
+		endEpisode, ok := rs.resolveEndEpisode(info, matchedEpisode, seasonDetails.EpisodeCount)
+		if !ok {
+			rangeOutOfSeason = true
+			continue
+		}
+		sug := rs.buildTVSuggestion(fullPath, query, info, result.ID, year, matchedEpisode, endEpisode, seasonDetails.Episodes, confidence)
 		suggestions = append(suggestions, sug)
 	}
 
+	if len(suggestions) == 0 && tmdbUnavailable && info.Episode > 0 {
+		logger.Warn("TMDB unavailable, falling back to parsed suggestion", "path", fullPath)
+		return []rename.Suggestion{rs.buildParsedTVSuggestion(fullPath, query, info, info.Season, 0)}, nil
+	}
+
+	if len(suggestions) == 0 && rangeOutOfSeason {
+		return nil, fmt.Errorf("多集范围 E%02d-E%02d 超出剧集 '%s' 第 %d 季的总集数", info.Episode, info.EndEpisode, query, info.Season)
+	}
 	if len(suggestions) == 0 {
 		return nil, fmt.Errorf("未找到包含第 %d 季的剧集 '%s'", info.Season, query)
 	}
@@ -134,16 +175,87 @@ func (rs *RenameSuggester) BatchSuggestTVNames(ctx context.Context, paths []stri
 	// 预解析需要处理的文件
 	pathInfoMap := rs.parseAllPaths(pathsToProcess)
 
-	// 提取剧集名
-	showName := rs.extractShowNameFromPaths(pathsToProcess, pathInfoMap)
-	if showName == "" {
+	// 按剧集分组：混合目录中可能包含多部不同的剧集，每部剧集独立搜索TMDB
+	showGroups := rs.groupPathsByShow(pathsToProcess, pathInfoMap)
+	if len(showGroups) == 0 {
 		return nil, fmt.Errorf("无法从路径中提取节目名称")
 	}
 
-	logger.Info("Batch rename: extracted show name", "showName", showName, "referencePath", pathsToProcess[0])
+	showNames := make([]string, 0, len(showGroups))
+	for showName := range showGroups {
+		showNames = append(showNames, showName)
+	}
+	sort.Strings(showNames)
+
+	concurrency := min(rs.batchConcurrency(), len(showNames))
+	logger.Info("Batch rename: extracted show names",
+		"shows", showNames,
+		"concurrency", concurrency,
+		"referencePath", pathsToProcess[0])
+
+	// 有界并发处理各剧集，结果在锁保护下合并
+	var (
+		unavailableErr error
+		mu             sync.Mutex
+		wg             sync.WaitGroup
+		sem            = make(chan struct{}, concurrency)
+	)
+	for _, showName := range showNames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(showName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			showResults, showUnavailableErr := rs.batchSuggestShow(ctx, showName, showGroups[showName], pathInfoMap)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for path, suggestions := range showResults {
+				result[path] = append(result[path], suggestions...)
+			}
+			if showUnavailableErr != nil {
+				unavailableErr = showUnavailableErr
+			}
+		}(showName)
+	}
+	wg.Wait()
+
+	// 检查是否有任何非跳过的结果，并统计因TMDB请求失败跳过的文件
+	hasNonSkippedResult := false
+	errorSkipped := 0
+	for _, suggestions := range result {
+		for _, sug := range suggestions {
+			if !sug.Skipped {
+				hasNonSkippedResult = true
+			} else if sug.Retryable {
+				errorSkipped++
+			}
+		}
+	}
+	if errorSkipped > 0 {
+		logger.Warn("Batch rename: some files skipped due to TMDB errors", "errorSkipped", errorSkipped, "totalFiles", len(paths))
+	}
+
+	// 如果没有非跳过的结果，且原始请求中有需要处理的文件，则返回错误
+	// 有请求失败的文件时返回结果，让调用方展示可重试的文件
+	if !hasNonSkippedResult && errorSkipped == 0 && len(pathsToProcess) > 0 {
+		if unavailableErr != nil {
+			return nil, fmt.Errorf("TMDB is unreachable and no episode could be parsed for '%s': %w", strings.Join(showNames, ", "), unavailableErr)
+		}
+		return nil, fmt.Errorf("TV series '%s' not found in TMDB database", strings.Join(showNames, ", "))
+	}
 
+	return result, nil
+}
+
+// batchSuggestShow 为单部剧集生成建议：按版本和父目录分组后批量搜索TMDB
+// TMDB不可达时返回仅凭文件名解析的建议，并通过第二个返回值报告不可达错误
+func (rs *RenameSuggester) batchSuggestShow(ctx context.Context, showName string, paths []string, pathInfoMap map[string]*MediaInfo) (map[string][]rename.Suggestion, error) {
 	// 按版本分组（仅处理未标准化的文件）
-	pathsByVersion := rs.groupPathsByVersion(pathsToProcess, pathInfoMap)
+	pathsByVersion := rs.groupPathsByVersion(paths, pathInfoMap)
+	result := make(map[string][]rename.Suggestion)
+	var unavailableErr error
 
 	for version, versionPaths := range pathsByVersion {
 		searchQuery := showName
@@ -192,7 +304,22 @@ func (rs *RenameSuggester) BatchSuggestTVNames(ctx context.Context, paths []stri
 			versionResults, err := rs.batchSearchTVByQuery(ctx, searchQuery, seasonMap, pathInfoMap, seasonRangeDetected, startSeason, endSeason)
 			if err != nil {
 				logger.Warn("Batch rename: search failed", "query", searchQuery, "parentDir", parentDir, "error", err)
-				continue
+				if errors.Is(err, errTVNotFound) {
+					continue
+				}
+				if !tmdb.IsUnavailable(err) {
+					// 请求失败（非无匹配）：标记为可重试，而不是静默丢弃
+					for _, path := range dirPaths {
+						result[path] = []rename.Suggestion{rs.buildErrorSkippedSuggestion(path)}
+					}
+					continue
+				}
+				// TMDB不可达：仅凭文件名解析结果生成未验证的建议
+				unavailableErr = err
+				versionResults = make(map[string][]rename.Suggestion)
+				for season, seasonPaths := range seasonMap {
+					rs.fillParsedTVSuggestions(searchQuery, season, 0, seasonPaths, pathInfoMap, versionResults)
+				}
 			}
 
 			for path, suggestions := range versionResults {
@@ -201,26 +328,7 @@ func (rs *RenameSuggester) BatchSuggestTVNames(ctx context.Context, paths []stri
 		}
 	}
 
-	// 检查是否有任何非跳过的结果
-	hasNonSkippedResult := false
-	for _, suggestions := range result {
-		for _, sug := range suggestions {
-			if !sug.Skipped {
-				hasNonSkippedResult = true
-				break
-			}
-		}
-		if hasNonSkippedResult {
-			break
-		}
-	}
-
-	// 如果没有非跳过的结果，且原始请求中有需要处理的文件，则返回错误
-	if !hasNonSkippedResult && len(pathsToProcess) > 0 {
-		return nil, fmt.Errorf("TV series '%s' not found in TMDB database", showName)
-	}
-
-	return result, nil
+	return result, unavailableErr
 }
 
 // batchSearchTVByQuery 批量搜索TV剧集
@@ -244,7 +352,7 @@ func (rs *RenameSuggester) batchSearchTVByQuery(
 		"seasonRangeDetected", seasonRangeDetected,
 		"seasonRange", fmt.Sprintf("%d-%d", startSeason, endSeason))
 
-	resp, err := rs.tmdbClient.SearchTV(ctx, query, 0)
+	resp, err := rs.searchTVWithRetry(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("TMDB搜索失败: %w", err)
 	}
@@ -276,6 +384,8 @@ func (rs *RenameSuggester) batchSearchTVByQuery(
 	}
 
 	result := make(map[string][]rename.Suggestion)
+	// 获取季度详情失败（重试后仍失败且非无匹配）的文件，没有从其他候选剧集得到建议时标记为可重试
+	failed := make(map[string]error)
 
 	for _, tvResult := range resp.Results {
 		// 检查 name 或 original_name 是否匹配
@@ -312,10 +422,10 @@ func (rs *RenameSuggester) batchSearchTVByQuery(
 
 		// 如果检测到季度范围,使用智能分配模式
 		if seasonRangeDetected && startSeason > 0 && endSeason > 0 {
-			successCount = rs.handleSeasonRange(ctx, tvResult.ID, query, year, startSeason, endSeason, seasonMap, pathInfoMap, &result)
+			successCount = rs.handleSeasonRange(ctx, tvResult.ID, query, year, startSeason, endSeason, seasonMap, pathInfoMap, &result, failed)
 		} else {
 			// 原有逻辑:按现有seasonMap处理
-			successCount = rs.handleRegularSeasons(ctx, tvResult.ID, query, year, seasonMap, pathInfoMap, &result)
+			successCount = rs.handleRegularSeasons(ctx, tvResult.ID, query, year, seasonMap, pathInfoMap, &result, failed)
 		}
 
 		if successCount > 0 {
@@ -323,6 +433,13 @@ func (rs *RenameSuggester) batchSearchTVByQuery(
 		}
 	}
 
+	for path, err := range failed {
+		if _, exists := result[path]; !exists {
+			logger.Warn("Batch rename: file skipped due to TMDB error", "path", path, "error", err)
+			result[path] = []rename.Suggestion{rs.buildErrorSkippedSuggestion(path)}
+		}
+	}
+
 	logger.Info("Batch search completed", "query", query, "matchedFiles", len(result), "totalInputFiles", totalFiles)
 	return result, nil
 }
@@ -336,16 +453,40 @@ func (rs *RenameSuggester) handleRegularSeasons(
 	seasonMap map[int][]string,
 	pathInfoMap map[string]*MediaInfo,
 	result *map[string][]rename.Suggestion,
+	failed map[string]error,
 ) int {
 	successCount := 0
 
 	for season, seasonPaths := range seasonMap {
-		seasonDetails, err := rs.tmdbClient.GetSeasonDetails(ctx, tvID, season)
+		seasonDetails, err := rs.getSeasonDetailsWithRetry(ctx, tvID, season)
 		if err != nil {
 			logger.Warn("Failed to get season details", "tvID", tvID, "query", query, "season", season, "error", err)
+			switch {
+			case tmdb.IsUnavailable(err):
+				successCount += rs.fillParsedTVSuggestions(query, season, year, seasonPaths, pathInfoMap, *result)
+			case !tmdb.IsNotFound(err):
+				for _, path := range seasonPaths {
+					failed[path] = err
+				}
+			}
 			continue
 		}
 
+		// 无季度范围标记的合集：集数超出本季总集数时，探测后续季度并按集数累加分配
+		if maxEpisode := maxFileEpisode(seasonPaths, pathInfoMap); len(seasonDetails.Episodes) > 0 && maxEpisode > len(seasonDetails.Episodes) {
+			seasons := rs.probeFollowingSeasons(ctx, tvID, season, seasonDetails.Episodes, maxEpisode)
+			if len(seasons) > 1 {
+				logger.Info("Season pack heuristic activated",
+					"query", query,
+					"season", season,
+					"seasonEpisodeCount", len(seasonDetails.Episodes),
+					"maxFileEpisode", maxEpisode,
+					"probedSeasons", fmt.Sprintf("%d-%d", seasons[0].season, seasons[len(seasons)-1].season))
+				successCount += rs.assignEpisodesAcrossSeasons(tvID, query, year, seasons, seasonPaths, pathInfoMap, result)
+				continue
+			}
+		}
+
 		episodeMap := rs.buildEpisodeMap(seasonDetails.Episodes)
 		logger.Info("Got season details", "query", query, "season", season, "episodeCount", len(episodeMap))
 
@@ -354,7 +495,12 @@ func (rs *RenameSuggester) handleRegularSeasons(
 			matchedEpisode, _ := rs.matchEpisodeByAirDate(info, seasonDetails.Episodes, "Batch rename: ")
 
 			if episode, exists := episodeMap[matchedEpisode]; exists {
-				sug := rs.buildBatchTVSuggestion(path, query, info, tvID, year, season, matchedEpisode, episode.Name)
+				endEpisode, ok := rs.resolveEndEpisode(info, matchedEpisode, len(seasonDetails.Episodes))
+				if !ok {
+					(*result)[path] = []rename.Suggestion{rs.BuildSkippedSuggestion(path, skipReasonEpisodeRange)}
+					continue
+				}
+				sug := rs.buildBatchTVSuggestion(path, query, info, tvID, year, season, matchedEpisode, endEpisode, episode.Name)
 				(*result)[path] = append((*result)[path], sug)
 				successCount++
 			} else {
@@ -377,6 +523,7 @@ func (rs *RenameSuggester) handleSeasonRange(
 	seasonMap map[int][]string,
 	pathInfoMap map[string]*MediaInfo,
 	result *map[string][]rename.Suggestion,
+	failed map[string]error,
 ) int {
 	// 收集所有文件并按集数排序
 	var allPaths []string
@@ -403,23 +550,24 @@ func (rs *RenameSuggester) handleSeasonRange(
 		"lastEpisode", pathInfoMap[allPaths[len(allPaths)-1]].Episode)
 
 	// 获取所有季度的数据
-	type seasonInfo struct {
-		season       int
-		episodeCount int
-		episodes     []tmdb.Episode
-	}
-
-	var seasons []seasonInfo
+	var seasons []tvSeasonData
 	totalEpisodes := 0
 
 	for s := startSeason; s <= endSeason; s++ {
-		seasonDetails, err := rs.tmdbClient.GetSeasonDetails(ctx, tvID, s)
+		seasonDetails, err := rs.getSeasonDetailsWithRetry(ctx, tvID, s)
 		if err != nil {
 			logger.Warn("Failed to get season details", "tvID", tvID, "season", s, "error", err)
-			continue
+			if tmdb.IsNotFound(err) {
+				continue
+			}
+			// 缺少中间季度会使后续季度的集数偏移全部错位，整个范围标记为可重试
+			for _, path := range allPaths {
+				failed[path] = err
+			}
+			return 0
 		}
 
-		info := seasonInfo{
+		info := tvSeasonData{
 			season:       s,
 			episodeCount: len(seasonDetails.Episodes),
 			episodes:     seasonDetails.Episodes,
@@ -442,7 +590,64 @@ func (rs *RenameSuggester) handleSeasonRange(
 		"totalEpisodes", totalEpisodes,
 		"totalFiles", len(allPaths))
 
-	// 智能分配:根据集数累加确定每个文件属于哪一季
+	successCount := rs.assignEpisodesAcrossSeasons(tvID, query, year, seasons, allPaths, pathInfoMap, result)
+
+	logger.Info("Season range processing completed",
+		"successCount", successCount,
+		"totalFiles", len(allPaths),
+		"failedCount", len(allPaths)-successCount)
+
+	return successCount
+}
+
+// tvSeasonData 多季智能分配使用的单季数据
+type tvSeasonData struct {
+	season       int
+	episodeCount int
+	episodes     []tmdb.Episode
+}
+
+// maxSeasonPackProbe 无范围标记的合集最多向后探测的季度数
+const maxSeasonPackProbe = 5
+
+// maxFileEpisode 返回文件中最大的集数（多集文件取最后一集）
+func maxFileEpisode(paths []string, pathInfoMap map[string]*MediaInfo) int {
+	maxEpisode := 0
+	for _, path := range paths {
+		info := pathInfoMap[path]
+		maxEpisode = max(maxEpisode, info.Episode, info.EndEpisode)
+	}
+	return maxEpisode
+}
+
+// probeFollowingSeasons 从当前季度开始依次获取后续季度，直到累计集数覆盖 maxEpisode
+// 后续季度不存在或探测次数达到上限时停止，返回的第一项始终是当前季度
+func (rs *RenameSuggester) probeFollowingSeasons(ctx context.Context, tvID, season int, episodes []tmdb.Episode, maxEpisode int) []tvSeasonData {
+	seasons := []tvSeasonData{{season: season, episodeCount: len(episodes), episodes: episodes}}
+	totalEpisodes := len(episodes)
+
+	for next := season + 1; totalEpisodes < maxEpisode && next <= season+maxSeasonPackProbe; next++ {
+		seasonDetails, err := rs.getSeasonDetailsWithRetry(ctx, tvID, next)
+		if err != nil || len(seasonDetails.Episodes) == 0 {
+			logger.Debug("Stopped probing following seasons", "tvID", tvID, "season", next, "error", err)
+			break
+		}
+		seasons = append(seasons, tvSeasonData{season: next, episodeCount: len(seasonDetails.Episodes), episodes: seasonDetails.Episodes})
+		totalEpisodes += len(seasonDetails.Episodes)
+	}
+	return seasons
+}
+
+// assignEpisodesAcrossSeasons 智能分配:根据集数累加确定每个文件属于哪一季，未匹配的文件添加跳过建议
+func (rs *RenameSuggester) assignEpisodesAcrossSeasons(
+	tvID int,
+	query string,
+	year int,
+	seasons []tvSeasonData,
+	allPaths []string,
+	pathInfoMap map[string]*MediaInfo,
+	result *map[string][]rename.Suggestion,
+) int {
 	successCount := 0
 	episodeOffset := 0
 
@@ -460,7 +665,12 @@ func (rs *RenameSuggester) handleSeasonRange(
 				seasonEpisode := fileEpisode - episodeOffset
 
 				if episode, exists := episodeMap[seasonEpisode]; exists {
-					sug := rs.buildBatchTVSuggestion(path, query, info, tvID, year, si.season, seasonEpisode, episode.Name)
+					endEpisode, ok := rs.resolveEndEpisode(info, seasonEpisode, si.episodeCount)
+					if !ok {
+						(*result)[path] = []rename.Suggestion{rs.BuildSkippedSuggestion(path, skipReasonEpisodeRange)}
+						continue
+					}
+					sug := rs.buildBatchTVSuggestion(path, query, info, tvID, year, si.season, seasonEpisode, endEpisode, episode.Name)
 					(*result)[path] = append((*result)[path], sug)
 					successCount++
 					seasonMatchCount++
@@ -489,11 +699,6 @@ func (rs *RenameSuggester) handleSeasonRange(
 		episodeOffset += si.episodeCount
 	}
 
-	logger.Info("Season range processing completed",
-		"successCount", successCount,
-		"totalFiles", len(allPaths),
-		"failedCount", len(allPaths)-successCount)
-
 	// 为未匹配的文件添加跳过建议
 	for _, path := range allPaths {
 		if _, exists := (*result)[path]; !exists {
@@ -607,6 +812,39 @@ func (rs *RenameSuggester) extractShowNameFromPaths(paths []string, pathInfoMap
 	return showName
 }
 
+// groupPathsByShow 按剧集名分组
+// 剧集名优先从路径目录中提取；无法从路径提取的文件归为一组，沿用首个TV文件推断剧集名
+func (rs *RenameSuggester) groupPathsByShow(paths []string, pathInfoMap map[string]*MediaInfo) map[string][]string {
+	showGroups := make(map[string][]string)
+	var unnamedPaths []string
+	for _, path := range paths {
+		showName, _ := rs.getPathInfo(pathInfoMap[path], path)
+		if showName == "" {
+			unnamedPaths = append(unnamedPaths, path)
+			continue
+		}
+		showGroups[showName] = append(showGroups[showName], path)
+	}
+
+	if len(unnamedPaths) > 0 {
+		showName := rs.extractShowNameFromPaths(unnamedPaths, pathInfoMap)
+		if showName == "" {
+			logger.Warn("Batch rename: unable to extract show name, skipping files", "fileCount", len(unnamedPaths), "firstPath", unnamedPaths[0])
+		} else {
+			showGroups[showName] = append(showGroups[showName], unnamedPaths...)
+		}
+	}
+	return showGroups
+}
+
+// batchConcurrency 批量重命名时并发处理的剧集数
+func (rs *RenameSuggester) batchConcurrency() int {
+	if rs.concurrency > 0 {
+		return rs.concurrency
+	}
+	return defaultBatchRenameConcurrency
+}
+
 // groupPathsByVersion 按版本分组
 func (rs *RenameSuggester) groupPathsByVersion(paths []string, pathInfoMap map[string]*MediaInfo) map[string][]string {
 	pathsByVersion := make(map[string][]string)
@@ -670,24 +908,63 @@ func (rs *RenameSuggester) logSeasonDistribution(searchQuery string, seasonMap m
 func (rs *RenameSuggester) retrySearchWithoutYear(ctx context.Context, query string) (*tmdb.SearchTVResponse, error) {
 	yearRegex := regexp.MustCompile(`\s+\d{4}$`)
 	if !yearRegex.MatchString(query) {
-		return nil, fmt.Errorf("TMDB数据库中未找到剧集 '%s'", query)
+		return nil, fmt.Errorf("%w '%s'", errTVNotFound, query)
 	}
 
 	showNameWithoutYear := yearRegex.ReplaceAllString(query, "")
 	logger.Info("Retry search without year", "originalQuery", query, "newQuery", showNameWithoutYear)
 
-	resp, err := rs.tmdbClient.SearchTV(ctx, showNameWithoutYear, 0)
+	resp, err := rs.searchTVWithRetry(ctx, showNameWithoutYear)
 	if err != nil {
 		return nil, fmt.Errorf("TMDB搜索失败: %w", err)
 	}
 
 	if len(resp.Results) == 0 {
-		return nil, fmt.Errorf("TMDB数据库中未找到剧集 '%s'", showNameWithoutYear)
+		return nil, fmt.Errorf("%w '%s'", errTVNotFound, showNameWithoutYear)
 	}
 
 	return resp, nil
 }
 
+// searchTVWithRetry 搜索TV剧集，TMDB暂时不可达时重试
+func (rs *RenameSuggester) searchTVWithRetry(ctx context.Context, query string) (*tmdb.SearchTVResponse, error) {
+	var resp *tmdb.SearchTVResponse
+	err := rs.retryTMDB(ctx, func() error {
+		var err error
+		resp, err = rs.tmdbClient.SearchTV(ctx, query, 0)
+		return err
+	})
+	return resp, err
+}
+
+// getSeasonDetailsWithRetry 获取季度详情，TMDB暂时不可达时重试
+func (rs *RenameSuggester) getSeasonDetailsWithRetry(ctx context.Context, tvID, season int) (*tmdb.Season, error) {
+	var details *tmdb.Season
+	err := rs.retryTMDB(ctx, func() error {
+		var err error
+		details, err = rs.tmdbClient.GetSeasonDetails(ctx, tvID, season)
+		return err
+	})
+	return details, err
+}
+
+// retryTMDB 执行TMDB请求，仅对不可达错误按递增间隔重试，其他错误直接返回
+func (rs *RenameSuggester) retryTMDB(ctx context.Context, call func() error) error {
+	var err error
+	for attempt := 1; attempt <= tmdbRetryAttempts; attempt++ {
+		if err = call(); err == nil || !tmdb.IsUnavailable(err) || attempt == tmdbRetryAttempts {
+			return err
+		}
+		logger.Debug("TMDB request failed, retrying", "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * rs.retryDelay):
+		}
+	}
+	return err
+}
+
 // buildEpisodeMap 构建集数映射
 func (rs *RenameSuggester) buildEpisodeMap(episodes []tmdb.Episode) map[int]*tmdb.Episode {
 	episodeMap := make(map[int]*tmdb.Episode)
@@ -698,14 +975,39 @@ func (rs *RenameSuggester) buildEpisodeMap(episodes []tmdb.Episode) map[int]*tmd
 	return episodeMap
 }
 
+// resolveEndEpisode 计算多集文件在季内的最后一集，单集文件返回0
+// startEpisode 为匹配后的起始集（可能经过播出日期或季度范围换算），跨度保持与文件名一致
+// 范围超出该季总集数时返回 false，调用方应将文件报告为未匹配，而不是按单集重命名
+func (rs *RenameSuggester) resolveEndEpisode(info *MediaInfo, startEpisode, episodeCount int) (int, bool) {
+	if info.EndEpisode <= info.Episode {
+		return 0, true
+	}
+	endEpisode := startEpisode + info.EndEpisode - info.Episode
+	if episodeCount > 0 && endEpisode > episodeCount {
+		logger.Warn("Multi-episode range out of season range",
+			"file", info.OriginalName, "startEpisode", startEpisode, "endEpisode", endEpisode, "episodeCount", episodeCount)
+		return 0, false
+	}
+	return endEpisode, true
+}
+
+// formatEpisodeTag 格式化 Emby 季集标记：S01E01，多集为 S01E01-E03
+func formatEpisodeTag(season, episode, endEpisode int) string {
+	if endEpisode > episode {
+		return fmt.Sprintf("S%02dE%02d-E%02d", season, episode, endEpisode)
+	}
+	return fmt.Sprintf("S%02dE%02d", season, episode)
+}
+
 // buildTVSuggestion 构建TV建议
-func (rs *RenameSuggester) buildTVSuggestion(fullPath, query string, info *MediaInfo, tmdbID, year, matchedEpisode int, episodes []tmdb.Episode, confidence float64) rename.Suggestion {
+func (rs *RenameSuggester) buildTVSuggestion(fullPath, query string, info *MediaInfo, tmdbID, year, matchedEpisode, endEpisode int, episodes []tmdb.Episode, confidence float64) rename.Suggestion {
+	// 多集文件不附加单集标题
 	var episodeName string
-	if len(episodes) > 0 && matchedEpisode > 0 && matchedEpisode <= len(episodes) {
+	if endEpisode == 0 && len(episodes) > 0 && matchedEpisode > 0 && matchedEpisode <= len(episodes) {
 		episodeName = episodes[matchedEpisode-1].Name
 	}
 
-	newName := fmt.Sprintf("%s - S%02dE%02d", query, info.Season, matchedEpisode)
+	newName := fmt.Sprintf("%s - %s", query, formatEpisodeTag(info.Season, matchedEpisode, endEpisode))
 	if episodeName != "" {
 		newName += fmt.Sprintf(" - %s", episodeName)
 	}
@@ -731,9 +1033,10 @@ func (rs *RenameSuggester) buildTVSuggestion(fullPath, query string, info *Media
 }
 
 // buildBatchTVSuggestion 构建批量TV建议
-func (rs *RenameSuggester) buildBatchTVSuggestion(path, query string, info *MediaInfo, tmdbID, year, season, matchedEpisode int, episodeName string) rename.Suggestion {
-	newName := fmt.Sprintf("%s - S%02dE%02d", query, season, matchedEpisode)
-	if episodeName != "" {
+func (rs *RenameSuggester) buildBatchTVSuggestion(path, query string, info *MediaInfo, tmdbID, year, season, matchedEpisode, endEpisode int, episodeName string) rename.Suggestion {
+	newName := fmt.Sprintf("%s - %s", query, formatEpisodeTag(season, matchedEpisode, endEpisode))
+	// 多集文件不附加单集标题
+	if episodeName != "" && endEpisode == 0 {
 		newName += fmt.Sprintf(" - %s", episodeName)
 	}
 	newName += info.Extension
@@ -757,6 +1060,57 @@ func (rs *RenameSuggester) buildBatchTVSuggestion(path, query string, info *Medi
 	return sug
 }
 
+// fillParsedTVSuggestions TMDB不可达时为一组文件生成未验证的建议，返回生成的建议数
+// season 为0时使用各文件解析出的季度，无法识别集数的文件标记为跳过
+func (rs *RenameSuggester) fillParsedTVSuggestions(query string, season, year int, paths []string, pathInfoMap map[string]*MediaInfo, result map[string][]rename.Suggestion) int {
+	count := 0
+	for _, path := range paths {
+		info := pathInfoMap[path]
+		if info == nil || info.Episode <= 0 {
+			result[path] = []rename.Suggestion{rs.BuildSkippedSuggestion(path, skipReasonEpisodeNotFound)}
+			continue
+		}
+		result[path] = []rename.Suggestion{rs.buildParsedTVSuggestion(path, query, info, season, year)}
+		count++
+	}
+	return count
+}
+
+// buildParsedTVSuggestion 仅根据文件名解析结果构建TV建议（未经TMDB验证，低置信度）
+func (rs *RenameSuggester) buildParsedTVSuggestion(path, query string, info *MediaInfo, season, year int) rename.Suggestion {
+	if season <= 0 {
+		season = info.Season
+	}
+	if season <= 0 {
+		season = 1
+	}
+	if year == 0 {
+		year = info.Year
+	}
+	endEpisode := 0
+	if info.EndEpisode > info.Episode {
+		endEpisode = info.EndEpisode
+	}
+
+	newName := fmt.Sprintf("%s - %s%s", query, formatEpisodeTag(season, info.Episode, endEpisode), info.Extension)
+	newPath := rs.buildEmbyPath(path, query, year, season, newName)
+
+	logger.Info("Generated parsed rename suggestion (TMDB unavailable)", "originalPath", path, "newName", newName, "newPath", newPath)
+
+	sug := rename.Suggestion{
+		NewName:    newName,
+		NewPath:    newPath,
+		MediaType:  rename.FromTMDBMediaType(tmdb.MediaTypeTV),
+		Title:      query,
+		Year:       year,
+		Confidence: parsedConfidence,
+		Source:     rename.SourceParsed,
+	}
+	sug.SetSeason(season)
+	sug.SetEpisode(info.Episode)
+	return sug
+}
+
 // matchEpisodeByAirDate 根据播出日期匹配集数
 func (rs *RenameSuggester) matchEpisodeByAirDate(info *MediaInfo, episodes []tmdb.Episode, logPrefix string) (int, string) {
 	if info.AirDate == "" {
@@ -0,0 +1,49 @@
+package notification
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// emailSubjectPrefix 邮件标题前缀
+const emailSubjectPrefix = "[alist-aria2]"
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// sendEmail 同步发送邮件通知，message 为 Telegram 风格的 HTML 片段
+func (s *AppNotificationService) sendEmail(title, message string) error {
+	if s.emailClient == nil {
+		return fmt.Errorf("email client not configured")
+	}
+
+	textBody, htmlBody := buildEmailBodies(title, message)
+	return s.emailClient.Send(fmt.Sprintf("%s %s", emailSubjectPrefix, title), textBody, htmlBody)
+}
+
+// sendEmailAsync 异步发送邮件通知，失败只记录日志，不影响 Telegram 发送
+func (s *AppNotificationService) sendEmailAsync(title, message string) {
+	if s.emailClient == nil {
+		return
+	}
+
+	go func() {
+		if err := s.sendEmail(title, message); err != nil {
+			logger.Warn("Failed to send email notification", "title", title, "error", err)
+		}
+	}()
+}
+
+// buildEmailBodies 将 Telegram HTML 消息转换为邮件的纯文本和HTML正文
+func buildEmailBodies(title, message string) (textBody, htmlBody string) {
+	textBody = html.UnescapeString(htmlTagPattern.ReplaceAllString(message, ""))
+	htmlBody = fmt.Sprintf(
+		"<html><body style=\"font-family: sans-serif; font-size: 14px;\">\n<h3>%s</h3>\n%s\n</body></html>",
+		html.EscapeString(title),
+		strings.ReplaceAll(message, "\n", "<br>\n"),
+	)
+	return textBody, htmlBody
+}
@@ -3,10 +3,12 @@ package notification
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/email"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/telegram"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 )
@@ -15,6 +17,7 @@ import (
 type AppNotificationService struct {
 	config         *config.Config
 	telegramClient *telegram.Client
+	emailClient    *email.Client // 可选，未启用时为nil
 }
 
 // NewAppNotificationService 创建应用通知服务
@@ -27,6 +30,7 @@ func NewAppNotificationService(cfg *config.Config) contracts.NotificationService
 	return &AppNotificationService{
 		config:         cfg,
 		telegramClient: telegramClient,
+		emailClient:    newEmailClient(cfg),
 	}
 }
 
@@ -35,16 +39,25 @@ func NewAppNotificationServiceWithClient(cfg *config.Config, client *telegram.Cl
 	return &AppNotificationService{
 		config:         cfg,
 		telegramClient: client,
+		emailClient:    newEmailClient(cfg),
 	}
 }
 
+// newEmailClient 按配置创建邮件客户端，未启用时返回nil
+func newEmailClient(cfg *config.Config) *email.Client {
+	if !cfg.Email.Enabled {
+		return nil
+	}
+	return email.NewClient(&cfg.Email)
+}
+
 func (s *AppNotificationService) SetTelegramClient(client *telegram.Client) {
 	s.telegramClient = client
 }
 
 // SendNotification 发送通知
 func (s *AppNotificationService) SendNotification(ctx context.Context, req contracts.NotificationRequest) (*contracts.NotificationResponse, error) {
-	if s.telegramClient == nil {
+	if req.Channel == contracts.ChannelTelegram && s.telegramClient == nil {
 		return nil, fmt.Errorf("telegram client not available")
 	}
 
@@ -82,6 +95,8 @@ func (s *AppNotificationService) SendNotification(ctx context.Context, req contr
 			// 发送给所有授权用户
 			err = s.sendToAllTelegramUsers(message)
 		}
+	case contracts.ChannelEmail:
+		err = s.sendEmail(req.Title, req.Message)
 	default:
 		err = fmt.Errorf("unsupported notification channel: %s", req.Channel)
 	}
@@ -159,10 +174,6 @@ func (s *AppNotificationService) SendBatchNotifications(ctx context.Context, req
 
 // NotifyDownloadComplete 下载完成通知
 func (s *AppNotificationService) NotifyDownloadComplete(ctx context.Context, req contracts.DownloadNotificationRequest) error {
-	if !s.config.Telegram.Enabled {
-		return nil // 静默跳过
-	}
-
 	sizeStr := formatFileSize(req.FileSize)
 	durationStr := req.Duration.String()
 
@@ -180,6 +191,12 @@ func (s *AppNotificationService) NotifyDownloadComplete(ctx context.Context, req
 		req.DownloadID,
 	)
 
+	s.sendEmailAsync("下载完成", message)
+
+	if !s.config.Telegram.Enabled {
+		return nil // 静默跳过
+	}
+
 	notificationReq := contracts.NotificationRequest{
 		Channel: contracts.ChannelTelegram,
 		Level:   contracts.NotificationLevelSuccess,
@@ -193,10 +210,6 @@ func (s *AppNotificationService) NotifyDownloadComplete(ctx context.Context, req
 
 // NotifyDownloadFailed 下载失败通知
 func (s *AppNotificationService) NotifyDownloadFailed(ctx context.Context, req contracts.DownloadNotificationRequest) error {
-	if !s.config.Telegram.Enabled {
-		return nil // 静默跳过
-	}
-
 	message := fmt.Sprintf(
 		"<b>❌ 下载失败</b>\n\n"+
 			"<b>文件:</b> <code>%s</code>\n"+
@@ -207,6 +220,12 @@ func (s *AppNotificationService) NotifyDownloadFailed(ctx context.Context, req c
 		escapeHTML(req.ErrorMessage),
 	)
 
+	s.sendEmailAsync("下载失败", message)
+
+	if !s.config.Telegram.Enabled {
+		return nil // 静默跳过
+	}
+
 	notificationReq := contracts.NotificationRequest{
 		Channel: contracts.ChannelTelegram,
 		Level:   contracts.NotificationLevelError,
@@ -220,34 +239,40 @@ func (s *AppNotificationService) NotifyDownloadFailed(ctx context.Context, req c
 
 // NotifyTaskComplete 任务完成通知
 func (s *AppNotificationService) NotifyTaskComplete(ctx context.Context, req contracts.TaskNotificationRequest) error {
-	if !s.config.Telegram.Enabled {
-		return nil // 静默跳过
-	}
-
 	sizeStr := formatFileSize(req.TotalSize)
 	durationStr := req.Duration.String()
 
-	message := fmt.Sprintf(
-		"<b>✅ 定时任务完成</b>\n\n"+
-			"<b>任务:</b> <code>%s</code>\n"+
-			"<b>类型:</b> %s\n"+
-			"<b>文件数:</b> %d 个\n"+
-			"<b>总大小:</b> %s\n"+
-			"<b>用时:</b> %s\n"+
-			"<b>任务ID:</b> <code>%s</code>",
-		escapeHTML(req.TaskName),
-		req.TaskType,
-		req.FilesCount,
-		sizeStr,
-		durationStr,
-		req.TaskID,
-	)
+	message := req.Message
+	if message == "" {
+		message = fmt.Sprintf(
+			"<b>✅ 定时任务完成</b>\n\n"+
+				"<b>任务:</b> <code>%s</code>\n"+
+				"<b>类型:</b> %s\n"+
+				"<b>文件数:</b> %d 个\n"+
+				"<b>总大小:</b> %s\n"+
+				"<b>用时:</b> %s\n"+
+				"<b>任务ID:</b> <code>%s</code>",
+			escapeHTML(req.TaskName),
+			req.TaskType,
+			req.FilesCount,
+			sizeStr,
+			durationStr,
+			req.TaskID,
+		)
+	}
+
+	s.sendEmailAsync("任务完成", message)
+
+	if !s.config.Telegram.Enabled {
+		return nil // 静默跳过
+	}
 
 	notificationReq := contracts.NotificationRequest{
-		Channel: contracts.ChannelTelegram,
-		Level:   contracts.NotificationLevelSuccess,
-		Title:   "任务完成",
-		Message: message,
+		Channel:  contracts.ChannelTelegram,
+		Level:    contracts.NotificationLevelSuccess,
+		Title:    "任务完成",
+		Message:  message,
+		TargetID: req.TargetID,
 	}
 
 	_, err := s.SendNotification(ctx, notificationReq)
@@ -256,10 +281,6 @@ func (s *AppNotificationService) NotifyTaskComplete(ctx context.Context, req con
 
 // NotifyTaskFailed 任务失败通知
 func (s *AppNotificationService) NotifyTaskFailed(ctx context.Context, req contracts.TaskNotificationRequest) error {
-	if !s.config.Telegram.Enabled {
-		return nil // 静默跳过
-	}
-
 	message := fmt.Sprintf(
 		"<b>❌ 定时任务失败</b>\n\n"+
 			"<b>任务:</b> <code>%s</code>\n"+
@@ -272,11 +293,18 @@ func (s *AppNotificationService) NotifyTaskFailed(ctx context.Context, req contr
 		escapeHTML(req.ErrorMessage),
 	)
 
+	s.sendEmailAsync("任务失败", message)
+
+	if !s.config.Telegram.Enabled {
+		return nil // 静默跳过
+	}
+
 	notificationReq := contracts.NotificationRequest{
-		Channel: contracts.ChannelTelegram,
-		Level:   contracts.NotificationLevelError,
-		Title:   "任务失败",
-		Message: message,
+		Channel:  contracts.ChannelTelegram,
+		Level:    contracts.NotificationLevelError,
+		Title:    "任务失败",
+		Message:  message,
+		TargetID: req.TargetID,
 	}
 
 	_, err := s.SendNotification(ctx, notificationReq)
@@ -373,6 +401,7 @@ func (s *AppNotificationService) GetConfig(ctx context.Context) (*contracts.Noti
 		MinLevel:       contracts.NotificationLevelInfo,
 		Channels: map[contracts.NotificationChannel]bool{
 			contracts.ChannelTelegram: s.config.Telegram.Enabled,
+			contracts.ChannelEmail:    s.emailClient != nil,
 		},
 		RateLimit:     60, // 每分钟60条
 		RetryLimit:    3,
@@ -395,6 +424,11 @@ func (s *AppNotificationService) CheckChannelHealth(ctx context.Context, channel
 		}
 		// 简化实现：假设健康
 		return nil
+	case contracts.ChannelEmail:
+		if s.emailClient == nil {
+			return fmt.Errorf("email notification not enabled")
+		}
+		return nil
 	default:
 		return fmt.Errorf("unsupported channel: %s", channel)
 	}
@@ -458,8 +492,11 @@ func parseInt64(s string) int64 {
 	if s == "" {
 		return 0
 	}
-	// 简化实现
-	return 0
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
 }
 
 // formatFileSize 格式化文件大小
@@ -2,6 +2,7 @@ package path
 
 import (
 	"strings"
+	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	mediaservices "github.com/easayliu/alist-aria2-download/internal/domain/services/media"
@@ -37,30 +38,117 @@ func NewPathGenerationService(
 // GenerateDownloadPath 生成下载路径
 func (s *PathGenerationService) GenerateDownloadPath(file contracts.FileResponse) string {
 	// 如果启用了路径策略服务，使用新的统一路径生成
-	if s.pathStrategy != nil {
-		baseDir := s.config.Aria2.DownloadDir
-		if baseDir == "" {
-			baseDir = "/downloads"
-		}
+	baseDir := s.resolveBaseDir(file)
 
+	// 音乐和文档放入各自配置的目录，不使用视频的路径模板
+	if categoryDir := s.extensionCategoryDir(file.Name, baseDir); categoryDir != "" {
+		return categoryDir
+	}
+
+	if s.pathStrategy != nil {
 		generatedPath, err := s.pathStrategy.GenerateDownloadPath(file, baseDir)
 		if err != nil {
-			return s.generateDownloadPathLegacy(file)
+			return s.generateDownloadPathLegacy(file, baseDir)
 		}
 
 		return generatedPath
 	}
 
 	// 未启用路径策略服务时，使用旧逻辑
-	return s.generateDownloadPathLegacy(file)
+	return s.generateDownloadPathLegacy(file, baseDir)
 }
 
-// generateDownloadPathLegacy 旧的路径生成逻辑（保留作为回退）
-func (s *PathGenerationService) generateDownloadPathLegacy(file contracts.FileResponse) string {
+// PreviewDownloadPath 预览下载路径及其来源说明，不做冲突检测、不创建目录（用于规则测试）
+func (s *PathGenerationService) PreviewDownloadPath(file contracts.FileResponse) (string, string) {
+	baseDir := s.resolveBaseDir(file)
+
+	if categoryDir := s.extensionCategoryDir(file.Name, baseDir); categoryDir != "" {
+		return categoryDir, "音乐/文档分类目录"
+	}
+
+	if s.pathStrategy != nil {
+		if generatedPath, category, err := s.pathStrategy.PreviewDownloadPath(file, baseDir); err == nil {
+			return generatedPath, "路径模板 (" + category + ")"
+		}
+	}
+
+	return s.generateDownloadPathLegacy(file, baseDir), "默认目录规则"
+}
+
+// resolveBaseDir 选择下载根目录，旧内容在启用归档时使用归档路径
+func (s *PathGenerationService) resolveBaseDir(file contracts.FileResponse) string {
+	alistCfg := s.config.Alist
+	if shouldArchive(file.Modified, alistCfg.ArchiveAfterDays, time.Now()) && alistCfg.ArchiveDownloadPath != "" {
+		logger.Debug("Routing download to archive path", "file", file.Name, "modified", file.Modified, "archivePath", alistCfg.ArchiveDownloadPath)
+		return alistCfg.ArchiveDownloadPath
+	}
+
 	baseDir := s.config.Aria2.DownloadDir
 	if baseDir == "" {
 		baseDir = "/downloads"
 	}
+	return baseDir
+}
+
+// extensionCategoryDir 音乐/文档分类的下载目录，相对路径基于下载根目录；非这两类返回空字符串
+func (s *PathGenerationService) extensionCategoryDir(filename, baseDir string) string {
+	var dir string
+	switch s.mediaClassifier.ExtensionCategory(filename) {
+	case "music":
+		dir = s.config.Download.Music.Path
+	case "document":
+		dir = s.config.Download.Documents.Path
+	default:
+		return ""
+	}
+
+	if strings.HasPrefix(dir, "/") {
+		return pathutil.JoinPath(dir)
+	}
+	return pathutil.JoinPath(baseDir, dir)
+}
+
+// shouldArchive 文件修改时间是否早于归档阈值（修改时间未知时不归档）
+func shouldArchive(modified time.Time, archiveAfterDays int, now time.Time) bool {
+	if archiveAfterDays <= 0 || modified.IsZero() {
+		return false
+	}
+	return modified.Before(now.AddDate(0, 0, -archiveAfterDays))
+}
+
+// legacyCategoryDirs 旧路径生成逻辑中各分类对应的目录名
+var legacyCategoryDirs = map[string]string{
+	"tv":      "tvs",
+	"movie":   "movies",
+	"variety": "variety",
+	"video":   "videos",
+}
+
+// otherCategoryDir 无法分类的文件所在的目录名
+const otherCategoryDir = "others"
+
+// CategoryForDirName 根据自动分类生成的一级目录名（tvs、movies 等，不区分大小写）返回分类，不是分类目录时返回空
+func CategoryForDirName(name string) string {
+	name = strings.ToLower(name)
+	if name == otherCategoryDir {
+		return "other"
+	}
+	for category, dir := range legacyCategoryDirs {
+		if dir == name {
+			return category
+		}
+	}
+	return ""
+}
+
+// generateDownloadPathLegacy 旧的路径生成逻辑（保留作为回退）
+func (s *PathGenerationService) generateDownloadPathLegacy(file contracts.FileResponse, baseDir string) string {
+	// 用户纠正过分类的文件按纠正后的分类放入对应目录
+	if override, ok := s.mediaClassifier.OverrideFor(file.Name); ok {
+		if categoryDir, exists := legacyCategoryDirs[override.Category]; exists {
+			return pathutil.JoinPath(baseDir, categoryDir, override.Title)
+		}
+	}
 
 	pathCategory := s.pathCategory.GetCategoryFromPath(file.Path)
 	if pathCategory != "" {
@@ -70,7 +158,7 @@ func (s *PathGenerationService) generateDownloadPathLegacy(file contracts.FileRe
 		}
 	}
 
-	return pathutil.JoinPath(baseDir, "others")
+	return pathutil.JoinPath(baseDir, otherCategoryDir)
 }
 
 // extractPathStructure 从原始路径中提取并保留目录结构
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	mediaservices "github.com/easayliu/alist-aria2-download/internal/domain/services/media"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/platform"
@@ -19,13 +20,14 @@ type PathStrategyService struct {
 	fileService      contracts.FileService
 	pathValidator    *filesystem.PathValidatorService
 	directoryMgr     *filesystem.DirectoryManager
-	varExtractor     *utils.VariableExtractor     // 变量提取器
-	templateRenderer *utils.TemplateRenderer      // 模板渲染器
-	conflictDetector *filesystem.ConflictDetector // 冲突检测器
-	mappingEngine    *PathMappingEngine           // 映射规则引擎（可选）
-	pathAdapter      *platform.PathAdapter        // 跨平台路径适配器
-	useTemplateMode  bool                         // 是否启用模板模式
-	useMappingMode   bool                         // 是否启用映射规则模式
+	varExtractor     *utils.VariableExtractor        // 变量提取器
+	templateRenderer *utils.TemplateRenderer         // 模板渲染器
+	conflictDetector *filesystem.ConflictDetector    // 冲突检测器
+	mappingEngine    *PathMappingEngine              // 映射规则引擎（可选）
+	pathAdapter      *platform.PathAdapter           // 跨平台路径适配器
+	useTemplateMode  bool                            // 是否启用模板模式
+	useMappingMode   bool                            // 是否启用映射规则模式
+	overrides        mediaservices.CategoryOverrides // 用户纠正的分类（可选）
 }
 
 // NewPathStrategyService 创建路径策略服务
@@ -62,6 +64,36 @@ func NewPathStrategyService(
 	}
 }
 
+// SetOverrides 设置用户纠正的分类，模板模式下优先于路径识别的分类
+func (s *PathStrategyService) SetOverrides(overrides mediaservices.CategoryOverrides) {
+	s.overrides = overrides
+}
+
+// extractVariables 提取模板变量，文件名匹配到分类覆盖时替换分类并补全标题变量
+func (s *PathStrategyService) extractVariables(file contracts.FileResponse, baseDir string) map[string]string {
+	vars := s.varExtractor.ExtractVariables(file, baseDir)
+	if s.overrides == nil {
+		return vars
+	}
+
+	override, ok := s.overrides.Match(file.Name)
+	if !ok {
+		return vars
+	}
+	vars["category"] = override.Category
+	switch override.Category {
+	case "tv", "variety":
+		if vars["show"] == "" {
+			vars["show"] = override.Title
+		}
+	case "movie":
+		if vars["title"] == "" {
+			vars["title"] = override.Title
+		}
+	}
+	return vars
+}
+
 // GenerateDownloadPath 生成下载路径（主入口）
 func (s *PathStrategyService) GenerateDownloadPath(
 	file contracts.FileResponse,
@@ -90,33 +122,16 @@ func (s *PathStrategyService) GenerateDownloadPath(
 	}
 
 	if downloadPath == "" {
-		if s.useTemplateMode {
-			// 模板模式：使用变量和模板渲染
-			vars := s.varExtractor.ExtractVariables(file, baseDir)
-			category := vars["category"]
-			downloadPath = s.templateRenderer.RenderByCategory(category, vars)
-
-			logger.Debug("Path rendered from template",
-				"category", category,
-				"path", downloadPath)
-		} else {
-			return "", fmt.Errorf("PathStrategyService requires template mode to be enabled")
+		// 模板模式：使用变量和模板渲染
+		path, _, err := s.renderTemplate(file, baseDir)
+		if err != nil {
+			return "", err
 		}
+		downloadPath = path
 	}
 
-	// 1.5 使用PathAdapter规范化路径（跨平台处理）
-	downloadPath = s.pathAdapter.NormalizePath(downloadPath)
-
-	// 2. 路径验证和清理
-	cleanPath, err := s.pathValidator.ValidateAndClean(downloadPath)
-	if err != nil {
-		logger.Warn("Path validation failed, using fallback",
-			"original", downloadPath,
-			"error", err)
-
-		// 回退到基础目录
-		cleanPath = filepath.Join(baseDir, "others")
-	}
+	// 1.5 规范化、验证和清理路径
+	cleanPath := s.cleanDownloadPath(downloadPath, baseDir)
 
 	// 3. 冲突检测和处理
 	if s.conflictDetector != nil {
@@ -133,7 +148,7 @@ func (s *PathStrategyService) GenerateDownloadPath(
 		// 检查路径冲突
 		mediaType := "other"
 		if s.useTemplateMode {
-			vars := s.varExtractor.ExtractVariables(file, baseDir)
+			vars := s.extractVariables(file, baseDir)
 			mediaType = vars["category"]
 		}
 
@@ -165,6 +180,45 @@ func (s *PathStrategyService) GenerateDownloadPath(
 	return cleanPath, nil
 }
 
+// PreviewDownloadPath 预览模板生成的下载路径及使用的分类，不做冲突检测、不创建目录（用于规则测试）
+func (s *PathStrategyService) PreviewDownloadPath(file contracts.FileResponse, baseDir string) (string, string, error) {
+	downloadPath, category, err := s.renderTemplate(file, baseDir)
+	if err != nil {
+		return "", "", err
+	}
+	return s.cleanDownloadPath(downloadPath, baseDir), category, nil
+}
+
+// renderTemplate 按文件分类渲染路径模板，返回路径和使用的分类
+func (s *PathStrategyService) renderTemplate(file contracts.FileResponse, baseDir string) (string, string, error) {
+	if !s.useTemplateMode {
+		return "", "", fmt.Errorf("PathStrategyService requires template mode to be enabled")
+	}
+
+	vars := s.extractVariables(file, baseDir)
+	category := vars["category"]
+	downloadPath := s.templateRenderer.RenderByCategory(category, vars)
+
+	logger.Debug("Path rendered from template",
+		"category", category,
+		"path", downloadPath)
+	return downloadPath, category, nil
+}
+
+// cleanDownloadPath 使用PathAdapter规范化路径（跨平台处理）并验证清理，验证失败时回退到基础目录
+func (s *PathStrategyService) cleanDownloadPath(downloadPath, baseDir string) string {
+	downloadPath = s.pathAdapter.NormalizePath(downloadPath)
+
+	cleanPath, err := s.pathValidator.ValidateAndClean(downloadPath)
+	if err != nil {
+		logger.Warn("Path validation failed, using fallback",
+			"original", downloadPath,
+			"error", err)
+		return filepath.Join(baseDir, otherCategoryDir)
+	}
+	return cleanPath
+}
+
 // PrepareDownloadDirectory 准备下载目录（用于批量下载前的预检）
 func (s *PathStrategyService) PrepareDownloadDirectory(
 	baseDir string,
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/application/services/download"
@@ -9,6 +11,7 @@ import (
 	"github.com/easayliu/alist-aria2-download/internal/application/services/llm"
 	"github.com/easayliu/alist-aria2-download/internal/application/services/notification"
 	"github.com/easayliu/alist-aria2-download/internal/application/services/task"
+	mediaservices "github.com/easayliu/alist-aria2-download/internal/domain/services/media"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
@@ -48,8 +51,8 @@ func NewNotificationService(cfg *config.Config) *notification.AppNotificationSer
 	return &notification.AppNotificationService{}
 }
 
-func NewSchedulerService(taskRepo *repository.TaskRepository, fileService contracts.FileService, notificationService contracts.NotificationService, downloadService contracts.DownloadService) *task.SchedulerService {
-	return task.NewSchedulerService(taskRepo, fileService, notificationService, downloadService)
+func NewSchedulerService(taskRepo *repository.TaskRepository, runRepo *repository.TaskRunRepository, fileService contracts.FileService, notificationService contracts.NotificationService, downloadService contracts.DownloadService, location *time.Location) *task.SchedulerService {
+	return task.NewSchedulerService(taskRepo, runRepo, fileService, notificationService, downloadService, location)
 }
 
 // ServiceContainer 应用服务容器 - 实现依赖注入
@@ -66,7 +69,15 @@ type ServiceContainer struct {
 
 	// 基础设施服务（非contracts）
 	taskRepo       *repository.TaskRepository
-	telegramClient interface{} // 单例 Telegram Client
+	pinRepo        *repository.PinRepository                 // 目录收藏
+	presetRepo     *repository.PresetRepository              // 下载预设
+	historyRepo    *repository.DownloadHistoryRepository     // 下载历史
+	batchRepo      *repository.DownloadBatchRepository       // 批量下载记录
+	taskRunRepo    *repository.TaskRunRepository             // 定时任务运行记录
+	overrideRepo   *repository.CategoryOverrideRepository    // 用户纠正的分类
+	snapshotRepo   *repository.SnapshotRepository            // 目录快照
+	checkpointRepo *repository.DirectoryCheckpointRepository // 目录下载断点
+	telegramClient interface{}                               // 单例 Telegram Client
 }
 
 // NewServiceContainer 创建服务容器
@@ -83,6 +94,54 @@ func NewServiceContainer(cfg *config.Config) (*ServiceContainer, error) {
 	}
 	container.taskRepo = taskRepo
 
+	pinRepo, err := repository.NewPinRepository(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pin repository: %w", err)
+	}
+	container.pinRepo = pinRepo
+
+	presetRepo, err := repository.NewPresetRepository(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create preset repository: %w", err)
+	}
+	container.presetRepo = presetRepo
+
+	historyRepo, err := repository.NewDownloadHistoryRepository(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download history repository: %w", err)
+	}
+	container.historyRepo = historyRepo
+
+	batchRepo, err := repository.NewDownloadBatchRepository(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download batch repository: %w", err)
+	}
+	container.batchRepo = batchRepo
+
+	taskRunRepo, err := repository.NewTaskRunRepository(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create task run repository: %w", err)
+	}
+	container.taskRunRepo = taskRunRepo
+
+	overrideRepo, err := repository.NewCategoryOverrideRepository(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create category override repository: %w", err)
+	}
+	container.overrideRepo = overrideRepo
+
+	snapshotRepo, err := repository.NewSnapshotRepository(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot repository: %w", err)
+	}
+	container.snapshotRepo = snapshotRepo
+
+	checkpointRepo, err := repository.NewDirectoryCheckpointRepository(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory checkpoint repository: %w", err)
+	}
+	container.checkpointRepo = checkpointRepo
+
 	// 2. 初始化应用服务 - 注意依赖顺序
 	// 先初始化不依赖其他服务的服务
 	container.notificationService = notification.NewAppNotificationServiceWithClient(cfg, nil)
@@ -106,21 +165,89 @@ func NewServiceContainer(cfg *config.Config) (*ServiceContainer, error) {
 	// 创建FileService，注入LLM服务
 	container.fileService = file.NewAppFileService(cfg, container.llmService, nil)
 	container.downloadService = download.NewAppDownloadService(cfg, container.fileService)
+	container.downloadService.StartBandwidthSampling()
+	// 启动时校验 aria2 连接和 RPC 密钥：密钥错误直接失败，连接失败只告警（健康检查会自动重连）
+	if err := container.downloadService.VerifyAria2(); err != nil {
+		if errors.Is(err, contracts.ErrAria2Unauthorized) {
+			return nil, fmt.Errorf("aria2 拒绝了 RPC 密钥，请检查 aria2.token 是否与 aria2 的 --rpc-secret 一致: %w", err)
+		}
+		logger.Warn("Aria2 not reachable at startup, will keep retrying", "rpc_url", cfg.Aria2.RpcURL, "error", err)
+	} else {
+		logger.Info("Aria2 connection and RPC secret verified", "rpc_url", cfg.Aria2.RpcURL)
+	}
+	container.downloadService.StartHealthCheck()
+	container.downloadService.StartCategoryLimits()
+
+	// 自定义分类规则（classification 配置），文件分类和视频识别优先使用
+	classificationRules := mediaservices.NewClassificationRules(cfg.Classification, cfg.Download.VideoExts)
+	if rules := cfg.Classification; len(rules.ExtraVideoExts)+len(rules.OtherExts)+len(rules.TVKeywords)+len(rules.MovieKeywords) > 0 {
+		logger.Info("Custom classification rules loaded",
+			"extra_video_extensions", len(rules.ExtraVideoExts),
+			"other_extensions", len(rules.OtherExts),
+			"tv_keywords", len(rules.TVKeywords),
+			"movie_keywords", len(rules.MovieKeywords))
+	}
 
 	// 更新fileService的downloadService依赖
 	// 注意：由于字段私有，需要添加setter方法
 	if appFileService, ok := container.fileService.(*file.AppFileService); ok {
 		appFileService.SetDownloadService(container.downloadService)
+		appFileService.SetCategoryOverrides(container.overrideRepo)
+		appFileService.SetDownloadHistory(container.historyRepo)
+		appFileService.SetSnapshots(container.snapshotRepo)
+		appFileService.SetDirectoryCheckpoints(container.checkpointRepo)
+		appFileService.SetClassificationRules(classificationRules)
+	}
+
+	if appDownloadService, ok := container.downloadService.(*download.AppDownloadService); ok {
+		appDownloadService.SetDownloadHistory(container.historyRepo)
+		appDownloadService.SetDownloadBatches(container.batchRepo)
+		appDownloadService.SetDirectoryCheckpoints(container.checkpointRepo)
+		appDownloadService.SetNotificationService(container.notificationService)
+		appDownloadService.SetClassificationRules(classificationRules)
+	}
+
+	// 记录每个源文件的下载历史（完成时记录所在分类，用于 /stats 统计）
+	historyRecorder := download.NewHistoryRecorder(container.historyRepo)
+	if appDownloadService, ok := container.downloadService.(*download.AppDownloadService); ok {
+		historyRecorder.SetCategoryResolver(appDownloadService.CategoryForDirectory)
+	}
+	container.downloadService.AddEventListener(historyRecorder.HandleEvent)
+
+	// 下载完成后删除源文件（需在配置中显式开启）
+	if cfg.Download.AllowDeleteAfterDownload {
+		cleanup := download.NewSourceCleanup(cfg, container.fileService, container.notificationService)
+		container.downloadService.AddEventListener(cleanup.HandleEvent)
+		logger.Warn("Delete after download is enabled, source files will be removed from Alist after verified downloads")
+	}
+
+	// 开启 Archive 的批次全部完成后打包为 zip 文件（依赖下载历史，需注册在 HistoryRecorder 之后）
+	archiver := download.NewBatchArchiver(cfg, container.batchRepo, container.historyRepo, container.downloadService, container.notificationService)
+	container.downloadService.AddEventListener(archiver.HandleEvent)
+
+	// 磁盘写满导致的下载失败给出明确提醒，可用空间低于阈值时自动暂停全部下载
+	diskGuard := download.NewDiskSpaceGuard(cfg, container.downloadService, container.notificationService)
+	container.downloadService.AddEventListener(diskGuard.HandleEvent)
+	diskGuard.Start()
+
+	// 电影/剧集下载完成后生成 .nfo 元数据（需在配置中显式开启）
+	if cfg.Alist.GenerateNFO {
+		container.downloadService.AddEventListener(download.NewNFOWriter(container.fileService).HandleEvent)
 	}
 
 	// 3. 初始化TaskService和SchedulerService
 	// 创建SchedulerService
 	container.schedulerService = task.NewSchedulerService(
 		container.taskRepo,
+		container.taskRunRepo,
 		container.fileService,
 		container.notificationService,
 		container.downloadService,
+		cfg.Scheduler.Location(),
 	)
+	container.schedulerService.SetMaxConsecutiveFailures(cfg.Scheduler.MaxConsecutiveFailures)
+	// 定时任务创建的下载出错时记入任务的失败文件
+	container.downloadService.AddEventListener(container.schedulerService.HandleEvent)
 
 	// 创建TaskService
 	container.taskService = task.NewAppTaskService(
@@ -131,6 +258,14 @@ func NewServiceContainer(cfg *config.Config) (*ServiceContainer, error) {
 		container.fileService,
 	)
 
+	// 注册内部清理任务
+	if cfg.Scheduler.Cleanup.Enabled {
+		cleanupJob := task.NewCleanupJob(cfg.Scheduler.Cleanup, container.downloadService)
+		if err := container.schedulerService.AddSystemJob("cleanup", cfg.Scheduler.Cleanup.Cron, cleanupJob.Run); err != nil {
+			logger.Warn("Failed to register cleanup job", "error", err)
+		}
+	}
+
 	// 启动调度器
 	if err := container.schedulerService.Start(); err != nil {
 		return nil, fmt.Errorf("failed to start scheduler: %w", err)
@@ -159,11 +294,19 @@ func (c *ServiceContainer) GetNotificationService() contracts.NotificationServic
 	return c.notificationService
 }
 
-// GetHealthStatus 获取系统健康状态 - 临时实现
+// GetHealthStatus 获取系统健康状态（aria2 不可用时为 degraded）
 func (c *ServiceContainer) GetHealthStatus() *contracts.SystemHealth {
+	aria2Health := c.downloadService.GetAria2Health()
+	status := contracts.HealthStatusHealthy
+	if aria2Health.Status == contracts.HealthStatusUnhealthy {
+		status = contracts.HealthStatusDegraded
+	}
+
 	return &contracts.SystemHealth{
-		Status:     contracts.HealthStatusHealthy,
-		Components: []contracts.ComponentHealth{},
+		Status:     status,
+		Components: []contracts.ComponentHealth{aria2Health},
+		Timestamp:  time.Now(),
+		ReadOnly:   c.config.Server.ReadOnly,
 	}
 }
 
@@ -177,6 +320,26 @@ func (c *ServiceContainer) GetSchedulerService() *task.SchedulerService {
 	return c.schedulerService
 }
 
+// GetPinRepository 获取目录收藏存储
+func (c *ServiceContainer) GetPinRepository() *repository.PinRepository {
+	return c.pinRepo
+}
+
+// GetPresetRepository 获取下载预设存储
+func (c *ServiceContainer) GetPresetRepository() *repository.PresetRepository {
+	return c.presetRepo
+}
+
+// GetDownloadHistoryRepository 获取下载历史存储
+func (c *ServiceContainer) GetDownloadHistoryRepository() *repository.DownloadHistoryRepository {
+	return c.historyRepo
+}
+
+// GetCategoryOverrideRepository 获取分类覆盖存储
+func (c *ServiceContainer) GetCategoryOverrideRepository() *repository.CategoryOverrideRepository {
+	return c.overrideRepo
+}
+
 func (c *ServiceContainer) GetTelegramClient() interface{} {
 	return c.telegramClient
 }
@@ -189,3 +352,15 @@ func (c *ServiceContainer) SetTelegramClient(client interface{}) {
 func (c *ServiceContainer) GetLLMService() contracts.LLMService {
 	return c.llmService
 }
+
+// Shutdown 停止后台任务（分批提交等）并写入未保存的下载历史，进程退出前调用
+func (c *ServiceContainer) Shutdown() {
+	if appDownloadService, ok := c.downloadService.(*download.AppDownloadService); ok {
+		appDownloadService.Shutdown()
+	}
+	if c.historyRepo != nil {
+		if err := c.historyRepo.Flush(); err != nil {
+			logger.Warn("Failed to save download history", "error", err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// CleanupJob 定期清理任务 - 移除过期的 aria2 结束任务记录
+type CleanupJob struct {
+	config          config.CleanupConfig
+	downloadService contracts.DownloadService
+
+	mu        sync.Mutex
+	firstSeen map[string]time.Time // gid -> 首次发现已结束的时间（aria2 不提供结束时间）
+}
+
+// NewCleanupJob 创建清理任务
+func NewCleanupJob(cfg config.CleanupConfig, downloadService contracts.DownloadService) *CleanupJob {
+	return &CleanupJob{
+		config:          cfg,
+		downloadService: downloadService,
+		firstSeen:       make(map[string]time.Time),
+	}
+}
+
+// Run 执行一次清理
+func (j *CleanupJob) Run() {
+	ctx := context.Background()
+	removedResults := j.purgeDownloadResults(ctx)
+	logger.Info("Cleanup job finished", "removed_results", removedResults)
+}
+
+// purgeDownloadResults 移除结束时间超过阈值的 aria2 任务记录
+// 结束时间以本任务首次观察到为准，重启后会重新计时，只会更保守
+func (j *CleanupJob) purgeDownloadResults(ctx context.Context) int {
+	if j.downloadService == nil || j.config.ResultMaxAgeHours <= 0 {
+		return 0
+	}
+
+	// 不限制数量：列表按 下载中 → 等待中 → 已停止 合并分页，限制数量会漏掉排在后面的已停止任务
+	resp, err := j.downloadService.ListDownloads(ctx, contracts.DownloadListRequest{})
+	if err != nil {
+		logger.Warn("Cleanup job failed to list downloads", "error", err)
+		return 0
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	maxAge := time.Duration(j.config.ResultMaxAgeHours) * time.Hour
+	current := make(map[string]bool)
+	removed := 0
+
+	for _, d := range resp.Downloads {
+		if !isStoppedStatus(d.Status) {
+			continue
+		}
+		current[d.ID] = true
+
+		seenAt, exists := j.firstSeen[d.ID]
+		if !exists {
+			j.firstSeen[d.ID] = now
+			continue
+		}
+		if now.Sub(seenAt) < maxAge {
+			continue
+		}
+
+		if err := j.downloadService.RemoveDownloadResult(ctx, d.ID); err != nil {
+			logger.Warn("Failed to remove download result", "gid", d.ID, "error", err)
+			continue
+		}
+		logger.Info("Removed download result", "gid", d.ID, "filename", d.Filename, "status", d.Status)
+		delete(j.firstSeen, d.ID)
+		delete(current, d.ID)
+		removed++
+	}
+
+	// 清理已不存在的记录
+	for gid := range j.firstSeen {
+		if !current[gid] {
+			delete(j.firstSeen, gid)
+		}
+	}
+
+	return removed
+}
+
+// isStoppedStatus 是否为已结束状态
+func isStoppedStatus(status valueobjects.DownloadStatus) bool {
+	switch status {
+	case valueobjects.DownloadStatusComplete, valueobjects.DownloadStatusError, valueobjects.DownloadStatusRemoved:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,44 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// cronParser 任务和系统任务共用的解析规则：标准5字段格式，可选前置秒字段（6字段自动识别），
+// 并支持 @daily/@every 等描述符和 CRON_TZ= 前缀
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// parseCron 解析 cron 表达式（秒 分 时 日 月 周 或 分 时 日 月 周）
+func parseCron(spec string) (cron.Schedule, error) {
+	return cronParser.Parse(strings.TrimSpace(spec))
+}
+
+// LoadTimezone 解析任务时区（IANA 名称，如 Asia/Shanghai），空字符串返回 nil 表示使用调度器时区
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("未知时区 %q，请使用 IANA 时区名称，例如 Asia/Shanghai、America/New_York、UTC", name)
+	}
+	return location, nil
+}
+
+// zonedSchedule 在指定时区计算下次运行时间，调度器本身的时区不受影响
+type zonedSchedule struct {
+	cron.Schedule
+	location *time.Location
+}
+
+// Next 返回 t 之后的下一次运行时间（按任务时区计算）
+func (s zonedSchedule) Next(t time.Time) time.Time {
+	return s.Schedule.Next(t.In(s.location))
+}
@@ -3,6 +3,8 @@ package task
 import (
 	"context"
 	"fmt"
+	"html"
+	"strconv"
 	"sync"
 	"time"
 
@@ -19,23 +21,44 @@ type SchedulerService struct {
 	fileService     contracts.FileService
 	notificationSvc contracts.NotificationService
 	downloadService contracts.DownloadService
+	runRepo         *repository.TaskRunRepository         // 运行记录，可为nil
+	location        *time.Location                        // cron 触发和按天统计使用的时区
+	maxFailures     int                                   // 连续失败达到该次数后自动停用任务，0 表示不停用
+	summaryFormat   func(contracts.TaskRunSummary) string // 下载汇总通知的格式化函数，为nil时使用通知服务的默认格式
 	jobs            map[string]cron.EntryID
 	mu              sync.RWMutex
 	running         bool
 }
 
-func NewSchedulerService(taskRepo *repository.TaskRepository, fileService contracts.FileService, notificationSvc contracts.NotificationService, downloadService contracts.DownloadService) *SchedulerService {
+func NewSchedulerService(taskRepo *repository.TaskRepository, runRepo *repository.TaskRunRepository, fileService contracts.FileService, notificationSvc contracts.NotificationService, downloadService contracts.DownloadService, location *time.Location) *SchedulerService {
+	if location == nil {
+		location = time.Local
+	}
 	return &SchedulerService{
-		cron:            cron.New(), // 使用标准5字段格式（分 时 日 月 周）
+		cron:            cron.New(cron.WithLocation(location), cron.WithParser(cronParser)), // 分 时 日 月 周，可选前置秒字段
 		taskRepo:        taskRepo,
 		fileService:     fileService,
 		notificationSvc: notificationSvc,
 		downloadService: downloadService,
+		runRepo:         runRepo,
+		location:        location,
 		jobs:            make(map[string]cron.EntryID),
 		running:         false,
 	}
 }
 
+// SetMaxConsecutiveFailures 设置自动停用任务的连续失败次数，0 表示不自动停用
+func (s *SchedulerService) SetMaxConsecutiveFailures(n int) {
+	s.maxFailures = n
+}
+
+// SetRunSummaryFormatter 设置任务运行下载汇总通知的格式化函数（由 Telegram 层提供）
+func (s *SchedulerService) SetRunSummaryFormatter(format func(contracts.TaskRunSummary) string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summaryFormat = format
+}
+
 // Start 启动调度器
 func (s *SchedulerService) Start() error {
 	s.mu.Lock()
@@ -81,9 +104,12 @@ func (s *SchedulerService) Stop() {
 
 // CreateTask 创建新任务
 func (s *SchedulerService) CreateTask(task *entities.ScheduledTask) error {
-	// 验证cron表达式
-	if _, err := cron.ParseStandard(task.Cron); err != nil {
-		return fmt.Errorf("invalid cron expression: %w", err)
+	// 验证cron表达式或时间窗口
+	if _, err := taskSchedule(task); err != nil {
+		return err
+	}
+	if err := task.ValidateSizeRange(); err != nil {
+		return err
 	}
 
 	// 保存任务
@@ -108,9 +134,15 @@ func (s *SchedulerService) CreateTask(task *entities.ScheduledTask) error {
 
 // UpdateTask 更新任务
 func (s *SchedulerService) UpdateTask(task *entities.ScheduledTask) error {
-	// 验证cron表达式
-	if _, err := cron.ParseStandard(task.Cron); err != nil {
-		return fmt.Errorf("invalid cron expression: %w", err)
+	// 验证cron表达式或时间窗口
+	if _, err := taskSchedule(task); err != nil {
+		return err
+	}
+
+	// 重新启用时清除连续失败状态
+	if task.Enabled {
+		task.ConsecutiveFailures = 0
+		task.AutoDisabledAt = nil
 	}
 
 	// 更新任务
@@ -174,6 +206,41 @@ func (s *SchedulerService) GetUserTasks(userID int64) ([]*entities.ScheduledTask
 	return s.taskRepo.GetByUserID(userID)
 }
 
+// AddSystemJob 注册内部系统任务（如清理任务），不持久化、不对用户展示
+func (s *SchedulerService) AddSystemJob(name, spec string, job func()) error {
+	if _, err := parseCron(spec); err != nil {
+		return fmt.Errorf("invalid cron expression for %s: %w", name, err)
+	}
+
+	if _, err := s.cron.AddFunc(spec, job); err != nil {
+		return fmt.Errorf("failed to add system job %s: %w", name, err)
+	}
+
+	logger.Info("System job registered", "name", name, "cron", spec)
+	return nil
+}
+
+// PreviewCron 使用调度器相同的解析规则校验 cron 表达式，返回 from 之后的 count 次执行时间
+// 支持标准5字段格式、带秒的6字段格式、@daily/@every 等描述符以及 CRON_TZ= 前缀，未指定 CRON_TZ 时按 from 的时区计算
+func PreviewCron(spec string, from time.Time, count int) ([]time.Time, error) {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]time.Time, 0, count)
+	next := from
+	for len(times) < count {
+		next = schedule.Next(next)
+		if next.IsZero() {
+			// 表达式永远不会触发（如 2月30日）
+			break
+		}
+		times = append(times, next)
+	}
+	return times, nil
+}
+
 // scheduleTask 调度单个任务（内部方法，需要加锁）
 func (s *SchedulerService) scheduleTask(task *entities.ScheduledTask) error {
 	// 创建任务执行函数
@@ -181,12 +248,14 @@ func (s *SchedulerService) scheduleTask(task *entities.ScheduledTask) error {
 		s.executeTask(task)
 	}
 
-	// 添加到cron
-	entryID, err := s.cron.AddFunc(task.Cron, jobFunc)
+	schedule, err := taskSchedule(task)
 	if err != nil {
 		return err
 	}
 
+	// 添加到cron
+	entryID := s.cron.Schedule(schedule, cron.FuncJob(jobFunc))
+
 	s.jobs[task.ID] = entryID
 
 	// 更新下次运行时间
@@ -203,13 +272,23 @@ func (s *SchedulerService) scheduleTask(task *entities.ScheduledTask) error {
 func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 	logger.Info("Executing scheduled task", "task", task.Name)
 
-	// 创建context
-	ctx := context.Background()
+	// 创建context，下载使用任务创建者的专属下载目录
+	ctx := contracts.WithUserID(context.Background(), task.CreatedBy)
 
 	// 更新最后运行时间
 	now := time.Now()
 	s.taskRepo.UpdateLastRunTime(task.ID, now)
 
+	// 记录本次运行结果，供 /today 汇总
+	run := &entities.TaskRun{
+		TaskID:    task.ID,
+		TaskName:  task.Name,
+		CreatedBy: task.CreatedBy,
+		Status:    entities.TaskRunStatusEmpty,
+		StartedAt: now,
+	}
+	defer s.finishRun(task, run)
+
 	// 计算时间范围
 	startTime := now.Add(-time.Duration(task.HoursAgo) * time.Hour)
 
@@ -220,17 +299,23 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 		EndTime:   now,
 		VideoOnly: task.VideoOnly,
 		HoursAgo:  task.HoursAgo,
+
+		MinFileSize: task.MinFileSize,
+		MaxFileSize: task.MaxFileSize,
 	}
 
 	resp, err := s.fileService.GetFilesByTimeRange(ctx, req)
 	if err != nil {
 		logger.Error("Failed to fetch files for scheduled task", "task_name", task.Name, "error", err)
+		run.Status = entities.TaskRunStatusFailed
+		run.ErrorMessage = err.Error()
 
 		// 发送失败通知
 		failReq := contracts.TaskNotificationRequest{
 			TaskID:       task.ID,
 			TaskName:     task.Name,
 			TaskType:     "scheduled",
+			TargetID:     notifyTargetID(task),
 			Status:       "failed",
 			ErrorMessage: err.Error(),
 		}
@@ -239,6 +324,7 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 	}
 
 	files := resp.Files
+	run.FilesFound = len(files)
 
 	if len(files) == 0 {
 		logger.Info("No files found for scheduled task", "task", task.Name)
@@ -248,6 +334,7 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 				TaskID:     task.ID,
 				TaskName:   task.Name,
 				TaskType:   "scheduled",
+				TargetID:   notifyTargetID(task),
 				Status:     "completed",
 				FilesCount: 0,
 				Extra: map[string]interface{}{
@@ -272,10 +359,12 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 
 	if task.AutoPreview {
 		// 预览模式 - 不实际下载,只发送通知
+		run.Status = entities.TaskRunStatusPreview
 		completeReq := contracts.TaskNotificationRequest{
 			TaskID:     task.ID,
 			TaskName:   task.Name,
 			TaskType:   "scheduled",
+			TargetID:   notifyTargetID(task),
 			Status:     "completed",
 			FilesCount: len(files),
 			TotalSize:  totalSize,
@@ -293,6 +382,9 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 		downloadCount := 0
 		var downloadedFiles []string
 		var downloadedSize int64
+		// 创建失败的文件单独记录，可通过 RetryFailedItems 重试；本次成功的文件从失败列表移除
+		var failedItems []entities.TaskFailedItem
+		var recovered []string
 
 		for _, file := range files {
 			// 视频过滤（如果需要）- files 已经按需过滤
@@ -306,6 +398,11 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 				Filename:  file.Name,
 				Directory: file.DownloadPath,
 				FileSize:  file.Size,
+				// 记录源路径，供“下载后删除源文件”使用
+				SourcePath:          file.Path,
+				DeleteAfterDownload: task.DeleteAfterDownload,
+				// 下载在 aria2 中出错时记入任务的失败文件
+				TaskID: task.ID,
 				Options: map[string]interface{}{
 					"dir": file.DownloadPath,
 					"out": file.Name,
@@ -315,9 +412,13 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 			// 创建下载任务
 			if _, err := s.downloadService.CreateDownload(ctx, downloadReq); err != nil {
 				logger.Error("Failed to create download for file", "file_name", file.Name, "error", err)
+				run.FailedCount++
+				run.ErrorMessage = err.Error()
+				failedItems = append(failedItems, failedItemFromRequest(downloadReq, err.Error(), time.Now()))
 			} else {
 				downloadCount++
 				downloadedSize += file.Size
+				recovered = append(recovered, file.Path)
 				// 记录前5个文件名
 				if len(downloadedFiles) < 5 {
 					downloadedFiles = append(downloadedFiles, file.Name)
@@ -325,40 +426,29 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 			}
 		}
 
-		// 发送完成通知
+		run.FilesDownloaded = downloadCount
+		run.DownloadedSize = downloadedSize
+		s.recordFailedItems(task, failedItems, recovered)
+
 		if downloadCount > 0 {
-			completeReq := contracts.TaskNotificationRequest{
-				TaskID:     task.ID,
-				TaskName:   task.Name,
-				TaskType:   "scheduled",
-				Status:     "completed",
-				FilesCount: downloadCount,
-				TotalSize:  downloadedSize,
-				Duration:   time.Since(executionStart),
-				Extra: map[string]interface{}{
-					"path":             task.Path,
-					"hours_ago":        task.HoursAgo,
-					"downloaded_files": downloadedFiles,
-					"total_files":      len(files),
-				},
-			}
-			s.notificationSvc.NotifyTaskComplete(ctx, completeReq)
-		} else {
-			// 没有文件需要下载
-			completeReq := contracts.TaskNotificationRequest{
-				TaskID:     task.ID,
-				TaskName:   task.Name,
-				TaskType:   "scheduled",
-				Status:     "completed",
-				FilesCount: 0,
-				Duration:   time.Since(executionStart),
-				Extra: map[string]interface{}{
-					"path":      task.Path,
-					"hours_ago": task.HoursAgo,
-					"message":   "没有符合条件的文件需要下载",
-				},
-			}
-			s.notificationSvc.NotifyTaskComplete(ctx, completeReq)
+			run.Status = entities.TaskRunStatusSuccess
+		} else if run.FailedCount > 0 {
+			// 全部创建失败时记为失败
+			run.Status = entities.TaskRunStatusFailed
+		}
+
+		// 发送下载汇总（任务开启完成通知时）
+		if task.NotifyOnComplete {
+			s.notifyRunComplete(ctx, task, contracts.TaskRunSummary{
+				TaskID:       task.ID,
+				TaskName:     task.Name,
+				Path:         task.Path,
+				FilesMatched: len(files),
+				SuccessCount: downloadCount,
+				FailureCount: run.FailedCount,
+				TotalSize:    downloadedSize,
+				Duration:     time.Since(executionStart),
+			}, downloadedFiles)
 		}
 	}
 
@@ -373,6 +463,182 @@ func (s *SchedulerService) executeTask(task *entities.ScheduledTask) {
 	s.mu.RUnlock()
 }
 
+// notifyRunComplete 通过通知服务向任务的通知目标发送本次运行的下载汇总
+func (s *SchedulerService) notifyRunComplete(ctx context.Context, task *entities.ScheduledTask, summary contracts.TaskRunSummary, downloadedFiles []string) {
+	req := contracts.TaskNotificationRequest{
+		TaskID:     task.ID,
+		TaskName:   task.Name,
+		TaskType:   "scheduled",
+		TargetID:   notifyTargetID(task),
+		Status:     "completed",
+		FilesCount: summary.SuccessCount,
+		TotalSize:  summary.TotalSize,
+		Duration:   summary.Duration,
+		Extra: map[string]interface{}{
+			"path":             task.Path,
+			"hours_ago":        task.HoursAgo,
+			"downloaded_files": downloadedFiles,
+			"total_files":      summary.FilesMatched,
+			"failed_files":     summary.FailureCount,
+		},
+	}
+	s.mu.RLock()
+	format := s.summaryFormat
+	s.mu.RUnlock()
+	if format != nil {
+		req.Message = format(summary)
+	}
+	if err := s.notificationSvc.NotifyTaskComplete(ctx, req); err != nil {
+		logger.Warn("Failed to send task run summary", "task", task.Name, "error", err)
+	}
+}
+
+// finishRun 任务执行结束后保存运行记录并更新连续失败状态
+func (s *SchedulerService) finishRun(task *entities.ScheduledTask, run *entities.TaskRun) {
+	s.recordRun(run)
+
+	failed := run.Status == entities.TaskRunStatusFailed
+	updated, err := s.taskRepo.RecordRunResult(task.ID, failed, run.ErrorMessage)
+	if err != nil {
+		// 任务可能在执行期间被删除
+		logger.Warn("Failed to update task run result", "task", task.Name, "error", err)
+		return
+	}
+
+	if failed && shouldAutoDisable(updated, s.maxFailures) {
+		s.autoDisableTask(updated)
+	}
+}
+
+// shouldAutoDisable 启用中的任务连续失败达到阈值时需要自动停用
+func shouldAutoDisable(task entities.ScheduledTask, maxFailures int) bool {
+	return maxFailures > 0 && task.Enabled && task.ConsecutiveFailures >= maxFailures
+}
+
+// autoDisableTask 停用连续失败的任务并通知任务创建者（或任务的通知聊天）
+func (s *SchedulerService) autoDisableTask(snapshot entities.ScheduledTask) {
+	task, err := s.taskRepo.GetByID(snapshot.ID)
+	if err != nil {
+		logger.Warn("Failed to load task for auto disable", "task_id", snapshot.ID, "error", err)
+		return
+	}
+
+	now := time.Now()
+	task.Enabled = false
+	task.AutoDisabledAt = &now
+	if err := s.UpdateTask(task); err != nil {
+		logger.Error("Failed to auto disable task", "task", task.Name, "error", err)
+		return
+	}
+	logger.Warn("Task auto disabled after consecutive failures",
+		"task", task.Name, "task_id", task.ID, "failures", snapshot.ConsecutiveFailures, "last_error", snapshot.LastError)
+
+	message := fmt.Sprintf(
+		"<b>任务:</b> <code>%s</code>\n"+
+			"<b>任务ID:</b> <code>%s</code>\n"+
+			"<b>连续失败:</b> %d 次\n"+
+			"<b>最后错误:</b> <code>%s</code>\n\n"+
+			"任务已自动停用，排查问题后可在 /tasks 中重新启用",
+		html.EscapeString(task.Name),
+		task.ID,
+		snapshot.ConsecutiveFailures,
+		html.EscapeString(snapshot.LastError),
+	)
+	req := contracts.NotificationRequest{
+		Channel: contracts.ChannelTelegram,
+		Level:   contracts.NotificationLevelError,
+		Title:   "⛔ 定时任务已自动停用",
+		Message: message,
+	}
+	req.TargetID = notifyTargetID(task)
+	if _, err := s.notificationSvc.SendNotification(context.Background(), req); err != nil {
+		logger.Warn("Failed to notify task auto disable", "task", task.Name, "error", err)
+	}
+}
+
+// notifyTargetID 任务通知的接收聊天ID，未设置通知聊天和创建者时为空（发送给所有授权用户）
+func notifyTargetID(task *entities.ScheduledTask) string {
+	if target := task.NotifyTarget(); target != 0 {
+		return strconv.FormatInt(target, 10)
+	}
+	return ""
+}
+
+// recordRun 保存运行记录（未配置运行记录存储时跳过）
+func (s *SchedulerService) recordRun(run *entities.TaskRun) {
+	if s.runRepo == nil {
+		return
+	}
+	run.FinishedAt = time.Now()
+	if err := s.runRepo.Save(run); err != nil {
+		logger.Warn("Failed to save task run", "task", run.TaskName, "error", err)
+	}
+}
+
+// Location 获取调度使用的时区
+func (s *SchedulerService) Location() *time.Location {
+	return s.location
+}
+
+// GetDailyDigest 汇总 day 所在自然日（按调度时区划分）内用户各任务的运行情况，userID 为0时包含所有用户
+func (s *SchedulerService) GetDailyDigest(day time.Time, userID int64) *contracts.TaskDigest {
+	day = day.In(s.location)
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, s.location)
+	end := start.AddDate(0, 0, 1)
+
+	var runs []*entities.TaskRun
+	if s.runRepo != nil {
+		for _, run := range s.runRepo.ListBetween(start, end) {
+			if userID == 0 || run.CreatedBy == userID {
+				runs = append(runs, run)
+			}
+		}
+	}
+	return buildTaskDigest(start, runs)
+}
+
+// buildTaskDigest 按任务聚合运行记录，任务按首次运行时间排序
+func buildTaskDigest(date time.Time, runs []*entities.TaskRun) *contracts.TaskDigest {
+	digest := &contracts.TaskDigest{Date: date}
+	index := make(map[string]int)
+
+	for _, run := range runs {
+		i, ok := index[run.TaskID]
+		if !ok {
+			i = len(digest.Tasks)
+			index[run.TaskID] = i
+			digest.Tasks = append(digest.Tasks, contracts.TaskDigestItem{TaskID: run.TaskID})
+		}
+		item := &digest.Tasks[i]
+
+		// 任务可能被重命名，以最近一次运行时的名称为准
+		item.TaskName = run.TaskName
+		item.Runs++
+		item.FilesDownloaded += run.FilesDownloaded
+		item.DownloadedSize += run.DownloadedSize
+		item.FailedDownloads += run.FailedCount
+		item.LastRunAt = run.StartedAt
+		switch run.Status {
+		case entities.TaskRunStatusEmpty:
+			item.EmptyRuns++
+		case entities.TaskRunStatusPreview:
+			item.PreviewRuns++
+		case entities.TaskRunStatusFailed:
+			item.FailedRuns++
+			digest.FailedRuns++
+		}
+		if run.ErrorMessage != "" {
+			item.LastError = run.ErrorMessage
+		}
+
+		digest.TotalRuns++
+		digest.FilesDownloaded += run.FilesDownloaded
+		digest.DownloadedSize += run.DownloadedSize
+	}
+
+	return digest
+}
+
 // RunTaskNow 立即运行任务
 func (s *SchedulerService) RunTaskNow(taskID string) error {
 	task, err := s.taskRepo.GetByID(taskID)
@@ -0,0 +1,379 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
+)
+
+func TestPreviewCron(t *testing.T) {
+	from := time.Date(2025, 1, 1, 10, 15, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		spec      string
+		count     int
+		wantErr   bool
+		wantTimes []time.Time
+	}{
+		{
+			name:  "标准5字段",
+			spec:  "0 2 * * *",
+			count: 2,
+			wantTimes: []time.Time{
+				time.Date(2025, 1, 2, 2, 0, 0, 0, time.Local),
+				time.Date(2025, 1, 3, 2, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:  "带秒的6字段",
+			spec:  "30 0 2 * * *",
+			count: 1,
+			wantTimes: []time.Time{
+				time.Date(2025, 1, 2, 2, 0, 30, 0, time.Local),
+			},
+		},
+		{
+			name:  "描述符",
+			spec:  "@hourly",
+			count: 1,
+			wantTimes: []time.Time{
+				time.Date(2025, 1, 1, 11, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name:      "永不触发",
+			spec:      "0 0 30 2 *",
+			count:     5,
+			wantTimes: []time.Time{},
+		},
+		{
+			name:    "字段数错误",
+			spec:    "0 2 * *",
+			count:   5,
+			wantErr: true,
+		},
+		{
+			name:    "超出范围",
+			spec:    "61 * * * *",
+			count:   5,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PreviewCron(tt.spec, from, tt.count)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PreviewCron(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.wantTimes) {
+				t.Fatalf("PreviewCron(%q) returned %d times, want %d", tt.spec, len(got), len(tt.wantTimes))
+			}
+			for i := range got {
+				if !got[i].Equal(tt.wantTimes[i]) {
+					t.Errorf("PreviewCron(%q)[%d] = %v, want %v", tt.spec, i, got[i], tt.wantTimes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTaskScheduleTimezone(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		task     *entities.ScheduledTask
+		wantErr  bool
+		wantNext time.Time
+	}{
+		{
+			name:     "未设置时区",
+			task:     &entities.ScheduledTask{Cron: "0 9 * * *"},
+			wantNext: time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "任务时区",
+			task:     &entities.ScheduledTask{Cron: "0 9 * * *", Timezone: "Asia/Shanghai"},
+			wantNext: time.Date(2025, 1, 1, 9, 0, 0, 0, shanghai),
+		},
+		{
+			name:     "时间窗口使用任务时区",
+			task:     &entities.ScheduledTask{Timezone: "Asia/Shanghai", Window: &entities.ScheduleWindow{Start: "09:00", End: "10:00", IntervalMinutes: 30}},
+			wantNext: time.Date(2025, 1, 1, 9, 0, 0, 0, shanghai),
+		},
+		{
+			name:    "未知时区",
+			task:    &entities.ScheduledTask{Cron: "0 9 * * *", Timezone: "Mars/Olympus"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := taskSchedule(tt.task)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("taskSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := schedule.Next(from); !got.Equal(tt.wantNext) {
+				t.Errorf("Next(%v) = %v, want %v", from, got, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestNotifyOnCompleteDefault(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		{name: "旧任务未设置", data: `{"id":"a","cron":"0 2 * * *"}`, want: false},
+		{name: "显式开启", data: `{"id":"a","notify_on_complete":true}`, want: true},
+		{name: "显式关闭", data: `{"id":"a","notify_on_complete":false}`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var task entities.ScheduledTask
+			if err := json.Unmarshal([]byte(tt.data), &task); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if task.NotifyOnComplete != tt.want {
+				t.Errorf("NotifyOnComplete = %v, want %v", task.NotifyOnComplete, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildTaskDigest(t *testing.T) {
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	runs := []*entities.TaskRun{
+		{TaskID: "a", TaskName: "每日下载", Status: entities.TaskRunStatusSuccess, FilesDownloaded: 3, DownloadedSize: 300, StartedAt: day.Add(2 * time.Hour)},
+		{TaskID: "b", TaskName: "频繁同步", Status: entities.TaskRunStatusEmpty, StartedAt: day.Add(3 * time.Hour)},
+		{TaskID: "a", TaskName: "每日下载", Status: entities.TaskRunStatusFailed, ErrorMessage: "timeout", StartedAt: day.Add(4 * time.Hour)},
+	}
+
+	digest := buildTaskDigest(day, runs)
+
+	if digest.TotalRuns != 3 || digest.FailedRuns != 1 || digest.FilesDownloaded != 3 || digest.DownloadedSize != 300 {
+		t.Errorf("totals = %+v, want 3 runs, 1 failed, 3 files, 300 bytes", digest)
+	}
+	if len(digest.Tasks) != 2 {
+		t.Fatalf("len(Tasks) = %d, want 2", len(digest.Tasks))
+	}
+
+	a := digest.Tasks[0]
+	if a.TaskID != "a" || a.Runs != 2 || a.FailedRuns != 1 || a.LastError != "timeout" || !a.LastRunAt.Equal(day.Add(4*time.Hour)) {
+		t.Errorf("Tasks[0] = %+v", a)
+	}
+	b := digest.Tasks[1]
+	if b.TaskID != "b" || b.Runs != 1 || b.EmptyRuns != 1 {
+		t.Errorf("Tasks[1] = %+v", b)
+	}
+}
+
+func TestShouldAutoDisable(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     bool
+		failures    int
+		maxFailures int
+		want        bool
+	}{
+		{name: "below threshold", enabled: true, failures: 2, maxFailures: 3, want: false},
+		{name: "reaches threshold", enabled: true, failures: 3, maxFailures: 3, want: true},
+		{name: "already disabled", enabled: false, failures: 5, maxFailures: 3, want: false},
+		{name: "threshold off", enabled: true, failures: 100, maxFailures: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := entities.ScheduledTask{Enabled: tt.enabled, ConsecutiveFailures: tt.failures}
+			if got := shouldAutoDisable(task, tt.maxFailures); got != tt.want {
+				t.Errorf("shouldAutoDisable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyTargetID(t *testing.T) {
+	tests := []struct {
+		name      string
+		createdBy int64
+		notify    int64
+		want      string
+	}{
+		{name: "creator by default", createdBy: 42, want: "42"},
+		{name: "notify chat overrides creator", createdBy: 42, notify: -1001234567890, want: "-1001234567890"},
+		{name: "no target broadcasts", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &entities.ScheduledTask{CreatedBy: tt.createdBy, NotifyChatID: tt.notify}
+			if got := notifyTargetID(task); got != tt.want {
+				t.Errorf("notifyTargetID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeFailedItems(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	items := []entities.TaskFailedItem{
+		{SourcePath: "/a.mkv", Error: "timeout", Attempts: 1, FailedAt: base},
+		{SourcePath: "/b.mkv", Error: "timeout", Attempts: 2, FailedAt: base.Add(time.Hour)},
+	}
+	failed := []entities.TaskFailedItem{
+		{SourcePath: "/b.mkv", Error: "refused", FailedAt: base.Add(3 * time.Hour)},
+		{SourcePath: "/c.mkv", Error: "refused", FailedAt: base.Add(2 * time.Hour)},
+	}
+
+	got := mergeFailedItems(items, failed, []string{"/a.mkv"}, 0)
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2 (recovered /a.mkv removed)", len(got))
+	}
+	if got[0].SourcePath != "/b.mkv" || got[0].Attempts != 3 || got[0].Error != "refused" {
+		t.Errorf("got[0] = %+v, want /b.mkv retried with 3 attempts", got[0])
+	}
+	if got[1].SourcePath != "/c.mkv" || got[1].Attempts != 1 {
+		t.Errorf("got[1] = %+v, want new /c.mkv with 1 attempt", got[1])
+	}
+
+	// 超出上限时丢弃最早失败的文件
+	bounded := mergeFailedItems(items, failed, nil, 2)
+	if len(bounded) != 2 || bounded[0].SourcePath != "/c.mkv" || bounded[1].SourcePath != "/b.mkv" {
+		t.Errorf("bounded = %+v, want [/c.mkv /b.mkv]", bounded)
+	}
+
+	if all := mergeFailedItems(items, nil, []string{"/a.mkv", "/b.mkv"}, 2); all != nil {
+		t.Errorf("all recovered = %+v, want nil", all)
+	}
+}
+
+func TestHandleEventRecordsFailedDownload(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "scheduled_tasks.json"), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := repository.NewTaskRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task := &entities.ScheduledTask{Name: "每日下载"}
+	if err := repo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+	s := &SchedulerService{taskRepo: repo}
+
+	req := func(taskID, path string) *contracts.DownloadRequest {
+		return &contracts.DownloadRequest{Filename: path[1:], SourcePath: path, TaskID: taskID}
+	}
+	events := []contracts.DownloadEvent{
+		{Type: contracts.DownloadEventFailed, Download: contracts.DownloadResponse{ID: "g1", ErrorMessage: "resource not found"}, Request: req(task.ID, "/a.mkv")},
+		{Type: contracts.DownloadEventFailed, Download: contracts.DownloadResponse{ID: "g2"}, Request: req(task.ID, "/b.mkv")},
+		{Type: contracts.DownloadEventCompleted, Download: contracts.DownloadResponse{ID: "g3"}, Request: req(task.ID, "/c.mkv")},
+		{Type: contracts.DownloadEventFailed, Download: contracts.DownloadResponse{ID: "g4"}, Request: req("", "/d.mkv")},
+		{Type: contracts.DownloadEventFailed, Download: contracts.DownloadResponse{ID: "g5"}, Request: req("deleted", "/e.mkv")},
+		{Type: contracts.DownloadEventFailed, Download: contracts.DownloadResponse{ID: "g6"}},
+	}
+	for _, event := range events {
+		s.HandleEvent(context.Background(), event)
+	}
+
+	got, err := repo.GetByID(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.FailedItems) != 2 {
+		t.Fatalf("FailedItems = %+v, want /a.mkv and /b.mkv", got.FailedItems)
+	}
+	if item := got.FailedItems[0]; item.SourcePath != "/a.mkv" || item.Error != "resource not found" || item.Attempts != 1 {
+		t.Errorf("FailedItems[0] = %+v, want /a.mkv with aria2 error", item)
+	}
+	if item := got.FailedItems[1]; item.SourcePath != "/b.mkv" || item.Error == "" {
+		t.Errorf("FailedItems[1] = %+v, want /b.mkv with fallback error", item)
+	}
+}
+
+func TestWindowCron(t *testing.T) {
+	tests := []struct {
+		start, end string
+		interval   int
+		want       string
+	}{
+		{start: "01:00", end: "06:00", interval: 30, want: "*/30 1-5 * * *"},
+		{start: "22:00", end: "02:00", interval: 15, want: "*/15 22-23,0-1 * * *"},
+		{start: "01:00", end: "06:00", interval: 60, want: "0 1-5 * * *"},
+		{start: "01:30", end: "06:00", interval: 30, want: ""},
+		{start: "01:00", end: "06:00", interval: 45, want: ""},
+	}
+
+	for _, tt := range tests {
+		w, err := NewScheduleWindow(tt.start, tt.end, tt.interval)
+		if err != nil {
+			t.Fatalf("NewScheduleWindow(%s, %s, %d) error = %v", tt.start, tt.end, tt.interval, err)
+		}
+		if got := WindowCron(w); got != tt.want {
+			t.Errorf("WindowCron(%s-%s/%d) = %q, want %q", tt.start, tt.end, tt.interval, got, tt.want)
+		}
+	}
+
+	for _, bad := range []struct {
+		start, end string
+		interval   int
+	}{
+		{"01:00", "01:00", 30},
+		{"25:00", "06:00", 30},
+		{"01:00", "02:00", 90},
+		{"01:00", "06:00", 0},
+	} {
+		if _, err := NewScheduleWindow(bad.start, bad.end, bad.interval); err == nil {
+			t.Errorf("NewScheduleWindow(%s, %s, %d) expected error", bad.start, bad.end, bad.interval)
+		}
+	}
+}
+
+func TestWindowScheduleNext(t *testing.T) {
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 1, day, hour, minute, 0, 0, time.Local) }
+
+	// 跨越午夜、无法用 cron 表示的窗口：23:15-01:00 每45分钟 → 23:15, 00:00, 00:45
+	schedule, err := newWindowSchedule(&entities.ScheduleWindow{Start: "23:15", End: "01:00", IntervalMinutes: 45})
+	if err != nil {
+		t.Fatalf("newWindowSchedule error = %v", err)
+	}
+
+	tests := []struct {
+		from time.Time
+		want time.Time
+	}{
+		{from: at(1, 12, 0), want: at(1, 23, 15)},
+		{from: at(1, 23, 15), want: at(2, 0, 0)},
+		{from: at(2, 0, 10), want: at(2, 0, 45)},
+		{from: at(2, 0, 45), want: at(2, 23, 15)},
+	}
+	for _, tt := range tests {
+		if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// maxTaskFailedItems 每个任务最多保留的失败文件数，超出时丢弃最早失败的
+const maxTaskFailedItems = 50
+
+// failedItemFromRequest 根据创建失败或下载出错的请求构建失败记录
+func failedItemFromRequest(req contracts.DownloadRequest, errMsg string, now time.Time) entities.TaskFailedItem {
+	return entities.TaskFailedItem{
+		Filename:   req.Filename,
+		URL:        req.URL,
+		SourcePath: req.SourcePath,
+		Directory:  req.Directory,
+		FileSize:   req.FileSize,
+		Error:      errMsg,
+		FailedAt:   now,
+	}
+}
+
+// failedBatchItem 将失败记录转换为批次记录项，重试时与批量下载共用重建请求的逻辑
+func failedBatchItem(task *entities.ScheduledTask, item entities.TaskFailedItem) entities.DownloadBatchItem {
+	return entities.DownloadBatchItem{
+		URL:                 item.URL,
+		Filename:            item.Filename,
+		Directory:           item.Directory,
+		SourcePath:          item.SourcePath,
+		FileSize:            item.FileSize,
+		DeleteAfterDownload: task.DeleteAfterDownload,
+		TaskID:              task.ID,
+	}
+}
+
+// mergeFailedItems 合并一次运行（或重试）的结果：移除已恢复的文件，累加再次失败文件的失败次数，追加新失败的文件
+// 以源文件路径去重，结果最多保留 limit 个最近失败的文件
+func mergeFailedItems(items, failed []entities.TaskFailedItem, recovered []string, limit int) []entities.TaskFailedItem {
+	recoveredSet := make(map[string]bool, len(recovered))
+	for _, path := range recovered {
+		recoveredSet[path] = true
+	}
+
+	merged := make([]entities.TaskFailedItem, 0, len(items)+len(failed))
+	index := make(map[string]int, len(items)+len(failed))
+	for _, item := range items {
+		if recoveredSet[item.SourcePath] {
+			continue
+		}
+		index[item.SourcePath] = len(merged)
+		merged = append(merged, item)
+	}
+
+	for _, item := range failed {
+		if i, ok := index[item.SourcePath]; ok {
+			merged[i].URL = item.URL
+			merged[i].Error = item.Error
+			merged[i].FailedAt = item.FailedAt
+			merged[i].Attempts++
+			continue
+		}
+		item.Attempts = 1
+		index[item.SourcePath] = len(merged)
+		merged = append(merged, item)
+	}
+
+	if limit > 0 && len(merged) > limit {
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i].FailedAt.Before(merged[j].FailedAt)
+		})
+		merged = merged[len(merged)-limit:]
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// recordFailedItems 保存本次运行中创建失败的文件，并移除本次已成功创建下载的文件
+func (s *SchedulerService) recordFailedItems(task *entities.ScheduledTask, failed []entities.TaskFailedItem, recovered []string) {
+	if len(failed) == 0 && len(recovered) == 0 {
+		return
+	}
+
+	items, err := s.taskRepo.UpdateFailedItems(task.ID, func(items []entities.TaskFailedItem) []entities.TaskFailedItem {
+		return mergeFailedItems(items, failed, recovered, maxTaskFailedItems)
+	})
+	if err != nil {
+		// 任务可能在执行期间被删除
+		logger.Warn("Failed to save task failed items", "task", task.Name, "error", err)
+		return
+	}
+	if len(failed) > 0 {
+		logger.Info("Task failed items recorded", "task", task.Name, "new_failures", len(failed), "pending", len(items))
+	}
+}
+
+// HandleEvent 处理下载事件（实现 contracts.DownloadEventListener）
+// 定时任务创建的下载在 aria2 中出错时记入任务的失败文件，与创建失败一样可通过 RetryFailedItems 重试
+func (s *SchedulerService) HandleEvent(ctx context.Context, event contracts.DownloadEvent) {
+	if event.Type != contracts.DownloadEventFailed || event.Request == nil || event.Request.TaskID == "" {
+		return
+	}
+
+	task, err := s.taskRepo.GetByID(event.Request.TaskID)
+	if err != nil {
+		// 任务可能已被删除
+		logger.Debug("Failed download belongs to unknown task", "task", event.Request.TaskID, "gid", event.Download.ID)
+		return
+	}
+
+	errMsg := event.Download.ErrorMessage
+	if errMsg == "" {
+		errMsg = "下载出错"
+	}
+	s.recordFailedItems(task, []entities.TaskFailedItem{failedItemFromRequest(*event.Request, errMsg, time.Now())}, nil)
+}
+
+// RetryFailedItems 只重新提交任务记录的失败文件，不重跑整个时间窗口
+// 重新提交成功的文件从失败列表移除，仍失败的累加失败次数
+func (s *SchedulerService) RetryFailedItems(taskID string) (*contracts.TaskRetryResult, error) {
+	task, err := s.taskRepo.GetByID(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	items := append([]entities.TaskFailedItem(nil), task.FailedItems...)
+	result := &contracts.TaskRetryResult{TaskID: task.ID, TaskName: task.Name, FailedCount: len(items)}
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	ctx := contracts.WithUserID(context.Background(), task.CreatedBy)
+	var stillFailed []entities.TaskFailedItem
+	var recovered []string
+	for _, item := range items {
+		batchItem := failedBatchItem(task, item)
+		retryResult := s.downloadService.RetryBatchItem(ctx, &batchItem)
+		if !retryResult.Success {
+			result.FailureCount++
+			stillFailed = append(stillFailed, failedItemFromRequest(retryResult.Request, retryResult.Error, time.Now()))
+		} else {
+			result.SuccessCount++
+			recovered = append(recovered, item.SourcePath)
+		}
+		result.Results = append(result.Results, retryResult)
+	}
+
+	s.recordFailedItems(task, stillFailed, recovered)
+
+	logger.Info("Task failed items retried",
+		"task", task.Name,
+		"failed", result.FailedCount,
+		"success", result.SuccessCount,
+		"failure", result.FailureCount)
+	return result, nil
+}
@@ -3,6 +3,7 @@ package task
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
@@ -50,24 +51,33 @@ func (s *AppTaskService) CreateTask(ctx context.Context, req contracts.TaskReque
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	// 2. 验证Cron表达式
-	if _, err := cron.ParseStandard(req.CronExpr); err != nil {
+	// 2. 验证Cron表达式和时区
+	if _, err := parseCron(req.CronExpr); err != nil {
 		return nil, fmt.Errorf("invalid cron expression: %w", err)
 	}
+	if _, err := LoadTimezone(req.Timezone); err != nil {
+		return nil, err
+	}
 
 	// 3. 创建任务实体
 	task := &entities.ScheduledTask{
-		Name:        req.Name,
-		Path:        req.Path,
-		Cron:        req.CronExpr,
-		HoursAgo:    req.HoursAgo,
-		VideoOnly:   req.VideoOnly,
-		AutoPreview: req.AutoPreview,
-		Enabled:     req.Enabled,
-		CreatedBy:   req.CreatedBy,
-		Status:      entities.TaskStatusIdle,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Name:                req.Name,
+		Path:                req.Path,
+		Cron:                req.CronExpr,
+		Timezone:            strings.TrimSpace(req.Timezone),
+		HoursAgo:            req.HoursAgo,
+		VideoOnly:           req.VideoOnly,
+		AutoPreview:         req.AutoPreview,
+		DeleteAfterDownload: req.DeleteAfterDownload,
+		Enabled:             req.Enabled,
+		CreatedBy:           req.CreatedBy,
+		NotifyChatID:        req.NotifyChatID,
+		NotifyOnComplete:    req.NotifyOnComplete,
+		MinFileSize:         req.MinFileSize,
+		MaxFileSize:         req.MaxFileSize,
+		Status:              entities.TaskStatusIdle,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
 	}
 
 	// 4. 保存到数据库
@@ -89,7 +99,7 @@ func (s *AppTaskService) CreateTask(ctx context.Context, req contracts.TaskReque
 func (s *AppTaskService) GetTask(ctx context.Context, id string) (*contracts.TaskResponse, error) {
 	task, err := s.taskRepo.GetByID(id)
 	if err != nil {
-		return nil, fmt.Errorf("task not found: %w", err)
+		return nil, err
 	}
 
 	return s.convertToTaskResponse(task), nil
@@ -100,7 +110,7 @@ func (s *AppTaskService) UpdateTask(ctx context.Context, id string, req contract
 	// 获取现有任务
 	task, err := s.taskRepo.GetByID(id)
 	if err != nil {
-		return nil, fmt.Errorf("task not found: %w", err)
+		return nil, err
 	}
 
 	// 更新字段
@@ -115,10 +125,19 @@ func (s *AppTaskService) UpdateTask(ctx context.Context, id string, req contract
 	}
 	if req.CronExpr != nil && *req.CronExpr != task.Cron {
 		// 验证新的Cron表达式
-		if _, err := cron.ParseStandard(*req.CronExpr); err != nil {
+		if _, err := parseCron(*req.CronExpr); err != nil {
 			return nil, fmt.Errorf("invalid cron expression: %w", err)
 		}
 		task.Cron = *req.CronExpr
+		// 改用 cron 表达式后不再按时间窗口调度
+		task.Window = nil
+		updated = true
+	}
+	if req.Timezone != nil && strings.TrimSpace(*req.Timezone) != task.Timezone {
+		if _, err := LoadTimezone(*req.Timezone); err != nil {
+			return nil, err
+		}
+		task.Timezone = strings.TrimSpace(*req.Timezone)
 		updated = true
 	}
 	if req.HoursAgo != nil && *req.HoursAgo != task.HoursAgo {
@@ -133,6 +152,29 @@ func (s *AppTaskService) UpdateTask(ctx context.Context, id string, req contract
 		task.AutoPreview = *req.AutoPreview
 		updated = true
 	}
+	if req.DeleteAfterDownload != nil && *req.DeleteAfterDownload != task.DeleteAfterDownload {
+		task.DeleteAfterDownload = *req.DeleteAfterDownload
+		updated = true
+	}
+	if req.NotifyChatID != nil && *req.NotifyChatID != task.NotifyChatID {
+		task.NotifyChatID = *req.NotifyChatID
+		updated = true
+	}
+	if req.NotifyOnComplete != nil && *req.NotifyOnComplete != task.NotifyOnComplete {
+		task.NotifyOnComplete = *req.NotifyOnComplete
+		updated = true
+	}
+	if req.MinFileSize != nil && *req.MinFileSize != task.MinFileSize {
+		task.MinFileSize = *req.MinFileSize
+		updated = true
+	}
+	if req.MaxFileSize != nil && *req.MaxFileSize != task.MaxFileSize {
+		task.MaxFileSize = *req.MaxFileSize
+		updated = true
+	}
+	if err := task.ValidateSizeRange(); err != nil {
+		return nil, err
+	}
 	if req.Enabled != nil && *req.Enabled != task.Enabled {
 		task.Enabled = *req.Enabled
 		updated = true
@@ -221,7 +263,7 @@ func (s *AppTaskService) RunTaskNow(ctx context.Context, req contracts.TaskRunRe
 	// 获取任务
 	task, err := s.taskRepo.GetByID(req.TaskID)
 	if err != nil {
-		return nil, fmt.Errorf("task not found: %w", err)
+		return nil, err
 	}
 
 	runID := fmt.Sprintf("run_%s_%d", req.TaskID[:8], time.Now().Unix())
@@ -243,8 +285,8 @@ func (s *AppTaskService) RunTaskNow(ctx context.Context, req contracts.TaskRunRe
 		}, nil
 	}
 
-	// 实际执行任务
-	downloadIDs, err := s.executeTask(ctx, task)
+	// 实际执行任务，下载使用任务创建者的专属下载目录
+	downloadIDs, err := s.executeTask(contracts.WithUserID(ctx, task.CreatedBy), task)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute task: %w", err)
 	}
@@ -279,7 +321,7 @@ func (s *AppTaskService) StopTask(ctx context.Context, id string) error {
 func (s *AppTaskService) PreviewTask(ctx context.Context, req contracts.TaskPreviewRequest) (*contracts.TaskPreviewResponse, error) {
 	task, err := s.taskRepo.GetByID(req.TaskID)
 	if err != nil {
-		return nil, fmt.Errorf("task not found: %w", err)
+		return nil, err
 	}
 
 	return s.previewTaskExecution(ctx, task)
@@ -425,7 +467,7 @@ func (s *AppTaskService) validateTaskRequest(req contracts.TaskRequest) error {
 
 // calculateNextRunTime 计算下次执行时间
 func (s *AppTaskService) calculateNextRunTime(task *entities.ScheduledTask) {
-	if schedule, err := cron.ParseStandard(task.Cron); err == nil {
+	if schedule, err := taskSchedule(task); err == nil {
 		nextTime := schedule.Next(time.Now())
 		task.NextRunAt = &nextTime
 	}
@@ -434,23 +476,30 @@ func (s *AppTaskService) calculateNextRunTime(task *entities.ScheduledTask) {
 // convertToTaskResponse 转换任务实体到响应格式
 func (s *AppTaskService) convertToTaskResponse(task *entities.ScheduledTask) *contracts.TaskResponse {
 	return &contracts.TaskResponse{
-		ID:           task.ID,
-		Name:         task.Name,
-		Path:         task.Path,
-		CronExpr:     task.Cron,
-		HoursAgo:     task.HoursAgo,
-		VideoOnly:    task.VideoOnly,
-		AutoPreview:  task.AutoPreview,
-		Enabled:      task.Enabled,
-		CreatedBy:    task.CreatedBy,
-		Status:       task.Status,
-		LastRunAt:    task.LastRunAt,
-		NextRunAt:    task.NextRunAt,
-		RunCount:     task.RunCount,
-		SuccessCount: task.SuccessCount,
-		FailureCount: task.FailureCount,
-		CreatedAt:    task.CreatedAt,
-		UpdatedAt:    task.UpdatedAt,
+		ID:                  task.ID,
+		Name:                task.Name,
+		Path:                task.Path,
+		CronExpr:            task.Cron,
+		Timezone:            task.Timezone,
+		Window:              task.Window,
+		HoursAgo:            task.HoursAgo,
+		VideoOnly:           task.VideoOnly,
+		AutoPreview:         task.AutoPreview,
+		DeleteAfterDownload: task.DeleteAfterDownload,
+		Enabled:             task.Enabled,
+		CreatedBy:           task.CreatedBy,
+		NotifyChatID:        task.NotifyChatID,
+		NotifyOnComplete:    task.NotifyOnComplete,
+		MinFileSize:         task.MinFileSize,
+		MaxFileSize:         task.MaxFileSize,
+		Status:              task.Status,
+		LastRunAt:           task.LastRunAt,
+		NextRunAt:           task.NextRunAt,
+		RunCount:            task.RunCount,
+		SuccessCount:        task.SuccessCount,
+		FailureCount:        task.FailureCount,
+		CreatedAt:           task.CreatedAt,
+		UpdatedAt:           task.UpdatedAt,
 	}
 }
 
@@ -492,6 +541,9 @@ func (s *AppTaskService) previewTaskExecution(ctx context.Context, task *entitie
 		StartTime: startTime,
 		EndTime:   endTime,
 		VideoOnly: task.VideoOnly,
+
+		MinFileSize: task.MinFileSize,
+		MaxFileSize: task.MaxFileSize,
 	}
 
 	fileResp, err := s.fileService.GetFilesByTimeRange(ctx, fileReq)
@@ -515,12 +567,14 @@ func (s *AppTaskService) previewTaskExecution(ctx context.Context, task *entitie
 
 	// 构建预览摘要
 	summary := contracts.PreviewSummary{
-		TotalFiles: len(filePreviews),
-		TotalSize:  fileResp.Summary.TotalSizeFormatted,
-		VideoFiles: fileResp.Summary.VideoFiles,
-		MovieFiles: fileResp.Summary.MovieFiles,
-		TVFiles:    fileResp.Summary.TVFiles,
-		OtherFiles: fileResp.Summary.OtherFiles,
+		TotalFiles:    len(filePreviews),
+		TotalSize:     fileResp.Summary.TotalSizeFormatted,
+		VideoFiles:    fileResp.Summary.VideoFiles,
+		MovieFiles:    fileResp.Summary.MovieFiles,
+		TVFiles:       fileResp.Summary.TVFiles,
+		OtherFiles:    fileResp.Summary.OtherFiles,
+		MusicFiles:    fileResp.Summary.MusicFiles,
+		DocumentFiles: fileResp.Summary.DocumentFiles,
 	}
 
 	return &contracts.TaskPreviewResponse{
@@ -545,6 +599,9 @@ func (s *AppTaskService) executeTask(ctx context.Context, task *entities.Schedul
 		StartTime: startTime,
 		EndTime:   endTime,
 		VideoOnly: task.VideoOnly,
+
+		MinFileSize: task.MinFileSize,
+		MaxFileSize: task.MaxFileSize,
 	}
 
 	fileResp, err := s.fileService.GetFilesByTimeRange(ctx, fileReq)
@@ -561,6 +618,7 @@ func (s *AppTaskService) executeTask(ctx context.Context, task *entities.Schedul
 			Directory:    file.DownloadPath,
 			VideoOnly:    task.VideoOnly,
 			AutoClassify: true,
+			SourcePath:   file.Path,
 		})
 	}
 
@@ -0,0 +1,174 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// minWindowInterval/maxWindowInterval 时间窗口调度允许的运行间隔（分钟）
+	minWindowInterval = 1
+	maxWindowInterval = 24 * 60
+)
+
+// NewScheduleWindow 校验并创建时间窗口调度参数
+// start/end 为 HH:MM，end 早于 start 表示窗口跨越午夜；间隔不能超过窗口长度
+func NewScheduleWindow(start, end string, intervalMinutes int) (*entities.ScheduleWindow, error) {
+	startOffset, err := parseClock(start)
+	if err != nil {
+		return nil, fmt.Errorf("开始时间无效: %w", err)
+	}
+	endOffset, err := parseClock(end)
+	if err != nil {
+		return nil, fmt.Errorf("结束时间无效: %w", err)
+	}
+	if startOffset == endOffset {
+		return nil, fmt.Errorf("开始时间和结束时间不能相同")
+	}
+	if intervalMinutes < minWindowInterval || intervalMinutes > maxWindowInterval {
+		return nil, fmt.Errorf("间隔必须在 %d-%d 分钟之间: %d", minWindowInterval, maxWindowInterval, intervalMinutes)
+	}
+	if length := windowLength(startOffset, endOffset); time.Duration(intervalMinutes)*time.Minute > length {
+		return nil, fmt.Errorf("间隔 %d 分钟超过了窗口长度 %d 分钟", intervalMinutes, int(length/time.Minute))
+	}
+
+	return &entities.ScheduleWindow{
+		Start:           formatClock(startOffset),
+		End:             formatClock(endOffset),
+		IntervalMinutes: intervalMinutes,
+	}, nil
+}
+
+// WindowCron 生成与时间窗口等价的标准 cron 表达式，无法用单个表达式精确表示时返回空
+// 仅当窗口起止为整点且间隔能整除 60 分钟时可以表示，如 01:00-06:00 每30分钟 → */30 1-5 * * *
+func WindowCron(w *entities.ScheduleWindow) string {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return ""
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return ""
+	}
+	if start%time.Hour != 0 || end%time.Hour != 0 || 60%w.IntervalMinutes != 0 {
+		return ""
+	}
+
+	startHour, endHour := int(start/time.Hour), int(end/time.Hour)
+	var hours string
+	if startHour < endHour {
+		hours = hourRange(startHour, endHour-1)
+	} else {
+		// 跨越午夜：startHour-23 和 0-(endHour-1)
+		hours = hourRange(startHour, 23)
+		if endHour > 0 {
+			hours += "," + hourRange(0, endHour-1)
+		}
+	}
+
+	minutes := "0"
+	if w.IntervalMinutes < 60 {
+		minutes = fmt.Sprintf("*/%d", w.IntervalMinutes)
+	}
+	return fmt.Sprintf("%s %s * * *", minutes, hours)
+}
+
+// hourRange 格式化 cron 小时字段
+func hourRange(from, to int) string {
+	if from == to {
+		return fmt.Sprintf("%d", from)
+	}
+	return fmt.Sprintf("%d-%d", from, to)
+}
+
+// windowSchedule 时间窗口调度：每天从窗口开始时间起每隔 interval 运行一次，不含结束时间
+type windowSchedule struct {
+	start    time.Duration // 窗口开始时间距午夜的偏移
+	length   time.Duration // 窗口长度
+	interval time.Duration
+}
+
+// newWindowSchedule 根据时间窗口参数创建调度
+func newWindowSchedule(w *entities.ScheduleWindow) (*windowSchedule, error) {
+	if _, err := NewScheduleWindow(w.Start, w.End, w.IntervalMinutes); err != nil {
+		return nil, err
+	}
+	start, _ := parseClock(w.Start)
+	end, _ := parseClock(w.End)
+	return &windowSchedule{start: start, length: windowLength(start, end), interval: time.Duration(w.IntervalMinutes) * time.Minute}, nil
+}
+
+// windowLength 计算窗口长度，结束早于开始时跨越午夜
+func windowLength(start, end time.Duration) time.Duration {
+	if end < start {
+		return end + 24*time.Hour - start
+	}
+	return end - start
+}
+
+// Next 返回 t 之后的下一次运行时间（实现 cron.Schedule），按 t 所在时区计算
+func (s *windowSchedule) Next(t time.Time) time.Time {
+	var next time.Time
+	// 前一天开始的跨午夜窗口可能仍在进行中
+	for offset := -1; offset <= 1; offset++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, t.Location())
+		windowStart := day.Add(s.start)
+		windowEnd := windowStart.Add(s.length)
+
+		candidate := windowStart
+		if !t.Before(windowStart) {
+			if !t.Before(windowEnd) {
+				continue
+			}
+			candidate = windowStart.Add((t.Sub(windowStart)/s.interval + 1) * s.interval)
+			if !candidate.Before(windowEnd) {
+				continue
+			}
+		}
+		if next.IsZero() || candidate.Before(next) {
+			next = candidate
+		}
+	}
+	return next
+}
+
+// taskSchedule 返回任务的调度：有 cron 表达式时使用表达式，否则使用时间窗口；设置了时区时按该时区计算
+func taskSchedule(task *entities.ScheduledTask) (cron.Schedule, error) {
+	location, err := LoadTimezone(task.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	var schedule cron.Schedule
+	if task.Cron == "" && task.Window != nil {
+		schedule, err = newWindowSchedule(task.Window)
+	} else if schedule, err = parseCron(task.Cron); err != nil {
+		err = fmt.Errorf("invalid cron expression: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if location != nil {
+		return zonedSchedule{Schedule: schedule, location: location}, nil
+	}
+	return schedule, nil
+}
+
+// parseClock 解析 HH:MM（小时可省略前导零），返回距午夜的偏移
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("格式应为 HH:MM: %s", clock)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// formatClock 将距午夜的偏移格式化为 HH:MM
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int(offset%time.Hour/time.Minute))
+}
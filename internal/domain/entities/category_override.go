@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// CategoryOverride 用户纠正的文件分类 - 文件名规范化后包含 Pattern 时使用 Category
+type CategoryOverride struct {
+	Pattern   string    `json:"pattern"`  // 匹配键（小写，分隔符统一为空格）
+	Title     string    `json:"title"`    // 显示标题，也用于生成下载目录
+	Category  string    `json:"category"` // movie/tv/variety/video
+	CreatedBy int64     `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
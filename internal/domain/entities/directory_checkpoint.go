@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// DirectoryDownloadCheckpoint 目录下载断点 - 记录已成功提交到 aria2 的文件，
+// 下载中途中断（重启、取消）后可跳过这些文件从断点继续；整次下载提交完成后删除
+type DirectoryDownloadCheckpoint struct {
+	ID                  string    `json:"id"`
+	Path                string    `json:"path"`                            // 目录路径
+	TotalFiles          int       `json:"total_files"`                     // 开始时待提交的文件数
+	Queued              []string  `json:"queued,omitempty"`                // 已提交的源文件路径
+	DeleteAfterDownload bool      `json:"delete_after_download,omitempty"` // 继续时沿用的下载后删除设置
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
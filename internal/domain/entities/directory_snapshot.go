@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// DirectorySnapshot 目录文件快照（/snapshot 记录，/diff 与当前内容对比）
+type DirectorySnapshot struct {
+	Path      string         `json:"path"`                // 目录路径
+	Files     []SnapshotFile `json:"files"`               // 快照时的文件（递归）
+	Truncated bool           `json:"truncated,omitempty"` // 目录过大，快照只包含部分文件
+	CreatedBy int64          `json:"created_by,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// SnapshotFile 快照中的单个文件
+type SnapshotFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
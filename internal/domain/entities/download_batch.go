@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// DownloadBatch 批量下载记录 - 保存每次批量下载的原始请求，用于批量重试失败的文件
+type DownloadBatch struct {
+	ID        string              `json:"id"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+	Items     []DownloadBatchItem `json:"items"`
+	// Archive 全部文件下载完成后打包为一个 zip 文件（创建批次时可选开启）
+	Archive *DownloadBatchArchive `json:"archive,omitempty"`
+}
+
+// DownloadBatchArchive 批次打包设置和结果
+type DownloadBatchArchive struct {
+	RemoveOriginals bool      `json:"remove_originals,omitempty"` // 打包成功后删除原文件
+	Path            string    `json:"path,omitempty"`             // 生成的压缩包路径，为空表示尚未打包
+	ArchivedAt      time.Time `json:"archived_at,omitempty"`
+	Error           string    `json:"error,omitempty"` // 最近一次未能打包的原因
+}
+
+// DownloadBatchItem 批量下载中的单个文件
+type DownloadBatchItem struct {
+	URL                 string `json:"url"`
+	Filename            string `json:"filename,omitempty"`
+	Directory           string `json:"directory,omitempty"`
+	SourcePath          string `json:"source_path,omitempty"` // Alist 源文件路径，重试时用于刷新下载链接和去重
+	FileSize            int64  `json:"file_size,omitempty"`
+	VideoOnly           bool   `json:"video_only,omitempty"`
+	AutoClassify        bool   `json:"auto_classify,omitempty"`
+	DeleteAfterDownload bool   `json:"delete_after_download,omitempty"`
+	TaskID              string `json:"task_id,omitempty"` // 创建该文件下载的定时任务ID
+	GID                 string `json:"gid,omitempty"`     // 最近一次创建成功的 aria2 GID
+	Error               string `json:"error,omitempty"`   // 最近一次创建失败的错误信息
+}
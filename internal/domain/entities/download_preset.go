@@ -0,0 +1,16 @@
+package entities
+
+import (
+	"time"
+)
+
+// DownloadPreset 用户保存的常用下载（路径和筛选条件的组合）
+type DownloadPreset struct {
+	UserID     int64     `json:"user_id"`              // 创建者Telegram ID
+	Name       string    `json:"name"`                 // 预设名称（同一用户内唯一）
+	Path       string    `json:"path"`                 // Alist 目录路径
+	VideoOnly  bool      `json:"video_only"`           // 是否只下载视频
+	Hours      int       `json:"hours,omitempty"`      // 只下载最近多少小时内修改的文件，0 表示全部
+	Extensions []string  `json:"extensions,omitempty"` // 只下载这些扩展名的文件，为空时不筛选
+	CreatedAt  time.Time `json:"created_at"`
+}
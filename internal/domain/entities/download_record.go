@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+)
+
+// DownloadRecord 下载历史记录 - 每次下载尝试（aria2 任务）对应一条记录
+type DownloadRecord struct {
+	ID           string                      `json:"id"`          // aria2 GID
+	SourcePath   string                      `json:"source_path"` // Alist 源文件路径（已规范化），非 Alist 下载为空
+	Filename     string                      `json:"filename"`
+	Directory    string                      `json:"directory"` // 最终保存目录
+	Status       valueobjects.DownloadStatus `json:"status"`
+	TotalSize    int64                       `json:"total_size"`
+	ErrorMessage string                      `json:"error_message,omitempty"`
+	Category     string                      `json:"category,omitempty"`     // 完成时所在分类目录对应的分类，无法判断时为空
+	CompletedAt  time.Time                   `json:"completed_at,omitempty"` // 下载完成时间
+	CreatedAt    time.Time                   `json:"created_at"`
+	UpdatedAt    time.Time                   `json:"updated_at"`
+}
+
+// CompletionTime 下载完成时间，早期记录没有完成时间时使用最后更新时间
+func (r *DownloadRecord) CompletionTime() time.Time {
+	if r.CompletedAt.IsZero() {
+		return r.UpdatedAt
+	}
+	return r.CompletedAt
+}
@@ -0,0 +1,13 @@
+package entities
+
+import (
+	"time"
+)
+
+// PinnedDirectory 用户收藏的 Alist 目录
+type PinnedDirectory struct {
+	UserID    int64     `json:"user_id"`        // 收藏者Telegram ID
+	Path      string    `json:"path"`           // 目录路径
+	Root      string    `json:"root,omitempty"` // 收藏时所在的 alist.roots 根目录名称（为空时不限定）
+	CreatedAt time.Time `json:"created_at"`     // 收藏时间
+}
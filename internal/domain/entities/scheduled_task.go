@@ -1,9 +1,14 @@
 package entities
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
+// ErrTaskNotFound 定时任务不存在
+var ErrTaskNotFound = errors.New("task not found")
+
 // TaskStatus 任务状态枚举
 type TaskStatus string
 
@@ -17,21 +22,83 @@ const (
 
 // ScheduledTask 定时任务实体
 type ScheduledTask struct {
-	ID           string     `json:"id"`            // 任务ID
-	Name         string     `json:"name"`          // 任务名称
-	Enabled      bool       `json:"enabled"`       // 是否启用
-	Status       TaskStatus `json:"status"`        // 任务状态
-	Cron         string     `json:"cron"`          // cron表达式
-	Path         string     `json:"path"`          // 下载路径
-	HoursAgo     int        `json:"hours_ago"`     // 下载多少小时内的文件
-	VideoOnly    bool       `json:"video_only"`    // 是否只下载视频
-	AutoPreview  bool       `json:"auto_preview"`  // 是否预览模式
-	CreatedBy    int64      `json:"created_by"`    // 创建者Telegram ID
-	RunCount     int        `json:"run_count"`     // 运行次数
-	SuccessCount int        `json:"success_count"` // 成功次数
-	FailureCount int        `json:"failure_count"` // 失败次数
-	CreatedAt    time.Time  `json:"created_at"`    // 创建时间
-	UpdatedAt    time.Time  `json:"updated_at"`    // 更新时间
-	LastRunAt    *time.Time `json:"last_run_at"`   // 最后运行时间
-	NextRunAt    *time.Time `json:"next_run_at"`   // 下次运行时间
+	ID                  string     `json:"id"`                              // 任务ID
+	Name                string     `json:"name"`                            // 任务名称
+	Enabled             bool       `json:"enabled"`                         // 是否启用
+	Status              TaskStatus `json:"status"`                          // 任务状态
+	Cron                string     `json:"cron"`                            // cron表达式（分 时 日 月 周，可选前置秒字段）
+	Timezone            string     `json:"timezone,omitempty"`              // 计算触发时间的 IANA 时区（如 Asia/Shanghai），为空时使用调度器时区
+	Path                string     `json:"path"`                            // 下载路径
+	HoursAgo            int        `json:"hours_ago"`                       // 下载多少小时内的文件
+	VideoOnly           bool       `json:"video_only"`                      // 是否只下载视频
+	MinFileSize         int64      `json:"min_file_size,omitempty"`         // 最小文件大小（字节），0为不限
+	MaxFileSize         int64      `json:"max_file_size,omitempty"`         // 最大文件大小（字节），0为不限
+	AutoPreview         bool       `json:"auto_preview"`                    // 是否预览模式
+	DeleteAfterDownload bool       `json:"delete_after_download,omitempty"` // 下载完成后删除源文件
+	CreatedBy           int64      `json:"created_by"`                      // 创建者Telegram ID
+	NotifyChatID        int64      `json:"notify_chat_id,omitempty"`        // 运行结果通知的聊天/频道ID，为0时通知创建者
+	NotifyOnComplete    bool       `json:"notify_on_complete,omitempty"`    // 运行下载结束后发送下载汇总（匹配、成功、失败数和总大小），/addtask 创建的任务默认开启
+	RunCount            int        `json:"run_count"`                       // 运行次数
+	SuccessCount        int        `json:"success_count"`                   // 成功次数
+	FailureCount        int        `json:"failure_count"`                   // 失败次数
+	CreatedAt           time.Time  `json:"created_at"`                      // 创建时间
+	UpdatedAt           time.Time  `json:"updated_at"`                      // 更新时间
+	LastRunAt           *time.Time `json:"last_run_at"`                     // 最后运行时间
+	NextRunAt           *time.Time `json:"next_run_at"`                     // 下次运行时间
+
+	ConsecutiveFailures int        `json:"consecutive_failures,omitempty"` // 连续失败次数，成功运行后清零
+	LastError           string     `json:"last_error,omitempty"`           // 最近一次失败的错误信息
+	AutoDisabledAt      *time.Time `json:"auto_disabled_at,omitempty"`     // 因连续失败被自动停用的时间
+
+	FailedItems []TaskFailedItem `json:"failed_items,omitempty"` // 运行中创建下载失败、尚未恢复的文件（有上限）
+
+	Window *ScheduleWindow `json:"window,omitempty"` // 时间窗口调度（/addwindow 创建），Cron 为空时按窗口计算触发时间
+}
+
+// ScheduleWindow 每天在时间窗口内按固定间隔运行
+type ScheduleWindow struct {
+	Start           string `json:"start"`            // 窗口开始时间 HH:MM
+	End             string `json:"end"`              // 窗口结束时间 HH:MM（不含），早于开始时间表示跨越午夜
+	IntervalMinutes int    `json:"interval_minutes"` // 运行间隔（分钟）
+}
+
+// Describe 返回窗口调度的可读描述
+func (w *ScheduleWindow) Describe() string {
+	return fmt.Sprintf("%s-%s 每%d分钟", w.Start, w.End, w.IntervalMinutes)
+}
+
+// TaskFailedItem 定时任务运行中创建下载失败的文件，可单独重试而无需重跑整个时间窗口
+type TaskFailedItem struct {
+	Filename   string    `json:"filename"`
+	URL        string    `json:"url"`         // 失败时的下载链接，无法刷新链接时使用
+	SourcePath string    `json:"source_path"` // Alist 源文件路径，重试时据此刷新下载链接
+	Directory  string    `json:"directory"`
+	FileSize   int64     `json:"file_size"`
+	Error      string    `json:"error"`    // 最近一次失败原因
+	Attempts   int       `json:"attempts"` // 累计失败次数
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+// ValidateSizeRange 校验文件大小范围：不能为负数，同时设置时最小值不能大于最大值
+func (t *ScheduledTask) ValidateSizeRange() error {
+	if t.MinFileSize < 0 || t.MaxFileSize < 0 {
+		return fmt.Errorf("file size limits must not be negative")
+	}
+	if t.MaxFileSize > 0 && t.MinFileSize > t.MaxFileSize {
+		return fmt.Errorf("min file size %d exceeds max file size %d", t.MinFileSize, t.MaxFileSize)
+	}
+	return nil
+}
+
+// NotifyTarget 任务通知的接收聊天：设置了 NotifyChatID 时使用该聊天，否则为创建者（都未设置时为0）
+func (t *ScheduledTask) NotifyTarget() int64 {
+	if t.NotifyChatID != 0 {
+		return t.NotifyChatID
+	}
+	return t.CreatedBy
+}
+
+// IsAutoDisabled 任务是否因连续失败被自动停用
+func (t *ScheduledTask) IsAutoDisabled() bool {
+	return !t.Enabled && t.AutoDisabledAt != nil
 }
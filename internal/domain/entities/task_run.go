@@ -0,0 +1,29 @@
+package entities
+
+import "time"
+
+// TaskRunStatus 定时任务单次运行结果
+type TaskRunStatus string
+
+const (
+	TaskRunStatusSuccess TaskRunStatus = "success" // 已创建下载任务
+	TaskRunStatusEmpty   TaskRunStatus = "empty"   // 触发但没有符合条件的文件
+	TaskRunStatusPreview TaskRunStatus = "preview" // 预览模式，只通知不下载
+	TaskRunStatusFailed  TaskRunStatus = "failed"  // 获取文件列表失败或下载全部创建失败
+)
+
+// TaskRun 定时任务运行记录 - 每次触发（含立即运行）对应一条记录
+type TaskRun struct {
+	ID              string        `json:"id"`
+	TaskID          string        `json:"task_id"`
+	TaskName        string        `json:"task_name"`
+	CreatedBy       int64         `json:"created_by"` // 任务创建者
+	Status          TaskRunStatus `json:"status"`
+	FilesFound      int           `json:"files_found"`      // 时间范围内找到的文件数
+	FilesDownloaded int           `json:"files_downloaded"` // 成功创建下载的文件数
+	DownloadedSize  int64         `json:"downloaded_size"`
+	FailedCount     int           `json:"failed_count"` // 创建下载失败的文件数
+	ErrorMessage    string        `json:"error_message,omitempty"`
+	StartedAt       time.Time     `json:"started_at"`
+	FinishedAt      time.Time     `json:"finished_at"`
+}
@@ -36,6 +36,7 @@ type Suggestion struct {
 	// ========== 跳过标记 ==========
 	Skipped    bool   `json:"skipped,omitempty"`     // 是否跳过（已符合标准格式）
 	SkipReason string `json:"skip_reason,omitempty"` // 跳过原因
+	Retryable  bool   `json:"retryable,omitempty"`   // 因TMDB请求失败跳过（而非无匹配），可稍后重试
 }
 
 // MediaType 媒体类型
@@ -53,6 +54,7 @@ const (
 	SourceTMDB   Source = "tmdb"
 	SourceLLM    Source = "llm"
 	SourceHybrid Source = "hybrid"
+	SourceParsed Source = "parsed" // 仅由文件名解析得出，未经TMDB验证
 )
 
 // ToTMDBMediaType 转换为TMDB的MediaType（用于API调用）
@@ -0,0 +1,77 @@
+package media
+
+import (
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	fileutil "github.com/easayliu/alist-aria2-download/pkg/utils/file"
+)
+
+// ClassificationRules 用户配置的文件分类规则，优先于内置的扩展名和关键词规则
+type ClassificationRules struct {
+	videoExts     []string // 视频扩展名（download.video_extensions 或默认列表 + 追加的扩展名）
+	otherExts     []string // 始终归为"其他"的扩展名
+	tvKeywords    []string // 文件名包含时归为电视剧
+	movieKeywords []string // 文件名包含时归为电影
+}
+
+// NewClassificationRules 根据 classification 配置创建分类规则
+// videoExts 为 download.video_extensions，为空时在默认视频扩展名基础上追加
+func NewClassificationRules(cfg config.ClassificationConfig, videoExts []string) *ClassificationRules {
+	if len(videoExts) == 0 {
+		videoExts = fileutil.DefaultVideoExtensions
+	}
+	return &ClassificationRules{
+		videoExts:     normalizeRuleValues(append(append([]string{}, videoExts...), cfg.ExtraVideoExts...), "."),
+		otherExts:     normalizeRuleValues(cfg.OtherExts, "."),
+		tvKeywords:    normalizeRuleValues(cfg.TVKeywords, ""),
+		movieKeywords: normalizeRuleValues(cfg.MovieKeywords, ""),
+	}
+}
+
+// IsOtherFile 扩展名是否配置为始终归为"其他"
+func (r *ClassificationRules) IsOtherFile(filename string) bool {
+	return r != nil && fileutil.HasExtension(filename, r.otherExts)
+}
+
+// IsVideoFile 检查是否为视频文件，配置为"其他"的扩展名不视为视频
+func (r *ClassificationRules) IsVideoFile(filename string) bool {
+	return !r.IsOtherFile(filename) && fileutil.HasExtension(filename, r.videoExts)
+}
+
+// MatchKeyword 按自定义关键词匹配分类（电视剧关键词优先于电影关键词），未命中时返回空字符串
+func (r *ClassificationRules) MatchKeyword(filename string) (category, keyword string) {
+	if r == nil {
+		return "", ""
+	}
+	filename = strings.ToLower(filename)
+	for _, keyword := range r.tvKeywords {
+		if strings.Contains(filename, keyword) {
+			return "tv", keyword
+		}
+	}
+	for _, keyword := range r.movieKeywords {
+		if strings.Contains(filename, keyword) {
+			return "movie", keyword
+		}
+	}
+	return "", ""
+}
+
+// normalizeRuleValues 去除空白和前缀（扩展名的点号）并转为小写，忽略空值和重复值
+func normalizeRuleValues(values []string, trimPrefix string) []string {
+	normalized := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if trimPrefix != "" {
+			value = strings.TrimPrefix(value, trimPrefix)
+		}
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+	return normalized
+}
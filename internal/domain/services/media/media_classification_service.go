@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
 	pathservices "github.com/easayliu/alist-aria2-download/internal/domain/services/path"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
@@ -11,10 +12,17 @@ import (
 	pathutil "github.com/easayliu/alist-aria2-download/pkg/utils/path"
 )
 
+// CategoryOverrides 用户纠正的分类查询接口
+type CategoryOverrides interface {
+	Match(filename string) (entities.CategoryOverride, bool)
+}
+
 // MediaClassificationService 媒体分类服务 - 专注于文件的媒体类型判断和分类
 type MediaClassificationService struct {
 	config       *config.Config
 	pathCategory *pathservices.PathCategoryService
+	overrides    CategoryOverrides    // 可为nil
+	rules        *ClassificationRules // 用户配置的分类规则，可为nil
 }
 
 // NewMediaClassificationService 创建媒体分类服务
@@ -25,15 +33,70 @@ func NewMediaClassificationService(cfg *config.Config, pathCategory *pathservice
 	}
 }
 
+// SetOverrides 设置用户纠正的分类，匹配时优先于关键词和路径分类
+func (s *MediaClassificationService) SetOverrides(overrides CategoryOverrides) {
+	s.overrides = overrides
+}
+
+// SetClassificationRules 设置用户配置的分类规则，优先于内置的扩展名和关键词规则
+func (s *MediaClassificationService) SetClassificationRules(rules *ClassificationRules) {
+	s.rules = rules
+}
+
+// OverrideFor 查找文件名对应的分类覆盖（仅视频文件）
+func (s *MediaClassificationService) OverrideFor(filename string) (entities.CategoryOverride, bool) {
+	if s.overrides == nil || !s.IsVideoFile(filename) {
+		return entities.CategoryOverride{}, false
+	}
+	return s.overrides.Match(filename)
+}
+
 // IsVideoFile 检查是否为视频文件
 func (s *MediaClassificationService) IsVideoFile(filename string) bool {
+	if s.rules != nil {
+		return s.rules.IsVideoFile(filename)
+	}
 	return fileutil.IsVideoFile(filename, s.config.Download.VideoExts)
 }
 
+// ExtensionCategory 按扩展名识别音乐（music）和文档（document）分类
+// 对应分类未在配置中启用或扩展名不匹配时返回空字符串
+func (s *MediaClassificationService) ExtensionCategory(filename string) string {
+	cfg := s.config.Download
+	switch {
+	case s.rules.IsOtherFile(filename):
+		return ""
+	case cfg.Music.Enabled && fileutil.HasExtension(filename, cfg.Music.Extensions):
+		return "music"
+	case cfg.Documents.Enabled && fileutil.HasExtension(filename, cfg.Documents.Extensions):
+		return "document"
+	default:
+		return ""
+	}
+}
+
 // GetFileCategory 获取文件分类（基于文件名）
 func (s *MediaClassificationService) GetFileCategory(filename string) string {
+	category, _ := s.MatchFileCategory(filename)
+	return category
+}
+
+// MatchFileCategory 基于文件名获取文件分类，同时返回命中的文件名关键词（非关键词规则命中时为空）
+func (s *MediaClassificationService) MatchFileCategory(filename string) (category, keyword string) {
 	if !s.IsVideoFile(filename) {
-		return "other"
+		if category := s.ExtensionCategory(filename); category != "" {
+			return category, ""
+		}
+		return "other", ""
+	}
+
+	if override, ok := s.OverrideFor(filename); ok {
+		return override.Category, ""
+	}
+
+	// 用户配置的关键词优先于内置关键词
+	if category, keyword := s.rules.MatchKeyword(filename); category != "" {
+		return category, keyword
 	}
 
 	filename = strings.ToLower(filename)
@@ -42,7 +105,7 @@ func (s *MediaClassificationService) GetFileCategory(filename string) string {
 	movieKeywords := []string{"movie", "film", "电影", "蓝光", "bluray", "bd", "4k", "1080p", "720p"}
 	for _, keyword := range movieKeywords {
 		if strings.Contains(filename, keyword) {
-			return "movie"
+			return "movie", keyword
 		}
 	}
 
@@ -50,7 +113,7 @@ func (s *MediaClassificationService) GetFileCategory(filename string) string {
 	tvKeywords := []string{"tv", "series", "episode", "ep", "s01", "s02", "s03", "season", "电视剧", "连续剧"}
 	for _, keyword := range tvKeywords {
 		if strings.Contains(filename, keyword) {
-			return "tv"
+			return "tv", keyword
 		}
 	}
 
@@ -58,16 +121,24 @@ func (s *MediaClassificationService) GetFileCategory(filename string) string {
 	varietyKeywords := []string{"variety", "show", "综艺", "娱乐"}
 	for _, keyword := range varietyKeywords {
 		if strings.Contains(filename, keyword) {
-			return "variety"
+			return "variety", keyword
 		}
 	}
 
-	return "video"
+	return "video", ""
 }
 
 // GetMediaType 获取媒体类型（用于统计）
 // 优先使用路径分类，回退到文件名分类
 func (s *MediaClassificationService) GetMediaType(filePath string) string {
+	if category := s.ExtensionCategory(pathutil.GetFileName(filePath)); category != "" {
+		return category
+	}
+
+	if override, ok := s.OverrideFor(pathutil.GetFileName(filePath)); ok {
+		return s.pathCategory.GetMediaType(override.Category)
+	}
+
 	// 使用路径分类服务
 	pathCategory := s.pathCategory.GetCategoryFromPath(filePath)
 
@@ -85,7 +156,14 @@ func (s *MediaClassificationService) GetMediaType(filePath string) string {
 // UpdateMediaStats 更新媒体统计
 func (s *MediaClassificationService) UpdateMediaStats(summary *contracts.FileSummary, filePath, filename string) {
 	if !s.IsVideoFile(filename) {
-		summary.OtherFiles++
+		switch s.ExtensionCategory(filename) {
+		case "music":
+			summary.MusicFiles++
+		case "document":
+			summary.DocumentFiles++
+		default:
+			summary.OtherFiles++
+		}
 		return
 	}
 
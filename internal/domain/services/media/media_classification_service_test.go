@@ -0,0 +1,82 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	pathservices "github.com/easayliu/alist-aria2-download/internal/domain/services/path"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+)
+
+func TestExtensionCategories(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Download.VideoExts = []string{"mkv"}
+	cfg.Download.Music = config.ExtensionCategoryConfig{Enabled: true, Extensions: []string{"flac", "mp3"}}
+	cfg.Download.Documents = config.ExtensionCategoryConfig{Extensions: []string{"pdf"}}
+	s := NewMediaClassificationService(cfg, pathservices.NewPathCategoryService())
+
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{name: "音乐（不区分大小写）", filename: "Album/01.FLAC", want: "music"},
+		{name: "文档分类未启用", filename: "manual.pdf", want: "other"},
+		{name: "未知扩展名", filename: "cover.jpg", want: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.GetFileCategory(tt.filename); got != tt.want {
+				t.Errorf("GetFileCategory(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+
+	var summary contracts.FileSummary
+	for _, path := range []string{"/tvs/Show/OST.mp3", "/tvs/Show/manual.pdf", "/tvs/Show/S01E01.mkv"} {
+		s.UpdateMediaStats(&summary, path, path[len("/tvs/Show/"):])
+	}
+	if summary.MusicFiles != 1 || summary.DocumentFiles != 0 || summary.OtherFiles != 1 || summary.TVFiles != 1 {
+		t.Errorf("summary = %+v, want 1 music, 1 other, 1 tv", summary)
+	}
+}
+
+func TestClassificationRules(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Classification = config.ClassificationConfig{
+		ExtraVideoExts: []string{".STRM"},
+		OtherExts:      []string{"ts"},
+		TVKeywords:     []string{"Anime"},
+		MovieKeywords:  []string{"剧场版"},
+	}
+	cfg.Download.Music = config.ExtensionCategoryConfig{Enabled: true, Extensions: []string{"mp3"}}
+	s := NewMediaClassificationService(cfg, pathservices.NewPathCategoryService())
+	s.SetClassificationRules(NewClassificationRules(cfg.Classification, cfg.Download.VideoExts))
+
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{name: "追加的视频扩展名", filename: "Some.Clip.strm", want: "video"},
+		{name: "默认视频扩展名仍然生效", filename: "Some.Clip.mkv", want: "video"},
+		{name: "其他扩展名覆盖默认视频扩展名", filename: "index.ts", want: "other"},
+		{name: "自定义电视剧关键词优先于内置电影关键词", filename: "Anime.Show.1080p.mkv", want: "tv"},
+		{name: "自定义电影关键词优先于内置电视剧关键词", filename: "名侦探柯南 剧场版 EP01.mkv", want: "movie"},
+		{name: "未命中自定义关键词时使用内置关键词", filename: "Show.S01E01.mkv", want: "tv"},
+		{name: "不影响音乐分类", filename: "OST.mp3", want: "music"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.GetFileCategory(tt.filename); got != tt.want {
+				t.Errorf("GetFileCategory(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+
+	// 配置了 download.video_extensions 时在其基础上追加
+	rules := NewClassificationRules(cfg.Classification, []string{"mkv"})
+	if !rules.IsVideoFile("a.strm") || !rules.IsVideoFile("a.MKV") || rules.IsVideoFile("a.mp4") {
+		t.Errorf("extra video extensions should extend download.video_extensions only")
+	}
+}
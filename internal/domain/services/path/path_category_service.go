@@ -30,52 +30,54 @@ func (s *PathCategoryService) GetCategoryFromPath(path string) string {
 		return cached.(string)
 	}
 
-	// 获取小写路径（使用缓存）
-	pathLower := s.getPathLower(path)
+	// 获取小写路径（使用缓存）并计算分类结果
+	category, _ := s.computeCategory(s.getPathLower(path))
 
+	// 缓存结果（只缓存有效分类）
+	if category != "" {
+		s.categoryCache.Store(path, category)
+	}
+
+	return category
+}
+
+// MatchCategoryFromPath 从路径中分析文件类型，同时返回命中的路径关键词（不使用缓存，用于规则测试）
+func (s *PathCategoryService) MatchCategoryFromPath(path string) (category, keyword string) {
+	if path == "" {
+		return "", ""
+	}
+	return s.computeCategory(strings.ToLower(path))
+}
+
+// computeCategory 计算路径分类，返回分类和命中的关键词（内部方法）
+func (s *PathCategoryService) computeCategory(pathLower string) (string, string) {
 	// 检查 TVs 和 Movies 的位置，选择最早出现的
 	tvsIndex := strings.Index(pathLower, "tvs")
 	moviesIndex := strings.Index(pathLower, "movies")
 
 	// 如果两个都存在，选择最早出现的（路径层级更高的）
 	if tvsIndex != -1 && moviesIndex != -1 {
-		category := "tv"
 		if tvsIndex > moviesIndex {
-			category = "movie"
+			return "movie", "movies"
 		}
-		// 缓存并返回
-		s.categoryCache.Store(path, category)
-		return category
-	}
-
-	// 计算分类结果
-	category := s.computeCategory(pathLower, tvsIndex, moviesIndex)
-
-	// 缓存结果（只缓存有效分类）
-	if category != "" {
-		s.categoryCache.Store(path, category)
+		return "tv", "tvs"
 	}
 
-	return category
-}
-
-// computeCategory 计算路径分类（内部方法）
-func (s *PathCategoryService) computeCategory(pathLower string, tvsIndex, moviesIndex int) string {
 	// 简化的 TVs 判断：只要路径包含 tvs 就判断为 tv
 	if tvsIndex != -1 {
-		return "tv"
+		return "tv", "tvs"
 	}
 
 	// 简化的 Movies 判断：只要路径包含 movies 就判断为 movie
 	if moviesIndex != -1 {
-		return "movie"
+		return "movie", "movies"
 	}
 
 	// 综艺类型指示器
 	varietyPathKeywords := []string{"/variety/", "/show/", "/综艺/", "/娱乐/"}
 	for _, keyword := range varietyPathKeywords {
 		if strings.Contains(pathLower, keyword) {
-			return "variety"
+			return "variety", keyword
 		}
 	}
 
@@ -83,12 +85,12 @@ func (s *PathCategoryService) computeCategory(pathLower string, tvsIndex, movies
 	videoPathKeywords := []string{"/videos/", "/video/", "/视频/"}
 	for _, keyword := range videoPathKeywords {
 		if strings.Contains(pathLower, keyword) {
-			return "video"
+			return "video", keyword
 		}
 	}
 
 	// 如果路径中没有明确的类型指示器，返回空字符串
-	return ""
+	return "", ""
 }
 
 // getPathLower 获取小写路径（带缓存）
@@ -41,6 +41,7 @@ type LoginResponse struct {
 }
 
 // NewClient 创建新的Alist客户端
+// httpClient 不设置自定义 Transport，压缩响应由 httputil.DoJSONRequest 声明 Accept-Encoding 并解压
 func NewClient(baseURL, username, password string) *Client {
 	return &Client{
 		BaseURL:  baseURL,
@@ -153,6 +154,17 @@ func (c *Client) ensureValidToken(ctx context.Context) error {
 	return c.LoginWithContext(ctx)
 }
 
+// GetValidToken 获取有效token（必要时自动登录），用于需要鉴权的直链下载
+func (c *Client) GetValidToken(ctx context.Context) (string, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return "", err
+	}
+
+	c.tokenMutex.RLock()
+	defer c.tokenMutex.RUnlock()
+	return c.Token, nil
+}
+
 // ClearToken 清除当前token，强制下次请求重新登录
 func (c *Client) ClearToken() {
 	c.tokenMutex.Lock()
@@ -0,0 +1,74 @@
+package alist
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestListFiles_CompressedResponse 测试压缩的目录列表响应能被正确解压
+func TestListFiles_CompressedResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		compress func(w io.Writer) io.WriteCloser
+	}{
+		{
+			name:     "gzip",
+			encoding: "gzip",
+			compress: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		},
+		{
+			name:     "deflate",
+			encoding: "deflate",
+			compress: func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var resp any
+				switch r.URL.Path {
+				case "/api/auth/login":
+					resp = map[string]any{"code": 200, "data": map[string]any{"token": "test-token"}}
+				case "/api/fs/list":
+					if !strings.Contains(r.Header.Get("Accept-Encoding"), tt.encoding) {
+						t.Errorf("Accept-Encoding = %q, want it to contain %q", r.Header.Get("Accept-Encoding"), tt.encoding)
+					}
+					resp = map[string]any{
+						"code": 200,
+						"data": map[string]any{
+							"content": []map[string]any{{"name": "movie.mkv", "size": 1024}},
+							"total":   1,
+						},
+					}
+				default:
+					http.NotFound(w, r)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", tt.encoding)
+				cw := tt.compress(w)
+				_ = json.NewEncoder(cw).Encode(resp)
+				_ = cw.Close()
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "user", "pass")
+			listResp, err := client.ListFiles("/", 1, 100)
+			if err != nil {
+				t.Fatalf("ListFiles() error = %v", err)
+			}
+			if listResp.Data.Total != 1 || len(listResp.Data.Content) != 1 || listResp.Data.Content[0].Name != "movie.mkv" {
+				t.Errorf("ListFiles() = %+v, want one file movie.mkv", listResp.Data)
+			}
+		})
+	}
+}
@@ -25,6 +25,16 @@ type FileListResponse struct {
 	} `json:"data"`
 }
 
+// Alist 文件类型（FileItem.Type）
+const (
+	FileTypeUnknown = 0
+	FileTypeFolder  = 1
+	FileTypeVideo   = 2
+	FileTypeAudio   = 3
+	FileTypeText    = 4
+	FileTypeImage   = 5
+)
+
 // FileItem 文件项
 type FileItem struct {
 	ID        string      `json:"id"`
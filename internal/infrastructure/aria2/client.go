@@ -2,8 +2,10 @@ package aria2
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
@@ -59,17 +61,56 @@ type StatusResult struct {
 	TotalLength     string `json:"totalLength"`
 	CompletedLength string `json:"completedLength"`
 	DownloadSpeed   string `json:"downloadSpeed"`
+	UploadSpeed     string `json:"uploadSpeed,omitempty"`
+	Connections     string `json:"connections,omitempty"`
+	NumPieces       string `json:"numPieces,omitempty"`
+	PieceLength     string `json:"pieceLength,omitempty"`
+	Bitfield        string `json:"bitfield,omitempty"` // 十六进制，每一位表示一个分片是否已完成
 	ErrorCode       string `json:"errorCode,omitempty"`
 	ErrorMessage    string `json:"errorMessage,omitempty"`
+	Dir             string `json:"dir,omitempty"`
+	InfoHash        string `json:"infoHash,omitempty"` // 仅 BT 任务有
 	Files           []struct {
-		Path string `json:"path"`
-		URI  []struct {
+		Index           string `json:"index"`
+		Path            string `json:"path"`
+		Length          string `json:"length"`
+		CompletedLength string `json:"completedLength"`
+		Selected        string `json:"selected"`
+		URI             []struct {
 			URI    string `json:"uri"`
 			Status string `json:"status"`
 		} `json:"uris"`
 	} `json:"files,omitempty"`
 }
 
+// ErrUnauthorized aria2 拒绝了 RPC 密钥（token 与 aria2 的 --rpc-secret 不一致）
+var ErrUnauthorized = errors.New("aria2 RPC unauthorized")
+
+// unauthorizedMessage aria2 密钥错误时返回的错误信息，部分版本同时返回 HTTP 400
+const unauthorizedMessage = "Unauthorized"
+
+// ErrGIDNotFound 任务不存在（GID 无效或结果已被清除）
+var ErrGIDNotFound = errors.New("gid not found")
+
+// isGIDNotFoundError aria2 对未知 GID 返回 "GID xxx is not found"，格式错误的 GID 返回 "Invalid GID xxx"
+func isGIDNotFoundError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "is not found") || strings.Contains(msg, "Invalid GID")
+}
+
+// errorCodeNotEnoughDiskSpace aria2 错误码 9：磁盘空间不足
+const errorCodeNotEnoughDiskSpace = "9"
+
+// IsDiskFullError 判断任务是否因磁盘空间不足失败：aria2 错误码 9，
+// 或下载过程中写文件报 ENOSPC（此时错误码为通用的文件 I/O 错误，只能匹配错误信息）
+func IsDiskFullError(errorCode, errorMessage string) bool {
+	if errorCode == errorCodeNotEnoughDiskSpace {
+		return true
+	}
+	msg := strings.ToLower(errorMessage)
+	return strings.Contains(msg, "no space left") || strings.Contains(msg, "not enough disk space")
+}
+
 // VersionResult 版本信息结果
 type VersionResult struct {
 	Version  string   `json:"version"`
@@ -79,10 +120,19 @@ type VersionResult struct {
 // callRPC 调用RPC方法
 func (c *Client) callRPC(method string, params []interface{}) (*RPCResponse, error) {
 	// 如果有token，添加到参数前面
-	if c.Token != "" {
-		params = append([]interface{}{"token:" + c.Token}, params...)
+	return c.sendRPC(method, c.withToken(params))
+}
+
+// withToken 有token时添加到参数前面
+func (c *Client) withToken(params []interface{}) []interface{} {
+	if c.Token == "" {
+		return params
 	}
+	return append([]interface{}{"token:" + c.Token}, params...)
+}
 
+// sendRPC 发送RPC请求，params 需已包含 token
+func (c *Client) sendRPC(method string, params []interface{}) (*RPCResponse, error) {
 	request := RPCRequest{
 		Version: "2.0",
 		Method:  method,
@@ -96,10 +146,17 @@ func (c *Client) callRPC(method string, params []interface{}) (*RPCResponse, err
 
 	var rpcResp RPCResponse
 	if err := httputil.PostJSON(c.RpcURL, request, &rpcResp, opts); err != nil {
+		var statusErr *httputil.StatusError
+		if errors.As(err, &statusErr) && strings.Contains(statusErr.Body, unauthorizedMessage) {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+		}
 		return nil, fmt.Errorf("failed to send RPC request: %w", err)
 	}
 
 	if rpcResp.Error != nil {
+		if rpcResp.Error.Message == unauthorizedMessage {
+			return nil, fmt.Errorf("%w (code: %d)", ErrUnauthorized, rpcResp.Error.Code)
+		}
 		return nil, fmt.Errorf("RPC error: %s (code: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
 	}
 
@@ -144,12 +201,15 @@ func (c *Client) AddURIs(uris []string, options map[string]interface{}) ([]strin
 	return gids, nil
 }
 
-// GetStatus 获取下载状态
+// GetStatus 获取下载状态，GID 不存在时返回 ErrGIDNotFound
 func (c *Client) GetStatus(gid string) (*StatusResult, error) {
 	params := []interface{}{gid}
 
 	resp, err := c.callRPC("aria2.tellStatus", params)
 	if err != nil {
+		if isGIDNotFoundError(err) {
+			return nil, fmt.Errorf("%w: %s", ErrGIDNotFound, gid)
+		}
 		return nil, err
 	}
 
@@ -179,21 +239,143 @@ func (c *Client) GetGlobalStat() (map[string]interface{}, error) {
 // Pause 暂停下载
 func (c *Client) Pause(gid string) error {
 	_, err := c.callRPC("aria2.pause", []interface{}{gid})
+	if err != nil && isGIDNotFoundError(err) {
+		return fmt.Errorf("%w: %s", ErrGIDNotFound, gid)
+	}
 	return err
 }
 
 // Resume 恢复下载
 func (c *Client) Resume(gid string) error {
 	_, err := c.callRPC("aria2.unpause", []interface{}{gid})
+	if err != nil && isGIDNotFoundError(err) {
+		return fmt.Errorf("%w: %s", ErrGIDNotFound, gid)
+	}
 	return err
 }
 
-// Remove 删除下载
+// Remove 删除下载，GID 不存在时返回 ErrGIDNotFound
 func (c *Client) Remove(gid string) error {
 	_, err := c.callRPC("aria2.remove", []interface{}{gid})
+	if err != nil && isGIDNotFoundError(err) {
+		return fmt.Errorf("%w: %s", ErrGIDNotFound, gid)
+	}
+	return err
+}
+
+// RemoveDownloadResult 从内存中移除已结束（完成/错误/已删除）的任务记录
+func (c *Client) RemoveDownloadResult(gid string) error {
+	_, err := c.callRPC("aria2.removeDownloadResult", []interface{}{gid})
+	return err
+}
+
+// ChangeOption 修改任务的选项（如 select-file），修改进行中的任务可能导致其重新开始
+func (c *Client) ChangeOption(gid string, options map[string]interface{}) error {
+	_, err := c.callRPC("aria2.changeOption", []interface{}{gid, options})
 	return err
 }
 
+// GetOption 获取任务的选项（如 split、max-connection-per-server），值均为字符串
+func (c *Client) GetOption(gid string) (map[string]string, error) {
+	resp, err := c.callRPC("aria2.getOption", []interface{}{gid})
+	if err != nil {
+		if isGIDNotFoundError(err) {
+			return nil, fmt.Errorf("%w: %s", ErrGIDNotFound, gid)
+		}
+		return nil, err
+	}
+
+	var options map[string]string
+	if err := json.Unmarshal(resp.Result, &options); err != nil {
+		return nil, fmt.Errorf("failed to parse options: %w", err)
+	}
+	return options, nil
+}
+
+// GetOptions 通过 system.multicall 一次读取多个任务的选项，按 GID 返回；
+// 单个任务读取失败（如已结束被清除）时不出现在结果中
+func (c *Client) GetOptions(gids []string) (map[string]map[string]string, error) {
+	if len(gids) == 0 {
+		return map[string]map[string]string{}, nil
+	}
+
+	calls := make([]map[string]interface{}, len(gids))
+	for i, gid := range gids {
+		calls[i] = map[string]interface{}{
+			"methodName": "aria2.getOption",
+			"params":     c.withToken([]interface{}{gid}),
+		}
+	}
+	// system.multicall 本身不需要 token，token 放在每个子调用的参数中
+	resp, err := c.sendRPC("system.multicall", []interface{}{calls})
+	if err != nil {
+		return nil, err
+	}
+
+	// 成功的子调用结果包装为单元素数组，失败的为 {code, message} 对象
+	var results []json.RawMessage
+	if err := json.Unmarshal(resp.Result, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse multicall result: %w", err)
+	}
+	options := make(map[string]map[string]string, len(gids))
+	for i, raw := range results {
+		if i >= len(gids) {
+			break
+		}
+		var wrapped []map[string]string
+		if err := json.Unmarshal(raw, &wrapped); err != nil || len(wrapped) == 0 {
+			continue
+		}
+		options[gids[i]] = wrapped[0]
+	}
+	return options, nil
+}
+
+// 队列位置调整方式（aria2.changePosition 的 how 参数）
+const (
+	PositionSet = "POS_SET" // 相对队列开头
+	PositionCur = "POS_CUR" // 相对当前位置
+	PositionEnd = "POS_END" // 相对队列末尾
+)
+
+// ErrNotWaiting 任务不在等待队列中（下载中、已结束或不存在），无法调整队列位置
+var ErrNotWaiting = errors.New("gid not in waiting queue")
+
+// ChangePosition 调整等待中任务在队列中的位置，返回调整后的位置（0 为队首）
+// 任务不在等待队列中时 aria2 返回 "GID#xxx not found in the waiting queue."，包装为 ErrNotWaiting
+func (c *Client) ChangePosition(gid string, pos int, how string) (int, error) {
+	resp, err := c.callRPC("aria2.changePosition", []interface{}{gid, pos, how})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found in the waiting queue") {
+			return 0, fmt.Errorf("%w: %v", ErrNotWaiting, err)
+		}
+		if isGIDNotFoundError(err) {
+			return 0, fmt.Errorf("%w: %s", ErrGIDNotFound, gid)
+		}
+		return 0, err
+	}
+
+	var newPos int
+	if err := json.Unmarshal(resp.Result, &newPos); err != nil {
+		return 0, fmt.Errorf("failed to parse position: %w", err)
+	}
+	return newPos, nil
+}
+
+// GetGlobalOption 获取全局选项（如 max-concurrent-downloads）
+func (c *Client) GetGlobalOption() (map[string]string, error) {
+	resp, err := c.callRPC("aria2.getGlobalOption", []interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	var options map[string]string
+	if err := json.Unmarshal(resp.Result, &options); err != nil {
+		return nil, fmt.Errorf("failed to parse global options: %w", err)
+	}
+	return options, nil
+}
+
 // GetVersion 获取Aria2版本信息
 func (c *Client) GetVersion() (*VersionResult, error) {
 	resp, err := c.callRPC("aria2.getVersion", []interface{}{})
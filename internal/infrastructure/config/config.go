@@ -2,6 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,16 +17,74 @@ type Config struct {
 	Aria2     Aria2Config     `mapstructure:"aria2"`
 	Alist     AlistConfig     `mapstructure:"alist"`
 	Telegram  TelegramConfig  `mapstructure:"telegram"`
+	Email     EmailConfig     `mapstructure:"email"`
 	Download  DownloadConfig  `mapstructure:"download"`
 	Scheduler SchedulerConfig `mapstructure:"scheduler"`
 	TMDB      TMDBConfig      `mapstructure:"tmdb"`
 	LLM       LLMConfig       `mapstructure:"llm"`
+	// Classification 自定义文件分类规则（可选），优先于内置的扩展名和关键词规则
+	Classification ClassificationConfig `mapstructure:"classification"`
 }
 
 type ServerConfig struct {
-	Host string `mapstructure:"host"`
-	Port string `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Host        string            `mapstructure:"host"`
+	Port        string            `mapstructure:"port"`
+	Mode        string            `mapstructure:"mode"`
+	IPAllowlist IPAllowlistConfig `mapstructure:"ip_allowlist"` // 只允许指定网段访问 HTTP 接口
+	BasicAuth   BasicAuthConfig   `mapstructure:"basic_auth"`   // HTTP Basic 认证
+	// ReadOnly 只读模式：禁用删除、移动、重命名和下载后删除，浏览、查询、链接等功能不受影响
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+// IPAllowlistConfig HTTP 接口 IP 白名单配置（健康检查除外）
+type IPAllowlistConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	CIDRs   []string `mapstructure:"cidrs"` // 允许的网段，如 192.168.1.0/24，也可以是单个 IP
+}
+
+// Prefixes 解析白名单网段，单个 IP 视为只包含该地址的网段
+func (cfg *IPAllowlistConfig) Prefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cfg.CIDRs))
+	for _, cidr := range cfg.CIDRs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("server.ip_allowlist.cidrs 包含无效地址: %s", cidr)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("server.ip_allowlist.cidrs 包含无效网段: %s", cidr)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// BasicAuthConfig HTTP Basic 认证配置（健康检查除外）
+type BasicAuthConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// Validate 验证 HTTP 服务访问控制配置
+func (cfg *ServerConfig) Validate() error {
+	if cfg.IPAllowlist.Enabled {
+		if len(cfg.IPAllowlist.CIDRs) == 0 {
+			return fmt.Errorf("启用 server.ip_allowlist 时必须配置 cidrs")
+		}
+		if _, err := cfg.IPAllowlist.Prefixes(); err != nil {
+			return err
+		}
+	}
+	if cfg.BasicAuth.Enabled && (cfg.BasicAuth.Username == "" || cfg.BasicAuth.Password == "") {
+		return fmt.Errorf("启用 server.basic_auth 时必须配置 username 和 password")
+	}
+	return nil
 }
 
 type LogConfig struct {
@@ -37,6 +100,17 @@ type Aria2Config struct {
 	RpcURL      string `mapstructure:"rpc_url"`
 	Token       string `mapstructure:"token"`
 	DownloadDir string `mapstructure:"download_dir"`
+
+	// BatchChunkSize 目录下载文件数超过该值时分批提交，下载队列低于同时下载数后再提交下一批，0表示不分批
+	BatchChunkSize int `mapstructure:"batch_chunk_size"`
+}
+
+// Validate 验证 aria2 配置
+func (cfg *Aria2Config) Validate() error {
+	if cfg.BatchChunkSize < 0 {
+		return fmt.Errorf("aria2.batch_chunk_size 不能为负数: %d", cfg.BatchChunkSize)
+	}
+	return nil
 }
 
 type AlistConfig struct {
@@ -46,6 +120,76 @@ type AlistConfig struct {
 	Password    string `mapstructure:"password"`
 	DefaultPath string `mapstructure:"default_path"`
 	QPS         int    `mapstructure:"qps"` // 每秒请求数限制，默认50
+
+	// DefaultVideoOnly /download 按时间范围手动下载时默认只包含视频文件，命令中加 --all 可包含所有文件
+	DefaultVideoOnly bool `mapstructure:"default_video_only"`
+
+	// ShowHiddenFiles Telegram 文件浏览默认是否显示隐藏文件（名称以 . 开头），可在浏览界面按会话切换
+	ShowHiddenFiles bool `mapstructure:"show_hidden_files"`
+
+	// GroupBrowse Telegram 文件浏览默认是否按类型分组显示（目录/电影/剧集/其他），可在浏览界面按会话切换
+	GroupBrowse bool `mapstructure:"group_browse"`
+
+	// ArchiveDownloadPath 归档下载根目录，修改时间早于 ArchiveAfterDays 的文件下载到此处
+	ArchiveDownloadPath string `mapstructure:"archive_download_path"`
+	// ArchiveAfterDays 归档阈值（天），0表示不启用
+	ArchiveAfterDays int `mapstructure:"archive_after_days"`
+
+	// GenerateNFO 电影/剧集下载完成后在媒体文件旁生成 Emby/Jellyfin 兼容的 .nfo 元数据文件
+	GenerateNFO bool `mapstructure:"generate_nfo"`
+
+	// ProbeMediaInfo 文件详情中显示视频时长和分辨率（读取文件头部，必要时调用 ffprobe）
+	ProbeMediaInfo bool `mapstructure:"probe_media_info"`
+
+	// ZipDownloadURL 目录打包下载地址模板，{path} 替换为 URL 编码的目录路径；为空表示部署不支持打包下载
+	ZipDownloadURL string `mapstructure:"zip_download_url"`
+
+	// Roots Telegram 文件浏览的多个存储根目录（如电影、剧集、音乐分别挂载），配置后文件菜单先选择根目录
+	Roots []AlistRoot `mapstructure:"roots"`
+}
+
+// AlistRoot 文件浏览的存储根目录，浏览时不会返回到根目录之外
+type AlistRoot struct {
+	Label string `mapstructure:"label"` // 显示名称，不能重复
+	Path  string `mapstructure:"path"`  // Alist 中的绝对路径
+}
+
+// FindRoot 按显示名称查找存储根目录
+func (cfg *AlistConfig) FindRoot(label string) (AlistRoot, bool) {
+	for _, root := range cfg.Roots {
+		if root.Label == label {
+			return root, true
+		}
+	}
+	return AlistRoot{}, false
+}
+
+// Validate 验证 Alist 配置
+func (cfg *AlistConfig) Validate() error {
+	labels := make(map[string]bool, len(cfg.Roots))
+	for i, root := range cfg.Roots {
+		if strings.TrimSpace(root.Label) == "" {
+			return fmt.Errorf("alist.roots[%d].label 不能为空", i)
+		}
+		if labels[root.Label] {
+			return fmt.Errorf("alist.roots 中的名称重复: %s", root.Label)
+		}
+		labels[root.Label] = true
+		if !strings.HasPrefix(root.Path, "/") {
+			return fmt.Errorf("alist.roots[%d].path 必须是以 / 开头的绝对路径: %q", i, root.Path)
+		}
+	}
+
+	if cfg.ZipDownloadURL == "" {
+		return nil
+	}
+	if !strings.Contains(cfg.ZipDownloadURL, "{path}") {
+		return fmt.Errorf("alist.zip_download_url 必须包含 {path} 占位符: %s", cfg.ZipDownloadURL)
+	}
+	if !strings.HasPrefix(cfg.ZipDownloadURL, "http://") && !strings.HasPrefix(cfg.ZipDownloadURL, "https://") {
+		return fmt.Errorf("alist.zip_download_url 必须是 http(s) 地址: %s", cfg.ZipDownloadURL)
+	}
+	return nil
 }
 
 type TelegramConfig struct {
@@ -54,12 +198,202 @@ type TelegramConfig struct {
 	Enabled  bool          `mapstructure:"enabled"`
 	AdminIDs []int64       `mapstructure:"admin_ids"`
 	Webhook  WebhookConfig `mapstructure:"webhook"`
+	Polling  PollingConfig `mapstructure:"polling"`
+	// CommandPrefix 命令前缀（如 "dl_" 则命令为 /dl_list），用于避免群组中与其他机器人的命令冲突
+	CommandPrefix string `mapstructure:"command_prefix"`
+	// SendRate 消息发送速率限制，避免批量操作触发 Telegram 429
+	SendRate SendRateConfig `mapstructure:"send_rate"`
+	// AutoDelete 消息自动删除时间，重要结果默认保留
+	AutoDelete AutoDeleteConfig `mapstructure:"auto_delete"`
+	// CallbackTTLMinutes 文件浏览等按钮中路径令牌的有效期（分钟），0表示不过期
+	CallbackTTLMinutes int `mapstructure:"callback_ttl_minutes"`
+	// WelcomeMessage /start 显示的欢迎语（HTML），为空时使用内置欢迎语
+	WelcomeMessage string `mapstructure:"welcome_message"`
+	// Shortcuts 自定义快捷按钮（回复键盘），为空时使用内置按钮
+	Shortcuts []ShortcutConfig `mapstructure:"shortcuts"`
+	// ProgressTracking 下载进度实时跟踪（定时编辑状态消息）
+	ProgressTracking ProgressTrackingConfig `mapstructure:"progress_tracking"`
+}
+
+// maxShortcuts 快捷按钮数量上限，避免回复键盘占满屏幕
+const maxShortcuts = 12
+
+// ShortcutConfig 快捷按钮：点击按钮等同于发送对应命令
+type ShortcutConfig struct {
+	Label   string `mapstructure:"label"`   // 按钮文字
+	Command string `mapstructure:"command"` // 执行的命令（含参数，不带 command_prefix），如 "/list /movies"
+}
+
+// commandPrefixPattern Telegram 命令只允许小写字母、数字和下划线
+var commandPrefixPattern = regexp.MustCompile(`^[a-z0-9_]*$`)
+
+// Validate 验证 Telegram 配置
+func (cfg *TelegramConfig) Validate() error {
+	if !commandPrefixPattern.MatchString(cfg.CommandPrefix) {
+		return fmt.Errorf("telegram.command_prefix 只能包含小写字母、数字和下划线: %s", cfg.CommandPrefix)
+	}
+	if err := cfg.SendRate.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.AutoDelete.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.ProgressTracking.Validate(); err != nil {
+		return err
+	}
+	if cfg.CallbackTTLMinutes < 0 {
+		return fmt.Errorf("telegram.callback_ttl_minutes 不能为负数: %d", cfg.CallbackTTLMinutes)
+	}
+	if err := validateShortcuts(cfg.Shortcuts); err != nil {
+		return err
+	}
+	if err := cfg.Webhook.Validate(); err != nil {
+		return err
+	}
+	return cfg.Polling.Validate()
+}
+
+// validateShortcuts 验证快捷按钮：按钮文字不能为空或重复，命令必须以 / 开头
+// 命令是否存在由 Telegram 模块在启动时检查
+func validateShortcuts(shortcuts []ShortcutConfig) error {
+	if len(shortcuts) > maxShortcuts {
+		return fmt.Errorf("telegram.shortcuts 最多 %d 个，当前 %d 个", maxShortcuts, len(shortcuts))
+	}
+	labels := make(map[string]bool, len(shortcuts))
+	for i, shortcut := range shortcuts {
+		label := strings.TrimSpace(shortcut.Label)
+		if label == "" {
+			return fmt.Errorf("telegram.shortcuts[%d] 缺少 label", i)
+		}
+		if labels[label] {
+			return fmt.Errorf("telegram.shortcuts 按钮文字重复: %s", label)
+		}
+		labels[label] = true
+		if !strings.HasPrefix(strings.TrimSpace(shortcut.Command), "/") {
+			return fmt.Errorf("telegram.shortcuts[%d] (%s) 的 command 必须以 / 开头: %q", i, label, shortcut.Command)
+		}
+	}
+	return nil
 }
 
 type WebhookConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
 	URL     string `mapstructure:"url"`
 	Port    string `mapstructure:"port"`
+	// SecretToken 设置 Webhook 时交给 Telegram，请求头 X-Telegram-Bot-Api-Secret-Token 不一致的请求将被拒绝，为空时不校验
+	SecretToken string `mapstructure:"secret_token"`
+}
+
+// webhookSecretPattern Telegram 要求 secret_token 为 1-256 个字母、数字、下划线或连字符
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Validate 验证 Webhook 配置
+func (cfg *WebhookConfig) Validate() error {
+	if cfg.SecretToken != "" && !webhookSecretPattern.MatchString(cfg.SecretToken) {
+		return fmt.Errorf("telegram.webhook.secret_token 只能包含字母、数字、下划线和连字符，长度 1-256")
+	}
+	return nil
+}
+
+// PollingConfig 轮询模式配置（getUpdates 参数）
+type PollingConfig struct {
+	Timeout int `mapstructure:"timeout"` // 长轮询超时时间(秒)，0表示短轮询
+	Limit   int `mapstructure:"limit"`   // 单次拉取的最大更新数
+}
+
+// Telegram getUpdates 参数允许范围
+const (
+	MaxPollingTimeout = 50
+	MinPollingLimit   = 1
+	MaxPollingLimit   = 100
+)
+
+// Validate 验证轮询配置是否在 Telegram 允许范围内
+func (cfg *PollingConfig) Validate() error {
+	if cfg.Timeout < 0 || cfg.Timeout > MaxPollingTimeout {
+		return fmt.Errorf("telegram.polling.timeout 必须在 0-%d 秒之间: %d", MaxPollingTimeout, cfg.Timeout)
+	}
+	if cfg.Limit < MinPollingLimit || cfg.Limit > MaxPollingLimit {
+		return fmt.Errorf("telegram.polling.limit 必须在 %d-%d 之间: %d", MinPollingLimit, MaxPollingLimit, cfg.Limit)
+	}
+	return nil
+}
+
+// SendRateConfig 消息发送速率配置（0表示不限制）
+type SendRateConfig struct {
+	GlobalPerSecond  int `mapstructure:"global_per_second"`   // 全局每秒最多发送条数
+	PerChatPerSecond int `mapstructure:"per_chat_per_second"` // 单个聊天每秒最多发送条数
+	PerChatBurst     int `mapstructure:"per_chat_burst"`      // 单个聊天允许的突发条数
+	MaxRetries       int `mapstructure:"max_retries"`         // 遇到 429 时的最大重试次数
+}
+
+// Validate 验证发送速率配置
+func (cfg *SendRateConfig) Validate() error {
+	if cfg.GlobalPerSecond < 0 || cfg.PerChatPerSecond < 0 || cfg.PerChatBurst < 0 || cfg.MaxRetries < 0 {
+		return fmt.Errorf("telegram.send_rate 配置不能为负数")
+	}
+	return nil
+}
+
+// AutoDeleteConfig 消息自动删除配置（0表示不删除）
+type AutoDeleteConfig struct {
+	TransientSeconds int  `mapstructure:"transient_seconds"` // "正在处理"、提示等临时消息的删除时间（秒）
+	ImportantSeconds int  `mapstructure:"important_seconds"` // 下载结果等重要消息的删除时间（秒），默认0即保留
+	ShowHint         bool `mapstructure:"show_hint"`         // 在会被删除的消息末尾提示剩余时间
+}
+
+// Validate 验证消息自动删除配置
+func (cfg *AutoDeleteConfig) Validate() error {
+	if cfg.TransientSeconds < 0 || cfg.ImportantSeconds < 0 {
+		return fmt.Errorf("telegram.auto_delete 配置不能为负数")
+	}
+	return nil
+}
+
+// ProgressTrackingConfig 下载进度实时跟踪配置
+type ProgressTrackingConfig struct {
+	IntervalSeconds int `mapstructure:"interval_seconds"` // 刷新间隔（秒），小于3秒按3秒处理，避免触发 Telegram 限流
+	TimeoutMinutes  int `mapstructure:"timeout_minutes"`  // 单次跟踪的最长时间（分钟），超时后停止刷新
+}
+
+// Validate 验证进度跟踪配置
+func (cfg *ProgressTrackingConfig) Validate() error {
+	if cfg.IntervalSeconds < 0 || cfg.TimeoutMinutes < 0 {
+		return fmt.Errorf("telegram.progress_tracking 配置不能为负数")
+	}
+	return nil
+}
+
+// EmailConfig 邮件通知配置（SMTP）
+type EmailConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`   // 是否启用邮件通知
+	SMTPHost string   `mapstructure:"smtp_host"` // SMTP服务器地址
+	SMTPPort int      `mapstructure:"smtp_port"` // SMTP端口，465使用隐式TLS，其他端口支持STARTTLS
+	Username string   `mapstructure:"username"`  // SMTP用户名
+	Password string   `mapstructure:"password"`  // SMTP密码或授权码
+	From     string   `mapstructure:"from"`      // 发件人地址，为空时使用用户名
+	To       []string `mapstructure:"to"`        // 收件人列表
+	Timeout  int      `mapstructure:"timeout"`   // 发送超时(秒)
+}
+
+// Validate 验证邮件配置
+func (cfg *EmailConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.SMTPHost == "" {
+		return fmt.Errorf("email.smtp_host 未配置")
+	}
+	if cfg.SMTPPort <= 0 {
+		return fmt.Errorf("email.smtp_port 无效: %d", cfg.SMTPPort)
+	}
+	if cfg.From == "" && cfg.Username == "" {
+		return fmt.Errorf("email.from 未配置")
+	}
+	if len(cfg.To) == 0 {
+		return fmt.Errorf("email.to 未配置收件人")
+	}
+	return nil
 }
 
 type DownloadConfig struct {
@@ -69,13 +403,136 @@ type DownloadConfig struct {
 	MinFileSize int64      `mapstructure:"min_file_size_mb"`
 	MaxFileSize int64      `mapstructure:"max_file_size_mb"`
 	PathConfig  PathConfig `mapstructure:"path_config"` // 路径配置
+	// AllowDeleteAfterDownload 是否允许“下载完成后删除 Alist 源文件”（破坏性操作，需显式开启）
+	AllowDeleteAfterDownload bool            `mapstructure:"allow_delete_after_download"`
+	Bandwidth                BandwidthConfig `mapstructure:"bandwidth"`  // 带宽采样配置
+	DiskGuard                DiskGuardConfig `mapstructure:"disk_guard"` // 磁盘空间保护
+	// JumpQueuePauseOthers "立即下载"时若同时下载数已满，暂停剩余最多的活动任务以腾出槽位（默认只移到队首）
+	JumpQueuePauseOthers bool `mapstructure:"jump_queue_pause_others"`
+	// Boost 任务详情中"加速"按钮提高连接数和分段数的上限
+	Boost BoostConfig `mapstructure:"boost"`
+	// CategoryLimits 按分类的同时下载数上限（如 movie: 2、tv: 4），超出的任务暂停等待，未配置的分类不限制
+	CategoryLimits map[string]int `mapstructure:"category_limits"`
+	// UserPaths 按 Telegram 用户配置的专属下载基础目录，未配置的用户使用 aria2.download_dir
+	UserPaths []UserDownloadPath `mapstructure:"user_paths"`
+	// BatchRetryWindowHours 批量下载创建后允许"重试全部失败"的时长（小时）
+	BatchRetryWindowHours int `mapstructure:"batch_retry_window_hours"`
+	// Music/Documents 按扩展名识别的音乐和文档分类（默认关闭，未开启时归为"其他"）
+	Music     ExtensionCategoryConfig `mapstructure:"music"`
+	Documents ExtensionCategoryConfig `mapstructure:"documents"`
+}
+
+// ExtensionCategoryConfig 按扩展名识别的非视频分类配置
+type ExtensionCategoryConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`    // 是否启用该分类
+	Extensions []string `mapstructure:"extensions"` // 扩展名列表（不带点号，不区分大小写）
+	Path       string   `mapstructure:"path"`       // 下载目录，相对路径基于下载根目录（aria2.download_dir）
+}
+
+// ClassificationConfig 自定义文件分类规则
+type ClassificationConfig struct {
+	ExtraVideoExts []string `mapstructure:"extra_video_extensions"` // 追加的视频扩展名（在 download.video_extensions 或默认列表基础上）
+	OtherExts      []string `mapstructure:"other_extensions"`       // 始终归为"其他"的扩展名，优先于视频、音乐和文档识别
+	TVKeywords     []string `mapstructure:"tv_keywords"`            // 文件名包含任一关键词时归为电视剧（不区分大小写）
+	MovieKeywords  []string `mapstructure:"movie_keywords"`         // 文件名包含任一关键词时归为电影，电视剧关键词优先
+}
+
+// LimitableCategories 可配置同时下载数上限的分类（与自动分类的下载目录对应）
+var LimitableCategories = []string{"movie", "tv", "variety", "video", "music", "document", "other"}
+
+// BoostConfig "加速"的上限：每次翻倍当前任务的 max-connection-per-server 和 split，不超过这里的值
+type BoostConfig struct {
+	MaxConnections int `mapstructure:"max_connections"` // 每服务器最大连接数上限（aria2 允许 1-16），0使用默认值16
+	MaxSplit       int `mapstructure:"max_split"`       // 分段数上限，0使用默认值32
+}
+
+// UserDownloadPath 用户专属下载基础目录
+type UserDownloadPath struct {
+	UserID   int64  `mapstructure:"user_id"`
+	BasePath string `mapstructure:"base_path"`
+}
+
+// Validate 验证下载配置
+func (cfg *DownloadConfig) Validate() error {
+	if cfg.Boost.MaxConnections < 0 || cfg.Boost.MaxConnections > 16 {
+		return fmt.Errorf("download.boost.max_connections 必须在 1-16 之间: %d", cfg.Boost.MaxConnections)
+	}
+	if cfg.Boost.MaxSplit < 0 {
+		return fmt.Errorf("download.boost.max_split 不能为负数: %d", cfg.Boost.MaxSplit)
+	}
+	for category, limit := range cfg.CategoryLimits {
+		if !slices.Contains(LimitableCategories, category) {
+			return fmt.Errorf("download.category_limits 中的分类 %q 无效，可选: %s", category, strings.Join(LimitableCategories, ", "))
+		}
+		if limit < 0 {
+			return fmt.Errorf("download.category_limits.%s 不能为负数: %d", category, limit)
+		}
+	}
+	seen := make(map[int64]bool, len(cfg.UserPaths))
+	for _, p := range cfg.UserPaths {
+		if p.UserID == 0 {
+			return fmt.Errorf("download.user_paths 中的 user_id 不能为空")
+		}
+		if !strings.HasPrefix(p.BasePath, "/") {
+			return fmt.Errorf("download.user_paths 中用户 %d 的 base_path 必须为绝对路径: %q", p.UserID, p.BasePath)
+		}
+		if seen[p.UserID] {
+			return fmt.Errorf("download.user_paths 中用户 %d 重复配置", p.UserID)
+		}
+		seen[p.UserID] = true
+	}
+	if policy := cfg.PathConfig.ConflictPolicy; policy != "" && !slices.Contains(ConflictPolicies, policy) {
+		return fmt.Errorf("download.path_config.conflict_policy 无效: %q，可选: %s", policy, strings.Join(ConflictPolicies, ", "))
+	}
+	if cfg.Music.Enabled && len(cfg.Music.Extensions) == 0 {
+		return fmt.Errorf("download.music 已启用但 extensions 为空")
+	}
+	if cfg.Documents.Enabled && len(cfg.Documents.Extensions) == 0 {
+		return fmt.Errorf("download.documents 已启用但 extensions 为空")
+	}
+	if cfg.DiskGuard.MinFreeGB < 0 || cfg.DiskGuard.CheckInterval < 0 || cfg.DiskGuard.WarnFreeGB < 0 {
+		return fmt.Errorf("download.disk_guard 配置不能为负数")
+	}
+	return nil
+}
+
+// UserBasePath 获取用户专属下载基础目录，未配置时返回空字符串
+func (cfg *DownloadConfig) UserBasePath(userID int64) string {
+	if userID == 0 {
+		return ""
+	}
+	for _, p := range cfg.UserPaths {
+		if p.UserID == userID {
+			return strings.TrimRight(p.BasePath, "/")
+		}
+	}
+	return ""
+}
+
+// BandwidthConfig 带宽采样配置
+type BandwidthConfig struct {
+	Enabled        bool `mapstructure:"enabled"`          // 是否启用采样
+	SampleInterval int  `mapstructure:"sample_interval"`  // 采样间隔(秒)
+	TypicalSpeedMB int  `mapstructure:"typical_speed_mb"` // 典型下载速度(MB/s)，当前无下载时用于估算耗时，0为不估算
+}
+
+// DiskGuardConfig 磁盘空间保护配置：可用空间低于阈值时自动暂停全部下载
+type DiskGuardConfig struct {
+	MinFreeGB     int `mapstructure:"min_free_gb"`    // 可用空间低于该值(GB)时暂停全部下载，0为不自动暂停
+	CheckInterval int `mapstructure:"check_interval"` // 检查间隔(秒)
+	WarnFreeGB    int `mapstructure:"warn_free_gb"`   // /diskspace 中可用空间低于该值(GB)时显示警告，0为不警告
 }
 
 // PathConfig 路径配置
 type PathConfig struct {
 	Templates PathTemplates `mapstructure:"templates"` // 路径模板
+	// ConflictPolicy 路径冲突策略：skip 跳过、rename 添加序号、overwrite 覆盖，默认 rename
+	ConflictPolicy string `mapstructure:"conflict_policy"`
 }
 
+// ConflictPolicies 可选的路径冲突策略
+var ConflictPolicies = []string{"skip", "rename", "overwrite"}
+
 // PathTemplates 路径模板配置
 type PathTemplates struct {
 	TV      string `mapstructure:"tv"`      // 电视剧路径模板
@@ -85,8 +542,44 @@ type PathTemplates struct {
 }
 
 type SchedulerConfig struct {
-	Enabled bool            `mapstructure:"enabled"`
-	Tasks   []ScheduledTask `mapstructure:"tasks"`
+	Enabled                bool            `mapstructure:"enabled"`
+	Timezone               string          `mapstructure:"timezone"`                 // IANA 时区名（如 Asia/Shanghai），为空使用系统时区
+	MaxConsecutiveFailures int             `mapstructure:"max_consecutive_failures"` // 连续失败达到该次数后自动停用任务，0 表示不停用
+	Tasks                  []ScheduledTask `mapstructure:"tasks"`
+	Cleanup                CleanupConfig   `mapstructure:"cleanup"` // 内部清理任务
+}
+
+// Validate 验证调度配置
+func (cfg *SchedulerConfig) Validate() error {
+	if cfg.MaxConsecutiveFailures < 0 {
+		return fmt.Errorf("scheduler.max_consecutive_failures 不能为负数: %d", cfg.MaxConsecutiveFailures)
+	}
+	if cfg.Timezone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(cfg.Timezone); err != nil {
+		return fmt.Errorf("scheduler.timezone 无效: %q: %w", cfg.Timezone, err)
+	}
+	return nil
+}
+
+// Location 获取调度使用的时区（定时任务触发和按天统计），未配置或无效时使用系统时区
+func (cfg *SchedulerConfig) Location() *time.Location {
+	if cfg.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// CleanupConfig 定期清理配置（aria2 已结束任务记录）
+type CleanupConfig struct {
+	Enabled           bool   `mapstructure:"enabled"`              // 是否启用清理任务
+	Cron              string `mapstructure:"cron"`                 // cron表达式
+	ResultMaxAgeHours int    `mapstructure:"result_max_age_hours"` // aria2 已结束任务记录保留时长（小时）
 }
 
 type ScheduledTask struct {
@@ -100,11 +593,22 @@ type ScheduledTask struct {
 }
 
 type TMDBConfig struct {
-	APIKey             string   `mapstructure:"api_key"`
-	Language           string   `mapstructure:"language"`
-	QPS                int      `mapstructure:"qps"`
-	BatchRenameLimit   int      `mapstructure:"batch_rename_limit"`
-	QualityDirPatterns []string `mapstructure:"quality_dir_patterns"`
+	APIKey                 string   `mapstructure:"api_key"`
+	Language               string   `mapstructure:"language"`
+	QPS                    int      `mapstructure:"qps"`
+	BatchRenameLimit       int      `mapstructure:"batch_rename_limit"`
+	BatchRenameConcurrency int      `mapstructure:"batch_rename_concurrency"`
+	QualityDirPatterns     []string `mapstructure:"quality_dir_patterns"`
+	Timeout                int      `mapstructure:"timeout"`     // 单次请求超时时间(秒)
+	MaxRetries             int      `mapstructure:"max_retries"` // 超时、网络错误、5xx、429 时的重试次数
+}
+
+// Validate 验证TMDB配置
+func (cfg *TMDBConfig) Validate() error {
+	if cfg.Timeout < 0 || cfg.MaxRetries < 0 {
+		return fmt.Errorf("tmdb.timeout 和 tmdb.max_retries 不能为负数")
+	}
+	return nil
 }
 
 // LLMConfig LLM配置
@@ -201,6 +705,9 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("server.host", "127.0.0.1")
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.mode", "debug")
+	viper.SetDefault("server.ip_allowlist.enabled", false)
+	viper.SetDefault("server.basic_auth.enabled", false)
+	viper.SetDefault("server.read_only", false)
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.output", "console")
 	viper.SetDefault("log.format", "text")
@@ -209,12 +716,37 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("log.add_source", false)
 	viper.SetDefault("aria2.rpc_url", "http://localhost:6800/jsonrpc")
 	viper.SetDefault("aria2.download_dir", "/downloads")
+	viper.SetDefault("aria2.batch_chunk_size", 0)
 	viper.SetDefault("alist.base_url", "http://localhost:5244")
 	viper.SetDefault("alist.default_path", "/")
 	viper.SetDefault("alist.qps", 50)
+	viper.SetDefault("alist.default_video_only", true)
+	viper.SetDefault("alist.show_hidden_files", false)
+	viper.SetDefault("alist.group_browse", false)
+	viper.SetDefault("alist.archive_download_path", "")
+	viper.SetDefault("alist.archive_after_days", 0)
+	viper.SetDefault("alist.generate_nfo", false)
+	viper.SetDefault("alist.probe_media_info", false)
+	viper.SetDefault("alist.zip_download_url", "")
 	viper.SetDefault("telegram.enabled", false)
 	viper.SetDefault("telegram.webhook.enabled", false)
 	viper.SetDefault("telegram.webhook.port", "8082")
+	viper.SetDefault("telegram.polling.timeout", 30)
+	viper.SetDefault("telegram.polling.limit", 100)
+	viper.SetDefault("telegram.command_prefix", "")
+	viper.SetDefault("telegram.send_rate.global_per_second", 30)
+	viper.SetDefault("telegram.send_rate.per_chat_per_second", 1)
+	viper.SetDefault("telegram.send_rate.per_chat_burst", 3)
+	viper.SetDefault("telegram.send_rate.max_retries", 3)
+	viper.SetDefault("telegram.auto_delete.transient_seconds", 30)
+	viper.SetDefault("telegram.auto_delete.important_seconds", 0)
+	viper.SetDefault("telegram.auto_delete.show_hint", false)
+	viper.SetDefault("telegram.progress_tracking.interval_seconds", 5)
+	viper.SetDefault("telegram.progress_tracking.timeout_minutes", 30)
+	viper.SetDefault("telegram.callback_ttl_minutes", 1440)
+	viper.SetDefault("email.enabled", false)
+	viper.SetDefault("email.smtp_port", 587)
+	viper.SetDefault("email.timeout", 15)
 
 	// 下载配置默认值
 	viper.SetDefault("download.video_only", true)
@@ -228,6 +760,28 @@ func LoadConfig() (*Config, error) {
 	})
 	viper.SetDefault("download.min_file_size_mb", 50)
 	viper.SetDefault("download.max_file_size_mb", 0)
+	viper.SetDefault("download.allow_delete_after_download", false)
+	viper.SetDefault("download.batch_retry_window_hours", 72)
+	viper.SetDefault("download.bandwidth.enabled", true)
+	viper.SetDefault("download.bandwidth.sample_interval", 30)
+	viper.SetDefault("download.bandwidth.typical_speed_mb", 0)
+	viper.SetDefault("download.disk_guard.min_free_gb", 0)
+	viper.SetDefault("download.disk_guard.check_interval", 60)
+	viper.SetDefault("download.disk_guard.warn_free_gb", 10)
+	viper.SetDefault("download.jump_queue_pause_others", false)
+	viper.SetDefault("download.boost.max_connections", 16)
+	viper.SetDefault("download.boost.max_split", 32)
+	viper.SetDefault("download.path_config.conflict_policy", "rename")
+	viper.SetDefault("download.music.enabled", false)
+	viper.SetDefault("download.music.extensions", []string{
+		"mp3", "flac", "ape", "wav", "m4a", "aac", "ogg", "opus", "wma", "alac", "dsf", "dff",
+	})
+	viper.SetDefault("download.music.path", "music")
+	viper.SetDefault("download.documents.enabled", false)
+	viper.SetDefault("download.documents.extensions", []string{
+		"pdf", "epub", "mobi", "azw3", "doc", "docx", "xls", "xlsx", "ppt", "pptx",
+	})
+	viper.SetDefault("download.documents.path", "documents")
 
 	// 路径模板默认值（留空表示使用智能路径生成）
 	viper.SetDefault("download.path_config.templates.tv", "")
@@ -237,12 +791,19 @@ func LoadConfig() (*Config, error) {
 
 	// 调度器配置默认值
 	viper.SetDefault("scheduler.enabled", false)
+	viper.SetDefault("scheduler.max_consecutive_failures", 5)
 	viper.SetDefault("scheduler.tasks", []ScheduledTask{})
+	viper.SetDefault("scheduler.cleanup.enabled", true)
+	viper.SetDefault("scheduler.cleanup.cron", "30 4 * * *")
+	viper.SetDefault("scheduler.cleanup.result_max_age_hours", 72)
 
 	// TMDB配置默认值
 	viper.SetDefault("tmdb.language", "zh-CN")
 	viper.SetDefault("tmdb.qps", 40)
 	viper.SetDefault("tmdb.batch_rename_limit", 20)
+	viper.SetDefault("tmdb.batch_rename_concurrency", 4)
+	viper.SetDefault("tmdb.timeout", 10)
+	viper.SetDefault("tmdb.max_retries", 2)
 	viper.SetDefault("tmdb.quality_dir_patterns", []string{
 		`(?i)\d{3,4}[pP]`,
 		`(?i)\d+K`,
@@ -294,5 +855,37 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if err := config.Server.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Alist.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Aria2.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Telegram.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Email.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Download.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Scheduler.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := config.TMDB.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
@@ -0,0 +1,95 @@
+package filesystem
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CreateZipArchive 将多个本地文件打包为一个 zip 文件
+// 压缩包内的路径相对于 baseDir；逐个文件流式写入磁盘，不在内存中缓存文件内容
+// 先写入临时文件，全部成功后再重命名，失败时不会留下不完整的压缩包
+func CreateZipArchive(archivePath, baseDir string, files []string) (err error) {
+	if len(files) == 0 {
+		return fmt.Errorf("no files to archive")
+	}
+	if _, statErr := os.Stat(archivePath); statErr == nil {
+		return fmt.Errorf("archive already exists: %s", archivePath)
+	}
+	if err := os.MkdirAll(filepath.Dir(archivePath), 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	tmpPath := archivePath + ".part"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	writer := zip.NewWriter(out)
+	for _, file := range files {
+		if err = addZipEntry(writer, baseDir, file); err != nil {
+			return err
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err = out.Close(); err != nil {
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+	if err = os.Rename(tmpPath, archivePath); err != nil {
+		return fmt.Errorf("failed to rename archive: %w", err)
+	}
+	return nil
+}
+
+// addZipEntry 将单个文件写入压缩包
+func addZipEntry(writer *zip.Writer, baseDir, file string) error {
+	in, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrSourceNotFound, file)
+		}
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("source is a directory: %s", file)
+	}
+
+	name, err := filepath.Rel(baseDir, file)
+	if err != nil || strings.HasPrefix(name, "..") {
+		return fmt.Errorf("file is outside of archive base directory: %s", file)
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(name)
+	header.Method = zip.Deflate
+
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+	if _, err := io.Copy(entry, in); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
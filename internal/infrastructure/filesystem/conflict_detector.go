@@ -193,8 +193,15 @@ func (d *ConflictDetector) GetHistoryCount() int {
 
 // GetConflictPolicy 获取冲突策略
 func (d *ConflictDetector) GetConflictPolicy() ConflictPolicy {
-	// 默认策略：重命名
-	return ConflictPolicyRename
+	return ConfiguredConflictPolicy(d.config)
+}
+
+// ConfiguredConflictPolicy 读取配置的冲突策略（download.path_config.conflict_policy），未配置时默认重命名
+func ConfiguredConflictPolicy(cfg *config.Config) ConflictPolicy {
+	if cfg == nil || cfg.Download.PathConfig.ConflictPolicy == "" {
+		return ConflictPolicyRename
+	}
+	return ConflictPolicy(cfg.Download.PathConfig.ConflictPolicy)
 }
 
 // ShouldSkipDuplicate 是否跳过重复下载
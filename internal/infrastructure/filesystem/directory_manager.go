@@ -5,7 +5,6 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
-	"syscall"
 
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
@@ -195,23 +194,7 @@ func (m *DirectoryManager) checkWritable(path string) error {
 
 // getAvailableSpace 获取可用磁盘空间
 func (m *DirectoryManager) getAvailableSpace(path string) (int64, error) {
-	var stat syscall.Statfs_t
-
-	// 确保路径存在，否则使用父目录
-	checkPath := path
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		checkPath = filepath.Dir(path)
-	}
-
-	err := syscall.Statfs(checkPath, &stat)
-	if err != nil {
-		return 0, fmt.Errorf("获取文件系统信息失败: %w", err)
-	}
-
-	// 可用空间 = 可用块数 * 块大小
-	availableBytes := int64(stat.Bavail) * int64(stat.Bsize)
-
-	return availableBytes, nil
+	return AvailableSpace(path)
 }
 
 // isInCache 检查目录是否在缓存中
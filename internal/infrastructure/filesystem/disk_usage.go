@@ -0,0 +1,68 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// ErrDirectoryNotExist 目录不存在
+var ErrDirectoryNotExist = errors.New("directory does not exist")
+
+// DiskUsage 目录所在文件系统的空间使用情况（字节）
+type DiskUsage struct {
+	Total     int64
+	Used      int64
+	Available int64 // 非 root 用户可用的空间
+}
+
+// UsedPercent 已用空间占比（0-100），与 df 一致按 已用/(已用+可用) 计算，不含为 root 保留的块
+func (u DiskUsage) UsedPercent() float64 {
+	if u.Used+u.Available <= 0 {
+		return 0
+	}
+	return float64(u.Used) / float64(u.Used+u.Available) * 100
+}
+
+// GetDiskUsage 获取目录所在文件系统的空间使用情况，目录不存在时返回 ErrDirectoryNotExist
+func GetDiskUsage(path string) (*DiskUsage, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrDirectoryNotExist, path)
+		}
+		return nil, fmt.Errorf("访问目录失败: %w", err)
+	}
+
+	return statDiskUsage(path)
+}
+
+// AvailableSpace 获取路径所在文件系统的可用空间（字节），路径不存在时使用父目录
+func AvailableSpace(path string) (int64, error) {
+	checkPath := path
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		checkPath = filepath.Dir(path)
+	}
+
+	usage, err := statDiskUsage(checkPath)
+	if err != nil {
+		return 0, err
+	}
+	return usage.Available, nil
+}
+
+// statDiskUsage 读取路径所在文件系统的块统计并换算为字节
+func statDiskUsage(path string) (*DiskUsage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return nil, fmt.Errorf("获取文件系统信息失败: %w", err)
+	}
+
+	blockSize := int64(stat.Bsize)
+	return &DiskUsage{
+		Total:     int64(stat.Blocks) * blockSize,
+		Used:      int64(stat.Blocks-stat.Bfree) * blockSize,
+		Available: int64(stat.Bavail) * blockSize,
+	}, nil
+}
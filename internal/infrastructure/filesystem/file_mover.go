@@ -0,0 +1,79 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// ErrSourceNotFound 源文件不存在（下载目录可能不在本机）
+var ErrSourceNotFound = errors.New("source file not found")
+
+// MoveFile 移动本地文件，目标目录不存在时自动创建，目标文件已存在时返回错误
+// 跨文件系统时回退为复制后删除
+func MoveFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%w: %s", ErrSourceNotFound, src)
+		}
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("source is a directory: %s", src)
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("destination already exists: %s", dst)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	err = os.Rename(src, dst)
+	if err == nil {
+		logger.Info("File moved", "from", src, "to", dst)
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := copyFile(src, dst, info.Mode()); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("copied but failed to remove source: %w", err)
+	}
+	logger.Info("File moved across filesystems", "from", src, "to", dst)
+	return nil
+}
+
+// copyFile 复制文件内容并同步到磁盘
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
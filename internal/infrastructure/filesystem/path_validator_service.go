@@ -10,6 +10,7 @@ import (
 
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
 )
 
 // PathValidatorService 路径验证服务 - 负责路径安全性和有效性验证
@@ -235,13 +236,11 @@ func (s *PathValidatorService) truncatePath(path string) string {
 	if availableLength < 20 {
 		// 如果目录路径太长，只保留基础目录
 		logger.Warn("Path too long, using simplified path", "original", path)
-		return filepath.Join(filepath.Dir(dir), base[:20]+ext)
+		return filepath.Join(filepath.Dir(dir), strutil.TruncateBytes(nameWithoutExt, 20)+ext)
 	}
 
-	// 截断文件名
-	if len(nameWithoutExt) > availableLength {
-		nameWithoutExt = nameWithoutExt[:availableLength]
-	}
+	// 截断文件名（按字节限制，不截断多字节字符）
+	nameWithoutExt = strutil.TruncateBytes(nameWithoutExt, availableLength)
 
 	return filepath.Join(dir, nameWithoutExt+ext)
 }
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
+	"github.com/easayliu/alist-aria2-download/pkg/utils/media"
+)
+
+// maxCategoryOverrides 最多保留的分类覆盖数，超出时移除最旧的
+const maxCategoryOverrides = 200
+
+// CategoryOverrideRepository 分类覆盖存储（持久化到JSON文件，可手动编辑）
+type CategoryOverrideRepository struct {
+	filePath  string
+	mu        sync.RWMutex
+	overrides map[string]*entities.CategoryOverride // pattern -> 覆盖
+	jsonUtils *httputil.JSONFileUtils
+}
+
+func NewCategoryOverrideRepository(dataDir string) (*CategoryOverrideRepository, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	repo := &CategoryOverrideRepository{
+		filePath:  dataDir + "/category_overrides.json",
+		overrides: make(map[string]*entities.CategoryOverride),
+		jsonUtils: httputil.NewJSONFileUtils(),
+	}
+
+	if err := repo.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load category overrides: %w", err)
+	}
+
+	return repo, nil
+}
+
+// load 从文件加载分类覆盖（手动编辑的 pattern 会被规范化）
+func (r *CategoryOverrideRepository) load() error {
+	var overrides []*entities.CategoryOverride
+	if err := r.jsonUtils.ReadJSONFile(r.filePath, &overrides); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.overrides = make(map[string]*entities.CategoryOverride, len(overrides))
+	for _, override := range overrides {
+		override.Pattern = media.NormalizeForMatch(override.Pattern)
+		if override.Pattern == "" || override.Category == "" {
+			continue
+		}
+		r.overrides[override.Pattern] = override
+	}
+
+	return nil
+}
+
+// listUnlocked 按创建时间升序返回所有覆盖（调用时必须已经持有锁）
+func (r *CategoryOverrideRepository) listUnlocked() []*entities.CategoryOverride {
+	overrides := make([]*entities.CategoryOverride, 0, len(r.overrides))
+	for _, override := range r.overrides {
+		overrides = append(overrides, override)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if !overrides[i].CreatedAt.Equal(overrides[j].CreatedAt) {
+			return overrides[i].CreatedAt.Before(overrides[j].CreatedAt)
+		}
+		return overrides[i].Pattern < overrides[j].Pattern
+	})
+	return overrides
+}
+
+// saveUnlocked 超出上限时移除最旧的覆盖后保存到文件（调用时必须已经持有锁）
+func (r *CategoryOverrideRepository) saveUnlocked() error {
+	overrides := r.listUnlocked()
+	if excess := len(overrides) - maxCategoryOverrides; excess > 0 {
+		for _, override := range overrides[:excess] {
+			delete(r.overrides, override.Pattern)
+		}
+		overrides = overrides[excess:]
+	}
+
+	return r.jsonUtils.WriteJSONFile(r.filePath, overrides, true)
+}
+
+// Save 新增或替换覆盖（按 pattern）
+func (r *CategoryOverrideRepository) Save(override *entities.CategoryOverride) error {
+	override.Pattern = media.NormalizeForMatch(override.Pattern)
+	if override.Pattern == "" || override.Category == "" {
+		return fmt.Errorf("category override requires pattern and category")
+	}
+	if override.CreatedAt.IsZero() {
+		override.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides[override.Pattern] = override
+	return r.saveUnlocked()
+}
+
+// Delete 删除覆盖
+func (r *CategoryOverrideRepository) Delete(pattern string) error {
+	pattern = media.NormalizeForMatch(pattern)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.overrides[pattern]; !exists {
+		return fmt.Errorf("category override not found: %s", pattern)
+	}
+	delete(r.overrides, pattern)
+	return r.saveUnlocked()
+}
+
+// List 获取所有覆盖（按创建时间升序）
+func (r *CategoryOverrideRepository) List() []entities.CategoryOverride {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	overrides := r.listUnlocked()
+	result := make([]entities.CategoryOverride, len(overrides))
+	for i, override := range overrides {
+		result[i] = *override
+	}
+	return result
+}
+
+// Match 查找与文件名匹配的覆盖，多个匹配时使用最长的 pattern
+func (r *CategoryOverrideRepository) Match(filename string) (entities.CategoryOverride, bool) {
+	name := media.NormalizeForMatch(filename)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var best *entities.CategoryOverride
+	for pattern, override := range r.overrides {
+		if !strings.Contains(name, pattern) {
+			continue
+		}
+		if best == nil || len(pattern) > len(best.Pattern) || (len(pattern) == len(best.Pattern) && pattern < best.Pattern) {
+			best = override
+		}
+	}
+	if best == nil {
+		return entities.CategoryOverride{}, false
+	}
+	return *best, true
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
+	"github.com/google/uuid"
+)
+
+// directoryCheckpointMaxAge 未完成断点的保留时长，超过后视为放弃
+const directoryCheckpointMaxAge = 7 * 24 * time.Hour
+
+// DirectoryCheckpointRepository 目录下载断点存储（每个目录只保留最近一次，持久化到JSON文件）
+type DirectoryCheckpointRepository struct {
+	filePath    string
+	mu          sync.RWMutex
+	checkpoints map[string]*entities.DirectoryDownloadCheckpoint // path -> 断点
+	jsonUtils   *httputil.JSONFileUtils
+}
+
+func NewDirectoryCheckpointRepository(dataDir string) (*DirectoryCheckpointRepository, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	repo := &DirectoryCheckpointRepository{
+		filePath:    dataDir + "/directory_checkpoints.json",
+		checkpoints: make(map[string]*entities.DirectoryDownloadCheckpoint),
+		jsonUtils:   httputil.NewJSONFileUtils(),
+	}
+
+	if err := repo.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load directory checkpoints: %w", err)
+	}
+
+	return repo, nil
+}
+
+// load 从文件加载断点
+func (r *DirectoryCheckpointRepository) load() error {
+	var checkpoints []*entities.DirectoryDownloadCheckpoint
+	if err := r.jsonUtils.ReadJSONFile(r.filePath, &checkpoints); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkpoints = make(map[string]*entities.DirectoryDownloadCheckpoint, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Path == "" {
+			continue
+		}
+		r.checkpoints[checkpoint.Path] = checkpoint
+	}
+
+	return nil
+}
+
+// saveUnlocked 清理过期断点后保存到文件（调用时必须已经持有锁）
+func (r *DirectoryCheckpointRepository) saveUnlocked() error {
+	cutoff := time.Now().Add(-directoryCheckpointMaxAge)
+	checkpoints := make([]*entities.DirectoryDownloadCheckpoint, 0, len(r.checkpoints))
+	for path, checkpoint := range r.checkpoints {
+		if checkpoint.UpdatedAt.Before(cutoff) {
+			delete(r.checkpoints, path)
+			continue
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	slices.SortFunc(checkpoints, func(a, b *entities.DirectoryDownloadCheckpoint) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return r.jsonUtils.WriteJSONFile(r.filePath, checkpoints, true)
+}
+
+// Start 为目录开始一次新的下载，替换该目录之前的断点
+func (r *DirectoryCheckpointRepository) Start(path string, totalFiles int, deleteAfterDownload bool) (*entities.DirectoryDownloadCheckpoint, error) {
+	now := time.Now()
+	checkpoint := &entities.DirectoryDownloadCheckpoint{
+		ID:                  uuid.New().String(),
+		Path:                path,
+		TotalFiles:          totalFiles,
+		DeleteAfterDownload: deleteAfterDownload,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkpoints[path] = checkpoint
+	copied := *checkpoint
+	return &copied, r.saveUnlocked()
+}
+
+// Get 获取目录未完成的断点（返回副本）
+func (r *DirectoryCheckpointRepository) Get(path string) (*entities.DirectoryDownloadCheckpoint, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	checkpoint, exists := r.checkpoints[path]
+	if !exists || time.Since(checkpoint.UpdatedAt) > directoryCheckpointMaxAge {
+		return nil, false
+	}
+	copied := *checkpoint
+	copied.Queued = slices.Clone(checkpoint.Queued)
+	return &copied, true
+}
+
+// MarkQueued 记录断点中一批已成功提交的源文件（每批只写一次文件）；断点已被新的下载替换或已完成时忽略
+func (r *DirectoryCheckpointRepository) MarkQueued(id string, sourcePaths []string) error {
+	if len(sourcePaths) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, checkpoint := range r.checkpoints {
+		if checkpoint.ID == id {
+			checkpoint.Queued = append(checkpoint.Queued, sourcePaths...)
+			checkpoint.UpdatedAt = time.Now()
+			return r.saveUnlocked()
+		}
+	}
+	return nil
+}
+
+// Complete 删除已完成的断点；该目录已开始新的下载时保留新断点
+func (r *DirectoryCheckpointRepository) Complete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for path, checkpoint := range r.checkpoints {
+		if checkpoint.ID == id {
+			delete(r.checkpoints, path)
+			return r.saveUnlocked()
+		}
+	}
+	return nil
+}
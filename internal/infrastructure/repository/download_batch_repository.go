@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxDownloadBatches 最多保留的批量下载记录数
+	maxDownloadBatches = 50
+	// downloadBatchMaxAge 批量下载记录保留时长
+	downloadBatchMaxAge = 30 * 24 * time.Hour
+)
+
+// DownloadBatchRepository 批量下载记录存储（按创建时间排序，持久化到JSON文件）
+type DownloadBatchRepository struct {
+	filePath  string
+	mu        sync.RWMutex
+	batches   []*entities.DownloadBatch // 按创建时间升序
+	jsonUtils *httputil.JSONFileUtils
+}
+
+func NewDownloadBatchRepository(dataDir string) (*DownloadBatchRepository, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	repo := &DownloadBatchRepository{
+		filePath:  dataDir + "/download_batches.json",
+		jsonUtils: httputil.NewJSONFileUtils(),
+	}
+
+	// ReadJSONFile 会包装错误，需用 errors.Is 判断文件不存在（首次启动）
+	if err := repo.load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load download batches: %w", err)
+	}
+
+	return repo, nil
+}
+
+// load 从文件加载批量下载记录
+func (r *DownloadBatchRepository) load() error {
+	var batches []*entities.DownloadBatch
+	if err := r.jsonUtils.ReadJSONFile(r.filePath, &batches); err != nil {
+		return err
+	}
+	slices.SortStableFunc(batches, func(a, b *entities.DownloadBatch) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = batches
+
+	return nil
+}
+
+// saveUnlocked 清理过期记录后保存到文件（调用时必须已经持有锁）
+func (r *DownloadBatchRepository) saveUnlocked() error {
+	cutoff := time.Now().Add(-downloadBatchMaxAge)
+	start := 0
+	for start < len(r.batches) && r.batches[start].CreatedAt.Before(cutoff) {
+		start++
+	}
+	start = max(start, len(r.batches)-maxDownloadBatches)
+	r.batches = r.batches[start:]
+
+	return r.jsonUtils.WriteJSONFile(r.filePath, r.batches, true)
+}
+
+// Save 新增或更新批量下载记录（按 ID），新记录自动生成 ID
+func (r *DownloadBatchRepository) Save(batch *entities.DownloadBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	batch.UpdatedAt = now
+	if batch.ID == "" {
+		batch.ID = uuid.New().String()
+	}
+
+	for i, existing := range r.batches {
+		if existing.ID == batch.ID {
+			batch.CreatedAt = existing.CreatedAt
+			r.batches[i] = cloneDownloadBatch(batch)
+			return r.saveUnlocked()
+		}
+	}
+
+	if batch.CreatedAt.IsZero() {
+		batch.CreatedAt = now
+	}
+	r.batches = append(r.batches, cloneDownloadBatch(batch))
+	return r.saveUnlocked()
+}
+
+// SetArchive 只更新批次的打包结果，避免覆盖同时发生的重试对文件列表的修改
+func (r *DownloadBatchRepository) SetArchive(id string, archive entities.DownloadBatchArchive) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, batch := range r.batches {
+		if batch.ID == id {
+			batch.Archive = &archive
+			batch.UpdatedAt = time.Now()
+			return r.saveUnlocked()
+		}
+	}
+	return fmt.Errorf("download batch not found: %s", id)
+}
+
+// AppendItems 向批次追加文件（分批提交的后续批次），避免覆盖同时发生的重试对文件列表的修改
+func (r *DownloadBatchRepository) AppendItems(id string, items []entities.DownloadBatchItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, batch := range r.batches {
+		if batch.ID == id {
+			batch.Items = append(batch.Items, items...)
+			batch.UpdatedAt = time.Now()
+			return r.saveUnlocked()
+		}
+	}
+	return fmt.Errorf("download batch not found: %s", id)
+}
+
+// GetByID 按 ID 获取批量下载记录（返回副本）
+func (r *DownloadBatchRepository) GetByID(id string) (*entities.DownloadBatch, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, batch := range r.batches {
+		if batch.ID == id {
+			return cloneDownloadBatch(batch), true
+		}
+	}
+	return nil, false
+}
+
+// GetLatest 获取最近创建的批量下载记录（返回副本）
+func (r *DownloadBatchRepository) GetLatest() (*entities.DownloadBatch, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.batches) == 0 {
+		return nil, false
+	}
+	return cloneDownloadBatch(r.batches[len(r.batches)-1]), true
+}
+
+// FindByGID 查找包含指定 aria2 GID 的批量下载记录（返回副本，优先最近的批次）
+func (r *DownloadBatchRepository) FindByGID(gid string) (*entities.DownloadBatch, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.batches) - 1; i >= 0; i-- {
+		batch := r.batches[i]
+		for _, item := range batch.Items {
+			if item.GID == gid {
+				return cloneDownloadBatch(batch), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// cloneDownloadBatch 复制批量下载记录，避免调用方修改存储中的文件列表和打包设置
+func cloneDownloadBatch(batch *entities.DownloadBatch) *entities.DownloadBatch {
+	batchCopy := *batch
+	batchCopy.Items = slices.Clone(batch.Items)
+	if batch.Archive != nil {
+		archive := *batch.Archive
+		batchCopy.Archive = &archive
+	}
+	return &batchCopy
+}
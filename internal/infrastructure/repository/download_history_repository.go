@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+const (
+	// maxDownloadRecords 最多保留的未完成（失败、取消等）历史记录数，已完成的记录只按时长清理，保证下载统计完整
+	maxDownloadRecords = 2000
+	// downloadRecordMaxAge 历史记录保留时长
+	downloadRecordMaxAge = 180 * 24 * time.Hour
+	// historySaveDelay 合并写入的延迟：一批下载产生的多个事件只重写一次文件
+	historySaveDelay = 5 * time.Second
+)
+
+// DownloadHistoryRepository 下载历史存储（按 GID 保存，可按源文件路径查询，持久化到JSON文件）
+type DownloadHistoryRepository struct {
+	filePath  string
+	mu        sync.RWMutex
+	records   map[string]*entities.DownloadRecord // gid -> 记录
+	jsonUtils *httputil.JSONFileUtils
+	saveTimer *time.Timer // 非 nil 表示有尚未写入文件的修改
+}
+
+func NewDownloadHistoryRepository(dataDir string) (*DownloadHistoryRepository, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	repo := &DownloadHistoryRepository{
+		filePath:  dataDir + "/download_history.json",
+		records:   make(map[string]*entities.DownloadRecord),
+		jsonUtils: httputil.NewJSONFileUtils(),
+	}
+
+	if err := repo.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load download history: %w", err)
+	}
+
+	return repo, nil
+}
+
+// NormalizeHistoryPath 规范化源文件路径，作为历史查询的键
+func NormalizeHistoryPath(p string) string {
+	if p == "" {
+		return ""
+	}
+	return path.Clean("/" + p)
+}
+
+// load 从文件加载历史记录
+func (r *DownloadHistoryRepository) load() error {
+	var records []*entities.DownloadRecord
+	if err := r.jsonUtils.ReadJSONFile(r.filePath, &records); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = make(map[string]*entities.DownloadRecord, len(records))
+	for _, record := range records {
+		r.records[record.ID] = record
+	}
+
+	return nil
+}
+
+// saveUnlocked 清理过期记录后保存到文件（调用时必须已经持有锁）
+func (r *DownloadHistoryRepository) saveUnlocked() error {
+	r.pruneUnlocked(time.Now())
+
+	records := make([]*entities.DownloadRecord, 0, len(r.records))
+	for _, record := range r.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	return r.jsonUtils.WriteJSONFile(r.filePath, records, true)
+}
+
+// pruneUnlocked 移除超过保留时长的记录，并在未完成的记录超出数量上限时移除其中最旧的
+func (r *DownloadHistoryRepository) pruneUnlocked(now time.Time) {
+	cutoff := now.Add(-downloadRecordMaxAge)
+	var records []*entities.DownloadRecord
+	for id, record := range r.records {
+		switch {
+		case record.UpdatedAt.Before(cutoff):
+			delete(r.records, id)
+		case record.Status != valueobjects.DownloadStatusComplete:
+			records = append(records, record)
+		}
+	}
+
+	if len(records) <= maxDownloadRecords {
+		return
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].UpdatedAt.Before(records[j].UpdatedAt)
+	})
+	for _, record := range records[:len(records)-maxDownloadRecords] {
+		delete(r.records, record.ID)
+	}
+}
+
+// Save 新增或更新记录（按 GID），创建时间保持首次写入的值；文件写入延迟合并，见 Flush
+func (r *DownloadHistoryRepository) Save(record *entities.DownloadRecord) error {
+	if record.ID == "" {
+		return fmt.Errorf("download record requires id")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record.SourcePath = NormalizeHistoryPath(record.SourcePath)
+	now := time.Now()
+	if existing, ok := r.records[record.ID]; ok {
+		record.CreatedAt = existing.CreatedAt
+		if record.Directory == "" {
+			record.Directory = existing.Directory
+		}
+		if record.Category == "" {
+			record.Category = existing.Category
+		}
+		if record.CompletedAt.IsZero() {
+			record.CompletedAt = existing.CompletedAt
+		}
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = now
+	}
+	record.UpdatedAt = now
+
+	r.records[record.ID] = record
+	r.scheduleSaveUnlocked()
+	return nil
+}
+
+// scheduleSaveUnlocked 延迟 historySaveDelay 后写入文件，期间的修改合并为一次写入（调用时必须已经持有锁）
+func (r *DownloadHistoryRepository) scheduleSaveUnlocked() {
+	if r.saveTimer != nil {
+		return
+	}
+	r.saveTimer = time.AfterFunc(historySaveDelay, func() {
+		if err := r.Flush(); err != nil {
+			logger.Warn("Failed to save download history", "error", err)
+		}
+	})
+}
+
+// Flush 立即写入尚未保存的修改（退出前调用）
+func (r *DownloadHistoryRepository) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.saveTimer == nil {
+		return nil
+	}
+	r.saveTimer.Stop()
+	r.saveTimer = nil
+	return r.saveUnlocked()
+}
+
+// GetBySourcePath 获取某个源文件的所有下载记录（最新的在前）
+func (r *DownloadHistoryRepository) GetBySourcePath(sourcePath string) []*entities.DownloadRecord {
+	key := NormalizeHistoryPath(sourcePath)
+	if key == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var records []*entities.DownloadRecord
+	for _, record := range r.records {
+		if record.SourcePath == key {
+			recordCopy := *record
+			records = append(records, &recordCopy)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+
+	return records
+}
+
+// GetByID 按 GID 获取记录（返回副本）
+func (r *DownloadHistoryRepository) GetByID(id string) (*entities.DownloadRecord, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.records[id]
+	if !ok {
+		return nil, false
+	}
+	recordCopy := *record
+	return &recordCopy, true
+}
+
+// GetCompletedSince 获取完成时间不早于 since 的下载记录（按完成时间倒序）
+func (r *DownloadHistoryRepository) GetCompletedSince(since time.Time) []*entities.DownloadRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var records []*entities.DownloadRecord
+	for _, record := range r.records {
+		if record.Status == valueobjects.DownloadStatusComplete && !record.CompletionTime().Before(since) {
+			recordCopy := *record
+			records = append(records, &recordCopy)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CompletionTime().After(records[j].CompletionTime())
+	})
+	return records
+}
+
+// GetRecentCompleted 获取最近完成的下载记录（按完成时间倒序，最多 limit 条）
+func (r *DownloadHistoryRepository) GetRecentCompleted(limit int) []*entities.DownloadRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var records []*entities.DownloadRecord
+	for _, record := range r.records {
+		if record.Status == valueobjects.DownloadStatusComplete && record.Directory != "" {
+			recordCopy := *record
+			records = append(records, &recordCopy)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].UpdatedAt.After(records[j].UpdatedAt)
+	})
+
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
+)
+
+// PinRepository 目录收藏存储（按用户保存，持久化到JSON文件）
+type PinRepository struct {
+	filePath  string
+	mu        sync.RWMutex
+	pins      map[int64][]*entities.PinnedDirectory
+	jsonUtils *httputil.JSONFileUtils
+}
+
+func NewPinRepository(dataDir string) (*PinRepository, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	repo := &PinRepository{
+		filePath:  dataDir + "/pinned_directories.json",
+		pins:      make(map[int64][]*entities.PinnedDirectory),
+		jsonUtils: httputil.NewJSONFileUtils(),
+	}
+
+	if err := repo.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load pinned directories: %w", err)
+	}
+
+	return repo, nil
+}
+
+// load 从文件加载收藏
+func (r *PinRepository) load() error {
+	var pins []*entities.PinnedDirectory
+	if err := r.jsonUtils.ReadJSONFile(r.filePath, &pins); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pins = make(map[int64][]*entities.PinnedDirectory)
+	for _, pin := range pins {
+		r.pins[pin.UserID] = append(r.pins[pin.UserID], pin)
+	}
+
+	return nil
+}
+
+// saveUnlocked 保存收藏到文件（调用时必须已经持有锁）
+func (r *PinRepository) saveUnlocked() error {
+	pins := make([]*entities.PinnedDirectory, 0)
+	for _, userPins := range r.pins {
+		pins = append(pins, userPins...)
+	}
+
+	return r.jsonUtils.WriteJSONFile(r.filePath, pins, true)
+}
+
+// Add 添加收藏，已存在时返回 false；root 为收藏时所在的 alist.roots 根目录名称
+func (r *PinRepository) Add(userID int64, root, path string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, pin := range r.pins[userID] {
+		if pin.Path == path {
+			return false, nil
+		}
+	}
+
+	r.pins[userID] = append(r.pins[userID], &entities.PinnedDirectory{
+		UserID:    userID,
+		Path:      path,
+		Root:      root,
+		CreatedAt: time.Now(),
+	})
+	return true, r.saveUnlocked()
+}
+
+// Remove 取消收藏，不存在时返回 false
+func (r *PinRepository) Remove(userID int64, path string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userPins := r.pins[userID]
+	for i, pin := range userPins {
+		if pin.Path == path {
+			r.pins[userID] = append(userPins[:i], userPins[i+1:]...)
+			if len(r.pins[userID]) == 0 {
+				delete(r.pins, userID)
+			}
+			return true, r.saveUnlocked()
+		}
+	}
+
+	return false, nil
+}
+
+// IsPinned 判断目录是否已收藏
+func (r *PinRepository) IsPinned(userID int64, path string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, pin := range r.pins[userID] {
+		if pin.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// GetByUserID 获取用户的收藏（按收藏时间排序）
+func (r *PinRepository) GetByUserID(userID int64) []*entities.PinnedDirectory {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pins := make([]*entities.PinnedDirectory, len(r.pins[userID]))
+	copy(pins, r.pins[userID])
+	sort.Slice(pins, func(i, j int) bool {
+		return pins[i].CreatedAt.Before(pins[j].CreatedAt)
+	})
+
+	return pins
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
+)
+
+// PresetRepository 下载预设存储（按用户保存，持久化到JSON文件）
+type PresetRepository struct {
+	filePath  string
+	mu        sync.RWMutex
+	presets   map[int64][]*entities.DownloadPreset
+	jsonUtils *httputil.JSONFileUtils
+}
+
+func NewPresetRepository(dataDir string) (*PresetRepository, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	repo := &PresetRepository{
+		filePath:  dataDir + "/download_presets.json",
+		presets:   make(map[int64][]*entities.DownloadPreset),
+		jsonUtils: httputil.NewJSONFileUtils(),
+	}
+
+	if err := repo.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load download presets: %w", err)
+	}
+
+	return repo, nil
+}
+
+// load 从文件加载预设
+func (r *PresetRepository) load() error {
+	var presets []*entities.DownloadPreset
+	if err := r.jsonUtils.ReadJSONFile(r.filePath, &presets); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.presets = make(map[int64][]*entities.DownloadPreset)
+	for _, preset := range presets {
+		r.presets[preset.UserID] = append(r.presets[preset.UserID], preset)
+	}
+
+	return nil
+}
+
+// saveUnlocked 保存预设到文件（调用时必须已经持有锁）
+func (r *PresetRepository) saveUnlocked() error {
+	presets := make([]*entities.DownloadPreset, 0)
+	for _, userPresets := range r.presets {
+		presets = append(presets, userPresets...)
+	}
+
+	return r.jsonUtils.WriteJSONFile(r.filePath, presets, true)
+}
+
+// Save 保存预设，同名预设存在时覆盖并返回 true
+func (r *PresetRepository) Save(preset *entities.DownloadPreset) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if preset.CreatedAt.IsZero() {
+		preset.CreatedAt = time.Now()
+	}
+
+	userPresets := r.presets[preset.UserID]
+	for i, existing := range userPresets {
+		if existing.Name == preset.Name {
+			userPresets[i] = preset
+			return true, r.saveUnlocked()
+		}
+	}
+
+	r.presets[preset.UserID] = append(userPresets, preset)
+	return false, r.saveUnlocked()
+}
+
+// Remove 删除预设，不存在时返回 false
+func (r *PresetRepository) Remove(userID int64, name string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userPresets := r.presets[userID]
+	for i, preset := range userPresets {
+		if preset.Name == name {
+			r.presets[userID] = append(userPresets[:i], userPresets[i+1:]...)
+			if len(r.presets[userID]) == 0 {
+				delete(r.presets, userID)
+			}
+			return true, r.saveUnlocked()
+		}
+	}
+
+	return false, nil
+}
+
+// Get 获取用户的指定预设
+func (r *PresetRepository) Get(userID int64, name string) (*entities.DownloadPreset, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, preset := range r.presets[userID] {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return nil, false
+}
+
+// GetByUserID 获取用户的预设（按创建时间排序）
+func (r *PresetRepository) GetByUserID(userID int64) []*entities.DownloadPreset {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	presets := make([]*entities.DownloadPreset, len(r.presets[userID]))
+	copy(presets, r.presets[userID])
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].CreatedAt.Before(presets[j].CreatedAt)
+	})
+
+	return presets
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
+)
+
+// maxDirectorySnapshots 最多保留的目录快照数（每个目录只保留最近一次），超出时移除最旧的
+const maxDirectorySnapshots = 50
+
+// SnapshotRepository 目录快照存储（按目录路径保存，持久化到JSON文件）
+type SnapshotRepository struct {
+	filePath  string
+	mu        sync.RWMutex
+	snapshots map[string]*entities.DirectorySnapshot // path -> 快照
+	jsonUtils *httputil.JSONFileUtils
+}
+
+func NewSnapshotRepository(dataDir string) (*SnapshotRepository, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	repo := &SnapshotRepository{
+		filePath:  dataDir + "/directory_snapshots.json",
+		snapshots: make(map[string]*entities.DirectorySnapshot),
+		jsonUtils: httputil.NewJSONFileUtils(),
+	}
+
+	if err := repo.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load directory snapshots: %w", err)
+	}
+
+	return repo, nil
+}
+
+// load 从文件加载快照
+func (r *SnapshotRepository) load() error {
+	var snapshots []*entities.DirectorySnapshot
+	if err := r.jsonUtils.ReadJSONFile(r.filePath, &snapshots); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.snapshots = make(map[string]*entities.DirectorySnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Path == "" {
+			continue
+		}
+		r.snapshots[snapshot.Path] = snapshot
+	}
+
+	return nil
+}
+
+// saveUnlocked 超出上限时移除最旧的快照后保存到文件（调用时必须已经持有锁）
+func (r *SnapshotRepository) saveUnlocked() error {
+	snapshots := make([]*entities.DirectorySnapshot, 0, len(r.snapshots))
+	for _, snapshot := range r.snapshots {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+		}
+		return snapshots[i].Path < snapshots[j].Path
+	})
+
+	if excess := len(snapshots) - maxDirectorySnapshots; excess > 0 {
+		for _, snapshot := range snapshots[:excess] {
+			delete(r.snapshots, snapshot.Path)
+		}
+		snapshots = snapshots[excess:]
+	}
+
+	return r.jsonUtils.WriteJSONFile(r.filePath, snapshots, true)
+}
+
+// Save 保存目录快照，替换该目录之前的快照
+func (r *SnapshotRepository) Save(snapshot *entities.DirectorySnapshot) error {
+	if snapshot.Path == "" {
+		return fmt.Errorf("directory snapshot requires path")
+	}
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.snapshots[snapshot.Path] = snapshot
+	return r.saveUnlocked()
+}
+
+// Get 获取目录最近一次的快照
+func (r *SnapshotRepository) Get(path string) (*entities.DirectorySnapshot, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot, exists := r.snapshots[path]
+	if !exists {
+		return nil, false
+	}
+	copied := *snapshot
+	return &copied, true
+}
@@ -87,7 +87,7 @@ func (r *TaskRepository) Update(task *entities.ScheduledTask) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if _, exists := r.tasks[task.ID]; !exists {
-		return fmt.Errorf("task not found: %s", task.ID)
+		return fmt.Errorf("%w: %s", entities.ErrTaskNotFound, task.ID)
 	}
 	r.tasks[task.ID] = task
 	return r.saveUnlocked()
@@ -108,7 +108,7 @@ func (r *TaskRepository) GetByID(id string) (*entities.ScheduledTask, error) {
 
 	task, exists := r.tasks[id]
 	if !exists {
-		return nil, fmt.Errorf("task not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", entities.ErrTaskNotFound, id)
 	}
 
 	return task, nil
@@ -149,7 +149,7 @@ func (r *TaskRepository) UpdateLastRunTime(id string, runTime time.Time) error {
 
 	task, exists := r.tasks[id]
 	if !exists {
-		return fmt.Errorf("task not found: %s", id)
+		return fmt.Errorf("%w: %s", entities.ErrTaskNotFound, id)
 	}
 
 	task.LastRunAt = &runTime
@@ -165,7 +165,7 @@ func (r *TaskRepository) UpdateNextRunTime(id string, nextTime time.Time) error
 
 	task, exists := r.tasks[id]
 	if !exists {
-		return fmt.Errorf("task not found: %s", id)
+		return fmt.Errorf("%w: %s", entities.ErrTaskNotFound, id)
 	}
 
 	task.NextRunAt = &nextTime
@@ -173,3 +173,45 @@ func (r *TaskRepository) UpdateNextRunTime(id string, nextTime time.Time) error
 
 	return r.saveUnlocked()
 }
+
+// RecordRunResult 更新运行统计：失败时累加连续失败次数并记录错误信息，成功时清零连续失败次数
+// 返回更新后的任务副本
+func (r *TaskRepository) RecordRunResult(id string, failed bool, errMsg string) (entities.ScheduledTask, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, exists := r.tasks[id]
+	if !exists {
+		return entities.ScheduledTask{}, fmt.Errorf("%w: %s", entities.ErrTaskNotFound, id)
+	}
+
+	task.RunCount++
+	if failed {
+		task.FailureCount++
+		task.ConsecutiveFailures++
+		task.LastError = errMsg
+	} else {
+		task.SuccessCount++
+		task.ConsecutiveFailures = 0
+	}
+	task.UpdatedAt = time.Now()
+
+	return *task, r.saveUnlocked()
+}
+
+// UpdateFailedItems 在锁内用 update 计算任务新的失败文件列表并保存，返回更新后的列表
+func (r *TaskRepository) UpdateFailedItems(id string, update func([]entities.TaskFailedItem) []entities.TaskFailedItem) ([]entities.TaskFailedItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, exists := r.tasks[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", entities.ErrTaskNotFound, id)
+	}
+
+	task.FailedItems = update(task.FailedItems)
+	task.UpdatedAt = time.Now()
+
+	items := append([]entities.TaskFailedItem(nil), task.FailedItems...)
+	return items, r.saveUnlocked()
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/httpclient"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxTaskRuns 最多保留的运行记录数
+	maxTaskRuns = 2000
+	// taskRunMaxAge 运行记录保留时长
+	taskRunMaxAge = 30 * 24 * time.Hour
+)
+
+// TaskRunRepository 定时任务运行记录存储（按开始时间查询，持久化到JSON文件）
+type TaskRunRepository struct {
+	filePath  string
+	mu        sync.RWMutex
+	runs      []*entities.TaskRun // 按开始时间升序
+	jsonUtils *httputil.JSONFileUtils
+}
+
+func NewTaskRunRepository(dataDir string) (*TaskRunRepository, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	repo := &TaskRunRepository{
+		filePath:  dataDir + "/task_runs.json",
+		jsonUtils: httputil.NewJSONFileUtils(),
+	}
+
+	if err := repo.load(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load task runs: %w", err)
+	}
+
+	return repo, nil
+}
+
+// load 从文件加载运行记录
+func (r *TaskRunRepository) load() error {
+	var runs []*entities.TaskRun
+	if err := r.jsonUtils.ReadJSONFile(r.filePath, &runs); err != nil {
+		return err
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = runs
+
+	return nil
+}
+
+// saveUnlocked 清理过期记录后保存到文件（调用时必须已经持有锁）
+func (r *TaskRunRepository) saveUnlocked() error {
+	cutoff := time.Now().Add(-taskRunMaxAge)
+	start := sort.Search(len(r.runs), func(i int) bool {
+		return !r.runs[i].StartedAt.Before(cutoff)
+	})
+	start = max(start, len(r.runs)-maxTaskRuns)
+	r.runs = r.runs[start:]
+
+	return r.jsonUtils.WriteJSONFile(r.filePath, r.runs, true)
+}
+
+// Save 保存一条运行记录
+func (r *TaskRunRepository) Save(run *entities.TaskRun) error {
+	if run.TaskID == "" {
+		return fmt.Errorf("task run requires task id")
+	}
+	if run.ID == "" {
+		run.ID = uuid.New().String()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 记录通常按时间顺序写入，仅在乱序时插入到对应位置
+	idx := sort.Search(len(r.runs), func(i int) bool {
+		return r.runs[i].StartedAt.After(run.StartedAt)
+	})
+	r.runs = append(r.runs, nil)
+	copy(r.runs[idx+1:], r.runs[idx:])
+	r.runs[idx] = run
+
+	return r.saveUnlocked()
+}
+
+// ListBetween 获取开始时间在 [start, end) 内的运行记录（按开始时间升序）
+func (r *TaskRunRepository) ListBetween(start, end time.Time) []*entities.TaskRun {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var runs []*entities.TaskRun
+	for _, run := range r.runs {
+		if run.StartedAt.Before(start) || !run.StartedAt.Before(end) {
+			continue
+		}
+		runCopy := *run
+		runs = append(runs, &runCopy)
+	}
+
+	return runs
+}
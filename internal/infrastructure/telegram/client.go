@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -33,16 +34,17 @@ func NewClient(cfg *config.TelegramConfig) *Client {
 		bot:    bot,
 	}
 
-	// 注册Bot命令菜单
-	if err := client.RegisterBotCommands(); err != nil {
-		logger.Error("Failed to register bot commands", "error", err)
-	} else {
-		logger.Info("Bot commands registered successfully")
-	}
-
 	return client
 }
 
+// BotUsername 获取机器人用户名（未连接时为空）
+func (c *Client) BotUsername() string {
+	if c.bot == nil {
+		return ""
+	}
+	return c.bot.Self.UserName
+}
+
 // GetBot 获取bot实例
 func (c *Client) GetBot() *tgbotapi.BotAPI {
 	return c.bot
@@ -89,6 +91,74 @@ func (c *Client) SendMessageWithKeyboard(chatID int64, text, parseMode string, k
 	return sentMsg.MessageID, nil
 }
 
+// SendMessageWithForceReply 发送 HTML 消息并要求用户回复（客户端自动进入回复模式）
+// placeholder 为输入框中的提示文字
+func (c *Client) SendMessageWithForceReply(chatID int64, text, placeholder string) (int, error) {
+	if c.bot == nil {
+		return 0, fmt.Errorf("telegram bot not initialized")
+	}
+
+	// Telegram 限制输入框占位文本最多 64 个字符，超出时请求会被拒绝
+	if runes := []rune(placeholder); len(runes) > 64 {
+		placeholder = string(runes[:64])
+	}
+
+	msg := tgbotapi.NewMessage(chatID, cleanUTF8(text))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.ForceReply{
+		ForceReply:            true,
+		InputFieldPlaceholder: placeholder,
+		Selective:             true,
+	}
+
+	sentMsg, err := c.bot.Send(msg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send telegram message: %w", err)
+	}
+
+	return sentMsg.MessageID, nil
+}
+
+// SendPhoto 发送内存中的图片（如二维码），caption 支持 HTML
+func (c *Client) SendPhoto(chatID int64, fileName string, data []byte, caption string) (int, error) {
+	if c.bot == nil {
+		return 0, fmt.Errorf("telegram bot not initialized")
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	if caption != "" {
+		photo.Caption = cleanUTF8(caption)
+		photo.ParseMode = "HTML"
+	}
+
+	sentMsg, err := c.bot.Send(photo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send telegram photo: %w", err)
+	}
+
+	return sentMsg.MessageID, nil
+}
+
+// SendDocument 发送内存中的文件（如导出的报表），caption 支持 HTML
+func (c *Client) SendDocument(chatID int64, fileName string, data []byte, caption string) (int, error) {
+	if c.bot == nil {
+		return 0, fmt.Errorf("telegram bot not initialized")
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	if caption != "" {
+		doc.Caption = cleanUTF8(caption)
+		doc.ParseMode = "HTML"
+	}
+
+	sentMsg, err := c.bot.Send(doc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send telegram document: %w", err)
+	}
+
+	return sentMsg.MessageID, nil
+}
+
 // SendMessageWithAutoDelete 发送消息并在指定时间后自动删除
 // chatID: 目标聊天ID
 // text: 消息文本
@@ -181,13 +251,15 @@ func (c *Client) formatNotification(msg *NotificationMessage) string {
 	}
 }
 
-func (c *Client) GetUpdates(offset int64, timeout int) ([]tgbotapi.Update, error) {
+// GetUpdates 拉取更新，timeout 为长轮询秒数，limit 为单次最大更新数（0 使用 Telegram 默认值）
+func (c *Client) GetUpdates(offset int64, timeout, limit int) ([]tgbotapi.Update, error) {
 	if c.bot == nil {
 		return nil, fmt.Errorf("telegram bot not initialized")
 	}
 
 	updateConfig := tgbotapi.NewUpdate(int(offset))
 	updateConfig.Timeout = timeout
+	updateConfig.Limit = limit
 
 	updates, err := c.bot.GetUpdates(updateConfig)
 	if err != nil {
@@ -210,6 +282,49 @@ func (c *Client) IsAuthorized(userID int64) bool {
 	return false
 }
 
+// IsAdmin 是否为管理员（必须显式配置在 admin_ids 中，未配置时任何人都不是管理员）
+func (c *Client) IsAdmin(userID int64) bool {
+	for _, adminID := range c.config.AdminIDs {
+		if adminID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCanPost 检查机器人能否向指定聊天发送消息：私聊需用户已启动机器人，
+// 群组中机器人需为成员且未被禁言，频道中需为有发布权限的管理员
+func (c *Client) CheckCanPost(chatID int64) error {
+	if c.bot == nil {
+		return fmt.Errorf("telegram bot not initialized")
+	}
+
+	chat, err := c.bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatID}})
+	if err != nil {
+		return fmt.Errorf("无法访问聊天 %d（机器人需已加入该群组/频道，私聊需先启动机器人）: %w", chatID, err)
+	}
+	if chat.IsPrivate() {
+		return nil
+	}
+
+	member, err := c.bot.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: c.bot.Self.ID},
+	})
+	if err != nil {
+		return fmt.Errorf("无法获取机器人在聊天 %d 中的权限: %w", chatID, err)
+	}
+
+	switch {
+	case member.HasLeft() || member.WasKicked():
+		return fmt.Errorf("机器人不在聊天 %d 中", chatID)
+	case chat.IsChannel() && !member.IsCreator() && !(member.IsAdministrator() && member.CanPostMessages):
+		return fmt.Errorf("机器人不是频道 %d 的管理员或没有发布消息权限", chatID)
+	case member.Status == "restricted" && !member.CanSendMessages:
+		return fmt.Errorf("机器人在聊天 %d 中被禁止发言", chatID)
+	}
+	return nil
+}
+
 func (c *Client) AnswerCallbackQuery(callbackQueryID string, text string) error {
 	if c.bot == nil {
 		return fmt.Errorf("telegram bot not initialized")
@@ -224,54 +339,30 @@ func (c *Client) AnswerCallbackQuery(callbackQueryID string, text string) error
 	return nil
 }
 
-// RegisterBotCommands 注册Bot命令菜单
-func (c *Client) RegisterBotCommands() error {
+// RegisterBotCommands 注册Bot命令菜单，languageCode 为空时注册为默认语言
+func (c *Client) RegisterBotCommands(commands []tgbotapi.BotCommand, languageCode string) error {
 	if c.bot == nil {
 		return fmt.Errorf("telegram bot not initialized")
 	}
 
-	commands := []tgbotapi.BotCommand{
-		{
-			Command:     "start",
-			Description: "🏠 显示主菜单和欢迎信息",
-		},
-		{
-			Command:     "help",
-			Description: "❓ 显示帮助信息和可用命令",
-		},
-		{
-			Command:     "status",
-			Description: "📊 查看系统运行状态",
-		},
-		{
-			Command:     "download",
-			Description: "📥 开始下载文件 (用法: /download <URL>)",
-		},
-		{
-			Command:     "list",
-			Description: "📁 列出文件和目录 (用法: /list [路径])",
-		},
-		{
-			Command:     "cancel",
-			Description: "❌ 取消下载任务 (用法: /cancel <下载ID>)",
-		},
-		{
-			Command:     "manage",
-			Description: "⚡ 打开管理面板和快捷功能",
-		},
-	}
-
-	setCommandsConfig := tgbotapi.NewSetMyCommands(commands...)
-	_, err := c.bot.Request(setCommandsConfig)
-	if err != nil {
+	// 配置了命令前缀时，菜单中注册带前缀的命令
+	prefixed := make([]tgbotapi.BotCommand, len(commands))
+	for i, cmd := range commands {
+		prefixed[i] = tgbotapi.BotCommand{Command: c.config.CommandPrefix + cmd.Command, Description: cmd.Description}
+	}
+
+	setCommandsConfig := tgbotapi.NewSetMyCommands(prefixed...)
+	setCommandsConfig.LanguageCode = languageCode
+	if _, err := c.bot.Request(setCommandsConfig); err != nil {
 		return fmt.Errorf("failed to set bot commands: %w", err)
 	}
 
 	return nil
 }
 
-// SetWebhook 设置 Telegram Webhook
-func (c *Client) SetWebhook(webhookURL string) error {
+// SetWebhook 设置 Telegram Webhook，secretToken 不为空时 Telegram 会在每个请求的
+// X-Telegram-Bot-Api-Secret-Token 请求头中带上该值（tgbotapi 的 WebhookConfig 不支持该参数，直接发送请求）
+func (c *Client) SetWebhook(webhookURL, secretToken string) error {
 	if c.bot == nil {
 		return fmt.Errorf("telegram bot not initialized")
 	}
@@ -279,14 +370,17 @@ func (c *Client) SetWebhook(webhookURL string) error {
 	if webhookURL == "" {
 		return fmt.Errorf("webhook URL cannot be empty")
 	}
+	if _, err := url.Parse(webhookURL); err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
 
-	webhookConfig, _ := tgbotapi.NewWebhook(webhookURL)
-	_, err := c.bot.Request(webhookConfig)
-	if err != nil {
+	params := tgbotapi.Params{"url": webhookURL}
+	params.AddNonEmpty("secret_token", secretToken)
+	if _, err := c.bot.MakeRequest("setWebhook", params); err != nil {
 		return fmt.Errorf("failed to set webhook: %w", err)
 	}
 
-	logger.Info("Webhook set successfully", "url", webhookURL)
+	logger.Info("Webhook set successfully", "url", webhookURL, "secretToken", secretToken != "")
 	return nil
 }
 
@@ -2,7 +2,9 @@ package tmdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -17,8 +19,43 @@ import (
 const (
 	DefaultBaseURL = "https://api.themoviedb.org/3"
 	DefaultTimeout = 10 * time.Second
+	// DefaultMaxRetries 服务不可达时的默认重试次数
+	DefaultMaxRetries = 2
+	// DefaultRetryBackoff 首次重试前的等待时间，之后每次翻倍
+	DefaultRetryBackoff = time.Second
 )
 
+// ErrUnavailable TMDB服务不可达（网络错误、5xx、限流），区别于"无搜索结果"
+var ErrUnavailable = errors.New("TMDB service unavailable")
+
+// IsUnavailable 判断错误是否由TMDB不可达导致
+func IsUnavailable(err error) bool {
+	return errors.Is(err, ErrUnavailable)
+}
+
+// IsNotFound 判断错误是否为TMDB返回的404（请求的剧集或季度不存在）
+func IsNotFound(err error) bool {
+	var statusErr *httputil.StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// isUnavailableError 判断请求错误是否属于服务不可达（可重试）
+func isUnavailableError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var statusErr *httputil.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError ||
+			statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
 type Client struct {
 	BaseURL     string
 	APIKey      string
@@ -26,6 +63,10 @@ type Client struct {
 	httpClient  *http.Client
 	rateLimiter *ratelimit.RateLimiter
 	mu          sync.RWMutex
+
+	timeout      time.Duration // 单次请求超时时间
+	maxRetries   int           // 服务不可达时的重试次数
+	retryBackoff time.Duration // 首次重试前的等待时间
 }
 
 func NewClient(apiKey string) *Client {
@@ -34,13 +75,14 @@ func NewClient(apiKey string) *Client {
 	}
 
 	return &Client{
-		BaseURL:  DefaultBaseURL,
-		APIKey:   apiKey,
-		Language: "en-US",
-		httpClient: &http.Client{
-			Timeout: DefaultTimeout,
-		},
-		rateLimiter: ratelimit.NewRateLimiter(40),
+		BaseURL:      DefaultBaseURL,
+		APIKey:       apiKey,
+		Language:     "en-US",
+		httpClient:   &http.Client{},
+		rateLimiter:  ratelimit.NewRateLimiter(40),
+		timeout:      DefaultTimeout,
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
 	}
 }
 
@@ -56,17 +98,31 @@ func (c *Client) SetQPS(qps int) {
 	}
 }
 
+// SetTimeout 设置单次请求超时时间（每次重试单独计时）
+func (c *Client) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeout = timeout
+}
+
+// SetRetry 设置服务不可达（超时、网络错误、5xx、429）时的重试次数和首次重试等待时间
+func (c *Client) SetRetry(maxRetries int, backoff time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxRetries = max(maxRetries, 0)
+	if backoff > 0 {
+		c.retryBackoff = backoff
+	}
+}
+
 func (c *Client) makeRequest(ctx context.Context, method, endpoint string, params url.Values, result interface{}) error {
 	if c.APIKey == "" {
 		return fmt.Errorf("TMDB API key is not set")
 	}
 
-	if c.rateLimiter != nil {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return fmt.Errorf("rate limit exceeded: %w", err)
-		}
-	}
-
 	if params == nil {
 		params = url.Values{}
 	}
@@ -74,6 +130,7 @@ func (c *Client) makeRequest(ctx context.Context, method, endpoint string, param
 
 	c.mu.RLock()
 	lang := c.Language
+	timeout, maxRetries, backoff := c.timeout, c.maxRetries, c.retryBackoff
 	c.mu.RUnlock()
 
 	if lang != "" {
@@ -88,17 +145,55 @@ func (c *Client) makeRequest(ctx context.Context, method, endpoint string, param
 		"endpoint", endpoint,
 		"language", lang)
 
-	opts := httputil.DefaultOptions().
-		WithContext(ctx).
-		WithClient(c.httpClient)
+	// 只重试服务不可达的错误；404、无搜索结果等直接返回
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.doRequest(ctx, method, urlStr, timeout, result)
+		if err == nil {
+			return nil
+		}
+		if !isUnavailableError(err) || attempt >= maxRetries || ctx.Err() != nil {
+			break
+		}
+
+		wait := backoff << attempt
+		logger.Warn("TMDB API Request failed, retrying",
+			"endpoint", endpoint,
+			"attempt", attempt+1,
+			"maxRetries", maxRetries,
+			"wait", wait,
+			"error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ErrUnavailable, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
 
-	err := httputil.DoJSONRequest(method, urlStr, nil, result, opts)
-	if err != nil {
-		logger.Error("TMDB API Request failed", "endpoint", endpoint, "error", err)
+	logger.Error("TMDB API Request failed", "endpoint", endpoint, "error", err)
+	if isUnavailableError(err) {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
 	}
 	return err
 }
 
+// doRequest 发送单次请求，超时通过 context 控制，每次请求都经过限流
+func (c *Client) doRequest(ctx context.Context, method, urlStr string, timeout time.Duration, result interface{}) error {
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit exceeded: %w", err)
+		}
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts := httputil.DefaultOptions().
+		WithContext(reqCtx).
+		WithClient(c.httpClient)
+	return httputil.DoJSONRequest(method, urlStr, nil, result, opts)
+}
+
 func (c *Client) SearchMovie(ctx context.Context, query string, year int) (*SearchMovieResponse, error) {
 	params := url.Values{}
 	params.Set("query", query)
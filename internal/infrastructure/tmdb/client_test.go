@@ -0,0 +1,121 @@
+package tmdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMakeRequestRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       func(attempt int32, w http.ResponseWriter)
+		wantAttempts  int32
+		wantErr       bool
+		wantUnavail   bool
+		wantNotFound  bool
+		wantResultLen int
+	}{
+		{
+			name: "5xx 后重试成功",
+			handler: func(attempt int32, w http.ResponseWriter) {
+				if attempt < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Write([]byte(`{"results":[{"id":1,"name":"Show"}],"total_results":1}`))
+			},
+			wantAttempts:  3,
+			wantResultLen: 1,
+		},
+		{
+			name: "响应过慢，超时后重试直至用尽",
+			handler: func(attempt int32, w http.ResponseWriter) {
+				time.Sleep(200 * time.Millisecond)
+				w.Write([]byte(`{"results":[]}`))
+			},
+			wantAttempts: 3,
+			wantErr:      true,
+			wantUnavail:  true,
+		},
+		{
+			name: "404 不重试",
+			handler: func(attempt int32, w http.ResponseWriter) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantAttempts: 1,
+			wantErr:      true,
+			wantNotFound: true,
+		},
+		{
+			name: "无搜索结果不重试",
+			handler: func(attempt int32, w http.ResponseWriter) {
+				w.Write([]byte(`{"results":[],"total_results":0}`))
+			},
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(attempts.Add(1), w)
+			}))
+			defer server.Close()
+
+			client := NewClient("test-key")
+			client.BaseURL = server.URL
+			client.SetTimeout(50 * time.Millisecond)
+			client.SetRetry(2, time.Millisecond)
+
+			resp, err := client.SearchTV(context.Background(), "Show", 0)
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchTV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if IsUnavailable(err) != tt.wantUnavail {
+				t.Errorf("IsUnavailable() = %v, want %v (err: %v)", IsUnavailable(err), tt.wantUnavail, err)
+			}
+			if IsNotFound(err) != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v (err: %v)", IsNotFound(err), tt.wantNotFound, err)
+			}
+			if err == nil && len(resp.Results) != tt.wantResultLen {
+				t.Errorf("len(Results) = %d, want %d", len(resp.Results), tt.wantResultLen)
+			}
+		})
+	}
+}
+
+func TestMakeRequestStopsOnCancel(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.BaseURL = server.URL
+	client.SetRetry(5, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetSeasonDetails(ctx, 1, 1)
+	if !IsUnavailable(err) {
+		t.Fatalf("GetSeasonDetails() error = %v, want unavailable", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("retry did not stop when the caller's context ended, took %v", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("attempts = %d, want 1", got)
+	}
+}
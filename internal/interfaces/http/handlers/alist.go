@@ -1,8 +1,7 @@
 package handlers
 
 import (
-	"net/http"
-
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/alist"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	httputil "github.com/easayliu/alist-aria2-download/pkg/utils/http"
@@ -35,14 +34,14 @@ func ListFiles(c *gin.Context) {
 
 	// 绑定查询参数
 	if err := c.ShouldBindQuery(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -67,7 +66,7 @@ func ListFiles(c *gin.Context) {
 	// 获取文件列表
 	fileList, err := client.ListFiles(req.Path, req.Page, req.PerPage)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get file list: "+err.Error())
+		respondError(c, err, "Failed to get file list")
 		return
 	}
 
@@ -119,14 +118,14 @@ func GetFileInfo(c *gin.Context) {
 
 	// 绑定查询参数
 	if err := c.ShouldBindQuery(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -136,7 +135,7 @@ func GetFileInfo(c *gin.Context) {
 	// 获取文件信息
 	fileInfo, err := client.GetFileInfo(req.Path)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get file info: "+err.Error())
+		respondError(c, err, "Failed to get file info")
 		return
 	}
 
@@ -171,7 +170,7 @@ func GetFileInfo(c *gin.Context) {
 func AlistLogin(c *gin.Context) {
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -183,7 +182,7 @@ func AlistLogin(c *gin.Context) {
 	// 通过调用API测试连接和登录（客户端会自动处理token刷新）
 	_, err = client.ListFiles("/", 1, 1)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusUnauthorized, 401, "Failed to connect to Alist: "+err.Error())
+		respondErrorCode(c, contracts.ErrorCodeUnauthorized, "Failed to connect to Alist: "+err.Error())
 		return
 	}
 
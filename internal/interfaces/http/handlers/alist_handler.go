@@ -1,8 +1,7 @@
 package handlers
 
 import (
-	"net/http"
-
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/application/services"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/alist"
 	httputil "github.com/easayliu/alist-aria2-download/pkg/utils/http"
@@ -41,7 +40,7 @@ func (h *AlistHandler) ListFiles(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindQuery(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
@@ -63,7 +62,7 @@ func (h *AlistHandler) ListFiles(c *gin.Context) {
 	client := alist.NewClient(cfg.Alist.BaseURL, cfg.Alist.Username, cfg.Alist.Password)
 	fileList, err := client.ListFiles(req.Path, req.Page, req.PerPage)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get file list: "+err.Error())
+		respondError(c, err, "Failed to get file list")
 		return
 	}
 
@@ -107,7 +106,7 @@ func (h *AlistHandler) GetFileInfo(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindQuery(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
@@ -116,7 +115,7 @@ func (h *AlistHandler) GetFileInfo(c *gin.Context) {
 
 	fileInfo, err := client.GetFileInfo(req.Path)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get file info: "+err.Error())
+		respondError(c, err, "Failed to get file info")
 		return
 	}
 
@@ -154,7 +153,7 @@ func (h *AlistHandler) Login(c *gin.Context) {
 
 	_, err := client.ListFiles("/", 1, 1)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusUnauthorized, 401, "Failed to connect to Alist: "+err.Error())
+		respondErrorCode(c, contracts.ErrorCodeUnauthorized, "Failed to connect to Alist: "+err.Error())
 		return
 	}
 
@@ -1,9 +1,9 @@
 package handlers
 
 import (
-	"net/http"
 	"strconv"
 
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/aria2"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	httputil "github.com/easayliu/alist-aria2-download/pkg/utils/http"
@@ -33,14 +33,14 @@ func CreateDownload(c *gin.Context) {
 	var req CreateDownloadRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -68,7 +68,7 @@ func CreateDownload(c *gin.Context) {
 	// 添加下载任务
 	gid, err := aria2Client.AddURI(req.URL, options)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to create download: "+err.Error())
+		respondError(c, err, "Failed to create download")
 		return
 	}
 
@@ -91,7 +91,7 @@ func ListDownloads(c *gin.Context) {
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -101,21 +101,21 @@ func ListDownloads(c *gin.Context) {
 	// 获取活动下载
 	active, err := aria2Client.GetActive()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get active downloads: "+err.Error())
+		respondError(c, err, "Failed to get active downloads")
 		return
 	}
 
 	// 获取等待下载
 	waiting, err := aria2Client.GetWaiting(0, 100)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get waiting downloads: "+err.Error())
+		respondError(c, err, "Failed to get waiting downloads")
 		return
 	}
 
 	// 获取已停止下载
 	stopped, err := aria2Client.GetStopped(0, 100)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get stopped downloads: "+err.Error())
+		respondError(c, err, "Failed to get stopped downloads")
 		return
 	}
 
@@ -148,7 +148,7 @@ func GetDownload(c *gin.Context) {
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -158,7 +158,7 @@ func GetDownload(c *gin.Context) {
 	// 获取下载状态
 	status, err := aria2Client.GetStatus(gid)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get download status: "+err.Error())
+		respondError(c, err, "Failed to get download status")
 		return
 	}
 
@@ -200,7 +200,7 @@ func DeleteDownload(c *gin.Context) {
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -209,7 +209,7 @@ func DeleteDownload(c *gin.Context) {
 
 	// 删除下载
 	if err := aria2Client.Remove(gid); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to delete download: "+err.Error())
+		respondError(c, err, "Failed to delete download")
 		return
 	}
 
@@ -233,7 +233,7 @@ func PauseDownload(c *gin.Context) {
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -242,7 +242,7 @@ func PauseDownload(c *gin.Context) {
 
 	// 暂停下载
 	if err := aria2Client.Pause(gid); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to pause download: "+err.Error())
+		respondError(c, err, "Failed to pause download")
 		return
 	}
 
@@ -266,7 +266,7 @@ func ResumeDownload(c *gin.Context) {
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to load config")
+		respondErrorCode(c, contracts.ErrorCodeInternalError, "Failed to load config")
 		return
 	}
 
@@ -275,7 +275,7 @@ func ResumeDownload(c *gin.Context) {
 
 	// 恢复下载
 	if err := aria2Client.Resume(gid); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to resume download: "+err.Error())
+		respondError(c, err, "Failed to resume download")
 		return
 	}
 
@@ -1,8 +1,6 @@
 package handlers
 
 import (
-	"net/http"
-
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/application/services"
 	httputil "github.com/easayliu/alist-aria2-download/pkg/utils/http"
@@ -33,14 +31,14 @@ func NewDownloadHandler(container *services.ServiceContainer) *DownloadHandler {
 func (h *DownloadHandler) CreateDownload(c *gin.Context) {
 	var req contracts.DownloadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	downloadService := h.container.GetDownloadService()
 	response, err := downloadService.CreateDownload(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to create download: "+err.Error())
+		respondError(c, err, "Failed to create download")
 		return
 	}
 
@@ -61,14 +59,14 @@ func (h *DownloadHandler) CreateDownload(c *gin.Context) {
 func (h *DownloadHandler) ListDownloads(c *gin.Context) {
 	var req contracts.DownloadListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	downloadService := h.container.GetDownloadService()
 	response, err := downloadService.ListDownloads(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to list downloads: "+err.Error())
+		respondError(c, err, "Failed to list downloads")
 		return
 	}
 
@@ -87,14 +85,14 @@ func (h *DownloadHandler) ListDownloads(c *gin.Context) {
 func (h *DownloadHandler) GetDownload(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Download ID is required")
+		respondInvalidRequest(c, "Download ID is required")
 		return
 	}
 
 	downloadService := h.container.GetDownloadService()
 	response, err := downloadService.GetDownload(c.Request.Context(), id)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusNotFound, 404, "Download not found: "+err.Error())
+		respondError(c, err, "Failed to get download")
 		return
 	}
 
@@ -113,13 +111,13 @@ func (h *DownloadHandler) GetDownload(c *gin.Context) {
 func (h *DownloadHandler) DeleteDownload(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Download ID is required")
+		respondInvalidRequest(c, "Download ID is required")
 		return
 	}
 
 	downloadService := h.container.GetDownloadService()
 	if err := downloadService.CancelDownload(c.Request.Context(), id); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to delete download: "+err.Error())
+		respondError(c, err, "Failed to delete download")
 		return
 	}
 
@@ -140,13 +138,13 @@ func (h *DownloadHandler) DeleteDownload(c *gin.Context) {
 func (h *DownloadHandler) PauseDownload(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Download ID is required")
+		respondInvalidRequest(c, "Download ID is required")
 		return
 	}
 
 	downloadService := h.container.GetDownloadService()
 	if err := downloadService.PauseDownload(c.Request.Context(), id); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to pause download: "+err.Error())
+		respondError(c, err, "Failed to pause download")
 		return
 	}
 
@@ -167,13 +165,13 @@ func (h *DownloadHandler) PauseDownload(c *gin.Context) {
 func (h *DownloadHandler) ResumeDownload(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Download ID is required")
+		respondInvalidRequest(c, "Download ID is required")
 		return
 	}
 
 	downloadService := h.container.GetDownloadService()
 	if err := downloadService.ResumeDownload(c.Request.Context(), id); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to resume download: "+err.Error())
+		respondError(c, err, "Failed to resume download")
 		return
 	}
 
@@ -196,14 +194,14 @@ func (h *DownloadHandler) ResumeDownload(c *gin.Context) {
 func (h *DownloadHandler) CreateBatchDownload(c *gin.Context) {
 	var req contracts.BatchDownloadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	downloadService := h.container.GetDownloadService()
 	response, err := downloadService.CreateBatchDownload(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to create batch download: "+err.Error())
+		respondError(c, err, "Failed to create batch download")
 		return
 	}
 
@@ -213,23 +211,53 @@ func (h *DownloadHandler) CreateBatchDownload(c *gin.Context) {
 	})
 }
 
+// BatchCancelDownloads 批量取消下载任务
+// @Summary 批量取消下载任务
+// @Description 逐个取消指定的下载任务，单个任务失败不影响其余任务，返回每个GID的结果（cancelled/not_found/failed）
+// @Tags 下载管理
+// @Accept json
+// @Produce json
+// @Param request body contracts.BatchCancelRequest true "要取消的下载任务GID列表"
+// @Success 200 {object} map[string]interface{} "批量取消结果"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Router /downloads/batch-cancel [post]
+func (h *DownloadHandler) BatchCancelDownloads(c *gin.Context) {
+	var req contracts.BatchCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
+		return
+	}
+	if len(req.IDs) == 0 {
+		respondInvalidRequest(c, "At least one download ID is required")
+		return
+	}
+
+	result := h.container.GetDownloadService().CancelDownloads(c.Request.Context(), req.IDs)
+	httputil.Success(c, gin.H{
+		"message": "Batch cancel completed",
+		"result":  result,
+	})
+}
+
 // PauseAllDownloads 暂停所有下载
 // @Summary 暂停所有下载
 // @Description 暂停所有正在进行的下载任务
 // @Tags 下载管理
 // @Produce json
-// @Success 200 {object} map[string]string
+// @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /downloads/pause-all [post]
 func (h *DownloadHandler) PauseAllDownloads(c *gin.Context) {
 	downloadService := h.container.GetDownloadService()
-	if err := downloadService.PauseAllDownloads(c.Request.Context()); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to pause all downloads: "+err.Error())
+	affected, err := downloadService.PauseAllDownloads(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Failed to pause all downloads")
 		return
 	}
 
 	httputil.Success(c, gin.H{
-		"message": "All downloads paused successfully",
+		"message":  "All downloads paused successfully",
+		"affected": affected,
 	})
 }
 
@@ -238,18 +266,20 @@ func (h *DownloadHandler) PauseAllDownloads(c *gin.Context) {
 // @Description 恢复所有已暂停的下载任务
 // @Tags 下载管理
 // @Produce json
-// @Success 200 {object} map[string]string
+// @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /downloads/resume-all [post]
 func (h *DownloadHandler) ResumeAllDownloads(c *gin.Context) {
 	downloadService := h.container.GetDownloadService()
-	if err := downloadService.ResumeAllDownloads(c.Request.Context()); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to resume all downloads: "+err.Error())
+	affected, err := downloadService.ResumeAllDownloads(c.Request.Context())
+	if err != nil {
+		respondError(c, err, "Failed to resume all downloads")
 		return
 	}
 
 	httputil.Success(c, gin.H{
-		"message": "All downloads resumed successfully",
+		"message":  "All downloads resumed successfully",
+		"affected": affected,
 	})
 }
 
@@ -265,7 +295,7 @@ func (h *DownloadHandler) GetDownloadStatistics(c *gin.Context) {
 	downloadService := h.container.GetDownloadService()
 	stats, err := downloadService.GetDownloadStatistics(c.Request.Context())
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get statistics: "+err.Error())
+		respondError(c, err, "Failed to get statistics")
 		return
 	}
 
@@ -284,7 +314,7 @@ func (h *DownloadHandler) GetSystemStatus(c *gin.Context) {
 	downloadService := h.container.GetDownloadService()
 	status, err := downloadService.GetSystemStatus(c.Request.Context())
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get system status: "+err.Error())
+		respondError(c, err, "Failed to get system status")
 		return
 	}
 
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"net/http"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/application/services"
@@ -47,7 +46,7 @@ func (h *FileHandler) GetYesterdayFiles(c *gin.Context) {
 	// 调用服务获取昨天的文件
 	response, err := fileService.GetYesterdayFiles(ctx, path)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get yesterday files: "+err.Error())
+		respondError(c, err, "Failed to get yesterday files")
 		return
 	}
 
@@ -87,7 +86,7 @@ func (h *FileHandler) DownloadYesterdayFiles(c *gin.Context) {
 	// 先获取昨天的文件列表
 	filesResp, err := fileService.GetYesterdayFiles(ctx, path)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get yesterday files: "+err.Error())
+		respondError(c, err, "Failed to get yesterday files")
 		return
 	}
 
@@ -121,6 +120,7 @@ func (h *FileHandler) DownloadYesterdayFiles(c *gin.Context) {
 			Filename:     file.Name,
 			Directory:    file.DownloadPath,
 			AutoClassify: true,
+			SourcePath:   file.Path,
 		})
 	}
 
@@ -134,7 +134,7 @@ func (h *FileHandler) DownloadYesterdayFiles(c *gin.Context) {
 	downloadService := h.container.GetDownloadService()
 	batchResponse, err := downloadService.CreateBatchDownload(ctx, batchRequest)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to create batch download: "+err.Error())
+		respondError(c, err, "Failed to create batch download")
 		return
 	}
 
@@ -165,7 +165,10 @@ func (h *FileHandler) DownloadFilesFromPath(c *gin.Context) {
 	var req contracts.DirectoryDownloadRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
+		return
+	}
+	if h.rejectDeletingResume(c, req) {
 		return
 	}
 
@@ -174,7 +177,7 @@ func (h *FileHandler) DownloadFilesFromPath(c *gin.Context) {
 	// 调用目录下载服务
 	batchResponse, err := fileService.DownloadDirectory(ctx, req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to download files: "+err.Error())
+		respondError(c, err, "Failed to download files")
 		return
 	}
 
@@ -185,11 +188,61 @@ func (h *FileHandler) DownloadFilesFromPath(c *gin.Context) {
 		"total":         len(batchResponse.Results),
 		"success_count": batchResponse.SuccessCount,
 		"fail_count":    batchResponse.FailureCount,
+		"pending_count": batchResponse.PendingCount, // 分批提交时在后台等待提交的文件数
+		"resumed_count": batchResponse.ResumedCount, // 从断点继续时跳过的已提交文件数
 		"summary":       batchResponse.Summary,
 		"results":       batchResponse.Results,
 	})
 }
 
+// DownloadDirectory 下载目录，preview=true 时只统计不创建任务
+// @Summary 下载目录
+// @Description 将目录中的文件添加到Aria2下载队列；preview=true 时只返回文件数、总大小和分类统计，不创建任务（不返回 results）
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Param request body contracts.DirectoryDownloadRequest true "目录下载请求"
+// @Success 200 {object} map[string]interface{} "下载任务创建结果或预览统计"
+// @Failure 400 {object} map[string]interface{} "请求参数错误"
+// @Failure 500 {object} map[string]interface{} "服务器内部错误"
+// @Router /files/download-directory [post]
+func (h *FileHandler) DownloadDirectory(c *gin.Context) {
+	var req contracts.DirectoryDownloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
+		return
+	}
+	if req.DirectoryPath == "" {
+		respondInvalidRequest(c, "directory_path is required")
+		return
+	}
+	if h.rejectDeletingResume(c, req) {
+		return
+	}
+
+	batchResponse, err := h.container.GetFileService().DownloadDirectory(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err, "Failed to download directory")
+		return
+	}
+
+	data := gin.H{
+		"source_path":   req.DirectoryPath,
+		"preview":       req.Preview,
+		"summary":       batchResponse.Summary,
+		"resumed_count": batchResponse.ResumedCount,
+	}
+	if !req.Preview {
+		data["batch_id"] = batchResponse.BatchID
+		data["success_count"] = batchResponse.SuccessCount
+		data["failure_count"] = batchResponse.FailureCount
+		data["pending_count"] = batchResponse.PendingCount
+		data["chunk_count"] = batchResponse.ChunkCount
+		data["results"] = batchResponse.Results
+	}
+	httputil.Success(c, data)
+}
+
 // ListFilesHandler 列出指定路径的文件
 // @Summary 列出指定路径的文件
 // @Description 获取指定路径下的文件列表，支持分页和视频文件过滤
@@ -206,7 +259,7 @@ func (h *FileHandler) ListFilesHandler(c *gin.Context) {
 	var req contracts.FileListRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
@@ -226,7 +279,7 @@ func (h *FileHandler) ListFilesHandler(c *gin.Context) {
 	// 调用文件列表服务
 	response, err := fileService.ListFiles(ctx, req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to list files: "+err.Error())
+		respondError(c, err, "Failed to list files")
 		return
 	}
 
@@ -264,7 +317,7 @@ func (h *FileHandler) ManualDownloadFiles(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
@@ -278,7 +331,7 @@ func (h *FileHandler) ManualDownloadFiles(c *gin.Context) {
 	// 调用时间范围文件查询服务
 	timeRangeResp, err := fileService.GetFilesByTimeRange(ctx, req.TimeRangeFileRequest)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get files by time range: "+err.Error())
+		respondError(c, err, "Failed to get files by time range")
 		return
 	}
 
@@ -313,6 +366,7 @@ func (h *FileHandler) ManualDownloadFiles(c *gin.Context) {
 			Filename:     file.Name,
 			Directory:    file.DownloadPath,
 			AutoClassify: true,
+			SourcePath:   file.Path,
 		})
 	}
 
@@ -326,7 +380,7 @@ func (h *FileHandler) ManualDownloadFiles(c *gin.Context) {
 	downloadService := h.container.GetDownloadService()
 	batchResponse, err := downloadService.CreateBatchDownload(ctx, batchRequest)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to create batch download: "+err.Error())
+		respondError(c, err, "Failed to create batch download")
 		return
 	}
 
@@ -360,7 +414,7 @@ func (h *FileHandler) SearchFiles(c *gin.Context) {
 	var req contracts.FileSearchRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
@@ -371,16 +425,17 @@ func (h *FileHandler) SearchFiles(c *gin.Context) {
 	fileService := h.container.GetFileService()
 	response, err := fileService.SearchFiles(ctx, req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to search files: "+err.Error())
+		respondError(c, err, "Failed to search files")
 		return
 	}
 
 	httputil.Success(c, gin.H{
-		"query":   req.Query,
-		"path":    req.Path,
-		"total":   response.TotalCount,
-		"files":   response.Files,
-		"summary": response.Summary,
+		"query":     req.Query,
+		"path":      req.Path,
+		"total":     response.TotalCount,
+		"files":     response.Files,
+		"summary":   response.Summary,
+		"truncated": response.Truncated,
 	})
 }
 
@@ -400,7 +455,7 @@ func (h *FileHandler) GetFilesByTimeRange(c *gin.Context) {
 	var req contracts.TimeRangeFileRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
@@ -411,7 +466,7 @@ func (h *FileHandler) GetFilesByTimeRange(c *gin.Context) {
 	fileService := h.container.GetFileService()
 	response, err := fileService.GetFilesByTimeRange(ctx, req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get files by time range: "+err.Error())
+		respondError(c, err, "Failed to get files by time range")
 		return
 	}
 
@@ -441,7 +496,7 @@ func (h *FileHandler) GetRecentFiles(c *gin.Context) {
 	var req contracts.RecentFilesRequest
 
 	if err := c.ShouldBindQuery(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
@@ -452,7 +507,7 @@ func (h *FileHandler) GetRecentFiles(c *gin.Context) {
 	fileService := h.container.GetFileService()
 	response, err := fileService.GetRecentFiles(ctx, req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get recent files: "+err.Error())
+		respondError(c, err, "Failed to get recent files")
 		return
 	}
 
@@ -481,14 +536,14 @@ func (h *FileHandler) ClassifyFiles(c *gin.Context) {
 	var req contracts.FileClassificationRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
 	fileService := h.container.GetFileService()
 	response, err := fileService.ClassifyFiles(ctx, req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to classify files: "+err.Error())
+		respondError(c, err, "Failed to classify files")
 		return
 	}
 
@@ -520,7 +575,7 @@ func (h *FileHandler) GetFilesByCategory(c *gin.Context) {
 	fileService := h.container.GetFileService()
 	response, err := fileService.GetFilesByCategory(ctx, path, category)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get files by category: "+err.Error())
+		respondError(c, err, "Failed to get files by category")
 		return
 	}
 
@@ -549,14 +604,14 @@ func (h *FileHandler) DownloadSingleFile(c *gin.Context) {
 	var req contracts.FileDownloadRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
 	fileService := h.container.GetFileService()
 	response, err := fileService.DownloadFile(ctx, req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to download file: "+err.Error())
+		respondError(c, err, "Failed to download file")
 		return
 	}
 
@@ -566,3 +621,16 @@ func (h *FileHandler) DownloadSingleFile(c *gin.Context) {
 		"download": response,
 	})
 }
+
+// rejectDeletingResume 拒绝通过 HTTP 继续开启了“下载后删除源文件”的目录断点（该断点只能由 Telegram 管理员继续）
+func (h *FileHandler) rejectDeletingResume(c *gin.Context, req contracts.DirectoryDownloadRequest) bool {
+	if !req.Resume {
+		return false
+	}
+	checkpoint, ok := h.container.GetFileService().GetDirectoryCheckpoint(req.DirectoryPath)
+	if !ok || !checkpoint.DeleteAfterDownload {
+		return false
+	}
+	respondErrorCode(c, contracts.ErrorCodeForbidden, "This checkpoint deletes source files after download and can only be resumed by a Telegram admin")
+	return true
+}
@@ -1,21 +1,32 @@
 package handlers
 
 import (
-	"net/http"
-
+	"github.com/easayliu/alist-aria2-download/internal/application/services"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/utils/http"
 	"github.com/gin-gonic/gin"
 )
 
+type HealthHandler struct {
+	container *services.ServiceContainer
+}
+
+func NewHealthHandler(container *services.ServiceContainer) *HealthHandler {
+	return &HealthHandler{
+		container: container,
+	}
+}
+
 // HealthCheck 健康检查
 // @Summary 健康检查
-// @Description 检查服务健康状态
+// @Description 检查服务健康状态，包含 aria2 连接状态（aria2 不可用时为 degraded）
 // @Tags 健康检查
 // @Produce json
-// @Success 200 {object} map[string]string
+// @Success 200 {object} map[string]interface{}
 // @Router /health [get]
-func HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+func (h *HealthHandler) HealthCheck(c *gin.Context) {
+	httputil.Success(c, gin.H{
 		"status":  "ok",
 		"message": "Alist Aria2 Download service is running",
+		"health":  h.container.GetHealthStatus(),
 	})
 }
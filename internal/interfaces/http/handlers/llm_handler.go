@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
@@ -90,7 +89,7 @@ func (h *LLMHandler) Generate(c *gin.Context) {
 	var req GenerateRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "无效的请求参数: "+err.Error())
+		respondInvalidRequest(c, "无效的请求参数: "+err.Error())
 		return
 	}
 
@@ -99,7 +98,7 @@ func (h *LLMHandler) Generate(c *gin.Context) {
 
 	// 检查LLM是否启用
 	if !llmService.IsEnabled() {
-		httputil.ErrorWithStatus(c, http.StatusServiceUnavailable, 503, "LLM功能未启用，请在配置文件中配置LLM Provider")
+		respondErrorCode(c, contracts.ErrorCodeServiceUnavailable, "LLM功能未启用，请在配置文件中配置LLM Provider")
 		return
 	}
 
@@ -118,7 +117,7 @@ func (h *LLMHandler) Generate(c *gin.Context) {
 	// 调用LLM生成
 	text, err := llmService.GenerateText(ctx, req.Prompt, opts...)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "LLM生成失败: "+err.Error())
+		respondError(c, err, "LLM生成失败")
 		return
 	}
 
@@ -145,7 +144,7 @@ func (h *LLMHandler) Generate(c *gin.Context) {
 func (h *LLMHandler) Stream(c *gin.Context) {
 	prompt := c.Query("prompt")
 	if prompt == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "缺少必需参数: prompt")
+		respondInvalidRequest(c, "缺少必需参数: prompt")
 		return
 	}
 
@@ -156,7 +155,7 @@ func (h *LLMHandler) Stream(c *gin.Context) {
 
 	// 检查LLM是否启用
 	if !llmService.IsEnabled() {
-		httputil.ErrorWithStatus(c, http.StatusServiceUnavailable, 503, "LLM功能未启用")
+		respondErrorCode(c, contracts.ErrorCodeServiceUnavailable, "LLM功能未启用")
 		return
 	}
 
@@ -225,7 +224,7 @@ func (h *LLMHandler) RenameWithLLM(c *gin.Context) {
 	var req LLMRenameRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "无效的请求参数: "+err.Error())
+		respondInvalidRequest(c, "无效的请求参数: "+err.Error())
 		return
 	}
 
@@ -235,14 +234,14 @@ func (h *LLMHandler) RenameWithLLM(c *gin.Context) {
 	// 统一使用批量TMDB模式(即使只有单个文件)
 	suggestionsMap, _, err := fileService.GetBatchRenameSuggestionsWithLLM(ctx, []string{req.FilePath})
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "重命名失败: "+err.Error())
+		respondError(c, err, "重命名失败")
 		return
 	}
 
 	// 获取结果
 	suggestions, found := suggestionsMap[req.FilePath]
 	if !found || len(suggestions) == 0 {
-		httputil.ErrorWithStatus(c, http.StatusNotFound, 404, "未找到匹配的重命名建议")
+		respondErrorCode(c, contracts.ErrorCodeNotFound, "未找到匹配的重命名建议")
 		return
 	}
 
@@ -266,12 +265,12 @@ func (h *LLMHandler) BatchRenameWithLLM(c *gin.Context) {
 	var req BatchLLMRenameRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "无效的请求参数: "+err.Error())
+		respondInvalidRequest(c, "无效的请求参数: "+err.Error())
 		return
 	}
 
 	if len(req.FilePaths) == 0 {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "文件路径列表不能为空")
+		respondInvalidRequest(c, "文件路径列表不能为空")
 		return
 	}
 
@@ -281,7 +280,7 @@ func (h *LLMHandler) BatchRenameWithLLM(c *gin.Context) {
 	// 统一使用批量TMDB模式
 	suggestionsMap, _, err := fileService.GetBatchRenameSuggestionsWithLLM(ctx, req.FilePaths)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "批量重命名失败: "+err.Error())
+		respondError(c, err, "批量重命名失败")
 		return
 	}
 
@@ -338,7 +337,7 @@ func (h *LLMHandler) StreamRename(c *gin.Context) {
 	var req StreamRenameRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "无效的请求参数: "+err.Error())
+		respondInvalidRequest(c, "无效的请求参数: "+err.Error())
 		return
 	}
 
@@ -347,7 +346,7 @@ func (h *LLMHandler) StreamRename(c *gin.Context) {
 
 	// 检查LLM是否启用
 	if !llmService.IsEnabled() {
-		httputil.ErrorWithStatus(c, http.StatusServiceUnavailable, 503, "LLM功能未启用")
+		respondErrorCode(c, contracts.ErrorCodeServiceUnavailable, "LLM功能未启用")
 		return
 	}
 
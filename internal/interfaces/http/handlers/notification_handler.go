@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"net/http"
 	"strconv"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
@@ -34,14 +33,14 @@ func NewNotificationHandler(container *services.ServiceContainer) *NotificationH
 func (h *NotificationHandler) SendNotification(c *gin.Context) {
 	var req contracts.NotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	notificationService := h.container.GetNotificationService()
 	response, err := notificationService.SendNotification(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to send notification: "+err.Error())
+		respondError(c, err, "Failed to send notification")
 		return
 	}
 
@@ -65,14 +64,14 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 func (h *NotificationHandler) SendBatchNotifications(c *gin.Context) {
 	var req contracts.BatchNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	notificationService := h.container.GetNotificationService()
 	response, err := notificationService.SendBatchNotifications(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to send batch notifications: "+err.Error())
+		respondError(c, err, "Failed to send batch notifications")
 		return
 	}
 
@@ -112,7 +111,7 @@ func (h *NotificationHandler) GetNotificationHistory(c *gin.Context) {
 	notificationService := h.container.GetNotificationService()
 	history, err := notificationService.GetNotificationHistory(c.Request.Context(), limit, offset)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get notification history: "+err.Error())
+		respondError(c, err, "Failed to get notification history")
 		return
 	}
 
@@ -136,7 +135,7 @@ func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
 	notificationService := h.container.GetNotificationService()
 	stats, err := notificationService.GetNotificationStats(c.Request.Context())
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get notification stats: "+err.Error())
+		respondError(c, err, "Failed to get notification stats")
 		return
 	}
 
@@ -157,14 +156,14 @@ func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
 func (h *NotificationHandler) NotifyDownloadComplete(c *gin.Context) {
 	var req contracts.DownloadNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	notificationService := h.container.GetNotificationService()
 	err := notificationService.NotifyDownloadComplete(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to send download complete notification: "+err.Error())
+		respondError(c, err, "Failed to send download complete notification")
 		return
 	}
 
@@ -187,14 +186,14 @@ func (h *NotificationHandler) NotifyDownloadComplete(c *gin.Context) {
 func (h *NotificationHandler) NotifyDownloadFailed(c *gin.Context) {
 	var req contracts.DownloadNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	notificationService := h.container.GetNotificationService()
 	err := notificationService.NotifyDownloadFailed(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to send download failed notification: "+err.Error())
+		respondError(c, err, "Failed to send download failed notification")
 		return
 	}
 
@@ -217,14 +216,14 @@ func (h *NotificationHandler) NotifyDownloadFailed(c *gin.Context) {
 func (h *NotificationHandler) NotifyTaskComplete(c *gin.Context) {
 	var req contracts.TaskNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	notificationService := h.container.GetNotificationService()
 	err := notificationService.NotifyTaskComplete(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to send task complete notification: "+err.Error())
+		respondError(c, err, "Failed to send task complete notification")
 		return
 	}
 
@@ -247,14 +246,14 @@ func (h *NotificationHandler) NotifyTaskComplete(c *gin.Context) {
 func (h *NotificationHandler) NotifyTaskFailed(c *gin.Context) {
 	var req contracts.TaskNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	notificationService := h.container.GetNotificationService()
 	err := notificationService.NotifyTaskFailed(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to send task failed notification: "+err.Error())
+		respondError(c, err, "Failed to send task failed notification")
 		return
 	}
 
@@ -277,14 +276,14 @@ func (h *NotificationHandler) NotifyTaskFailed(c *gin.Context) {
 func (h *NotificationHandler) NotifySystemEvent(c *gin.Context) {
 	var req contracts.SystemNotificationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request: "+err.Error())
+		respondInvalidRequest(c, "Invalid request: "+err.Error())
 		return
 	}
 
 	notificationService := h.container.GetNotificationService()
 	err := notificationService.NotifySystemEvent(c.Request.Context(), req)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to send system event notification: "+err.Error())
+		respondError(c, err, "Failed to send system event notification")
 		return
 	}
 
@@ -305,7 +304,7 @@ func (h *NotificationHandler) GetNotificationConfig(c *gin.Context) {
 	notificationService := h.container.GetNotificationService()
 	config, err := notificationService.GetConfig(c.Request.Context())
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get notification config: "+err.Error())
+		respondError(c, err, "Failed to get notification config")
 		return
 	}
 
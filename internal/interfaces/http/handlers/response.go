@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/application/services/llm"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/utils/http"
+	"github.com/gin-gonic/gin"
+)
+
+// 统一错误响应：{"code": <HTTP状态码>, "message": "...", "error": "<业务错误码>"}
+// 成功响应统一使用 httputil.Success：{"code": 0, "message": "success", "data": ...}
+
+// respondError 根据错误类型映射业务错误码和HTTP状态码
+// ServiceError 直接使用其消息，其他错误在 message 后附加错误详情
+func respondError(c *gin.Context, err error, message string) {
+	var serviceErr *contracts.ServiceError
+	if errors.As(err, &serviceErr) {
+		respondErrorCode(c, serviceErr.Code, serviceErr.Message)
+		return
+	}
+	respondErrorCode(c, ErrorCodeFor(err), message+": "+err.Error())
+}
+
+// respondInvalidRequest 请求参数错误
+func respondInvalidRequest(c *gin.Context, message string) {
+	respondErrorCode(c, contracts.ErrorCodeInvalidRequest, message)
+}
+
+// respondErrorCode 使用指定业务错误码响应
+func respondErrorCode(c *gin.Context, code contracts.ErrorCode, message string) {
+	httputil.ErrorWithCode(c, HTTPStatusForErrorCode(code), string(code), message)
+}
+
+// ErrorCodeFor 将领域错误映射为业务错误码，未知错误视为内部错误
+func ErrorCodeFor(err error) contracts.ErrorCode {
+	var serviceErr *contracts.ServiceError
+	switch {
+	case errors.As(err, &serviceErr):
+		return serviceErr.Code
+	case errors.Is(err, contracts.ErrDownloadNotFound), errors.Is(err, contracts.ErrSnapshotNotFound), errors.Is(err, contracts.ErrTaskNotFound):
+		return contracts.ErrorCodeNotFound
+	case errors.Is(err, contracts.ErrReadOnly):
+		return contracts.ErrorCodeForbidden
+	case errors.Is(err, contracts.ErrAria2Unavailable), errors.Is(err, contracts.ErrAria2Unauthorized), errors.Is(err, llm.ErrLLMDisabled):
+		return contracts.ErrorCodeServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return contracts.ErrorCodeTimeout
+	default:
+		return contracts.ErrorCodeInternalError
+	}
+}
+
+// HTTPStatusForErrorCode 将业务错误码映射到HTTP状态码
+func HTTPStatusForErrorCode(code contracts.ErrorCode) int {
+	switch code {
+	case contracts.ErrorCodeInvalidRequest:
+		return http.StatusBadRequest
+	case contracts.ErrorCodeNotFound:
+		return http.StatusNotFound
+	case contracts.ErrorCodeUnauthorized:
+		return http.StatusUnauthorized
+	case contracts.ErrorCodeForbidden:
+		return http.StatusForbidden
+	case contracts.ErrorCodeConflict:
+		return http.StatusConflict
+	case contracts.ErrorCodeServiceUnavailable:
+		return http.StatusServiceUnavailable
+	case contracts.ErrorCodeTimeout:
+		return http.StatusRequestTimeout
+	case contracts.ErrorCodeRateLimit:
+		return http.StatusTooManyRequests
+	case contracts.ErrorCodeQuotaExceeded:
+		return http.StatusInsufficientStorage
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+)
+
+func TestErrorCodeFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   contracts.ErrorCode
+		wantStatus int
+	}{
+		{name: "业务错误", err: contracts.NewServiceError(contracts.ErrorCodeConflict, "exists"), wantCode: contracts.ErrorCodeConflict, wantStatus: http.StatusConflict},
+		{name: "下载任务不存在", err: fmt.Errorf("%w: abc", contracts.ErrDownloadNotFound), wantCode: contracts.ErrorCodeNotFound, wantStatus: http.StatusNotFound},
+		{name: "定时任务不存在", err: fmt.Errorf("%w: abc", contracts.ErrTaskNotFound), wantCode: contracts.ErrorCodeNotFound, wantStatus: http.StatusNotFound},
+		{name: "只读模式", err: contracts.ErrReadOnly, wantCode: contracts.ErrorCodeForbidden, wantStatus: http.StatusForbidden},
+		{name: "aria2不可用", err: fmt.Errorf("failed: %w", contracts.ErrAria2Unavailable), wantCode: contracts.ErrorCodeServiceUnavailable, wantStatus: http.StatusServiceUnavailable},
+		{name: "超时", err: fmt.Errorf("list: %w", context.DeadlineExceeded), wantCode: contracts.ErrorCodeTimeout, wantStatus: http.StatusRequestTimeout},
+		{name: "未知错误", err: errors.New("boom"), wantCode: contracts.ErrorCodeInternalError, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := ErrorCodeFor(tt.err)
+			if code != tt.wantCode {
+				t.Errorf("ErrorCodeFor() = %s, want %s", code, tt.wantCode)
+			}
+			if status := HTTPStatusForErrorCode(code); status != tt.wantStatus {
+				t.Errorf("HTTPStatusForErrorCode(%s) = %d, want %d", code, status, tt.wantStatus)
+			}
+		})
+	}
+}
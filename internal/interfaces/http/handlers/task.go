@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"net/http"
 	"strconv"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
@@ -22,6 +21,28 @@ func NewTaskHandler(container *services.ServiceContainer) *TaskHandler {
 	}
 }
 
+// chatPostChecker 检查机器人能否向指定聊天发送消息（由 Telegram 客户端实现）
+type chatPostChecker interface {
+	CheckCanPost(chatID int64) error
+}
+
+// checkNotifyChat 与 Telegram 的 notify= 参数一致，确认任务通知能发送到指定聊天；
+// 未启用 Telegram 时跳过检查。检查失败时已写入响应并返回 false
+func (h *TaskHandler) checkNotifyChat(c *gin.Context, chatID int64) bool {
+	if chatID == 0 {
+		return true
+	}
+	checker, ok := h.container.GetTelegramClient().(chatPostChecker)
+	if !ok {
+		return true
+	}
+	if err := checker.CheckCanPost(chatID); err != nil {
+		respondInvalidRequest(c, "Invalid notify_chat_id: "+err.Error())
+		return false
+	}
+	return true
+}
+
 // CreateTask 创建定时任务
 // @Summary 创建定时任务
 // @Description 创建一个新的定时任务，按照cron表达式定期执行
@@ -37,7 +58,10 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	// 1. 解析HTTP请求 - 协议转换
 	var req contracts.TaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
+		return
+	}
+	if !h.checkNotifyChat(c, req.NotifyChatID) {
 		return
 	}
 
@@ -45,13 +69,7 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	response, err := taskService.CreateTask(c.Request.Context(), req)
 	if err != nil {
-		// 错误映射
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to create task: "+err.Error())
-		}
+		respondError(c, err, "Failed to create task")
 		return
 	}
 
@@ -77,7 +95,7 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 	// 1. 提取路径参数
 	taskID := c.Param("id")
 	if taskID == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Task ID is required")
+		respondInvalidRequest(c, "Task ID is required")
 		return
 	}
 
@@ -85,12 +103,7 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	response, err := taskService.GetTask(c.Request.Context(), taskID)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusNotFound, 404, "Task not found")
-		}
+		respondError(c, err, "Failed to get task")
 		return
 	}
 
@@ -155,12 +168,7 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	response, err := taskService.ListTasks(c.Request.Context(), req)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get tasks: "+err.Error())
-		}
+		respondError(c, err, "Failed to get tasks")
 		return
 	}
 
@@ -185,14 +193,17 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	// 1. 提取路径参数
 	taskID := c.Param("id")
 	if taskID == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Task ID is required")
+		respondInvalidRequest(c, "Task ID is required")
 		return
 	}
 
 	// 2. 解析请求体
 	var req contracts.TaskUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
+		return
+	}
+	if req.NotifyChatID != nil && !h.checkNotifyChat(c, *req.NotifyChatID) {
 		return
 	}
 
@@ -200,12 +211,7 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	response, err := taskService.UpdateTask(c.Request.Context(), taskID, req)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to update task: "+err.Error())
-		}
+		respondError(c, err, "Failed to update task")
 		return
 	}
 
@@ -231,7 +237,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	// 1. 提取路径参数
 	taskID := c.Param("id")
 	if taskID == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Task ID is required")
+		respondInvalidRequest(c, "Task ID is required")
 		return
 	}
 
@@ -239,12 +245,7 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	err := taskService.DeleteTask(c.Request.Context(), taskID)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to delete task: "+err.Error())
-		}
+		respondError(c, err, "Failed to delete task")
 		return
 	}
 
@@ -271,7 +272,7 @@ func (h *TaskHandler) RunTaskNow(c *gin.Context) {
 	// 1. 提取路径参数
 	taskID := c.Param("id")
 	if taskID == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Task ID is required")
+		respondInvalidRequest(c, "Task ID is required")
 		return
 	}
 
@@ -291,12 +292,7 @@ func (h *TaskHandler) RunTaskNow(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	response, err := taskService.RunTaskNow(c.Request.Context(), req)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to run task: "+err.Error())
-		}
+		respondError(c, err, "Failed to run task")
 		return
 	}
 
@@ -322,7 +318,7 @@ func (h *TaskHandler) PreviewTask(c *gin.Context) {
 	// 1. 提取路径参数
 	taskID := c.Param("id")
 	if taskID == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Task ID is required")
+		respondInvalidRequest(c, "Task ID is required")
 		return
 	}
 
@@ -343,12 +339,7 @@ func (h *TaskHandler) PreviewTask(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	response, err := taskService.PreviewTask(c.Request.Context(), req)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to preview task: "+err.Error())
-		}
+		respondError(c, err, "Failed to preview task")
 		return
 	}
 
@@ -371,7 +362,7 @@ func (h *TaskHandler) EnableTask(c *gin.Context) {
 	// 1. 提取路径参数
 	taskID := c.Param("id")
 	if taskID == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Task ID is required")
+		respondInvalidRequest(c, "Task ID is required")
 		return
 	}
 
@@ -379,12 +370,7 @@ func (h *TaskHandler) EnableTask(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	err := taskService.EnableTask(c.Request.Context(), taskID)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to enable task: "+err.Error())
-		}
+		respondError(c, err, "Failed to enable task")
 		return
 	}
 
@@ -410,7 +396,7 @@ func (h *TaskHandler) DisableTask(c *gin.Context) {
 	// 1. 提取路径参数
 	taskID := c.Param("id")
 	if taskID == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Task ID is required")
+		respondInvalidRequest(c, "Task ID is required")
 		return
 	}
 
@@ -418,12 +404,7 @@ func (h *TaskHandler) DisableTask(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	err := taskService.DisableTask(c.Request.Context(), taskID)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to disable task: "+err.Error())
-		}
+		respondError(c, err, "Failed to disable task")
 		return
 	}
 
@@ -449,7 +430,7 @@ func (h *TaskHandler) CreateQuickTask(c *gin.Context) {
 	// 1. 解析请求
 	var req contracts.QuickTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid request parameters: "+err.Error())
+		respondInvalidRequest(c, "Invalid request parameters: "+err.Error())
 		return
 	}
 
@@ -457,12 +438,7 @@ func (h *TaskHandler) CreateQuickTask(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	response, err := taskService.CreateQuickTask(c.Request.Context(), req)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to create quick task: "+err.Error())
-		}
+		respondError(c, err, "Failed to create quick task")
 		return
 	}
 
@@ -486,7 +462,7 @@ func (h *TaskHandler) GetTaskStatistics(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	stats, err := taskService.GetTaskStatistics(c.Request.Context())
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get task statistics: "+err.Error())
+		respondError(c, err, "Failed to get task statistics")
 		return
 	}
 
@@ -507,7 +483,7 @@ func (h *TaskHandler) GetSchedulerStatus(c *gin.Context) {
 	taskService := h.container.GetTaskService()
 	status, err := taskService.GetSchedulerStatus(c.Request.Context())
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get scheduler status: "+err.Error())
+		respondError(c, err, "Failed to get scheduler status")
 		return
 	}
 
@@ -528,19 +504,14 @@ func (h *TaskHandler) GetSchedulerStatus(c *gin.Context) {
 func (h *TaskHandler) StopTask(c *gin.Context) {
 	taskID := c.Param("id")
 	if taskID == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Task ID is required")
+		respondInvalidRequest(c, "Task ID is required")
 		return
 	}
 
 	taskService := h.container.GetTaskService()
 	err := taskService.StopTask(c.Request.Context(), taskID)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to stop task: "+err.Error())
-		}
+		respondError(c, err, "Failed to stop task")
 		return
 	}
 
@@ -563,25 +534,20 @@ func (h *TaskHandler) StopTask(c *gin.Context) {
 func (h *TaskHandler) GetUserTasks(c *gin.Context) {
 	userIDStr := c.Param("user-id")
 	if userIDStr == "" {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "User ID is required")
+		respondInvalidRequest(c, "User ID is required")
 		return
 	}
 
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil {
-		httputil.ErrorWithStatus(c, http.StatusBadRequest, 400, "Invalid user ID format")
+		respondInvalidRequest(c, "Invalid user ID format")
 		return
 	}
 
 	taskService := h.container.GetTaskService()
 	response, err := taskService.GetUserTasks(c.Request.Context(), userID)
 	if err != nil {
-		if serviceErr, ok := err.(*contracts.ServiceError); ok {
-			statusCode := h.mapErrorCodeToHTTPStatus(serviceErr.Code)
-			httputil.ErrorWithStatus(c, statusCode, statusCode, serviceErr.Message)
-		} else {
-			httputil.ErrorWithStatus(c, http.StatusInternalServerError, 500, "Failed to get user tasks: "+err.Error())
-		}
+		respondError(c, err, "Failed to get user tasks")
 		return
 	}
 
@@ -591,31 +557,3 @@ func (h *TaskHandler) GetUserTasks(c *gin.Context) {
 		"total_count": response.TotalCount,
 	})
 }
-
-// ========== 私有方法 ==========
-
-// mapErrorCodeToHTTPStatus 将业务错误码映射到HTTP状态码
-func (h *TaskHandler) mapErrorCodeToHTTPStatus(code contracts.ErrorCode) int {
-	switch code {
-	case contracts.ErrorCodeInvalidRequest:
-		return http.StatusBadRequest
-	case contracts.ErrorCodeNotFound:
-		return http.StatusNotFound
-	case contracts.ErrorCodeUnauthorized:
-		return http.StatusUnauthorized
-	case contracts.ErrorCodeForbidden:
-		return http.StatusForbidden
-	case contracts.ErrorCodeConflict:
-		return http.StatusConflict
-	case contracts.ErrorCodeServiceUnavailable:
-		return http.StatusServiceUnavailable
-	case contracts.ErrorCodeTimeout:
-		return http.StatusRequestTimeout
-	case contracts.ErrorCodeRateLimit:
-		return http.StatusTooManyRequests
-	case contracts.ErrorCodeQuotaExceeded:
-		return http.StatusInsufficientStorage
-	default:
-		return http.StatusInternalServerError
-	}
-}
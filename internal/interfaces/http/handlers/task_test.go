@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/services"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeChatChecker 只允许向 allowed 中的聊天发送消息
+type fakeChatChecker struct {
+	allowed map[int64]bool
+}
+
+func (f fakeChatChecker) CheckCanPost(chatID int64) error {
+	if !f.allowed[chatID] {
+		return errors.New("bot is not a member")
+	}
+	return nil
+}
+
+func TestCheckNotifyChat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		client     interface{}
+		chatID     int64
+		want       bool
+		wantStatus int
+	}{
+		{name: "未设置通知聊天", client: fakeChatChecker{}, chatID: 0, want: true},
+		{name: "机器人可以发送", client: fakeChatChecker{allowed: map[int64]bool{-100: true}}, chatID: -100, want: true},
+		{name: "机器人无法发送", client: fakeChatChecker{}, chatID: -100, want: false, wantStatus: http.StatusBadRequest},
+		{name: "未启用 Telegram", client: nil, chatID: -100, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container := &services.ServiceContainer{}
+			container.SetTelegramClient(tt.client)
+			h := NewTaskHandler(container)
+
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			if got := h.checkNotifyChat(c, tt.chatID); got != tt.want {
+				t.Fatalf("checkNotifyChat() = %v, want %v", got, tt.want)
+			}
+			if !tt.want && recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/netip"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/utils/http"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// healthPath 健康检查接口，供监控和容器探针使用，不做访问控制
+	healthPath = "/health"
+	// telegramWebhookPath Telegram 推送更新的接口，Telegram 无法携带 Basic 认证信息
+	telegramWebhookPath = "/telegram/webhook"
+)
+
+// IPAllowlistMiddleware 只允许白名单网段访问，其他客户端返回 403
+// 按 TCP 连接的对端地址判断，不信任 X-Forwarded-For；部署在反向代理后时需要把代理地址加入白名单
+// 使用 Telegram Webhook 时需要把 Telegram 的网段加入白名单
+// 网段在加载配置时已校验，解析失败时拒绝所有请求
+func IPAllowlistMiddleware(cfg config.IPAllowlistConfig) gin.HandlerFunc {
+	prefixes, err := cfg.Prefixes()
+	if err != nil {
+		logger.Error("Invalid IP allowlist, denying all HTTP requests", "error", err)
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.URL.Path == healthPath {
+			c.Next()
+			return
+		}
+
+		ip := c.RemoteIP()
+		if !ipAllowed(ip, prefixes) {
+			logger.Warn("HTTP request denied by IP allowlist", "ip", ip, "method", c.Request.Method, "path", c.Request.URL.Path)
+			httputil.ErrorWithCode(c, http.StatusForbidden, string(contracts.ErrorCodeForbidden), "Access denied")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// BasicAuthMiddleware 要求 HTTP Basic 认证，缺少或错误的凭据返回 401 并提示浏览器输入
+func BasicAuthMiddleware(cfg config.BasicAuthConfig) gin.HandlerFunc {
+	username := []byte(cfg.Username)
+	password := []byte(cfg.Password)
+
+	return func(c *gin.Context) {
+		if path := c.Request.URL.Path; path == healthPath || path == telegramWebhookPath {
+			c.Next()
+			return
+		}
+
+		user, pass, ok := c.Request.BasicAuth()
+		// 用户名和密码都比较，避免提前返回泄露用户名是否正确
+		userMatch := subtle.ConstantTimeCompare([]byte(user), username)
+		passMatch := subtle.ConstantTimeCompare([]byte(pass), password)
+		if !ok || userMatch&passMatch != 1 {
+			logger.Warn("HTTP request denied by basic auth", "ip", c.ClientIP(), "method", c.Request.Method, "path", c.Request.URL.Path, "user", user)
+			c.Header("WWW-Authenticate", `Basic realm="alist-aria2-download"`)
+			httputil.ErrorWithCode(c, http.StatusUnauthorized, string(contracts.ErrorCodeUnauthorized), "Authentication required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ipAllowed 判断地址是否在任一白名单网段内
+func ipAllowed(ip string, prefixes []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
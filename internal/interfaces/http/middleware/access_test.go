@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/gin-gonic/gin"
+)
+
+func newAccessRouter(handlers ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(handlers...)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/health", ok)
+	router.GET("/downloads/", ok)
+	return router
+}
+
+func TestIPAllowlistMiddleware(t *testing.T) {
+	router := newAccessRouter(IPAllowlistMiddleware(config.IPAllowlistConfig{
+		Enabled: true,
+		CIDRs:   []string{"192.168.1.0/24", "10.0.0.5"},
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		path       string
+		want       int
+	}{
+		{name: "in CIDR", remoteAddr: "192.168.1.20:5000", path: "/downloads/", want: http.StatusOK},
+		{name: "single IP", remoteAddr: "10.0.0.5:5000", path: "/downloads/", want: http.StatusOK},
+		{name: "outside", remoteAddr: "10.0.0.6:5000", path: "/downloads/", want: http.StatusForbidden},
+		{name: "health skipped", remoteAddr: "8.8.8.8:5000", path: "/health", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			// 不信任客户端自带的转发头
+			req.Header.Set("X-Forwarded-For", "192.168.1.20")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	router := newAccessRouter(BasicAuthMiddleware(config.BasicAuthConfig{Enabled: true, Username: "admin", Password: "secret"}))
+
+	tests := []struct {
+		name string
+		path string
+		user string
+		pass string
+		want int
+	}{
+		{name: "valid", path: "/downloads/", user: "admin", pass: "secret", want: http.StatusOK},
+		{name: "wrong password", path: "/downloads/", user: "admin", pass: "nope", want: http.StatusUnauthorized},
+		{name: "missing", path: "/downloads/", want: http.StatusUnauthorized},
+		{name: "health skipped", path: "/health", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
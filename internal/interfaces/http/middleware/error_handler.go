@@ -1,9 +1,12 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/http/handlers"
+	httputil "github.com/easayliu/alist-aria2-download/pkg/utils/http"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,59 +21,23 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 			err := c.Errors.Last().Err
 
 			// 根据错误类型返回不同的HTTP状态码
-			if serviceErr, ok := err.(*contracts.ServiceError); ok {
-				statusCode := mapErrorCodeToHTTPStatus(serviceErr.Code)
-				c.JSON(statusCode, gin.H{
-					"error":   serviceErr.Message,
-					"code":    serviceErr.Code,
-					"details": serviceErr.Details,
-				})
-			} else {
-				// 未知错误,返回500
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": err.Error(),
-					"code":  contracts.ErrorCodeInternalError,
-				})
+			code := handlers.ErrorCodeFor(err)
+			message := err.Error()
+			var serviceErr *contracts.ServiceError
+			if errors.As(err, &serviceErr) {
+				message = serviceErr.Message
 			}
+			httputil.ErrorWithCode(c, handlers.HTTPStatusForErrorCode(code), string(code), message)
 		}
 	}
 }
 
-// mapErrorCodeToHTTPStatus 将业务错误码映射到HTTP状态码
-func mapErrorCodeToHTTPStatus(code contracts.ErrorCode) int {
-	switch code {
-	case contracts.ErrorCodeInvalidRequest:
-		return http.StatusBadRequest
-	case contracts.ErrorCodeNotFound:
-		return http.StatusNotFound
-	case contracts.ErrorCodeUnauthorized:
-		return http.StatusUnauthorized
-	case contracts.ErrorCodeForbidden:
-		return http.StatusForbidden
-	case contracts.ErrorCodeConflict:
-		return http.StatusConflict
-	case contracts.ErrorCodeServiceUnavailable:
-		return http.StatusServiceUnavailable
-	case contracts.ErrorCodeTimeout:
-		return http.StatusRequestTimeout
-	case contracts.ErrorCodeRateLimit:
-		return http.StatusTooManyRequests
-	case contracts.ErrorCodeQuotaExceeded:
-		return http.StatusInsufficientStorage
-	default:
-		return http.StatusInternalServerError
-	}
-}
-
 // RecoverMiddleware 恢复中间件 - 捕获panic并转换为500错误
 func RecoverMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-					"code":  contracts.ErrorCodeInternalError,
-				})
+				httputil.ErrorWithCode(c, http.StatusInternalServerError, string(contracts.ErrorCodeInternalError), "Internal server error")
 				c.Abort()
 			}
 		}()
@@ -30,8 +30,9 @@ func (rc *RoutesConfig) SetupRoutes(router *gin.Engine) {
 	taskHandler := handlers.NewTaskHandler(rc.container)
 	alistHandler := handlers.NewAlistHandler(rc.container)
 	llmHandler := handlers.NewLLMHandler(rc.container)
+	healthHandler := handlers.NewHealthHandler(rc.container)
 
-	router.GET("/health", handlers.HealthCheck)
+	router.GET("/health", healthHandler.HealthCheck)
 
 	downloads := router.Group("/downloads")
 	{
@@ -42,6 +43,7 @@ func (rc *RoutesConfig) SetupRoutes(router *gin.Engine) {
 		downloads.POST("/:id/pause", downloadHandler.PauseDownload)
 		downloads.POST("/:id/resume", downloadHandler.ResumeDownload)
 		downloads.POST("/batch", downloadHandler.CreateBatchDownload)
+		downloads.POST("/batch-cancel", downloadHandler.BatchCancelDownloads)
 		downloads.POST("/pause-all", downloadHandler.PauseAllDownloads)
 		downloads.POST("/resume-all", downloadHandler.ResumeAllDownloads)
 		downloads.GET("/statistics", downloadHandler.GetDownloadStatistics)
@@ -60,6 +62,7 @@ func (rc *RoutesConfig) SetupRoutes(router *gin.Engine) {
 		files.GET("/yesterday", fileHandler.GetYesterdayFiles)
 		files.POST("/yesterday/download", fileHandler.DownloadYesterdayFiles)
 		files.POST("/download", fileHandler.DownloadFilesFromPath)
+		files.POST("/download-directory", fileHandler.DownloadDirectory)
 		files.POST("/list", fileHandler.ListFilesHandler)
 		files.POST("/manual-download", fileHandler.ManualDownloadFiles)
 		files.POST("/search", fileHandler.SearchFiles)
@@ -119,6 +122,12 @@ func SetupRoutesWithContainer(cfg *config.Config, container *services.ServiceCon
 	// 全局中间件
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.LoggerMiddleware())
+	if cfg.Server.IPAllowlist.Enabled {
+		router.Use(middleware.IPAllowlistMiddleware(cfg.Server.IPAllowlist))
+	}
+	if cfg.Server.BasicAuth.Enabled {
+		router.Use(middleware.BasicAuthMiddleware(cfg.Server.BasicAuth))
+	}
 	router.Use(middleware.ContainerMiddleware(container))
 
 	// Swagger文档路由
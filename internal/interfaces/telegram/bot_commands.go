@@ -0,0 +1,132 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// menuLanguages are the Telegram language codes the command menu is registered for ("" is the default, Chinese)
+var menuLanguages = []string{"", "en"}
+
+// botCommand describes a slash command routed by MessageHandler.HandleMessage
+type botCommand struct {
+	Name          string // without the leading slash and command prefix
+	Description   string
+	DescriptionEN string
+}
+
+// botCommands is the single source of truth for the commands the bot routes.
+// It drives the Telegram command menu, /commands and shortcut validation;
+// TestRoutedCommandsMatchMessageRouter keeps it in sync with the command switch in message.go.
+var botCommands = []botCommand{
+	{"start", "显示主菜单和欢迎信息", "Show the main menu"},
+	{"help", "显示帮助和命令用法", "Show help and command usage"},
+	{"commands", "列出全部命令", "List all commands"},
+	{"ping", "检查机器人是否在线并测量延迟", "Check the bot is alive and measure latency"},
+	{"download", "预览/下载最近的文件或指定URL", "Preview/download recent files or a URL"},
+	{"list", "列出目录中的文件", "List files in a directory"},
+	{"llmrename", "使用LLM推断文件名", "Rename files with an LLM"},
+	{"rename", "智能重命名文件", "Rename files with TMDB"},
+	{"cancel", "取消下载任务", "Cancel a download"},
+	{"cancelall", "取消全部活动和等待中的下载", "Cancel all active and waiting downloads"},
+	{"recent", "最近完成的下载", "Recently completed downloads"},
+	{"mvdl", "移动已完成下载的文件", "Move a completed download"},
+	{"search", "按文件名递归搜索 Alist 文件", "Search Alist files by name"},
+	{"find", "查找已下载文件的位置", "Find a downloaded file"},
+	{"pauseall", "暂停全部下载", "Pause all downloads"},
+	{"resumeall", "恢复全部已暂停的下载", "Resume all paused downloads"},
+	{"pause", "暂停单个下载任务", "Pause a single download"},
+	{"resume", "恢复单个已暂停的下载任务", "Resume a single paused download"},
+	{"retryfailed", "重试批量下载中失败的文件", "Retry failed files of a batch"},
+	{"taskinfo", "查看下载任务详情", "Show download details"},
+	{"boost", "提高下载任务的连接数和分段数", "Raise a download's connections and splits"},
+	{"tasks", "查看我的定时任务", "List my scheduled tasks"},
+	{"today", "今日定时任务运行汇总", "Today's scheduled task runs"},
+	{"cron", "校验cron表达式并预览执行时间", "Check a cron expression"},
+	{"addtask", "自定义定时任务", "Create a custom task"},
+	{"addwindow", "创建按时段运行的任务", "Create a time-window task"},
+	{"quicktask", "快捷创建定时任务", "Create a task from a preset"},
+	{"deltask", "删除定时任务", "Delete a task"},
+	{"retrytask", "重试任务中创建失败的文件", "Retry failed items of a task"},
+	{"runtask", "立即运行定时任务", "Run a task now"},
+	{"delete", "删除文件或目录", "Delete a file or directory"},
+	{"eta", "估算目录下载耗时", "Estimate directory download time"},
+	{"saveas", "重命名后下载文件", "Download a file under a new name"},
+	{"overrides", "查看/删除分类纠正记录", "Manage category overrides"},
+	{"inventory", "生成目录分类统计和媒体清单", "Scan a directory inventory"},
+	{"snapshot", "记录目录当前的文件列表", "Snapshot a directory"},
+	{"diff", "对比目录与上次快照", "Compare a directory with its snapshot"},
+	{"testrule", "测试文件名命中的分类规则", "Test classification rules"},
+	{"reorganize", "按分类规则整理已下载的文件", "Reorganize the download directory"},
+	{"savepreset", "保存下载预设（路径和筛选条件）", "Save a download preset"},
+	{"presets", "查看/运行/删除下载预设", "List download presets"},
+	{"preset", "运行下载预设", "Run a download preset"},
+	{"unpin", "取消收藏目录", "Unpin a directory"},
+	{"pin", "收藏目录/查看收藏夹", "Pin a directory or list pins"},
+	{"bandwidth", "查看带宽使用", "Show bandwidth usage"},
+	{"diskspace", "查看下载目录磁盘空间", "Show download directory disk space"},
+	{"stats", "查看今日/本周/本月下载统计", "Show download statistics"},
+	{"testnotify", "测试通知渠道", "Test notification channels"},
+}
+
+// routedCommands lists the slash commands routed by MessageHandler.HandleMessage; shortcuts may only map to these
+var routedCommands = func() []string {
+	names := make([]string, 0, len(botCommands))
+	for _, cmd := range botCommands {
+		names = append(names, "/"+cmd.Name)
+	}
+	return names
+}()
+
+// description returns the command description for a Telegram language code, falling back to Chinese
+func (cmd botCommand) description(languageCode string) string {
+	if strings.HasPrefix(languageCode, "en") && cmd.DescriptionEN != "" {
+		return cmd.DescriptionEN
+	}
+	return cmd.Description
+}
+
+// menuCommands builds the Telegram command menu for a language code
+func menuCommands(languageCode string) []tgbotapi.BotCommand {
+	commands := make([]tgbotapi.BotCommand, 0, len(botCommands))
+	for _, cmd := range botCommands {
+		commands = append(commands, tgbotapi.BotCommand{Command: cmd.Name, Description: cmd.description(languageCode)})
+	}
+	return commands
+}
+
+// registerBotCommands publishes botCommands as the Telegram command menu for every supported language
+func (c *TelegramController) registerBotCommands() {
+	if !c.config.Telegram.Enabled || c.telegramClient == nil {
+		return
+	}
+	for _, lang := range menuLanguages {
+		if err := c.telegramClient.RegisterBotCommands(menuCommands(lang), lang); err != nil {
+			logger.Error("Failed to register bot commands", "language", lang, "error", err)
+			return
+		}
+	}
+	logger.Info("Bot commands registered successfully", "count", len(botCommands))
+}
+
+// handleCommandList sends the list of supported commands, localized by the user's Telegram language
+func (h *MessageHandler) handleCommandList(chatID int64, languageCode string) {
+	formatter := h.controller.messageUtils.GetFormatter().(*utils.MessageFormatter)
+	prefix := h.controller.config.Telegram.CommandPrefix
+
+	title, footer := "命令列表", "发送 /%shelp 查看详细用法"
+	if strings.HasPrefix(languageCode, "en") {
+		title, footer = "Commands", "Send /%shelp for usage details"
+	}
+
+	lines := []string{formatter.FormatTitle("📋", title), ""}
+	for _, cmd := range botCommands {
+		lines = append(lines, fmt.Sprintf("/%s%s - %s", prefix, cmd.Name, h.controller.messageUtils.EscapeHTML(cmd.description(languageCode))))
+	}
+	lines = append(lines, "", fmt.Sprintf(footer, prefix))
+	h.controller.messageUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+}
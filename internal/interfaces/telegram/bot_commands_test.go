@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"os"
+	"regexp"
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestRoutedCommandsMatchMessageRouter 测试命令列表与 HandleMessage 的命令路由保持一致
+func TestRoutedCommandsMatchMessageRouter(t *testing.T) {
+	source, err := os.ReadFile("message.go")
+	if err != nil {
+		t.Fatalf("read message.go: %v", err)
+	}
+
+	var routed []string
+	for _, m := range regexp.MustCompile(`strings\.HasPrefix\(command, "(/[a-z]+)"\)`).FindAllStringSubmatch(string(source), -1) {
+		routed = append(routed, m[1])
+	}
+
+	slices.Sort(routed)
+	want := slices.Clone(routedCommands)
+	slices.Sort(want)
+	if !slices.Equal(routed, want) {
+		t.Errorf("routedCommands = %v, message.go routes %v", want, routed)
+	}
+}
+
+// TestMenuCommandsValid 测试命令菜单满足 Telegram 对命令名和描述的限制
+func TestMenuCommandsValid(t *testing.T) {
+	namePattern := regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+	for _, lang := range menuLanguages {
+		commands := menuCommands(lang)
+		if len(commands) > 100 {
+			t.Fatalf("menuCommands(%q) has %d commands, Telegram allows 100", lang, len(commands))
+		}
+		for _, cmd := range commands {
+			if !namePattern.MatchString(cmd.Command) {
+				t.Errorf("invalid command name %q", cmd.Command)
+			}
+			if n := utf8.RuneCountInString(cmd.Description); n < 1 || n > 256 {
+				t.Errorf("command %q (%q) description length %d out of range", cmd.Command, lang, n)
+			}
+		}
+	}
+}
@@ -4,6 +4,11 @@ import (
 	"strconv"
 	"strings"
 
+	filehandler "github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/handlers/file"
+	statushandler "github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/handlers/status"
+	taskhandler "github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/handlers/task"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -39,7 +44,18 @@ func (h *CallbackHandler) HandleCallbackQuery(update *tgbotapi.Update) {
 
 	logger.Info("Received callback query:", "data", data, "from", callback.From.UserName, "chatID", chatID)
 
+	// Buttons of old menus may refer to path tokens that have expired or were evicted;
+	// tell the user instead of silently falling back to the root directory
+	if token, expired := h.controller.common.ExpiredPathToken(data); expired {
+		logger.Info("Callback refers to expired path token", "data", data, "token", token, "chatID", chatID)
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, ExpiredButtonMessage)
+		return
+	}
+
 	// Route to appropriate handler based on callback data prefix
+	if h.handleDeleteAfterDownloadCallbacks(callback, chatID, userID, data) {
+		return
+	}
 	if h.handlePreviewCallbacks(callback, chatID, data) {
 		return
 	}
@@ -49,6 +65,18 @@ func (h *CallbackHandler) HandleCallbackQuery(update *tgbotapi.Update) {
 	if h.handleRenameCallbacks(callback, chatID, data) {
 		return
 	}
+	if h.handleTaskCallbacks(callback, chatID, userID, data) {
+		return
+	}
+	if h.handlePinCallbacks(callback, chatID, userID, data) {
+		return
+	}
+	if h.handlePresetCallbacks(callback, chatID, userID, data) {
+		return
+	}
+	if h.handleReorganizeCallbacks(callback, chatID, userID, data) {
+		return
+	}
 
 	// Respond to callback query before processing file operations
 	h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "")
@@ -62,6 +90,9 @@ func (h *CallbackHandler) HandleCallbackQuery(update *tgbotapi.Update) {
 	if h.handleDirCallbacks(callback, chatID, data) {
 		return
 	}
+	if h.handleStatusCallbacks(callback, chatID, userID, data) {
+		return
+	}
 
 	// Handle menu callbacks
 	h.handleMenuCallbacks(callback, chatID, userID, data)
@@ -72,13 +103,25 @@ func (h *CallbackHandler) HandleCallbackQuery(update *tgbotapi.Update) {
 func (h *CallbackHandler) handlePreviewCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, data string) bool {
 	if hours, found := strings.CutPrefix(data, "preview_hours|"); found {
 		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在生成预览")
-		h.controller.downloadHandler.HandleQuickPreview(chatID, []string{hours})
+		h.controller.common.RunExclusive(chatID, "生成预览", func() {
+			h.controller.downloadHandler.HandleQuickPreview(chatID, []string{hours})
+		})
 		return true
 	}
 
 	if minutes, found := strings.CutPrefix(data, "preview_minutes|"); found {
 		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在生成预览")
-		h.controller.downloadHandler.HandleQuickPreview(chatID, []string{minutes + "m"})
+		h.controller.common.RunExclusive(chatID, "生成预览", func() {
+			h.controller.downloadHandler.HandleQuickPreview(chatID, []string{minutes + "m"})
+		})
+		return true
+	}
+
+	if data == "preview_yesterday" {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在生成预览")
+		h.controller.common.RunExclusive(chatID, "生成预览", func() {
+			h.controller.downloadHandler.HandleQuickPreview(chatID, []string{"yesterday"})
+		})
 		return true
 	}
 
@@ -90,7 +133,7 @@ func (h *CallbackHandler) handlePreviewCallbacks(callback *tgbotapi.CallbackQuer
 			"• <code>/download &lt;数字&gt;</code> （例如：/download 6 表示6小时）\n" +
 			"• <code>/download YYYY-MM-DD YYYY-MM-DD</code>\n" +
 			"• <code>/download 2025-01-01T00:00:00Z 2025-01-01T12:00:00Z</code>"
-		h.controller.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+		h.controller.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageTransient)
 		return true
 	}
 
@@ -111,7 +154,9 @@ func (h *CallbackHandler) handleDownloadCallbacks(callback *tgbotapi.CallbackQue
 	if token, found := strings.CutPrefix(data, "manual_confirm|"); found {
 		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "开始创建下载任务")
 		if callback.Message != nil {
-			h.controller.downloadHandler.HandleManualConfirm(chatID, token, callback.Message.MessageID)
+			h.controller.common.RunExclusive(chatID, "创建下载任务", func() {
+				h.controller.downloadHandler.HandleManualConfirm(chatID, callback.From.ID, token, callback.Message.MessageID)
+			})
 		}
 		return true
 	}
@@ -142,7 +187,7 @@ func (h *CallbackHandler) handleRenameCallbacks(callback *tgbotapi.CallbackQuery
 		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "已取消")
 		if callback.Message != nil {
 			h.controller.messageUtils.ClearInlineKeyboard(chatID, callback.Message.MessageID)
-			h.controller.messageUtils.DeleteMessageAfterDelay(chatID, callback.Message.MessageID, 30)
+			h.controller.messageUtils.DeleteMessageAfterDelay(chatID, callback.Message.MessageID, types.MessageTransient)
 		}
 		return true
 	}
@@ -155,12 +200,25 @@ func (h *CallbackHandler) handleRenameCallbacks(callback *tgbotapi.CallbackQuery
 func (h *CallbackHandler) handleBrowseCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, data string) bool {
 	messageID := callback.Message.MessageID
 
-	// Handle browse_dir, browse_page, browse_refresh with same logic
-	for _, prefix := range []string{"browse_dir:", "browse_page:", "browse_refresh:"} {
+	// Section headers of the grouped browse view are labels only
+	if data == "browse_section" {
+		return true
+	}
+
+	// Back to the Alist root picker (alist.roots)
+	if data == filehandler.BrowseRootsCallback {
+		h.controller.fileHandler.HandleFilesBrowseWithEdit(chatID, messageID)
+		return true
+	}
+
+	// Handle browse_dir, browse_page, browse_refresh, browse_hidden, browse_group with same logic.
+	// Format: browse_*:<path>:<page>[:<days>], where days is the active modification date filter.
+	// The path token also carries the Alist root it was browsed from.
+	for _, prefix := range []string{"browse_dir:", "browse_page:", "browse_refresh:", "browse_hidden:", "browse_group:"} {
 		if strings.HasPrefix(data, prefix) {
 			parts := strings.Split(data, ":")
 			if len(parts) >= 3 {
-				path := h.controller.common.DecodeFilePath(parts[1])
+				root, path := h.controller.common.DecodeRootFilePath(parts[1])
 				page, err := strconv.Atoi(parts[2])
 				if err != nil || page < 1 {
 					page = 1
@@ -168,7 +226,17 @@ func (h *CallbackHandler) handleBrowseCallbacks(callback *tgbotapi.CallbackQuery
 				if prefix == "browse_dir:" {
 					logger.Info("Directory clicked", "encodedPath", parts[1], "decodedPath", path, "page", page)
 				}
-				h.controller.fileHandler.HandleBrowseFilesWithEdit(chatID, path, page, messageID)
+				if prefix == "browse_hidden:" {
+					h.controller.fileHandler.ToggleHiddenFiles(chatID)
+				}
+				if prefix == "browse_group:" {
+					h.controller.fileHandler.ToggleGroupBrowse(chatID)
+				}
+				days := 0
+				if len(parts) >= 4 {
+					days, _ = strconv.Atoi(parts[3])
+				}
+				h.controller.fileHandler.HandleBrowseFilesFiltered(chatID, root, path, page, messageID, days)
 			}
 			return true
 		}
@@ -177,28 +245,295 @@ func (h *CallbackHandler) handleBrowseCallbacks(callback *tgbotapi.CallbackQuery
 	return false
 }
 
+// handleDeleteAfterDownloadCallbacks handles "download then delete source" callbacks.
+// These are destructive, so they require admin rights and the config opt-in.
+// Returns true if the callback was handled.
+func (h *CallbackHandler) handleDeleteAfterDownloadCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, userID int64, data string) bool {
+	filePath, isFile := strings.CutPrefix(data, "file_download_delete:")
+	dirPath, isDir := strings.CutPrefix(data, "download_dir_delete:")
+	if !isFile && !isDir {
+		return false
+	}
+
+	if !h.controller.config.Download.AllowDeleteAfterDownload {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "下载后删除功能未开启")
+		return true
+	}
+	if !h.controller.telegramClient.IsAdmin(userID) {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "仅管理员可用")
+		return true
+	}
+
+	h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在创建下载任务")
+	if isFile {
+		root, path := h.controller.common.DecodeRootFilePath(filePath)
+		h.controller.fileHandler.HandleFileDownloadAndDelete(chatID, userID, root, path)
+	} else {
+		h.controller.common.RunExclusive(chatID, "下载目录", func() {
+			h.controller.fileHandler.HandleDownloadDirectoryAndDeleteExecute(chatID, userID, h.controller.common.DecodeFilePath(dirPath), callback.Message.MessageID)
+		})
+	}
+	return true
+}
+
+// handleReorganizeCallbacks handles confirming or cancelling /reorganize.
+// Moving files on disk requires admin rights. Returns true if the callback was handled.
+func (h *CallbackHandler) handleReorganizeCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, userID int64, data string) bool {
+	switch data {
+	case "reorganize_apply":
+		if !h.controller.telegramClient.IsAdmin(userID) {
+			h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "仅管理员可用")
+			return true
+		}
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在整理")
+		h.controller.common.RunExclusive(chatID, "/reorganize", func() {
+			h.controller.fileHandler.HandleReorganizeExecute(chatID, callback.Message.MessageID)
+		})
+	case "reorganize_cancel":
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "已取消")
+		h.controller.messageUtils.EditMessageWithKeyboard(chatID, callback.Message.MessageID, "已取消整理下载目录", "HTML", nil)
+	default:
+		return false
+	}
+	return true
+}
+
+// handleTaskCallbacks handles paginated task list callbacks.
+// Formats: task_page:<page>:<filterToken> and task_<action>:<id>:<page>:<filterToken>.
+// Returns true if the callback was handled.
+func (h *CallbackHandler) handleTaskCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, userID int64, data string) bool {
+	if !strings.HasPrefix(data, "task_") {
+		return false
+	}
+	messageID := callback.Message.MessageID
+
+	if rest, found := strings.CutPrefix(data, "task_page:"); found {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "")
+		pageStr, filterToken, _ := strings.Cut(rest, ":")
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			page = 1
+		}
+		filter := ""
+		if filterToken != "" {
+			filter = h.controller.common.DecodeFilePath(filterToken)
+		}
+		h.controller.taskHandler.HandleTaskPage(chatID, userID, page, filter, messageID)
+		return true
+	}
+
+	action, rest, found := strings.Cut(strings.TrimPrefix(data, "task_"), ":")
+	if !found {
+		return false
+	}
+	parts := strings.SplitN(rest, ":", 3)
+	if len(parts) < 3 {
+		return false
+	}
+	page, err := strconv.Atoi(parts[1])
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	switch action {
+	case taskhandler.ActionRun:
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在运行任务")
+	case taskhandler.ActionToggle:
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "已切换任务状态")
+	case taskhandler.ActionRetryFailed:
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在重试失败文件")
+	case taskhandler.ActionDeleteConfirm, taskhandler.ActionDelete:
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "")
+	default:
+		return false
+	}
+
+	if action == taskhandler.ActionRetryFailed {
+		h.controller.common.RunExclusive(chatID, "重试失败文件", func() {
+			h.controller.taskHandler.HandleTaskAction(chatID, userID, action, parts[0], page, parts[2], messageID)
+		})
+		return true
+	}
+	h.controller.taskHandler.HandleTaskAction(chatID, userID, action, parts[0], page, parts[2], messageID)
+	return true
+}
+
+// handlePinCallbacks handles per-user favorite directory callbacks.
+// Returns true if the callback was handled.
+func (h *CallbackHandler) handlePinCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, userID int64, data string) bool {
+	messageID := callback.Message.MessageID
+
+	if data == "pins_list" {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "")
+		h.controller.fileHandler.HandleFavoritesWithEdit(chatID, userID, messageID)
+		return true
+	}
+
+	if dirPath, found := strings.CutPrefix(data, "pin_add:"); found {
+		root, path := h.controller.common.DecodeRootFilePath(dirPath)
+		added, err := h.controller.fileHandler.PinDirectory(userID, root, path)
+		switch {
+		case err != nil:
+			h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "收藏失败: "+err.Error())
+		case added:
+			h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "⭐ 已收藏")
+		default:
+			h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "已在收藏夹中")
+		}
+		return true
+	}
+
+	if dirPath, found := strings.CutPrefix(data, "pin_remove:"); found {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "已移除")
+		h.controller.fileHandler.HandleUnpinWithEdit(chatID, userID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		return true
+	}
+
+	return false
+}
+
+// handlePresetCallbacks handles per-user download preset callbacks.
+// Formats: presets_list, preset_show:<name>, preset_run:<name> and preset_del:<name>.
+// Returns true if the callback was handled.
+func (h *CallbackHandler) handlePresetCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, userID int64, data string) bool {
+	messageID := callback.Message.MessageID
+
+	if data == "presets_list" {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "")
+		h.controller.fileHandler.HandlePresetsWithEdit(chatID, userID, messageID)
+		return true
+	}
+	if name, found := strings.CutPrefix(data, "preset_show:"); found {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "")
+		h.controller.common.RunExclusive(chatID, "/preset", func() {
+			h.controller.fileHandler.HandlePresetConfirm(chatID, userID, name, messageID)
+		})
+		return true
+	}
+	if name, found := strings.CutPrefix(data, "preset_run:"); found {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在创建下载任务")
+		h.controller.common.RunExclusive(chatID, "下载目录", func() {
+			h.controller.fileHandler.HandlePresetExecute(chatID, userID, name, messageID)
+		})
+		return true
+	}
+	if name, found := strings.CutPrefix(data, "preset_del:"); found {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "已删除")
+		h.controller.fileHandler.HandlePresetDelete(chatID, userID, name, messageID)
+		return true
+	}
+
+	return false
+}
+
 // handleFileCallbacks handles file operation callbacks.
 // Returns true if the callback was handled.
 func (h *CallbackHandler) handleFileCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, data string) bool {
 	messageID := callback.Message.MessageID
 
 	if filePath, found := strings.CutPrefix(data, "file_menu:"); found {
-		h.controller.fileHandler.HandleFileMenuWithEdit(chatID, h.controller.common.DecodeFilePath(filePath), messageID)
+		root, path := h.controller.common.DecodeRootFilePath(filePath)
+		h.controller.fileHandler.HandleFileMenuWithEdit(chatID, root, path, messageID)
+		return true
+	}
+
+	if filePath, found := strings.CutPrefix(data, "file_download_subs:"); found {
+		root, path := h.controller.common.DecodeRootFilePath(filePath)
+		h.controller.common.RunExclusive(chatID, "下载视频和字幕", func() {
+			h.controller.fileHandler.HandleFileDownloadWithSubtitles(chatID, callback.From.ID, root, path)
+		})
 		return true
 	}
 
 	if filePath, found := strings.CutPrefix(data, "file_download:"); found {
-		h.controller.fileHandler.HandleFileDownload(chatID, h.controller.common.DecodeFilePath(filePath))
+		root, path := h.controller.common.DecodeRootFilePath(filePath)
+		h.controller.fileHandler.HandleFileDownload(chatID, callback.From.ID, root, path)
+		return true
+	}
+
+	if dirPath, found := strings.CutPrefix(data, "files_search:"); found {
+		root, path := h.controller.common.DecodeRootFilePath(dirPath)
+		h.controller.fileHandler.HandleSearchPrompt(chatID, root, path)
+		return true
+	}
+
+	if pageStr, found := strings.CutPrefix(data, "search_page:"); found {
+		page, _ := strconv.Atoi(pageStr)
+		h.controller.fileHandler.HandleSearchPage(chatID, page, messageID)
+		return true
+	}
+
+	if filePath, found := strings.CutPrefix(data, "file_saveas:"); found {
+		h.controller.fileHandler.HandleSaveAsPrompt(chatID, h.controller.common.DecodeFilePath(filePath))
+		return true
+	}
+
+	if payload, found := strings.CutPrefix(data, "file_saveas_ok:"); found {
+		// payload: <encodedPath>:<encodedName>
+		if pathToken, nameToken, ok := strings.Cut(payload, ":"); ok {
+			root, path := h.controller.common.DecodeRootFilePath(pathToken)
+			h.controller.fileHandler.HandleSaveAsDownload(chatID, callback.From.ID,
+				root, path, h.controller.common.DecodeFilePath(nameToken), messageID)
+		}
 		return true
 	}
 
 	if filePath, found := strings.CutPrefix(data, "file_info:"); found {
-		h.controller.fileHandler.HandleFileInfoWithEdit(chatID, h.controller.common.DecodeFilePath(filePath), messageID)
+		root, path := h.controller.common.DecodeRootFilePath(filePath)
+		h.controller.fileHandler.HandleFileInfoWithEdit(chatID, root, path, messageID)
 		return true
 	}
 
 	if filePath, found := strings.CutPrefix(data, "file_link:"); found {
-		h.controller.fileHandler.HandleFileLinkWithEdit(chatID, h.controller.common.DecodeFilePath(filePath), messageID)
+		root, path := h.controller.common.DecodeRootFilePath(filePath)
+		h.controller.fileHandler.HandleFileLinkWithEdit(chatID, root, path, messageID)
+		return true
+	}
+
+	if filePath, found := strings.CutPrefix(data, "file_sample:"); found {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在读取文件片段")
+		h.controller.common.RunExclusive(chatID, "预览片段", func() {
+			h.controller.fileHandler.HandleFileSampleWithEdit(chatID, h.controller.common.DecodeFilePath(filePath), messageID)
+		})
+		return true
+	}
+
+	if filePath, found := strings.CutPrefix(data, "file_recat:"); found {
+		h.controller.fileHandler.HandleReclassifyMenu(chatID, h.controller.common.DecodeFilePath(filePath), messageID)
+		return true
+	}
+
+	if payload, found := strings.CutPrefix(data, "file_recat_set:"); found {
+		// payload: <encodedPath>:<category>
+		// Moving downloaded files on disk requires admin rights
+		if !h.controller.telegramClient.IsAdmin(callback.From.ID) {
+			h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "仅管理员可用")
+			return true
+		}
+		if idx := strings.LastIndex(payload, ":"); idx > 0 {
+			h.controller.fileHandler.HandleReclassify(chatID, callback.From.ID, h.controller.common.DecodeFilePath(payload[:idx]), payload[idx+1:], messageID)
+		}
+		return true
+	}
+
+	if dirPath, found := strings.CutPrefix(data, "snapshot:"); found {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在更新快照")
+		h.controller.common.RunExclusive(chatID, "/snapshot", func() {
+			h.controller.fileHandler.HandleSnapshot(chatID, callback.From.ID, h.controller.common.DecodeFilePath(dirPath))
+		})
+		return true
+	}
+
+	if dirPath, found := strings.CutPrefix(data, "diff_dl:"); found {
+		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在创建下载")
+		h.controller.common.RunExclusive(chatID, "下载新增文件", func() {
+			h.controller.fileHandler.HandleDiffDownload(chatID, callback.From.ID, h.controller.common.DecodeFilePath(dirPath))
+		})
+		return true
+	}
+
+	if filePath, found := strings.CutPrefix(data, "file_qr:"); found {
+		h.controller.fileHandler.HandleFileQRCode(chatID, h.controller.common.DecodeFilePath(filePath))
 		return true
 	}
 
@@ -208,13 +543,15 @@ func (h *CallbackHandler) handleFileCallbacks(callback *tgbotapi.CallbackQuery,
 	}
 
 	if filePath, found := strings.CutPrefix(data, "file_delete_confirm:"); found {
-		h.controller.fileHandler.HandleFileDeleteConfirm(chatID, h.controller.common.DecodeFilePath(filePath), messageID)
+		root, path := h.controller.common.DecodeRootFilePath(filePath)
+		h.controller.fileHandler.HandleFileDeleteConfirm(chatID, root, path, messageID)
 		return true
 	}
 
 	if filePath, found := strings.CutPrefix(data, "file_delete:"); found {
 		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在删除文件")
-		h.controller.fileHandler.HandleFileDelete(chatID, h.controller.common.DecodeFilePath(filePath), messageID)
+		root, path := h.controller.common.DecodeRootFilePath(filePath)
+		h.controller.fileHandler.HandleFileDelete(chatID, root, path, messageID)
 		return true
 	}
 
@@ -227,38 +564,120 @@ func (h *CallbackHandler) handleDirCallbacks(callback *tgbotapi.CallbackQuery, c
 	messageID := callback.Message.MessageID
 
 	if dirPath, found := strings.CutPrefix(data, "dir_menu:"); found {
-		h.controller.fileHandler.HandleDirMenuWithEdit(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		root, path := h.controller.common.DecodeRootFilePath(dirPath)
+		h.controller.fileHandler.HandleDirMenuWithEdit(chatID, root, path, messageID)
 		return true
 	}
 
 	if dirPath, found := strings.CutPrefix(data, "dir_delete_confirm:"); found {
-		h.controller.fileHandler.HandleDirDeleteConfirm(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		root, path := h.controller.common.DecodeRootFilePath(dirPath)
+		h.controller.fileHandler.HandleDirDeleteConfirm(chatID, root, path, messageID)
 		return true
 	}
 
 	if dirPath, found := strings.CutPrefix(data, "dir_delete:"); found {
 		h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "正在删除目录")
-		h.controller.fileHandler.HandleDirDelete(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		root, path := h.controller.common.DecodeRootFilePath(dirPath)
+		h.controller.common.RunExclusive(chatID, "删除目录", func() {
+			h.controller.fileHandler.HandleDirDelete(chatID, root, path, messageID)
+		})
 		return true
 	}
 
 	if dirPath, found := strings.CutPrefix(data, "batch_rename:"); found {
-		h.controller.fileHandler.HandleBatchRename(chatID, h.controller.common.DecodeFilePath(dirPath))
+		h.controller.common.RunExclusive(chatID, "批量重命名", func() {
+			h.controller.fileHandler.HandleBatchRename(chatID, h.controller.common.DecodeFilePath(dirPath))
+		})
 		return true
 	}
 
 	if dirPath, found := strings.CutPrefix(data, "batch_rename_confirm:"); found {
-		h.controller.fileHandler.HandleBatchRenameConfirm(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		h.controller.common.RunExclusive(chatID, "批量重命名", func() {
+			h.controller.fileHandler.HandleBatchRenameConfirm(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		})
+		return true
+	}
+
+	if dirPath, found := strings.CutPrefix(data, "dir_links:"); found {
+		h.controller.common.RunExclusive(chatID, "获取链接", func() {
+			h.controller.fileHandler.HandleDirectoryLinks(chatID, h.controller.common.DecodeFilePath(dirPath))
+		})
 		return true
 	}
 
+	// Count the files first (as a new message), tasks are only created after download_dir_confirm:<token>
 	if dirPath, found := strings.CutPrefix(data, "download_dir:"); found {
-		h.controller.fileHandler.HandleDownloadDirectoryConfirm(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		h.controller.common.RunExclusive(chatID, "扫描目录", func() {
+			h.controller.fileHandler.HandleDownloadDirectoryConfirm(chatID, h.controller.common.DecodeFilePath(dirPath), 0)
+		})
+		return true
+	}
+
+	// Same count, editing the current message (zip fallback to per-file download)
+	if dirPath, found := strings.CutPrefix(data, "download_dir_preview:"); found {
+		h.controller.common.RunExclusive(chatID, "扫描目录", func() {
+			h.controller.fileHandler.HandleDownloadDirectoryConfirm(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		})
+		return true
+	}
+
+	if token, found := strings.CutPrefix(data, "download_dir_confirm:"); found {
+		h.controller.common.RunExclusive(chatID, "下载目录", func() {
+			h.controller.fileHandler.HandleDownloadDirectoryExecute(chatID, callback.From.ID, token, messageID)
+		})
 		return true
 	}
 
-	if dirPath, found := strings.CutPrefix(data, "download_dir_confirm:"); found {
-		h.controller.fileHandler.HandleDownloadDirectoryExecute(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+	if token, found := strings.CutPrefix(data, "download_dir_cancel:"); found {
+		h.controller.fileHandler.HandleDownloadDirectoryCancel(chatID, token, messageID)
+		return true
+	}
+
+	if dirPath, found := strings.CutPrefix(data, "download_dir_resume:"); found {
+		dirPath = h.controller.common.DecodeFilePath(dirPath)
+		// 继续时沿用上次的下载后删除设置，与 download_dir_delete 相同需要管理员权限
+		if checkpoint, ok := h.controller.fileService.GetDirectoryCheckpoint(dirPath); ok && checkpoint.DeleteAfterDownload &&
+			(!h.controller.config.Download.AllowDeleteAfterDownload || !h.controller.telegramClient.IsAdmin(callback.From.ID)) {
+			h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "上次开启了下载后删除源文件，仅管理员可继续")
+			return true
+		}
+		h.controller.common.RunExclusive(chatID, "下载目录", func() {
+			h.controller.fileHandler.HandleDownloadDirectoryResumeExecute(chatID, callback.From.ID, dirPath, messageID)
+		})
+		return true
+	}
+
+	// Download the whole directory as one server-side generated zip
+	if dirPath, found := strings.CutPrefix(data, "download_dir_zip_confirm:"); found {
+		h.controller.common.RunExclusive(chatID, "下载目录", func() {
+			h.controller.fileHandler.HandleDownloadDirectoryZipExecute(chatID, callback.From.ID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		})
+		return true
+	}
+
+	if dirPath, found := strings.CutPrefix(data, "download_dir_zip:"); found {
+		h.controller.common.RunExclusive(chatID, "检查打包下载", func() {
+			h.controller.fileHandler.HandleDownloadDirectoryZipConfirm(chatID, h.controller.common.DecodeFilePath(dirPath), messageID)
+		})
+		return true
+	}
+
+	// Download the files matched by the browse date filter: download_since[_confirm]:<path>:<days>
+	if args, found := strings.CutPrefix(data, "download_since_confirm:"); found {
+		if _, dirPath, days, ok := h.parseDownloadSince(args); ok {
+			h.controller.common.RunExclusive(chatID, "下载目录", func() {
+				h.controller.fileHandler.HandleDownloadFilteredExecute(chatID, callback.From.ID, dirPath, days, messageID)
+			})
+		}
+		return true
+	}
+
+	if args, found := strings.CutPrefix(data, "download_since:"); found {
+		if root, dirPath, days, ok := h.parseDownloadSince(args); ok {
+			h.controller.common.RunExclusive(chatID, "扫描目录", func() {
+				h.controller.fileHandler.HandleDownloadFilteredConfirm(chatID, root, dirPath, days, messageID)
+			})
+		}
 		return true
 	}
 
@@ -270,6 +689,136 @@ func (h *CallbackHandler) handleDirCallbacks(callback *tgbotapi.CallbackQuery, c
 	return false
 }
 
+// parseDownloadSince parses "<path>:<days>" of the download_since callbacks
+// into the Alist root carried by the path token, the path and the day count.
+func (h *CallbackHandler) parseDownloadSince(args string) (string, string, int, bool) {
+	encodedPath, daysStr, found := strings.Cut(args, ":")
+	days, err := strconv.Atoi(daysStr)
+	if !found || err != nil || days < 1 {
+		return "", "", 0, false
+	}
+	root, path := h.controller.common.DecodeRootFilePath(encodedPath)
+	return root, path, days, true
+}
+
+// handleStatusCallbacks handles download status callbacks.
+// Returns true if the callback was handled.
+func (h *CallbackHandler) handleStatusCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, userID int64, data string) bool {
+	if gid, found := strings.CutPrefix(data, "task_info:"); found {
+		h.controller.statusHandler.HandleTaskInfo(chatID, gid, callback.Message.MessageID)
+		return true
+	}
+
+	if args, found := strings.CutPrefix(data, statushandler.TaskFileCancelCallbackPrefix); found {
+		h.controller.statusHandler.HandleCancelTaskFile(chatID, args, callback.Message.MessageID)
+		return true
+	}
+
+	if period, found := strings.CutPrefix(data, statushandler.StatsCallbackPrefix); found {
+		h.controller.statusHandler.HandleStats(chatID, period, callback.Message.MessageID)
+		return true
+	}
+
+	if data == "recent_downloads" {
+		h.controller.statusHandler.HandleRecentDownloads(chatID, callback.Message.MessageID)
+		return true
+	}
+
+	switch data {
+	case statushandler.QueuePauseAllConfirmCallback, statushandler.QueueResumeAllConfirmCallback:
+		h.controller.statusHandler.HandleQueueControlConfirm(chatID, callback.Message.MessageID, data == statushandler.QueuePauseAllConfirmCallback)
+		return true
+	case statushandler.QueuePauseAllCallback, statushandler.QueueResumeAllCallback:
+		h.controller.common.RunExclusive(chatID, "全局暂停/恢复", func() {
+			h.controller.statusHandler.HandleQueueControl(chatID, callback.Message.MessageID, data == statushandler.QueuePauseAllCallback)
+		})
+		return true
+	case statushandler.QueueCancelAllConfirmCallback:
+		h.controller.statusHandler.HandleCancelAllConfirm(chatID, callback.Message.MessageID)
+		return true
+	case statushandler.QueueCancelAllCallback:
+		h.controller.common.RunExclusive(chatID, "取消全部下载", func() {
+			h.controller.statusHandler.HandleCancelAll(chatID, callback.Message.MessageID)
+		})
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.DownloadNowCallbackPrefix); found {
+		h.controller.statusHandler.HandleDownloadNow(chatID, gid, callback.Message.MessageID)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.MoveTopCallbackPrefix); found {
+		h.controller.statusHandler.HandleChangePosition(chatID, gid, callback.Message.MessageID, true)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.MoveBottomCallbackPrefix); found {
+		h.controller.statusHandler.HandleChangePosition(chatID, gid, callback.Message.MessageID, false)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.BoostCallbackPrefix); found {
+		h.controller.statusHandler.HandleBoostDownload(chatID, gid, callback.Message.MessageID)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.TrackCallbackPrefix); found {
+		h.controller.statusHandler.StartProgressTracking(chatID, gid, callback.Message.MessageID)
+		return true
+	}
+
+	if data == statushandler.UntrackCallback {
+		h.controller.statusHandler.StopProgressTracking(chatID, callback.Message.MessageID)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.PauseCallbackPrefix); found {
+		h.controller.statusHandler.HandlePauseDownload(chatID, gid, callback.Message.MessageID, true)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.ResumeCallbackPrefix); found {
+		h.controller.statusHandler.HandlePauseDownload(chatID, gid, callback.Message.MessageID, false)
+		return true
+	}
+
+	if pageStr, found := strings.CutPrefix(data, statushandler.DownloadPageCallbackPrefix); found {
+		page, _ := strconv.Atoi(pageStr)
+		h.controller.statusHandler.HandleDownloadListPage(chatID, page, callback.Message.MessageID)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.CancelCallbackPrefix); found {
+		h.controller.statusHandler.HandleCancelDownloadConfirm(chatID, gid, callback.Message.MessageID)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, statushandler.CancelConfirmedCallbackPrefix); found {
+		h.controller.statusHandler.HandleCancelDownload(chatID, gid, callback.Message.MessageID)
+		return true
+	}
+
+	if gid, found := strings.CutPrefix(data, "dl_move:"); found {
+		// Moving files on disk requires admin rights
+		if !h.controller.telegramClient.IsAdmin(userID) {
+			h.controller.telegramClient.AnswerCallbackQuery(callback.ID, "仅管理员可用")
+			return true
+		}
+		h.controller.statusHandler.HandleMoveDownloadPrompt(chatID, gid)
+		return true
+	}
+
+	if batchID, found := strings.CutPrefix(data, utils.BatchRetryCallbackPrefix); found {
+		h.controller.common.RunExclusive(chatID, "重试失败文件", func() {
+			h.controller.statusHandler.HandleRetryFailedBatch(chatID, userID, batchID)
+		})
+		return true
+	}
+
+	return false
+}
+
 // handleMenuCallbacks handles menu navigation callbacks.
 func (h *CallbackHandler) handleMenuCallbacks(callback *tgbotapi.CallbackQuery, chatID int64, userID int64, data string) {
 	messageID := callback.Message.MessageID
@@ -287,6 +836,10 @@ func (h *CallbackHandler) handleMenuCallbacks(callback *tgbotapi.CallbackQuery,
 		h.controller.menuCallbacks.HandleStartWithEdit(chatID, messageID)
 	case "download_list":
 		h.controller.statusHandler.HandleDownloadStatusAPIWithEdit(chatID, messageID)
+	case statushandler.DiskSpaceCallback:
+		h.controller.statusHandler.HandleDiskSpace(chatID, messageID)
+	case "menu_download":
+		h.controller.statusHandler.HandleDownloadControlWithEdit(chatID, messageID)
 	case "files_browse":
 		h.controller.fileHandler.HandleFilesBrowseWithEdit(chatID, messageID)
 	case "api_alist_login":
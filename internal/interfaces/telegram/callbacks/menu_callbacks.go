@@ -10,6 +10,7 @@ import (
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/commands"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -75,11 +76,12 @@ func (mc *MenuCallbacks) HandleStatusWithEdit(chatID int64, messageID int) {
 	aria2Info := safeSubMap(status, "aria2")
 	telegramInfo := safeSubMap(status, "telegram")
 	serverInfo := safeSubMap(status, "server")
+	formatter := mc.messageUtils.GetFormatter().(*utils.MessageFormatter)
 
 	message := "<b>系统状态</b>\n\n" +
 		"<b>服务状态:</b>\n" +
 		"• Telegram: " + safeMapString(telegramInfo, "status") + "\n" +
-		"• Aria2: " + safeMapString(aria2Info, "status") + " (" + safeMapString(aria2Info, "version") + ")\n" +
+		"• Aria2: " + formatter.FormatAria2Status(safeMapString(aria2Info, "status")) + " (" + safeMapString(aria2Info, "version") + ")\n" +
 		"• 服务器: " + safeMapString(serverInfo, "mode") + " 模式\n" +
 		"• 端口: " + safeMapString(serverInfo, "port")
 
@@ -106,13 +108,14 @@ func (mc *MenuCallbacks) HandleSystemStatusWithEdit(chatID int64, messageID int)
 		aria2Info := safeSubMap(status, "aria2")
 		telegramInfo := safeSubMap(status, "telegram")
 		serverInfo := safeSubMap(status, "server")
+		formatter := mc.messageUtils.GetFormatter().(*utils.MessageFormatter)
 
 		message = "<b>系统状态</b>\n\n" +
 			"<b>服务状态:</b>\n" +
 			"• 服务器: " + safeMapString(serverInfo, "mode") + " 模式\n" +
 			"• 端口: " + safeMapString(serverInfo, "port") + "\n" +
 			"• Telegram: " + safeMapString(telegramInfo, "status") + "\n" +
-			"• Aria2: " + safeMapString(aria2Info, "status") + " (" + safeMapString(aria2Info, "version") + ")\n\n" +
+			"• Aria2: " + formatter.FormatAria2Status(safeMapString(aria2Info, "status")) + " (" + safeMapString(aria2Info, "version") + ")\n\n" +
 			"<b>配置信息:</b>\n" +
 			"• Alist地址: " + mc.config.Alist.BaseURL + "\n" +
 			"• 下载目录: " + mc.config.Aria2.DownloadDir + "\n\n" +
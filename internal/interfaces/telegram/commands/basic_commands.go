@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/alist"
@@ -61,13 +63,7 @@ func NewBasicCommands(downloadService contracts.DownloadService, fileService con
 }
 
 func (bc *BasicCommands) buildStartContent() (string, tgbotapi.InlineKeyboardMarkup) {
-	message := "<b>欢迎使用 Alist-Aria2 下载管理器</b>\n\n" +
-		"<b>快捷功能:</b>\n" +
-		"• 浏览文件 - 浏览和下载Alist文件\n" +
-		"• 下载状态 - 查看下载任务进度\n" +
-		"• 定时任务 - 自动下载任务管理\n" +
-		"• 系统状态 - 服务状态和健康检查\n\n" +
-		"选择功能开始使用："
+	message := bc.welcomeMessage()
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -86,9 +82,28 @@ func (bc *BasicCommands) buildStartContent() (string, tgbotapi.InlineKeyboardMar
 	return message, keyboard
 }
 
+// welcomeMessage returns the configured welcome text, or the built-in one when unset
+func (bc *BasicCommands) welcomeMessage() string {
+	if welcome := strings.TrimSpace(bc.config.Telegram.WelcomeMessage); welcome != "" {
+		return welcome
+	}
+	return "<b>欢迎使用 Alist-Aria2 下载管理器</b>\n\n" +
+		"<b>快捷功能:</b>\n" +
+		"• 浏览文件 - 浏览和下载Alist文件\n" +
+		"• 下载状态 - 查看下载任务进度\n" +
+		"• 定时任务 - 自动下载任务管理\n" +
+		"• 系统状态 - 服务状态和健康检查\n\n" +
+		"选择功能开始使用："
+}
+
 func (bc *BasicCommands) HandleStart(chatID int64) {
 	message, keyboard := bc.buildStartContent()
 	bc.messageUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+
+	// Custom shortcuts live on the reply keyboard, which needs its own message
+	if len(bc.config.Telegram.Shortcuts) > 0 {
+		bc.messageUtils.SendMessageWithReplyKeyboard(chatID, "⌨️ 快捷按钮已更新，可使用下方键盘")
+	}
 }
 
 func (bc *BasicCommands) HandleStartWithEdit(chatID int64, messageID int) {
@@ -100,11 +115,42 @@ func (bc *BasicCommands) buildHelpContent(includeBackButton bool) (string, tgbot
 	message := "<b>使用帮助</b>\n\n" +
 		"<b>快捷按钮:</b>\n" +
 		"使用下方键盘按钮进行常用操作\n\n" +
+		"/commands - 列出全部命令（也可在输入框中输入 / 查看命令菜单）\n\n" +
 		"<b>文件操作命令:</b>\n" +
 		"/list [path] - 列出指定路径的文件\n" +
 		"/rename &lt;path&gt; [--llm] [--strategy=xxx] - 智能重命名文件\n" +
 		"/llmrename &lt;path&gt; [策略] - 使用LLM推断文件名\n" +
-		"/cancel &lt;id&gt; - 取消下载任务\n\n" +
+		"/cancel &lt;id&gt; - 取消下载任务\n" +
+		"/cancelall - 取消全部活动和等待中的下载（需确认）\n" +
+		"/taskinfo &lt;gid&gt; - 查看下载任务详情（连接数、分片、错误信息）\n" +
+		"/boost &lt;gid&gt; - 提高下载慢的任务的每服务器连接数和分段数（不重新创建任务）\n" +
+		"/recent - 最近完成的下载（可将文件移动到其他目录）\n" +
+		"/mvdl &lt;gid&gt; &lt;目录&gt; - 移动已完成下载的文件\n" +
+		"/search &lt;关键词&gt; - 从默认目录递归搜索 Alist 文件（浏览目录时点击「🔍 搜索」可在当前目录搜索）\n" +
+		"/find &lt;关键词&gt; - 查找已下载文件在本机的位置和分类\n" +
+		"/pauseall - 暂停全部下载（立即释放带宽，需确认）\n" +
+		"/resumeall - 恢复全部已暂停的下载（需确认）\n" +
+		"/pause &lt;gid&gt; - 暂停单个下载任务\n" +
+		"/resume &lt;gid&gt; - 恢复单个已暂停的下载任务\n" +
+		"/retryfailed [批次ID] - 重试最近一次（或指定）批量下载中的全部失败文件\n" +
+		"/eta &lt;path&gt; - 按当前速度估算目录下载耗时\n" +
+		"/inventory [path] - 扫描目录生成分类统计和媒体清单（CSV，不下载）\n" +
+		"/overrides - 查看/删除分类纠正记录\n" +
+		"/snapshot &lt;path&gt; - 记录目录当前的文件列表\n" +
+		"/diff &lt;path&gt; - 对比目录与上次快照（新增/删除/变化，可下载新增文件）\n" +
+		"/testrule &lt;文件名&gt; - 测试文件名命中的分类规则和下载路径（多个文件名每行一个）\n" +
+		"/reorganize - 按当前分类规则整理本地下载目录中已下载的文件（先预览，确认后移动，仅管理员）\n" +
+		"/delete [--dryrun] &lt;path&gt; - 删除文件或目录（--dryrun 只预览不删除）\n" +
+		"/savepreset &lt;名称&gt; &lt;路径&gt; [24h|7d|all] [--all] [--ext=mkv] - 保存下载预设\n" +
+		"/preset &lt;名称&gt; - 运行下载预设（预览后确认下载）\n" +
+		"/presets - 查看/运行/删除下载预设\n" +
+		"/pin [path] - 收藏目录（不带路径时显示收藏夹）\n" +
+		"/unpin &lt;path&gt; - 取消收藏目录\n" +
+		"/bandwidth - 查看最近1小时/24小时带宽使用\n" +
+		"/diskspace - 查看下载目录的总容量、已用和可用空间\n" +
+		"/stats [today|week|month] - 按分类统计今日/本周/本月完成的下载数量和大小\n" +
+		"/ping - 检查机器人是否在线，并测量 Alist 和 aria2 的延迟\n" +
+		"/testnotify [telegram|email] - 测试通知渠道\n\n" +
 		"<b>LLM重命名说明:</b>\n" +
 		"• /rename 默认使用TMDB，可添加 --llm 启用LLM\n" +
 		"• /llmrename 专用LLM重命名命令\n" +
@@ -113,10 +159,16 @@ func (bc *BasicCommands) buildHelpContent(includeBackButton bool) (string, tgbot
 		"• <code>/download</code> - 预览最近24小时的视频文件（使用 <code>/download confirm</code> 开始下载）\n" +
 		"• <code>/download 5m</code> - 预览最近5分钟的视频文件（使用 <code>/download confirm 5m</code> 下载）\n" +
 		"• <code>/download 48</code> - 预览最近48小时的视频文件（使用 <code>/download confirm 48</code> 下载）\n" +
+		"• <code>/download 48 --all</code> - 预览时包含非视频文件（默认只包含视频，见 alist.default_video_only）\n" +
+		"• <code>/download yesterday</code> - 预览昨天（整天）的视频文件（使用 <code>/download confirm yesterday</code> 下载）\n" +
+		"• <code>/download latest 10 /path</code> - 预览目录中最新修改的10个文件（不限时间，加 <code>confirm</code> 下载）\n" +
 		"• <code>/download 2025-09-01 2025-09-26</code> - 预览指定日期范围的文件\n" +
 		"• <code>/download confirm 2025-09-01 2025-09-26</code> - 下载指定日期范围的文件\n" +
 		"• <code>/download 2025-09-01T00:00:00Z 2025-09-26T23:59:59Z</code> - 预览精确时间范围（加 <code>confirm</code> 下载）\n" +
-		"• <code>/download https://example.com/file.zip</code> - 直接下载指定URL文件\n\n" +
+		"• <code>/download https://example.com/file.zip</code> - 直接下载指定URL文件\n" +
+		"• <code>/download URL header=Authorization:xxx cookie=a=1;b=2</code> - 附带请求头/Cookie下载受保护链接，含空格的值需加双引号，如 <code>header=\"Authorization: Bearer xxx\"</code>\n" +
+		"• <code>/download URL speed=2M</code> - 限制该任务的下载速度（单位 K/M/G）\n" +
+		"• <code>/download URL dir=anime</code> - 下载到指定目录（相对 aria2 下载目录，不自动分类）\n\n" +
 		"<b>时间格式说明:</b>\n" +
 		"• 分钟数：1m-525600m（最大一年），例如：5m, 30m, 120m\n" +
 		"• 小时数：1-8760（最大一年），例如：1, 24, 168\n" +
@@ -127,7 +179,11 @@ func (bc *BasicCommands) buildHelpContent(includeBackButton bool) (string, tgbot
 		"/tasks - 查看我的定时任务\n" +
 		"/quicktask &lt;类型&gt; [路径] - 快捷创建任务\n" +
 		"/addtask - 自定义任务（查看详细帮助）\n" +
+		"/addwindow - 在每天的时段内按间隔运行的任务\n" +
+		"/cron &lt;表达式&gt; - 校验cron表达式并预览执行时间\n" +
+		"/today - 今日定时任务运行汇总\n" +
 		"/runtask &lt;id&gt; - 立即运行任务\n" +
+		"/retrytask &lt;id&gt; - 只重试任务中创建失败的文件\n" +
 		"/deltask &lt;id&gt; - 删除任务\n\n" +
 		"<b>快捷任务类型:</b>\n" +
 		"• <code>daily</code> - 每日下载（24小时内文件）\n" +
@@ -186,7 +242,7 @@ func (bc *BasicCommands) HandleStatus(chatID int64) {
 	formatter := bc.messageUtils.GetFormatter().(*utils.MessageFormatter)
 	message := formatter.FormatSimpleSystemStatus(utils.SimpleSystemStatusData{
 		TelegramStatus: safeMapString(telegramInfo, "status"),
-		Aria2Status:    safeMapString(aria2Info, "status"),
+		Aria2Status:    formatter.FormatAria2Status(safeMapString(aria2Info, "status")),
 		Aria2Version:   safeMapString(aria2Info, "version"),
 		ServerPort:     safeMapString(serverInfo, "port"),
 		ServerMode:     safeMapString(serverInfo, "mode"),
@@ -278,7 +334,8 @@ func (bc *BasicCommands) HandlePreviewMenu(chatID int64) {
 	message := "<b>选择预览时间范围</b>\n\n" +
 		"请选择要预览的时间范围：\n" +
 		"• 预览 5/10/30 分钟内的文件\n" +
-		"• 预览 1/3/6 小时内的文件\n\n" +
+		"• 预览 1/3/6 小时内的文件\n" +
+		"• 预览昨天（整天）的文件\n\n" +
 		"也可以直接输入命令：<code>/download &lt;数字&gt;</code>（小时）或 <code>/download &lt;数字&gt;m</code>（分钟）来自定义时间范围。"
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
@@ -293,6 +350,7 @@ func (bc *BasicCommands) HandlePreviewMenu(chatID int64) {
 			tgbotapi.NewInlineKeyboardButtonData("6小时", "preview_hours|6"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 昨天", "preview_yesterday"),
 			tgbotapi.NewInlineKeyboardButtonData("自定义时间", "preview_custom"),
 			tgbotapi.NewInlineKeyboardButtonData("关闭", "preview_cancel"),
 		),
@@ -336,6 +394,9 @@ func (bc *BasicCommands) HandleHealthCheck(chatID int64) {
 	message += "服务状态: 正常\n"
 	message += fmt.Sprintf("端口: %s\n", bc.config.Server.Port)
 	message += fmt.Sprintf("模式: %s\n", bc.config.Server.Mode)
+	if bc.config.Server.ReadOnly {
+		message += "只读模式: 开启（删除/移动/重命名已禁用）\n"
+	}
 	message += "\nAlist配置:\n"
 	message += fmt.Sprintf("地址: %s\n", bc.config.Alist.BaseURL)
 	message += fmt.Sprintf("默认路径: %s\n", bc.config.Alist.DefaultPath)
@@ -351,3 +412,51 @@ func (bc *BasicCommands) HandleHealthCheck(chatID int64) {
 
 	bc.messageUtils.SendMessageHTML(chatID, message)
 }
+
+// pingTimeout bounds each dependency call of /ping so a hung service still gets a quick reply
+const pingTimeout = 5 * time.Second
+
+// HandlePing replies "pong" with the time spent handling the command since receivedAt
+// and the latency of a single cheap call to Alist and aria2, all in milliseconds.
+func (bc *BasicCommands) HandlePing(chatID int64, receivedAt time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	// Both dependencies are measured concurrently so one slow service does not add to the other
+	var alistLatency, aria2Latency time.Duration
+	var alistErr, aria2Err error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		alistLatency, alistErr = bc.fileService.PingAlist(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		aria2Latency, aria2Err = bc.downloadService.PingAria2(ctx)
+	}()
+	wg.Wait()
+
+	formatter := bc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+	lines := []string{
+		formatter.FormatTitle("🏓", "pong"),
+		"",
+		formatter.FormatField("处理耗时", formatLatency(time.Since(receivedAt))),
+		formatter.FormatField("Alist", bc.pingResult(alistLatency, alistErr)),
+		formatter.FormatField("aria2", bc.pingResult(aria2Latency, aria2Err)),
+	}
+	bc.messageUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+}
+
+// pingResult renders a dependency latency, or the failure with the time it took to fail
+func (bc *BasicCommands) pingResult(latency time.Duration, err error) string {
+	if err != nil {
+		return fmt.Sprintf("❌ %s（%s）", bc.messageUtils.EscapeHTML(err.Error()), formatLatency(latency))
+	}
+	return "✅ " + formatLatency(latency)
+}
+
+// formatLatency formats a duration in whole milliseconds
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%d ms", d.Milliseconds())
+}
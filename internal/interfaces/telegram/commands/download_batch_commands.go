@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
 	"github.com/easayliu/alist-aria2-download/pkg/utils/time"
 )
 
@@ -20,7 +22,8 @@ type TimeParseResult struct {
 }
 
 // handleManualDownload handles manual download functionality
-func (dc *DownloadCommands) handleManualDownload(ctx context.Context, chatID int64, timeArgs []string, preview bool) {
+// videoOnly restricts the listing to video files (--all clears it)
+func (dc *DownloadCommands) handleManualDownload(ctx context.Context, chatID int64, timeArgs []string, preview, videoOnly bool) {
 	// Parse time parameters
 	timeResult, err := dc.parseTimeArguments(timeArgs)
 	if err != nil {
@@ -42,7 +45,7 @@ func (dc *DownloadCommands) handleManualDownload(ctx context.Context, chatID int
 		Path:      path,
 		StartTime: timeResult.StartTime,
 		EndTime:   timeResult.EndTime,
-		VideoOnly: true, // Only process video files
+		VideoOnly: videoOnly,
 	}
 
 	// Call application service to get files by time range
@@ -66,13 +69,13 @@ func (dc *DownloadCommands) handleManualDownload(ctx context.Context, chatID int
 		message := formatter.FormatTitle(title, "") + "\n\n" +
 			formatter.FormatField("时间范围", timeResult.Description) + "\n" +
 			formatter.FormatField("结果", "未找到符合条件的文件")
-		dc.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+		dc.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageTransient)
 		return
 	}
 
 	if preview {
 		// Preview mode: display file info and confirmation button
-		dc.sendManualDownloadPreview(chatID, response, timeResult, timeArgs)
+		dc.sendManualDownloadPreview(chatID, response, timeResult, timeArgs, videoOnly)
 	} else {
 		// Direct download mode: create download tasks
 		dc.executeManualDownload(ctx, chatID, response, timeResult)
@@ -83,8 +86,9 @@ func (dc *DownloadCommands) handleManualDownload(ctx context.Context, chatID int
 // Supported formats:
 // 1. Number - hours (e.g., 48)
 // 2. Minutes - number with 'm' suffix (e.g., 30m)
-// 3. Date range - two dates (e.g., 2025-09-01 2025-09-26)
-// 4. Time range - two timestamps (e.g., 2025-09-01T00:00:00Z 2025-09-26T23:59:59Z)
+// 3. Yesterday - the previous calendar day (yesterday or 昨天)
+// 4. Date range - two dates (e.g., 2025-09-01 2025-09-26)
+// 5. Time range - two timestamps (e.g., 2025-09-01T00:00:00Z 2025-09-26T23:59:59Z)
 func (dc *DownloadCommands) parseTimeArguments(args []string) (*TimeParseResult, error) {
 	if len(args) == 0 {
 		// Default 24 hours
@@ -99,6 +103,16 @@ func (dc *DownloadCommands) parseTimeArguments(args []string) (*TimeParseResult,
 	if len(args) == 1 {
 		arg := args[0]
 
+		// 昨天整天（自然日）
+		if strings.EqualFold(arg, "yesterday") || arg == "昨天" {
+			timeRange := timeutil.CreateYesterdayRange()
+			return &TimeParseResult{
+				StartTime:   timeRange.Start,
+				EndTime:     timeRange.End,
+				Description: "昨天（" + timeRange.Start.Format("2006-01-02") + "）",
+			}, nil
+		}
+
 		// 检查是否为分钟格式（以m结尾）
 		if strings.HasSuffix(strings.ToLower(arg), "m") {
 			minuteStr := strings.TrimSuffix(strings.ToLower(arg), "m")
@@ -134,7 +148,7 @@ func (dc *DownloadCommands) parseTimeArguments(args []string) (*TimeParseResult,
 			}, nil
 		}
 
-		return nil, fmt.Errorf("无效的时间格式，应为小时数（如：48）或分钟数（如：30m）")
+		return nil, fmt.Errorf("无效的时间格式，应为小时数（如：48）、分钟数（如：30m）或 yesterday")
 	}
 
 	if len(args) == 2 {
@@ -165,7 +179,7 @@ func (dc *DownloadCommands) parseTimeArguments(args []string) (*TimeParseResult,
 }
 
 // sendManualDownloadPreview sends manual download preview
-func (dc *DownloadCommands) sendManualDownloadPreview(chatID int64, response *contracts.TimeRangeFileResponse, timeResult *TimeParseResult, timeArgs []string) {
+func (dc *DownloadCommands) sendManualDownloadPreview(chatID int64, response *contracts.TimeRangeFileResponse, timeResult *TimeParseResult, timeArgs []string, videoOnly bool) {
 	// Get configured default path
 	config := dc.container.GetConfig()
 	path := config.Alist.DefaultPath
@@ -177,7 +191,8 @@ func (dc *DownloadCommands) sendManualDownloadPreview(chatID int64, response *co
 	message := fmt.Sprintf(
 		"<b>手动下载预览</b>\n\n"+
 			"<b>时间范围:</b> %s\n"+
-			"<b>路径:</b> <code>%s</code>\n\n"+
+			"<b>路径:</b> <code>%s</code>\n"+
+			"<b>文件类型:</b> %s\n\n"+
 			"<b>文件统计:</b>\n"+
 			"• 总文件: %d 个\n"+
 			"• 总大小: %s\n"+
@@ -186,12 +201,17 @@ func (dc *DownloadCommands) sendManualDownloadPreview(chatID int64, response *co
 			"• 其他: %d 个",
 		timeResult.Description,
 		dc.messageUtils.EscapeHTML(path),
+		utils.FileScopeLabel(videoOnly),
 		response.Summary.TotalFiles,
 		response.Summary.TotalSizeFormatted,
 		response.Summary.MovieFiles,
 		response.Summary.TVFiles,
 		response.Summary.OtherFiles,
 	)
+	formatter := dc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+	for _, line := range formatter.FormatExtraCategoryCounts(response.Summary.MusicFiles, response.Summary.DocumentFiles) {
+		message += "\n" + line
+	}
 
 	if len(response.Files) > 0 {
 		message += "\n\n<b>示例文件:</b>\n"
@@ -201,12 +221,8 @@ func (dc *DownloadCommands) sendManualDownloadPreview(chatID int64, response *co
 		}
 		for i := 0; i < displayCount; i++ {
 			file := response.Files[i]
-			filename := dc.messageUtils.EscapeHTML(file.Name)
-			// Limit filename length
-			if len([]rune(filename)) > 40 {
-				runes := []rune(filename)
-				filename = string(runes[:40]) + "..."
-			}
+			// Limit filename length before escaping so entities are never cut
+			filename := dc.messageUtils.EscapeHTML(strutil.TruncateRunes(file.Name, 40, "..."))
 			downloadPath := dc.messageUtils.EscapeHTML(file.DownloadPath)
 			message += fmt.Sprintf("• %s → <code>%s</code>\n", filename, downloadPath)
 		}
@@ -220,10 +236,16 @@ func (dc *DownloadCommands) sendManualDownloadPreview(chatID int64, response *co
 	if len(timeArgs) > 0 {
 		confirmCommand += " " + strings.Join(timeArgs, " ")
 	}
+	if !videoOnly {
+		confirmCommand += " " + utils.AllFilesFlag
+		if warning := utils.VideoOnlyConflictWarning(videoOnly, config.Download.VideoOnly); warning != "" {
+			message += "\n\n" + warning
+		}
+	}
 
 	message += fmt.Sprintf("\n\n⚠️ 预览有效期 10 分钟。发送 <code>%s</code> 开始下载。", confirmCommand)
 
-	dc.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+	dc.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageTransient)
 }
 
 // executeManualDownload executes manual download
@@ -235,27 +257,7 @@ func (dc *DownloadCommands) executeManualDownload(ctx context.Context, chatID in
 		return
 	}
 
-	// Build batch download request
-	var downloadItems []contracts.DownloadRequest
-	for _, file := range response.Files {
-		downloadItems = append(downloadItems, contracts.DownloadRequest{
-			URL:          file.InternalURL,
-			Filename:     file.Name,
-			Directory:    file.DownloadPath,
-			AutoClassify: true,
-		})
-	}
-
-	config := dc.container.GetConfig()
-	batchRequest := contracts.BatchDownloadRequest{
-		Items:        downloadItems,
-		VideoOnly:    config.Download.VideoOnly,
-		AutoClassify: true,
-	}
-
-	// Call application service to create batch download
-	downloadService := dc.container.GetDownloadService()
-	batchResponse, err := downloadService.CreateBatchDownload(ctx, batchRequest)
+	batchResponse, err := dc.createBatchDownload(ctx, response.Files)
 	if err != nil {
 		formatter := dc.messageUtils.GetFormatter().(*utils.MessageFormatter)
 		dc.messageUtils.SendMessage(chatID, formatter.FormatError("批量下载", err))
@@ -263,6 +265,7 @@ func (dc *DownloadCommands) executeManualDownload(ctx context.Context, chatID in
 	}
 
 	// Get configured default path
+	config := dc.container.GetConfig()
 	path := config.Alist.DefaultPath
 	if path == "" {
 		path = "/"
@@ -278,10 +281,7 @@ func (dc *DownloadCommands) executeManualDownload(ctx context.Context, chatID in
 			"• 总大小: %s\n"+
 			"• 电影: %d 个\n"+
 			"• 剧集: %d 个\n"+
-			"• 其他: %d 个\n\n"+
-			"<b>下载结果:</b>\n"+
-			"• 成功: %d\n"+
-			"• 失败: %d",
+			"• 其他: %d 个",
 		timeResult.Description,
 		dc.messageUtils.EscapeHTML(path),
 		response.Summary.TotalFiles,
@@ -289,6 +289,12 @@ func (dc *DownloadCommands) executeManualDownload(ctx context.Context, chatID in
 		response.Summary.MovieFiles,
 		response.Summary.TVFiles,
 		response.Summary.OtherFiles,
+	)
+	formatter := dc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+	for _, line := range formatter.FormatExtraCategoryCounts(response.Summary.MusicFiles, response.Summary.DocumentFiles) {
+		message += "\n" + line
+	}
+	message += fmt.Sprintf("\n\n<b>下载结果:</b>\n• 成功: %d\n• 失败: %d",
 		batchResponse.SuccessCount,
 		batchResponse.FailureCount,
 	)
@@ -297,5 +303,38 @@ func (dc *DownloadCommands) executeManualDownload(ctx context.Context, chatID in
 		message += fmt.Sprintf("\n\n⚠️ 有 %d 个文件下载失败，请检查日志获取详细信息", batchResponse.FailureCount)
 	}
 
-	dc.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+	dc.sendBatchResult(chatID, message, batchResponse)
+}
+
+// sendBatchResult sends a batch download result; with failures the message offers a
+// "retry all failed" button, otherwise it follows telegram.auto_delete.important_seconds
+func (dc *DownloadCommands) sendBatchResult(chatID int64, message string, batchResponse *contracts.BatchDownloadResponse) {
+	if keyboard := utils.BatchRetryKeyboard(batchResponse.BatchID, batchResponse.FailureCount); keyboard != nil {
+		dc.messageUtils.SendMessageWithKeyboard(chatID, message, "HTML", keyboard)
+		return
+	}
+	dc.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageImportant)
+}
+
+// createBatchDownload queues the files as one auto-classified batch
+func (dc *DownloadCommands) createBatchDownload(ctx context.Context, files []contracts.FileResponse) (*contracts.BatchDownloadResponse, error) {
+	var downloadItems []contracts.DownloadRequest
+	for _, file := range files {
+		downloadItems = append(downloadItems, contracts.DownloadRequest{
+			URL:          file.InternalURL,
+			Filename:     file.Name,
+			Directory:    file.DownloadPath,
+			AutoClassify: true,
+			SourcePath:   file.Path,
+		})
+	}
+
+	batchRequest := contracts.BatchDownloadRequest{
+		Items:        downloadItems,
+		VideoOnly:    dc.container.GetConfig().Download.VideoOnly,
+		AutoClassify: true,
+	}
+
+	// Call application service to create batch download
+	return dc.container.GetDownloadService().CreateBatchDownload(ctx, batchRequest)
 }
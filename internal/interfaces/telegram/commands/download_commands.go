@@ -2,12 +2,16 @@ package commands
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/application/services"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
 )
 
 // DownloadCommands handles download-related commands - pure protocol conversion layer
@@ -25,19 +29,39 @@ func NewDownloadCommands(container *services.ServiceContainer, messageUtils type
 }
 
 // HandleDownload handles download command - Telegram protocol conversion
-func (dc *DownloadCommands) HandleDownload(chatID int64, command string) {
-	ctx := context.Background()
+// userID selects the user's download base path, if configured
+func (dc *DownloadCommands) HandleDownload(chatID, userID int64, command string) {
+	ctx := contracts.WithUserID(context.Background(), userID)
 	parts := strings.Fields(command)
 
+	// --all includes non-video files in time range downloads
+	args, includeAll := utils.ExtractAllFilesFlag(parts[1:])
+	videoOnly := dc.container.GetConfig().Alist.DefaultVideoOnly && !includeAll
+	parts = append(parts[:1], args...)
+
 	// If no additional parameters, default to preview mode (last 24 hours)
 	if len(parts) == 1 {
-		dc.handleManualDownload(ctx, chatID, []string{}, true)
+		dc.handleManualDownload(ctx, chatID, []string{}, true, videoOnly)
 		return
 	}
 
 	// Check if first parameter is a URL (starts with http)
 	if strings.HasPrefix(parts[1], "http") {
-		dc.handleURLDownload(ctx, chatID, parts[1])
+		// Re-split honoring quotes so values like header="Authorization: Bearer x" keep their spaces
+		urlArgs, _ := utils.ExtractAllFilesFlag(utils.SplitQuotedArgs(command)[1:])
+		urlArgs = urlArgs[1:]
+		speed, err := parseSpeedArg(urlArgs)
+		if err != nil {
+			dc.messageUtils.SendMessageHTML(chatID, "❌ 限速格式错误，单位必须是 K、M 或 G\n\n示例：<code>/download https://example.com/file.mkv speed=2M</code>")
+			return
+		}
+		directory, err := parseDirArg(urlArgs, dc.container.GetConfig().Aria2.DownloadDir)
+		if err != nil {
+			dc.messageUtils.SendMessageHTML(chatID, "❌ 下载目录无效："+dc.messageUtils.EscapeHTML(err.Error())+"\n\n示例：<code>/download https://example.com/file.mkv dir=anime</code>")
+			return
+		}
+		headers, cookie := parseHeaderArgs(urlArgs)
+		dc.handleURLDownload(ctx, chatID, parts[1], headers, cookie, speed, directory)
 		return
 	}
 
@@ -71,7 +95,13 @@ func (dc *DownloadCommands) HandleDownload(chatID int64, command string) {
 		}
 	}
 
-	dc.handleManualDownload(ctx, chatID, timeArgs, preview)
+	// Count-based selection: /download latest <N> [path]
+	if len(timeArgs) > 0 && strings.EqualFold(timeArgs[0], latestSubCommand) {
+		dc.handleLatestDownload(ctx, chatID, timeArgs[1:], preview, videoOnly)
+		return
+	}
+
+	dc.handleManualDownload(ctx, chatID, timeArgs, preview, videoOnly)
 }
 
 // HandleCancel handles cancel download command
@@ -99,12 +129,17 @@ func (dc *DownloadCommands) HandleCancel(chatID int64, command string) {
 	dc.messageUtils.SendMessageHTML(chatID, message)
 }
 
-// handleURLDownload handles URL download
-func (dc *DownloadCommands) handleURLDownload(ctx context.Context, chatID int64, url string) {
+// handleURLDownload handles URL download; speed is an optional per-task limit such as "2M",
+// directory an explicit target directory that takes precedence over auto-classification
+func (dc *DownloadCommands) handleURLDownload(ctx context.Context, chatID int64, url string, headers map[string]string, cookie string, speed string, directory string) {
 	// Build download request
 	req := contracts.DownloadRequest{
-		URL:          url,
-		AutoClassify: true,
+		URL:              url,
+		Directory:        directory,
+		AutoClassify:     directory == "",
+		Headers:          headers,
+		Cookie:           cookie,
+		MaxDownloadSpeed: speed,
 	}
 
 	// Call application service to create download
@@ -118,12 +153,93 @@ func (dc *DownloadCommands) handleURLDownload(ctx context.Context, chatID int64,
 
 	// Send confirmation message using unified formatter
 	formatter := dc.messageUtils.GetFormatter().(*utils.MessageFormatter)
-	message := formatter.FormatDownloadCreated(utils.DownloadCreatedData{
-		URL:      url,
-		GID:      response.ID,
-		Filename: response.Filename,
-	})
-	dc.messageUtils.SendMessageHTML(chatID, message)
+	createdData := utils.DownloadCreatedData{
+		URL:        url,
+		GID:        response.ID,
+		Filename:   response.Filename,
+		SpeedLimit: speed,
+		EscapeHTML: dc.messageUtils.EscapeHTML,
+	}
+	if directory != "" {
+		// Show the final directory, the user's base path may have been applied
+		createdData.Directory = response.Directory
+	}
+	dc.messageUtils.SendMessageHTML(chatID, formatter.FormatDownloadCreated(createdData))
+}
+
+// parseHeaderArgs parses optional "header=Name:Value" and "cookie=..." arguments after the URL.
+// Values containing spaces must be quoted: header="Authorization: Bearer x"
+func parseHeaderArgs(args []string) (map[string]string, string) {
+	var headers map[string]string
+	var cookie string
+
+	for _, arg := range args {
+		if value, found := strings.CutPrefix(arg, "header="); found {
+			name, headerValue, ok := strings.Cut(value, ":")
+			name = strings.TrimSpace(name)
+			if !ok || name == "" {
+				continue
+			}
+			if headers == nil {
+				headers = make(map[string]string)
+			}
+			headers[name] = strings.TrimSpace(headerValue)
+			continue
+		}
+		if value, found := strings.CutPrefix(arg, "cookie="); found {
+			cookie = value
+		}
+	}
+
+	return headers, cookie
+}
+
+// parseSpeedArg parses the optional "speed=<limit>" argument after the URL (e.g. speed=500K, speed=2M)
+// and returns the normalized limit, or "" when absent
+func parseSpeedArg(args []string) (string, error) {
+	for _, arg := range args {
+		value, found := strings.CutPrefix(arg, "speed=")
+		if !found {
+			continue
+		}
+		if _, err := strutil.ParseSpeedLimit(value); err != nil {
+			return "", err
+		}
+		return strings.ToUpper(value), nil
+	}
+	return "", nil
+}
+
+// parseDirArg parses the optional "dir=<directory>" argument after the URL. Relative directories are
+// resolved under downloadDir; the result must stay within downloadDir, ".." segments are rejected.
+// Returns "" when absent.
+func parseDirArg(args []string, downloadDir string) (string, error) {
+	for _, arg := range args {
+		value, found := strings.CutPrefix(arg, "dir=")
+		if !found {
+			continue
+		}
+		if value == "" {
+			return "", fmt.Errorf("目录不能为空")
+		}
+		if slices.Contains(strings.Split(value, "/"), "..") {
+			return "", fmt.Errorf("目录不能包含 ..")
+		}
+		if downloadDir == "" {
+			return "", fmt.Errorf("未配置 aria2.download_dir，无法指定下载目录")
+		}
+
+		base := path.Clean(downloadDir)
+		directory := path.Clean(value)
+		if !path.IsAbs(directory) {
+			directory = path.Join(base, directory)
+		}
+		if directory != base && !strings.HasPrefix(directory, strings.TrimSuffix(base, "/")+"/") {
+			return "", fmt.Errorf("目录必须位于 %s 之下", base)
+		}
+		return directory, nil
+	}
+	return "", nil
 }
 
 // handleDownloadFileByPath downloads a single file by path
@@ -165,6 +281,7 @@ func (dc *DownloadCommands) handleDownloadDirectoryByPath(ctx context.Context, c
 		VideoOnly:     true, // Only download video files
 		AutoClassify:  true,
 		Recursive:     true,
+		NotifyChatID:  chatID,
 	}
 
 	// Call application service to download directory
@@ -204,6 +321,13 @@ func (dc *DownloadCommands) handleDownloadDirectoryByPath(ctx context.Context, c
 
 	// Use unified formatter
 	resultMessage := dc.messageUtils.FormatDownloadDirectoryResult(summary)
+	if note := utils.BatchChunkNote(response.PendingCount, response.ChunkCount); note != "" {
+		resultMessage += "\n\n" + note
+	}
+	if keyboard := utils.BatchRetryKeyboard(response.BatchID, response.FailureCount); keyboard != nil {
+		dc.messageUtils.SendMessageWithKeyboard(chatID, resultMessage, "HTML", keyboard)
+		return
+	}
 	dc.messageUtils.SendMessageHTML(chatID, resultMessage)
 }
 
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+)
+
+func TestParseDirArg(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		downloadDir string
+		want        string
+		wantErr     bool
+	}{
+		{name: "未指定目录", args: []string{"speed=2M"}, downloadDir: "/downloads", want: ""},
+		{name: "相对路径", args: []string{"dir=anime/2024"}, downloadDir: "/downloads", want: "/downloads/anime/2024"},
+		{name: "下载目录内的绝对路径", args: []string{"dir=/downloads/movies/"}, downloadDir: "/downloads/", want: "/downloads/movies"},
+		{name: "下载目录本身", args: []string{"dir=/downloads"}, downloadDir: "/downloads", want: "/downloads"},
+		{name: "包含 ..", args: []string{"dir=anime/../../etc"}, downloadDir: "/downloads", wantErr: true},
+		{name: "下载目录外的绝对路径", args: []string{"dir=/etc"}, downloadDir: "/downloads", wantErr: true},
+		{name: "前缀相同的兄弟目录", args: []string{"dir=/downloads2/x"}, downloadDir: "/downloads", wantErr: true},
+		{name: "空值", args: []string{"dir="}, downloadDir: "/downloads", wantErr: true},
+		{name: "未配置下载目录", args: []string{"dir=anime"}, downloadDir: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDirArg(tt.args, tt.downloadDir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDirArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDirArg() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHeaderArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		wantHeaders map[string]string
+		wantCookie  string
+	}{
+		{name: "无空格的值", command: "/download https://a.com/f header=Referer:https://a.com cookie=a=1;b=2", wantHeaders: map[string]string{"Referer": "https://a.com"}, wantCookie: "a=1;b=2"},
+		{name: "引号内含空格的值", command: `/download https://a.com/f header="Authorization: Bearer abc" cookie="a=1; b=2"`, wantHeaders: map[string]string{"Authorization": "Bearer abc"}, wantCookie: "a=1; b=2"},
+		{name: "缺少冒号的请求头被忽略", command: "/download https://a.com/f header=Authorization", wantHeaders: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, cookie := parseHeaderArgs(utils.SplitQuotedArgs(tt.command)[2:])
+			if fmt.Sprint(headers) != fmt.Sprint(tt.wantHeaders) || cookie != tt.wantCookie {
+				t.Errorf("parseHeaderArgs() = %v, %q, want %v, %q", headers, cookie, tt.wantHeaders, tt.wantCookie)
+			}
+		})
+	}
+}
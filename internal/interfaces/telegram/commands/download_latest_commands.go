@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
+)
+
+// latestSubCommand selects the N most recently modified files instead of a time range
+const latestSubCommand = "latest"
+
+// latestPreviewLimit caps the files listed in the latest-files preview
+const latestPreviewLimit = 10
+
+// handleLatestDownload handles /download latest <N> [path]
+// Files are selected by modification time regardless of age; path defaults to alist.default_path.
+func (dc *DownloadCommands) handleLatestDownload(ctx context.Context, chatID int64, args []string, preview, videoOnly bool) {
+	formatter := dc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+
+	count, path, err := dc.parseLatestArguments(args)
+	if err != nil {
+		dc.messageUtils.SendMessageHTML(chatID, formatter.FormatError("参数", err)+
+			fmt.Sprintf("\n\n用法：<code>/download latest &lt;数量&gt; [路径]</code>（数量 1-%d）", contracts.MaxLatestFilesCount))
+		return
+	}
+
+	response, err := dc.container.GetFileService().GetLatestFiles(ctx, contracts.LatestFilesRequest{
+		Path:      path,
+		Count:     count,
+		VideoOnly: videoOnly,
+	})
+	if err != nil {
+		dc.messageUtils.SendMessage(chatID, formatter.FormatError("获取最新文件", err))
+		return
+	}
+
+	if len(response.Files) == 0 {
+		message := formatter.FormatTitle("ℹ️", "最新文件下载") + "\n\n" +
+			formatter.FormatFieldCode("路径", dc.messageUtils.EscapeHTML(path)) + "\n" +
+			formatter.FormatField("结果", "未找到符合条件的文件")
+		dc.messageUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageTransient)
+		return
+	}
+
+	if preview {
+		dc.sendLatestDownloadPreview(chatID, response, count, args, videoOnly)
+		return
+	}
+	dc.executeLatestDownload(ctx, chatID, response)
+}
+
+// parseLatestArguments parses "<N> [path]"; the path may contain spaces
+func (dc *DownloadCommands) parseLatestArguments(args []string) (int, string, error) {
+	if len(args) == 0 {
+		return 0, "", fmt.Errorf("缺少文件数量")
+	}
+
+	count, err := strconv.Atoi(args[0])
+	if err != nil || count <= 0 {
+		return 0, "", fmt.Errorf("文件数量必须是正整数")
+	}
+	if count > contracts.MaxLatestFilesCount {
+		return 0, "", fmt.Errorf("文件数量不能超过 %d", contracts.MaxLatestFilesCount)
+	}
+
+	path := strings.Join(args[1:], " ")
+	if path == "" {
+		path = dc.container.GetConfig().Alist.DefaultPath
+	}
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		return 0, "", fmt.Errorf("路径必须以 / 开头")
+	}
+	return count, path, nil
+}
+
+// sendLatestDownloadPreview lists the selected files with their modification times
+func (dc *DownloadCommands) sendLatestDownloadPreview(chatID int64, response *contracts.LatestFilesResponse, count int, args []string, videoOnly bool) {
+	formatter := dc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+
+	lines := []string{
+		formatter.FormatTitle("📋", "最新文件下载预览"),
+		"",
+		formatter.FormatFieldCode("路径", dc.messageUtils.EscapeHTML(response.Path)),
+		formatter.FormatField("选择", fmt.Sprintf("最新 %d 个（找到 %d 个）", count, len(response.Files))),
+		formatter.FormatField("文件类型", utils.FileScopeLabel(videoOnly)),
+		formatter.FormatField("总大小", response.Summary.TotalSizeFormatted),
+	}
+	if response.Truncated {
+		lines = append(lines, fmt.Sprintf("⚠️ 目录文件较多，仅在前 %d 个文件中挑选", response.ScannedFiles))
+	}
+
+	lines = append(lines, "", formatter.FormatSection("文件（按修改时间倒序）"))
+	for i, file := range response.Files {
+		if i == latestPreviewLimit {
+			lines = append(lines, fmt.Sprintf("• ... 还有 %d 个文件", len(response.Files)-latestPreviewLimit))
+			break
+		}
+		modified := "未知时间"
+		if !file.Modified.IsZero() {
+			modified = file.Modified.Local().Format("2006-01-02 15:04")
+		}
+		lines = append(lines, fmt.Sprintf("• %s  %s (%s)",
+			modified, dc.messageUtils.EscapeHTML(strutil.TruncateRunes(file.Name, 40, "...")), file.SizeFormatted))
+	}
+
+	confirmCommand := "/download confirm " + latestSubCommand + " " + strings.Join(args, " ")
+	if !videoOnly {
+		confirmCommand += " " + utils.AllFilesFlag
+		if warning := utils.VideoOnlyConflictWarning(videoOnly, dc.container.GetConfig().Download.VideoOnly); warning != "" {
+			lines = append(lines, "", warning)
+		}
+	}
+	lines = append(lines, "", fmt.Sprintf("发送 <code>%s</code> 开始下载。", dc.messageUtils.EscapeHTML(confirmCommand)))
+
+	dc.messageUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+}
+
+// executeLatestDownload queues the selected files
+func (dc *DownloadCommands) executeLatestDownload(ctx context.Context, chatID int64, response *contracts.LatestFilesResponse) {
+	batchResponse, err := dc.createBatchDownload(ctx, response.Files)
+	if err != nil {
+		formatter := dc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+		dc.messageUtils.SendMessage(chatID, formatter.FormatError("批量下载", err))
+		return
+	}
+
+	message := fmt.Sprintf(
+		"<b>最新文件下载任务已创建</b>\n\n"+
+			"<b>路径:</b> <code>%s</code>\n"+
+			"<b>文件:</b> %d 个（%s）\n\n"+
+			"<b>下载结果:</b>\n"+
+			"• 成功: %d\n"+
+			"• 失败: %d",
+		dc.messageUtils.EscapeHTML(response.Path),
+		response.Summary.TotalFiles,
+		response.Summary.TotalSizeFormatted,
+		batchResponse.SuccessCount,
+		batchResponse.FailureCount,
+	)
+	if batchResponse.FailureCount > 0 {
+		message += fmt.Sprintf("\n\n⚠️ 有 %d 个文件下载失败，请检查日志获取详细信息", batchResponse.FailureCount)
+	}
+
+	dc.sendBatchResult(chatID, message, batchResponse)
+}
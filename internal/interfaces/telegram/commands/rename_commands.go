@@ -91,6 +91,13 @@ func (bc *BasicCommands) HandleRename(chatID int64, command string) {
 		return
 	}
 
+	// 文件名已符合 Emby 标准格式，无需重命名
+	if len(suggestions) == 1 && suggestions[0].Skipped {
+		bc.messageUtils.SendMessageHTML(chatID, fmt.Sprintf("✅ 无需重命名\n\n文件：<code>%s</code>\n\n%s",
+			bc.messageUtils.EscapeHTML(path), bc.messageUtils.EscapeHTML(suggestions[0].SkipReason)))
+		return
+	}
+
 	encodedPath := base64.URLEncoding.EncodeToString([]byte(path))
 
 	message := fmt.Sprintf("<b>重命名建议</b>\n\n原文件名：<code>%s</code>\n\n请选择新名称：\n\n", path)
@@ -115,7 +122,11 @@ func (bc *BasicCommands) HandleRename(chatID int64, command string) {
 			confidenceStr = "⭐"
 		}
 
-		message += fmt.Sprintf("%d. %s %s\n<code>%s</code>\n\n", i+1, label, confidenceStr, s.NewName)
+		message += fmt.Sprintf("%d. %s %s\n<code>%s</code>\n", i+1, label, confidenceStr, s.NewName)
+		if s.Source == "parsed" {
+			message += "⚠️ TMDB暂不可达，仅根据文件名解析，未经验证\n"
+		}
+		message += "\n"
 
 		callbackData := fmt.Sprintf("rename_apply|%d|%s", i, encodedPath)
 		buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
@@ -218,6 +229,8 @@ func (bc *BasicCommands) HandleLLMRename(chatID int64, path string, strategy str
 			sourceIcon = "🎬"
 		case "hybrid":
 			sourceIcon = "🔀"
+		case "parsed":
+			sourceIcon = "📝"
 		}
 
 		message = fmt.Sprintf("<b>%s LLM重命名建议</b> %s\n\n"+
@@ -230,6 +243,9 @@ func (bc *BasicCommands) HandleLLMRename(chatID int64, path string, strategy str
 			bc.messageUtils.EscapeHTML(result.SuggestedName),
 			result.Confidence, confidenceStr,
 			result.Source)
+		if result.Source == "parsed" {
+			message += "\n⚠️ TMDB暂不可达，该建议仅根据文件名解析，未经验证，请确认后再应用"
+		}
 
 		// 添加媒体信息（如果有）
 		if result.MediaInfo != nil {
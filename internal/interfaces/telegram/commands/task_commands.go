@@ -4,113 +4,68 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/application/services/task"
 	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
 )
 
+// cronPreviewCount is the number of fire times shown by /cron
+const cronPreviewCount = 5
+
+// ChatPostChecker checks whether the bot can post messages to a chat
+type ChatPostChecker interface {
+	CheckCanPost(chatID int64) error
+}
+
 // TaskCommands handles scheduled task commands
 type TaskCommands struct {
 	schedulerService *task.SchedulerService
 	config           *config.Config
 	messageUtils     types.MessageSender
+	chatChecker      ChatPostChecker
 }
 
 // NewTaskCommands creates a scheduled task command handler
-func NewTaskCommands(schedulerService *task.SchedulerService, config *config.Config, messageUtils types.MessageSender) *TaskCommands {
+func NewTaskCommands(schedulerService *task.SchedulerService, config *config.Config, messageUtils types.MessageSender, chatChecker ChatPostChecker) *TaskCommands {
 	return &TaskCommands{
 		schedulerService: schedulerService,
 		config:           config,
 		messageUtils:     messageUtils,
+		chatChecker:      chatChecker,
 	}
 }
 
-// HandleTasks handles viewing scheduled tasks
-func (tc *TaskCommands) HandleTasks(chatID int64, userID int64) {
+// HandleAddTask handles adding a scheduled task
+func (tc *TaskCommands) HandleAddTask(chatID int64, userID int64, command string) {
 	if tc.schedulerService == nil {
 		tc.messageUtils.SendMessage(chatID, "定时任务服务未启用")
 		return
 	}
 
-	tasks, err := tc.schedulerService.GetUserTasks(userID)
+	// Optional notify=<chat ID> routes run results to another chat/channel
+	parts, notifyChatID, err := extractNotifyChat(strings.Fields(command))
 	if err != nil {
-		formatter := tc.messageUtils.GetFormatter().(*utils.MessageFormatter)
-		tc.messageUtils.SendMessage(chatID, formatter.FormatError("获取任务", err))
+		tc.messageUtils.SendMessageHTML(chatID, "❌ "+tc.messageUtils.EscapeHTML(err.Error()))
 		return
 	}
-
-	if len(tasks) == 0 {
-		message := "<b>定时任务管理</b>\n\n" +
-			"您还没有创建任何定时任务\n\n" +
-			"<b>添加任务示例:</b>\n" +
-			"<code>/addtask 下载昨日视频 0 2 * * * /movies 24 true</code>\n" +
-			"格式: /addtask 名称 cron表达式 路径 小时数 是否只视频\n\n" +
-			"<b>Cron表达式说明:</b>\n" +
-			"• <code>0 2 * * *</code> - 每天凌晨2点\n" +
-			"• <code>0 */6 * * *</code> - 每6小时\n" +
-			"• <code>0 0 * * 1</code> - 每周一凌晨"
-		tc.messageUtils.SendMessageHTML(chatID, message)
+	// Optional min=<size> / max=<size> only download files within that size range
+	parts, minSize, maxSize, err := extractSizeRange(parts)
+	if err != nil {
+		tc.messageUtils.SendMessageHTML(chatID, "❌ "+tc.messageUtils.EscapeHTML(err.Error()))
 		return
 	}
-
-	formatter := tc.messageUtils.GetFormatter().(*utils.MessageFormatter)
-	message := formatter.FormatTitle("⏰", fmt.Sprintf("定时任务 (%d个)", len(tasks))) + "\n\n"
-
-	for i, task := range tasks {
-		status := "禁用"
-		if task.Enabled {
-			status = "启用"
-		}
-
-		// Calculate time description
-		timeDesc := tc.formatTaskTimeDescription(task.HoursAgo)
-
-		message += fmt.Sprintf(
-			"<b>%d. %s</b> %s\n"+
-				"   ID: <code>%s</code>\n"+
-				"   Cron: <code>%s</code>\n"+
-				"   路径: <code>%s</code>\n"+
-				"   时间范围: 最近<b>%s</b>内修改的文件\n"+
-				"   文件类型: %s\n",
-			i+1, tc.messageUtils.EscapeHTML(task.Name), status,
-			task.ID[:8], task.Cron, task.Path,
-			timeDesc,
-			func() string {
-				if task.VideoOnly {
-					return "仅视频"
-				}
-				return "所有文件"
-			}(),
-		)
-
-		if task.LastRunAt != nil {
-			message += fmt.Sprintf("   上次: %s\n", task.LastRunAt.Format("01-02 15:04"))
-		}
-		if task.NextRunAt != nil {
-			message += fmt.Sprintf("   下次: %s\n", task.NextRunAt.Format("01-02 15:04"))
-		}
-		message += "\n"
-	}
-
-	message += "<b>命令:</b>\n" +
-		"• 立即运行: <code>/runtask ID</code>\n" +
-		"• 删除任务: <code>/deltask ID</code>\n" +
-		"• 添加任务: <code>/addtask</code> 查看帮助"
-
-	tc.messageUtils.SendMessageHTML(chatID, message)
-}
-
-// HandleAddTask handles adding a scheduled task
-func (tc *TaskCommands) HandleAddTask(chatID int64, userID int64, command string) {
-	if tc.schedulerService == nil {
-		tc.messageUtils.SendMessage(chatID, "定时任务服务未启用")
+	// Optional tz=<IANA zone> computes run times in that timezone instead of the scheduler's
+	parts, timezone, err := extractTimezone(parts)
+	if err != nil {
+		tc.messageUtils.SendMessageHTML(chatID, "❌ "+tc.messageUtils.EscapeHTML(err.Error()))
 		return
 	}
-
-	parts := strings.Fields(command)
 	if len(parts) < 5 { // Minimum 5 parameters required (path is optional)
 		tc.sendAddTaskHelp(chatID)
 		return
@@ -125,7 +80,6 @@ func (tc *TaskCommands) HandleAddTask(chatID int64, userID int64, command string
 
 	// Last two parameters are always hoursAgo and videoOnly
 	videoOnly = parts[len(parts)-1] == "true"
-	var err error
 	hoursAgo, err = strconv.Atoi(parts[len(parts)-2])
 	if err != nil {
 		hoursAgo = 24 // default to 24 hours
@@ -152,34 +106,166 @@ func (tc *TaskCommands) HandleAddTask(chatID int64, userID int64, command string
 	// Remove possible quotes
 	cron = strings.Trim(cron, "\"'")
 
-	// Create task
+	// Make sure run results can actually be delivered before creating the task
+	if notifyChatID != 0 && tc.chatChecker != nil {
+		if err := tc.chatChecker.CheckCanPost(notifyChatID); err != nil {
+			formatter := tc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+			tc.messageUtils.SendMessage(chatID, formatter.FormatError("检查通知聊天", err))
+			return
+		}
+	}
+
+	// Create task; tasks added with /addtask report each run's download summary by default
 	task := &entities.ScheduledTask{
-		Name:      name,
+		Name:             name,
+		Enabled:          true,
+		Cron:             cron,
+		Timezone:         timezone,
+		Path:             path,
+		HoursAgo:         hoursAgo,
+		VideoOnly:        videoOnly,
+		MinFileSize:      minSize,
+		MaxFileSize:      maxSize,
+		CreatedBy:        userID,
+		NotifyChatID:     notifyChatID,
+		NotifyOnComplete: true,
+	}
+
+	if err := tc.schedulerService.CreateTask(task); err != nil {
+		formatter := tc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+		tc.messageUtils.SendMessage(chatID, formatter.FormatError("创建任务", err))
+		return
+	}
+
+	notifyLine := ""
+	if sizeRange := strutil.FormatSizeRange(minSize, maxSize); sizeRange != "" {
+		notifyLine = fmt.Sprintf("文件大小: %s\n", sizeRange)
+	}
+	if timezone != "" {
+		notifyLine += fmt.Sprintf("时区: %s\n", timezone)
+	}
+	if notifyChatID != 0 {
+		notifyLine += fmt.Sprintf("通知聊天: <code>%d</code>\n", notifyChatID)
+	}
+
+	message := fmt.Sprintf(
+		"<b>任务创建成功</b>\n\n"+
+			"名称: %s\n"+
+			"ID: <code>%s</code>\n"+
+			"Cron: <code>%s</code>\n"+
+			"路径: %s\n"+
+			"时间范围: 最近%d小时\n"+
+			"只下载视频: %v\n"+
+			"%s\n"+
+			"使用 <code>/runtask %s</code> 立即运行",
+		tc.messageUtils.EscapeHTML(name), task.ID[:8], cron, path, hoursAgo, videoOnly, notifyLine, task.ID[:8],
+	)
+
+	tc.messageUtils.SendMessageHTML(chatID, message)
+}
+
+// HandleAddWindow creates a task that runs every N minutes inside a daily time window.
+// Format: /addwindow <name> <interval minutes> <HH:MM-HH:MM> [path] [hours] [videoOnly]
+func (tc *TaskCommands) HandleAddWindow(chatID int64, userID int64, command string) {
+	if tc.schedulerService == nil {
+		tc.messageUtils.SendMessage(chatID, "定时任务服务未启用")
+		return
+	}
+
+	parts := strings.Fields(command)
+	if len(parts) < 4 {
+		tc.sendAddWindowHelp(chatID)
+		return
+	}
+
+	interval, err := strconv.Atoi(parts[2])
+	if err != nil {
+		tc.messageUtils.SendMessageHTML(chatID, fmt.Sprintf("❌ 间隔必须是分钟数: <code>%s</code>", tc.messageUtils.EscapeHTML(parts[2])))
+		return
+	}
+	start, end, found := strings.Cut(parts[3], "-")
+	if !found {
+		tc.messageUtils.SendMessageHTML(chatID, "❌ 时间窗口格式应为 <code>HH:MM-HH:MM</code>，例如 <code>01:00-06:00</code>")
+		return
+	}
+	window, err := task.NewScheduleWindow(start, end, interval)
+	if err != nil {
+		tc.messageUtils.SendMessageHTML(chatID, "❌ "+tc.messageUtils.EscapeHTML(err.Error()))
+		return
+	}
+
+	path := tc.config.Alist.DefaultPath
+	if path == "" {
+		path = "/"
+	}
+	hoursAgo := 24
+	videoOnly := tc.config.Alist.DefaultVideoOnly
+	// Optional trailing parameters are recognized by their form
+	for _, arg := range parts[4:] {
+		switch {
+		case strings.HasPrefix(arg, "/"):
+			path = arg
+		case arg == "true" || arg == "false":
+			videoOnly = arg == "true"
+		default:
+			if hours, err := strconv.Atoi(arg); err == nil && hours > 0 {
+				hoursAgo = hours
+			}
+		}
+	}
+
+	newTask := &entities.ScheduledTask{
+		Name:      parts[1],
 		Enabled:   true,
-		Cron:      cron,
+		Cron:      task.WindowCron(window),
+		Window:    window,
 		Path:      path,
 		HoursAgo:  hoursAgo,
 		VideoOnly: videoOnly,
 		CreatedBy: userID,
 	}
-
-	if err := tc.schedulerService.CreateTask(task); err != nil {
+	if err := tc.schedulerService.CreateTask(newTask); err != nil {
 		formatter := tc.messageUtils.GetFormatter().(*utils.MessageFormatter)
 		tc.messageUtils.SendMessage(chatID, formatter.FormatError("创建任务", err))
 		return
 	}
 
+	schedule := "内置时间窗口调度（无法用单个 cron 表达式表示）"
+	if newTask.Cron != "" {
+		schedule = fmt.Sprintf("<code>%s</code>", newTask.Cron)
+	}
 	message := fmt.Sprintf(
-		"<b>任务创建成功</b>\n\n"+
+		"<b>时间窗口任务创建成功</b>\n\n"+
 			"名称: %s\n"+
 			"ID: <code>%s</code>\n"+
-			"Cron: <code>%s</code>\n"+
+			"窗口: 每天 %s-%s（不含结束时间）\n"+
+			"间隔: 每%d分钟\n"+
+			"调度: %s\n"+
 			"路径: %s\n"+
 			"时间范围: 最近%d小时\n"+
 			"只下载视频: %v\n\n"+
 			"使用 <code>/runtask %s</code> 立即运行",
-		tc.messageUtils.EscapeHTML(name), task.ID[:8], cron, path, hoursAgo, videoOnly, task.ID[:8],
+		tc.messageUtils.EscapeHTML(newTask.Name), newTask.ID[:8], window.Start, window.End, window.IntervalMinutes,
+		schedule, tc.messageUtils.EscapeHTML(path), hoursAgo, videoOnly, newTask.ID[:8],
 	)
+	tc.messageUtils.SendMessageHTML(chatID, message)
+}
+
+// sendAddWindowHelp sends the /addwindow usage
+func (tc *TaskCommands) sendAddWindowHelp(chatID int64) {
+	message := "<b>添加时间窗口任务</b>\n\n" +
+		"在每天的指定时段内按固定间隔运行，无需编写 cron 表达式\n\n" +
+		"<b>命令格式:</b>\n" +
+		"<code>/addwindow 名称 间隔分钟 开始-结束 [路径] [小时数] [是否只视频]</code>\n\n" +
+		"<b>示例:</b>\n" +
+		"• <code>/addwindow 夜间下载 30 01:00-06:00</code>\n" +
+		"  每天 01:00 到 06:00 之间每30分钟运行（最后一次 05:30）\n" +
+		"• <code>/addwindow 深夜同步 45 23:00-02:00 /movies 6 true</code>\n" +
+		"  跨越午夜的窗口，扫描 /movies 最近6小时的视频\n\n" +
+		"<b>说明:</b>\n" +
+		"• 结束时间不含在窗口内，早于开始时间表示跨越午夜\n" +
+		"• 间隔为 1-1440 分钟，且不能超过窗口长度\n" +
+		"• 小时数默认 24"
 
 	tc.messageUtils.SendMessageHTML(chatID, message)
 }
@@ -368,22 +454,185 @@ func (tc *TaskCommands) HandleRunTask(chatID int64, userID int64, command string
 	tc.messageUtils.SendMessage(chatID, fmt.Sprintf("任务 '%s' 已开始运行，请稍后查看结果", taskName))
 }
 
-// formatTaskTimeDescription formats task time description
-func (tc *TaskCommands) formatTaskTimeDescription(hoursAgo int) string {
-	switch hoursAgo {
-	case 24:
-		return "1天"
-	case 48:
-		return "2天"
-	case 72:
-		return "3天"
-	case 168:
-		return "7天"
-	case 720:
-		return "30天"
-	default:
-		return fmt.Sprintf("%d小时", hoursAgo)
+// HandleCron validates a cron expression and previews its next fire times
+func (tc *TaskCommands) HandleCron(chatID int64, command string) {
+	spec := strings.Trim(strings.TrimSpace(strings.TrimPrefix(command, "/cron")), "\"'")
+	if spec == "" {
+		tc.messageUtils.SendMessageHTML(chatID, "<b>校验 cron 表达式</b>\n\n"+
+			"<b>命令格式:</b>\n"+
+			"<code>/cron 表达式</code>\n\n"+
+			"<b>示例:</b>\n"+
+			"• <code>/cron 0 2 * * *</code> - 每天凌晨2点\n"+
+			"• <code>/cron */30 * * * *</code> - 每30分钟\n"+
+			"• <code>/cron @every 2h</code> - 每2小时\n"+
+			"• <code>/cron 30 0 2 * * *</code> - 每天2:00:30（6字段，首位为秒）\n"+
+			"• <code>/cron CRON_TZ=Asia/Shanghai 0 9 * * 1</code> - 指定时区")
+		return
+	}
+
+	times, err := task.PreviewCron(spec, time.Now().In(tc.config.Scheduler.Location()), cronPreviewCount)
+	if err != nil {
+		tc.messageUtils.SendMessageHTML(chatID, fmt.Sprintf(
+			"<b>❌ cron 表达式无效</b>\n\n表达式: <code>%s</code>\n错误: %s\n\n格式: 分 时 日 月 周（可选前置秒字段），例如 <code>0 2 * * *</code>",
+			tc.messageUtils.EscapeHTML(spec), tc.messageUtils.EscapeHTML(err.Error())))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<b>✅ cron 表达式有效</b>\n\n表达式: <code>%s</code>\n\n", tc.messageUtils.EscapeHTML(spec)))
+	if len(times) == 0 {
+		sb.WriteString("⚠️ 该表达式永远不会触发")
+	} else {
+		sb.WriteString(fmt.Sprintf("<b>接下来 %d 次执行时间:</b>\n", len(times)))
+		for i, t := range times {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, t.Format("2006-01-02 15:04:05 MST (Mon)")))
+		}
+	}
+
+	tc.messageUtils.SendMessageHTML(chatID, sb.String())
+}
+
+// HandleToday sends a digest of today's scheduled task runs for the user
+func (tc *TaskCommands) HandleToday(chatID int64, userID int64) {
+	if tc.schedulerService == nil {
+		tc.messageUtils.SendMessage(chatID, "定时任务服务未启用")
+		return
+	}
+
+	digest := tc.schedulerService.GetDailyDigest(time.Now(), userID)
+	formatter := tc.messageUtils.GetFormatter().(*utils.MessageFormatter)
+
+	lines := []string{
+		formatter.FormatTitle("📅", "今日定时任务汇总"),
+		"",
+		formatter.FormatField("日期", digest.Date.Format("2006-01-02 (MST)")),
+	}
+
+	if digest.TotalRuns == 0 {
+		lines = append(lines, "", "今天还没有定时任务运行")
+		tc.messageUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+		return
+	}
+
+	lines = append(lines,
+		formatter.FormatField("运行", fmt.Sprintf("%d 个任务，共 %d 次", len(digest.Tasks), digest.TotalRuns)),
+		formatter.FormatField("下载", fmt.Sprintf("%d 个文件，%s", digest.FilesDownloaded, strutil.FormatFileSize(digest.DownloadedSize))),
+	)
+	if digest.FailedRuns > 0 {
+		lines = append(lines, formatter.FormatField("失败", fmt.Sprintf("%d 次", digest.FailedRuns)))
+	}
+
+	for _, item := range digest.Tasks {
+		lines = append(lines, "", tc.formatDigestItem(item))
+	}
+
+	tc.messageUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+}
+
+// formatDigestItem formats one task's runs in the daily digest
+func (tc *TaskCommands) formatDigestItem(item contracts.TaskDigestItem) string {
+	icon := "✅"
+	switch {
+	case item.FailedRuns > 0:
+		icon = "❌"
+	case item.EmptyRuns == item.Runs:
+		icon = "💤"
+	case item.PreviewRuns > 0 && item.FilesDownloaded == 0:
+		icon = "👁"
+	}
+
+	lines := []string{fmt.Sprintf("%s <b>%s</b>（%d 次，最近 %s）",
+		icon, tc.messageUtils.EscapeHTML(item.TaskName), item.Runs, item.LastRunAt.In(tc.config.Scheduler.Location()).Format("15:04"))}
+
+	if item.EmptyRuns == item.Runs {
+		lines = append(lines, "   已触发，未找到新文件")
+	} else {
+		if item.FilesDownloaded > 0 {
+			lines = append(lines, fmt.Sprintf("   下载 %d 个文件，%s", item.FilesDownloaded, strutil.FormatFileSize(item.DownloadedSize)))
+		}
+		if item.EmptyRuns > 0 {
+			lines = append(lines, fmt.Sprintf("   %d 次未找到新文件", item.EmptyRuns))
+		}
+		if item.PreviewRuns > 0 {
+			lines = append(lines, fmt.Sprintf("   %d 次预览（未下载）", item.PreviewRuns))
+		}
+	}
+	if item.FailedRuns > 0 {
+		lines = append(lines, fmt.Sprintf("   %d 次运行失败", item.FailedRuns))
+	}
+	if item.FailedDownloads > 0 {
+		lines = append(lines, fmt.Sprintf("   %d 个文件创建下载失败", item.FailedDownloads))
+	}
+	if item.LastError != "" {
+		lines = append(lines, fmt.Sprintf("   错误: %s", tc.messageUtils.EscapeHTML(item.LastError)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// extractNotifyChat removes the optional notify=<chat ID> argument from the command parts.
+// Channel and group IDs are negative, e.g. notify=-1001234567890.
+func extractNotifyChat(parts []string) ([]string, int64, error) {
+	var notifyChatID int64
+	rest := make([]string, 0, len(parts))
+	for _, part := range parts {
+		value, found := strings.CutPrefix(part, "notify=")
+		if !found {
+			rest = append(rest, part)
+			continue
+		}
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id == 0 {
+			return nil, 0, fmt.Errorf("通知聊天ID无效: %s（应为数字ID，如 notify=-1001234567890）", value)
+		}
+		notifyChatID = id
+	}
+	return rest, notifyChatID, nil
+}
+
+// extractTimezone removes the optional tz=<IANA zone> argument (e.g. tz=Asia/Shanghai) from parts.
+// An absent token means the scheduler's timezone.
+func extractTimezone(parts []string) ([]string, string, error) {
+	var timezone string
+	rest := make([]string, 0, len(parts))
+	for _, part := range parts {
+		value, found := strings.CutPrefix(part, "tz=")
+		if !found {
+			rest = append(rest, part)
+			continue
+		}
+		if _, err := task.LoadTimezone(value); err != nil || value == "" {
+			return nil, "", fmt.Errorf("时区无效: %s（应为 IANA 时区名称，如 tz=Asia/Shanghai）", value)
+		}
+		timezone = value
+	}
+	return rest, timezone, nil
+}
+
+// extractSizeRange removes the min=<size> / max=<size> tokens (e.g. min=500M max=5G) from parts.
+// Absent tokens mean no limit.
+func extractSizeRange(parts []string) ([]string, int64, int64, error) {
+	var minSize, maxSize int64
+	rest := make([]string, 0, len(parts))
+	for _, part := range parts {
+		key, value, found := strings.Cut(part, "=")
+		if !found || (key != "min" && key != "max") {
+			rest = append(rest, part)
+			continue
+		}
+		size, err := strutil.ParseFileSize(value)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("文件大小无效: %s（如 min=500M、max=5G）", part)
+		}
+		if key == "min" {
+			minSize = size
+		} else {
+			maxSize = size
+		}
+	}
+	if maxSize > 0 && minSize > maxSize {
+		return nil, 0, 0, fmt.Errorf("最小文件大小 %s 大于最大文件大小 %s", strutil.FormatFileSize(minSize), strutil.FormatFileSize(maxSize))
 	}
+	return rest, minSize, maxSize, nil
 }
 
 // sendAddTaskHelp sends add task help message
@@ -395,13 +644,16 @@ func (tc *TaskCommands) sendAddTaskHelp(chatID int64) {
 
 	message := "<b>添加定时下载任务</b>\n\n" +
 		"<b>命令格式:</b>\n" +
-		"<code>/addtask 名称 cron表达式 [路径] 小时数 是否只视频</code>\n\n" +
+		"<code>/addtask 名称 cron表达式 [路径] 小时数 是否只视频 [min=大小] [max=大小] [notify=聊天ID] [tz=时区]</code>\n\n" +
 		"<b>参数说明:</b>\n" +
 		"• <b>名称</b>: 任务的自定义名称\n" +
-		"• <b>cron表达式</b>: 执行频率（需要引号）\n" +
+		"• <b>cron表达式</b>: 执行频率（需要引号，支持5字段或带秒的6字段，可先用 <code>/cron</code> 校验）\n" +
 		"• <b>路径</b>: 扫描路径（可选，默认: <code>" + defaultPath + "</code>）\n" +
 		"• <b>小时数</b>: 下载最近N小时内修改的文件\n" +
-		"• <b>是否只视频</b>: true(仅视频) 或 false(所有文件)\n\n" +
+		"• <b>是否只视频</b>: true(仅视频) 或 false(所有文件)\n" +
+		"• <b>min=大小 / max=大小</b>: 只下载该大小范围内的文件（可选，如 <code>min=500M max=5G</code>，单位 K/M/G/T）\n" +
+		"• <b>notify=聊天ID</b>: 运行结果发送到指定群组/频道（可选，默认发给创建者；机器人需能在该聊天发言）\n" +
+		"• <b>tz=时区</b>: 按该时区计算执行时间（可选，IANA 名称，如 <code>tz=Asia/Shanghai</code>，默认使用调度器时区）\n\n" +
 		"<b>详细示例:</b>\n\n" +
 		"1. <code>/addtask 昨日视频 \"0 2 * * *\" 24 true</code>\n" +
 		"  • 任务名: 昨日视频\n" +
@@ -428,7 +680,7 @@ func (tc *TaskCommands) sendAddTaskHelp(chatID int64) {
 		"• <code>168</code> = 最近7天\n" +
 		"• <code>720</code> = 最近30天\n\n" +
 		"<b>Cron表达式说明:</b>\n" +
-		"格式: <code>分 时 日 月 周</code>\n\n" +
+		"格式: <code>分 时 日 月 周</code> 或 <code>秒 分 时 日 月 周</code>\n\n" +
 		"<b>常用表达式:</b>\n" +
 		"• <code>*/10 * * * *</code> → 每10分钟\n" +
 		"• <code>*/30 * * * *</code> → 每30分钟\n" +
@@ -438,7 +690,8 @@ func (tc *TaskCommands) sendAddTaskHelp(chatID int64) {
 		"• <code>0 2 * * *</code> → 每天凌晨2:00\n" +
 		"• <code>30 18 * * *</code> → 每天18:30\n" +
 		"• <code>0 9 * * 1</code> → 每周一9:00\n" +
-		"• <code>0 0 1 * *</code> → 每月1号凌晨"
+		"• <code>0 0 1 * *</code> → 每月1号凌晨\n" +
+		"• <code>30 0 2 * * *</code> → 每天2:00:30（6字段，首位为秒）"
 
 	tc.messageUtils.SendMessageHTML(chatID, message)
 }
@@ -2,8 +2,12 @@ package telegram
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 )
 
@@ -13,19 +17,29 @@ type Common struct {
 
 	// Path cache related
 	pathMutex        sync.RWMutex
-	pathCache        map[string]string // token -> path
-	pathReverseCache map[string]string // path -> token
-	pathTokenCounter int
+	pathCache        map[string]pathCacheEntry // token -> path
+	pathReverseCache map[string]string         // root + path -> token
+	pathTokenCounter int64                     // starts at the process start time in milliseconds
+	pathTTL          time.Duration             // 0 means tokens never expire
+
+	// Per-chat heavy operation lock
+	busyMutex sync.Mutex
+	busyChats map[int64]string // chatID -> running operation
 }
 
 // NewCommon creates a new common utility instance
 func NewCommon(controller *TelegramController) *Common {
-	return &Common{
+	c := &Common{
 		controller:       controller,
-		pathCache:        make(map[string]string),
+		pathCache:        make(map[string]pathCacheEntry),
 		pathReverseCache: make(map[string]string),
-		pathTokenCounter: 1,
+		pathTokenCounter: time.Now().UnixMilli(),
+		busyChats:        make(map[int64]string),
+	}
+	if controller != nil && controller.config != nil {
+		c.pathTTL = time.Duration(controller.config.Telegram.CallbackTTLMinutes) * time.Minute
 	}
+	return c
 }
 
 // ================================
@@ -41,23 +55,59 @@ func (c *Common) FormatFileSize(size int64) string {
 // Path cache management
 // ================================
 
+// ExpiredButtonMessage is shown when a button refers to a path token that expired or was evicted
+const ExpiredButtonMessage = "此按钮已过期，请重新打开菜单"
+
+// pathCacheEntry is a cached path and the time its token was issued
+type pathCacheEntry struct {
+	root     string // label of the Alist root the path was browsed from, empty when not bound to a root
+	path     string
+	issuedAt time.Time
+}
+
+// pathCacheKey keys the reverse cache, so the same path browsed from different roots gets different tokens
+func pathCacheKey(root, path string) string {
+	if root == "" {
+		return path
+	}
+	return root + "\x00" + path
+}
+
+// pathTokenStatus is the result of looking up a path token
+type pathTokenStatus int
+
+const (
+	pathTokenValid pathTokenStatus = iota
+	// pathTokenExpired the token was issued (by this or a previous run) but expired, was evicted or lost on restart
+	pathTokenExpired
+	// pathTokenUnknown the token was never issued
+	pathTokenUnknown
+)
+
 // EncodeFilePath encodes file path for callback data (using cache to avoid 64-byte limit)
 func (c *Common) EncodeFilePath(path string) string {
+	return c.EncodeRootFilePath("", path)
+}
+
+// EncodeRootFilePath encodes a path browsed from the given Alist root (alist.roots label).
+// Tokens of different roots never resolve into each other, even for the same path.
+func (c *Common) EncodeRootFilePath(root, path string) string {
 	c.pathMutex.Lock()
 	defer c.pathMutex.Unlock()
 
-	// Check if path is already in cache
-	if token, exists := c.pathReverseCache[path]; exists {
+	// Reuse the token of a cached path unless it has expired
+	key := pathCacheKey(root, path)
+	if token, exists := c.pathReverseCache[key]; exists && !c.isPathEntryExpired(c.pathCache[token]) {
 		return token
 	}
 
 	// Create new short token for path
 	c.pathTokenCounter++
-	token := fmt.Sprintf("p%d", c.pathTokenCounter)
+	token := "p" + strconv.FormatInt(c.pathTokenCounter, 10)
 
 	// Store path and token in cache
-	c.pathCache[token] = path
-	c.pathReverseCache[path] = token
+	c.pathCache[token] = pathCacheEntry{root: root, path: path, issuedAt: time.Now()}
+	c.pathReverseCache[key] = token
 
 	// Clean up cache if it gets too large (keep cache size reasonable)
 	if len(c.pathCache) > 1000 {
@@ -67,35 +117,148 @@ func (c *Common) EncodeFilePath(path string) string {
 	return token
 }
 
-// DecodeFilePath decodes file path from token
+// DecodeFilePath decodes file path from token, unknown or expired tokens fall back to root
 func (c *Common) DecodeFilePath(encoded string) string {
+	_, path := c.DecodeRootFilePath(encoded)
+	return path
+}
+
+// DecodeRootFilePath decodes the Alist root label and path from token.
+// Unknown or expired tokens fall back to "/" without a root.
+func (c *Common) DecodeRootFilePath(encoded string) (string, string) {
+	entry, status := c.lookupFilePath(encoded)
+	switch status {
+	case pathTokenValid:
+		return entry.root, entry.path
+	case pathTokenExpired:
+		logger.Info("Path token expired", "token", encoded)
+	default:
+		logger.WarnSafe("Path token not found", "token", encoded)
+	}
+	return "", "/"
+}
+
+// lookupFilePath resolves a token and reports why it could not be resolved.
+// Token numbers only grow and start from the process start time, so a missing token
+// numbered at or below the counter was issued earlier and has since expired or been evicted.
+func (c *Common) lookupFilePath(token string) (pathCacheEntry, pathTokenStatus) {
 	c.pathMutex.RLock()
 	defer c.pathMutex.RUnlock()
 
-	if path, exists := c.pathCache[encoded]; exists {
-		return path
+	if entry, exists := c.pathCache[token]; exists {
+		if c.isPathEntryExpired(entry) {
+			return pathCacheEntry{}, pathTokenExpired
+		}
+		return entry, pathTokenValid
+	}
+	if number, ok := parsePathToken(token); ok && number <= c.pathTokenCounter {
+		return pathCacheEntry{}, pathTokenExpired
 	}
+	return pathCacheEntry{}, pathTokenUnknown
+}
 
-	logger.WarnSafe("Path token not found", "token", encoded)
-	return "/"
+// ExpiredPathToken returns the first expired path token in callback data ("action:token[:...]")
+func (c *Common) ExpiredPathToken(data string) (string, bool) {
+	_, payload, found := strings.Cut(data, ":")
+	if !found {
+		return "", false
+	}
+	for _, segment := range strings.Split(payload, ":") {
+		if _, ok := parsePathToken(segment); !ok {
+			continue
+		}
+		if _, status := c.lookupFilePath(segment); status == pathTokenExpired {
+			return segment, true
+		}
+	}
+	return "", false
+}
+
+// parsePathToken parses a token of the form "p<number>"
+func parsePathToken(token string) (int64, bool) {
+	digits, found := strings.CutPrefix(token, "p")
+	if !found || digits == "" {
+		return 0, false
+	}
+	number, err := strconv.ParseInt(digits, 10, 64)
+	return number, err == nil
+}
+
+// isPathEntryExpired reports whether a cached token is older than the configured TTL
+func (c *Common) isPathEntryExpired(entry pathCacheEntry) bool {
+	return c.pathTTL > 0 && time.Since(entry.issuedAt) > c.pathTTL
 }
 
-// cleanupPathCache cleans up path cache (keeps most recent 500 entries)
+// cleanupPathCache drops expired tokens, and clears the cache if it is still too large.
+// The token counter keeps growing so evicted tokens are never reused for other paths.
 func (c *Common) cleanupPathCache() {
-	// Simple cleanup strategy: clear all when limit exceeded
-	// In production, could use LRU or other advanced strategies
+	for token, entry := range c.pathCache {
+		if c.isPathEntryExpired(entry) {
+			delete(c.pathCache, token)
+			if key := pathCacheKey(entry.root, entry.path); c.pathReverseCache[key] == token {
+				delete(c.pathReverseCache, key)
+			}
+		}
+	}
 	if len(c.pathCache) <= 500 {
 		return
 	}
 
-	// Clear entire cache and restart counter (simple but effective)
-	c.pathCache = make(map[string]string)
+	c.pathCache = make(map[string]pathCacheEntry)
 	c.pathReverseCache = make(map[string]string)
-	c.pathTokenCounter = 1
 
 	logger.Info("Path cache cleared")
 }
 
+// ================================
+// Heavy operation lock
+// ================================
+
+// RunExclusive starts a heavy operation in the background unless another one is still running in the same chat.
+// Updates are dispatched one at a time, so fn runs on its own goroutine to keep the chat responsive
+// (and the busy reply reachable) while it works. The lock is released when fn returns, a panic is
+// logged and swallowed. Returns false if the chat was busy.
+func (c *Common) RunExclusive(chatID int64, operation string, fn func()) bool {
+	if running, ok := c.acquireChat(chatID, operation); !ok {
+		logger.Info("Heavy operation rejected, chat busy", "chatID", chatID, "operation", operation, "running", running)
+		if c.controller != nil && c.controller.messageUtils != nil {
+			c.controller.messageUtils.SendMessageWithAutoDelete(chatID,
+				fmt.Sprintf("⏳ 上一个操作仍在进行中（%s），请稍后再试", running), types.MessageTransient)
+		}
+		return false
+	}
+
+	go func() {
+		defer c.releaseChat(chatID)
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("Heavy operation panicked", "chatID", chatID, "operation", operation, "panic", r)
+			}
+		}()
+		fn()
+	}()
+	return true
+}
+
+// acquireChat marks the chat busy, returns the running operation if already busy
+func (c *Common) acquireChat(chatID int64, operation string) (string, bool) {
+	c.busyMutex.Lock()
+	defer c.busyMutex.Unlock()
+
+	if running, busy := c.busyChats[chatID]; busy {
+		return running, false
+	}
+	c.busyChats[chatID] = operation
+	return "", true
+}
+
+// releaseChat clears the busy flag of the chat
+func (c *Common) releaseChat(chatID int64) {
+	c.busyMutex.Lock()
+	delete(c.busyChats, chatID)
+	c.busyMutex.Unlock()
+}
+
 // ================================
 // Path utility functions
 // ================================
@@ -0,0 +1,127 @@
+package telegram
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestRunExclusive_ReleasesLock 测试重操作结束（包括 panic）后释放聊天锁，运行期间拒绝同一聊天的其他重操作
+func TestRunExclusive_ReleasesLock(t *testing.T) {
+	c := NewCommon(nil)
+
+	if _, ok := c.acquireChat(1, "/download"); !ok {
+		t.Fatal("acquireChat() on idle chat = false, want true")
+	}
+	if running, ok := c.acquireChat(1, "/eta"); ok || running != "/download" {
+		t.Errorf("acquireChat() on busy chat = %q, %v, want \"/download\", false", running, ok)
+	}
+	if _, ok := c.acquireChat(2, "/eta"); !ok {
+		t.Error("acquireChat() on another chat = false, want true")
+	}
+	c.releaseChat(1)
+	c.releaseChat(2)
+
+	release := make(chan struct{})
+	if !c.RunExclusive(1, "批量重命名", func() {
+		<-release
+		panic("boom")
+	}) {
+		t.Fatal("RunExclusive() on idle chat = false, want true")
+	}
+	if c.RunExclusive(1, "/download", func() { t.Error("operation ran while chat was busy") }) {
+		t.Error("RunExclusive() on busy chat = true, want false")
+	}
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, ok := c.acquireChat(1, "/download"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("chat still busy after panicking operation")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestExpiredPathToken 测试过期或被清理的路径令牌返回"按钮已过期"，未签发过的令牌不算过期
+func TestExpiredPathToken(t *testing.T) {
+	c := NewCommon(nil)
+	c.pathTTL = time.Minute
+
+	valid := c.EncodeFilePath("/movies")
+	stale := c.EncodeFilePath("/tvs")
+	evicted := c.EncodeFilePath("/music")
+
+	c.pathMutex.Lock()
+	entry := c.pathCache[stale]
+	entry.issuedAt = time.Now().Add(-2 * time.Minute)
+	c.pathCache[stale] = entry
+	delete(c.pathCache, evicted)
+	c.pathMutex.Unlock()
+
+	tests := []struct {
+		name        string
+		data        string
+		wantToken   string
+		wantExpired bool
+	}{
+		{"valid token", "browse_dir:" + valid + ":1", "", false},
+		{"token older than ttl", "browse_dir:" + stale + ":1", stale, true},
+		{"evicted token", "file_menu:" + evicted, evicted, true},
+		{"token from before restart", "file_menu:p1", "p1", true},
+		{"never issued token", "file_menu:p" + strconv.FormatInt(c.pathTokenCounter+100, 10), "", false},
+		{"no path token", "download_list", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, expired := c.ExpiredPathToken(tt.data)
+			if token != tt.wantToken || expired != tt.wantExpired {
+				t.Errorf("ExpiredPathToken(%q) = %q, %v, want %q, %v", tt.data, token, expired, tt.wantToken, tt.wantExpired)
+			}
+		})
+	}
+
+	if got := c.DecodeFilePath(stale); got != "/" {
+		t.Errorf("DecodeFilePath(expired) = %q, want /", got)
+	}
+	if got := c.EncodeFilePath("/tvs"); got == stale {
+		t.Errorf("EncodeFilePath() reused expired token %q", got)
+	}
+}
+
+// TestRootPathTokens 测试不同根目录下的同一路径使用不同令牌，解码时不会串到其他根目录
+func TestRootPathTokens(t *testing.T) {
+	c := NewCommon(nil)
+
+	movies := c.EncodeRootFilePath("电影", "/media/shared")
+	tvs := c.EncodeRootFilePath("剧集", "/media/shared")
+	plain := c.EncodeFilePath("/media/shared")
+
+	if movies == tvs || movies == plain || tvs == plain {
+		t.Fatalf("tokens of different roots must differ: %q, %q, %q", movies, tvs, plain)
+	}
+	if again := c.EncodeRootFilePath("电影", "/media/shared"); again != movies {
+		t.Errorf("EncodeRootFilePath() = %q, want reused token %q", again, movies)
+	}
+
+	tests := []struct {
+		token    string
+		wantRoot string
+	}{
+		{movies, "电影"},
+		{tvs, "剧集"},
+		{plain, ""},
+	}
+	for _, tt := range tests {
+		root, path := c.DecodeRootFilePath(tt.token)
+		if root != tt.wantRoot || path != "/media/shared" {
+			t.Errorf("DecodeRootFilePath(%q) = %q, %q, want %q, /media/shared", tt.token, root, path, tt.wantRoot)
+		}
+	}
+	if got := c.DecodeFilePath(movies); got != "/media/shared" {
+		t.Errorf("DecodeFilePath(root token) = %q, want /media/shared", got)
+	}
+}
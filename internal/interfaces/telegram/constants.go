@@ -4,9 +4,8 @@ import "github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/ty
 
 // Re-export constants from types package for backward compatibility
 const (
-	MaxDisplayItems          = types.MaxDisplayItems
-	MaxSuggestions           = types.MaxSuggestions
-	HighConfidence           = types.HighConfidence
-	MediumConfidence         = types.MediumConfidence
-	MessageAutoDeleteSeconds = types.MessageAutoDeleteSeconds
+	MaxDisplayItems  = types.MaxDisplayItems
+	MaxSuggestions   = types.MaxSuggestions
+	HighConfidence   = types.HighConfidence
+	MediumConfidence = types.MediumConfidence
 )
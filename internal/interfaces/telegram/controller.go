@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"context"
+	"crypto/subtle"
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
@@ -40,6 +41,9 @@ type TelegramController struct {
 	container           *services.ServiceContainer
 	config              *config.Config
 
+	// Custom reply keyboard buttons (validated telegram.shortcuts)
+	shortcuts []config.ShortcutConfig
+
 	// State management - compatible with legacy version
 	lastUpdateID int
 	ctx          context.Context
@@ -53,7 +57,7 @@ type TelegramController struct {
 	menuCallbacks    *callbacks.MenuCallbacks
 
 	// Specialized function handlers
-	messageHandler  *MessageHandler
+	messageHandler  messageUpdateHandler
 	callbackHandler *CallbackHandler
 	downloadHandler *DownloadHandler
 	fileHandler     *FileHandler
@@ -85,7 +89,9 @@ func NewTelegramController(cfg ControllerConfig) *TelegramController {
 // initializeModules initializes all modular components with proper dependencies
 func (c *TelegramController) initializeModules() {
 	// Create message utilities for formatting and sending
-	c.messageUtils = utils.NewMessageUtils(c.telegramClient)
+	c.messageUtils = utils.NewMessageUtils(c.telegramClient, c.config.Telegram.SendRate, c.config.Telegram.AutoDelete)
+	c.applyShortcuts()
+	c.registerBotCommands()
 
 	// Get contract interfaces from service container to implement API First architecture
 	c.fileService = c.container.GetFileService()
@@ -94,7 +100,11 @@ func (c *TelegramController) initializeModules() {
 	// Initialize command modules with contract interfaces
 	c.basicCommands = commands.NewBasicCommands(c.downloadService, c.fileService, c.config, c.messageUtils)
 	c.downloadCommands = commands.NewDownloadCommands(c.container, c.messageUtils)
-	c.taskCommands = commands.NewTaskCommands(c.schedulerService, c.config, c.messageUtils)
+	c.taskCommands = commands.NewTaskCommands(c.schedulerService, c.config, c.messageUtils, c.telegramClient)
+	if c.schedulerService != nil {
+		// Scheduled task run summaries use the same layout as directory downloads
+		c.schedulerService.SetRunSummaryFormatter(c.messageUtils.FormatTaskRunSummary)
+	}
 
 	c.menuCallbacks = callbacks.NewMenuCallbacks(c.downloadService, c.config, c.messageUtils, c.basicCommands)
 
@@ -112,6 +122,9 @@ func (c *TelegramController) initializeModules() {
 // Public interface implementation - maintains full compatibility
 // ================================
 
+// webhookSecretHeader carries the secret token Telegram echoes back on every webhook request
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
 // Webhook handles webhook requests (fully compatible with legacy version)
 func (c *TelegramController) Webhook(ctx *gin.Context) {
 	if !c.config.Telegram.Enabled {
@@ -119,6 +132,14 @@ func (c *TelegramController) Webhook(ctx *gin.Context) {
 		return
 	}
 
+	// Only requests carrying the secret token registered with setWebhook come from Telegram
+	if secret := c.config.Telegram.Webhook.SecretToken; secret != "" &&
+		subtle.ConstantTimeCompare([]byte(ctx.GetHeader(webhookSecretHeader)), []byte(secret)) != 1 {
+		logger.Warn("Rejected telegram webhook request with invalid secret token", "clientIP", ctx.ClientIP())
+		ctx.JSON(403, gin.H{"error": "Invalid secret token"})
+		return
+	}
+
 	var update tgbotapi.Update
 	if err := ctx.ShouldBindJSON(&update); err != nil {
 		logger.Error("Failed to parse telegram update", "error", err)
@@ -126,11 +147,7 @@ func (c *TelegramController) Webhook(ctx *gin.Context) {
 		return
 	}
 
-	if update.Message != nil {
-		c.messageHandler.HandleMessage(&update)
-	} else if update.CallbackQuery != nil {
-		c.callbackHandler.HandleCallbackQuery(&update)
-	}
+	c.dispatchUpdate(&update)
 
 	ctx.JSON(200, gin.H{"ok": true})
 }
@@ -158,16 +175,19 @@ func (c *TelegramController) StartPolling() {
 	}()
 }
 
-// StopPolling stops update polling (fully compatible with legacy version)
+// StopPolling stops update polling and live progress tracking (fully compatible with legacy version)
 func (c *TelegramController) StopPolling() {
 	if c.cancel != nil {
 		c.cancel()
 	}
+	c.statusHandler.StopAllProgressTracking()
 }
 
 // pollUpdates polls for new updates from Telegram
 func (c *TelegramController) pollUpdates() {
-	updates, err := c.telegramClient.GetUpdates(int64(c.lastUpdateID+1), 30)
+	// 每次轮询读取配置，退避等待结束后同样使用配置的参数
+	polling := c.config.Telegram.Polling
+	updates, err := c.telegramClient.GetUpdates(int64(c.lastUpdateID+1), polling.Timeout, polling.Limit)
 	if err != nil {
 		logger.Error("Failed to get telegram updates", "error", err)
 		time.Sleep(5 * time.Second)
@@ -179,11 +199,7 @@ func (c *TelegramController) pollUpdates() {
 			c.lastUpdateID = update.UpdateID
 		}
 
-		if update.Message != nil {
-			c.messageHandler.HandleMessage(&update)
-		} else if update.CallbackQuery != nil {
-			c.callbackHandler.HandleCallbackQuery(&update)
-		}
+		c.dispatchUpdate(&update)
 	}
 }
 
@@ -50,8 +50,8 @@ func (h *DownloadHandler) HandleQuickPreview(chatID int64, timeArgs []string) {
 	h.handler.HandleQuickPreview(chatID, timeArgs)
 }
 
-func (h *DownloadHandler) HandleManualConfirm(chatID int64, token string, messageID int) {
-	h.handler.HandleManualConfirm(chatID, token, messageID)
+func (h *DownloadHandler) HandleManualConfirm(chatID, userID int64, token string, messageID int) {
+	h.handler.HandleManualConfirm(chatID, userID, token, messageID)
 }
 
 func (h *DownloadHandler) HandleManualCancel(chatID int64, token string, messageID int) {
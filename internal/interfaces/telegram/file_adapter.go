@@ -3,6 +3,7 @@ package telegram
 import (
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
 	filehandler "github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/handlers/file"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 )
@@ -36,6 +37,10 @@ func (h *FileHandler) GetFileService() contracts.FileService {
 	return h.controller.fileService
 }
 
+func (h *FileHandler) GetDownloadService() contracts.DownloadService {
+	return h.controller.downloadService
+}
+
 func (h *FileHandler) GetConfig() *config.Config {
 	return h.controller.config
 }
@@ -48,6 +53,30 @@ func (h *FileHandler) DecodeFilePath(encoded string) string {
 	return h.controller.common.DecodeFilePath(encoded)
 }
 
+func (h *FileHandler) EncodeRootFilePath(root, path string) string {
+	return h.controller.common.EncodeRootFilePath(root, path)
+}
+
+func (h *FileHandler) DecodeRootFilePath(encoded string) (string, string) {
+	return h.controller.common.DecodeRootFilePath(encoded)
+}
+
+func (h *FileHandler) GetPinRepository() *repository.PinRepository {
+	return h.controller.container.GetPinRepository()
+}
+
+func (h *FileHandler) GetPresetRepository() *repository.PresetRepository {
+	return h.controller.container.GetPresetRepository()
+}
+
+func (h *FileHandler) GetDownloadHistoryRepository() *repository.DownloadHistoryRepository {
+	return h.controller.container.GetDownloadHistoryRepository()
+}
+
+func (h *FileHandler) GetCategoryOverrideRepository() *repository.CategoryOverrideRepository {
+	return h.controller.container.GetCategoryOverrideRepository()
+}
+
 func (h *FileHandler) HandleRenameCommand(chatID int64, command string) {
 	h.controller.basicCommands.HandleRename(chatID, command)
 }
@@ -64,6 +93,18 @@ func (h *FileHandler) HandleBrowseFilesWithEdit(chatID int64, path string, page
 	h.handler.HandleBrowseFilesWithEdit(chatID, path, page, messageID)
 }
 
+func (h *FileHandler) HandleBrowseFilesFiltered(chatID int64, root string, path string, page int, messageID int, days int) {
+	h.handler.HandleBrowseFilesFiltered(chatID, root, path, page, messageID, days)
+}
+
+func (h *FileHandler) ToggleHiddenFiles(chatID int64) bool {
+	return h.handler.ToggleHiddenFiles(chatID)
+}
+
+func (h *FileHandler) ToggleGroupBrowse(chatID int64) bool {
+	return h.handler.ToggleGroupBrowse(chatID)
+}
+
 func (h *FileHandler) HandleFilesBrowseWithEdit(chatID int64, messageID int) {
 	h.handler.HandleFilesBrowseWithEdit(chatID, messageID)
 }
@@ -80,72 +121,156 @@ func (h *FileHandler) HandleFileMenu(chatID int64, filePath string) {
 	h.handler.HandleFileMenu(chatID, filePath)
 }
 
-func (h *FileHandler) HandleFileMenuWithEdit(chatID int64, filePath string, messageID int) {
-	h.handler.HandleFileMenuWithEdit(chatID, filePath, messageID)
+func (h *FileHandler) HandleFileMenuWithEdit(chatID int64, root, filePath string, messageID int) {
+	h.handler.HandleFileMenuWithEdit(chatID, root, filePath, messageID)
 }
 
 func (h *FileHandler) HandleDirMenu(chatID int64, dirPath string) {
 	h.handler.HandleDirMenu(chatID, dirPath)
 }
 
-func (h *FileHandler) HandleDirMenuWithEdit(chatID int64, dirPath string, messageID int) {
-	h.handler.HandleDirMenuWithEdit(chatID, dirPath, messageID)
+func (h *FileHandler) HandleDirMenuWithEdit(chatID int64, root, dirPath string, messageID int) {
+	h.handler.HandleDirMenuWithEdit(chatID, root, dirPath, messageID)
 }
 
 func (h *FileHandler) HandleFileInfo(chatID int64, filePath string) {
 	h.handler.HandleFileInfo(chatID, filePath)
 }
 
-func (h *FileHandler) HandleFileInfoWithEdit(chatID int64, filePath string, messageID int) {
-	h.handler.HandleFileInfoWithEdit(chatID, filePath, messageID)
+func (h *FileHandler) HandleFileInfoWithEdit(chatID int64, root, filePath string, messageID int) {
+	h.handler.HandleFileInfoWithEdit(chatID, root, filePath, messageID)
 }
 
 func (h *FileHandler) HandleFileLink(chatID int64, filePath string) {
 	h.handler.HandleFileLink(chatID, filePath)
 }
 
-func (h *FileHandler) HandleFileLinkWithEdit(chatID int64, filePath string, messageID int) {
-	h.handler.HandleFileLinkWithEdit(chatID, filePath, messageID)
+func (h *FileHandler) HandleFileLinkWithEdit(chatID int64, root, filePath string, messageID int) {
+	h.handler.HandleFileLinkWithEdit(chatID, root, filePath, messageID)
+}
+
+func (h *FileHandler) HandleFileQRCode(chatID int64, filePath string) {
+	h.handler.HandleFileQRCode(chatID, filePath)
+}
+
+func (h *FileHandler) HandleDirectoryLinks(chatID int64, dirPath string) {
+	h.handler.HandleDirectoryLinks(chatID, dirPath)
+}
+
+func (h *FileHandler) HandleFileSampleWithEdit(chatID int64, filePath string, messageID int) {
+	h.handler.HandleFileSampleWithEdit(chatID, filePath, messageID)
+}
+
+func (h *FileHandler) HandleReclassifyMenu(chatID int64, filePath string, messageID int) {
+	h.handler.HandleReclassifyMenu(chatID, filePath, messageID)
+}
+
+func (h *FileHandler) HandleReclassify(chatID, userID int64, filePath, category string, messageID int) {
+	h.handler.HandleReclassify(chatID, userID, filePath, category, messageID)
+}
+
+func (h *FileHandler) HandleCategoryOverrides(chatID int64, args string) {
+	h.handler.HandleCategoryOverrides(chatID, args)
 }
 
 // ================================
 // 代理方法 - 文件删除
 // ================================
 
-func (h *FileHandler) HandleFileDeleteConfirm(chatID int64, filePath string, messageID int) {
-	h.handler.HandleFileDeleteConfirm(chatID, filePath, messageID)
+func (h *FileHandler) HandleFileDeleteConfirm(chatID int64, root, filePath string, messageID int) {
+	h.handler.HandleFileDeleteConfirm(chatID, root, filePath, messageID)
 }
 
-func (h *FileHandler) HandleFileDelete(chatID int64, filePath string, messageID int) {
-	h.handler.HandleFileDelete(chatID, filePath, messageID)
+func (h *FileHandler) HandleFileDelete(chatID int64, root, filePath string, messageID int) {
+	h.handler.HandleFileDelete(chatID, root, filePath, messageID)
 }
 
-func (h *FileHandler) HandleDirDeleteConfirm(chatID int64, dirPath string, messageID int) {
-	h.handler.HandleDirDeleteConfirm(chatID, dirPath, messageID)
+func (h *FileHandler) HandleDirDeleteConfirm(chatID int64, root, dirPath string, messageID int) {
+	h.handler.HandleDirDeleteConfirm(chatID, root, dirPath, messageID)
 }
 
-func (h *FileHandler) HandleDirDelete(chatID int64, dirPath string, messageID int) {
-	h.handler.HandleDirDelete(chatID, dirPath, messageID)
+func (h *FileHandler) HandleDirDelete(chatID int64, root, dirPath string, messageID int) {
+	h.handler.HandleDirDelete(chatID, root, dirPath, messageID)
 }
 
 // ================================
 // 代理方法 - 文件下载
 // ================================
 
-func (h *FileHandler) HandleFileDownload(chatID int64, filePath string) {
-	h.handler.HandleFileDownload(chatID, filePath)
+func (h *FileHandler) HandleFileDownload(chatID, userID int64, root, filePath string) {
+	h.handler.HandleFileDownload(chatID, userID, root, filePath)
 }
 
-func (h *FileHandler) HandleDownloadDirectory(chatID int64, dirPath string) {
-	h.handler.HandleDownloadDirectory(chatID, dirPath)
+func (h *FileHandler) HandleFileDownloadWithSubtitles(chatID, userID int64, root, filePath string) {
+	h.handler.HandleFileDownloadWithSubtitles(chatID, userID, root, filePath)
+}
+
+func (h *FileHandler) HandleDownloadDirectory(chatID, userID int64, dirPath string) {
+	h.handler.HandleDownloadDirectory(chatID, userID, dirPath)
 }
 
 func (h *FileHandler) HandleDownloadDirectoryConfirm(chatID int64, dirPath string, messageID int) {
 	h.handler.HandleDownloadDirectoryConfirm(chatID, dirPath, messageID)
 }
 
-func (h *FileHandler) HandleDownloadDirectoryExecute(chatID int64, dirPath string, messageID int) {
-	h.handler.HandleDownloadDirectoryExecute(chatID, dirPath, messageID)
+func (h *FileHandler) HandleDownloadDirectoryExecute(chatID, userID int64, token string, messageID int) {
+	h.handler.HandleDownloadDirectoryExecute(chatID, userID, token, messageID)
+}
+
+func (h *FileHandler) HandleDownloadDirectoryCancel(chatID int64, token string, messageID int) {
+	h.handler.HandleDownloadDirectoryCancel(chatID, token, messageID)
+}
+
+func (h *FileHandler) HandleDownloadDirectoryResumeExecute(chatID, userID int64, dirPath string, messageID int) {
+	h.handler.HandleDownloadDirectoryResumeExecute(chatID, userID, dirPath, messageID)
+}
+
+func (h *FileHandler) HandleDownloadDirectoryZipConfirm(chatID int64, dirPath string, messageID int) {
+	h.handler.HandleDownloadDirectoryZipConfirm(chatID, dirPath, messageID)
+}
+
+func (h *FileHandler) HandleDownloadDirectoryZipExecute(chatID, userID int64, dirPath string, messageID int) {
+	h.handler.HandleDownloadDirectoryZipExecute(chatID, userID, dirPath, messageID)
+}
+
+func (h *FileHandler) HandleDownloadFilteredConfirm(chatID int64, root, dirPath string, days int, messageID int) {
+	h.handler.HandleDownloadFilteredConfirm(chatID, root, dirPath, days, messageID)
+}
+
+func (h *FileHandler) HandleDownloadFilteredExecute(chatID, userID int64, dirPath string, days int, messageID int) {
+	h.handler.HandleDownloadFilteredExecute(chatID, userID, dirPath, days, messageID)
+}
+
+func (h *FileHandler) HandleFileDownloadAndDelete(chatID, userID int64, root, filePath string) {
+	h.handler.HandleFileDownloadAndDelete(chatID, userID, root, filePath)
+}
+
+func (h *FileHandler) HandleSaveAsPrompt(chatID int64, filePath string) {
+	h.handler.HandleSaveAsPrompt(chatID, filePath)
+}
+
+func (h *FileHandler) HandleSaveAsCommand(chatID int64, args string) {
+	h.handler.HandleSaveAsCommand(chatID, args)
+}
+
+func (h *FileHandler) HandleSearchPrompt(chatID int64, root, dirPath string) {
+	h.handler.HandleSearchPrompt(chatID, root, dirPath)
+}
+
+func (h *FileHandler) HandleSearch(chatID int64, args string) {
+	h.handler.HandleSearch(chatID, args)
+}
+
+func (h *FileHandler) HandleSearchPage(chatID int64, page int, messageID int) {
+	h.handler.HandleSearchPage(chatID, page, messageID)
+}
+
+func (h *FileHandler) HandleSaveAsDownload(chatID, userID int64, root, filePath, fileName string, messageID int) {
+	h.handler.HandleSaveAsDownload(chatID, userID, root, filePath, fileName, messageID)
+}
+
+func (h *FileHandler) HandleDownloadDirectoryAndDeleteExecute(chatID, userID int64, dirPath string, messageID int) {
+	h.handler.HandleDownloadDirectoryAndDeleteExecute(chatID, userID, dirPath, messageID)
 }
 
 // ================================
@@ -176,6 +301,118 @@ func (h *FileHandler) HandleBatchRenameConfirm(chatID int64, dirPath string, mes
 	h.handler.HandleBatchRenameConfirm(chatID, dirPath, messageID)
 }
 
+// ================================
+// 代理方法 - 删除命令
+// ================================
+
+func (h *FileHandler) HandleDeleteCommand(chatID int64, args string) {
+	h.handler.HandleDeleteCommand(chatID, args)
+}
+
+// ================================
+// 代理方法 - 下载耗时估算
+// ================================
+
+func (h *FileHandler) HandleETA(chatID int64, dirPath string) {
+	h.handler.HandleETA(chatID, dirPath)
+}
+
+// ================================
+// 代理方法 - 目录媒体清单
+// ================================
+
+func (h *FileHandler) HandleInventory(chatID int64, dirPath string) {
+	h.handler.HandleInventory(chatID, dirPath)
+}
+
+// ================================
+// 代理方法 - 下载预设
+// ================================
+
+func (h *FileHandler) HandleSavePreset(chatID, userID int64, args string) {
+	h.handler.HandleSavePreset(chatID, userID, args)
+}
+
+func (h *FileHandler) HandlePreset(chatID, userID int64, name string) {
+	h.handler.HandlePreset(chatID, userID, name)
+}
+
+func (h *FileHandler) HandlePresetsWithEdit(chatID, userID int64, messageID int) {
+	h.handler.HandlePresetsWithEdit(chatID, userID, messageID)
+}
+
+func (h *FileHandler) HandlePresetConfirm(chatID, userID int64, name string, messageID int) {
+	h.handler.HandlePresetConfirm(chatID, userID, name, messageID)
+}
+
+func (h *FileHandler) HandlePresetExecute(chatID, userID int64, name string, messageID int) {
+	h.handler.HandlePresetExecute(chatID, userID, name, messageID)
+}
+
+func (h *FileHandler) HandlePresetDelete(chatID, userID int64, name string, messageID int) {
+	h.handler.HandlePresetDelete(chatID, userID, name, messageID)
+}
+
+// ================================
+// 代理方法 - 整理下载目录
+// ================================
+
+func (h *FileHandler) HandleReorganize(chatID int64) {
+	h.handler.HandleReorganize(chatID)
+}
+
+func (h *FileHandler) HandleReorganizeExecute(chatID int64, messageID int) {
+	h.handler.HandleReorganizeExecute(chatID, messageID)
+}
+
+// ================================
+// 代理方法 - 分类规则测试
+// ================================
+
+func (h *FileHandler) HandleTestRule(chatID int64, args string) {
+	h.handler.HandleTestRule(chatID, args)
+}
+
+// ================================
+// 代理方法 - 目录快照对比
+// ================================
+
+func (h *FileHandler) HandleSnapshot(chatID, userID int64, dirPath string) {
+	h.handler.HandleSnapshot(chatID, userID, dirPath)
+}
+
+func (h *FileHandler) HandleDiff(chatID int64, dirPath string) {
+	h.handler.HandleDiff(chatID, dirPath)
+}
+
+func (h *FileHandler) HandleDiffDownload(chatID, userID int64, dirPath string) {
+	h.handler.HandleDiffDownload(chatID, userID, dirPath)
+}
+
+// ================================
+// 代理方法 - 目录收藏
+// ================================
+
+func (h *FileHandler) PinDirectory(userID int64, root, dirPath string) (bool, error) {
+	return h.handler.PinDirectory(userID, root, dirPath)
+}
+
+func (h *FileHandler) HandlePin(chatID, userID int64, dirPath string) {
+	h.handler.HandlePin(chatID, userID, dirPath)
+}
+
+func (h *FileHandler) HandleUnpin(chatID, userID int64, dirPath string) {
+	h.handler.HandleUnpin(chatID, userID, dirPath)
+}
+
+func (h *FileHandler) HandleUnpinWithEdit(chatID, userID int64, dirPath string, messageID int) {
+	h.handler.HandleUnpinWithEdit(chatID, userID, dirPath, messageID)
+}
+
+func (h *FileHandler) HandleFavoritesWithEdit(chatID, userID int64, messageID int) {
+	h.handler.HandleFavoritesWithEdit(chatID, userID, messageID)
+}
+
 // ================================
 // 兼容类型定义（保留）
 // ================================
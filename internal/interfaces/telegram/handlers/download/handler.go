@@ -11,8 +11,10 @@ import (
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
 	timeutil "github.com/easayliu/alist-aria2-download/pkg/utils/time"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -49,6 +51,7 @@ type Handler struct {
 	// Manual download context management
 	manualMutex    sync.Mutex
 	manualContexts map[string]*ManualDownloadContext
+	activeTokens   map[int64]string // chatID -> latest preview token
 }
 
 // NewHandler creates a new download handler
@@ -56,6 +59,7 @@ func NewHandler(deps DownloadDeps) *Handler {
 	return &Handler{
 		deps:           deps,
 		manualContexts: make(map[string]*ManualDownloadContext),
+		activeTokens:   make(map[int64]string),
 	}
 }
 
@@ -77,6 +81,15 @@ func (h *Handler) parseTimeArguments(args []string) (*TimeParseResult, error) {
 	if len(args) == 1 {
 		arg := args[0]
 
+		if isYesterdayArg(arg) {
+			timeRange := timeutil.CreateYesterdayRange()
+			return &TimeParseResult{
+				StartTime:   timeRange.Start,
+				EndTime:     timeRange.End,
+				Description: "昨天（" + timeRange.Start.Format("2006-01-02") + "）",
+			}, nil
+		}
+
 		if strings.HasSuffix(strings.ToLower(arg), "m") {
 			minuteStr := strings.TrimSuffix(strings.ToLower(arg), "m")
 			if minutes, err := strconv.Atoi(minuteStr); err == nil {
@@ -110,7 +123,7 @@ func (h *Handler) parseTimeArguments(args []string) (*TimeParseResult, error) {
 			}, nil
 		}
 
-		return nil, fmt.Errorf("无效的时间格式，应为小时数（如：48）或分钟数（如：30m）")
+		return nil, fmt.Errorf("无效的时间格式，应为小时数（如：48）、分钟数（如：30m）或 yesterday")
 	}
 
 	if len(args) == 2 {
@@ -141,6 +154,9 @@ func (h *Handler) parseTimeArguments(args []string) (*TimeParseResult, error) {
 func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview bool) {
 	msgUtils := h.deps.GetMessageUtils()
 
+	timeArgs, includeAll := utils.ExtractAllFilesFlag(timeArgs)
+	videoOnly := h.deps.GetConfig().Alist.DefaultVideoOnly && !includeAll
+
 	timeResult, err := h.parseTimeArguments(timeArgs)
 	if err != nil {
 		formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
@@ -161,7 +177,7 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 		Path:      path,
 		StartTime: timeResult.StartTime,
 		EndTime:   timeResult.EndTime,
-		VideoOnly: true,
+		VideoOnly: videoOnly,
 	}
 
 	ctx := context.Background()
@@ -185,7 +201,7 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 		message := formatter.FormatTitle(title, "") + "\n\n" +
 			formatter.FormatField("时间范围", timeResult.Description) + "\n" +
 			formatter.FormatField("结果", "未找到符合条件的文件")
-		msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+		msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageTransient)
 		return
 	}
 
@@ -194,13 +210,17 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 	totalSizeStr := summary.TotalSizeFormatted
 
 	mediaStats := struct {
-		TV    int
-		Movie int
-		Other int
+		TV       int
+		Movie    int
+		Other    int
+		Music    int
+		Document int
 	}{
-		TV:    summary.TVFiles,
-		Movie: summary.MovieFiles,
-		Other: summary.OtherFiles,
+		TV:       summary.TVFiles,
+		Movie:    summary.MovieFiles,
+		Other:    summary.OtherFiles,
+		Music:    summary.MusicFiles,
+		Document: summary.DocumentFiles,
 	}
 
 	if preview {
@@ -208,6 +228,9 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 		if len(timeArgs) > 0 {
 			confirmCommand += " " + strings.Join(timeArgs, " ")
 		}
+		if !videoOnly {
+			confirmCommand += " " + utils.AllFilesFlag
+		}
 
 		var exampleFiles []utils.ExampleFileData
 		maxExamples := 5
@@ -216,13 +239,8 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 		}
 		for i := 0; i < maxExamples; i++ {
 			file := files[i]
-			filename := file.Name
-			runes := []rune(filename)
-			if len(runes) > 60 {
-				filename = string(runes[:60]) + "..."
-			}
 			exampleFiles = append(exampleFiles, utils.ExampleFileData{
-				Name:         filename,
+				Name:         strutil.TruncateRunes(file.Name, 60, "..."),
 				DownloadPath: file.DownloadPath,
 			})
 		}
@@ -236,16 +254,22 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 			MovieCount:      mediaStats.Movie,
 			TVCount:         mediaStats.TV,
 			OtherCount:      mediaStats.Other,
+			MusicCount:      mediaStats.Music,
+			DocumentCount:   mediaStats.Document,
 			ExampleFiles:    exampleFiles,
 			ConfirmCommand:  confirmCommand,
+			VideoOnly:       videoOnly,
 			EscapeHTML:      msgUtils.EscapeHTML,
 		})
+		if warning := utils.VideoOnlyConflictWarning(videoOnly, h.deps.GetConfig().Download.VideoOnly); warning != "" {
+			message += "\n\n" + warning
+		}
 
 		storedReq := manualDownloadRequest{
 			Path:      path,
 			StartTime: timeResult.StartTime.Format(time.RFC3339),
 			EndTime:   timeResult.EndTime.Format(time.RFC3339),
-			VideoOnly: true,
+			VideoOnly: videoOnly,
 			Preview:   false,
 		}
 
@@ -269,7 +293,7 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 
 		messageID := msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
 		if messageID > 0 {
-			msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+			msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
 		}
 		return
 	}
@@ -285,6 +309,7 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 				Filename:     file.Name,
 				Directory:    file.DownloadPath,
 				AutoClassify: true,
+				SourcePath:   file.Path,
 			}
 
 			_, err := h.deps.GetDownloadService().CreateDownload(ctx, downloadReq)
@@ -306,12 +331,14 @@ func (h *Handler) HandleManualDownload(chatID int64, timeArgs []string, preview
 			MovieCount:      mediaStats.Movie,
 			TVCount:         mediaStats.TV,
 			OtherCount:      mediaStats.Other,
+			MusicCount:      mediaStats.Music,
+			DocumentCount:   mediaStats.Document,
 			SuccessCount:    successCount,
 			FailCount:       failCount,
 			EscapeHTML:      msgUtils.EscapeHTML,
 		})
 
-		msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+		msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageImportant)
 	}
 }
 
@@ -324,6 +351,8 @@ func (h *Handler) HandleQuickPreview(chatID int64, timeArgs []string) {
 // Manual download context management
 // ================================
 
+// storeManualContext stores a preview context and returns its token.
+// A new preview supersedes the previous one of the same chat, whose token is dropped.
 func (h *Handler) storeManualContext(ctx *ManualDownloadContext) string {
 	h.cleanupManualContexts()
 
@@ -334,12 +363,26 @@ func (h *Handler) storeManualContext(ctx *ManualDownloadContext) string {
 	token := fmt.Sprintf("md-%d-%d", ctx.ChatID, time.Now().UnixNano())
 
 	h.manualMutex.Lock()
+	if oldToken, ok := h.activeTokens[ctx.ChatID]; ok {
+		delete(h.manualContexts, oldToken)
+		logger.Debug("Manual preview superseded", "chatID", ctx.ChatID, "oldToken", oldToken, "newToken", token)
+	}
 	h.manualContexts[token] = &ctxCopy
+	h.activeTokens[ctx.ChatID] = token
 	h.manualMutex.Unlock()
 
 	return token
 }
 
+// isSupersededToken reports whether token was replaced by a newer preview of the chat
+func (h *Handler) isSupersededToken(chatID int64, token string) bool {
+	h.manualMutex.Lock()
+	defer h.manualMutex.Unlock()
+
+	activeToken, ok := h.activeTokens[chatID]
+	return ok && activeToken != token
+}
+
 // GetManualContext retrieves manual download context
 func (h *Handler) GetManualContext(token string) (*ManualDownloadContext, bool) {
 	h.manualMutex.Lock()
@@ -358,27 +401,40 @@ func (h *Handler) GetManualContext(token string) (*ManualDownloadContext, bool)
 // DeleteManualContext deletes manual download context
 func (h *Handler) DeleteManualContext(token string) {
 	h.manualMutex.Lock()
-	delete(h.manualContexts, token)
+	h.deleteManualContextLocked(token)
 	h.manualMutex.Unlock()
 }
 
+// deleteManualContextLocked deletes a context and its active-token entry, caller holds manualMutex
+func (h *Handler) deleteManualContextLocked(token string) {
+	if ctx, ok := h.manualContexts[token]; ok && h.activeTokens[ctx.ChatID] == token {
+		delete(h.activeTokens, ctx.ChatID)
+	}
+	delete(h.manualContexts, token)
+}
+
 func (h *Handler) cleanupManualContexts() {
 	cutoff := time.Now().Add(-10 * time.Minute)
 	h.manualMutex.Lock()
 	for token, ctx := range h.manualContexts {
 		if ctx.CreatedAt.Before(cutoff) {
-			delete(h.manualContexts, token)
+			h.deleteManualContextLocked(token)
 		}
 	}
 	h.manualMutex.Unlock()
 }
 
-// HandleManualConfirm handles manual download confirmation
-func (h *Handler) HandleManualConfirm(chatID int64, token string, messageID int) {
+// HandleManualConfirm handles manual download confirmation, downloads go to the confirming user's base path
+func (h *Handler) HandleManualConfirm(chatID, userID int64, token string, messageID int) {
 	msgUtils := h.deps.GetMessageUtils()
 
 	ctx, ok := h.GetManualContext(token)
 	if !ok {
+		if h.isSupersededToken(chatID, token) {
+			msgUtils.ClearInlineKeyboard(chatID, messageID)
+			msgUtils.SendMessage(chatID, "该预览已被新的预览取代，请在最新的预览中确认")
+			return
+		}
 		msgUtils.SendMessage(chatID, "预览已过期，请重新生成")
 		return
 	}
@@ -391,7 +447,7 @@ func (h *Handler) HandleManualConfirm(chatID int64, token string, messageID int)
 	h.DeleteManualContext(token)
 	msgUtils.ClearInlineKeyboard(chatID, messageID)
 
-	msgUtils.SendMessageWithAutoDelete(chatID, "正在创建下载任务...", 30)
+	msgUtils.SendMessageWithAutoDelete(chatID, "正在创建下载任务...", types.MessageTransient)
 
 	req := ctx.Request
 
@@ -415,7 +471,7 @@ func (h *Handler) HandleManualConfirm(chatID int64, token string, messageID int)
 		VideoOnly: req.VideoOnly,
 	}
 
-	requestCtx := context.Background()
+	requestCtx := contracts.WithUserID(context.Background(), userID)
 	timeRangeResp, err := h.deps.GetFileService().GetFilesByTimeRange(requestCtx, timeRangeReq)
 	if err != nil {
 		formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
@@ -437,13 +493,17 @@ func (h *Handler) HandleManualConfirm(chatID int64, token string, messageID int)
 	totalSizeStr := summary.TotalSizeFormatted
 
 	mediaStats := struct {
-		TV    int
-		Movie int
-		Other int
+		TV       int
+		Movie    int
+		Other    int
+		Music    int
+		Document int
 	}{
-		TV:    summary.TVFiles,
-		Movie: summary.MovieFiles,
-		Other: summary.OtherFiles,
+		TV:       summary.TVFiles,
+		Movie:    summary.MovieFiles,
+		Other:    summary.OtherFiles,
+		Music:    summary.MusicFiles,
+		Document: summary.DocumentFiles,
 	}
 
 	successCount := 0
@@ -456,6 +516,7 @@ func (h *Handler) HandleManualConfirm(chatID int64, token string, messageID int)
 			Filename:     file.Name,
 			Directory:    file.DownloadPath,
 			AutoClassify: true,
+			SourcePath:   file.Path,
 		}
 
 		_, err := h.deps.GetDownloadService().CreateDownload(requestCtx, downloadReq)
@@ -477,12 +538,14 @@ func (h *Handler) HandleManualConfirm(chatID int64, token string, messageID int)
 		MovieCount:      mediaStats.Movie,
 		TVCount:         mediaStats.TV,
 		OtherCount:      mediaStats.Other,
+		MusicCount:      mediaStats.Music,
+		DocumentCount:   mediaStats.Document,
 		SuccessCount:    successCount,
 		FailCount:       failCount,
 		EscapeHTML:      msgUtils.EscapeHTML,
 	})
 
-	msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+	msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageImportant)
 }
 
 // HandleManualCancel handles manual download cancel
@@ -495,7 +558,12 @@ func (h *Handler) HandleManualCancel(chatID int64, token string, messageID int)
 	}
 
 	msgUtils.ClearInlineKeyboard(chatID, messageID)
-	msgUtils.SendMessageWithAutoDelete(chatID, "已取消此次下载预览", 30)
+	msgUtils.SendMessageWithAutoDelete(chatID, "已取消此次下载预览", types.MessageTransient)
+}
+
+// isYesterdayArg reports whether arg selects the previous calendar day
+func isYesterdayArg(arg string) bool {
+	return strings.EqualFold(arg, "yesterday") || arg == "昨天"
 }
 
 func parseHours(s string) (int, error) {
@@ -0,0 +1,58 @@
+package download
+
+import (
+	"testing"
+	"time"
+)
+
+// TestStoreManualContext_Supersede 测试同一聊天的新预览取代旧预览
+func TestStoreManualContext_Supersede(t *testing.T) {
+	h := NewHandler(nil)
+
+	oldToken := h.storeManualContext(&ManualDownloadContext{ChatID: 1, Description: "最近24小时"})
+	otherToken := h.storeManualContext(&ManualDownloadContext{ChatID: 2, Description: "最近6小时"})
+	newToken := h.storeManualContext(&ManualDownloadContext{ChatID: 1, Description: "最近30分钟"})
+
+	if oldToken == newToken {
+		t.Fatalf("new preview reused token %q", oldToken)
+	}
+	if _, ok := h.GetManualContext(oldToken); ok {
+		t.Errorf("superseded token %q should be removed", oldToken)
+	}
+	if !h.isSupersededToken(1, oldToken) {
+		t.Errorf("isSupersededToken(%q) = false, want true", oldToken)
+	}
+
+	ctx, ok := h.GetManualContext(newToken)
+	if !ok || ctx.Description != "最近30分钟" {
+		t.Errorf("GetManualContext(%q) = %v, %v, want latest preview", newToken, ctx, ok)
+	}
+	if _, ok := h.GetManualContext(otherToken); !ok {
+		t.Errorf("preview of another chat %q should be kept", otherToken)
+	}
+
+	h.DeleteManualContext(newToken)
+	if h.isSupersededToken(1, oldToken) {
+		t.Errorf("isSupersededToken(%q) = true after latest preview deleted, want false", oldToken)
+	}
+	if len(h.manualContexts) != 1 || len(h.activeTokens) != 1 {
+		t.Errorf("contexts = %d, active tokens = %d, want 1 and 1", len(h.manualContexts), len(h.activeTokens))
+	}
+}
+
+func TestParseTimeArguments_Yesterday(t *testing.T) {
+	h := NewHandler(nil)
+
+	for _, arg := range []string{"yesterday", "Yesterday", "昨天"} {
+		result, err := h.parseTimeArguments([]string{arg})
+		if err != nil {
+			t.Fatalf("parseTimeArguments(%q) error = %v", arg, err)
+		}
+		if got := result.EndTime.Sub(result.StartTime); got != 24*time.Hour {
+			t.Errorf("parseTimeArguments(%q) range = %v, want 24h", arg, got)
+		}
+		if result.StartTime.Hour() != 0 || result.StartTime.Minute() != 0 {
+			t.Errorf("parseTimeArguments(%q) start = %v, want midnight", arg, result.StartTime)
+		}
+	}
+}
@@ -37,9 +37,9 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 		msg := formatter.FormatError("获取文件列表", err)
 		if messageID > 0 {
 			msgUtils.EditMessageWithKeyboard(chatID, messageID, msg, "HTML", nil)
-			msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+			msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
 		} else {
-			msgUtils.SendMessageHTMLWithAutoDelete(chatID, msg, 30)
+			msgUtils.SendMessageHTMLWithAutoDelete(chatID, msg, types.MessageTransient)
 		}
 		return
 	}
@@ -48,9 +48,9 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 		msg := "当前目录中没有视频文件"
 		if messageID > 0 {
 			msgUtils.EditMessageWithKeyboard(chatID, messageID, msg, "HTML", nil)
-			msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+			msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
 		} else {
-			msgUtils.SendMessageHTMLWithAutoDelete(chatID, msg, 30)
+			msgUtils.SendMessageHTMLWithAutoDelete(chatID, msg, types.MessageTransient)
 		}
 		return
 	}
@@ -60,9 +60,9 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 		msg := fmt.Sprintf("目录中有 %d 个视频文件，为避免超时，批量重命名限制为 %d 个文件。\n\n请考虑分批处理或使用单文件重命名。", len(videoFiles), limit)
 		if messageID > 0 {
 			msgUtils.EditMessageWithKeyboard(chatID, messageID, msg, "HTML", nil)
-			msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+			msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
 		} else {
-			msgUtils.SendMessageHTMLWithAutoDelete(chatID, msg, 30)
+			msgUtils.SendMessageHTMLWithAutoDelete(chatID, msg, types.MessageTransient)
 		}
 		return
 	}
@@ -94,9 +94,9 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 		message += fmt.Sprintf("❌ 批量获取建议失败: %s\n", msgUtils.EscapeHTML(err.Error()))
 		if messageID > 0 {
 			msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", nil)
-			msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+			msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
 		} else {
-			msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+			msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageTransient)
 		}
 		return
 	}
@@ -106,6 +106,8 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 	successCount := 0
 	skippedCount := 0      // 已符合标准格式的文件数
 	unprocessableCount := 0 // 无法处理的文件数（特殊内容/无法识别）
+	unverifiedCount := 0    // TMDB不可达时仅凭文件名生成的建议数
+	retryableCount := 0     // 因TMDB请求失败跳过、可稍后重试的文件数
 	detailsMessage := ""
 
 	for i, filePath := range videoFiles {
@@ -146,7 +148,20 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 		if selected.Skipped {
 			// 区分"已符合标准"和"无法处理"两种情况
 			// 注：跳过原因常量定义在 file/rename_tv.go 中
-			if selected.SkipReason == "已符合 Emby 标准格式" {
+			if selected.Retryable {
+				// TMDB请求失败，与无匹配区分，提示用户稍后重试
+				retryableCount++
+				logger.Warn("文件因TMDB请求失败跳过",
+					"filePath", filePath,
+					"reason", selected.SkipReason)
+				if displayCount < maxDisplayItems {
+					detailsMessage += fmt.Sprintf("%d. 🔁 <code>%s</code>\n   %s\n\n",
+						i+1,
+						msgUtils.EscapeHTML(filepath.Base(filePath)),
+						selected.SkipReason)
+					displayCount++
+				}
+			} else if selected.SkipReason == "已符合 Emby 标准格式" {
 				// 已符合标准格式的文件，跳过不显示
 				skippedCount++
 				logger.Info("文件已符合标准格式，跳过显示",
@@ -169,8 +184,14 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 			continue
 		}
 
+		unverifiedMark := ""
+		if selected.Source == "parsed" {
+			unverifiedCount++
+			unverifiedMark = " 📝未验证"
+		}
+
 		if displayCount < maxDisplayItems {
-			detailsMessage += fmt.Sprintf("%d. <code>%s</code>\n   → <code>%s</code>\n\n", i+1, msgUtils.EscapeHTML(filePath), msgUtils.EscapeHTML(selected.NewPath))
+			detailsMessage += fmt.Sprintf("%d. <code>%s</code>\n   → <code>%s</code>%s\n\n", i+1, msgUtils.EscapeHTML(filePath), msgUtils.EscapeHTML(selected.NewPath), unverifiedMark)
 			displayCount++
 		}
 
@@ -183,7 +204,10 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 	}
 
 	if successCount == 0 {
-		if skippedCount > 0 && unprocessableCount == 0 {
+		if retryableCount > 0 {
+			message += fmt.Sprintf("\n🔁 %d 个文件因TMDB请求失败被跳过，请稍后重新执行批量重命名", retryableCount)
+			message += "\n\n" + detailsMessage
+		} else if skippedCount > 0 && unprocessableCount == 0 {
 			message += fmt.Sprintf("\n✅ 所有 %d 个文件已符合标准格式，无需重命名", skippedCount)
 		} else if skippedCount > 0 && unprocessableCount > 0 {
 			message += fmt.Sprintf("\n✅ %d 个文件已符合标准格式\n⚠️ %d 个文件无法处理（特殊内容/无法识别）", skippedCount, unprocessableCount)
@@ -196,9 +220,9 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 		}
 		if messageID > 0 {
 			msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", nil)
-			msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+			msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
 		} else {
-			msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+			msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageTransient)
 		}
 		return
 	}
@@ -211,8 +235,17 @@ func (h *Handler) HandleBatchRenameWithEdit(chatID int64, dirPath string, messag
 	if unprocessableCount > 0 {
 		statsLine += fmt.Sprintf(" | ⚠️ 无法处理: %d", unprocessableCount)
 	}
+	if retryableCount > 0 {
+		statsLine += fmt.Sprintf(" | 🔁 请求失败: %d", retryableCount)
+	}
 	statsLine += fmt.Sprintf(" | 📊 总计: %d\n\n", len(videoFiles))
 	message += statsLine
+	if unverifiedCount > 0 {
+		message += fmt.Sprintf("⚠️ TMDB暂不可达，%d 个建议仅根据文件名解析（📝未验证），请仔细核对\n\n", unverifiedCount)
+	}
+	if retryableCount > 0 {
+		message += fmt.Sprintf("🔁 %d 个文件因TMDB请求失败被跳过（非无匹配），确认后这些文件保持不变，可稍后重新执行批量重命名\n\n", retryableCount)
+	}
 	message += detailsMessage
 
 	if len(videoFiles) > maxDisplayItems {
@@ -277,6 +310,7 @@ func (h *Handler) HandleBatchRenameConfirm(chatID int64, dirPath string, message
 	taskIndexMap := make(map[int]int)      // 记录任务索引到videoFiles索引的映射
 	skippedFiles := make([]int, 0)         // 记录跳过的文件索引（无建议）
 	alreadyStandardFiles := make([]int, 0) // 记录已符合标准的文件索引
+	retryableFiles := make([]int, 0)       // 记录因TMDB请求失败跳过的文件索引
 
 	for i, filePath := range videoFiles {
 		suggestions, found := suggestionsMap[filePath]
@@ -284,6 +318,10 @@ func (h *Handler) HandleBatchRenameConfirm(chatID int64, dirPath string, message
 			skippedFiles = append(skippedFiles, i)
 			continue
 		}
+		if suggestions[0].Retryable {
+			retryableFiles = append(retryableFiles, i)
+			continue
+		}
 		// 跳过已符合标准格式的文件
 		if suggestions[0].Skipped {
 			alreadyStandardFiles = append(alreadyStandardFiles, i)
@@ -325,6 +363,17 @@ func (h *Handler) HandleBatchRenameConfirm(chatID int64, dirPath string, message
 		}
 	}
 
+	// 显示因TMDB请求失败跳过的文件
+	for _, idx := range retryableFiles {
+		if displayCount < maxDisplayItems {
+			results += fmt.Sprintf("%d. 🔁 <code>%s</code>\n   %s\n\n",
+				idx+1,
+				msgUtils.EscapeHTML(filepath.Base(videoFiles[idx])),
+				suggestionsMap[videoFiles[idx]][0].SkipReason)
+			displayCount++
+		}
+	}
+
 	// 显示重命名结果
 	for taskIdx, result := range renameResults {
 		originalIdx := taskIndexMap[taskIdx]
@@ -365,11 +414,14 @@ func (h *Handler) HandleBatchRenameConfirm(chatID int64, dirPath string, message
 	if failCount > 0 {
 		statsText += fmt.Sprintf("\n❌ 失败: %d", failCount)
 	}
+	if len(retryableFiles) > 0 {
+		statsText += fmt.Sprintf("\n🔁 请求失败待重试: %d（请稍后重新执行批量重命名）", len(retryableFiles))
+	}
 	statsText += fmt.Sprintf("\n📊 总计: %d", len(videoFiles))
 	results += statsText
 
 	msgUtils.EditMessageWithKeyboard(chatID, messageID, results, "HTML", nil)
-	msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+	msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageImportant)
 }
 
 // ================================
@@ -2,7 +2,10 @@ package file
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -12,6 +15,9 @@ import (
 // 文件浏览功能
 // ================================
 
+// BrowseRootsCallback 返回存储根目录列表（alist.roots）
+const BrowseRootsCallback = "browse_roots"
+
 // HandleBrowseFiles 处理文件浏览（支持分页和交互）
 func (h *Handler) HandleBrowseFiles(chatID int64, path string, page int) {
 	h.HandleBrowseFilesWithEdit(chatID, path, page, 0) // 0 表示发送新消息
@@ -19,14 +25,33 @@ func (h *Handler) HandleBrowseFiles(chatID int64, path string, page int) {
 
 // HandleBrowseFilesWithEdit 处理文件浏览（支持消息编辑和分页）
 func (h *Handler) HandleBrowseFilesWithEdit(chatID int64, path string, page int, messageID int) {
+	h.HandleBrowseFilesFiltered(chatID, "", path, page, messageID, 0)
+}
+
+// HandleBrowseFilesFiltered 处理文件浏览，days > 0 时只显示近 days 天内修改的文件和目录
+// 筛选条件编码在所有浏览按钮的回调数据中，翻页、进入子目录时保持
+// root 为 alist.roots 中的根目录名称（为空时不限定），浏览范围限制在该根目录内，路径令牌同样携带根目录
+func (h *Handler) HandleBrowseFilesFiltered(chatID int64, root string, path string, page int, messageID int, days int) {
 	if path == "" {
 		path = "/"
 	}
+	rootPath := "/"
+	if r, ok := h.deps.GetConfig().Alist.FindRoot(root); ok {
+		rootPath = cleanRootPath(r.Path)
+		if !isWithinRoot(path, rootPath) {
+			path = rootPath
+		}
+	} else {
+		root = ""
+	}
+	encode := func(p string) string {
+		return h.deps.EncodeRootFilePath(root, p)
+	}
 	if page < 1 {
 		page = 1
 	}
 
-	logger.Info("Browsing files", "path", path, "page", page, "messageID", messageID)
+	logger.Info("Browsing files", "root", root, "path", path, "page", page, "messageID", messageID, "days", days)
 
 	msgUtils := h.deps.GetMessageUtils()
 
@@ -36,15 +61,28 @@ func (h *Handler) HandleBrowseFilesWithEdit(chatID int64, path string, page int,
 	}
 
 	// 获取文件列表（每页显示8个文件，为按钮布局预留空间）
-	files, err := h.ListFilesSimple(path, page, 8)
+	showHidden := h.ShowHiddenFiles(chatID)
+	var files []contracts.FileResponse
+	var hiddenCount int
+	var err error
+	var filter *browseFilterResult
+	if days > 0 {
+		filter, err = h.listFilesModifiedWithin(path, page, days, showHidden)
+		if filter != nil {
+			files, hiddenCount, page = filter.files, filter.hiddenCount, filter.page
+		}
+	} else {
+		files, hiddenCount, err = h.listFiles(path, page, browsePageSize, showHidden)
+	}
 	if err != nil {
 		formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
 		msgUtils.SendMessage(chatID, formatter.FormatError("获取文件列表", err))
 		return
 	}
 
-	if len(files) == 0 {
-		msgUtils.SendMessageHTMLWithAutoDelete(chatID, "当前目录为空", 30)
+	// 全部为隐藏项时仍显示浏览器，以便切换显示；筛选无结果时也显示，以便清除筛选
+	if len(files) == 0 && hiddenCount == 0 && filter == nil {
+		msgUtils.SendMessageHTMLWithAutoDelete(chatID, "当前目录为空", types.MessageTransient)
 		return
 	}
 
@@ -66,38 +104,57 @@ func (h *Handler) HandleBrowseFilesWithEdit(chatID int64, path string, page int,
 
 	// 使用统一格式化器
 	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	totalPages := 1
+	if filter != nil {
+		totalPages = filter.totalPages
+	}
 	browserData := utils.FileBrowserData{
-		Path:       path,
-		Page:       page,
-		TotalPages: 1,
-		TotalFiles: len(files),
-		DirCount:   dirCount,
-		FileCount:  fileCount,
-		VideoCount: videoCount,
-		EscapeHTML: msgUtils.EscapeHTML,
+		Path:        path,
+		Page:        page,
+		TotalPages:  totalPages,
+		TotalFiles:  len(files),
+		DirCount:    dirCount,
+		FileCount:   fileCount,
+		VideoCount:  videoCount,
+		HiddenCount: hiddenCount,
+		EscapeHTML:  msgUtils.EscapeHTML,
 	}
 	message := formatter.FormatFileBrowser(browserData)
+	if root != "" {
+		message += "\n" + formatter.FormatField("根目录", msgUtils.EscapeHTML(root))
+	}
+	if filter != nil {
+		message += "\n" + formatter.FormatField("筛选", fmt.Sprintf("近%d天修改，匹配 %d/%d 项", days, filter.matched, filter.listed))
+	}
 	message += "\n"
 
-	// 构建内联键盘
+	// 所有浏览按钮携带当前筛选条件
+	suffix := browseFilterSuffix(days)
+
+	// 构建内联键盘：平铺时按列表顺序，分组时按类型分区
 	var keyboard [][]tgbotapi.InlineKeyboardButton
+	var sections [browseSectionCount][][]tgbotapi.InlineKeyboardButton
+	grouped := h.GroupBrowse(chatID)
 
 	for _, file := range files {
 		var prefix string
 		var callbackData string
+		var episodeTag string
 
 		if file.IsDir {
 			prefix = "📁"
 			fullPath := h.BuildFullPath(file, path)
-			callbackData = fmt.Sprintf("browse_dir:%s:1", h.deps.EncodeFilePath(fullPath))
+			callbackData = fmt.Sprintf("browse_dir:%s:1%s", encode(fullPath), suffix)
 		} else if fileService.IsVideoFile(file.Name) {
 			prefix = "🎬"
 			fullPath := h.BuildFullPath(file, path)
-			callbackData = fmt.Sprintf("file_menu:%s", h.deps.EncodeFilePath(fullPath))
+			callbackData = fmt.Sprintf("file_menu:%s", encode(fullPath))
+			// 仅对视频文件解析季集信息
+			episodeTag = fileService.GetEpisodeTag(fullPath)
 		} else {
 			prefix = "📄"
 			fullPath := h.BuildFullPath(file, path)
-			callbackData = fmt.Sprintf("file_menu:%s", h.deps.EncodeFilePath(fullPath))
+			callbackData = fmt.Sprintf("file_menu:%s", encode(fullPath))
 		}
 
 		fileName := file.Name
@@ -107,7 +164,12 @@ func (h *Handler) HandleBrowseFilesWithEdit(chatID int64, path string, page int,
 		}
 
 		btnFormatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
-		fileName = btnFormatter.TruncateButtonText(fileName, maxWidth)
+		if episodeTag != "" {
+			// 季集标记放在文件名前，截断时保留标记
+			fileName = episodeTag + " " + btnFormatter.TruncateButtonText(fileName, maxWidth-len(episodeTag)-1)
+		} else {
+			fileName = btnFormatter.TruncateButtonText(fileName, maxWidth)
+		}
 
 		button := tgbotapi.NewInlineKeyboardButtonData(
 			fmt.Sprintf("%s %s", prefix, fileName),
@@ -115,24 +177,47 @@ func (h *Handler) HandleBrowseFilesWithEdit(chatID int64, path string, page int,
 		)
 
 		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
+		section := browseSectionOf(file, fileService.IsVideoFile(file.Name))
+		sections[section] = append(sections[section], []tgbotapi.InlineKeyboardButton{button})
 	}
 
-	// 添加导航按钮
+	// 只有一种类型时分组没有意义，保持平铺
+	if grouped {
+		if groupedRows := groupBrowseRows(sections); groupedRows != nil {
+			keyboard = groupedRows
+		}
+	}
+
+	// 添加导航按钮（分组在当前页内进行，分页不受影响）
 	navButtons := []tgbotapi.InlineKeyboardButton{}
 
 	// 上一页按钮
 	if page > 1 {
 		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData(
 			"< 上一页",
-			fmt.Sprintf("browse_page:%s:%d", h.deps.EncodeFilePath(path), page-1),
+			fmt.Sprintf("browse_page:%s:%d%s", encode(path), page-1, suffix),
 		))
 	}
 
-	// 下一页按钮（如果当前页已满，可能还有更多）
-	if len(files) == 8 {
+	// 分组/平铺切换
+	groupLabel := "🗂️ 分组"
+	if grouped {
+		groupLabel = "📃 平铺"
+	}
+	navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData(
+		groupLabel,
+		fmt.Sprintf("browse_group:%s:%d%s", encode(path), page, suffix),
+	))
+
+	// 下一页按钮（如果当前页已满，可能还有更多；隐藏项同样占用分页名额；筛选时总页数已知）
+	hasNext := len(files)+hiddenCount == browsePageSize
+	if filter != nil {
+		hasNext = page < filter.totalPages
+	}
+	if hasNext {
 		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData(
 			"下一页 >",
-			fmt.Sprintf("browse_page:%s:%d", h.deps.EncodeFilePath(path), page+1),
+			fmt.Sprintf("browse_page:%s:%d%s", encode(path), page+1, suffix),
 		))
 	}
 
@@ -142,32 +227,60 @@ func (h *Handler) HandleBrowseFilesWithEdit(chatID int64, path string, page int,
 
 	// 添加操作按钮 - 第一行：下载和刷新
 	actionRow1 := []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📥 下载目录", fmt.Sprintf("download_dir:%s", h.deps.EncodeFilePath(path))),
-		tgbotapi.NewInlineKeyboardButtonData("📝 批量重命名", fmt.Sprintf("batch_rename:%s", h.deps.EncodeFilePath(path))),
-		tgbotapi.NewInlineKeyboardButtonData("🔄 刷新", fmt.Sprintf("browse_refresh:%s:%d", h.deps.EncodeFilePath(path), page)),
+		tgbotapi.NewInlineKeyboardButtonData("📥 下载目录", fmt.Sprintf("download_dir:%s", encode(path))),
+		tgbotapi.NewInlineKeyboardButtonData("📝 批量重命名", fmt.Sprintf("batch_rename:%s", encode(path))),
+		tgbotapi.NewInlineKeyboardButtonData("🔄 刷新", fmt.Sprintf("browse_refresh:%s:%d%s", encode(path), page, suffix)),
 	}
 	keyboard = append(keyboard, actionRow1)
 
+	// 修改日期筛选，筛选生效时提供下载筛选结果
+	keyboard = append(keyboard, h.browseFilterRow(encode(path), days))
+	if filter != nil && filter.matched > 0 {
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("📥 下载筛选结果（近%d天）", days),
+				fmt.Sprintf("download_since:%s:%d", encode(path), days),
+			),
+		})
+	}
+
 	// 添加导航按钮 - 第二行：上级目录、删除目录和主菜单
 	actionRow2 := []tgbotapi.InlineKeyboardButton{}
 
-	// 返回上级目录按钮
-	if path != "/" {
+	// 返回上级目录按钮（不超出所选根目录）
+	if path != rootPath {
 		parentPath := h.GetParentPath(path)
 		actionRow2 = append(actionRow2, tgbotapi.NewInlineKeyboardButtonData(
 			"⬆️ 上级目录",
-			fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(parentPath), 1),
+			fmt.Sprintf("browse_dir:%s:%d%s", encode(parentPath), 1, suffix),
 		))
 	}
 
 	// 删除目录按钮（仅非根目录）
-	if path != "/" {
+	if path != rootPath {
 		actionRow2 = append(actionRow2, tgbotapi.NewInlineKeyboardButtonData(
 			"🗑️ 删除目录",
-			fmt.Sprintf("dir_delete_confirm:%s", h.deps.EncodeFilePath(path)),
+			fmt.Sprintf("dir_delete_confirm:%s", encode(path)),
 		))
 	}
 
+	// 收藏当前目录、收藏夹入口、搜索和隐藏文件开关
+	hiddenLabel := "👁️ 显示隐藏"
+	if showHidden {
+		hiddenLabel = "🙈 隐藏隐藏项"
+	}
+	keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("⭐ 收藏", fmt.Sprintf("pin_add:%s", encode(path))),
+		tgbotapi.NewInlineKeyboardButtonData("🗂️ 收藏夹", "pins_list"),
+		tgbotapi.NewInlineKeyboardButtonData("🔍 搜索", fmt.Sprintf("files_search:%s", encode(path))),
+		tgbotapi.NewInlineKeyboardButtonData(hiddenLabel, fmt.Sprintf("browse_hidden:%s:%d%s", encode(path), page, suffix)),
+	})
+
+	// 配置了多个根目录时可返回根目录列表
+	if len(h.deps.GetConfig().Alist.Roots) > 0 {
+		actionRow2 = append(actionRow2, tgbotapi.NewInlineKeyboardButtonData("🗂️ 根目录", BrowseRootsCallback))
+	}
+
 	// 返回主菜单按钮
 	actionRow2 = append(actionRow2, tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"))
 
@@ -184,8 +297,72 @@ func (h *Handler) HandleBrowseFilesWithEdit(chatID int64, path string, page int,
 	}
 }
 
-// HandleFilesBrowseWithEdit 处理文件浏览（支持消息编辑）
+// 分组浏览的分区，按显示顺序排列
+const (
+	browseSectionDir = iota
+	browseSectionMovie
+	browseSectionTV
+	browseSectionOther
+	browseSectionCount
+)
+
+// browseSectionTitles 分区标题
+var browseSectionTitles = [browseSectionCount]string{
+	browseSectionDir:   "📁 目录",
+	browseSectionMovie: "🎬 电影",
+	browseSectionTV:    "📺 剧集",
+	browseSectionOther: "📄 其他",
+}
+
+// browseSectionOf 根据文件分类确定所属分区，只有视频文件归入电影/剧集（剧集目录中的字幕等归入其他）
+func browseSectionOf(file contracts.FileResponse, isVideo bool) int {
+	switch {
+	case file.IsDir:
+		return browseSectionDir
+	case !isVideo:
+		return browseSectionOther
+	case file.Category == "movie":
+		return browseSectionMovie
+	case file.Category == "tv" || file.Category == "variety":
+		return browseSectionTV
+	default:
+		return browseSectionOther
+	}
+}
+
+// groupBrowseRows 按分区组织按钮行并在每个分区前加标题行，只有一个非空分区时返回 nil（使用平铺列表）
+func groupBrowseRows(sections [browseSectionCount][][]tgbotapi.InlineKeyboardButton) [][]tgbotapi.InlineKeyboardButton {
+	nonEmpty := 0
+	for _, rows := range sections {
+		if len(rows) > 0 {
+			nonEmpty++
+		}
+	}
+	if nonEmpty < 2 {
+		return nil
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for section, rows := range sections {
+		if len(rows) == 0 {
+			continue
+		}
+		header := tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("── %s (%d) ──", browseSectionTitles[section], len(rows)),
+			"browse_section",
+		)
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{header})
+		keyboard = append(keyboard, rows...)
+	}
+	return keyboard
+}
+
+// HandleFilesBrowseWithEdit 处理文件浏览（支持消息编辑），配置了 alist.roots 时先选择根目录
 func (h *Handler) HandleFilesBrowseWithEdit(chatID int64, messageID int) {
+	if len(h.deps.GetConfig().Alist.Roots) > 0 {
+		h.HandleRootPicker(chatID, messageID)
+		return
+	}
 	defaultPath := h.deps.GetConfig().Alist.DefaultPath
 	if defaultPath == "" {
 		defaultPath = "/"
@@ -195,5 +372,46 @@ func (h *Handler) HandleFilesBrowseWithEdit(chatID int64, messageID int) {
 
 // HandleAlistFilesWithEdit 处理获取 Alist 文件列表（支持消息编辑）
 func (h *Handler) HandleAlistFilesWithEdit(chatID int64, messageID int) {
-	h.HandleBrowseFilesWithEdit(chatID, h.deps.GetConfig().Alist.DefaultPath, 1, messageID)
+	h.HandleFilesBrowseWithEdit(chatID, messageID)
+}
+
+// HandleRootPicker 显示 alist.roots 中的存储根目录，选择后在该根目录内浏览
+func (h *Handler) HandleRootPicker(chatID int64, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	lines := []string{formatter.FormatTitle("🗂️", "选择存储根目录"), ""}
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, root := range h.deps.GetConfig().Alist.Roots {
+		rootPath := cleanRootPath(root.Path)
+		lines = append(lines, formatter.FormatFieldCode(msgUtils.EscapeHTML(root.Label), msgUtils.EscapeHTML(rootPath)))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			"📁 "+formatter.TruncateButtonText(root.Label, 30),
+			fmt.Sprintf("browse_dir:%s:1", h.deps.EncodeRootFilePath(root.Label, rootPath)),
+		)))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
+	))
+
+	message := strings.Join(lines, "\n")
+	inlineKeyboard := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &inlineKeyboard)
+	} else {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &inlineKeyboard)
+	}
+}
+
+// cleanRootPath 规范化根目录路径（去除末尾的 /）
+func cleanRootPath(rootPath string) string {
+	if cleaned := strings.TrimRight(rootPath, "/"); cleaned != "" {
+		return cleaned
+	}
+	return "/"
+}
+
+// isWithinRoot 路径是否为根目录本身或其子路径
+func isWithinRoot(path, rootPath string) bool {
+	return rootPath == "/" || path == rootPath || strings.HasPrefix(path, rootPath+"/")
 }
@@ -0,0 +1,161 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	timeutil "github.com/easayliu/alist-aria2-download/pkg/utils/time"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 按修改日期筛选浏览和下载
+// ================================
+
+const (
+	// browsePageSize 浏览每页显示的条目数，为按钮布局预留空间
+	browsePageSize = 8
+	// browseFilterListSize 筛选时一次读取的目录条目上限，筛选后在本地分页
+	browseFilterListSize = 1000
+)
+
+// browseFilterDays 浏览中可选的修改日期筛选（天），0 表示不筛选
+var browseFilterDays = []int{0, 1, 7, 30}
+
+// browseFilterResult 按修改日期筛选后的当前页
+type browseFilterResult struct {
+	files       []contracts.FileResponse
+	hiddenCount int
+	page        int // 超出总页数时修正为最后一页
+	totalPages  int
+	matched     int // 匹配的条目数
+	listed      int // 目录中的条目总数
+}
+
+// browseFilterSuffix 浏览回调数据中的筛选后缀，格式为 browse_*:<path>:<page>:<days>，不筛选时为空
+func browseFilterSuffix(days int) string {
+	if days <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(":%d", days)
+}
+
+// modifiedSince 返回近 days 天的起始时间
+func modifiedSince(days int) timeutil.TimeRange {
+	return timeutil.CreateTimeRangeFromHours(days * 24)
+}
+
+// listFilesModifiedWithin 读取目录并只保留近 days 天内修改的文件和目录，在本地分页
+func (h *Handler) listFilesModifiedWithin(path string, page, days int, includeHidden bool) (*browseFilterResult, error) {
+	items, hiddenCount, err := h.listFiles(path, 1, browseFilterListSize, includeHidden)
+	if err != nil {
+		return nil, err
+	}
+
+	since := modifiedSince(days).Start
+	var matched []contracts.FileResponse
+	for _, item := range items {
+		if !item.Modified.Before(since) {
+			matched = append(matched, item)
+		}
+	}
+
+	totalPages := max((len(matched)+browsePageSize-1)/browsePageSize, 1)
+	page = min(page, totalPages)
+	start := (page - 1) * browsePageSize
+	end := min(start+browsePageSize, len(matched))
+
+	return &browseFilterResult{
+		files:       matched[start:end],
+		hiddenCount: hiddenCount,
+		page:        page,
+		totalPages:  totalPages,
+		matched:     len(matched),
+		listed:      len(items),
+	}, nil
+}
+
+// browseFilterRow 构建修改日期筛选按钮行，当前筛选标记 ✓，切换筛选时回到第一页
+// encodedPath 为当前目录的路径令牌（携带所选根目录）
+func (h *Handler) browseFilterRow(encodedPath string, days int) []tgbotapi.InlineKeyboardButton {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(browseFilterDays))
+	for _, option := range browseFilterDays {
+		label := "全部"
+		if option > 0 {
+			label = fmt.Sprintf("近%d天", option)
+		}
+		if option == days {
+			label = "✓ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label,
+			fmt.Sprintf("browse_page:%s:1%s", encodedPath, browseFilterSuffix(option))))
+	}
+	return row
+}
+
+// HandleDownloadFilteredConfirm 显示下载筛选结果的确认对话框，列出目录中（递归）近 days 天修改的视频文件数和大小
+// root 为浏览时所在的 alist.roots 根目录名称，“返回浏览”时保持在该根目录内
+func (h *Handler) HandleDownloadFilteredConfirm(chatID int64, root, dirPath string, days int, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	req := h.directoryDownloadRequest(chatID, dirPath)
+	resp, err := h.deps.GetFileService().ListFiles(context.Background(), contracts.FileListRequest{
+		Path:          dirPath,
+		Recursive:     req.Recursive,
+		VideoOnly:     req.VideoOnly,
+		PageSize:      10000,
+		IncludeHidden: req.IncludeHidden,
+	})
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("获取文件列表", err))
+		return
+	}
+
+	since := modifiedSince(days).Start
+	matched := 0
+	var totalSize int64
+	for _, file := range resp.Files {
+		if !file.Modified.Before(since) {
+			matched++
+			totalSize += file.Size
+		}
+	}
+
+	message := formatter.FormatTitle("📥", "下载筛选结果") + "\n\n" +
+		formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(dirPath)) + "\n" +
+		formatter.FormatField("筛选", fmt.Sprintf("近%d天修改（%s 之后）", days, since.Format("01-02 15:04"))) + "\n" +
+		formatter.FormatField("匹配", fmt.Sprintf("%d/%d 个视频文件", matched, len(resp.Files))) + "\n" +
+		formatter.FormatField("大小", msgUtils.FormatFileSize(totalSize))
+
+	backButton := tgbotapi.NewInlineKeyboardButtonData("↩️ 返回浏览",
+		fmt.Sprintf("browse_dir:%s:1%s", h.deps.EncodeRootFilePath(root, dirPath), browseFilterSuffix(days)))
+	if matched == 0 {
+		message += "\n\n没有符合条件的视频文件"
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(backButton))
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+		return
+	}
+
+	message += "\n\n是否确认下载？"
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ 下载 %d 个文件", matched),
+				fmt.Sprintf("download_since_confirm:%s:%d", h.deps.EncodeRootFilePath(root, dirPath), days)),
+			backButton,
+		),
+	)
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+}
+
+// HandleDownloadFilteredExecute 下载目录中近 days 天修改的视频文件
+func (h *Handler) HandleDownloadFilteredExecute(chatID, userID int64, dirPath string, days int, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在处理下载任务...", "HTML", nil)
+
+	req := h.directoryDownloadRequest(chatID, dirPath)
+	req.ModifiedAfter = modifiedSince(days).Start
+	h.handleDownloadDirectoryByPathWithEdit(chatID, userID, messageID, req)
+}
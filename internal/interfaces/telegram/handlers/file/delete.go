@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
+	"unicode"
 
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -13,8 +16,85 @@ import (
 // 文件/目录删除功能
 // ================================
 
+// dryRunFlag /delete 命令的试运行参数
+const dryRunFlag = "--dryrun"
+
+// HandleDeleteCommand 处理 /delete [--dryrun] <路径> 命令
+// 不带 --dryrun 时显示删除确认，带 --dryrun 时只展示将要删除的内容
+func (h *Handler) HandleDeleteCommand(chatID int64, args string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	rawPath, dryRun := parseDeleteArgs(args)
+	if rawPath == "" {
+		msgUtils.SendMessageHTML(chatID, "使用方式：<code>/delete [--dryrun] &lt;路径&gt;</code>")
+		return
+	}
+	targetPath := NormalizePinPath(rawPath)
+
+	ctx := context.Background()
+	fileInfo, err := h.deps.GetFileService().GetFileInfo(ctx, targetPath)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("获取文件信息", err))
+		return
+	}
+
+	if !dryRun {
+		if fileInfo.IsDir {
+			h.HandleDirDeleteConfirm(chatID, "", targetPath, 0)
+		} else {
+			h.HandleFileDeleteConfirm(chatID, "", targetPath, 0)
+		}
+		return
+	}
+
+	targets, err := h.deps.GetFileService().PreviewDelete(ctx, []string{targetPath})
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("试运行删除", err))
+		return
+	}
+
+	kind := "文件"
+	if fileInfo.IsDir {
+		kind = "目录（含所有内容）"
+	}
+	message := formatter.FormatTitle("🧪", "删除试运行") + "\n\n" +
+		formatter.FormatField("类型", kind) + "\n"
+	if !fileInfo.IsDir {
+		message += formatter.FormatField("大小", msgUtils.FormatFileSize(fileInfo.Size)) + "\n"
+	}
+	message += "\n" + formatDeleteTargets(targets, msgUtils.EscapeHTML) +
+		"\n\n<i>未实际删除，去掉 --dryrun 后执行删除</i>"
+
+	msgUtils.SendMessageHTML(chatID, message)
+}
+
+// parseDeleteArgs 从命令剩余部分取出路径，保留路径中的原始空格；--dryrun 可位于路径前或路径后
+func parseDeleteArgs(args string) (string, bool) {
+	rest := strings.TrimSpace(args)
+	if after, found := strings.CutPrefix(rest, dryRunFlag); found && (after == "" || unicode.IsSpace(rune(after[0]))) {
+		return strings.TrimSpace(after), true
+	}
+	if before, found := strings.CutSuffix(rest, dryRunFlag); found && (before == "" || unicode.IsSpace(rune(before[len(before)-1]))) {
+		return strings.TrimSpace(before), true
+	}
+	return rest, false
+}
+
+// formatDeleteTargets 格式化将要删除的目标
+func formatDeleteTargets(targets []contracts.DeleteTarget, escapeHTML func(string) string) string {
+	var lines []string
+	for _, target := range targets {
+		lines = append(lines, fmt.Sprintf("📂 <code>%s</code>", escapeHTML(target.Dir)))
+		for _, name := range target.Names {
+			lines = append(lines, fmt.Sprintf("  🗑️ <code>%s</code>", escapeHTML(name)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // HandleFileDeleteConfirm 处理文件删除确认
-func (h *Handler) HandleFileDeleteConfirm(chatID int64, filePath string, messageID int) {
+func (h *Handler) HandleFileDeleteConfirm(chatID int64, root, filePath string, messageID int) {
 	fileName := filepath.Base(filePath)
 	parentDir := filepath.Dir(filePath)
 
@@ -27,8 +107,8 @@ func (h *Handler) HandleFileDeleteConfirm(chatID int64, filePath string, message
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ 确认删除", fmt.Sprintf("file_delete:%s", h.deps.EncodeFilePath(filePath))),
-			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", fmt.Sprintf("file_menu:%s", h.deps.EncodeFilePath(filePath))),
+			tgbotapi.NewInlineKeyboardButtonData("✅ 确认删除", fmt.Sprintf("file_delete:%s", h.deps.EncodeRootFilePath(root, filePath))),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", fmt.Sprintf("file_menu:%s", h.deps.EncodeRootFilePath(root, filePath))),
 		),
 	)
 
@@ -40,7 +120,7 @@ func (h *Handler) HandleFileDeleteConfirm(chatID int64, filePath string, message
 }
 
 // HandleFileDelete 处理文件删除
-func (h *Handler) HandleFileDelete(chatID int64, filePath string, messageID int) {
+func (h *Handler) HandleFileDelete(chatID int64, root, filePath string, messageID int) {
 	fileName := filepath.Base(filePath)
 	parentDir := filepath.Dir(filePath)
 
@@ -59,7 +139,7 @@ func (h *Handler) HandleFileDelete(chatID int64, filePath string, messageID int)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📁 返回目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(parentDir), 1)),
+			tgbotapi.NewInlineKeyboardButtonData("📁 返回目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, parentDir), 1)),
 			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
 		),
 	)
@@ -72,7 +152,7 @@ func (h *Handler) HandleFileDelete(chatID int64, filePath string, messageID int)
 }
 
 // HandleDirDeleteConfirm 处理目录删除确认
-func (h *Handler) HandleDirDeleteConfirm(chatID int64, dirPath string, messageID int) {
+func (h *Handler) HandleDirDeleteConfirm(chatID int64, root, dirPath string, messageID int) {
 	dirName := filepath.Base(dirPath)
 	parentDir := filepath.Dir(dirPath)
 
@@ -85,8 +165,8 @@ func (h *Handler) HandleDirDeleteConfirm(chatID int64, dirPath string, messageID
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ 确认删除", fmt.Sprintf("dir_delete:%s", h.deps.EncodeFilePath(dirPath))),
-			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", fmt.Sprintf("dir_menu:%s", h.deps.EncodeFilePath(dirPath))),
+			tgbotapi.NewInlineKeyboardButtonData("✅ 确认删除", fmt.Sprintf("dir_delete:%s", h.deps.EncodeRootFilePath(root, dirPath))),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", fmt.Sprintf("dir_menu:%s", h.deps.EncodeRootFilePath(root, dirPath))),
 		),
 	)
 
@@ -98,7 +178,7 @@ func (h *Handler) HandleDirDeleteConfirm(chatID int64, dirPath string, messageID
 }
 
 // HandleDirDelete 处理目录删除
-func (h *Handler) HandleDirDelete(chatID int64, dirPath string, messageID int) {
+func (h *Handler) HandleDirDelete(chatID int64, root, dirPath string, messageID int) {
 	dirName := filepath.Base(dirPath)
 	parentDir := filepath.Dir(dirPath)
 
@@ -117,7 +197,7 @@ func (h *Handler) HandleDirDelete(chatID int64, dirPath string, messageID int) {
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📁 返回上级", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(parentDir), 1)),
+			tgbotapi.NewInlineKeyboardButtonData("📁 返回上级", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, parentDir), 1)),
 			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
 		),
 	)
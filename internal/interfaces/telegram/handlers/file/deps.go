@@ -3,6 +3,7 @@ package file
 import (
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/repository"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 )
 
@@ -10,9 +11,16 @@ import (
 type FileDeps interface {
 	GetMessageUtils() types.MessageSender
 	GetFileService() contracts.FileService
+	GetDownloadService() contracts.DownloadService
 	GetConfig() *config.Config
 	EncodeFilePath(path string) string
 	DecodeFilePath(encoded string) string
+	EncodeRootFilePath(root, path string) string
+	DecodeRootFilePath(encoded string) (string, string)
+	GetPinRepository() *repository.PinRepository
+	GetPresetRepository() *repository.PresetRepository
+	GetDownloadHistoryRepository() *repository.DownloadHistoryRepository
+	GetCategoryOverrideRepository() *repository.CategoryOverrideRepository
 
 	// 重命名相关（由 controller 实现，调用 BasicCommands）
 	HandleRenameCommand(chatID int64, command string)
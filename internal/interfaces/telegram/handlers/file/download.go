@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -15,18 +18,25 @@ import (
 // ================================
 
 // HandleFileDownload 处理文件下载
-func (h *Handler) HandleFileDownload(chatID int64, filePath string) {
-	h.handleDownloadFileByPath(chatID, filePath)
+func (h *Handler) HandleFileDownload(chatID, userID int64, root, filePath string) {
+	h.handleDownloadFileByPath(chatID, userID, root, contracts.FileDownloadRequest{FilePath: filePath, AutoClassify: true})
 }
 
-// handleDownloadFileByPath 通过路径下载单个文件
-func (h *Handler) handleDownloadFileByPath(chatID int64, filePath string) {
-	ctx := context.Background()
+// HandleFileDownloadAndDelete 下载文件，完成并校验后删除 Alist 源文件
+func (h *Handler) HandleFileDownloadAndDelete(chatID, userID int64, root, filePath string) {
+	h.handleDownloadFileByPath(chatID, userID, root, contracts.FileDownloadRequest{FilePath: filePath, AutoClassify: true, DeleteAfterDownload: true})
+}
 
-	req := contracts.FileDownloadRequest{
-		FilePath:     filePath,
-		AutoClassify: true,
-	}
+// HandleFileDownloadWithSubtitles 下载视频，同时下载同目录中同名的字幕文件
+func (h *Handler) HandleFileDownloadWithSubtitles(chatID, userID int64, root, filePath string) {
+	h.handleDownloadFileByPath(chatID, userID, root, contracts.FileDownloadRequest{FilePath: filePath, AutoClassify: true, DownloadSubtitles: true})
+}
+
+// handleDownloadFileByPath 通过路径下载单个文件（userID 用于选择用户专属下载目录，指定文件名时不自动分类）
+// root 为文件所在的 alist.roots 根目录名称，“返回目录”按钮限定在该根目录内浏览
+func (h *Handler) handleDownloadFileByPath(chatID, userID int64, root string, req contracts.FileDownloadRequest) {
+	ctx := contracts.WithUserID(context.Background(), userID)
+	filePath := req.FilePath
 
 	msgUtils := h.deps.GetMessageUtils()
 
@@ -46,12 +56,23 @@ func (h *Handler) handleDownloadFileByPath(chatID int64, filePath string) {
 		Size:         msgUtils.FormatFileSize(response.TotalSize),
 		EscapeHTML:   msgUtils.EscapeHTML,
 	})
+	if req.DeleteAfterDownload {
+		message += "\n\n🗑️ 下载完成且大小校验通过后将删除源文件"
+	}
+	if req.DownloadSubtitles {
+		if len(response.Subtitles) == 0 {
+			message += "\n\n💬 源目录中没有找到同名字幕文件"
+		} else {
+			message += fmt.Sprintf("\n\n💬 同时下载 %d 个字幕：<code>%s</code>",
+				len(response.Subtitles), msgUtils.EscapeHTML(strings.Join(response.Subtitles, ", ")))
+		}
+	}
 
 	parentDir := filepath.Dir(filePath)
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("📥 下载管理", "download_list"),
-			tgbotapi.NewInlineKeyboardButtonData("📁 返回目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(parentDir), 1)),
+			tgbotapi.NewInlineKeyboardButtonData("📁 返回目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, parentDir), 1)),
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
@@ -62,52 +83,180 @@ func (h *Handler) handleDownloadFileByPath(chatID int64, filePath string) {
 }
 
 // HandleDownloadDirectory 处理目录下载
-func (h *Handler) HandleDownloadDirectory(chatID int64, dirPath string) {
-	h.handleDownloadDirectoryByPath(chatID, dirPath)
+func (h *Handler) HandleDownloadDirectory(chatID, userID int64, dirPath string) {
+	h.handleDownloadDirectoryByPath(chatID, userID, dirPath)
 }
 
-// HandleDownloadDirectoryConfirm 显示下载目录确认对话框（发送新消息，保留主菜单）
-func (h *Handler) HandleDownloadDirectoryConfirm(chatID int64, dirPath string, _ int) {
+// directoryDownloadTTL 目录下载确认的有效期，过期后需重新统计
+const directoryDownloadTTL = 10 * time.Minute
+
+// directoryDownloadContext 等待确认的目录下载，确认时按预览时的请求创建任务
+type directoryDownloadContext struct {
+	ChatID    int64
+	Request   contracts.DirectoryDownloadRequest
+	CreatedAt time.Time
+}
+
+// HandleDownloadDirectoryConfirm 统计目录中将要下载的文件数和总大小，确认后才创建下载任务
+// messageID 为 0 时发送新消息（保留主菜单），否则在该消息上编辑
+func (h *Handler) HandleDownloadDirectoryConfirm(chatID int64, dirPath string, messageID int) {
 	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	if messageID == 0 {
+		messageID = msgUtils.SendMessageWithKeyboard(chatID, "⏳ 正在统计目录中的文件...", "HTML", nil)
+	} else {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在统计目录中的文件...", "HTML", nil)
+	}
+
+	req := h.directoryDownloadRequest(chatID, dirPath)
+	req.Preview = true
+	preview, err := h.deps.GetFileService().DownloadDirectory(context.Background(), req)
+	if err != nil {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, formatter.FormatError("统计目录文件", err), "HTML", nil)
+		msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
+		return
+	}
+	req.Preview = false
+	token := h.storeDirectoryDownload(chatID, req)
 
 	message := "<b>📥 确认下载目录</b>\n\n"
-	message += fmt.Sprintf("📂 目录: <code>%s</code>\n\n", msgUtils.EscapeHTML(dirPath))
-	message += "⚠️ 将下载该目录下的所有视频文件（递归2层）\n\n"
-	message += "是否确认下载？"
+	message += fmt.Sprintf("📂 目录: <code>%s</code>\n", msgUtils.EscapeHTML(dirPath))
+	message += fmt.Sprintf("📊 文件: %d 个，共 %s\n\n", preview.Summary.TotalFiles, msgUtils.FormatFileSize(preview.Summary.TotalSize))
+	message += "⚠️ 将下载该目录下的所有视频文件（递归2层）\n"
+	if req.IncludeHidden {
+		message += "👁️ 已开启显示隐藏文件，隐藏文件和目录也会被下载\n"
+	}
+	checkpoint, hasCheckpoint := h.deps.GetFileService().GetDirectoryCheckpoint(dirPath)
+	if hasCheckpoint {
+		message += fmt.Sprintf("\n⏸ 上次下载在 %s 中断，已提交 %d/%d 个文件\n",
+			checkpoint.UpdatedAt.Format("01-02 15:04"), checkpoint.QueuedFiles, checkpoint.TotalFiles)
+		message += "「继续上次」只提交剩余文件，「确认」重新提交全部文件\n"
+	}
+	message += "\n"
+	if preview.Summary.TotalFiles == 0 {
+		message += "目录中没有可下载的视频文件"
+	} else {
+		message += fmt.Sprintf("是否确认下载？（%d 分钟内有效）", int(directoryDownloadTTL.Minutes()))
+	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ 确认下载", fmt.Sprintf("download_dir_confirm:%s", h.deps.EncodeFilePath(dirPath))),
-			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", "download_dir_cancel"),
-		),
+	confirmRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✖️ 取消", fmt.Sprintf("download_dir_cancel:%s", token)),
 	)
+	if preview.Summary.TotalFiles > 0 {
+		label := fmt.Sprintf("✅ 确认 (%d 个文件, %s)", preview.Summary.TotalFiles, msgUtils.FormatFileSize(preview.Summary.TotalSize))
+		confirmRow = append([]tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("download_dir_confirm:%s", token)),
+		}, confirmRow...)
+	}
+	keyboardRows := [][]tgbotapi.InlineKeyboardButton{confirmRow}
+	if hasCheckpoint {
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏯ 继续上次", fmt.Sprintf("download_dir_resume:%s", h.deps.EncodeFilePath(dirPath))),
+		))
+	}
+	// 部署支持服务端打包时整个目录只创建一个任务（点击后探测，不支持时改为逐个文件下载）
+	if h.deps.GetConfig().Alist.ZipDownloadURL != "" {
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📦 打包下载", fmt.Sprintf("download_dir_zip:%s", h.deps.EncodeFilePath(dirPath))),
+		))
+	}
+	// 下载后删除源文件（需配置开启，回调中校验管理员权限）
+	if h.deps.GetConfig().Download.AllowDeleteAfterDownload && preview.Summary.TotalFiles > 0 {
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥🗑️ 下载后删除源文件", fmt.Sprintf("download_dir_delete:%s", h.deps.EncodeFilePath(dirPath))),
+		))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
 
-	msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
 }
 
-// HandleDownloadDirectoryExecute 执行目录下载
-func (h *Handler) HandleDownloadDirectoryExecute(chatID int64, dirPath string, messageID int) {
+// HandleDownloadDirectoryExecute 按令牌对应的确认请求执行目录下载
+func (h *Handler) HandleDownloadDirectoryExecute(chatID, userID int64, token string, messageID int) {
 	msgUtils := h.deps.GetMessageUtils()
+
+	pending, ok := h.takeDirectoryDownload(token)
+	if !ok {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, "⌛ 确认已过期，请重新选择要下载的目录", "HTML", nil)
+		msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
+		return
+	}
+	if pending.ChatID != chatID {
+		msgUtils.SendMessage(chatID, "无效的确认请求")
+		return
+	}
+
 	msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在处理下载任务...", "HTML", nil)
-	h.handleDownloadDirectoryByPathWithEdit(chatID, dirPath, messageID)
+	h.handleDownloadDirectoryByPathWithEdit(chatID, userID, messageID, pending.Request)
+}
+
+// HandleDownloadDirectoryCancel 取消等待确认的目录下载并删除确认消息
+func (h *Handler) HandleDownloadDirectoryCancel(chatID int64, token string, messageID int) {
+	h.takeDirectoryDownload(token)
+	h.deps.GetMessageUtils().DeleteMessage(chatID, messageID)
+}
+
+// storeDirectoryDownload 保存等待确认的目录下载请求并返回令牌，同时清理过期的请求
+func (h *Handler) storeDirectoryDownload(chatID int64, req contracts.DirectoryDownloadRequest) string {
+	now := time.Now()
+	token := fmt.Sprintf("dd-%d-%d", chatID, now.UnixNano())
+
+	h.dirDownloadMu.Lock()
+	defer h.dirDownloadMu.Unlock()
+	for key, pending := range h.dirDownloads {
+		if now.Sub(pending.CreatedAt) > directoryDownloadTTL {
+			delete(h.dirDownloads, key)
+		}
+	}
+	h.dirDownloads[token] = &directoryDownloadContext{ChatID: chatID, Request: req, CreatedAt: now}
+	return token
+}
+
+// takeDirectoryDownload 取出并删除令牌对应的目录下载请求，不存在或已过期时返回 false
+func (h *Handler) takeDirectoryDownload(token string) (*directoryDownloadContext, bool) {
+	h.dirDownloadMu.Lock()
+	defer h.dirDownloadMu.Unlock()
+	pending, ok := h.dirDownloads[token]
+	if !ok {
+		return nil, false
+	}
+	delete(h.dirDownloads, token)
+	if time.Since(pending.CreatedAt) > directoryDownloadTTL {
+		return nil, false
+	}
+	return pending, true
+}
+
+// HandleDownloadDirectoryResumeExecute 从上次中断处继续目录下载，只提交断点之后剩余的文件
+func (h *Handler) HandleDownloadDirectoryResumeExecute(chatID, userID int64, dirPath string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在继续上次的下载...", "HTML", nil)
+	req := h.directoryDownloadRequest(chatID, dirPath)
+	req.Resume = true
+	h.handleDownloadDirectoryByPathWithEdit(chatID, userID, messageID, req)
+}
+
+// HandleDownloadDirectoryAndDeleteExecute 执行目录下载，完成并校验后删除 Alist 源文件
+func (h *Handler) HandleDownloadDirectoryAndDeleteExecute(chatID, userID int64, dirPath string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在处理下载任务...", "HTML", nil)
+	req := h.directoryDownloadRequest(chatID, dirPath)
+	req.DeleteAfterDownload = true
+	h.handleDownloadDirectoryByPathWithEdit(chatID, userID, messageID, req)
 }
 
 // handleDownloadDirectoryByPath 通过路径下载目录
-func (h *Handler) handleDownloadDirectoryByPath(chatID int64, dirPath string) {
-	ctx := context.Background()
+func (h *Handler) handleDownloadDirectoryByPath(chatID, userID int64, dirPath string) {
+	ctx := contracts.WithUserID(context.Background(), userID)
 
 	msgUtils := h.deps.GetMessageUtils()
 	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
 	processingMsg := formatter.FormatTitle("⏳", "正在处理手动下载任务") + "\n\n" +
 		formatter.FormatField("目录路径", dirPath)
-	msgUtils.SendMessageHTMLWithAutoDelete(chatID, processingMsg, 30)
+	msgUtils.SendMessageHTMLWithAutoDelete(chatID, processingMsg, types.MessageTransient)
 
-	req := contracts.DirectoryDownloadRequest{
-		DirectoryPath: dirPath,
-		Recursive:     true,
-		VideoOnly:     true,
-		AutoClassify:  true,
-	}
+	req := h.directoryDownloadRequest(chatID, dirPath)
 
 	result, err := h.deps.GetFileService().DownloadDirectory(ctx, req)
 	if err != nil {
@@ -116,12 +265,16 @@ func (h *Handler) handleDownloadDirectoryByPath(chatID int64, dirPath string) {
 	}
 
 	if result.SuccessCount == 0 {
-		if result.Summary.VideoFiles == 0 {
-			message := formatter.FormatNoFilesFound("手动下载完成", dirPath)
-			msgUtils.SendMessageHTML(chatID, message)
-		} else {
-			msgUtils.SendMessage(chatID, formatter.FormatSimpleError("所有文件下载创建失败，请检查日志"))
+		if result.FailureCount == 0 {
+			msgUtils.SendMessageHTML(chatID, formatter.FormatNoFilesFound("手动下载完成", dirPath))
+			return
+		}
+		message := formatter.FormatSimpleError("所有文件下载创建失败，请检查日志")
+		if keyboard := utils.BatchRetryKeyboard(result.BatchID, result.FailureCount); keyboard != nil {
+			msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", keyboard)
+			return
 		}
+		msgUtils.SendMessage(chatID, message)
 		return
 	}
 
@@ -137,39 +290,62 @@ func (h *Handler) handleDownloadDirectoryByPath(chatID int64, dirPath string) {
 		FailCount:       result.FailureCount,
 		EscapeHTML:      msgUtils.EscapeHTML,
 	})
+	if note := utils.BatchChunkNote(result.PendingCount, result.ChunkCount); note != "" {
+		message += "\n\n" + note
+	}
 
-	msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, 30)
+	// 有失败文件时保留消息并提供批量重试
+	if keyboard := utils.BatchRetryKeyboard(result.BatchID, result.FailureCount); keyboard != nil {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", keyboard)
+		return
+	}
+	msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageImportant)
 }
 
-// handleDownloadDirectoryByPathWithEdit 下载目录并在指定消息上编辑显示结果
-func (h *Handler) handleDownloadDirectoryByPathWithEdit(chatID int64, dirPath string, messageID int) {
-	ctx := context.Background()
-	msgUtils := h.deps.GetMessageUtils()
-	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
-
-	req := contracts.DirectoryDownloadRequest{
+// directoryDownloadRequest 构建手动下载目录的默认请求
+func (h *Handler) directoryDownloadRequest(chatID int64, dirPath string) contracts.DirectoryDownloadRequest {
+	return contracts.DirectoryDownloadRequest{
 		DirectoryPath: dirPath,
 		Recursive:     true,
 		VideoOnly:     true,
 		AutoClassify:  true,
+		IncludeHidden: h.ShowHiddenFiles(chatID),
+		NotifyChatID:  chatID,
 	}
+}
+
+// handleDownloadDirectoryByPathWithEdit 下载目录并在指定消息上编辑显示结果（继续下载、下载后删除、日期筛选由请求决定）
+func (h *Handler) handleDownloadDirectoryByPathWithEdit(chatID, userID int64, messageID int, req contracts.DirectoryDownloadRequest) {
+	ctx := contracts.WithUserID(context.Background(), userID)
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	dirPath := req.DirectoryPath
 
 	result, err := h.deps.GetFileService().DownloadDirectory(ctx, req)
 	if err != nil {
 		msgUtils.EditMessageWithKeyboard(chatID, messageID, formatter.FormatError("处理", err), "HTML", nil)
-		msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+		msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
 		return
 	}
 
 	if result.SuccessCount == 0 {
-		var message string
-		if result.Summary.VideoFiles == 0 {
-			message = formatter.FormatNoFilesFound("手动下载完成", dirPath)
-		} else {
-			message = formatter.FormatSimpleError("所有文件下载创建失败，请检查日志")
+		if result.FailureCount == 0 && result.ResumedCount > 0 {
+			msgUtils.EditMessageWithKeyboard(chatID, messageID, resumedSummary(formatter, result), "HTML", nil)
+			msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageImportant)
+			return
+		}
+		if result.FailureCount == 0 {
+			msgUtils.EditMessageWithKeyboard(chatID, messageID, formatter.FormatNoFilesFound("手动下载完成", dirPath), "HTML", nil)
+			msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
+			return
+		}
+		message := formatter.FormatSimpleError("所有文件下载创建失败，请检查日志")
+		if keyboard := utils.BatchRetryKeyboard(result.BatchID, result.FailureCount); keyboard != nil {
+			msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", keyboard)
+			return
 		}
 		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", nil)
-		msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+		msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
 		return
 	}
 
@@ -185,7 +361,27 @@ func (h *Handler) handleDownloadDirectoryByPathWithEdit(chatID int64, dirPath st
 		FailCount:       result.FailureCount,
 		EscapeHTML:      msgUtils.EscapeHTML,
 	})
+	if note := utils.BatchChunkNote(result.PendingCount, result.ChunkCount); note != "" {
+		message += "\n\n" + note
+	}
+	if result.ResumedCount > 0 {
+		message += "\n\n" + resumedSummary(formatter, result)
+	}
 
+	// 有失败文件时保留消息并提供批量重试
+	if keyboard := utils.BatchRetryKeyboard(result.BatchID, result.FailureCount); keyboard != nil {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", keyboard)
+		return
+	}
 	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", nil)
-	msgUtils.DeleteMessageAfterDelay(chatID, messageID, 30)
+	msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageImportant)
+}
+
+// resumedSummary 说明从断点继续时跳过与新提交的文件数
+func resumedSummary(formatter *utils.MessageFormatter, result *contracts.BatchDownloadResponse) string {
+	if result.SuccessCount == 0 && result.FailureCount == 0 {
+		return formatter.FormatTitle("⏯", "继续上次的下载") + "\n\n" +
+			fmt.Sprintf("上次已提交全部 %d 个文件，没有剩余文件需要下载", result.ResumedCount)
+	}
+	return fmt.Sprintf("⏯ 从上次中断处继续：跳过已提交的 %d 个文件，本次新提交 %d 个", result.ResumedCount, result.SuccessCount)
 }
@@ -0,0 +1,101 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 目录打包下载
+// ================================
+
+// HandleDownloadDirectoryZipConfirm 探测目录能否打包下载，显示压缩包大小并确认；不支持时提供逐个文件下载
+func (h *Handler) HandleDownloadDirectoryZipConfirm(chatID int64, dirPath string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	encodedPath := h.deps.EncodeFilePath(dirPath)
+
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在检查打包下载...", "HTML", nil)
+
+	zip, err := h.deps.GetFileService().ProbeDirectoryZip(context.Background(), dirPath)
+	if err != nil {
+		message := formatter.FormatError("检查打包下载", err)
+		if errors.Is(err, contracts.ErrDirectoryZipUnsupported) {
+			message = formatter.FormatTitle("📦", "不支持打包下载") + "\n\n" +
+				formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(dirPath)) + "\n\n" +
+				"Alist 无法为该目录生成压缩包，可改为逐个文件下载"
+		}
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("📥 逐个文件下载", fmt.Sprintf("download_dir_preview:%s", encodedPath)),
+				tgbotapi.NewInlineKeyboardButtonData("❌ 取消", "download_dir_cancel"),
+			),
+		)
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+		return
+	}
+
+	message := formatter.FormatTitle("📦", "确认打包下载") + "\n\n" +
+		formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(dirPath)) + "\n" +
+		formatter.FormatFieldCode("文件名", msgUtils.EscapeHTML(zip.FileName)) + "\n" +
+		formatter.FormatField("大小", h.zipSizeText(zip.Size)) + "\n\n" +
+		"整个目录作为一个压缩包下载，只创建一个下载任务（不区分视频，包含目录中的所有文件）\n\n" +
+		"是否确认下载？"
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ 确认打包下载", fmt.Sprintf("download_dir_zip_confirm:%s", encodedPath)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", "download_dir_cancel"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 改为逐个文件下载", fmt.Sprintf("download_dir_preview:%s", encodedPath)),
+		),
+	)
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+}
+
+// HandleDownloadDirectoryZipExecute 将目录压缩包作为单个任务提交下载
+func (h *Handler) HandleDownloadDirectoryZipExecute(chatID, userID int64, dirPath string, messageID int) {
+	ctx := contracts.WithUserID(context.Background(), userID)
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在创建打包下载任务...", "HTML", nil)
+
+	response, err := h.deps.GetFileService().DownloadDirectoryZip(ctx, dirPath)
+	if err != nil {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, formatter.FormatError("创建打包下载任务", err), "HTML", nil)
+		msgUtils.DeleteMessageAfterDelay(chatID, messageID, types.MessageTransient)
+		return
+	}
+
+	message := formatter.FormatFileDownloadSuccess(utils.FileDownloadSuccessData{
+		Filename:     response.Filename,
+		FilePath:     dirPath,
+		DownloadPath: response.Directory,
+		TaskID:       response.ID,
+		Size:         h.zipSizeText(response.TotalSize),
+		EscapeHTML:   msgUtils.EscapeHTML,
+	})
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载管理", "download_list"),
+			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
+		),
+	)
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+}
+
+// zipSizeText 压缩包大小，服务端实时打包时大小未知
+func (h *Handler) zipSizeText(size int64) string {
+	if size <= 0 {
+		return "未知（服务端实时打包）"
+	}
+	return h.deps.GetMessageUtils().FormatFileSize(size)
+}
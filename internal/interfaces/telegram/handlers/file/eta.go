@@ -0,0 +1,84 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	timeutil "github.com/easayliu/alist-aria2-download/pkg/utils/time"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 下载耗时估算
+// ================================
+
+// HandleETA 处理 /eta 命令，按 aria2 当前速度估算下载整个目录的耗时
+func (h *Handler) HandleETA(chatID int64, dirPath string) {
+	ctx := context.Background()
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	if strings.TrimSpace(dirPath) == "" {
+		msgUtils.SendMessageHTML(chatID, "使用方式：<code>/eta &lt;路径&gt;</code>")
+		return
+	}
+	dirPath = NormalizePinPath(dirPath)
+
+	msgUtils.SendMessage(chatID, "⏳ 正在统计目录大小...")
+
+	// 与“下载目录”按钮使用同一请求预览，统计实际会下载的文件
+	req := h.directoryDownloadRequest(chatID, dirPath)
+	req.Preview = true
+	preview, err := h.deps.GetFileService().DownloadDirectory(ctx, req)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("统计目录大小", err))
+		return
+	}
+	totalSize := preview.Summary.TotalSize
+
+	currentSpeed, err := h.deps.GetDownloadService().GetCurrentSpeed(ctx)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("获取当前下载速度", err))
+		return
+	}
+
+	cfg := h.deps.GetConfig()
+	speedStr := msgUtils.FormatFileSize(currentSpeed) + "/s"
+	eta := "无法估算"
+	switch {
+	case totalSize == 0:
+		eta = "无需下载"
+	case currentSpeed > 0:
+		eta = timeutil.FormatDuration(estimateDownloadDuration(totalSize, currentSpeed))
+	case cfg.Download.Bandwidth.TypicalSpeedMB > 0:
+		typicalSpeed := int64(cfg.Download.Bandwidth.TypicalSpeedMB) * 1024 * 1024
+		speedStr = fmt.Sprintf("0 B/s（按典型速度 %s/s 估算）", msgUtils.FormatFileSize(typicalSpeed))
+		eta = timeutil.FormatDuration(estimateDownloadDuration(totalSize, typicalSpeed))
+	}
+
+	message := formatter.FormatTitle("⏱️", "下载耗时估算") + "\n\n" +
+		formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(dirPath)) + "\n" +
+		formatter.FormatField("文件数", fmt.Sprintf("%d", preview.Summary.TotalFiles)) + "\n" +
+		formatter.FormatField("总大小", msgUtils.FormatFileSize(totalSize)) + "\n" +
+		formatter.FormatField("当前速度", speedStr) + "\n" +
+		formatter.FormatField("预计耗时", eta)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载目录", fmt.Sprintf("download_dir:%s", h.deps.EncodeFilePath(dirPath))),
+			tgbotapi.NewInlineKeyboardButtonData("📁 浏览目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(dirPath), 1)),
+		),
+	)
+	msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+}
+
+// estimateDownloadDuration 按给定速度(B/s)估算下载耗时
+func estimateDownloadDuration(size, speed int64) time.Duration {
+	if speed <= 0 {
+		return 0
+	}
+	return time.Duration(float64(size) / float64(speed) * float64(time.Second))
+}
@@ -5,6 +5,7 @@ package file
 import (
 	"context"
 	"path/filepath"
+	"sync"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 )
@@ -12,12 +13,26 @@ import (
 // Handler 文件浏览处理器
 type Handler struct {
 	deps FileDeps
+
+	viewMu      sync.Mutex
+	showHidden  map[int64]bool // chatID -> 是否显示隐藏文件（会话内有效，重启后恢复配置默认值）
+	groupBrowse map[int64]bool // chatID -> 是否按类型分组浏览（会话内有效，重启后恢复配置默认值）
+
+	searchMu sync.Mutex
+	searches map[searchKey]*searchSession // 结果消息 -> 搜索结果，用于翻页
+
+	dirDownloadMu sync.Mutex
+	dirDownloads  map[string]*directoryDownloadContext // 令牌 -> 等待确认的目录下载
 }
 
 // NewHandler 创建文件处理器
 func NewHandler(deps FileDeps) *Handler {
 	return &Handler{
-		deps: deps,
+		deps:         deps,
+		showHidden:   make(map[int64]bool),
+		groupBrowse:  make(map[int64]bool),
+		searches:     make(map[searchKey]*searchSession),
+		dirDownloads: make(map[string]*directoryDownloadContext),
 	}
 }
 
@@ -36,18 +51,25 @@ func (h *Handler) BuildFullPath(file contracts.FileResponse, basePath string) st
 	return basePath + "/" + file.Name
 }
 
-// ListFilesSimple 简单列出文件
+// ListFilesSimple 简单列出文件（不含隐藏文件）
 func (h *Handler) ListFilesSimple(path string, page, perPage int) ([]contracts.FileResponse, error) {
+	items, _, err := h.listFiles(path, page, perPage, false)
+	return items, err
+}
+
+// listFiles 列出目录和文件，返回被过滤的隐藏项数量
+func (h *Handler) listFiles(path string, page, perPage int, includeHidden bool) ([]contracts.FileResponse, int, error) {
 	req := contracts.FileListRequest{
-		Path:     path,
-		Page:     page,
-		PageSize: perPage,
+		Path:          path,
+		Page:          page,
+		PageSize:      perPage,
+		IncludeHidden: includeHidden,
 	}
 
 	ctx := context.Background()
 	resp, err := h.deps.GetFileService().ListFiles(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	// 合并文件和目录
@@ -55,7 +77,45 @@ func (h *Handler) ListFilesSimple(path string, page, perPage int) ([]contracts.F
 	allItems = append(allItems, resp.Directories...)
 	allItems = append(allItems, resp.Files...)
 
-	return allItems, nil
+	return allItems, resp.Summary.HiddenItems, nil
+}
+
+// ShowHiddenFiles 当前会话是否显示隐藏文件，未切换过时使用配置默认值
+func (h *Handler) ShowHiddenFiles(chatID int64) bool {
+	h.viewMu.Lock()
+	defer h.viewMu.Unlock()
+	if show, ok := h.showHidden[chatID]; ok {
+		return show
+	}
+	return h.deps.GetConfig().Alist.ShowHiddenFiles
+}
+
+// ToggleHiddenFiles 切换当前会话的隐藏文件显示状态，返回切换后的状态
+func (h *Handler) ToggleHiddenFiles(chatID int64) bool {
+	show := !h.ShowHiddenFiles(chatID)
+	h.viewMu.Lock()
+	h.showHidden[chatID] = show
+	h.viewMu.Unlock()
+	return show
+}
+
+// GroupBrowse 当前会话是否按类型分组浏览，未切换过时使用配置默认值
+func (h *Handler) GroupBrowse(chatID int64) bool {
+	h.viewMu.Lock()
+	defer h.viewMu.Unlock()
+	if grouped, ok := h.groupBrowse[chatID]; ok {
+		return grouped
+	}
+	return h.deps.GetConfig().Alist.GroupBrowse
+}
+
+// ToggleGroupBrowse 切换当前会话的分组浏览状态，返回切换后的状态
+func (h *Handler) ToggleGroupBrowse(chatID int64) bool {
+	grouped := !h.GroupBrowse(chatID)
+	h.viewMu.Lock()
+	h.groupBrowse[chatID] = grouped
+	h.viewMu.Unlock()
+	return grouped
 }
 
 // GetFileDownloadURL 获取文件下载 URL
@@ -0,0 +1,63 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+)
+
+// maxHistoryShown 文件信息中最多显示的下载记录数
+const maxHistoryShown = 5
+
+// formatDownloadHistory 格式化文件的下载历史，无记录时返回空字符串
+func (h *Handler) formatDownloadHistory(filePath string) string {
+	historyRepo := h.deps.GetDownloadHistoryRepository()
+	if historyRepo == nil {
+		return ""
+	}
+	records := historyRepo.GetBySourcePath(filePath)
+	if len(records) == 0 {
+		return ""
+	}
+
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	var lines []string
+	lines = append(lines, "", formatter.FormatSection(fmt.Sprintf("下载记录（%d次）", len(records))))
+	for i, record := range records {
+		if i >= maxHistoryShown {
+			lines = append(lines, fmt.Sprintf("… 还有 %d 条更早的记录", len(records)-maxHistoryShown))
+			break
+		}
+		line := fmt.Sprintf("%s %s %s",
+			historyStatusEmoji(record.Status),
+			record.CreatedAt.Format("2006-01-02 15:04"),
+			record.Status.ChineseName())
+		if record.Directory != "" {
+			line += fmt.Sprintf("\n    → <code>%s</code>", msgUtils.EscapeHTML(record.Directory))
+		}
+		if record.ErrorMessage != "" {
+			line += "\n    " + msgUtils.EscapeHTML(record.ErrorMessage)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// historyStatusEmoji 下载记录状态图标
+func historyStatusEmoji(status valueobjects.DownloadStatus) string {
+	switch status {
+	case valueobjects.DownloadStatusComplete:
+		return "✅"
+	case valueobjects.DownloadStatusError:
+		return "❌"
+	case valueobjects.DownloadStatusRemoved:
+		return "🗑️"
+	default:
+		return "⏳"
+	}
+}
@@ -0,0 +1,221 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
+)
+
+// ================================
+// 目录媒体清单
+// ================================
+
+const (
+	// maxDocumentCaptionLength Telegram 文件说明的最大长度
+	maxDocumentCaptionLength = 1024
+	// maxInventoryTopLevelShown 摘要中显示的一级子目录数
+	maxInventoryTopLevelShown = 5
+)
+
+// inventoryCategoryLabels 分类显示名称
+var inventoryCategoryLabels = map[string]string{
+	"movie":    "电影",
+	"tv":       "电视剧",
+	"variety":  "综艺",
+	"video":    "其他视频",
+	"music":    "音乐",
+	"document": "文档",
+	"other":    "非视频",
+}
+
+// HandleInventory 处理 /inventory [路径] 命令，扫描目录并以 CSV 文件发送媒体清单（不创建下载）
+// 未指定路径时扫描默认目录
+func (h *Handler) HandleInventory(chatID int64, dirPath string) {
+	ctx := context.Background()
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	if strings.TrimSpace(dirPath) == "" {
+		dirPath = h.deps.GetConfig().Alist.DefaultPath
+	}
+	dirPath = NormalizePinPath(dirPath)
+
+	msgUtils.SendMessageWithAutoDelete(chatID, "⏳ 正在扫描目录，文件较多时需要一些时间...", types.MessageTransient)
+
+	report, err := h.deps.GetFileService().ScanInventory(ctx, contracts.InventoryRequest{Path: dirPath})
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("扫描目录", err))
+		return
+	}
+
+	data, err := buildInventoryCSV(report)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("生成清单", err))
+		return
+	}
+
+	fileName := fmt.Sprintf("inventory_%s_%s.csv", inventoryFileLabel(dirPath), report.ScannedAt.Format("20060102_150405"))
+	summary := formatInventorySummary(formatter, msgUtils.EscapeHTML, report)
+	if utf8.RuneCountInString(summary) > maxDocumentCaptionLength {
+		// 超出文件说明长度限制时单独发送摘要
+		msgUtils.SendMessageHTML(chatID, summary)
+		summary = ""
+	}
+	msgUtils.SendDocument(chatID, fileName, data, summary)
+}
+
+// formatInventorySummary 格式化清单摘要（作为文件说明发送）
+func formatInventorySummary(formatter *utils.MessageFormatter, escapeHTML func(string) string, report *contracts.InventoryReport) string {
+	lines := []string{
+		formatter.FormatTitle("📋", "目录媒体清单"),
+		"",
+		formatter.FormatFieldCode("目录", escapeHTML(report.Path)),
+		formatter.FormatField("文件", fmt.Sprintf("%d 个（%s），子目录 %d 个", report.TotalFiles, strutil.FormatFileSize(report.TotalSize), report.TotalDirs)),
+	}
+
+	categories := make([]string, 0, len(report.Categories))
+	for category := range report.Categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		stat := report.Categories[category]
+		lines = append(lines, formatter.FormatListItem("•", fmt.Sprintf("%s: %d 个，%s", inventoryCategoryLabel(category), stat.Count, strutil.FormatFileSize(stat.Size))))
+	}
+
+	lines = append(lines, formatter.FormatField("识别", fmt.Sprintf("剧集 %d 部，电影 %d 部，未识别 %d 个文件", len(report.Shows), len(report.Movies), len(report.Unclassified))))
+
+	if len(report.TopLevel) > 1 {
+		lines = append(lines, formatter.FormatSection("子目录占比"))
+		for i, dir := range report.TopLevel {
+			if i == maxInventoryTopLevelShown {
+				lines = append(lines, formatter.FormatListItem("•", fmt.Sprintf("... 还有 %d 个", len(report.TopLevel)-i)))
+				break
+			}
+			lines = append(lines, formatter.FormatListItem("•", fmt.Sprintf("%s: %d 个，%s（%s）",
+				escapeHTML(inventoryDirLabel(dir.Name)), dir.Count, strutil.FormatFileSize(dir.Size), inventoryShare(dir.Size, report.TotalSize))))
+		}
+	}
+
+	if len(report.FailedDirs) > 0 {
+		lines = append(lines, fmt.Sprintf("⚠️ %d 个目录读取失败，详见清单", len(report.FailedDirs)))
+	}
+	if report.Truncated {
+		lines = append(lines, fmt.Sprintf("⚠️ 已达到扫描上限（%d 个文件），结果不完整", report.TotalFiles))
+	}
+	if report.DepthLimited {
+		lines = append(lines, "⚠️ 已达到最大目录层数，更深的子目录未统计")
+	}
+	if report.Cached {
+		lines = append(lines, fmt.Sprintf("ℹ️ 结果来自 %s 的扫描", report.ScannedAt.Format("15:04:05")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// buildInventoryCSV 生成清单 CSV（带 BOM，便于 Excel 正确识别中文）
+func buildInventoryCSV(report *contracts.InventoryReport) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+
+	w := csv.NewWriter(&buf)
+	rows := [][]string{{"类型", "名称", "季/年份", "数量", "大小(字节)", "大小", "路径"}}
+
+	categories := make([]string, 0, len(report.Categories))
+	for category := range report.Categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		stat := report.Categories[category]
+		rows = append(rows, inventoryRow("分类", inventoryCategoryLabel(category), "", stat.Count, stat.Size, ""))
+	}
+
+	for _, dir := range report.TopLevel {
+		rows = append(rows, inventoryRow("子目录", inventoryDirLabel(dir.Name), "", dir.Count, dir.Size, ""))
+	}
+
+	for _, show := range report.Shows {
+		seasons := make([]string, len(show.Seasons))
+		for i, season := range show.Seasons {
+			seasons[i] = fmt.Sprintf("S%02d", season)
+		}
+		rows = append(rows, inventoryRow("剧集", show.Title, strings.Join(seasons, " "), show.Episodes, show.Size, ""))
+	}
+
+	for _, movie := range report.Movies {
+		year := ""
+		if movie.Year > 0 {
+			year = strconv.Itoa(movie.Year)
+		}
+		rows = append(rows, inventoryRow("电影", movie.Title, year, movie.Files, movie.Size, ""))
+	}
+
+	for _, file := range report.Unclassified {
+		rows = append(rows, inventoryRow("未识别", file.Name, "", 1, file.Size, file.Path))
+	}
+
+	for _, dir := range report.FailedDirs {
+		rows = append(rows, []string{"读取失败", "", "", "", "", "", dir})
+	}
+
+	if report.Truncated {
+		rows = append(rows, []string{"提示", fmt.Sprintf("已达到扫描上限（%d 个文件），结果不完整", report.TotalFiles), "", "", "", "", ""})
+	}
+	if report.DepthLimited {
+		rows = append(rows, []string{"提示", "已达到最大目录层数，更深的子目录未统计", "", "", "", "", ""})
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// inventoryRow 构建 CSV 数据行
+func inventoryRow(kind, name, detail string, count int, size int64, filePath string) []string {
+	return []string{kind, name, detail, strconv.Itoa(count), strconv.FormatInt(size, 10), strutil.FormatFileSize(size), filePath}
+}
+
+// inventoryCategoryLabel 获取分类显示名称
+func inventoryCategoryLabel(category string) string {
+	if label, ok := inventoryCategoryLabels[category]; ok {
+		return label
+	}
+	return category
+}
+
+// inventoryDirLabel 一级子目录显示名称，直接位于扫描目录下的文件显示为"（当前目录）"
+func inventoryDirLabel(name string) string {
+	if name == "" {
+		return "（当前目录）"
+	}
+	return name
+}
+
+// inventoryShare 格式化占总大小的百分比
+func inventoryShare(size, total int64) string {
+	if total <= 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.0f%%", float64(size)*100/float64(total))
+}
+
+// inventoryFileLabel 以目录名作为文件名的一部分
+func inventoryFileLabel(dirPath string) string {
+	name := path.Base(dirPath)
+	if name == "/" || name == "." || name == "" {
+		return "root"
+	}
+	return strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(name)
+}
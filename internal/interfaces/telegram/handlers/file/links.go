@@ -0,0 +1,91 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
+)
+
+// ================================
+// 目录批量直链
+// ================================
+
+// maxLinksMessageLength 单条消息的最大长度（Telegram 上限 4096，预留余量）
+const maxLinksMessageLength = 4000
+
+// HandleDirectoryLinks 获取目录下所有文件的直链，汇总为一条消息，超出长度时以文本文件发送
+func (h *Handler) HandleDirectoryLinks(chatID int64, dirPath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	msgUtils.SendMessageWithAutoDelete(chatID, "⏳ 正在获取目录中文件的链接...", types.MessageTransient)
+
+	resp, err := h.deps.GetFileService().GetDirectoryLinks(context.Background(), dirPath)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("获取链接", err))
+		return
+	}
+	if len(resp.Links) == 0 {
+		msgUtils.SendMessage(chatID, "该目录下没有文件（子目录中的文件请进入子目录获取）")
+		return
+	}
+
+	summary := formatDirectoryLinksSummary(formatter, msgUtils.EscapeHTML, resp)
+	message := summary + "\n\n" + formatDirectoryLinksHTML(msgUtils.EscapeHTML, resp.Links)
+	if utf8.RuneCountInString(message) <= maxLinksMessageLength {
+		msgUtils.SendMessageHTML(chatID, message)
+		return
+	}
+
+	// 链接较多时以文本文件发送，避免拆分成多条消息
+	fileName := fmt.Sprintf("links_%s_%s.txt", inventoryFileLabel(dirPath), time.Now().Format("20060102_150405"))
+	msgUtils.SendDocument(chatID, fileName, buildDirectoryLinksText(resp), summary)
+}
+
+// formatDirectoryLinksSummary 格式化链接列表摘要
+func formatDirectoryLinksSummary(formatter *utils.MessageFormatter, escapeHTML func(string) string, resp *contracts.DirectoryLinksResponse) string {
+	var totalSize int64
+	for _, link := range resp.Links {
+		totalSize += link.Size
+	}
+
+	lines := []string{
+		formatter.FormatTitle("🔗", "目录文件链接"),
+		"",
+		formatter.FormatFieldCode("目录", escapeHTML(resp.Path)),
+		formatter.FormatField("文件", fmt.Sprintf("%d 个（%s）", len(resp.Links), strutil.FormatFileSize(totalSize))),
+	}
+	if resp.Truncated {
+		lines = append(lines, fmt.Sprintf("⚠️ 文件较多，仅列出前 %d 个", len(resp.Links)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatDirectoryLinksHTML 每个文件一行，文件名即为链接
+func formatDirectoryLinksHTML(escapeHTML func(string) string, links []contracts.FileLink) string {
+	lines := make([]string, len(links))
+	for i, link := range links {
+		lines[i] = fmt.Sprintf("%d. <a href=\"%s\">%s</a>", i+1, escapeHTML(link.URL), escapeHTML(link.Name))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// buildDirectoryLinksText 生成纯文本链接列表（文件名和链接各占一行）
+func buildDirectoryLinksText(resp *contracts.DirectoryLinksResponse) []byte {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s (%d files)\n\n", resp.Path, len(resp.Links)))
+	for _, link := range resp.Links {
+		sb.WriteString(link.Name)
+		sb.WriteString("\n")
+		sb.WriteString(link.URL)
+		sb.WriteString("\n\n")
+	}
+	return []byte(sb.String())
+}
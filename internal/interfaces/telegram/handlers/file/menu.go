@@ -1,11 +1,13 @@
 package file
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
 
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	qrutil "github.com/easayliu/alist-aria2-download/pkg/utils/qrcode"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
@@ -15,11 +17,12 @@ import (
 
 // HandleFileMenu 处理文件操作菜单
 func (h *Handler) HandleFileMenu(chatID int64, filePath string) {
-	h.HandleFileMenuWithEdit(chatID, filePath, 0)
+	h.HandleFileMenuWithEdit(chatID, "", filePath, 0)
 }
 
 // HandleFileMenuWithEdit 处理文件操作菜单（支持消息编辑）
-func (h *Handler) HandleFileMenuWithEdit(chatID int64, filePath string, messageID int) {
+// root 为浏览时所在的 alist.roots 根目录名称，菜单中的路径令牌都携带该根目录，返回浏览时仍限定在根目录内
+func (h *Handler) HandleFileMenuWithEdit(chatID int64, root, filePath string, messageID int) {
 	fileName := filepath.Base(filePath)
 	fileExt := strings.ToLower(filepath.Ext(fileName))
 
@@ -54,26 +57,45 @@ func (h *Handler) HandleFileMenuWithEdit(chatID int64, filePath string, messageI
 	var keyboardRows [][]tgbotapi.InlineKeyboardButton
 
 	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("📥 立即下载", fmt.Sprintf("file_download:%s", h.deps.EncodeFilePath(filePath))),
-		tgbotapi.NewInlineKeyboardButtonData("ℹ️ 文件信息", fmt.Sprintf("file_info:%s", h.deps.EncodeFilePath(filePath))),
+		tgbotapi.NewInlineKeyboardButtonData("📥 立即下载", fmt.Sprintf("file_download:%s", h.deps.EncodeRootFilePath(root, filePath))),
+		tgbotapi.NewInlineKeyboardButtonData("ℹ️ 文件信息", fmt.Sprintf("file_info:%s", h.deps.EncodeRootFilePath(root, filePath))),
 	))
+	saveAsRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✏️ 重命名后下载", fmt.Sprintf("file_saveas:%s", h.deps.EncodeRootFilePath(root, filePath))),
+	)
+	if isVideo {
+		saveAsRow = append(saveAsRow, tgbotapi.NewInlineKeyboardButtonData("📥 含字幕下载", fmt.Sprintf("file_download_subs:%s", h.deps.EncodeRootFilePath(root, filePath))))
+	}
+	keyboardRows = append(keyboardRows, saveAsRow)
 
-	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🔗 获取链接", fmt.Sprintf("file_link:%s", h.deps.EncodeFilePath(filePath))),
-	))
+	linkRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔗 获取链接", fmt.Sprintf("file_link:%s", h.deps.EncodeRootFilePath(root, filePath))),
+	)
+	if isVideo {
+		linkRow = append(linkRow, tgbotapi.NewInlineKeyboardButtonData("🔍 预览片段", fmt.Sprintf("file_sample:%s", h.deps.EncodeRootFilePath(root, filePath))))
+	}
+	keyboardRows = append(keyboardRows, linkRow)
+
+	// 下载后删除源文件（需配置开启，回调中校验管理员权限）
+	if h.deps.GetConfig().Download.AllowDeleteAfterDownload {
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥🗑️ 下载后删除源文件", fmt.Sprintf("file_download_delete:%s", h.deps.EncodeRootFilePath(root, filePath))),
+		))
+	}
 
 	if isVideo {
 		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✏️ 智能重命名", fmt.Sprintf("file_rename:%s", h.deps.EncodeFilePath(filePath))),
+			tgbotapi.NewInlineKeyboardButtonData("✏️ 智能重命名", fmt.Sprintf("file_rename:%s", h.deps.EncodeRootFilePath(root, filePath))),
+			tgbotapi.NewInlineKeyboardButtonData("📂 改类别", fmt.Sprintf("file_recat:%s", h.deps.EncodeRootFilePath(root, filePath))),
 		))
 	}
 
 	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🗑️ 删除文件", fmt.Sprintf("file_delete_confirm:%s", h.deps.EncodeFilePath(filePath))),
+		tgbotapi.NewInlineKeyboardButtonData("🗑️ 删除文件", fmt.Sprintf("file_delete_confirm:%s", h.deps.EncodeRootFilePath(root, filePath))),
 	))
 
 	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("📁 返回目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(h.GetParentPath(filePath)), 1)),
+		tgbotapi.NewInlineKeyboardButtonData("📁 返回目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, h.GetParentPath(filePath)), 1)),
 		tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
 	))
 
@@ -88,11 +110,11 @@ func (h *Handler) HandleFileMenuWithEdit(chatID int64, filePath string, messageI
 
 // HandleDirMenu 处理目录操作菜单
 func (h *Handler) HandleDirMenu(chatID int64, dirPath string) {
-	h.HandleDirMenuWithEdit(chatID, dirPath, 0)
+	h.HandleDirMenuWithEdit(chatID, "", dirPath, 0)
 }
 
 // HandleDirMenuWithEdit 处理目录操作菜单（支持消息编辑）
-func (h *Handler) HandleDirMenuWithEdit(chatID int64, dirPath string, messageID int) {
+func (h *Handler) HandleDirMenuWithEdit(chatID int64, root, dirPath string, messageID int) {
 	dirName := filepath.Base(dirPath)
 	if dirPath == "/" {
 		dirName = "根目录"
@@ -114,22 +136,27 @@ func (h *Handler) HandleDirMenuWithEdit(chatID int64, dirPath string, messageID
 	var keyboardRows [][]tgbotapi.InlineKeyboardButton
 
 	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("📂 进入目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(dirPath), 1)),
-		tgbotapi.NewInlineKeyboardButtonData("📥 下载目录", fmt.Sprintf("download_dir:%s", h.deps.EncodeFilePath(dirPath))),
+		tgbotapi.NewInlineKeyboardButtonData("📂 进入目录", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, dirPath), 1)),
+		tgbotapi.NewInlineKeyboardButtonData("📥 下载目录", fmt.Sprintf("download_dir:%s", h.deps.EncodeRootFilePath(root, dirPath))),
 	))
 
 	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("📝 批量重命名", fmt.Sprintf("batch_rename:%s", h.deps.EncodeFilePath(dirPath))),
+		tgbotapi.NewInlineKeyboardButtonData("📝 批量重命名", fmt.Sprintf("batch_rename:%s", h.deps.EncodeRootFilePath(root, dirPath))),
+		tgbotapi.NewInlineKeyboardButtonData("⭐ 收藏", fmt.Sprintf("pin_add:%s", h.deps.EncodeRootFilePath(root, dirPath))),
+	))
+
+	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔗 获取全部链接", fmt.Sprintf("dir_links:%s", h.deps.EncodeRootFilePath(root, dirPath))),
 	))
 
 	if dirPath != "/" {
 		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🗑️ 删除目录", fmt.Sprintf("dir_delete_confirm:%s", h.deps.EncodeFilePath(dirPath))),
+			tgbotapi.NewInlineKeyboardButtonData("🗑️ 删除目录", fmt.Sprintf("dir_delete_confirm:%s", h.deps.EncodeRootFilePath(root, dirPath))),
 		))
 	}
 
 	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("📁 返回上级", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(h.GetParentPath(dirPath)), 1)),
+		tgbotapi.NewInlineKeyboardButtonData("📁 返回上级", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, h.GetParentPath(dirPath)), 1)),
 		tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
 	))
 
@@ -144,11 +171,11 @@ func (h *Handler) HandleDirMenuWithEdit(chatID int64, dirPath string, messageID
 
 // HandleFileInfo 处理文件信息查看
 func (h *Handler) HandleFileInfo(chatID int64, filePath string) {
-	h.HandleFileInfoWithEdit(chatID, filePath, 0)
+	h.HandleFileInfoWithEdit(chatID, "", filePath, 0)
 }
 
 // HandleFileInfoWithEdit 处理文件信息查看（支持消息编辑）
-func (h *Handler) HandleFileInfoWithEdit(chatID int64, filePath string, messageID int) {
+func (h *Handler) HandleFileInfoWithEdit(chatID int64, root, filePath string, messageID int) {
 	msgUtils := h.deps.GetMessageUtils()
 	fileService := h.deps.GetFileService()
 
@@ -158,12 +185,13 @@ func (h *Handler) HandleFileInfoWithEdit(chatID int64, filePath string, messageI
 	}
 
 	// 获取文件信息
-	fileInfo, err := h.ListFilesSimple(filepath.Dir(filePath), 1, 1000)
+	// 包含隐藏文件，以便查看在浏览中显示出来的隐藏文件
+	fileInfo, _, err := h.listFiles(filepath.Dir(filePath), 1, 1000, true)
 	if err != nil {
 		message := "获取文件信息失败: " + err.Error()
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(filepath.Dir(filePath)), 1)),
+				tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, filepath.Dir(filePath)), 1)),
 			),
 		)
 		if messageID > 0 {
@@ -203,7 +231,7 @@ func (h *Handler) HandleFileInfoWithEdit(chatID int64, filePath string, messageI
 		message := "文件未找到"
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(filepath.Dir(filePath)), 1)),
+				tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, filepath.Dir(filePath)), 1)),
 			),
 		)
 		if messageID > 0 {
@@ -232,12 +260,18 @@ func (h *Handler) HandleFileInfoWithEdit(chatID int64, filePath string, messageI
 		IsDir:      targetFile.IsDir,
 		EscapeHTML: msgUtils.EscapeHTML,
 	}
+	if !targetFile.IsDir && fileType == "视频文件" && h.deps.GetConfig().Alist.ProbeMediaInfo {
+		infoData.Duration, infoData.Resolution = h.probeVideoMetadata(filePath, targetFile.Size)
+	}
 
 	message := formatter.FormatFileInfo(infoData)
+	if !targetFile.IsDir {
+		message += h.formatDownloadHistory(filePath)
+	}
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(filepath.Dir(filePath)), 1)),
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, filepath.Dir(filePath)), 1)),
 		),
 	)
 
@@ -250,11 +284,11 @@ func (h *Handler) HandleFileInfoWithEdit(chatID int64, filePath string, messageI
 
 // HandleFileLink 处理获取文件链接
 func (h *Handler) HandleFileLink(chatID int64, filePath string) {
-	h.HandleFileLinkWithEdit(chatID, filePath, 0)
+	h.HandleFileLinkWithEdit(chatID, "", filePath, 0)
 }
 
 // HandleFileLinkWithEdit 处理获取文件链接（支持消息编辑）
-func (h *Handler) HandleFileLinkWithEdit(chatID int64, filePath string, messageID int) {
+func (h *Handler) HandleFileLinkWithEdit(chatID int64, root, filePath string, messageID int) {
 	msgUtils := h.deps.GetMessageUtils()
 
 	// 仅在发送新消息时显示加载提示
@@ -280,7 +314,8 @@ func (h *Handler) HandleFileLinkWithEdit(chatID int64, filePath string, messageI
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeFilePath(filepath.Dir(filePath)), 1)),
+			tgbotapi.NewInlineKeyboardButtonData("📱 二维码", fmt.Sprintf("file_qr:%s", h.deps.EncodeRootFilePath(root, filePath))),
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("browse_dir:%s:%d", h.deps.EncodeRootFilePath(root, filepath.Dir(filePath)), 1)),
 		),
 	)
 
@@ -290,3 +325,31 @@ func (h *Handler) HandleFileLinkWithEdit(chatID int64, filePath string, messageI
 		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
 	}
 }
+
+// HandleFileQRCode 生成文件下载链接的二维码图片并发送，便于在手机/电视上打开
+func (h *Handler) HandleFileQRCode(chatID int64, filePath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	downloadURL := h.GetFileDownloadURL(filepath.Dir(filePath), filepath.Base(filePath))
+
+	png, err := qrutil.EncodePNG(downloadURL, qrutil.DefaultImageSize)
+	if err != nil {
+		if errors.Is(err, qrutil.ErrContentTooLong) {
+			msgUtils.SendMessageHTML(chatID, formatter.FormatSimpleError(
+				fmt.Sprintf("链接过长（%d 字节），超出二维码容量，请使用文本链接", len(downloadURL))))
+			return
+		}
+		msgUtils.SendMessage(chatID, formatter.FormatError("生成二维码", err))
+		return
+	}
+
+	caption := fmt.Sprintf("📱 <b>%s</b>\n\n<code>%s</code>",
+		msgUtils.EscapeHTML(filepath.Base(filePath)), msgUtils.EscapeHTML(downloadURL))
+	// 图片说明最多1024字符，超长时仅保留文件名
+	if len([]rune(caption)) > 1024 {
+		caption = fmt.Sprintf("📱 <b>%s</b>", msgUtils.EscapeHTML(filepath.Base(filePath)))
+	}
+
+	msgUtils.SendPhoto(chatID, "qrcode.png", png, caption)
+}
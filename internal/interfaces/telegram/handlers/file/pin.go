@@ -0,0 +1,147 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 目录收藏功能
+// ================================
+
+// NormalizePinPath 规范化收藏路径
+func NormalizePinPath(dirPath string) string {
+	dirPath = strings.TrimSpace(dirPath)
+	if !strings.HasPrefix(dirPath, "/") {
+		dirPath = "/" + dirPath
+	}
+	return path.Clean(dirPath)
+}
+
+// PinDirectory 收藏目录，返回是否为新增；root 为浏览时所在的 alist.roots 根目录名称，从收藏夹打开时保持在该根目录内
+func (h *Handler) PinDirectory(userID int64, root, dirPath string) (bool, error) {
+	if !h.isDirectoryAvailable(dirPath) {
+		return false, fmt.Errorf("目录不存在: %s", dirPath)
+	}
+	return h.deps.GetPinRepository().Add(userID, root, dirPath)
+}
+
+// HandlePin 处理 /pin 命令
+func (h *Handler) HandlePin(chatID, userID int64, dirPath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	if strings.TrimSpace(dirPath) == "" {
+		h.HandleFavoritesWithEdit(chatID, userID, 0)
+		return
+	}
+
+	dirPath = NormalizePinPath(dirPath)
+	added, err := h.PinDirectory(userID, "", dirPath)
+	if err != nil {
+		msgUtils.SendMessage(chatID, "收藏失败: "+err.Error())
+		return
+	}
+	if !added {
+		msgUtils.SendMessageHTML(chatID, fmt.Sprintf("<code>%s</code> 已在收藏夹中", msgUtils.EscapeHTML(dirPath)))
+		return
+	}
+	msgUtils.SendMessageHTML(chatID, fmt.Sprintf("⭐ 已收藏 <code>%s</code>", msgUtils.EscapeHTML(dirPath)))
+}
+
+// HandleUnpin 处理 /unpin 命令
+func (h *Handler) HandleUnpin(chatID, userID int64, dirPath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	if strings.TrimSpace(dirPath) == "" {
+		msgUtils.SendMessageHTML(chatID, "使用方式：<code>/unpin &lt;路径&gt;</code>")
+		return
+	}
+
+	dirPath = NormalizePinPath(dirPath)
+	removed, err := h.deps.GetPinRepository().Remove(userID, dirPath)
+	if err != nil {
+		msgUtils.SendMessage(chatID, "取消收藏失败: "+err.Error())
+		return
+	}
+	if !removed {
+		msgUtils.SendMessageHTML(chatID, fmt.Sprintf("<code>%s</code> 不在收藏夹中", msgUtils.EscapeHTML(dirPath)))
+		return
+	}
+	msgUtils.SendMessageHTML(chatID, fmt.Sprintf("已取消收藏 <code>%s</code>", msgUtils.EscapeHTML(dirPath)))
+}
+
+// HandleUnpinWithEdit 移除收藏后刷新收藏夹
+func (h *Handler) HandleUnpinWithEdit(chatID, userID int64, dirPath string, messageID int) {
+	if _, err := h.deps.GetPinRepository().Remove(userID, dirPath); err != nil {
+		h.deps.GetMessageUtils().SendMessage(chatID, "取消收藏失败: "+err.Error())
+		return
+	}
+	h.HandleFavoritesWithEdit(chatID, userID, messageID)
+}
+
+// HandleFavoritesWithEdit 显示收藏夹（支持消息编辑）
+// 已不存在的目录标记为失效，点击即可移除
+func (h *Handler) HandleFavoritesWithEdit(chatID, userID int64, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	pins := h.deps.GetPinRepository().GetByUserID(userID)
+
+	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+	message := "<b>⭐ 收藏夹</b>\n\n"
+
+	if len(pins) == 0 {
+		message += "收藏夹为空\n\n在目录菜单点击「⭐ 收藏」或发送 <code>/pin &lt;路径&gt;</code> 添加"
+	} else {
+		staleCount := 0
+		for _, pin := range pins {
+			name := filepath.Base(pin.Path)
+			if pin.Path == "/" {
+				name = "根目录"
+			}
+			name = formatter.TruncateButtonText(name, 30)
+
+			if h.isDirectoryAvailable(pin.Path) {
+				message += fmt.Sprintf("• <code>%s</code>\n", msgUtils.EscapeHTML(pin.Path))
+				keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("📁 "+name, fmt.Sprintf("browse_dir:%s:1", h.deps.EncodeRootFilePath(pin.Root, pin.Path))),
+				))
+				continue
+			}
+
+			staleCount++
+			message += fmt.Sprintf("• ⚠️ <code>%s</code>（已失效）\n", msgUtils.EscapeHTML(pin.Path))
+			keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("🗑️ 移除失效: "+name, fmt.Sprintf("pin_remove:%s", h.deps.EncodeFilePath(pin.Path))),
+			))
+		}
+		if staleCount > 0 {
+			message += fmt.Sprintf("\n⚠️ %d 个收藏的目录已不存在", staleCount)
+		}
+	}
+
+	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📁 浏览文件", "files_browse"),
+		tgbotapi.NewInlineKeyboardButtonData("⚡ 下载预设", "presets_list"),
+		tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+	} else {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+	}
+}
+
+// isDirectoryAvailable 检查目录是否仍然存在
+func (h *Handler) isDirectoryAvailable(dirPath string) bool {
+	if dirPath == "/" {
+		return true
+	}
+	info, err := h.deps.GetFileService().GetFileInfo(context.Background(), dirPath)
+	return err == nil && info.IsDir
+}
@@ -0,0 +1,302 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 下载预设（常用路径和筛选条件）
+// ================================
+
+const (
+	// maxPresetNameBytes 预设名称最大字节数（名称放在按钮回调数据中，Telegram 限制 64 字节）
+	maxPresetNameBytes = 48
+	// maxPresetHours 预设时间范围上限（一年）
+	maxPresetHours = 8760
+	// presetExtFlag 扩展名筛选参数前缀
+	presetExtFlag = "--ext="
+)
+
+// presetUsage /savepreset 用法说明
+const presetUsage = "用法：<code>/savepreset &lt;名称&gt; &lt;路径&gt; [24h|7d|all] [--all] [--ext=mkv,mp4]</code>\n\n" +
+	"• 时间范围：最近多少小时（h）或天（d）内修改的文件，all 为全部（默认）\n" +
+	"• --all：包含非视频文件\n" +
+	"• --ext：只下载指定扩展名的文件\n\n" +
+	"示例：<code>/savepreset 追剧 /tvs 24h</code>"
+
+// parsePresetArgs 解析 /savepreset 参数：名称、路径（可包含空格）、时间范围和筛选参数
+func parsePresetArgs(args string, defaultVideoOnly bool) (*entities.DownloadPreset, error) {
+	fields, includeAll := utils.ExtractAllFilesFlag(strings.Fields(args))
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("缺少名称或路径")
+	}
+
+	preset := &entities.DownloadPreset{
+		Name:      fields[0],
+		VideoOnly: defaultVideoOnly && !includeAll,
+	}
+	if len(preset.Name) > maxPresetNameBytes {
+		return nil, fmt.Errorf("名称过长（最多 %d 字节）", maxPresetNameBytes)
+	}
+
+	var pathParts []string
+	for _, field := range fields[1:] {
+		if exts, ok := strings.CutPrefix(strings.ToLower(field), presetExtFlag); ok {
+			for _, ext := range strings.Split(exts, ",") {
+				if ext = strings.TrimPrefix(strings.TrimSpace(ext), "."); ext != "" {
+					preset.Extensions = append(preset.Extensions, ext)
+				}
+			}
+			continue
+		}
+		pathParts = append(pathParts, field)
+	}
+
+	// 最后一个参数为时间范围时从路径中去掉
+	if n := len(pathParts); n > 1 {
+		if hours, ok := parsePresetWindow(pathParts[n-1]); ok {
+			if hours > maxPresetHours {
+				return nil, fmt.Errorf("时间范围不能超过 %d 小时（一年）", maxPresetHours)
+			}
+			preset.Hours = hours
+			pathParts = pathParts[:n-1]
+		}
+	}
+	if len(pathParts) == 0 {
+		return nil, fmt.Errorf("缺少路径")
+	}
+	preset.Path = NormalizePinPath(strings.Join(pathParts, " "))
+	return preset, nil
+}
+
+// parsePresetWindow 解析时间范围：24h、7d 或 all（0 表示全部）
+func parsePresetWindow(arg string) (int, bool) {
+	arg = strings.ToLower(arg)
+	if arg == "all" || arg == "全部" {
+		return 0, true
+	}
+	multiplier := 1
+	number, ok := strings.CutSuffix(arg, "h")
+	if !ok {
+		if number, ok = strings.CutSuffix(arg, "d"); !ok {
+			return 0, false
+		}
+		multiplier = 24
+	}
+	value, err := strconv.Atoi(number)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+	return value * multiplier, true
+}
+
+// presetWindowLabel 预设时间范围说明
+func presetWindowLabel(hours int) string {
+	switch {
+	case hours <= 0:
+		return "全部"
+	case hours%24 == 0:
+		return fmt.Sprintf("最近%d天", hours/24)
+	default:
+		return fmt.Sprintf("最近%d小时", hours)
+	}
+}
+
+// presetDownloadRequest 根据预设生成目录下载请求，时间范围从运行时开始计算
+func (h *Handler) presetDownloadRequest(chatID int64, preset *entities.DownloadPreset) contracts.DirectoryDownloadRequest {
+	req := h.directoryDownloadRequest(chatID, preset.Path)
+	req.VideoOnly = preset.VideoOnly
+	req.Extensions = preset.Extensions
+	if preset.Hours > 0 {
+		req.ModifiedAfter = time.Now().Add(-time.Duration(preset.Hours) * time.Hour)
+	}
+	return req
+}
+
+// HandleSavePreset 处理 /savepreset 命令，保存（或覆盖同名）下载预设
+func (h *Handler) HandleSavePreset(chatID, userID int64, args string) {
+	msgUtils := h.deps.GetMessageUtils()
+
+	if strings.TrimSpace(args) == "" {
+		msgUtils.SendMessageHTML(chatID, presetUsage)
+		return
+	}
+	preset, err := parsePresetArgs(args, h.deps.GetConfig().Alist.DefaultVideoOnly)
+	if err != nil {
+		msgUtils.SendMessageHTML(chatID, fmt.Sprintf("❌ %s\n\n%s", msgUtils.EscapeHTML(err.Error()), presetUsage))
+		return
+	}
+	if !h.isDirectoryAvailable(preset.Path) {
+		msgUtils.SendMessageHTML(chatID, fmt.Sprintf("❌ 目录不存在: <code>%s</code>", msgUtils.EscapeHTML(preset.Path)))
+		return
+	}
+
+	preset.UserID = userID
+	replaced, err := h.deps.GetPresetRepository().Save(preset)
+	if err != nil {
+		msgUtils.SendMessage(chatID, "保存预设失败: "+err.Error())
+		return
+	}
+
+	action := "已保存"
+	if replaced {
+		action = "已更新"
+	}
+	message := fmt.Sprintf("💾 %s预设 <b>%s</b>\n\n%s\n\n发送 <code>/preset %s</code> 或在 /presets 中点击运行",
+		action, msgUtils.EscapeHTML(preset.Name), h.formatPresetDetails(preset), msgUtils.EscapeHTML(preset.Name))
+	msgUtils.SendMessageHTML(chatID, message)
+}
+
+// HandlePreset 处理 /preset [名称] 命令，不带名称时显示预设列表
+func (h *Handler) HandlePreset(chatID, userID int64, name string) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		h.HandlePresetsWithEdit(chatID, userID, 0)
+		return
+	}
+	h.HandlePresetConfirm(chatID, userID, name, 0)
+}
+
+// HandlePresetsWithEdit 显示用户的下载预设列表（支持消息编辑），每个预设一键运行或删除
+func (h *Handler) HandlePresetsWithEdit(chatID, userID int64, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	presets := h.deps.GetPresetRepository().GetByUserID(userID)
+
+	message := formatter.FormatTitle("⚡", "下载预设") + "\n\n"
+	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+	if len(presets) == 0 {
+		message += "还没有预设\n\n" + presetUsage
+	} else {
+		for _, preset := range presets {
+			message += fmt.Sprintf("<b>%s</b>\n%s\n\n", msgUtils.EscapeHTML(preset.Name), h.formatPresetDetails(preset))
+			keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("▶️ "+formatter.TruncateButtonText(preset.Name, 24), "preset_show:"+preset.Name),
+				tgbotapi.NewInlineKeyboardButtonData("🗑️ 删除", "preset_del:"+preset.Name),
+			))
+		}
+		message += "点击预设预览匹配的文件，确认后开始下载"
+	}
+
+	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🗂️ 收藏夹", "pins_list"),
+		tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+	} else {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+	}
+}
+
+// HandlePresetDelete 删除预设后刷新列表
+func (h *Handler) HandlePresetDelete(chatID, userID int64, name string, messageID int) {
+	if _, err := h.deps.GetPresetRepository().Remove(userID, name); err != nil {
+		h.deps.GetMessageUtils().SendMessage(chatID, "删除预设失败: "+err.Error())
+		return
+	}
+	h.HandlePresetsWithEdit(chatID, userID, messageID)
+}
+
+// HandlePresetConfirm 运行预设：检查目录仍然存在，列出匹配的文件数和大小并确认下载
+func (h *Handler) HandlePresetConfirm(chatID, userID int64, name string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	listButton := tgbotapi.NewInlineKeyboardButtonData("⚡ 预设列表", "presets_list")
+
+	render := func(message string, rows ...[]tgbotapi.InlineKeyboardButton) {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(append(rows, tgbotapi.NewInlineKeyboardRow(listButton))...)
+		if messageID > 0 {
+			msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+		} else {
+			msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+		}
+	}
+
+	preset, ok := h.deps.GetPresetRepository().Get(userID, name)
+	if !ok {
+		render(fmt.Sprintf("❓ 没有名为 <b>%s</b> 的预设", msgUtils.EscapeHTML(name)))
+		return
+	}
+
+	title := formatter.FormatTitle("⚡", "预设："+msgUtils.EscapeHTML(preset.Name)) + "\n\n" + h.formatPresetDetails(preset)
+	if !h.isDirectoryAvailable(preset.Path) {
+		render(title+"\n\n⚠️ 目录已不存在，请重新保存预设或删除",
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("🗑️ 删除预设", "preset_del:"+preset.Name)))
+		return
+	}
+
+	req := h.presetDownloadRequest(chatID, preset)
+	resp, err := h.deps.GetFileService().ListFiles(context.Background(), contracts.FileListRequest{
+		Path:          req.DirectoryPath,
+		Recursive:     req.Recursive,
+		VideoOnly:     req.VideoOnly,
+		PageSize:      10000,
+		IncludeHidden: req.IncludeHidden,
+	})
+	if err != nil {
+		render(formatter.FormatError("获取文件列表", err))
+		return
+	}
+
+	matched := 0
+	var totalSize int64
+	for _, file := range resp.Files {
+		if req.Matches(file) {
+			matched++
+			totalSize += file.Size
+		}
+	}
+	message := title + "\n" +
+		formatter.FormatField("匹配", fmt.Sprintf("%d/%d 个文件", matched, len(resp.Files))) + "\n" +
+		formatter.FormatField("大小", msgUtils.FormatFileSize(totalSize))
+
+	if matched == 0 {
+		render(message + "\n\n没有符合条件的文件")
+		return
+	}
+	render(message+"\n\n是否确认下载？", tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ 下载 %d 个文件", matched), "preset_run:"+preset.Name),
+	))
+}
+
+// HandlePresetExecute 按预设下载目录中匹配的文件
+func (h *Handler) HandlePresetExecute(chatID, userID int64, name string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+
+	preset, ok := h.deps.GetPresetRepository().Get(userID, name)
+	if !ok {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, fmt.Sprintf("❓ 没有名为 <b>%s</b> 的预设", msgUtils.EscapeHTML(name)), "HTML", nil)
+		return
+	}
+
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在处理下载任务...", "HTML", nil)
+	h.handleDownloadDirectoryByPathWithEdit(chatID, userID, messageID, h.presetDownloadRequest(chatID, preset))
+}
+
+// formatPresetDetails 预设的路径和筛选条件
+func (h *Handler) formatPresetDetails(preset *entities.DownloadPreset) string {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	lines := []string{
+		formatter.FormatFieldCode("路径", msgUtils.EscapeHTML(preset.Path)),
+		formatter.FormatField("时间范围", presetWindowLabel(preset.Hours)),
+		formatter.FormatField("文件类型", utils.FileScopeLabel(preset.VideoOnly)),
+	}
+	if len(preset.Extensions) > 0 {
+		lines = append(lines, formatter.FormatField("扩展名", msgUtils.EscapeHTML(strings.Join(preset.Extensions, ", "))))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,193 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	"github.com/easayliu/alist-aria2-download/pkg/utils/media"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 分类纠正
+// ================================
+
+// maxOverridesShown /overrides 最多显示的覆盖条数（显示最新的）
+const maxOverridesShown = 50
+
+// HandleReclassifyMenu 显示分类选择菜单
+func (h *Handler) HandleReclassifyMenu(chatID int64, filePath string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	fileService := h.deps.GetFileService()
+
+	fileName := filepath.Base(filePath)
+	key, title := media.OverrideKey(fileName)
+	current := fileService.GetFileCategory(fileName)
+	encoded := h.deps.EncodeFilePath(filePath)
+
+	// 文件名只有季集等标记时无法生成只匹配本剧的规则
+	if key == "" {
+		message := formatter.FormatTitle("📂", "修改分类") + "\n\n" +
+			formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(fileName)) + "\n\n" +
+			"无法从文件名识别剧集或电影标题，请先重命名文件（包含标题）后再修改分类。"
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("file_menu:%s", encoded)),
+		))
+		if messageID > 0 {
+			msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+		} else {
+			msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+		}
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("📂", "修改分类"),
+		"",
+		formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(fileName)),
+		formatter.FormatField("当前分类", inventoryCategoryLabel(current)),
+		"",
+		fmt.Sprintf("选择正确的分类后，文件名包含「<b>%s</b>」的文件今后都将使用该分类；已下载的文件会移动到新分类目录。", msgUtils.EscapeHTML(title)),
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+	for _, category := range contracts.ReclassifyCategories {
+		label := inventoryCategoryLabel(category)
+		if category == current {
+			label = "✓ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("file_recat_set:%s:%s", encoded, category)))
+		if len(row) == 2 {
+			keyboardRows = append(keyboardRows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboardRows = append(keyboardRows, row)
+	}
+	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("file_menu:%s", encoded)),
+	))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	message := strings.Join(lines, "\n")
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+	} else {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+	}
+}
+
+// HandleReclassify 执行分类纠正并显示结果
+func (h *Handler) HandleReclassify(chatID, userID int64, filePath, category string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	result, err := h.deps.GetFileService().ReclassifyFile(context.Background(), contracts.ReclassifyRequest{
+		Path:     filePath,
+		Category: category,
+		UserID:   userID,
+	})
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("修改分类", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("✅", "分类已更新"),
+		"",
+		formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(filepath.Base(filePath))),
+		formatter.FormatField("新分类", inventoryCategoryLabel(result.Category)),
+		formatter.FormatFieldCode("匹配", msgUtils.EscapeHTML(result.Pattern)),
+	}
+
+	switch {
+	case result.Moved:
+		lines = append(lines, "",
+			"📦 已移动已下载的文件",
+			fmt.Sprintf("从 <code>%s</code>", msgUtils.EscapeHTML(result.From)),
+			fmt.Sprintf("到 <code>%s</code>", msgUtils.EscapeHTML(result.To)))
+	case result.MoveError != "":
+		lines = append(lines, "", fmt.Sprintf("⚠️ 未能移动已下载的文件：%s", msgUtils.EscapeHTML(result.MoveError)))
+	case result.Downloaded:
+		lines = append(lines, "", "已下载的文件已在对应目录，无需移动")
+	}
+	lines = append(lines, "", "使用 /overrides 查看或删除已记录的分类纠正")
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("file_menu:%s", h.deps.EncodeFilePath(filePath))),
+		),
+	)
+	message := strings.Join(lines, "\n")
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+	} else {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+	}
+}
+
+// HandleCategoryOverrides 处理 /overrides 命令：列出分类纠正，或使用 del <序号> 删除
+func (h *Handler) HandleCategoryOverrides(chatID int64, args string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	repo := h.deps.GetCategoryOverrideRepository()
+	if repo == nil {
+		msgUtils.SendMessage(chatID, "分类纠正功能未启用")
+		return
+	}
+	overrides := repo.List()
+
+	fields := strings.Fields(args)
+	if len(fields) > 0 {
+		if len(fields) != 2 || (fields[0] != "del" && fields[0] != "rm") {
+			msgUtils.SendMessageHTML(chatID, "用法：<code>/overrides</code> 查看，<code>/overrides del &lt;序号&gt;</code> 删除")
+			return
+		}
+		index, err := strconv.Atoi(fields[1])
+		if err != nil || index < 1 || index > len(overrides) {
+			msgUtils.SendMessage(chatID, fmt.Sprintf("序号无效，请输入 1-%d", len(overrides)))
+			return
+		}
+		override := overrides[index-1]
+		if err := repo.Delete(override.Pattern); err != nil {
+			msgUtils.SendMessage(chatID, formatter.FormatError("删除分类纠正", err))
+			return
+		}
+		msgUtils.SendMessageHTML(chatID, fmt.Sprintf("✅ 已删除分类纠正：<code>%s</code> → %s",
+			msgUtils.EscapeHTML(override.Pattern), inventoryCategoryLabel(override.Category)))
+		return
+	}
+
+	if len(overrides) == 0 {
+		msgUtils.SendMessage(chatID, "还没有分类纠正记录。在文件菜单中点击「📂 改类别」即可纠正自动分类。")
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("📂", "分类纠正"),
+		"",
+		formatter.FormatField("共", fmt.Sprintf("%d 条（文件名包含匹配键时使用对应分类）", len(overrides))),
+		"",
+	}
+	start := max(len(overrides)-maxOverridesShown, 0)
+	if start > 0 {
+		lines = append(lines, fmt.Sprintf("… 省略 %d 条较早的记录", start))
+	}
+	for i := start; i < len(overrides); i++ {
+		override := overrides[i]
+		lines = append(lines, fmt.Sprintf("%d. <code>%s</code> → %s",
+			i+1, msgUtils.EscapeHTML(override.Pattern), inventoryCategoryLabel(override.Category)))
+	}
+	lines = append(lines, "", "删除：<code>/overrides del &lt;序号&gt;</code>")
+
+	msgUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,146 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 整理下载目录
+// ================================
+
+// maxReorganizeMovesShown 整理报告中最多列出的文件数
+const maxReorganizeMovesShown = 15
+
+// reorganizeActionLabels 整理操作显示名称
+var reorganizeActionLabels = map[string]string{
+	contracts.ReorganizeActionRename:    "重名，添加序号",
+	contracts.ReorganizeActionOverwrite: "覆盖已有文件",
+	contracts.ReorganizeActionSkip:      "目标已存在，跳过",
+}
+
+// HandleReorganize 处理 /reorganize 命令（仅管理员）：试运行整理本地下载目录，列出计划的移动并确认执行
+func (h *Handler) HandleReorganize(chatID int64) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	msgUtils.SendMessageWithAutoDelete(chatID, "⏳ 正在扫描下载目录，文件较多时需要一些时间...", types.MessageTransient)
+
+	report, err := h.deps.GetFileService().ReorganizeDownloads(contracts.WithDryRun(context.Background()))
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("扫描下载目录", err))
+		return
+	}
+
+	message := h.formatReorganizeReport(formatter, report)
+	pending := 0
+	for _, move := range report.Moves {
+		if move.Action != contracts.ReorganizeActionSkip {
+			pending++
+		}
+	}
+	if pending == 0 {
+		msgUtils.SendMessageHTML(chatID, message+"\n\n没有需要移动的文件")
+		return
+	}
+
+	message += "\n\n执行时会重新扫描并按上述规则移动文件，是否确认整理？"
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ 移动 %d 个文件", pending), "reorganize_apply"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", "reorganize_cancel"),
+		),
+	)
+	msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+}
+
+// HandleReorganizeExecute 执行下载目录整理并显示结果
+func (h *Handler) HandleReorganizeExecute(chatID int64, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, "⏳ 正在整理下载目录...", "HTML", nil)
+
+	report, err := h.deps.GetFileService().ReorganizeDownloads(context.Background())
+	if err != nil {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, formatter.FormatError("整理下载目录", err), "HTML", nil)
+		return
+	}
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, h.formatReorganizeReport(formatter, report), "HTML", nil)
+}
+
+// formatReorganizeReport 格式化整理报告，路径显示为相对下载根目录的路径
+func (h *Handler) formatReorganizeReport(formatter *utils.MessageFormatter, report *contracts.ReorganizeReport) string {
+	escape := h.deps.GetMessageUtils().EscapeHTML
+	relative := func(path string) string {
+		if rel, err := filepath.Rel(report.Root, path); err == nil {
+			return rel
+		}
+		return path
+	}
+
+	title := "整理下载目录（试运行）"
+	if !report.DryRun {
+		title = "整理下载目录完成"
+	}
+	lines := []string{
+		formatter.FormatTitle("🗂", title),
+		"",
+		formatter.FormatFieldCode("目录", escape(report.Root)),
+		formatter.FormatField("媒体文件", fmt.Sprintf("%d 个（已在正确目录 %d，下载中跳过 %d）", report.Scanned, report.InPlace, report.Downloading)),
+		formatter.FormatField("冲突策略", report.Policy),
+	}
+
+	moved, failed, skipped := 0, 0, 0
+	for _, move := range report.Moves {
+		switch {
+		case move.Action == contracts.ReorganizeActionSkip:
+			skipped++
+		case move.Moved:
+			moved++
+		case move.Error != "":
+			failed++
+		}
+	}
+	if report.DryRun {
+		lines = append(lines, formatter.FormatField("计划移动", fmt.Sprintf("%d 个（冲突跳过 %d）", len(report.Moves)-skipped, skipped)))
+	} else {
+		lines = append(lines, formatter.FormatField("已移动", fmt.Sprintf("%d 个（失败 %d，冲突跳过 %d）", moved, failed, skipped)))
+	}
+
+	if len(report.Moves) > 0 {
+		lines = append(lines, "")
+	}
+	for i, move := range report.Moves {
+		if i == maxReorganizeMovesShown {
+			lines = append(lines, fmt.Sprintf("... 另有 %d 个文件", len(report.Moves)-i))
+			break
+		}
+		status := "•"
+		switch {
+		case move.Error != "":
+			status = "❌"
+		case move.Moved:
+			status = "✅"
+		case move.Action == contracts.ReorganizeActionSkip:
+			status = "⏭"
+		}
+		line := fmt.Sprintf("%s [%s] <code>%s</code> → <code>%s</code>", status, inventoryCategoryLabel(move.Category),
+			escape(relative(move.From)), escape(relative(move.To)))
+		if label, ok := reorganizeActionLabels[move.Action]; ok {
+			line += "（" + label + "）"
+		}
+		if move.Error != "" {
+			line += "\n  " + escape(move.Error)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,117 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	"github.com/easayliu/alist-aria2-download/pkg/utils/media"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// videoProbeTimeout 文件详情中获取视频信息的超时时间，超时后不显示时长和分辨率
+const videoProbeTimeout = 20 * time.Second
+
+// ================================
+// 文件片段预览
+// ================================
+
+// HandleFileSampleWithEdit 读取文件开头片段并展示容器/编码信息（不创建下载任务）
+func (h *Handler) HandleFileSampleWithEdit(chatID int64, filePath string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	if messageID == 0 {
+		msgUtils.SendMessageWithAutoDelete(chatID, "⏳ 正在读取文件片段...", types.MessageTransient)
+	}
+
+	sample, err := h.deps.GetFileService().SampleFile(context.Background(), filePath, 0)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("读取文件片段", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("🔍", "片段预览"),
+		"",
+		formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(filepath.Base(filePath))),
+	}
+	if sample.TotalSize > 0 {
+		lines = append(lines, formatter.FormatField("大小", strutil.FormatFileSize(sample.TotalSize)))
+	}
+	lines = append(lines, formatter.FormatField("已读取", strutil.FormatFileSize(sample.BytesRead)))
+
+	container := "未识别"
+	if sample.Container != "" {
+		container = sample.Container
+		if sample.Detail != "" {
+			container = fmt.Sprintf("%s（%s）", sample.Container, sample.Detail)
+		}
+	}
+	lines = append(lines, formatter.FormatField("容器", msgUtils.EscapeHTML(container)))
+
+	codecs := "片段中未找到编码信息"
+	if len(sample.Codecs) > 0 {
+		codecs = strings.Join(sample.Codecs, " / ")
+	}
+	lines = append(lines, formatter.FormatField("编码", msgUtils.EscapeHTML(codecs)))
+	duration, resolution := formatVideoMetadata(sample.Video)
+	if duration != "" {
+		lines = append(lines, formatter.FormatField("时长", duration))
+	}
+	if resolution != "" {
+		lines = append(lines, formatter.FormatField("分辨率", resolution))
+	}
+
+	if !sample.RangeSupported {
+		lines = append(lines, "", "⚠️ 服务器不支持分段请求，已在读取到上限后中断传输")
+	}
+
+	message := strings.Join(lines, "\n")
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回", fmt.Sprintf("file_menu:%s", h.deps.EncodeFilePath(filePath))),
+		),
+	)
+
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+	} else {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+	}
+}
+
+// probeVideoMetadata 获取文件详情中显示的视频时长和分辨率，获取失败时返回空字符串（不影响文件详情显示）
+func (h *Handler) probeVideoMetadata(filePath string, size int64) (duration, resolution string) {
+	ctx, cancel := context.WithTimeout(context.Background(), videoProbeTimeout)
+	defer cancel()
+
+	metadata, err := h.deps.GetFileService().ProbeVideoMetadata(ctx, filePath, size)
+	if err != nil {
+		logger.Debug("Video metadata unavailable", "path", filePath, "error", err)
+		return "", ""
+	}
+	return formatVideoMetadata(metadata)
+}
+
+// formatVideoMetadata 格式化视频时长（时:分:秒）和分辨率（1920×1080 (1080p)）
+func formatVideoMetadata(metadata *contracts.VideoMetadata) (duration, resolution string) {
+	if metadata == nil {
+		return "", ""
+	}
+	if metadata.Duration > 0 {
+		total := int(metadata.Duration.Round(time.Second).Seconds())
+		duration = fmt.Sprintf("%d:%02d:%02d", total/3600, total/60%60, total%60)
+	}
+	if metadata.Width > 0 && metadata.Height > 0 {
+		resolution = fmt.Sprintf("%d×%d (%s)", metadata.Width, metadata.Height, media.ResolutionLabel(metadata.Width, metadata.Height))
+	}
+	return duration, resolution
+}
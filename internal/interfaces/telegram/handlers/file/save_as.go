@@ -0,0 +1,107 @@
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	fileutil "github.com/easayliu/alist-aria2-download/pkg/utils/file"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 重命名后下载
+// ================================
+
+// saveAsPromptPattern 从提示消息中提取路径令牌（提示消息中包含 "/saveas <令牌>"）
+var saveAsPromptPattern = regexp.MustCompile(`/saveas (\S+)`)
+
+// SaveAsReplyCommand 用户回复“重命名后下载”提示消息时，将回复内容转换为 /saveas 命令
+func SaveAsReplyCommand(promptText, reply string) (string, bool) {
+	match := saveAsPromptPattern.FindStringSubmatch(promptText)
+	if match == nil {
+		return "", false
+	}
+	return fmt.Sprintf("/saveas %s %s", match[1], strings.TrimSpace(reply)), true
+}
+
+// HandleSaveAsPrompt 提示用户输入下载保存的文件名
+func (h *Handler) HandleSaveAsPrompt(chatID int64, filePath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	fileName := filepath.Base(filePath)
+	hint := "请回复此消息发送新的文件名"
+	if ext := filepath.Ext(fileName); ext != "" {
+		hint += fmt.Sprintf("（省略扩展名时自动补全 %s）", msgUtils.EscapeHTML(ext))
+	}
+
+	lines := []string{
+		formatter.FormatTitle("✏️", "重命名后下载"),
+		"",
+		formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(fileName)),
+		"",
+		hint,
+		fmt.Sprintf("也可以直接发送：<code>/saveas %s 新文件名</code>", h.deps.EncodeFilePath(filePath)),
+	}
+	msgUtils.SendForceReply(chatID, strings.Join(lines, "\n"), fileName)
+}
+
+// HandleSaveAsCommand 处理 /saveas <令牌> <文件名>：清理文件名后请用户确认
+func (h *Handler) HandleSaveAsCommand(chatID int64, args string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	token, name, _ := strings.Cut(strings.TrimSpace(args), " ")
+	name = strings.TrimSpace(name)
+	if token == "" || name == "" {
+		msgUtils.SendMessage(chatID, "请在文件菜单中点击「✏️ 重命名后下载」，然后回复新的文件名")
+		return
+	}
+
+	filePath := h.deps.DecodeFilePath(token)
+	if filePath == "/" {
+		msgUtils.SendMessage(chatID, "文件链接已过期，请重新打开文件菜单")
+		return
+	}
+
+	fileName := filepath.Base(filePath)
+	newName, err := fileutil.SanitizeFileName(name, fileName)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("文件名无效", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("📥", "确认下载"),
+		"",
+		formatter.FormatFieldCode("源文件", msgUtils.EscapeHTML(fileName)),
+		formatter.FormatFieldCode("保存为", msgUtils.EscapeHTML(newName)),
+	}
+	if newName != name {
+		lines = append(lines, "", "ℹ️ 文件名已自动调整（替换非法字符或补全扩展名）")
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ 确认下载", fmt.Sprintf("file_saveas_ok:%s:%s", token, h.deps.EncodeFilePath(newName))),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", fmt.Sprintf("file_menu:%s", token)),
+		),
+	)
+	msgUtils.SendMessageWithKeyboard(chatID, strings.Join(lines, "\n"), "HTML", &keyboard)
+}
+
+// HandleSaveAsDownload 按用户确认的文件名创建下载任务
+func (h *Handler) HandleSaveAsDownload(chatID, userID int64, root, filePath, fileName string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	if filePath == "/" || fileName == "/" {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, "文件链接已过期，请重新打开文件菜单", "HTML", nil)
+		return
+	}
+
+	msgUtils.ClearInlineKeyboard(chatID, messageID)
+	h.handleDownloadFileByPath(chatID, userID, root, contracts.FileDownloadRequest{FilePath: filePath, Filename: fileName})
+}
@@ -0,0 +1,221 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 文件搜索
+// ================================
+
+// searchPageSize 搜索结果每页显示的文件数
+const searchPageSize = 8
+
+// searchPromptPattern 从搜索提示消息中提取目录令牌（提示消息中包含 "/search @<令牌>"）
+var searchPromptPattern = regexp.MustCompile(`/search (@\S+)`)
+
+// maxSearchSessionsPerChat 每个聊天保留的搜索结果数，超出时丢弃最早的搜索
+const maxSearchSessionsPerChat = 5
+
+// searchKey 搜索结果消息：每条结果消息独立翻页，互不覆盖
+type searchKey struct {
+	chatID    int64
+	messageID int
+}
+
+// searchSession 一次搜索的结果，翻页时使用，避免重新遍历目录
+type searchSession struct {
+	keyword   string
+	root      string
+	rootLabel string // 搜索目录所属的 alist.roots 根目录名称，结果中的路径令牌携带该根目录
+	files     []contracts.FileResponse
+	truncated bool
+	createdAt time.Time
+}
+
+// SearchReplyCommand 用户回复“搜索文件”提示消息时，将回复内容转换为 /search 命令
+func SearchReplyCommand(promptText, reply string) (string, bool) {
+	match := searchPromptPattern.FindStringSubmatch(promptText)
+	if match == nil {
+		return "", false
+	}
+	return fmt.Sprintf("/search %s %s", match[1], strings.TrimSpace(reply)), true
+}
+
+// HandleSearchPrompt 提示用户输入在指定目录中搜索的关键词，root 为浏览时所在的 alist.roots 根目录名称
+func (h *Handler) HandleSearchPrompt(chatID int64, root, dirPath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	lines := []string{
+		formatter.FormatTitle("🔍", "搜索文件"),
+		"",
+		formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(dirPath)),
+		"",
+		"请回复此消息发送关键词（文件名包含全部关键词即匹配，不区分大小写）",
+		fmt.Sprintf("也可以直接发送：<code>/search @%s 关键词</code>", h.deps.EncodeRootFilePath(root, dirPath)),
+	}
+	msgUtils.SendForceReply(chatID, strings.Join(lines, "\n"), "关键词")
+}
+
+// HandleSearch 处理 /search [@目录令牌] <关键词> [--all]：递归搜索文件名，默认从 alist.default_path 开始
+// alist.default_video_only 开启时只搜索视频文件，--all 包含全部文件
+func (h *Handler) HandleSearch(chatID int64, args string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	cfg := h.deps.GetConfig()
+
+	fields, includeAll := utils.ExtractAllFilesFlag(strings.Fields(args))
+	root := cfg.Alist.DefaultPath
+	rootLabel := ""
+	keyword := strings.Join(fields, " ")
+	if token, rest, found := strings.Cut(keyword, " "); found && strings.HasPrefix(token, "@") {
+		rootLabel, root = h.deps.DecodeRootFilePath(strings.TrimPrefix(token, "@"))
+		keyword = strings.TrimSpace(rest)
+	}
+	if root == "" {
+		root = "/"
+	}
+	if keyword == "" || strings.HasPrefix(keyword, "@") {
+		msgUtils.SendMessageHTML(chatID, "使用方式：<code>/search &lt;关键词&gt; [--all]</code>\n\n"+
+			fmt.Sprintf("从 <code>%s</code> 开始递归搜索文件名，多个关键词用空格分隔\n• 文件类型：%s",
+				msgUtils.EscapeHTML(root), utils.FileScopeLabel(cfg.Alist.DefaultVideoOnly && !includeAll)))
+		return
+	}
+
+	msgUtils.SendMessageWithAutoDelete(chatID, "🔍 正在搜索，目录较多时需要一些时间...", types.MessageTransient)
+
+	resp, err := h.deps.GetFileService().SearchFiles(context.Background(), contracts.FileSearchRequest{
+		Query:     keyword,
+		Path:      root,
+		VideoOnly: cfg.Alist.DefaultVideoOnly && !includeAll,
+	})
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("搜索文件", err))
+		return
+	}
+	if len(resp.Files) == 0 {
+		message := fmt.Sprintf("在 <code>%s</code> 中没有找到包含 <b>%s</b> 的文件",
+			msgUtils.EscapeHTML(resp.CurrentPath), msgUtils.EscapeHTML(keyword))
+		if resp.Truncated {
+			message += "\n\n⚠️ 结果被截断：目录过多，仅搜索了部分目录，请换用更具体的起始目录"
+		}
+		msgUtils.SendMessageHTML(chatID, message)
+		return
+	}
+
+	session := &searchSession{
+		keyword:   keyword,
+		root:      resp.CurrentPath,
+		rootLabel: rootLabel,
+		files:     resp.Files,
+		truncated: resp.Truncated,
+		createdAt: time.Now(),
+	}
+	message, keyboard := h.renderSearchPage(session, 1)
+	if messageID := msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", keyboard); messageID > 0 {
+		h.storeSearchSession(searchKey{chatID: chatID, messageID: messageID}, session)
+	}
+}
+
+// storeSearchSession 保存结果消息对应的搜索，并丢弃该聊天中超出数量的最早搜索
+func (h *Handler) storeSearchSession(key searchKey, session *searchSession) {
+	h.searchMu.Lock()
+	defer h.searchMu.Unlock()
+
+	h.searches[key] = session
+	var chatKeys []searchKey
+	for k := range h.searches {
+		if k.chatID == key.chatID {
+			chatKeys = append(chatKeys, k)
+		}
+	}
+	if len(chatKeys) <= maxSearchSessionsPerChat {
+		return
+	}
+	sort.Slice(chatKeys, func(i, j int) bool {
+		return h.searches[chatKeys[i]].createdAt.Before(h.searches[chatKeys[j]].createdAt)
+	})
+	for _, k := range chatKeys[:len(chatKeys)-maxSearchSessionsPerChat] {
+		delete(h.searches, k)
+	}
+}
+
+// HandleSearchPage 将搜索结果消息翻到指定页，每条结果消息翻页自己的搜索
+func (h *Handler) HandleSearchPage(chatID int64, page int, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+
+	h.searchMu.Lock()
+	session := h.searches[searchKey{chatID: chatID, messageID: messageID}]
+	h.searchMu.Unlock()
+	if session == nil {
+		msgUtils.SendMessage(chatID, "搜索结果已过期，请重新发送 /search 关键词")
+		return
+	}
+
+	message, keyboard := h.renderSearchPage(session, page)
+	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", keyboard)
+}
+
+// renderSearchPage 渲染搜索结果的指定页
+func (h *Handler) renderSearchPage(session *searchSession, page int) (string, *tgbotapi.InlineKeyboardMarkup) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	totalPages := (len(session.files) + searchPageSize - 1) / searchPageSize
+	page = min(max(page, 1), totalPages)
+	start := (page - 1) * searchPageSize
+	files := session.files[start:min(start+searchPageSize, len(session.files))]
+
+	lines := []string{
+		formatter.FormatTitle("🔍", "搜索结果"),
+		"",
+		formatter.FormatField("关键词", msgUtils.EscapeHTML(session.keyword)),
+		formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(session.root)),
+		formatter.FormatField("匹配", fmt.Sprintf("%d 个文件（第 %d/%d 页）", len(session.files), page, totalPages)),
+	}
+	if session.truncated {
+		lines = append(lines, "⚠️ 结果被截断：已达到搜索数量或目录上限，请使用更具体的关键词或起始目录")
+	}
+	lines = append(lines, "")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, file := range files {
+		index := start + i + 1
+		lines = append(lines, fmt.Sprintf("%d. <code>%s</code>  %s", index, msgUtils.EscapeHTML(file.Path), file.SizeFormatted))
+
+		label := fmt.Sprintf("📥 %d. %s", index, formatter.TruncateButtonText(path.Base(file.Path), 30))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, "file_download:"+h.deps.EncodeRootFilePath(session.rootLabel, file.Path)),
+		))
+	}
+
+	var navButtons []tgbotapi.InlineKeyboardButton
+	if page > 1 {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("< 上一页", fmt.Sprintf("search_page:%d", page-1)))
+	}
+	if page < totalPages {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("下一页 >", fmt.Sprintf("search_page:%d", page+1)))
+	}
+	if len(navButtons) > 0 {
+		keyboard = append(keyboard, navButtons)
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📁 浏览目录", fmt.Sprintf("browse_dir:%s:1", h.deps.EncodeRootFilePath(session.rootLabel, session.root))),
+		tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
+	))
+
+	inlineKeyboard := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	return strings.Join(lines, "\n"), &inlineKeyboard
+}
@@ -0,0 +1,222 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ================================
+// 目录快照对比
+// ================================
+
+// maxDiffFilesShown 差异结果中每类最多显示的文件数
+const maxDiffFilesShown = 10
+
+// HandleSnapshot 处理 /snapshot <路径> 命令，记录目录当前的文件列表
+func (h *Handler) HandleSnapshot(chatID, userID int64, dirPath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	if strings.TrimSpace(dirPath) == "" {
+		msgUtils.SendMessageHTML(chatID, "使用方式：<code>/snapshot &lt;路径&gt;</code>\n之后用 <code>/diff &lt;路径&gt;</code> 查看新增、删除和变化的文件")
+		return
+	}
+	dirPath = NormalizePinPath(dirPath)
+
+	msgUtils.SendMessageWithAutoDelete(chatID, "⏳ 正在扫描目录...", types.MessageTransient)
+
+	ctx := contracts.WithUserID(context.Background(), userID)
+	result, err := h.deps.GetFileService().SnapshotDirectory(ctx, dirPath)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("记录快照", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("📸", "已记录目录快照"),
+		"",
+		formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(result.Path)),
+		formatter.FormatField("文件", fmt.Sprintf("%d 个（%s）", result.FileCount, msgUtils.FormatFileSize(result.TotalSize))),
+	}
+	if result.PreviousAt != nil {
+		lines = append(lines, formatter.FormatField("替换", "上次快照 "+result.PreviousAt.Format("01-02 15:04")))
+	}
+	if result.Truncated {
+		lines = append(lines, "⚠️ 目录过大或部分子目录读取失败，快照不完整")
+	}
+	lines = append(lines, "", fmt.Sprintf("之后发送 <code>/diff %s</code> 查看变化", msgUtils.EscapeHTML(result.Path)))
+
+	msgUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+}
+
+// HandleDiff 处理 /diff <路径> 命令，对比目录当前内容与上次快照
+func (h *Handler) HandleDiff(chatID int64, dirPath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	if strings.TrimSpace(dirPath) == "" {
+		msgUtils.SendMessageHTML(chatID, "使用方式：<code>/diff &lt;路径&gt;</code>（需先用 <code>/snapshot</code> 记录快照）")
+		return
+	}
+	dirPath = NormalizePinPath(dirPath)
+
+	msgUtils.SendMessageWithAutoDelete(chatID, "⏳ 正在对比目录...", types.MessageTransient)
+
+	diff, err := h.deps.GetFileService().DiffDirectory(context.Background(), dirPath)
+	if errors.Is(err, contracts.ErrSnapshotNotFound) {
+		msgUtils.SendMessageHTML(chatID, fmt.Sprintf("<code>%s</code> 还没有快照，先发送 <code>/snapshot %s</code> 记录",
+			msgUtils.EscapeHTML(dirPath), msgUtils.EscapeHTML(dirPath)))
+		return
+	}
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("对比目录", err))
+		return
+	}
+
+	message := formatDirectoryDiff(formatter, msgUtils.EscapeHTML, msgUtils.FormatFileSize, diff)
+
+	encodedPath := h.deps.EncodeFilePath(dirPath)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if videos := h.diffVideoFiles(diff.Added); len(videos) > 0 {
+		if skipped := len(diff.Added) - len(videos); skipped > 0 {
+			message += fmt.Sprintf("\n\nℹ️ 「下载新增视频」只下载视频文件（与目录下载一致），跳过 %d 个其他新增文件", skipped)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⬇️ 下载新增视频 (%d)", len(videos)), "diff_dl:"+encodedPath),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📸 更新快照", "snapshot:"+encodedPath),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", &keyboard)
+}
+
+// HandleDiffDownload 重新对比目录并只下载上次快照之后新增的视频文件，结果中列出跳过的非视频文件数
+func (h *Handler) HandleDiffDownload(chatID, userID int64, dirPath string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	if dirPath == "" {
+		msgUtils.SendMessage(chatID, formatter.FormatSimpleError("按钮已过期，请重新发送 /diff"))
+		return
+	}
+
+	ctx := contracts.WithUserID(context.Background(), userID)
+	diff, err := h.deps.GetFileService().DiffDirectory(ctx, dirPath)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("对比目录", err))
+		return
+	}
+
+	videos := h.diffVideoFiles(diff.Added)
+	if len(videos) == 0 {
+		msgUtils.SendMessageHTML(chatID, formatter.FormatNoFilesFound("没有新增的视频文件", dirPath))
+		return
+	}
+
+	files := make([]contracts.FileDownloadRequest, 0, len(videos))
+	for _, file := range videos {
+		files = append(files, contracts.FileDownloadRequest{FilePath: file.Path, AutoClassify: true})
+	}
+	result, err := h.deps.GetFileService().DownloadFiles(ctx, contracts.BatchFileDownloadRequest{
+		Files:        files,
+		VideoOnly:    true,
+		AutoClassify: true,
+	})
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("创建下载", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("⬇️", "新增文件下载"),
+		"",
+		formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(dirPath)),
+		formatter.FormatField("文件类型", "仅视频"),
+		formatter.FormatField("成功", fmt.Sprintf("%d 个", result.SuccessCount)),
+	}
+	if result.FailureCount > 0 {
+		lines = append(lines, formatter.FormatField("失败", fmt.Sprintf("%d 个", result.FailureCount)))
+	}
+	if skipped := len(diff.Added) - len(videos); skipped > 0 {
+		lines = append(lines, formatter.FormatField("跳过", fmt.Sprintf("%d 个非视频文件", skipped)))
+	}
+	message := strings.Join(lines, "\n")
+
+	if keyboard := utils.BatchRetryKeyboard(result.BatchID, result.FailureCount); keyboard != nil {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", keyboard)
+		return
+	}
+	msgUtils.SendMessageHTMLWithAutoDelete(chatID, message, types.MessageImportant)
+}
+
+// diffVideoFiles 筛选新增文件中的视频（与目录下载一致，只下载视频）
+func (h *Handler) diffVideoFiles(files []contracts.FileResponse) []contracts.FileResponse {
+	fileService := h.deps.GetFileService()
+	var videos []contracts.FileResponse
+	for _, file := range files {
+		if fileService.IsVideoFile(file.Name) {
+			videos = append(videos, file)
+		}
+	}
+	return videos
+}
+
+// formatDirectoryDiff 格式化目录差异，每类最多显示 maxDiffFilesShown 个文件
+func formatDirectoryDiff(formatter *utils.MessageFormatter, escapeHTML func(string) string, formatSize func(int64) string, diff *contracts.DirectoryDiff) string {
+	lines := []string{
+		formatter.FormatTitle("🔍", "目录变化"),
+		"",
+		formatter.FormatFieldCode("目录", escapeHTML(diff.Path)),
+		formatter.FormatField("快照时间", diff.SnapshotAt.Format("2006-01-02 15:04")),
+		formatter.FormatField("统计", fmt.Sprintf("新增 %d，删除 %d，变化 %d，未变 %d",
+			len(diff.Added), len(diff.Removed), len(diff.Changed), diff.Unchanged)),
+	}
+
+	sections := []struct {
+		title string
+		files []contracts.FileResponse
+	}{
+		{"🆕 新增", diff.Added},
+		{"🗑️ 删除", diff.Removed},
+		{"✏️ 变化", diff.Changed},
+	}
+	for _, section := range sections {
+		if len(section.files) == 0 {
+			continue
+		}
+		lines = append(lines, formatter.FormatSection(section.title))
+		for i, file := range section.files {
+			if i == maxDiffFilesShown {
+				lines = append(lines, formatter.FormatListItem("•", fmt.Sprintf("... 还有 %d 个", len(section.files)-i)))
+				break
+			}
+			lines = append(lines, formatter.FormatListItem("•", fmt.Sprintf("%s（%s）",
+				escapeHTML(relativeDiffPath(diff.Path, file.Path)), formatSize(file.Size))))
+		}
+	}
+
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		lines = append(lines, "", "没有变化")
+	}
+	if diff.Truncated {
+		lines = append(lines, "", "⚠️ 目录过大或部分子目录读取失败，结果可能不完整")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// relativeDiffPath 文件相对于对比目录的路径
+func relativeDiffPath(dirPath, filePath string) string {
+	if rel, found := strings.CutPrefix(filePath, strings.TrimSuffix(dirPath, "/")+"/"); found {
+		return rel
+	}
+	return filePath
+}
@@ -0,0 +1,118 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+)
+
+// ================================
+// 分类规则测试
+// ================================
+
+// maxTestRuleInputs 单次 /testrule 最多测试的文件名数量
+const maxTestRuleInputs = 10
+
+// HandleTestRule 处理 /testrule <文件名> 命令，预览示例文件名命中的分类规则、解析出的媒体信息和最终下载路径
+// 多个文件名按行分隔（只有一行时按空格分隔）；纯本地计算，不访问 Alist/aria2
+func (h *Handler) HandleTestRule(chatID int64, args string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	inputs := parseTestRuleInputs(args)
+	if len(inputs) == 0 {
+		msgUtils.SendMessageHTML(chatID, "用法: /testrule &lt;文件名或路径&gt;\n"+
+			"多个文件名每行一个，例如:\n"+
+			"<code>/testrule\n/tvs/某剧/Show.S01E02.1080p.mkv\nMovie.2023.2160p.mkv</code>")
+		return
+	}
+
+	truncated := 0
+	if len(inputs) > maxTestRuleInputs {
+		truncated = len(inputs) - maxTestRuleInputs
+		inputs = inputs[:maxTestRuleInputs]
+	}
+
+	fileService := h.deps.GetFileService()
+	lines := []string{formatter.FormatTitle("🧪", "分类规则测试")}
+	for _, input := range inputs {
+		result := fileService.TestClassification(input)
+		lines = append(lines, "", formatTestRuleResult(formatter, msgUtils.EscapeHTML, result))
+	}
+	if truncated > 0 {
+		lines = append(lines, "", fmt.Sprintf("⚠️ 单次最多测试 %d 个，其余 %d 个已忽略", maxTestRuleInputs, truncated))
+	}
+
+	msgUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+}
+
+// parseTestRuleInputs 解析待测试的文件名：多行时每行一个（允许文件名含空格），单行时按空格分隔
+func parseTestRuleInputs(args string) []string {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return nil
+	}
+
+	var fields []string
+	if strings.Contains(args, "\n") {
+		fields = strings.Split(args, "\n")
+	} else {
+		fields = strings.Fields(args)
+	}
+
+	inputs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			inputs = append(inputs, field)
+		}
+	}
+	return inputs
+}
+
+// formatTestRuleResult 格式化单个文件名的测试结果
+func formatTestRuleResult(formatter *utils.MessageFormatter, escapeHTML func(string) string, result contracts.ClassificationTestResult) string {
+	lines := []string{
+		formatter.FormatFieldCode("文件", escapeHTML(result.Path)),
+		formatter.FormatField("分类", inventoryCategoryLabel(result.Category)+" ("+result.Category+")"),
+		formatter.FormatField("规则", escapeHTML(result.Rule)),
+	}
+
+	if media := formatTestRuleMedia(result.Media); media != "" {
+		lines = append(lines, formatter.FormatField("解析", escapeHTML(media)))
+	}
+
+	if result.DownloadPath != "" {
+		lines = append(lines,
+			formatter.FormatFieldCode("下载路径", escapeHTML(result.DownloadPath)),
+			formatter.FormatField("路径来源", escapeHTML(result.PathSource)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatTestRuleMedia 格式化从文件名解析出的媒体信息，未解析出标题时返回空字符串
+func formatTestRuleMedia(media contracts.MediaInfo) string {
+	if media.Title == "" {
+		return ""
+	}
+
+	parts := []string{media.Title}
+	if media.Year > 0 {
+		parts = append(parts, fmt.Sprintf("(%d)", media.Year))
+	}
+	if media.Season != nil || media.Episode != nil {
+		tag := ""
+		if media.Season != nil {
+			tag += fmt.Sprintf("S%02d", *media.Season)
+		}
+		if media.Episode != nil {
+			tag += fmt.Sprintf("E%02d", *media.Episode)
+		}
+		parts = append(parts, tag)
+	}
+	if media.Type != "" {
+		parts = append(parts, "["+media.Type+"]")
+	}
+	return strings.Join(parts, " ")
+}
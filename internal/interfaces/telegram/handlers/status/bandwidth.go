@@ -0,0 +1,78 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// bandwidthWindows time windows shown by /bandwidth
+var bandwidthWindows = []struct {
+	label  string
+	window time.Duration
+}{
+	{"最近1小时", time.Hour},
+	{"最近24小时", 24 * time.Hour},
+}
+
+// HandleBandwidth shows recent bandwidth usage sampled from aria2
+func (h *Handler) HandleBandwidth(chatID int64) {
+	ctx := context.Background()
+	msgUtils := h.deps.GetMessageUtils()
+
+	var sb strings.Builder
+	sb.WriteString("<b>📈 带宽使用</b>\n")
+
+	for _, w := range bandwidthWindows {
+		stats, err := h.deps.GetDownloadService().GetBandwidthStats(ctx, w.window)
+		if err != nil {
+			msgUtils.SendMessage(chatID, "获取带宽统计失败: "+err.Error())
+			return
+		}
+		sb.WriteString("\n")
+		sb.WriteString(formatBandwidthSection(w.label, stats, msgUtils))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "api_download_status"),
+			tgbotapi.NewInlineKeyboardButtonData("🏠 返回主菜单", "back_main"),
+		),
+	)
+	msgUtils.SendMessageWithKeyboard(chatID, sb.String(), "HTML", &keyboard)
+}
+
+// formatBandwidthSection formats stats for a single time window
+func formatBandwidthSection(label string, stats *contracts.BandwidthStats, msgUtils types.MessageSender) string {
+	if stats.SampleCount == 0 {
+		return fmt.Sprintf("<b>%s</b>\n暂无采样数据\n", label)
+	}
+
+	speed := func(v int64) string {
+		return msgUtils.FormatFileSize(v) + "/s"
+	}
+
+	return fmt.Sprintf(
+		"<b>%s</b>\n"+
+			"<code>%s</code>\n"+
+			"• 平均: %s\n"+
+			"• 峰值: %s\n"+
+			"• 最低: %s\n"+
+			"• 总量: %s\n"+
+			"• 样本: %d 个（每 %s）\n",
+		label,
+		strutil.Sparkline(stats.Buckets),
+		speed(stats.AvgSpeed),
+		speed(stats.PeakSpeed),
+		speed(stats.MinSpeed),
+		msgUtils.FormatFileSize(stats.TotalBytes),
+		stats.SampleCount,
+		stats.Interval,
+	)
+}
@@ -0,0 +1,70 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+)
+
+// HandleRetryFailedBatch resubmits every failed file of a batch.
+// An empty batchID selects the most recent batch download.
+func (h *Handler) HandleRetryFailedBatch(chatID, userID int64, batchID string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	ctx := contracts.WithUserID(context.Background(), userID)
+	result, err := h.deps.GetDownloadService().RetryFailedBatch(ctx, strings.TrimSpace(batchID))
+	if err != nil {
+		msgUtils.SendMessageHTML(chatID, h.batchRetryErrorMessage(formatter, err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("🔁", "重试失败文件"),
+		"",
+		formatter.FormatFieldCode("批次", result.BatchID),
+	}
+	if result.FailedCount == 0 {
+		lines = append(lines, "", "✅ 该批次没有失败的文件")
+		msgUtils.SendMessageHTMLWithAutoDelete(chatID, strings.Join(lines, "\n"), types.MessageTransient)
+		return
+	}
+
+	lines = append(lines,
+		formatter.FormatField("失败文件", fmt.Sprintf("%d 个", result.FailedCount)),
+		formatter.FormatField("重新提交成功", fmt.Sprintf("%d 个", result.SuccessCount)),
+		formatter.FormatField("仍然失败", fmt.Sprintf("%d 个", result.FailureCount)),
+	)
+	if result.SkippedCount > 0 {
+		lines = append(lines, formatter.FormatField("已跳过", fmt.Sprintf("%d 个（已有完成或进行中的下载）", result.SkippedCount)))
+	}
+
+	if result.FailureCount > 0 {
+		lines = append(lines, "", formatter.FormatSection("失败原因"))
+		lines = append(lines, utils.RetryErrorLines(result.Results, result.FailureCount, msgUtils.EscapeHTML)...)
+	}
+
+	if keyboard := utils.BatchRetryKeyboard(result.BatchID, result.FailureCount); keyboard != nil {
+		msgUtils.SendMessageWithKeyboard(chatID, strings.Join(lines, "\n"), "HTML", keyboard)
+		return
+	}
+	msgUtils.SendMessageHTMLWithAutoDelete(chatID, strings.Join(lines, "\n"), types.MessageImportant)
+}
+
+// batchRetryErrorMessage explains why a batch cannot be retried
+func (h *Handler) batchRetryErrorMessage(formatter *utils.MessageFormatter, err error) string {
+	switch {
+	case errors.Is(err, contracts.ErrBatchNotFound):
+		return "❌ 没有找到批量下载记录\n\n用法：<code>/retryfailed [批次ID]</code>，不指定时重试最近一次批量下载"
+	case errors.Is(err, contracts.ErrBatchRetryExpired):
+		return fmt.Sprintf("❌ %s\n\n批量下载创建后 %d 小时内可以重试",
+			err.Error(), h.deps.GetConfig().Download.BatchRetryWindowHours)
+	default:
+		return formatter.FormatError("重试失败文件", err)
+	}
+}
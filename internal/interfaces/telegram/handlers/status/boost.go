@@ -0,0 +1,72 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// BoostCallbackPrefix is the callback prefix for raising a download's connections and splits: dl_boost:<gid>
+const BoostCallbackPrefix = "dl_boost:"
+
+// HandleBoostDownload doubles max-connection-per-server and split of an unfinished download
+// within the configured limits and reports the applied values.
+// gid may be a unique prefix, as shown in the download list.
+func (h *Handler) HandleBoostDownload(chatID int64, gid string, messageID int) {
+	ctx := context.Background()
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	gid = strings.TrimSpace(gid)
+	if gid == "" {
+		msgUtils.SendMessageHTML(chatID, "用法：<code>/boost &lt;GID&gt;</code>\n\n提高下载任务的每服务器连接数和分段数，GID 可在下载状态列表中查看，输入前几位即可")
+		return
+	}
+
+	fullGID, err := h.resolveGID(ctx, gid)
+	var result *contracts.BoostResult
+	if err == nil {
+		result, err = h.deps.GetDownloadService().BoostDownload(ctx, fullGID)
+	}
+	if err != nil {
+		message := formatter.FormatError("加速", err)
+		if errors.Is(err, contracts.ErrDownloadNotFound) {
+			message = fmt.Sprintf("❓ 未找到任务 <code>%s</code>\n\n任务可能已完成并被清理，或 GID 输入有误", msgUtils.EscapeHTML(gid))
+		}
+		msgUtils.SendMessageHTML(chatID, message)
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("⚡", "加速"),
+		"",
+		formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(result.Filename)),
+		formatter.FormatFieldCode("GID", result.ID),
+	}
+	if result.AtLimit {
+		lines = append(lines,
+			formatter.FormatField("每服务器连接数", fmt.Sprintf("%d", result.ConnectionsAfter)),
+			formatter.FormatField("分段数", fmt.Sprintf("%d", result.SplitAfter)),
+			"",
+			"已达到配置的上限（download.boost），未做修改")
+	} else {
+		lines = append(lines,
+			formatter.FormatField("每服务器连接数", fmt.Sprintf("%d → %d", result.ConnectionsBefore, result.ConnectionsAfter)),
+			formatter.FormatField("分段数", fmt.Sprintf("%d → %d", result.SplitBefore, result.SplitAfter)),
+			"",
+			"新设置已生效，进行中的任务会短暂重启，已下载的部分保留。速度变化需几十秒后才能在任务详情中看到")
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("ℹ️ 任务详情", "task_info:"+result.ID),
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
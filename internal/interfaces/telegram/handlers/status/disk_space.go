@@ -0,0 +1,68 @@
+package status
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/filesystem"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DiskSpaceCallback refreshes the disk space view in place
+const DiskSpaceCallback = "status_storage"
+
+// HandleDiskSpace shows total, used and available space of aria2.download_dir,
+// warning when available space drops below download.disk_guard.warn_free_gb.
+// messageID 0 sends a new message.
+func (h *Handler) HandleDiskSpace(chatID int64, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	cfg := h.deps.GetConfig()
+	dir := cfg.Aria2.DownloadDir
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 刷新", DiskSpaceCallback),
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+			tgbotapi.NewInlineKeyboardButtonData("🏠 主菜单", "back_main"),
+		),
+	)
+
+	lines := []string{
+		formatter.FormatTitle("💾", "下载目录空间"),
+		"",
+		formatter.FormatFieldCode("目录", msgUtils.EscapeHTML(dir)),
+	}
+
+	usage, err := filesystem.GetDiskUsage(dir)
+	if err != nil {
+		if errors.Is(err, filesystem.ErrDirectoryNotExist) {
+			lines = append(lines, "", "📁 下载目录尚不存在，会在首次下载时自动创建",
+				"如果 aria2 运行在其他机器上，无法在本机查看其磁盘空间")
+		} else {
+			lines = append(lines, "", formatter.FormatError("获取磁盘空间", err))
+		}
+		h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+		return
+	}
+
+	lines = append(lines,
+		formatter.FormatProgressBar(usage.UsedPercent(), 20),
+		"",
+		formatter.FormatField("总容量", msgUtils.FormatFileSize(usage.Total)),
+		formatter.FormatField("已用", msgUtils.FormatFileSize(usage.Used)),
+		formatter.FormatField("可用", msgUtils.FormatFileSize(usage.Available)),
+	)
+
+	guard := cfg.Download.DiskGuard
+	if guard.MinFreeGB > 0 {
+		lines = append(lines, formatter.FormatField("自动暂停阈值", fmt.Sprintf("%d GB", guard.MinFreeGB)))
+	}
+	if guard.WarnFreeGB > 0 && usage.Available < int64(guard.WarnFreeGB)<<30 {
+		lines = append(lines, "", fmt.Sprintf("⚠️ 可用空间低于 %d GB，请及时清理下载目录", guard.WarnFreeGB))
+	}
+
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
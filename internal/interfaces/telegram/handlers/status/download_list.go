@@ -0,0 +1,178 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data for the paginated download list: dl_page:<page>, dl_cancel:<gid> asks for
+// confirmation and dl_cancel_ok:<gid> removes the download
+const (
+	DownloadPageCallbackPrefix    = "dl_page:"
+	CancelCallbackPrefix          = "dl_cancel:"
+	CancelConfirmedCallbackPrefix = "dl_cancel_ok:"
+)
+
+// downloadListPageSize downloads shown per page, leaving room for one button row per task
+const downloadListPageSize = 8
+
+// HandleDownloadListPage shows one page (1-based) of downloads: active first, then waiting, then stopped.
+// Only the requested page is fetched from aria2.
+func (h *Handler) HandleDownloadListPage(chatID int64, page int, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+	page = max(page, 1)
+
+	downloads, err := h.deps.GetDownloadService().ListDownloads(context.Background(), contracts.DownloadListRequest{
+		Offset: (page - 1) * downloadListPageSize,
+		Limit:  downloadListPageSize,
+	})
+	if err != nil {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("重试", "api_download_status"),
+				tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
+			),
+		)
+		h.renderMessage(chatID, messageID, formatter.FormatError("获取下载状态", err), &keyboard)
+		return
+	}
+
+	// The list may have shrunk since the page button was rendered
+	totalPages := max((downloads.TotalCount+downloadListPageSize-1)/downloadListPageSize, 1)
+	if page > totalPages {
+		h.HandleDownloadListPage(chatID, totalPages, messageID)
+		return
+	}
+
+	listData := utils.DownloadListData{
+		TotalCount:  downloads.TotalCount,
+		ActiveCount: downloads.ActiveCount,
+		PausedCount: downloads.PausedCount,
+		AllPaused:   downloads.AllPaused,
+		Offset:      (page - 1) * downloadListPageSize,
+		Page:        page,
+		TotalPages:  totalPages,
+	}
+	for _, d := range downloads.Downloads {
+		item := utils.DownloadItemData{
+			StatusEmoji: downloadStatusEmoji(string(d.Status)),
+			ID:          d.ID,
+			Filename:    d.Filename,
+			Progress:    d.Progress,
+		}
+		if d.SpeedLimit > 0 {
+			item.SpeedLimit = msgUtils.FormatFileSize(d.SpeedLimit)
+		}
+		listData.Downloads = append(listData.Downloads, item)
+	}
+	for _, c := range downloads.Categories {
+		label := categoryLabels[c.Category]
+		if label == "" {
+			label = c.Category
+		}
+		listData.Categories = append(listData.Categories, utils.CategoryConcurrencyData{
+			Label:   label,
+			Limit:   c.Limit,
+			Active:  c.Active,
+			Waiting: c.Waiting,
+			Held:    c.Held,
+		})
+	}
+
+	// One row per task, numbered as in the message: details, pause/resume and cancel
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, d := range downloads.Downloads {
+		number := listData.Offset + i + 1
+		row := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("ℹ️ %d", number), "task_info:"+d.ID),
+		)
+		switch d.Status {
+		case valueobjects.DownloadStatusActive, valueobjects.DownloadStatusPending:
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⏸ %d", number), PauseCallbackPrefix+d.ID))
+		case valueobjects.DownloadStatusPaused:
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("▶️ %d", number), ResumeCallbackPrefix+d.ID))
+		}
+		if d.Status.CanPause() || d.Status == valueobjects.DownloadStatusPaused {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🗑 %d", number), CancelCallbackPrefix+d.ID))
+		}
+		rows = append(rows, row)
+	}
+
+	var navButtons []tgbotapi.InlineKeyboardButton
+	if page > 1 {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("< 上一页", fmt.Sprintf("%s%d", DownloadPageCallbackPrefix, page-1)))
+	}
+	if page < totalPages {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("下一页 >", fmt.Sprintf("%s%d", DownloadPageCallbackPrefix, page+1)))
+	}
+	if len(navButtons) > 0 {
+		rows = append(rows, navButtons)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏸️ 全部暂停", QueuePauseAllConfirmCallback),
+			tgbotapi.NewInlineKeyboardButtonData("▶️ 全部恢复", QueueResumeAllConfirmCallback),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("刷新状态", fmt.Sprintf("%s%d", DownloadPageCallbackPrefix, page)),
+			tgbotapi.NewInlineKeyboardButtonData("下载管理", "menu_download"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🕘 最近完成", "recent_downloads"),
+			tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
+		),
+	)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	h.renderMessage(chatID, messageID, formatter.FormatDownloadList(listData), &keyboard)
+}
+
+// HandleCancelDownloadConfirm asks for confirmation before cancelling a single download
+func (h *Handler) HandleCancelDownloadConfirm(chatID int64, gid string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	lines := []string{formatter.FormatTitle("🗑", "取消下载"), ""}
+	if download, err := h.deps.GetDownloadService().GetDownload(context.Background(), gid); err == nil {
+		lines = append(lines, formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(download.Filename)))
+	}
+	lines = append(lines, formatter.FormatFieldCode("GID", gid), "", "确认取消此任务？")
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ 确认取消", CancelConfirmedCallbackPrefix+gid),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 返回", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// HandleCancelDownload cancels a single download and offers a way back to the list
+func (h *Handler) HandleCancelDownload(chatID int64, gid string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	message := formatter.FormatDownloadCancelled(gid)
+	if err := h.deps.GetDownloadService().CancelDownload(context.Background(), gid); err != nil {
+		message = formatter.FormatError("取消下载", err)
+		if errors.Is(err, contracts.ErrDownloadNotFound) {
+			message = fmt.Sprintf("❓ 未找到任务 <code>%s</code>\n\n任务可能已完成并被清理", msgUtils.EscapeHTML(gid))
+		}
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+			tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
+		),
+	)
+	h.renderMessage(chatID, messageID, message, &keyboard)
+}
@@ -0,0 +1,56 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// DownloadNowCallbackPrefix is the callback prefix for moving a waiting download to the front: dl_now:<gid>
+const DownloadNowCallbackPrefix = "dl_now:"
+
+// HandleDownloadNow moves a waiting or paused download to the front of the queue
+// and reports what was reprioritized.
+func (h *Handler) HandleDownloadNow(chatID int64, gid string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	result, err := h.deps.GetDownloadService().PrioritizeDownload(context.Background(), gid)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("立即下载", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("⚡", "立即下载"),
+		"",
+		formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(result.Filename)),
+		formatter.FormatFieldCode("GID", result.ID),
+	}
+	if result.Resumed {
+		lines = append(lines, formatter.FormatField("状态", "已从暂停中恢复"))
+	}
+	switch {
+	case len(result.Paused) > 0:
+		lines = append(lines, formatter.FormatField("结果", "已移到队首，并暂停以下任务腾出下载槽位："))
+		for _, d := range result.Paused {
+			lines = append(lines, fmt.Sprintf("• %s（%.1f%%）", msgUtils.EscapeHTML(d.Filename), d.Progress))
+		}
+		lines = append(lines, "", "被暂停的任务不会自动恢复，可在任务详情中点击 ⚡ 继续，或发送 /resumeall")
+	case result.QueueFull:
+		lines = append(lines, formatter.FormatField("结果", "已移到队首，同时下载数已满，将在下一个任务结束后开始"))
+	default:
+		lines = append(lines, formatter.FormatField("结果", "已移到队首，马上开始下载"))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("ℹ️ 任务详情", "task_info:"+result.ID),
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
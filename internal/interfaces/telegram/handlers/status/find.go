@@ -0,0 +1,73 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+)
+
+// categoryLabels display names of the categories that routed a download
+var categoryLabels = map[string]string{
+	"movie":    "电影",
+	"tv":       "电视剧",
+	"variety":  "综艺",
+	"video":    "其他视频",
+	"music":    "音乐",
+	"document": "文档",
+	"other":    "其他",
+}
+
+// HandleFindCommand handles /find <keyword>: lists the local paths of downloaded files whose name contains the keyword
+func (h *Handler) HandleFindCommand(chatID int64, args string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	keyword := strings.TrimSpace(args)
+	if keyword == "" {
+		msgUtils.SendMessageHTML(chatID, "用法：<code>/find &lt;关键词&gt;</code>\n\n按文件名查找已下载文件在本机的位置（先查下载记录，找不到时扫描下载目录）")
+		return
+	}
+
+	result, err := h.deps.GetDownloadService().FindDownloadedFiles(context.Background(), keyword)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("查找文件", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("🔍", "查找已下载文件"),
+		"",
+		formatter.FormatFieldCode("关键词", msgUtils.EscapeHTML(result.Keyword)),
+	}
+	if len(result.Matches) == 0 {
+		lines = append(lines, "", "未找到匹配的文件（已查找下载记录和下载目录）")
+		msgUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+		return
+	}
+
+	source := "下载记录"
+	if result.DiskSearched {
+		source = "下载目录扫描（下载记录中没有匹配）"
+	}
+	lines = append(lines, formatter.FormatField("来源", source), "")
+
+	for i, match := range result.Matches {
+		category := categoryLabels[match.Category]
+		if category == "" {
+			category = "未分类"
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s (%s)\n   📁 <code>%s</code>\n   🏷️ %s",
+			i+1,
+			msgUtils.EscapeHTML(match.Filename),
+			msgUtils.FormatFileSize(match.Size),
+			msgUtils.EscapeHTML(match.Path),
+			category))
+	}
+	if result.Truncated {
+		lines = append(lines, "", "⚠️ 结果较多或目录过大，仅显示部分结果，请使用更精确的关键词")
+	}
+
+	msgUtils.SendMessageHTML(chatID, strings.Join(lines, "\n"))
+}
@@ -4,10 +4,12 @@ package status
 
 import (
 	"context"
+	"fmt"
 	"runtime"
 	"time"
 
 	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/alist"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -15,13 +17,15 @@ import (
 
 // Handler handles status query related functions
 type Handler struct {
-	deps StatusDeps
+	deps    StatusDeps
+	tracker *progressTracker
 }
 
 // NewHandler creates a new status handler
 func NewHandler(deps StatusDeps) *Handler {
 	return &Handler{
-		deps: deps,
+		deps:    deps,
+		tracker: newProgressTracker(),
 	}
 }
 
@@ -31,71 +35,25 @@ func NewHandler(deps StatusDeps) *Handler {
 
 // HandleDownloadStatusAPIWithEdit handles download status API (supports message editing)
 func (h *Handler) HandleDownloadStatusAPIWithEdit(chatID int64, messageID int) {
-	ctx := context.Background()
-	listReq := contracts.DownloadListRequest{
-		Limit: 100,
-	}
-	msgUtils := h.deps.GetMessageUtils()
-
-	downloads, err := h.deps.GetDownloadService().ListDownloads(ctx, listReq)
-	if err != nil {
-		message := "获取下载状态失败: " + err.Error()
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("重试", "api_download_status"),
-				tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
-			),
-		)
-		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
-		return
-	}
-
-	// Build download list data
-	var downloadItems []utils.DownloadItemData
-	for _, d := range downloads.Downloads {
-		// Get status emoji
-		statusEmoji := "❓"
-		switch string(d.Status) {
-		case "active", "running":
-			statusEmoji = "🔄"
-		case "complete", "completed":
-			statusEmoji = "✅"
-		case "paused":
-			statusEmoji = "⏸️"
-		case "error", "failed":
-			statusEmoji = "❌"
-		case "waiting", "pending":
-			statusEmoji = "⏳"
-		}
-
-		downloadItems = append(downloadItems, utils.DownloadItemData{
-			StatusEmoji: statusEmoji,
-			ID:          d.ID,
-			Filename:    d.Filename,
-			Progress:    d.Progress,
-		})
-	}
+	h.HandleDownloadListPage(chatID, 1, messageID)
+}
 
-	// 使用统一格式化器
-	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
-	listData := utils.DownloadListData{
-		TotalCount:  downloads.TotalCount,
-		ActiveCount: downloads.ActiveCount,
-		Downloads:   downloadItems,
+// downloadStatusEmoji returns the emoji shown for a download status
+func downloadStatusEmoji(status string) string {
+	switch status {
+	case "active", "running":
+		return "🔄"
+	case "complete", "completed":
+		return "✅"
+	case "paused":
+		return "⏸️"
+	case "error", "failed":
+		return "❌"
+	case "waiting", "pending":
+		return "⏳"
+	default:
+		return "❓"
 	}
-	message := formatter.FormatDownloadList(listData)
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("刷新状态", "api_download_status"),
-			tgbotapi.NewInlineKeyboardButtonData("下载管理", "menu_download"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
-		),
-	)
-
-	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
 }
 
 // ================================
@@ -185,10 +143,13 @@ func (h *Handler) HandleHealthCheckWithEdit(chatID int64, messageID int) {
 		ServiceStatus:  "✅ 正常运行",
 		Port:           cfg.Server.Port,
 		Mode:           cfg.Server.Mode,
+		ReadOnly:       cfg.Server.ReadOnly,
 		AlistURL:       msgUtils.EscapeHTML(cfg.Alist.BaseURL),
 		AlistPath:      msgUtils.EscapeHTML(cfg.Alist.DefaultPath),
 		Aria2RPC:       msgUtils.EscapeHTML(cfg.Aria2.RpcURL),
 		Aria2Dir:       msgUtils.EscapeHTML(cfg.Aria2.DownloadDir),
+		Aria2Status:    formatAria2Health(h.deps.GetDownloadService().GetAria2Health()),
+		SpeedLimited:   h.speedLimitedDownloads(),
 		TelegramStatus: telegramStatus,
 		TelegramUsers:  telegramUsers,
 		TelegramAdmins: telegramAdmins,
@@ -242,54 +203,50 @@ func (h *Handler) HandleStatusRealtimeWithEdit(chatID int64, messageID int) {
 
 // HandleStatusStorageWithEdit handles storage status monitoring (supports message editing)
 func (h *Handler) HandleStatusStorageWithEdit(chatID int64, messageID int) {
-	msgUtils := h.deps.GetMessageUtils()
-
-	message := "<b>存储状态监控</b>\n\n" +
-		"<b>存储信息:</b>\n" +
-		"• 下载目录: /downloads\n" +
-		"• 可用空间: 计算中...\n" +
-		"• 已用空间: 计算中...\n\n" +
-		"<b>文件统计:</b>\n" +
-		"• 总文件数: 获取中...\n" +
-		"• 今日下载: 获取中...\n\n" +
-		"详细存储信息正在计算中..."
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("刷新状态", "status_storage"),
-			tgbotapi.NewInlineKeyboardButtonData("下载统计", "api_download_status"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回状态监控", "menu_status"),
-		),
-	)
-
-	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+	h.HandleDiskSpace(chatID, messageID)
 }
 
 // HandleStatusHistoryWithEdit handles historical statistics (supports message editing)
 func (h *Handler) HandleStatusHistoryWithEdit(chatID int64, messageID int) {
-	msgUtils := h.deps.GetMessageUtils()
+	h.HandleStats(chatID, "", messageID)
+}
 
-	message := "<b>历史统计数据</b>\n\n" +
-		"<b>下载历史:</b>\n" +
-		"• 昨日下载任务: 查询中...\n" +
-		"• 本周总下载: 查询中...\n" +
-		"• 本月总下载: 查询中...\n\n" +
-		"<b>文件统计:</b>\n" +
-		"• 电影文件: 统计中...\n" +
-		"• 电视剧集: 统计中...\n" +
-		"• 其他文件: 统计中...\n\n"
+// formatAria2Health formats aria2 connection health for status views
+func formatAria2Health(health contracts.ComponentHealth) string {
+	switch health.Status {
+	case contracts.HealthStatusHealthy:
+		if version, _ := health.Details["version"].(string); version != "" {
+			return "✅ 已连接 (v" + version + ")"
+		}
+		return "✅ 已连接"
+	case contracts.HealthStatusUnhealthy:
+		if auth, _ := health.Details["auth"].(string); auth == "failed" {
+			return "❌ RPC 密钥错误，请检查 aria2.token"
+		}
+		if since, ok := health.Details["since"].(time.Time); ok && !since.IsZero() {
+			return "❌ aria2 当前不可用（自 " + since.Format("01-02 15:04:05") + "）"
+		}
+		return "❌ aria2 当前不可用"
+	default:
+		return "❔ 检查中"
+	}
+}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("定时任务", "cmd_tasks"),
-			tgbotapi.NewInlineKeyboardButtonData("当前状态", "api_download_status"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("返回状态监控", "menu_status"),
-		),
-	)
+// speedLimitedDownloads lists active downloads that have a per-task speed limit,
+// so users can confirm a "/download <url> speed=..." limit took effect
+func (h *Handler) speedLimitedDownloads() []string {
+	// Only active downloads carry a speed limit
+	downloads, err := h.deps.GetDownloadService().ListDownloads(context.Background(), contracts.DownloadListRequest{Status: valueobjects.DownloadStatusActive})
+	if err != nil {
+		return nil
+	}
 
-	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+	msgUtils := h.deps.GetMessageUtils()
+	var limited []string
+	for _, d := range downloads.Downloads {
+		if d.SpeedLimit > 0 {
+			limited = append(limited, fmt.Sprintf("<code>%s</code> %s/s", d.ID, msgUtils.FormatFileSize(d.SpeedLimit)))
+		}
+	}
+	return limited
 }
@@ -0,0 +1,112 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback prefixes for pausing and resuming a single download: dl_pause:<gid>, dl_resume:<gid>
+const (
+	PauseCallbackPrefix  = "dl_pause:"
+	ResumeCallbackPrefix = "dl_resume:"
+)
+
+// HandlePauseDownload pauses (pause=true) or resumes a single download.
+// gid may be a unique prefix, as shown in the download list.
+func (h *Handler) HandlePauseDownload(chatID int64, gid string, messageID int, pause bool) {
+	ctx := context.Background()
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	command, operation := "/resume", "恢复下载"
+	if pause {
+		command, operation = "/pause", "暂停下载"
+	}
+
+	gid = strings.TrimSpace(gid)
+	if gid == "" {
+		msgUtils.SendMessageHTML(chatID, fmt.Sprintf("用法：<code>%s &lt;GID&gt;</code>\n\nGID 可在下载状态列表中查看，输入前几位即可", command))
+		return
+	}
+
+	downloadService := h.deps.GetDownloadService()
+	fullGID, err := h.resolveGID(ctx, gid)
+	if err == nil {
+		if pause {
+			err = downloadService.PauseDownload(ctx, fullGID)
+		} else {
+			err = downloadService.ResumeDownload(ctx, fullGID)
+		}
+	}
+	if err != nil {
+		message := formatter.FormatError(operation, err)
+		if errors.Is(err, contracts.ErrDownloadNotFound) {
+			message = fmt.Sprintf("❓ 未找到任务 <code>%s</code>\n\n任务可能已完成并被清理，或 GID 输入有误", msgUtils.EscapeHTML(gid))
+		}
+		h.renderMessage(chatID, messageID, message, nil)
+		return
+	}
+
+	emoji, title, toggle := "▶️", "已恢复下载", tgbotapi.NewInlineKeyboardButtonData("⏸ 暂停", PauseCallbackPrefix+fullGID)
+	if pause {
+		emoji, title, toggle = "⏸️", "已暂停下载", tgbotapi.NewInlineKeyboardButtonData("▶️ 恢复", ResumeCallbackPrefix+fullGID)
+	}
+	lines := []string{formatter.FormatTitle(emoji, title), ""}
+	if download, err := downloadService.GetDownload(ctx, fullGID); err == nil {
+		lines = append(lines, formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(download.Filename)))
+	}
+	lines = append(lines, formatter.FormatFieldCode("GID", fullGID))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			toggle,
+			tgbotapi.NewInlineKeyboardButtonData("ℹ️ 任务详情", "task_info:"+fullGID),
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// HandleDownloadControlWithEdit shows queue counts with the global pause/resume controls
+func (h *Handler) HandleDownloadControlWithEdit(chatID int64, messageID int) {
+	formatter := h.deps.GetMessageUtils().GetFormatter().(*utils.MessageFormatter)
+
+	// Filtering by waiting status counts the whole queue without listing stopped downloads
+	waiting, err := h.deps.GetDownloadService().ListDownloads(context.Background(), contracts.DownloadListRequest{Status: valueobjects.DownloadStatusPending, Limit: 1})
+	if err != nil {
+		h.renderMessage(chatID, messageID, formatter.FormatError("获取下载列表", err), nil)
+		return
+	}
+	all, err := h.deps.GetDownloadService().ListDownloads(context.Background(), contracts.DownloadListRequest{Limit: 1})
+	if err != nil {
+		h.renderMessage(chatID, messageID, formatter.FormatError("获取下载列表", err), nil)
+		return
+	}
+
+	message := formatter.FormatDownloadControl(utils.DownloadControlData{
+		ActiveCount:  all.ActiveCount,
+		WaitingCount: waiting.TotalCount,
+		PausedCount:  all.PausedCount,
+		TotalCount:   all.TotalCount,
+	}) + "\n\n发送 <code>/pause &lt;GID&gt;</code> 或 <code>/resume &lt;GID&gt;</code> 控制单个任务"
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏸ 暂停全部", QueuePauseAllConfirmCallback),
+			tgbotapi.NewInlineKeyboardButtonData("▶️ 恢复全部", QueueResumeAllConfirmCallback),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 取消全部", QueueCancelAllConfirmCallback),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+			tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
+		),
+	)
+	h.renderMessage(chatID, messageID, message, &keyboard)
+}
@@ -0,0 +1,290 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data for live progress tracking: dl_track:<gid> starts tracking in the pressed message,
+// dl_untrack stops tracking the pressed message
+const (
+	TrackCallbackPrefix = "dl_track:"
+	UntrackCallback     = "dl_untrack"
+)
+
+const (
+	// minProgressEditInterval keeps edits of one message well below Telegram's rate limits
+	minProgressEditInterval = 3 * time.Second
+	// defaultProgressTimeout applies when telegram.progress_tracking.timeout_minutes is 0
+	defaultProgressTimeout = 30 * time.Minute
+)
+
+var (
+	errTrackingStopped  = errors.New("progress tracking stopped by user")
+	errTrackingReplaced = errors.New("progress tracking restarted")
+	errTrackingShutdown = errors.New("progress tracking shut down")
+	errTrackingTimeout  = errors.New("progress tracking timed out")
+)
+
+// trackKey identifies a tracked message
+type trackKey struct {
+	chatID    int64
+	messageID int
+}
+
+// progressTracker owns the polling goroutines of all tracked messages
+type progressTracker struct {
+	mu       sync.Mutex
+	cancels  map[trackKey]context.CancelCauseFunc
+	shutdown bool
+	wg       sync.WaitGroup
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{cancels: make(map[trackKey]context.CancelCauseFunc)}
+}
+
+// start runs poll for key in a new goroutine, replacing any tracking of the same message.
+// Returns false after stopAll.
+func (t *progressTracker) start(key trackKey, timeout time.Duration, poll func(ctx context.Context)) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.shutdown {
+		return false
+	}
+	if cancel, ok := t.cancels[key]; ok {
+		cancel(errTrackingReplaced)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	t.cancels[key] = cancel
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer t.finish(ctx, key)
+		timeoutCtx, cancelTimeout := context.WithTimeoutCause(ctx, timeout, errTrackingTimeout)
+		defer cancelTimeout()
+		poll(timeoutCtx)
+	}()
+	return true
+}
+
+// finish forgets key unless it has been taken over by a newer tracking of the same message
+func (t *progressTracker) finish(ctx context.Context, key trackKey) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if cancel, ok := t.cancels[key]; ok && context.Cause(ctx) != errTrackingReplaced {
+		cancel(nil)
+		delete(t.cancels, key)
+	}
+}
+
+// stop stops tracking one message, returns false if it was not tracked
+func (t *progressTracker) stop(key trackKey) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cancel, ok := t.cancels[key]
+	if ok {
+		cancel(errTrackingStopped)
+	}
+	return ok
+}
+
+// stopAll stops every tracking and waits for the goroutines to exit; later starts are refused
+func (t *progressTracker) stopAll() {
+	t.mu.Lock()
+	t.shutdown = true
+	for _, cancel := range t.cancels {
+		cancel(errTrackingShutdown)
+	}
+	t.mu.Unlock()
+	t.wg.Wait()
+}
+
+// StartProgressTracking shows the progress of a download and keeps editing the message
+// until the download finishes, the user stops tracking, or the configured timeout passes.
+// messageID 0 sends a new message to track.
+func (h *Handler) StartProgressTracking(chatID int64, gid string, messageID int) {
+	ctx := context.Background()
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	fullGID, err := h.resolveGID(ctx, strings.TrimSpace(gid))
+	var download *contracts.DownloadResponse
+	if err == nil {
+		download, err = h.deps.GetDownloadService().GetDownload(ctx, fullGID)
+	}
+	if err != nil {
+		message := formatter.FormatError("获取下载状态", err)
+		if errors.Is(err, contracts.ErrDownloadNotFound) {
+			message = fmt.Sprintf("❓ 未找到任务 <code>%s</code>\n\n任务可能已完成并被清理", msgUtils.EscapeHTML(gid))
+		}
+		h.renderMessage(chatID, messageID, message, nil)
+		return
+	}
+
+	cfg := h.deps.GetConfig().Telegram.ProgressTracking
+	interval := max(time.Duration(cfg.IntervalSeconds)*time.Second, minProgressEditInterval)
+	timeout := time.Duration(cfg.TimeoutMinutes) * time.Minute
+	if timeout <= 0 {
+		timeout = defaultProgressTimeout
+	}
+
+	if progressFinished(download.Status) {
+		h.renderTrackedProgress(chatID, messageID, download, "")
+		return
+	}
+
+	text, keyboard := h.formatTrackedProgress(download, trackingFooter(interval))
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, text, "HTML", &keyboard)
+	} else if messageID = msgUtils.SendMessageWithKeyboard(chatID, text, "HTML", &keyboard); messageID == 0 {
+		return
+	}
+
+	started := h.tracker.start(trackKey{chatID, messageID}, timeout, func(ctx context.Context) {
+		h.pollProgress(ctx, chatID, messageID, fullGID, interval, text)
+	})
+	if started {
+		logger.Debug("Progress tracking started", "chatID", chatID, "messageID", messageID, "gid", fullGID)
+	}
+}
+
+// StopProgressTracking stops tracking the given message (the "stop" button)
+func (h *Handler) StopProgressTracking(chatID int64, messageID int) {
+	if !h.tracker.stop(trackKey{chatID, messageID}) {
+		// Tracking already ended (e.g. restart): just drop the stale button
+		h.deps.GetMessageUtils().ClearInlineKeyboard(chatID, messageID)
+	}
+}
+
+// StopAllProgressTracking stops all tracked messages and waits for their goroutines, used on shutdown
+func (h *Handler) StopAllProgressTracking() {
+	h.tracker.stopAll()
+}
+
+// pollProgress refreshes the tracked message until the download finishes or ctx ends.
+// Unchanged content is not re-sent, so idle downloads cost no edits.
+func (h *Handler) pollProgress(ctx context.Context, chatID int64, messageID int, gid string, interval time.Duration, lastText string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	footer := trackingFooter(interval)
+	var last *contracts.DownloadResponse
+	for {
+		select {
+		case <-ctx.Done():
+			switch context.Cause(ctx) {
+			case errTrackingStopped:
+				h.renderStoppedProgress(chatID, messageID, gid, last, "⏹ 已停止实时跟踪")
+			case errTrackingTimeout:
+				h.renderStoppedProgress(chatID, messageID, gid, last, "⌛ 实时跟踪已超时停止")
+			}
+			logger.Debug("Progress tracking ended", "chatID", chatID, "messageID", messageID, "gid", gid, "reason", context.Cause(ctx))
+			return
+		case <-ticker.C:
+		}
+
+		download, err := h.deps.GetDownloadService().GetDownload(ctx, gid)
+		if errors.Is(err, contracts.ErrDownloadNotFound) {
+			h.renderMessage(chatID, messageID, fmt.Sprintf("🗑 任务 <code>%s</code> 已被取消或清理", gid), nil)
+			return
+		}
+		if err != nil {
+			// Transient aria2 errors: keep the last shown state and retry on the next tick
+			logger.Debug("Failed to refresh tracked download", "gid", gid, "error", err)
+			continue
+		}
+		last = download
+
+		if progressFinished(download.Status) {
+			h.renderTrackedProgress(chatID, messageID, download, "")
+			return
+		}
+		text, keyboard := h.formatTrackedProgress(download, footer)
+		if text != lastText {
+			h.deps.GetMessageUtils().EditMessageWithKeyboard(chatID, messageID, text, "HTML", &keyboard)
+			lastText = text
+		}
+	}
+}
+
+// renderStoppedProgress shows the last known state with a button to resume tracking
+func (h *Handler) renderStoppedProgress(chatID int64, messageID int, gid string, download *contracts.DownloadResponse, footer string) {
+	if download == nil {
+		current, err := h.deps.GetDownloadService().GetDownload(context.Background(), gid)
+		if err != nil {
+			h.deps.GetMessageUtils().ClearInlineKeyboard(chatID, messageID)
+			return
+		}
+		download = current
+	}
+	h.renderTrackedProgress(chatID, messageID, download, footer)
+}
+
+// renderTrackedProgress renders the final (untracked) state of a download
+func (h *Handler) renderTrackedProgress(chatID int64, messageID int, download *contracts.DownloadResponse, footer string) {
+	text, _ := h.formatTrackedProgress(download, footer)
+	row := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("ℹ️ 任务详情", "task_info:"+download.ID))
+	if !progressFinished(download.Status) {
+		row = append([]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardButtonData("📡 实时进度", TrackCallbackPrefix+download.ID)}, row...)
+	}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row)
+	h.renderMessage(chatID, messageID, text, &keyboard)
+}
+
+// formatTrackedProgress formats a download with its progress bar, and the keyboard shown while tracking
+func (h *Handler) formatTrackedProgress(download *contracts.DownloadResponse, footer string) (string, tgbotapi.InlineKeyboardMarkup) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	text := formatter.FormatDownloadStatus(utils.DownloadStatusData{
+		StatusEmoji:    downloadStatusEmoji(string(download.Status)),
+		StatusText:     download.Status.ChineseName(),
+		ID:             download.ID,
+		Filename:       msgUtils.EscapeHTML(download.Filename),
+		Progress:       download.Progress,
+		CompletedSize:  download.CompletedSize,
+		TotalSize:      download.TotalSize,
+		Speed:          download.Speed,
+		ErrorMessage:   msgUtils.EscapeHTML(download.ErrorMessage),
+		FormatFileSize: msgUtils.FormatFileSize,
+	})
+	if footer != "" {
+		text += "\n\n" + footer
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏹ 停止跟踪", UntrackCallback),
+			tgbotapi.NewInlineKeyboardButtonData("ℹ️ 任务详情", "task_info:"+download.ID),
+		),
+	)
+	return text, keyboard
+}
+
+// trackingFooter is appended to the message while it is being tracked
+func trackingFooter(interval time.Duration) string {
+	return fmt.Sprintf("📡 实时跟踪中，每 %d 秒更新", int(interval.Seconds()))
+}
+
+// progressFinished reports whether a download will not make further progress
+func progressFinished(status valueobjects.DownloadStatus) bool {
+	switch status {
+	case valueobjects.DownloadStatusComplete, valueobjects.DownloadStatusError, valueobjects.DownloadStatusRemoved:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,160 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback data of the global pause/resume controls
+const (
+	QueuePauseAllConfirmCallback  = "queue_pause_all_confirm"
+	QueuePauseAllCallback         = "queue_pause_all"
+	QueueResumeAllConfirmCallback = "queue_resume_all_confirm"
+	QueueResumeAllCallback        = "queue_resume_all"
+	QueueCancelAllConfirmCallback = "queue_cancel_all_confirm"
+	QueueCancelAllCallback        = "queue_cancel_all"
+)
+
+// maxCancelFailuresShown limits the failed GIDs listed after /cancelall
+const maxCancelFailuresShown = 5
+
+// HandleQueueControlConfirm asks for confirmation before pausing or resuming the whole queue.
+// The message is edited when messageID > 0, otherwise a new one is sent.
+func (h *Handler) HandleQueueControlConfirm(chatID int64, messageID int, pause bool) {
+	formatter := h.deps.GetMessageUtils().GetFormatter().(*utils.MessageFormatter)
+
+	title, description, confirmLabel, confirmData := "确认恢复全部下载", "所有已暂停的任务将重新开始下载", "✅ 确认恢复", QueueResumeAllCallback
+	if pause {
+		title, description, confirmLabel, confirmData = "确认暂停全部下载", "所有活动和等待中的任务都会暂停，释放下载带宽", "✅ 确认暂停", QueuePauseAllCallback
+	}
+
+	lines := []string{
+		formatter.FormatTitle("⚠️", title),
+		"",
+		description,
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(confirmLabel, confirmData),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// HandleQueueControl pauses or resumes every task in the aria2 queue and reports how many were affected
+func (h *Handler) HandleQueueControl(chatID int64, messageID int, pause bool) {
+	formatter := h.deps.GetMessageUtils().GetFormatter().(*utils.MessageFormatter)
+	downloadService := h.deps.GetDownloadService()
+
+	var affected int
+	var err error
+	if pause {
+		affected, err = downloadService.PauseAllDownloads(context.Background())
+	} else {
+		affected, err = downloadService.ResumeAllDownloads(context.Background())
+	}
+
+	operation, emoji, title, field := "恢复全部下载", "▶️", "已恢复全部下载", "恢复任务"
+	if pause {
+		operation, emoji, title, field = "暂停全部下载", "⏸️", "已暂停全部下载", "暂停任务"
+	}
+	if err != nil {
+		h.renderMessage(chatID, messageID, formatter.FormatError(operation, err), nil)
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle(emoji, title),
+		"",
+		formatter.FormatField(field, fmt.Sprintf("%d 个", affected)),
+	}
+	if pause {
+		lines = append(lines, "", "发送 /resumeall 或点击下方按钮恢复下载")
+	}
+
+	toggle := tgbotapi.NewInlineKeyboardButtonData("⏸️ 全部暂停", QueuePauseAllConfirmCallback)
+	if pause {
+		toggle = tgbotapi.NewInlineKeyboardButtonData("▶️ 全部恢复", QueueResumeAllConfirmCallback)
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			toggle,
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// HandleCancelAllConfirm asks for confirmation before cancelling every active and waiting task.
+// The message is edited when messageID > 0, otherwise a new one is sent.
+func (h *Handler) HandleCancelAllConfirm(chatID int64, messageID int) {
+	formatter := h.deps.GetMessageUtils().GetFormatter().(*utils.MessageFormatter)
+
+	lines := []string{
+		formatter.FormatTitle("⚠️", "确认取消全部下载"),
+		"",
+		"所有活动和等待中的任务都会被取消，已下载的部分不会保留进度",
+		"已暂停的任务不受影响",
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 确认取消全部", QueueCancelAllCallback),
+			tgbotapi.NewInlineKeyboardButtonData("❌ 返回", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// HandleCancelAll cancels every active and waiting task, continuing past individual failures,
+// and reports how many were cancelled
+func (h *Handler) HandleCancelAll(chatID int64, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	result, err := h.deps.GetDownloadService().CancelAllDownloads(context.Background())
+	if err != nil {
+		h.renderMessage(chatID, messageID, formatter.FormatError("取消全部下载", err), nil)
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+		),
+	)
+	if result.Total == 0 {
+		h.renderMessage(chatID, messageID, "没有活动或等待中的下载任务", &keyboard)
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("🗑", "已取消全部下载"),
+		"",
+		formatter.FormatField("已取消", fmt.Sprintf("%d/%d 个", result.Cancelled, result.Total)),
+	}
+	if result.NotFound > 0 {
+		lines = append(lines, formatter.FormatField("已结束", fmt.Sprintf("%d 个（取消前已完成或被清理）", result.NotFound)))
+	}
+	if result.Failed > 0 {
+		lines = append(lines, formatter.FormatField("失败", fmt.Sprintf("%d 个", result.Failed)), "")
+		shown := 0
+		for _, item := range result.Items {
+			if item.Status != contracts.CancelStatusFailed {
+				continue
+			}
+			if shown == maxCancelFailuresShown {
+				lines = append(lines, fmt.Sprintf("... 还有 %d 个", result.Failed-shown))
+				break
+			}
+			lines = append(lines, fmt.Sprintf("• <code>%s</code>: %s", item.ID, msgUtils.EscapeHTML(item.Error)))
+			shown++
+		}
+	}
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
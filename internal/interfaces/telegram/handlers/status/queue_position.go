@@ -0,0 +1,69 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Callback prefixes for moving a waiting download within the queue: dl_top:<gid>, dl_bottom:<gid>
+const (
+	MoveTopCallbackPrefix    = "dl_top:"
+	MoveBottomCallbackPrefix = "dl_bottom:"
+)
+
+// HandleChangePosition moves a waiting or paused download to the front (top=true) or the end of the queue.
+// Unlike "download now" it never resumes the task or pauses others.
+func (h *Handler) HandleChangePosition(chatID int64, gid string, messageID int, top bool) {
+	ctx := context.Background()
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	operation, how := "置底", contracts.PositionFromEnd
+	if top {
+		operation, how = "置顶", contracts.PositionFromStart
+	}
+
+	downloadService := h.deps.GetDownloadService()
+	position, err := downloadService.ChangePosition(ctx, gid, 0, how)
+	if err != nil {
+		message := formatter.FormatError(operation, err)
+		if errors.Is(err, contracts.ErrDownloadNotFound) {
+			message = fmt.Sprintf("❓ 未找到任务 <code>%s</code>\n\n任务可能已完成并被清理", msgUtils.EscapeHTML(gid))
+		}
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("ℹ️ 任务详情", "task_info:"+gid),
+				tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+			),
+		)
+		h.renderMessage(chatID, messageID, message, &keyboard)
+		return
+	}
+
+	emoji := "⬇️"
+	if top {
+		emoji = "⬆️"
+	}
+	lines := []string{formatter.FormatTitle(emoji, "已"+operation), ""}
+	if download, err := downloadService.GetDownload(ctx, gid); err == nil {
+		lines = append(lines, formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(download.Filename)))
+	}
+	lines = append(lines,
+		formatter.FormatFieldCode("GID", gid),
+		formatter.FormatField("队列位置", fmt.Sprintf("第 %d 位", position+1)),
+	)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("ℹ️ 任务详情", "task_info:"+gid),
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
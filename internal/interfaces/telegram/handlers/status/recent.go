@@ -0,0 +1,153 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxRecentDownloads caps the completed downloads listed in the recent view
+const maxRecentDownloads = 10
+
+// moveDownloadPromptPattern extracts the GID from the move prompt (it contains "/mvdl <gid>")
+var moveDownloadPromptPattern = regexp.MustCompile(`/mvdl (\S+)`)
+
+// MoveDownloadReplyCommand converts a reply to the move prompt into a /mvdl command
+func MoveDownloadReplyCommand(promptText, reply string) (string, bool) {
+	match := moveDownloadPromptPattern.FindStringSubmatch(promptText)
+	if match == nil {
+		return "", false
+	}
+	return fmt.Sprintf("/mvdl %s %s", match[1], strings.TrimSpace(reply)), true
+}
+
+// HandleRecentDownloads lists recently completed downloads with a move action per file.
+// The message is edited when messageID > 0, otherwise a new one is sent.
+func (h *Handler) HandleRecentDownloads(chatID int64, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	records, err := h.deps.GetDownloadService().GetRecentCompletedDownloads(context.Background(), maxRecentDownloads)
+	if err != nil {
+		h.renderMessage(chatID, messageID, formatter.FormatError("获取最近下载", err), nil)
+		return
+	}
+
+	lines := []string{formatter.FormatTitle("🕘", "最近完成的下载"), ""}
+	if len(records) == 0 {
+		lines = append(lines, "暂无已完成的下载记录")
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var moveRow []tgbotapi.InlineKeyboardButton
+	for i, record := range records {
+		lines = append(lines, fmt.Sprintf("%d. ✅ %s (%s)\n   📁 <code>%s</code>\n   🕘 %s",
+			i+1,
+			msgUtils.EscapeHTML(record.Filename),
+			msgUtils.FormatFileSize(record.TotalSize),
+			msgUtils.EscapeHTML(record.Directory),
+			record.UpdatedAt.Format("2006-01-02 15:04")))
+
+		moveRow = append(moveRow, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("📦 %d", i+1), "dl_move:"+record.ID))
+		if len(moveRow) == 5 {
+			rows = append(rows, moveRow)
+			moveRow = nil
+		}
+	}
+	if len(moveRow) > 0 {
+		rows = append(rows, moveRow)
+	}
+	if len(records) > 0 {
+		lines = append(lines, "", "点击 📦 将对应文件移动到其他目录")
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔄 刷新", "recent_downloads"),
+		tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+	))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
+
+// HandleMoveDownloadPrompt asks for the target directory of a completed download
+func (h *Handler) HandleMoveDownloadPrompt(chatID int64, gid string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	records, err := h.deps.GetDownloadService().GetRecentCompletedDownloads(context.Background(), 0)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("获取下载记录", err))
+		return
+	}
+	for _, record := range records {
+		if record.ID != gid {
+			continue
+		}
+		lines := []string{
+			formatter.FormatTitle("📦", "移动下载"),
+			"",
+			formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(record.Filename)),
+			formatter.FormatFieldCode("当前目录", msgUtils.EscapeHTML(record.Directory)),
+			"",
+			"请回复此消息发送目标目录（本机绝对路径，不存在时自动创建）",
+			fmt.Sprintf("也可以直接发送：<code>/mvdl %s 目标目录</code>", gid),
+		}
+		msgUtils.SendForceReply(chatID, strings.Join(lines, "\n"), record.Directory)
+		return
+	}
+	msgUtils.SendMessage(chatID, "下载记录不存在，请重新打开最近下载列表")
+}
+
+// HandleMoveDownloadCommand handles /mvdl <gid> <target dir>
+func (h *Handler) HandleMoveDownloadCommand(chatID int64, args string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	gid, targetDir, _ := strings.Cut(strings.TrimSpace(args), " ")
+	targetDir = strings.TrimSpace(targetDir)
+	if gid == "" || targetDir == "" {
+		msgUtils.SendMessageHTML(chatID, "用法：<code>/mvdl &lt;GID&gt; &lt;目标目录&gt;</code>\n\n可在 /recent 中点击 📦 选择要移动的文件")
+		return
+	}
+
+	result, err := h.deps.GetDownloadService().MoveCompletedDownload(context.Background(), gid, targetDir)
+	if err != nil {
+		msgUtils.SendMessageHTML(chatID, moveDownloadErrorMessage(formatter, err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("✅", "文件已移动"),
+		"",
+		formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(result.Filename)),
+		formatter.FormatFieldCode("原位置", msgUtils.EscapeHTML(result.From)),
+		formatter.FormatFieldCode("新位置", msgUtils.EscapeHTML(result.To)),
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🕘 最近下载", "recent_downloads"),
+		),
+	)
+	msgUtils.SendMessageWithKeyboard(chatID, strings.Join(lines, "\n"), "HTML", &keyboard)
+}
+
+// moveDownloadErrorMessage explains the common move failures
+func moveDownloadErrorMessage(formatter *utils.MessageFormatter, err error) string {
+	switch {
+	case errors.Is(err, contracts.ErrDownloadNotFound):
+		return "❌ 下载记录不存在，请重新打开最近下载列表"
+	case errors.Is(err, contracts.ErrDownloadOutputMissing):
+		return "❌ 找不到下载的文件\n\n文件可能已被移动或删除，或下载目录不在本机（例如 aria2 运行在其他容器中）"
+	case errors.Is(err, fs.ErrPermission):
+		return "❌ 没有权限移动文件\n\n请检查源文件和目标目录的读写权限"
+	default:
+		return formatter.FormatError("移动文件", err)
+	}
+}
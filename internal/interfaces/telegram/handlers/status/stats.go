@@ -0,0 +1,94 @@
+package status
+
+import (
+	"context"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	timeutil "github.com/easayliu/alist-aria2-download/pkg/utils/time"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// StatsCallbackPrefix switches the /stats period: stats:<today|week|month>
+const StatsCallbackPrefix = "stats:"
+
+// statsPeriods periods accepted by /stats, the first one is the default
+var statsPeriods = []struct {
+	key     string
+	label   string
+	rangeOf func() timeutil.TimeRange
+}{
+	{"today", "今日", timeutil.CreateTodayRange},
+	{"week", "本周", timeutil.CreateWeekRange},
+	{"month", "本月", timeutil.CreateMonthRange},
+}
+
+// HandleStats handles /stats [today|week|month]: completed downloads per category from the download history.
+// messageID 0 sends a new message, otherwise the message is edited.
+func (h *Handler) HandleStats(chatID int64, period string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	period = strings.ToLower(strings.TrimSpace(period))
+	selected := statsPeriods[0]
+	if period != "" {
+		found := false
+		for _, p := range statsPeriods {
+			if p.key == period {
+				selected, found = p, true
+				break
+			}
+		}
+		if !found {
+			msgUtils.SendMessageHTML(chatID, "用法：<code>/stats [today|week|month]</code>\n\n统计今日、本周或本月完成的下载（默认今日）")
+			return
+		}
+	}
+
+	since := selected.rangeOf().Start
+	stats, err := h.deps.GetDownloadService().GetDownloadHistoryStats(context.Background(), since)
+	if err != nil {
+		h.renderMessage(chatID, messageID, formatter.FormatError("获取下载统计", err), nil)
+		return
+	}
+
+	data := utils.DownloadStatsData{
+		PeriodLabel: selected.label,
+		Since:       since.Format("2006-01-02 15:04"),
+		TotalFiles:  stats.TotalFiles,
+		TotalSize:   msgUtils.FormatFileSize(stats.TotalSize),
+	}
+	for _, category := range stats.Categories {
+		label := categoryLabels[category.Category]
+		if label == "" {
+			label = "未分类"
+		}
+		percent := 0.0
+		if stats.TotalSize > 0 {
+			percent = float64(category.Size) / float64(stats.TotalSize) * 100
+		}
+		data.Categories = append(data.Categories, utils.CategoryStatsItem{
+			Label:   label,
+			Files:   category.Files,
+			Size:    msgUtils.FormatFileSize(category.Size),
+			Percent: percent,
+		})
+	}
+
+	var periodButtons []tgbotapi.InlineKeyboardButton
+	for _, p := range statsPeriods {
+		label := p.label
+		if p.key == selected.key {
+			label = "✓ " + label
+		}
+		periodButtons = append(periodButtons, tgbotapi.NewInlineKeyboardButtonData(label, StatsCallbackPrefix+p.key))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		periodButtons,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+			tgbotapi.NewInlineKeyboardButtonData("🏠 返回主菜单", "back_main"),
+		),
+	)
+	h.renderMessage(chatID, messageID, formatter.FormatDownloadStats(data), &keyboard)
+}
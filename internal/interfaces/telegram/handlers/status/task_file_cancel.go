@@ -0,0 +1,66 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TaskFileCancelCallbackPrefix is the callback prefix for stopping one file: task_file_stop:<gid>:<index>
+const TaskFileCancelCallbackPrefix = "task_file_stop:"
+
+// maxCancelButtonName caps the file name shown on a per-file stop button
+const maxCancelButtonName = 24
+
+// taskFileCancelData builds the callback data for stopping one file of a download
+func taskFileCancelData(gid string, index int) string {
+	return fmt.Sprintf("%s%s:%d", TaskFileCancelCallbackPrefix, gid, index)
+}
+
+// HandleCancelTaskFile stops a single file of a download and reports what was stopped.
+// args is "<gid>:<index>" as produced by taskFileCancelData.
+func (h *Handler) HandleCancelTaskFile(chatID int64, args string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	gid, indexText, _ := strings.Cut(args, ":")
+	index, err := strconv.Atoi(indexText)
+	if gid == "" || err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatSimpleError("按钮已过期，请重新打开任务详情"))
+		return
+	}
+
+	result, err := h.deps.GetDownloadService().CancelDownloadFile(context.Background(), gid, index)
+	if err != nil {
+		msgUtils.SendMessage(chatID, formatter.FormatError("取消文件", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("⏹", "已停止文件"),
+		"",
+		formatter.FormatFieldCode("文件", msgUtils.EscapeHTML(filepath.Base(result.FilePath))),
+		formatter.FormatFieldCode("GID", result.ID),
+	}
+	switch {
+	case result.TaskStopped && result.BatchID != "":
+		lines = append(lines, formatter.FormatField("结果", fmt.Sprintf("已取消该任务，批次 <code>%s</code> 中的其他文件继续下载", result.BatchID)))
+	case result.TaskStopped:
+		lines = append(lines, formatter.FormatField("结果", "已取消整个任务（没有其他选中的文件）"))
+	default:
+		lines = append(lines, formatter.FormatField("结果", fmt.Sprintf("已取消选择该文件，任务中其余 %d 个文件继续下载", result.RemainingFiles)))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("ℹ️ 任务详情", "task_info:"+result.ID),
+			tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+		),
+	)
+	h.renderMessage(chatID, messageID, strings.Join(lines, "\n"), &keyboard)
+}
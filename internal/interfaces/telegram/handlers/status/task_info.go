@@ -0,0 +1,250 @@
+package status
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/domain/valueobjects"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
+	timeutil "github.com/easayliu/alist-aria2-download/pkg/utils/time"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// gidLength is the length of a full aria2 GID (16 hex characters)
+const gidLength = 16
+
+// maxTaskInfoFiles caps the files listed for multi-file (e.g. torrent) downloads
+const maxTaskInfoFiles = 10
+
+// HandleTaskInfo shows full details of a single aria2 download.
+// gid may be a unique prefix, as shown in the download list.
+func (h *Handler) HandleTaskInfo(chatID int64, gid string, messageID int) {
+	ctx := context.Background()
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	gid = strings.TrimSpace(gid)
+	if gid == "" {
+		msgUtils.SendMessageHTML(chatID, "用法：<code>/taskinfo &lt;GID&gt;</code>\n\nGID 可在下载状态列表中查看，输入前几位即可")
+		return
+	}
+
+	fullGID, err := h.resolveGID(ctx, gid)
+	if err == nil {
+		var detail *contracts.DownloadDetail
+		detail, err = h.deps.GetDownloadService().GetDownloadDetail(ctx, fullGID)
+		if err == nil {
+			keyboard := taskInfoKeyboard(detail)
+			h.renderMessage(chatID, messageID, formatTaskInfo(formatter, msgUtils.EscapeHTML, msgUtils.FormatFileSize, detail), &keyboard)
+			return
+		}
+	}
+
+	message := formatter.FormatError("获取任务详情", err)
+	if errors.Is(err, contracts.ErrDownloadNotFound) {
+		message = fmt.Sprintf("❓ 未找到任务 <code>%s</code>\n\n任务可能已完成并被清理，或 GID 输入有误", msgUtils.EscapeHTML(gid))
+	}
+	h.renderMessage(chatID, messageID, message, nil)
+}
+
+// taskInfoKeyboard builds the task detail keyboard: "download now" and move to top/bottom for queued tasks,
+// "boost" for unfinished non-BitTorrent tasks, pause/resume, live progress, per-file stop buttons for unfinished multi-file or batch downloads
+func taskInfoKeyboard(d *contracts.DownloadDetail) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if d.Status == valueobjects.DownloadStatusPending || d.Status == valueobjects.DownloadStatusPaused {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚡ 立即下载", DownloadNowCallbackPrefix+d.ID),
+			tgbotapi.NewInlineKeyboardButtonData("⬆️ 置顶", MoveTopCallbackPrefix+d.ID),
+			tgbotapi.NewInlineKeyboardButtonData("⬇️ 置底", MoveBottomCallbackPrefix+d.ID),
+		))
+	}
+
+	if d.Status == valueobjects.DownloadStatusActive && !d.BitTorrent {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚡ 加速", BoostCallbackPrefix+d.ID),
+		))
+	}
+
+	switch d.Status {
+	case valueobjects.DownloadStatusActive, valueobjects.DownloadStatusPending:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏸ 暂停", PauseCallbackPrefix+d.ID),
+			tgbotapi.NewInlineKeyboardButtonData("📡 实时进度", TrackCallbackPrefix+d.ID),
+		))
+	case valueobjects.DownloadStatusPaused:
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("▶️ 恢复", ResumeCallbackPrefix+d.ID),
+		))
+	}
+
+	switch d.Status {
+	case valueobjects.DownloadStatusActive, valueobjects.DownloadStatusPending, valueobjects.DownloadStatusPaused:
+		if len(d.Files) > 1 {
+			// Multi-file download (e.g. torrent): deselect single files, the rest keeps downloading
+			for i, file := range d.Files {
+				if i == maxTaskInfoFiles {
+					break
+				}
+				if !file.Selected || (file.Length > 0 && file.CompletedLength >= file.Length) {
+					continue
+				}
+				label := fmt.Sprintf("⏹ #%d %s", file.Index, strutil.TruncateRunes(filepath.Base(file.Path), maxCancelButtonName, "…"))
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(label, taskFileCancelData(d.ID, file.Index)),
+				))
+			}
+		} else if d.BatchID != "" && len(d.Files) == 1 {
+			// Batch member: each file is its own task, cancel only this one
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("⏹ 只取消此文件（批次其余继续）", taskFileCancelData(d.ID, d.Files[0].Index)),
+			))
+		}
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔄 刷新", "task_info:"+d.ID),
+		tgbotapi.NewInlineKeyboardButtonData("📥 下载状态", "download_list"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// renderMessage edits the callback message when available, otherwise sends a new one
+func (h *Handler) renderMessage(chatID int64, messageID int, message string, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	msgUtils := h.deps.GetMessageUtils()
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", keyboard)
+		return
+	}
+	if keyboard != nil {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", keyboard)
+		return
+	}
+	msgUtils.SendMessageHTML(chatID, message)
+}
+
+// resolveGID expands a GID prefix to the full GID of a unique matching download
+func (h *Handler) resolveGID(ctx context.Context, gid string) (string, error) {
+	if len(gid) >= gidLength {
+		return gid, nil
+	}
+
+	// No limit: the list is paginated after merging, so a limit would hide stopped downloads
+	downloads, err := h.deps.GetDownloadService().ListDownloads(ctx, contracts.DownloadListRequest{})
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, d := range downloads.Downloads {
+		if strings.HasPrefix(d.ID, gid) {
+			matches = append(matches, d.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", contracts.ErrDownloadNotFound, gid)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("GID 前缀 %s 匹配到 %d 个任务，请输入更多位", gid, len(matches))
+	}
+}
+
+// formatTaskInfo renders all diagnostic fields of a download
+func formatTaskInfo(formatter *utils.MessageFormatter, escapeHTML func(string) string, formatSize func(int64) string, d *contracts.DownloadDetail) string {
+	lines := []string{
+		formatter.FormatTitle("ℹ️", "任务详情"),
+		"",
+		formatter.FormatFieldCode("GID", d.ID),
+	}
+	if d.Filename != "" {
+		lines = append(lines, formatter.FormatFieldCode("文件", escapeHTML(d.Filename)))
+	}
+	lines = append(lines,
+		formatter.FormatField("状态", fmt.Sprintf("%s %s", downloadStatusEmoji(string(d.Status)), d.Status)),
+		formatter.FormatField("进度", fmt.Sprintf("%.1f%% (%s / %s)", d.Progress, formatSize(d.CompletedSize), formatSize(d.TotalSize))),
+		formatter.FormatField("速度", fmt.Sprintf("↓ %s/s  ↑ %s/s", formatSize(d.Speed), formatSize(d.UploadSpeed))),
+	)
+	if d.SpeedLimit > 0 {
+		lines = append(lines, formatter.FormatField("限速", formatSize(d.SpeedLimit)+"/s"))
+	}
+	if d.Speed > 0 && d.TotalSize > d.CompletedSize {
+		remaining := time.Duration((d.TotalSize-d.CompletedSize)/d.Speed) * time.Second
+		lines = append(lines, formatter.FormatField("剩余时间", timeutil.FormatDuration(remaining)))
+	}
+	lines = append(lines, formatter.FormatField("连接数", fmt.Sprintf("%d", d.Connections)))
+	if !d.BitTorrent && (d.MaxConnectionPerServer > 0 || d.Split > 0) {
+		lines = append(lines, formatter.FormatField("连接设置", fmt.Sprintf("每服务器 %d 连接，分段 %d", d.MaxConnectionPerServer, d.Split)))
+	}
+	if d.NumPieces > 0 {
+		lines = append(lines, formatter.FormatField("分片", fmt.Sprintf("%d / %d（每片 %s）", d.CompletedPieces, d.NumPieces, formatSize(d.PieceLength))))
+	}
+	if d.Directory != "" {
+		lines = append(lines, formatter.FormatFieldCode("目录", escapeHTML(d.Directory)))
+	}
+	if d.BatchID != "" {
+		lines = append(lines, formatter.FormatField("批次", fmt.Sprintf("<code>%s</code>（共 %d 个文件，各自独立下载）", d.BatchID, d.BatchSize)))
+	}
+
+	if len(d.Files) > 1 || (len(d.Files) == 1 && len(d.Files[0].URIs) > 0) {
+		lines = append(lines, "", formatter.FormatSection(fmt.Sprintf("文件（%d个）", len(d.Files))))
+		for i, file := range d.Files {
+			if i == maxTaskInfoFiles {
+				lines = append(lines, fmt.Sprintf("… 还有 %d 个文件", len(d.Files)-maxTaskInfoFiles))
+				break
+			}
+			lines = append(lines, formatTaskInfoFile(escapeHTML, formatSize, file))
+		}
+	}
+
+	if d.ErrorCode != "" && d.ErrorCode != "0" {
+		lines = append(lines, "", formatter.FormatField("错误码", d.ErrorCode))
+		if d.ErrorMessage != "" {
+			lines = append(lines, formatter.FormatFieldCode("错误信息", escapeHTML(d.ErrorMessage)))
+		}
+		if d.DiskFull {
+			lines = append(lines, "💾 磁盘空间不足：请清理下载磁盘后重试")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatTaskInfoFile renders one file line: name, progress, selection and active sources
+func formatTaskInfoFile(escapeHTML func(string) string, formatSize func(int64) string, file contracts.DownloadFileDetail) string {
+	name := filepath.Base(file.Path)
+	if file.Path == "" {
+		name = "(未知)"
+	}
+
+	progress := 0.0
+	if file.Length > 0 {
+		progress = float64(file.CompletedLength) / float64(file.Length) * 100
+	}
+
+	line := fmt.Sprintf("• %s (%s, %.1f%%)", escapeHTML(name), formatSize(file.Length), progress)
+	if !file.Selected {
+		line += " [未选择]"
+	}
+	if len(file.URIs) > 0 {
+		line += fmt.Sprintf("\n   来源 %d 个：<code>%s</code>", len(file.URIs), escapeHTML(sourceHost(file.URIs[0])))
+	}
+	return line
+}
+
+// sourceHost shows only the host of a download URI, hiding tokens in the path or query
+func sourceHost(uri string) string {
+	rest, found := strings.CutPrefix(uri, "https://")
+	if !found {
+		rest = strings.TrimPrefix(uri, "http://")
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	return host
+}
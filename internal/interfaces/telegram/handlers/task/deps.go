@@ -9,4 +9,7 @@ import (
 type TaskDeps interface {
 	GetMessageUtils() types.MessageSender
 	GetSchedulerService() *services.SchedulerService
+	// 复用路径令牌缓存编码筛选关键字，避免超出回调数据长度限制
+	EncodeFilePath(path string) string
+	DecodeFilePath(encoded string) string
 }
@@ -4,11 +4,31 @@ package task
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/easayliu/alist-aria2-download/internal/domain/entities"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+	strutil "github.com/easayliu/alist-aria2-download/pkg/utils/string"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// TasksPerPage 每页显示的任务数量
+const TasksPerPage = 5
+
+// maxLastErrorRunes caps the last error shown for auto-disabled tasks
+const maxLastErrorRunes = 120
+
+// Task list callback actions
+const (
+	ActionRun           = "run"
+	ActionToggle        = "toggle"
+	ActionDeleteConfirm = "del_confirm"
+	ActionDelete        = "del"
+	ActionRetryFailed   = "retry"
+)
+
 // Handler handles task management related functions
 type Handler struct {
 	deps TaskDeps
@@ -27,30 +47,35 @@ func NewHandler(deps TaskDeps) *Handler {
 
 // HandleTasksWithEdit handles viewing scheduled tasks (supports message editing)
 func (h *Handler) HandleTasksWithEdit(chatID int64, userID int64, messageID int) {
+	h.HandleTaskPage(chatID, userID, 1, "", messageID)
+}
+
+// HandleTaskList sends the first page of the task list, optionally filtered by name
+func (h *Handler) HandleTaskList(chatID int64, userID int64, filter string) {
+	h.HandleTaskPage(chatID, userID, 1, filter, 0)
+}
+
+// HandleTaskPage renders one page of the task list.
+// messageID == 0 sends a new message, otherwise the message is edited in place.
+func (h *Handler) HandleTaskPage(chatID int64, userID int64, page int, filter string, messageID int) {
 	msgUtils := h.deps.GetMessageUtils()
 	schedulerService := h.deps.GetSchedulerService()
 
+	backKeyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
+		),
+	)
+
 	if schedulerService == nil {
-		message := "定时任务服务未启用"
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
-			),
-		)
-		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+		h.render(chatID, messageID, "定时任务服务未启用", &backKeyboard)
 		return
 	}
 
 	tasks, err := schedulerService.GetUserTasks(userID)
 	if err != nil {
 		formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
-		message := formatter.FormatError("获取任务", err)
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("返回主菜单", "back_main"),
-			),
-		)
-		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+		h.render(chatID, messageID, formatter.FormatError("获取任务", err), &backKeyboard)
 		return
 	}
 
@@ -70,23 +95,56 @@ func (h *Handler) HandleTasksWithEdit(chatID int64, userID int64, messageID int)
 				tgbotapi.NewInlineKeyboardButtonData("返回管理面板", "cmd_manage"),
 			),
 		)
-		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+		h.render(chatID, messageID, message, &keyboard)
 		return
 	}
 
+	tasks = filterTasks(tasks, filter)
+
+	// 按创建时间排序，保证分页稳定
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
+
+	totalPages := (len(tasks) + TasksPerPage - 1) / TasksPerPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	start := (page - 1) * TasksPerPage
+	end := min(start+TasksPerPage, len(tasks))
+	pageTasks := tasks[start:end]
+
 	// 构建任务数据
 	var taskItems []utils.TaskItemData
-	for _, task := range tasks {
+	for _, task := range pageTasks {
 		statusEmoji := "⏸️"
 		status := "禁用"
-		if task.Enabled {
+		lastError := ""
+		switch {
+		case task.Enabled:
 			statusEmoji = "✅"
 			status = "启用"
+		case task.IsAutoDisabled():
+			// Paused automatically after repeated failures; show why
+			statusEmoji = "⛔"
+			status = fmt.Sprintf("连续失败 %d 次已停用", task.ConsecutiveFailures)
+			lastError = msgUtils.EscapeHTML(strutil.TruncateRunes(task.LastError, maxLastErrorRunes, "…"))
 		}
 
 		// Calculate time description
 		timeDesc := h.formatTaskTimeDescription(task.HoursAgo)
-		schedule := fmt.Sprintf("%s (最近%s)", task.Cron, timeDesc)
+		spec := task.Cron
+		if task.Window != nil {
+			spec = task.Window.Describe()
+		}
+		schedule := fmt.Sprintf("%s (最近%s)", spec, timeDesc)
 
 		lastRun := ""
 		if task.LastRunAt != nil {
@@ -99,13 +157,19 @@ func (h *Handler) HandleTasksWithEdit(chatID int64, userID int64, messageID int)
 		}
 
 		taskItems = append(taskItems, utils.TaskItemData{
-			ID:          task.ID[:8],
+			ID:          shortTaskID(task.ID),
 			Name:        msgUtils.EscapeHTML(task.Name),
 			Schedule:    schedule,
 			Status:      status,
 			StatusEmoji: statusEmoji,
 			LastRun:     lastRun,
 			NextRun:     nextRun,
+			LastError:   lastError,
+			Paused:      task.IsAutoDisabled(),
+			FailedItems: len(task.FailedItems),
+			NotifyChat:  task.NotifyChatID,
+			SizeRange:   strutil.FormatSizeRange(task.MinFileSize, task.MaxFileSize),
+			Timezone:    task.Timezone,
 		})
 	}
 
@@ -114,23 +178,161 @@ func (h *Handler) HandleTasksWithEdit(chatID int64, userID int64, messageID int)
 	listData := utils.TaskListData{
 		TotalCount: len(tasks),
 		Tasks:      taskItems,
+		StartIndex: start,
 	}
 	message := formatter.FormatTaskList(listData)
 
-	// Add command instructions
-	message += "\n\n" + formatter.FormatSection("命令")
-	message += "\n" + formatter.FormatListItem("•", "立即运行: <code>/runtask ID</code>")
-	message += "\n" + formatter.FormatListItem("•", "删除任务: <code>/deltask ID</code>")
-	message += "\n" + formatter.FormatListItem("•", "添加任务: <code>/addtask</code> 查看帮助")
+	if filter != "" {
+		message += "\n\n" + formatter.FormatField("筛选", msgUtils.EscapeHTML(filter))
+		if len(tasks) == 0 {
+			message += "\n没有匹配的任务"
+		}
+	}
+	if totalPages > 1 {
+		message += "\n" + formatter.FormatField("页码", fmt.Sprintf("%d/%d", page, totalPages))
+	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("刷新任务", "cmd_tasks"),
-			tgbotapi.NewInlineKeyboardButtonData("返回管理面板", "cmd_manage"),
-		),
-	)
+	// 首页保留命令说明，方便查看添加任务帮助
+	if page == 1 {
+		message += "\n\n" + formatter.FormatSection("命令")
+		message += "\n" + formatter.FormatListItem("•", "按名称筛选: <code>/tasks 关键字</code>")
+		message += "\n" + formatter.FormatListItem("•", "添加任务: <code>/addtask</code> 查看帮助")
+	}
+
+	filterToken := ""
+	if filter != "" {
+		filterToken = h.deps.EncodeFilePath(filter)
+	}
+
+	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+
+	// 每个任务一行操作按钮：运行 / 启停 / 删除
+	for i, task := range pageTasks {
+		index := start + i + 1
+		id := shortTaskID(task.ID)
+		toggleLabel := fmt.Sprintf("⏸️ 停用 %d", index)
+		if !task.Enabled {
+			toggleLabel = fmt.Sprintf("✅ 启用 %d", index)
+		}
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("▶️ 运行 %d", index), taskActionData(ActionRun, id, page, filterToken)),
+			tgbotapi.NewInlineKeyboardButtonData(toggleLabel, taskActionData(ActionToggle, id, page, filterToken)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🗑️ 删除 %d", index), taskActionData(ActionDeleteConfirm, id, page, filterToken)),
+		))
+		if len(task.FailedItems) > 0 {
+			keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔁 重试 %d 的失败文件 (%d)", index, len(task.FailedItems)), taskActionData(ActionRetryFailed, id, page, filterToken)),
+			))
+		}
+	}
+
+	// 分页导航
+	var navButtons []tgbotapi.InlineKeyboardButton
+	if page > 1 {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("< 上一页", taskPageData(page-1, filterToken)))
+	}
+	if page < totalPages {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("下一页 >", taskPageData(page+1, filterToken)))
+	}
+	if len(navButtons) > 0 {
+		keyboardRows = append(keyboardRows, navButtons)
+	}
+
+	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("刷新任务", taskPageData(page, filterToken)),
+		tgbotapi.NewInlineKeyboardButtonData("返回管理面板", "cmd_manage"),
+	))
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	h.render(chatID, messageID, message, &keyboard)
+}
 
-	msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", &keyboard)
+// HandleTaskAction handles per-task buttons in the task list, then re-renders the current page
+func (h *Handler) HandleTaskAction(chatID int64, userID int64, action, taskID string, page int, filterToken string, messageID int) {
+	msgUtils := h.deps.GetMessageUtils()
+	schedulerService := h.deps.GetSchedulerService()
+	if schedulerService == nil {
+		msgUtils.SendMessage(chatID, "定时任务服务未启用")
+		return
+	}
+
+	filter := ""
+	if filterToken != "" {
+		filter = h.deps.DecodeFilePath(filterToken)
+	}
+
+	task := h.findUserTask(userID, taskID)
+	if task == nil {
+		msgUtils.SendMessageWithAutoDelete(chatID, "未找到任务", types.MessageTransient)
+		h.HandleTaskPage(chatID, userID, page, filter, messageID)
+		return
+	}
+
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	switch action {
+	case ActionRun:
+		if err := schedulerService.RunTaskNow(task.ID); err != nil {
+			msgUtils.SendMessage(chatID, formatter.FormatError("运行任务", err))
+			return
+		}
+		msgUtils.SendMessageWithAutoDelete(chatID, fmt.Sprintf("任务 '%s' 已开始运行，请稍后查看结果", task.Name), types.MessageTransient)
+
+	case ActionToggle:
+		if err := schedulerService.ToggleTask(task.ID, !task.Enabled); err != nil {
+			msgUtils.SendMessage(chatID, formatter.FormatError("切换任务状态", err))
+			return
+		}
+
+	case ActionRetryFailed:
+		h.sendRetryResult(chatID, task.ID)
+
+	case ActionDeleteConfirm:
+		message := formatter.FormatTitle("🗑️", "确认删除任务") + "\n\n" +
+			formatter.FormatField("名称", msgUtils.EscapeHTML(task.Name)) + "\n" +
+			formatter.FormatField("ID", fmt.Sprintf("<code>%s</code>", shortTaskID(task.ID)))
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("✅ 确认删除", taskActionData(ActionDelete, shortTaskID(task.ID), page, filterToken)),
+				tgbotapi.NewInlineKeyboardButtonData("❌ 取消", taskPageData(page, filterToken)),
+			),
+		)
+		h.render(chatID, messageID, message, &keyboard)
+		return
+
+	case ActionDelete:
+		if err := schedulerService.DeleteTask(task.ID); err != nil {
+			msgUtils.SendMessage(chatID, formatter.FormatError("删除任务", err))
+			return
+		}
+		msgUtils.SendMessageWithAutoDelete(chatID, fmt.Sprintf("任务 '%s' 已删除", task.Name), types.MessageTransient)
+	}
+
+	h.HandleTaskPage(chatID, userID, page, filter, messageID)
+}
+
+// findUserTask finds a task of the user by (short) ID prefix
+func (h *Handler) findUserTask(userID int64, taskID string) *entities.ScheduledTask {
+	tasks, err := h.deps.GetSchedulerService().GetUserTasks(userID)
+	if err != nil || taskID == "" {
+		return nil
+	}
+	for _, task := range tasks {
+		if strings.HasPrefix(task.ID, taskID) {
+			return task
+		}
+	}
+	return nil
+}
+
+// render sends a new message or edits the existing one
+func (h *Handler) render(chatID int64, messageID int, message string, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	msgUtils := h.deps.GetMessageUtils()
+	if messageID > 0 {
+		msgUtils.EditMessageWithKeyboard(chatID, messageID, message, "HTML", keyboard)
+	} else {
+		msgUtils.SendMessageWithKeyboard(chatID, message, "HTML", keyboard)
+	}
 }
 
 // formatTaskTimeDescription formats task time description
@@ -150,3 +352,36 @@ func (h *Handler) formatTaskTimeDescription(hoursAgo int) string {
 		return fmt.Sprintf("%d小时", hoursAgo)
 	}
 }
+
+// filterTasks filters tasks by case-insensitive name match
+func filterTasks(tasks []*entities.ScheduledTask, filter string) []*entities.ScheduledTask {
+	if filter == "" {
+		return tasks
+	}
+	keyword := strings.ToLower(filter)
+	filtered := make([]*entities.ScheduledTask, 0, len(tasks))
+	for _, task := range tasks {
+		if strings.Contains(strings.ToLower(task.Name), keyword) {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
+
+// shortTaskID returns the 8-char ID prefix used in messages and callback data
+func shortTaskID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// taskPageData builds callback data: task_page:<page>:<filterToken>
+func taskPageData(page int, filterToken string) string {
+	return fmt.Sprintf("task_page:%d:%s", page, filterToken)
+}
+
+// taskActionData builds callback data: task_<action>:<id>:<page>:<filterToken>
+func taskActionData(action, taskID string, page int, filterToken string) string {
+	return fmt.Sprintf("task_%s:%s:%d:%s", action, taskID, page, filterToken)
+}
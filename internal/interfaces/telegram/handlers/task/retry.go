@@ -0,0 +1,68 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
+)
+
+// HandleRetryFailedItems resubmits only the failed files recorded for a task (/retrytask <id>)
+func (h *Handler) HandleRetryFailedItems(chatID int64, userID int64, taskID string) {
+	msgUtils := h.deps.GetMessageUtils()
+	if h.deps.GetSchedulerService() == nil {
+		msgUtils.SendMessage(chatID, "定时任务服务未启用")
+		return
+	}
+
+	taskID = strings.TrimSpace(taskID)
+	if taskID == "" {
+		msgUtils.SendMessageHTML(chatID, "用法: <code>/retrytask 任务ID</code>\n只重新提交任务上次运行中创建失败的文件")
+		return
+	}
+
+	task := h.findUserTask(userID, taskID)
+	if task == nil {
+		msgUtils.SendMessage(chatID, "未找到任务")
+		return
+	}
+	h.sendRetryResult(chatID, task.ID)
+}
+
+// sendRetryResult retries the task's failed files and reports what was recovered
+func (h *Handler) sendRetryResult(chatID int64, taskID string) {
+	msgUtils := h.deps.GetMessageUtils()
+	formatter := msgUtils.GetFormatter().(*utils.MessageFormatter)
+
+	result, err := h.deps.GetSchedulerService().RetryFailedItems(taskID)
+	if err != nil {
+		msgUtils.SendMessageHTML(chatID, formatter.FormatError("重试失败文件", err))
+		return
+	}
+
+	lines := []string{
+		formatter.FormatTitle("🔁", "重试任务失败文件"),
+		"",
+		formatter.FormatField("任务", msgUtils.EscapeHTML(result.TaskName)),
+	}
+	if result.FailedCount == 0 {
+		lines = append(lines, "", "✅ 该任务没有待重试的失败文件")
+		msgUtils.SendMessageHTMLWithAutoDelete(chatID, strings.Join(lines, "\n"), types.MessageTransient)
+		return
+	}
+
+	lines = append(lines,
+		formatter.FormatField("失败文件", fmt.Sprintf("%d 个", result.FailedCount)),
+		formatter.FormatField("已恢复", fmt.Sprintf("%d 个", result.SuccessCount)),
+		formatter.FormatField("仍然失败", fmt.Sprintf("%d 个", result.FailureCount)),
+	)
+
+	if result.FailureCount > 0 {
+		lines = append(lines, "", formatter.FormatSection("失败原因"))
+		lines = append(lines, utils.RetryErrorLines(result.Results, result.FailureCount, msgUtils.EscapeHTML)...)
+		lines = append(lines, "", fmt.Sprintf("仍失败的文件已保留，稍后可再次发送 <code>/retrytask %s</code>", shortTaskID(result.TaskID)))
+	}
+
+	msgUtils.SendMessageHTMLWithAutoDelete(chatID, strings.Join(lines, "\n"), types.MessageImportant)
+}
@@ -1,8 +1,16 @@
 package telegram
 
 import (
+	"context"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	filehandler "github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/handlers/file"
+	statushandler "github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/handlers/status"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/utils"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -25,10 +33,20 @@ func (h *MessageHandler) HandleMessage(update *tgbotapi.Update) {
 	if msg == nil || msg.Text == "" {
 		return
 	}
+	receivedAt := time.Now()
 
 	userID := msg.From.ID
 	chatID := msg.Chat.ID
 
+	// Normalize /command@botname and the configured command prefix.
+	// Commands addressed to other bots (or unprefixed ones in groups) are ignored silently.
+	isGroup := msg.Chat.IsGroup() || msg.Chat.IsSuperGroup()
+	command, ok := utils.NormalizeCommand(strings.TrimSpace(msg.Text),
+		h.controller.telegramClient.BotUsername(), h.controller.config.Telegram.CommandPrefix, isGroup)
+	if !ok {
+		return
+	}
+
 	// Authorization check
 	if !h.controller.telegramClient.IsAuthorized(userID) {
 		h.controller.messageUtils.SendMessage(chatID, "未授权访问")
@@ -40,17 +58,34 @@ func (h *MessageHandler) HandleMessage(update *tgbotapi.Update) {
 		return
 	}
 
-	command := strings.TrimSpace(msg.Text)
 	username := ""
 	if msg.From.UserName != "" {
 		username = msg.From.UserName
 	}
-	logger.Info("Received telegram command:", "command", command, "from", username, "chatID", chatID)
+	logger.Info("Received telegram command:", "command", redactCommandSecrets(command), "from", username, "chatID", chatID)
+
+	// Replies to bot prompts carry the missing argument:
+	// the new file name for "rename then download", the target directory for "move download",
+	// the keyword for "search files"
+	if reply := msg.ReplyToMessage; reply != nil && reply.From != nil && reply.From.IsBot && !strings.HasPrefix(command, "/") {
+		if saveAs, ok := filehandler.SaveAsReplyCommand(reply.Text, command); ok {
+			command = saveAs
+		} else if move, ok := statushandler.MoveDownloadReplyCommand(reply.Text, command); ok {
+			command = move
+		} else if search, ok := filehandler.SearchReplyCommand(reply.Text, command); ok {
+			command = search
+		}
+	}
+
+	// Custom shortcut buttons expand to their configured command
+	if shortcut, ok := h.controller.shortcutCommand(command); ok {
+		command = shortcut
+	}
 
 	// Handle quick buttons (Reply Keyboard)
 	switch command {
 	case "定时任务":
-		h.controller.taskCommands.HandleTasks(chatID, msg.From.ID)
+		h.controller.taskHandler.HandleTaskList(chatID, msg.From.ID, "")
 		return
 	case "预览文件":
 		h.controller.basicCommands.HandlePreviewMenu(chatID)
@@ -69,31 +104,158 @@ func (h *MessageHandler) HandleMessage(update *tgbotapi.Update) {
 		h.controller.basicCommands.HandleStart(chatID)
 	case strings.HasPrefix(command, "/help"):
 		h.controller.basicCommands.HandleHelp(chatID)
+	case strings.HasPrefix(command, "/commands"):
+		h.handleCommandList(chatID, msg.From.LanguageCode)
+	case strings.HasPrefix(command, "/ping"):
+		// Must precede /pin, which is a prefix of /ping
+		h.controller.basicCommands.HandlePing(chatID, receivedAt)
 	case strings.HasPrefix(command, "/download"):
-		h.controller.downloadCommands.HandleDownload(chatID, command)
+		h.controller.common.RunExclusive(chatID, "/download", func() {
+			h.controller.downloadCommands.HandleDownload(chatID, userID, command)
+		})
 	case strings.HasPrefix(command, "/list"):
 		h.controller.basicCommands.HandleList(chatID, command)
 	case strings.HasPrefix(command, "/llmrename"):
 		h.handleLLMRenameCommand(chatID, command)
 	case strings.HasPrefix(command, "/rename"):
 		h.controller.basicCommands.HandleRename(chatID, command)
+	case strings.HasPrefix(command, "/cancelall"):
+		h.controller.statusHandler.HandleCancelAllConfirm(chatID, 0)
 	case strings.HasPrefix(command, "/cancel"):
 		h.controller.downloadCommands.HandleCancel(chatID, command)
+	case strings.HasPrefix(command, "/recent"):
+		h.controller.statusHandler.HandleRecentDownloads(chatID, 0)
+	case strings.HasPrefix(command, "/mvdl"):
+		if !h.controller.telegramClient.IsAdmin(userID) {
+			h.controller.messageUtils.SendMessage(chatID, "仅管理员可用")
+			return
+		}
+		h.controller.common.RunExclusive(chatID, "/mvdl", func() {
+			h.controller.statusHandler.HandleMoveDownloadCommand(chatID, strings.TrimPrefix(command, "/mvdl"))
+		})
+	case strings.HasPrefix(command, "/search"):
+		h.controller.common.RunExclusive(chatID, "/search", func() {
+			h.controller.fileHandler.HandleSearch(chatID, strings.TrimPrefix(command, "/search"))
+		})
+	case strings.HasPrefix(command, "/find"):
+		h.controller.common.RunExclusive(chatID, "/find", func() {
+			h.controller.statusHandler.HandleFindCommand(chatID, strings.TrimPrefix(command, "/find"))
+		})
+	case strings.HasPrefix(command, "/pauseall"):
+		h.controller.statusHandler.HandleQueueControlConfirm(chatID, 0, true)
+	case strings.HasPrefix(command, "/pause"):
+		h.controller.statusHandler.HandlePauseDownload(chatID, strings.TrimPrefix(command, "/pause"), 0, true)
+	case strings.HasPrefix(command, "/resumeall"):
+		h.controller.statusHandler.HandleQueueControlConfirm(chatID, 0, false)
+	case strings.HasPrefix(command, "/resume"):
+		h.controller.statusHandler.HandlePauseDownload(chatID, strings.TrimPrefix(command, "/resume"), 0, false)
+	case strings.HasPrefix(command, "/retryfailed"):
+		h.controller.common.RunExclusive(chatID, "/retryfailed", func() {
+			h.controller.statusHandler.HandleRetryFailedBatch(chatID, userID, strings.TrimPrefix(command, "/retryfailed"))
+		})
+	case strings.HasPrefix(command, "/taskinfo"):
+		h.controller.statusHandler.HandleTaskInfo(chatID, strings.TrimPrefix(command, "/taskinfo"), 0)
+	case strings.HasPrefix(command, "/boost"):
+		h.controller.statusHandler.HandleBoostDownload(chatID, strings.TrimPrefix(command, "/boost"), 0)
 	case strings.HasPrefix(command, "/tasks"):
-		h.controller.taskCommands.HandleTasks(chatID, msg.From.ID)
+		filter := strings.TrimSpace(strings.TrimPrefix(command, "/tasks"))
+		h.controller.taskHandler.HandleTaskList(chatID, msg.From.ID, filter)
+	case strings.HasPrefix(command, "/today"):
+		h.controller.taskCommands.HandleToday(chatID, msg.From.ID)
+	case strings.HasPrefix(command, "/cron"):
+		h.controller.taskCommands.HandleCron(chatID, command)
 	case strings.HasPrefix(command, "/addtask"):
 		h.controller.taskCommands.HandleAddTask(chatID, msg.From.ID, command)
+	case strings.HasPrefix(command, "/addwindow"):
+		h.controller.taskCommands.HandleAddWindow(chatID, msg.From.ID, command)
 	case strings.HasPrefix(command, "/quicktask"):
 		h.controller.taskCommands.HandleQuickTask(chatID, msg.From.ID, command)
 	case strings.HasPrefix(command, "/deltask"):
 		h.controller.taskCommands.HandleDeleteTask(chatID, msg.From.ID, command)
+	case strings.HasPrefix(command, "/retrytask"):
+		h.controller.common.RunExclusive(chatID, "/retrytask", func() {
+			h.controller.taskHandler.HandleRetryFailedItems(chatID, msg.From.ID, strings.TrimPrefix(command, "/retrytask"))
+		})
 	case strings.HasPrefix(command, "/runtask"):
 		h.controller.taskCommands.HandleRunTask(chatID, msg.From.ID, command)
+	case strings.HasPrefix(command, "/delete"):
+		h.controller.common.RunExclusive(chatID, "/delete", func() {
+			h.controller.fileHandler.HandleDeleteCommand(chatID, strings.TrimPrefix(command, "/delete"))
+		})
+	case strings.HasPrefix(command, "/eta"):
+		h.controller.common.RunExclusive(chatID, "/eta", func() {
+			h.controller.fileHandler.HandleETA(chatID, strings.TrimPrefix(command, "/eta"))
+		})
+	case strings.HasPrefix(command, "/saveas"):
+		h.controller.fileHandler.HandleSaveAsCommand(chatID, strings.TrimPrefix(command, "/saveas"))
+	case strings.HasPrefix(command, "/overrides"):
+		h.controller.fileHandler.HandleCategoryOverrides(chatID, strings.TrimPrefix(command, "/overrides"))
+	case strings.HasPrefix(command, "/inventory"):
+		h.controller.common.RunExclusive(chatID, "/inventory", func() {
+			h.controller.fileHandler.HandleInventory(chatID, strings.TrimPrefix(command, "/inventory"))
+		})
+	case strings.HasPrefix(command, "/snapshot"):
+		h.controller.common.RunExclusive(chatID, "/snapshot", func() {
+			h.controller.fileHandler.HandleSnapshot(chatID, msg.From.ID, strings.TrimPrefix(command, "/snapshot"))
+		})
+	case strings.HasPrefix(command, "/diff"):
+		h.controller.common.RunExclusive(chatID, "/diff", func() {
+			h.controller.fileHandler.HandleDiff(chatID, strings.TrimPrefix(command, "/diff"))
+		})
+	case strings.HasPrefix(command, "/testrule"):
+		h.controller.fileHandler.HandleTestRule(chatID, strings.TrimPrefix(command, "/testrule"))
+	case strings.HasPrefix(command, "/reorganize"):
+		if !h.controller.telegramClient.IsAdmin(userID) {
+			h.controller.messageUtils.SendMessage(chatID, "仅管理员可用")
+			return
+		}
+		h.controller.common.RunExclusive(chatID, "/reorganize", func() {
+			h.controller.fileHandler.HandleReorganize(chatID)
+		})
+	case strings.HasPrefix(command, "/savepreset"):
+		h.controller.fileHandler.HandleSavePreset(chatID, msg.From.ID, strings.TrimPrefix(command, "/savepreset"))
+	case strings.HasPrefix(command, "/presets"):
+		h.controller.fileHandler.HandlePresetsWithEdit(chatID, msg.From.ID, 0)
+	case strings.HasPrefix(command, "/preset"):
+		h.controller.common.RunExclusive(chatID, "/preset", func() {
+			h.controller.fileHandler.HandlePreset(chatID, msg.From.ID, strings.TrimPrefix(command, "/preset"))
+		})
+	case strings.HasPrefix(command, "/unpin"):
+		h.controller.fileHandler.HandleUnpin(chatID, msg.From.ID, strings.TrimPrefix(command, "/unpin"))
+	case strings.HasPrefix(command, "/pin"):
+		h.controller.fileHandler.HandlePin(chatID, msg.From.ID, strings.TrimPrefix(command, "/pin"))
+	case strings.HasPrefix(command, "/stats"):
+		h.controller.statusHandler.HandleStats(chatID, strings.TrimPrefix(command, "/stats"), 0)
+	case strings.HasPrefix(command, "/diskspace"):
+		h.controller.statusHandler.HandleDiskSpace(chatID, 0)
+	case strings.HasPrefix(command, "/bandwidth"):
+		h.controller.statusHandler.HandleBandwidth(chatID)
+	case strings.HasPrefix(command, "/testnotify"):
+		h.handleTestNotifyCommand(chatID, command)
 	default:
 		h.controller.messageUtils.SendMessage(chatID, "未知命令，发送 /help 查看可用命令")
 	}
 }
 
+// redactCommandSecrets hides header/cookie values passed to /download before logging
+func redactCommandSecrets(command string) string {
+	if !strings.Contains(command, "header=") && !strings.Contains(command, "cookie=") {
+		return command
+	}
+
+	// Split like /download does so quoted values are redacted as a whole
+	parts := utils.SplitQuotedArgs(command)
+	for i, part := range parts {
+		if value, found := strings.CutPrefix(part, "header="); found {
+			name, _, _ := strings.Cut(value, ":")
+			parts[i] = "header=" + name + ":***"
+		} else if strings.HasPrefix(part, "cookie=") {
+			parts[i] = "cookie=***"
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 // handleLLMRenameCommand 处理/llmrename命令
 func (h *MessageHandler) handleLLMRenameCommand(chatID int64, command string) {
 	parts := strings.Fields(command)
@@ -129,3 +291,48 @@ func (h *MessageHandler) handleLLMRenameCommand(chatID int64, command string) {
 	// 调用LLM重命名处理
 	h.controller.basicCommands.HandleLLMRename(chatID, path, strategy)
 }
+
+// handleTestNotifyCommand sends a test notification through the selected channels.
+// Usage: /testnotify [telegram|email|all]
+func (h *MessageHandler) handleTestNotifyCommand(chatID int64, command string) {
+	if h.controller.notificationService == nil {
+		h.controller.messageUtils.SendMessage(chatID, "通知服务不可用")
+		return
+	}
+
+	target := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(command, "/testnotify")))
+	var channels []contracts.NotificationChannel
+	switch target {
+	case "", "all":
+		channels = []contracts.NotificationChannel{contracts.ChannelTelegram, contracts.ChannelEmail}
+	case "telegram":
+		channels = []contracts.NotificationChannel{contracts.ChannelTelegram}
+	case "email":
+		channels = []contracts.NotificationChannel{contracts.ChannelEmail}
+	default:
+		h.controller.messageUtils.SendMessageHTML(chatID,
+			"<b>用法错误</b>\n\n使用方式：<code>/testnotify [telegram|email|all]</code>")
+		return
+	}
+
+	ctx := context.Background()
+	service := h.controller.notificationService
+	var sb strings.Builder
+	sb.WriteString("<b>🔔 通知渠道测试</b>\n\n")
+
+	for _, channel := range channels {
+		err := service.CheckChannelHealth(ctx, channel)
+		if err == nil {
+			// Telegram test goes to the current chat only
+			err = service.TestNotification(ctx, channel, strconv.FormatInt(chatID, 10))
+		}
+		if err != nil {
+			logger.Warn("Test notification failed", "channel", channel, "error", err)
+			sb.WriteString(fmt.Sprintf("❌ %s: <code>%s</code>\n", channel, h.controller.messageUtils.EscapeHTML(err.Error())))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("✅ %s: 已发送\n", channel))
+	}
+
+	h.controller.messageUtils.SendMessageHTML(chatID, sb.String())
+}
@@ -0,0 +1,42 @@
+package telegram
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+)
+
+// applyShortcuts validates the configured shortcut buttons and installs them on the reply keyboard.
+// Call again after the Telegram config changes.
+func (c *TelegramController) applyShortcuts() {
+	c.shortcuts = validShortcuts(c.config.Telegram.Shortcuts)
+	c.messageUtils.SetShortcuts(c.shortcuts)
+}
+
+// validShortcuts drops shortcuts whose command is not routed by the bot, logging a warning for each
+func validShortcuts(shortcuts []config.ShortcutConfig) []config.ShortcutConfig {
+	valid := make([]config.ShortcutConfig, 0, len(shortcuts))
+	for _, shortcut := range shortcuts {
+		shortcut.Label = strings.TrimSpace(shortcut.Label)
+		shortcut.Command = strings.TrimSpace(shortcut.Command)
+		name, _, _ := strings.Cut(shortcut.Command, " ")
+		if !slices.Contains(routedCommands, name) {
+			logger.Warn("Ignoring shortcut with unknown command", "label", shortcut.Label, "command", shortcut.Command)
+			continue
+		}
+		valid = append(valid, shortcut)
+	}
+	return valid
+}
+
+// shortcutCommand returns the command bound to a shortcut button label
+func (c *TelegramController) shortcutCommand(text string) (string, bool) {
+	for _, shortcut := range c.shortcuts {
+		if shortcut.Label == text {
+			return shortcut.Command, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,26 @@
+package telegram
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+)
+
+// TestValidShortcuts 测试映射到未知命令的快捷按钮被忽略
+func TestValidShortcuts(t *testing.T) {
+	got := validShortcuts([]config.ShortcutConfig{
+		{Label: " 🎬 电影 ", Command: "/list /movies"},
+		{Label: "最近", Command: "/recent"},
+		{Label: "未知", Command: "/nosuch arg"},
+		{Label: "前缀", Command: "/listall"},
+	})
+
+	want := []config.ShortcutConfig{
+		{Label: "🎬 电影", Command: "/list /movies"},
+		{Label: "最近", Command: "/recent"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("validShortcuts() = %v, want %v", got, want)
+	}
+}
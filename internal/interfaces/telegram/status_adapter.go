@@ -46,10 +46,102 @@ func (h *StatusHandler) HandleDownloadStatusAPIWithEdit(chatID int64, messageID
 	h.handler.HandleDownloadStatusAPIWithEdit(chatID, messageID)
 }
 
+func (h *StatusHandler) HandleDownloadListPage(chatID int64, page int, messageID int) {
+	h.handler.HandleDownloadListPage(chatID, page, messageID)
+}
+
+func (h *StatusHandler) HandleCancelDownloadConfirm(chatID int64, gid string, messageID int) {
+	h.handler.HandleCancelDownloadConfirm(chatID, gid, messageID)
+}
+
+func (h *StatusHandler) HandleCancelDownload(chatID int64, gid string, messageID int) {
+	h.handler.HandleCancelDownload(chatID, gid, messageID)
+}
+
 func (h *StatusHandler) HandleAlistLoginWithEdit(chatID int64, messageID int) {
 	h.handler.HandleAlistLoginWithEdit(chatID, messageID)
 }
 
+func (h *StatusHandler) HandleBandwidth(chatID int64) {
+	h.handler.HandleBandwidth(chatID)
+}
+
+func (h *StatusHandler) HandleTaskInfo(chatID int64, gid string, messageID int) {
+	h.handler.HandleTaskInfo(chatID, gid, messageID)
+}
+
+func (h *StatusHandler) HandleDownloadNow(chatID int64, gid string, messageID int) {
+	h.handler.HandleDownloadNow(chatID, gid, messageID)
+}
+
+func (h *StatusHandler) HandleChangePosition(chatID int64, gid string, messageID int, top bool) {
+	h.handler.HandleChangePosition(chatID, gid, messageID, top)
+}
+
+func (h *StatusHandler) HandleBoostDownload(chatID int64, gid string, messageID int) {
+	h.handler.HandleBoostDownload(chatID, gid, messageID)
+}
+
+func (h *StatusHandler) HandlePauseDownload(chatID int64, gid string, messageID int, pause bool) {
+	h.handler.HandlePauseDownload(chatID, gid, messageID, pause)
+}
+
+func (h *StatusHandler) HandleDownloadControlWithEdit(chatID int64, messageID int) {
+	h.handler.HandleDownloadControlWithEdit(chatID, messageID)
+}
+
+func (h *StatusHandler) StartProgressTracking(chatID int64, gid string, messageID int) {
+	h.handler.StartProgressTracking(chatID, gid, messageID)
+}
+
+func (h *StatusHandler) StopProgressTracking(chatID int64, messageID int) {
+	h.handler.StopProgressTracking(chatID, messageID)
+}
+
+func (h *StatusHandler) StopAllProgressTracking() {
+	h.handler.StopAllProgressTracking()
+}
+
+func (h *StatusHandler) HandleCancelTaskFile(chatID int64, args string, messageID int) {
+	h.handler.HandleCancelTaskFile(chatID, args, messageID)
+}
+
+func (h *StatusHandler) HandleRetryFailedBatch(chatID, userID int64, batchID string) {
+	h.handler.HandleRetryFailedBatch(chatID, userID, batchID)
+}
+
+func (h *StatusHandler) HandleQueueControlConfirm(chatID int64, messageID int, pause bool) {
+	h.handler.HandleQueueControlConfirm(chatID, messageID, pause)
+}
+
+func (h *StatusHandler) HandleQueueControl(chatID int64, messageID int, pause bool) {
+	h.handler.HandleQueueControl(chatID, messageID, pause)
+}
+
+func (h *StatusHandler) HandleCancelAllConfirm(chatID int64, messageID int) {
+	h.handler.HandleCancelAllConfirm(chatID, messageID)
+}
+
+func (h *StatusHandler) HandleCancelAll(chatID int64, messageID int) {
+	h.handler.HandleCancelAll(chatID, messageID)
+}
+
+func (h *StatusHandler) HandleRecentDownloads(chatID int64, messageID int) {
+	h.handler.HandleRecentDownloads(chatID, messageID)
+}
+
+func (h *StatusHandler) HandleMoveDownloadPrompt(chatID int64, gid string) {
+	h.handler.HandleMoveDownloadPrompt(chatID, gid)
+}
+
+func (h *StatusHandler) HandleMoveDownloadCommand(chatID int64, args string) {
+	h.handler.HandleMoveDownloadCommand(chatID, args)
+}
+
+func (h *StatusHandler) HandleFindCommand(chatID int64, args string) {
+	h.handler.HandleFindCommand(chatID, args)
+}
+
 func (h *StatusHandler) HandleHealthCheckWithEdit(chatID int64, messageID int) {
 	h.handler.HandleHealthCheckWithEdit(chatID, messageID)
 }
@@ -62,6 +154,14 @@ func (h *StatusHandler) HandleStatusStorageWithEdit(chatID int64, messageID int)
 	h.handler.HandleStatusStorageWithEdit(chatID, messageID)
 }
 
+func (h *StatusHandler) HandleDiskSpace(chatID int64, messageID int) {
+	h.handler.HandleDiskSpace(chatID, messageID)
+}
+
+func (h *StatusHandler) HandleStats(chatID int64, period string, messageID int) {
+	h.handler.HandleStats(chatID, period, messageID)
+}
+
 func (h *StatusHandler) HandleStatusHistoryWithEdit(chatID int64, messageID int) {
 	h.handler.HandleStatusHistoryWithEdit(chatID, messageID)
 }
@@ -33,6 +33,14 @@ func (h *TaskHandler) GetSchedulerService() *services.SchedulerService {
 	return h.controller.schedulerService
 }
 
+func (h *TaskHandler) EncodeFilePath(path string) string {
+	return h.controller.common.EncodeFilePath(path)
+}
+
+func (h *TaskHandler) DecodeFilePath(encoded string) string {
+	return h.controller.common.DecodeFilePath(encoded)
+}
+
 // ================================
 // 代理方法
 // ================================
@@ -40,3 +48,19 @@ func (h *TaskHandler) GetSchedulerService() *services.SchedulerService {
 func (h *TaskHandler) HandleTasksWithEdit(chatID int64, userID int64, messageID int) {
 	h.handler.HandleTasksWithEdit(chatID, userID, messageID)
 }
+
+func (h *TaskHandler) HandleTaskList(chatID int64, userID int64, filter string) {
+	h.handler.HandleTaskList(chatID, userID, filter)
+}
+
+func (h *TaskHandler) HandleTaskPage(chatID int64, userID int64, page int, filter string, messageID int) {
+	h.handler.HandleTaskPage(chatID, userID, page, filter, messageID)
+}
+
+func (h *TaskHandler) HandleTaskAction(chatID int64, userID int64, action, taskID string, page int, filterToken string, messageID int) {
+	h.handler.HandleTaskAction(chatID, userID, action, taskID, page, filterToken, messageID)
+}
+
+func (h *TaskHandler) HandleRetryFailedItems(chatID int64, userID int64, taskID string) {
+	h.handler.HandleRetryFailedItems(chatID, userID, taskID)
+}
@@ -39,9 +39,17 @@ const (
 
 	// MediumConfidence 中等置信度阈值（用于显示星级）
 	MediumConfidence = 0.7
+)
+
+// MessageLifetime decides whether an auto-delete message is actually deleted
+type MessageLifetime int
 
-	// MessageAutoDeleteSeconds 消息自动删除时间（秒）
-	MessageAutoDeleteSeconds = 30
+const (
+	// MessageTransient progress notes and hints, deleted after telegram.auto_delete.transient_seconds
+	MessageTransient MessageLifetime = iota
+	// MessageImportant results users may want to keep (e.g. download summaries),
+	// deleted only when telegram.auto_delete.important_seconds is set
+	MessageImportant
 )
 
 // DownloadResult download result structure
@@ -70,13 +78,18 @@ type MessageSender interface {
 	SendMessageHTML(chatID int64, text string)
 	SendMessageMarkdown(chatID int64, text string)
 
-	// Message sending with auto deletion
-	SendMessageWithAutoDelete(chatID int64, text string, deleteAfterSeconds int)
-	SendMessageHTMLWithAutoDelete(chatID int64, text string, deleteAfterSeconds int)
+	// Message sending with auto deletion, the delay depends on the message lifetime
+	SendMessageWithAutoDelete(chatID int64, text string, lifetime MessageLifetime)
+	SendMessageHTMLWithAutoDelete(chatID int64, text string, lifetime MessageLifetime)
 
 	// Message sending with keyboard
 	SendMessageWithKeyboard(chatID int64, text, parseMode string, keyboard *tgbotapi.InlineKeyboardMarkup) int
 	SendMessageWithReplyKeyboard(chatID int64, text string)
+	SendForceReply(chatID int64, text, placeholder string) int
+
+	// Media sending
+	SendPhoto(chatID int64, fileName string, data []byte, caption string) int
+	SendDocument(chatID int64, fileName string, data []byte, caption string) int
 
 	// Message editing
 	EditMessageWithKeyboard(chatID int64, messageID int, text, parseMode string, keyboard *tgbotapi.InlineKeyboardMarkup) bool
@@ -84,7 +97,7 @@ type MessageSender interface {
 
 	// Message deletion
 	DeleteMessage(chatID int64, messageID int)
-	DeleteMessageAfterDelay(chatID int64, messageID int, delaySeconds int)
+	DeleteMessageAfterDelay(chatID int64, messageID int, lifetime MessageLifetime)
 
 	// Utility methods
 	EscapeHTML(text string) string
@@ -100,6 +113,6 @@ type MessageSender interface {
 
 // DownloadCommandHandler download command handler interface
 type DownloadCommandHandler interface {
-	HandleDownload(chatID int64, command string)
+	HandleDownload(chatID, userID int64, command string)
 	HandleCancel(chatID int64, command string)
 }
@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// updateKind identifies which field of a Telegram update is populated
+type updateKind string
+
+const (
+	updateMessage       updateKind = "message"
+	updateEditedMessage updateKind = "edited_message"
+	updateCallbackQuery updateKind = "callback_query"
+	updateInlineQuery   updateKind = "inline_query"
+	updateMyChatMember  updateKind = "my_chat_member"
+	updateUnsupported   updateKind = "unsupported"
+)
+
+// messageUpdateHandler handles message updates; implemented by *MessageHandler
+type messageUpdateHandler interface {
+	HandleMessage(update *tgbotapi.Update)
+}
+
+// classifyUpdate returns the kind of an update; Telegram populates exactly one field per update
+func classifyUpdate(update *tgbotapi.Update) updateKind {
+	switch {
+	case update.Message != nil:
+		return updateMessage
+	case update.EditedMessage != nil:
+		return updateEditedMessage
+	case update.CallbackQuery != nil:
+		return updateCallbackQuery
+	case update.InlineQuery != nil:
+		return updateInlineQuery
+	case update.MyChatMember != nil:
+		return updateMyChatMember
+	default:
+		return updateUnsupported
+	}
+}
+
+// dispatchUpdate routes an update to its handler.
+// Shared by webhook and polling mode so both process the same update types.
+func (c *TelegramController) dispatchUpdate(update *tgbotapi.Update) {
+	switch classifyUpdate(update) {
+	case updateMessage:
+		c.messageHandler.HandleMessage(update)
+	case updateCallbackQuery:
+		c.callbackHandler.HandleCallbackQuery(update)
+	case updateEditedMessage:
+		// Commands are not re-run when edited, otherwise fixing a typo would execute a command twice
+		msg := update.EditedMessage
+		logger.Debug("Ignoring edited telegram message", "chatID", msg.Chat.ID, "messageID", msg.MessageID)
+	case updateInlineQuery:
+		query := update.InlineQuery
+		logger.Info("Ignoring telegram inline query, inline mode is not supported", "from", query.From.ID, "query", query.Query)
+	case updateMyChatMember:
+		c.handleMyChatMember(update.MyChatMember)
+	default:
+		logger.Debug("Ignoring unsupported telegram update", "updateID", update.UpdateID)
+	}
+}
+
+// handleMyChatMember logs when the bot is added to or removed from a chat
+func (c *TelegramController) handleMyChatMember(member *tgbotapi.ChatMemberUpdated) {
+	chat := member.Chat
+	status := member.NewChatMember
+	switch {
+	case status.HasLeft() || status.WasKicked():
+		logger.Info("Bot removed from telegram chat",
+			"chatID", chat.ID, "title", chat.Title, "type", chat.Type, "by", member.From.ID, "status", status.Status)
+	case member.OldChatMember.HasLeft() || member.OldChatMember.WasKicked():
+		logger.Info("Bot added to telegram chat",
+			"chatID", chat.ID, "title", chat.Title, "type", chat.Type, "by", member.From.ID, "status", status.Status)
+	default:
+		logger.Info("Bot membership changed in telegram chat",
+			"chatID", chat.ID, "title", chat.Title, "from", member.OldChatMember.Status, "to", status.Status)
+	}
+}
@@ -0,0 +1,41 @@
+package telegram
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// TestClassifyUpdate 测试 webhook 和轮询共用的更新类型路由
+func TestClassifyUpdate(t *testing.T) {
+	chat := &tgbotapi.Chat{ID: -100, Type: "group", Title: "media"}
+	tests := []struct {
+		name   string
+		update tgbotapi.Update
+		want   updateKind
+	}{
+		{name: "message", update: tgbotapi.Update{Message: &tgbotapi.Message{Chat: chat, Text: "/help"}}, want: updateMessage},
+		{name: "edited message", update: tgbotapi.Update{EditedMessage: &tgbotapi.Message{Chat: chat}}, want: updateEditedMessage},
+		{name: "callback query", update: tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{Data: "back_main"}}, want: updateCallbackQuery},
+		{name: "inline query", update: tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{From: &tgbotapi.User{ID: 1}}}, want: updateInlineQuery},
+		{name: "my chat member", update: tgbotapi.Update{MyChatMember: &tgbotapi.ChatMemberUpdated{
+			Chat:          *chat,
+			OldChatMember: tgbotapi.ChatMember{Status: "left"},
+			NewChatMember: tgbotapi.ChatMember{Status: "member"},
+		}}, want: updateMyChatMember},
+		{name: "channel post", update: tgbotapi.Update{ChannelPost: &tgbotapi.Message{Chat: chat}}, want: updateUnsupported},
+	}
+
+	c := &TelegramController{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyUpdate(&tt.update); got != tt.want {
+				t.Fatalf("classifyUpdate() = %q, want %q", got, tt.want)
+			}
+			// 只记录日志的更新类型不依赖消息和回调处理器
+			if tt.want != updateMessage && tt.want != updateCallbackQuery {
+				c.dispatchUpdate(&tt.update)
+			}
+		})
+	}
+}
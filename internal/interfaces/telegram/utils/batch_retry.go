@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// BatchRetryCallbackPrefix is the callback data prefix of the "retry all failed" button
+const BatchRetryCallbackPrefix = "batch_retry:"
+
+// MaxRetryErrorsShown caps the still-failing files listed after a batch or task retry
+const MaxRetryErrorsShown = 5
+
+// RetryErrorLines lists the files that still failed after a retry with their errors,
+// at most MaxRetryErrorsShown of them followed by a count of the rest.
+func RetryErrorLines(results []contracts.DownloadResult, failureCount int, escapeHTML func(string) string) []string {
+	var lines []string
+	for _, r := range results {
+		if r.Success {
+			continue
+		}
+		if len(lines) == MaxRetryErrorsShown {
+			lines = append(lines, fmt.Sprintf("• ... 还有 %d 个", failureCount-len(lines)))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("• %s：%s", escapeHTML(r.Request.Filename), escapeHTML(r.Error)))
+	}
+	return lines
+}
+
+// BatchRetryKeyboard returns a "retry all failed" keyboard for a batch result,
+// or nil when nothing failed or the batch was not recorded.
+func BatchRetryKeyboard(batchID string, failureCount int) *tgbotapi.InlineKeyboardMarkup {
+	if batchID == "" || failureCount == 0 {
+		return nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 重试全部失败", BatchRetryCallbackPrefix+batchID),
+		),
+	)
+	return &keyboard
+}
+
+// BatchChunkNote explains that a large batch was split and the remaining files are queued in the background,
+// or returns "" when everything was submitted at once.
+func BatchChunkNote(pendingCount, chunkCount int) string {
+	if pendingCount == 0 {
+		return ""
+	}
+	return fmt.Sprintf("📦 文件较多，已分 %d 批提交：其余 %d 个文件将在下载队列空出后自动提交，每批提交后发送进度通知", chunkCount, pendingCount)
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AllFilesFlag includes non-video files in time range downloads
+const AllFilesFlag = "--all"
+
+// ExtractAllFilesFlag removes AllFilesFlag from args, reporting whether it was present.
+func ExtractAllFilesFlag(args []string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	found := false
+	for _, arg := range args {
+		if strings.EqualFold(arg, AllFilesFlag) {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+// NormalizeCommand normalizes a slash command before routing.
+// It strips the "@botusername" suffix and the configured command prefix,
+// e.g. "/dl_list@mybot /movies" -> "/list /movies".
+// Returns false if the command should be ignored: addressed to another bot,
+// or sent in a group without prefix/@mention while a prefix is configured.
+// Non-command text is returned unchanged.
+func NormalizeCommand(text, botUsername, prefix string, isGroup bool) (string, bool) {
+	if !strings.HasPrefix(text, "/") {
+		return text, true
+	}
+
+	name, rest := text, ""
+	if i := strings.IndexAny(text, " \t\n"); i >= 0 {
+		name, rest = text[:i], text[i:]
+	}
+
+	addressed := false
+	if cmd, target, found := strings.Cut(name, "@"); found {
+		if botUsername != "" && !strings.EqualFold(target, botUsername) {
+			return "", false
+		}
+		name = cmd
+		addressed = true
+	}
+
+	if prefix != "" {
+		if cmd, found := strings.CutPrefix(name, "/"+prefix); found && cmd != "" {
+			name = "/" + cmd
+			addressed = true
+		}
+		if isGroup && !addressed {
+			return "", false
+		}
+	}
+
+	return name + rest, true
+}
+
+// SplitQuotedArgs splits a command on whitespace like strings.Fields, but keeps
+// text inside double quotes together and drops the quotes, so
+// header="Authorization: Bearer x" stays one argument. An unclosed quote runs
+// to the end of the command.
+// Single quotes are kept literally since they appear in URLs and file names.
+func SplitQuotedArgs(command string) []string {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	quoted := false
+	for _, r := range command {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inArg = true
+		case quoted:
+			current.WriteRune(r)
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
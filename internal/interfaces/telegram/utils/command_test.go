@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		botUsername string
+		prefix      string
+		isGroup     bool
+		expected    string
+		expectedOK  bool
+	}{
+		{
+			name:        "普通命令",
+			text:        "/list /movies",
+			botUsername: "mybot",
+			expected:    "/list /movies",
+			expectedOK:  true,
+		},
+		{
+			name:        "去除@本机器人",
+			text:        "/list@mybot /movies",
+			botUsername: "mybot",
+			expected:    "/list /movies",
+			expectedOK:  true,
+		},
+		{
+			name:        "@用户名不区分大小写",
+			text:        "/start@MyBot",
+			botUsername: "mybot",
+			expected:    "/start",
+			expectedOK:  true,
+		},
+		{
+			name:        "发给其他机器人的命令被忽略",
+			text:        "/start@otherbot",
+			botUsername: "mybot",
+			expectedOK:  false,
+		},
+		{
+			name:        "未知机器人用户名时仍去除@后缀",
+			text:        "/help@mybot",
+			botUsername: "",
+			expected:    "/help",
+			expectedOK:  true,
+		},
+		{
+			name:        "去除命令前缀",
+			text:        "/dl_list /movies",
+			botUsername: "mybot",
+			prefix:      "dl_",
+			isGroup:     true,
+			expected:    "/list /movies",
+			expectedOK:  true,
+		},
+		{
+			name:        "同时带前缀和@本机器人",
+			text:        "/dl_tasks@mybot 下载",
+			botUsername: "mybot",
+			prefix:      "dl_",
+			isGroup:     true,
+			expected:    "/tasks 下载",
+			expectedOK:  true,
+		},
+		{
+			name:        "群组中未带前缀的命令被忽略",
+			text:        "/start",
+			botUsername: "mybot",
+			prefix:      "dl_",
+			isGroup:     true,
+			expectedOK:  false,
+		},
+		{
+			name:        "群组中@本机器人无需前缀",
+			text:        "/start@mybot",
+			botUsername: "mybot",
+			prefix:      "dl_",
+			isGroup:     true,
+			expected:    "/start",
+			expectedOK:  true,
+		},
+		{
+			name:        "私聊中未带前缀的命令仍可用",
+			text:        "/start",
+			botUsername: "mybot",
+			prefix:      "dl_",
+			expected:    "/start",
+			expectedOK:  true,
+		},
+		{
+			name:        "非命令文本原样返回",
+			text:        "帮助",
+			botUsername: "mybot",
+			prefix:      "dl_",
+			isGroup:     true,
+			expected:    "帮助",
+			expectedOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NormalizeCommand(tt.text, tt.botUsername, tt.prefix, tt.isGroup)
+			if ok != tt.expectedOK {
+				t.Fatalf("NormalizeCommand(%q) ok = %v, want %v", tt.text, ok, tt.expectedOK)
+			}
+			if ok && got != tt.expected {
+				t.Errorf("NormalizeCommand(%q) = %q, want %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractAllFilesFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantArgs  []string
+		wantFound bool
+	}{
+		{name: "无参数", args: nil, wantArgs: []string{}, wantFound: false},
+		{name: "仅时间参数", args: []string{"48"}, wantArgs: []string{"48"}, wantFound: false},
+		{name: "标志在末尾", args: []string{"confirm", "48", "--all"}, wantArgs: []string{"confirm", "48"}, wantFound: true},
+		{name: "标志在开头且大写", args: []string{"--ALL", "yesterday"}, wantArgs: []string{"yesterday"}, wantFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, found := ExtractAllFilesFlag(tt.args)
+			if !slices.Equal(args, tt.wantArgs) || found != tt.wantFound {
+				t.Errorf("ExtractAllFilesFlag(%v) = %v, %v, want %v, %v", tt.args, args, found, tt.wantArgs, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestSplitQuotedArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{name: "无引号", command: "/download  https://a.com/f.mkv speed=2M", want: []string{"/download", "https://a.com/f.mkv", "speed=2M"}},
+		{name: "引号内保留空格", command: `/download https://a.com/f header="Authorization: Bearer x"`, want: []string{"/download", "https://a.com/f", "header=Authorization: Bearer x"}},
+		{name: "整个参数加引号", command: `/download u "cookie=a=1; b=2"`, want: []string{"/download", "u", "cookie=a=1; b=2"}},
+		{name: "空引号", command: `/x ""`, want: []string{"/x", ""}},
+		{name: "未闭合引号", command: `/x header="A: b c`, want: []string{"/x", "header=A: b c"}},
+		{name: "单引号原样保留", command: "/x it's", want: []string{"/x", "it's"}},
+		{name: "空命令", command: "  ", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitQuotedArgs(tt.command); !slices.Equal(got, tt.want) {
+				t.Errorf("SplitQuotedArgs(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
 )
 
 // MessageFormatter message formatting utility - follows Telegram Bot API HTML best practices
@@ -330,7 +333,22 @@ func (mf *MessageFormatter) truncateID(id string) string {
 type DownloadListData struct {
 	TotalCount  int
 	ActiveCount int
+	PausedCount int
+	AllPaused   bool // 队列已全部暂停
 	Downloads   []DownloadItemData
+	Categories  []CategoryConcurrencyData // 配置了同时下载数上限的分类
+	Offset      int                       // 当前页第一个任务之前的任务数，用于编号
+	Page        int
+	TotalPages  int
+}
+
+// CategoryConcurrencyData 分类并发情况
+type CategoryConcurrencyData struct {
+	Label   string
+	Limit   int
+	Active  int
+	Waiting int
+	Held    int
 }
 
 type DownloadItemData struct {
@@ -338,6 +356,7 @@ type DownloadItemData struct {
 	ID          string
 	Filename    string
 	Progress    float64
+	SpeedLimit  string // 已格式化的单任务限速，为空表示不限速
 }
 
 func (mf *MessageFormatter) FormatDownloadList(data DownloadListData) string {
@@ -348,22 +367,39 @@ func (mf *MessageFormatter) FormatDownloadList(data DownloadListData) string {
 	lines = append(lines, "")
 
 	// 统计信息
-	if data.ActiveCount > 0 {
-		lines = append(lines, mf.FormatField("活动任务", fmt.Sprintf("%d 个", data.ActiveCount)))
+	if data.AllPaused {
+		lines = append(lines, fmt.Sprintf("⏸️ <b>队列已全部暂停</b>（%d 个任务），发送 /resumeall 恢复", data.PausedCount))
+		lines = append(lines, "")
+	} else if data.ActiveCount > 0 || data.PausedCount > 0 {
+		if data.ActiveCount > 0 {
+			lines = append(lines, mf.FormatField("活动任务", fmt.Sprintf("%d 个", data.ActiveCount)))
+		}
+		if data.PausedCount > 0 {
+			lines = append(lines, mf.FormatField("暂停任务", fmt.Sprintf("%d 个", data.PausedCount)))
+		}
 		lines = append(lines, "")
 	}
 
-	// 任务列表 - 固定格式
-	displayCount := len(data.Downloads)
-	if displayCount > 10 {
-		displayCount = 10
+	// 分类并发：下载中/上限，超限暂停的任务在同分类有空位时自动恢复
+	if len(data.Categories) > 0 {
+		lines = append(lines, "<b>分类并发:</b>")
+		for _, c := range data.Categories {
+			line := fmt.Sprintf("• %s %d/%d", c.Label, c.Active, c.Limit)
+			if c.Waiting > 0 {
+				line += fmt.Sprintf("，排队 %d", c.Waiting)
+			}
+			if c.Held > 0 {
+				line += fmt.Sprintf("，限流暂停 %d", c.Held)
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "")
 	}
 
-	for i := 0; i < displayCount; i++ {
-		item := data.Downloads[i]
-
+	// 任务列表 - 固定格式
+	for i, item := range data.Downloads {
 		// 序号和状态
-		prefix := fmt.Sprintf("%d. %s", i+1, item.StatusEmoji)
+		prefix := fmt.Sprintf("%d. %s", data.Offset+i+1, item.StatusEmoji)
 
 		// ID (截断)
 		shortID := mf.truncateID(item.ID)
@@ -374,18 +410,20 @@ func (mf *MessageFormatter) FormatDownloadList(data DownloadListData) string {
 			shortID,
 			wrappedFilename,
 			item.Progress)
+		if item.SpeedLimit != "" {
+			taskInfo += fmt.Sprintf(" ⏱ 限速 %s/s", item.SpeedLimit)
+		}
 
 		lines = append(lines, fmt.Sprintf("%s %s", prefix, taskInfo))
 
-		if i < displayCount-1 {
+		if i < len(data.Downloads)-1 {
 			lines = append(lines, "")
 		}
 	}
 
-	// 显示剩余数量
-	if len(data.Downloads) > 10 {
+	if data.TotalPages > 1 {
 		lines = append(lines, "")
-		lines = append(lines, fmt.Sprintf("... 还有 %d 个任务", len(data.Downloads)-10))
+		lines = append(lines, fmt.Sprintf("第 %d/%d 页", data.Page, data.TotalPages))
 	}
 
 	message := strings.Join(lines, "\n")
@@ -397,10 +435,13 @@ type SystemStatusData struct {
 	ServiceStatus  string
 	Port           string
 	Mode           string
+	ReadOnly       bool
 	AlistURL       string
 	AlistPath      string
 	Aria2RPC       string
 	Aria2Dir       string
+	Aria2Status    string
+	SpeedLimited   []string // 设置了单任务限速的活动任务（HTML），如 "<code>gid</code> 1.0 MB/s"
 	TelegramStatus string
 	TelegramUsers  int
 	TelegramAdmins int
@@ -420,6 +461,9 @@ func (mf *MessageFormatter) FormatSystemStatus(data SystemStatusData) string {
 	lines = append(lines, mf.FormatField("状态", data.ServiceStatus))
 	lines = append(lines, mf.FormatFieldCode("端口", data.Port))
 	lines = append(lines, mf.FormatFieldCode("模式", data.Mode))
+	if data.ReadOnly {
+		lines = append(lines, mf.FormatField("只读模式", "🔒 开启（删除/移动/重命名已禁用）"))
+	}
 
 	// Alist配置 - 使用智能换行
 	lines = append(lines, mf.FormatSection("📂 Alist配置"))
@@ -436,6 +480,12 @@ func (mf *MessageFormatter) FormatSystemStatus(data SystemStatusData) string {
 
 	wrappedDir := mf.formatLongPath(data.Aria2Dir)
 	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("下载目录: <code>%s</code>", wrappedDir)))
+	if data.Aria2Status != "" {
+		lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("连接状态: %s", data.Aria2Status)))
+	}
+	if len(data.SpeedLimited) > 0 {
+		lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("限速任务: %s", strings.Join(data.SpeedLimited, "，"))))
+	}
 
 	// Telegram配置
 	lines = append(lines, mf.FormatSection("📱 Telegram配置"))
@@ -456,15 +506,29 @@ func (mf *MessageFormatter) FormatSystemStatus(data SystemStatusData) string {
 
 // FormatBatchResult 格式化批量操作结果 - 固定宽度布局
 type BatchResultData struct {
-	Title        string
-	TotalFiles   int
-	VideoFiles   int
-	SuccessCount int
-	FailureCount int
-	MovieCount   int
-	TVCount      int
-	OtherCount   int
-	TotalSize    string
+	Title         string
+	TotalFiles    int
+	VideoFiles    int
+	SuccessCount  int
+	FailureCount  int
+	MovieCount    int
+	TVCount       int
+	OtherCount    int
+	MusicCount    int
+	DocumentCount int
+	TotalSize     string
+}
+
+// FormatExtraCategoryCounts 格式化音乐和文档统计行（对应分类未启用或数量为0时不显示）
+func (mf *MessageFormatter) FormatExtraCategoryCounts(musicCount, documentCount int) []string {
+	var lines []string
+	if musicCount > 0 {
+		lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("音乐: %d 个", musicCount)))
+	}
+	if documentCount > 0 {
+		lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("文档: %d 个", documentCount)))
+	}
+	return lines
 }
 
 func (mf *MessageFormatter) FormatBatchResult(data BatchResultData) string {
@@ -490,6 +554,7 @@ func (mf *MessageFormatter) FormatBatchResult(data BatchResultData) string {
 		if data.OtherCount > 0 {
 			lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("其他: %d 个", data.OtherCount)))
 		}
+		lines = append(lines, mf.FormatExtraCategoryCounts(data.MusicCount, data.DocumentCount)...)
 		lines = append(lines, "")
 	}
 
@@ -517,6 +582,50 @@ func (mf *MessageFormatter) FormatBatchResult(data BatchResultData) string {
 	return message
 }
 
+// TaskRunSummaryData 定时任务运行下载汇总数据
+type TaskRunSummaryData struct {
+	TaskID       string
+	TaskName     string
+	Path         string
+	FilesMatched int
+	SuccessCount int
+	FailureCount int
+	TotalSize    string
+	Duration     string
+}
+
+// FormatTaskRunSummary 格式化定时任务运行后的下载汇总
+func (mf *MessageFormatter) FormatTaskRunSummary(data TaskRunSummaryData) string {
+	var lines []string
+
+	lines = append(lines, mf.FormatTitle("📊", "定时任务完成"))
+	lines = append(lines, "")
+	lines = append(lines, mf.FormatField("任务", data.TaskName))
+	lines = append(lines, mf.FormatFieldCode("路径", data.Path))
+	lines = append(lines, mf.FormatFieldCode("任务ID", data.TaskID))
+	lines = append(lines, "")
+
+	lines = append(lines, mf.FormatSection("下载结果"))
+	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("匹配文件: %d 个", data.FilesMatched)))
+	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("成功: %d", data.SuccessCount)))
+	if data.FailureCount > 0 {
+		lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("失败: %d", data.FailureCount)))
+	}
+	if data.TotalSize != "" {
+		lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("总大小: %s", data.TotalSize)))
+	}
+	if data.Duration != "" {
+		lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("耗时: %s", data.Duration)))
+	}
+
+	if data.FailureCount > 0 {
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("⚠️ 有 %d 个文件创建下载失败，可在任务列表中重试", data.FailureCount))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // FormatFileInfo 格式化文件信息 - 固定宽度布局
 type FileInfoData struct {
 	Icon       string
@@ -526,6 +635,8 @@ type FileInfoData struct {
 	Size       string
 	Modified   string
 	IsDir      bool
+	Duration   string // 视频时长，未获取时为空
+	Resolution string // 视频分辨率，未获取时为空
 	EscapeHTML func(string) string
 }
 
@@ -555,6 +666,14 @@ func (mf *MessageFormatter) FormatFileInfo(data FileInfoData) string {
 		lines = append(lines, mf.FormatField("修改时间", data.Modified))
 	}
 
+	if data.Duration != "" {
+		lines = append(lines, mf.FormatField("时长", data.Duration))
+	}
+
+	if data.Resolution != "" {
+		lines = append(lines, mf.FormatField("分辨率", data.Resolution))
+	}
+
 	message := strings.Join(lines, "\n")
 	return message
 }
@@ -601,6 +720,7 @@ func (mf *MessageFormatter) FormatFileOperation(data FileOperationData) string {
 type TaskListData struct {
 	TotalCount int
 	Tasks      []TaskItemData
+	StartIndex int // 分页时本页第一个任务的序号偏移（从0开始）
 }
 
 type TaskItemData struct {
@@ -611,6 +731,12 @@ type TaskItemData struct {
 	StatusEmoji string
 	LastRun     string
 	NextRun     string
+	LastError   string
+	Paused      bool   // Auto-disabled after repeated failures
+	FailedItems int    // Files whose download could not be created and await retry
+	NotifyChat  int64  // Chat receiving run results when different from the creator
+	SizeRange   string // File size filter, empty when unlimited
+	Timezone    string // IANA zone used for run times, empty for the scheduler's timezone
 }
 
 func (mf *MessageFormatter) FormatTaskList(data TaskListData) string {
@@ -630,13 +756,21 @@ func (mf *MessageFormatter) FormatTaskList(data TaskListData) string {
 	for i, task := range data.Tasks {
 		// 任务标题 - 使用智能换行
 		wrappedName := mf.wrapLongText(task.Name, mf.maxWidth-10)
-		taskTitle := fmt.Sprintf("%d. %s %s", i+1, task.StatusEmoji, wrappedName)
+		taskTitle := fmt.Sprintf("%d. %s %s", data.StartIndex+i+1, task.StatusEmoji, wrappedName)
 		lines = append(lines, fmt.Sprintf("<b>%s</b>", taskTitle))
 
 		// 任务详情
 		lines = append(lines, fmt.Sprintf("   ID: <code>%s</code>", task.ID))
 		lines = append(lines, fmt.Sprintf("   计划: %s", task.Schedule))
 
+		if task.SizeRange != "" {
+			lines = append(lines, fmt.Sprintf("   大小: %s", task.SizeRange))
+		}
+
+		if task.Timezone != "" {
+			lines = append(lines, fmt.Sprintf("   时区: %s", task.Timezone))
+		}
+
 		if task.LastRun != "" {
 			lines = append(lines, fmt.Sprintf("   上次: %s", task.LastRun))
 		}
@@ -645,6 +779,21 @@ func (mf *MessageFormatter) FormatTaskList(data TaskListData) string {
 			lines = append(lines, fmt.Sprintf("   下次: %s", task.NextRun))
 		}
 
+		if task.Paused {
+			lines = append(lines, fmt.Sprintf("   状态: %s", task.Status))
+			if task.LastError != "" {
+				lines = append(lines, fmt.Sprintf("   错误: <code>%s</code>", task.LastError))
+			}
+		}
+
+		if task.FailedItems > 0 {
+			lines = append(lines, fmt.Sprintf("   失败待重试: %d 个文件", task.FailedItems))
+		}
+
+		if task.NotifyChat != 0 {
+			lines = append(lines, fmt.Sprintf("   通知: <code>%d</code>", task.NotifyChat))
+		}
+
 		if i < len(data.Tasks)-1 {
 			lines = append(lines, "")
 		}
@@ -663,7 +812,9 @@ type FileBrowserData struct {
 	DirCount   int
 	FileCount  int
 	VideoCount int
-	EscapeHTML func(string) string
+	// HiddenCount 当前页被过滤的隐藏项数量（显示隐藏文件时为0）
+	HiddenCount int
+	EscapeHTML  func(string) string
 }
 
 func (mf *MessageFormatter) FormatFileBrowser(data FileBrowserData) string {
@@ -698,6 +849,10 @@ func (mf *MessageFormatter) FormatFileBrowser(data FileBrowserData) string {
 		}
 	}
 
+	if data.HiddenCount > 0 {
+		lines = append(lines, mf.FormatField("隐藏项", fmt.Sprintf("%d 个已隐藏", data.HiddenCount)))
+	}
+
 	// 页码信息
 	if data.TotalPages > 1 {
 		lines = append(lines, mf.FormatField("页码", fmt.Sprintf("第 %d/%d 页", data.Page, data.TotalPages)))
@@ -726,7 +881,9 @@ func (mf *MessageFormatter) FormatTimeRangeHelp(errorMsg string) string {
 	lines = append(lines, mf.FormatSection("支持的格式"))
 	lines = append(lines, mf.FormatListItem("•", "<code>/download</code> - 预览最近24小时"))
 	lines = append(lines, mf.FormatListItem("•", "<code>/download 48</code> - 预览最近48小时"))
+	lines = append(lines, mf.FormatListItem("•", "<code>/download yesterday</code> - 预览昨天（整天）"))
 	lines = append(lines, mf.FormatListItem("•", "<code>/download 2025-09-01 2025-09-26</code> - 预览日期范围"))
+	lines = append(lines, mf.FormatListItem("•", "<code>/download latest 10</code> - 预览最新的10个文件"))
 	lines = append(lines, mf.FormatListItem("•", "<code>/download 2025-09-01T00:00:00Z ...</code> - 精确时间"))
 
 	lines = append(lines, "")
@@ -762,6 +919,48 @@ func (mf *MessageFormatter) FormatDownloadControl(data DownloadControlData) stri
 	return message
 }
 
+// DownloadStatsData 下载历史统计数据
+type DownloadStatsData struct {
+	PeriodLabel string // 今日、本周、本月
+	Since       string
+	TotalFiles  int
+	TotalSize   string
+	Categories  []CategoryStatsItem
+}
+
+// CategoryStatsItem 单个分类的下载统计
+type CategoryStatsItem struct {
+	Label   string
+	Files   int
+	Size    string
+	Percent float64 // 占总大小的百分比
+}
+
+// FormatDownloadStats 格式化下载历史统计
+func (mf *MessageFormatter) FormatDownloadStats(data DownloadStatsData) string {
+	var lines []string
+
+	lines = append(lines, mf.FormatTitle("📊", data.PeriodLabel+"下载统计"))
+	lines = append(lines, "")
+	lines = append(lines, mf.FormatField("统计起点", data.Since))
+	lines = append(lines, mf.FormatField("完成下载", fmt.Sprintf("%d 个文件", data.TotalFiles)))
+	lines = append(lines, mf.FormatField("总大小", data.TotalSize))
+
+	if len(data.Categories) == 0 {
+		lines = append(lines, "")
+		lines = append(lines, "暂无完成的下载")
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines, mf.FormatSection("按分类"))
+	for _, category := range data.Categories {
+		lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("%s: %d 个，%s（%.0f%%）",
+			category.Label, category.Files, category.Size, category.Percent)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 // FormatFileBrowseCenter 格式化文件浏览中心
 func (mf *MessageFormatter) FormatFileBrowseCenter() string {
 	var lines []string
@@ -830,6 +1029,7 @@ func (mf *MessageFormatter) FormatHelp() string {
 	lines = append(lines, mf.FormatSection("下载命令"))
 	lines = append(lines, mf.FormatListItem("•", "<code>/download</code> - 预览最近24小时文件"))
 	lines = append(lines, mf.FormatListItem("•", "<code>/download 48</code> - 预览最近48小时"))
+	lines = append(lines, mf.FormatListItem("•", "<code>/download yesterday</code> - 预览昨天（整天）"))
 	lines = append(lines, mf.FormatListItem("•", "<code>/download confirm</code> - 直接下载"))
 	lines = append(lines, mf.FormatListItem("•", "<code>/download URL</code> - 从URL下载"))
 	lines = append(lines, "")
@@ -871,8 +1071,11 @@ type TimeRangeDownloadPreviewData struct {
 	MovieCount      int
 	TVCount         int
 	OtherCount      int
+	MusicCount      int
+	DocumentCount   int
 	ExampleFiles    []ExampleFileData
 	ConfirmCommand  string
+	VideoOnly       bool
 	EscapeHTML      func(string) string
 }
 
@@ -881,6 +1084,22 @@ type ExampleFileData struct {
 	DownloadPath string
 }
 
+// FileScopeLabel describes which files a time range download includes
+func FileScopeLabel(videoOnly bool) string {
+	if videoOnly {
+		return "仅视频（加 " + AllFilesFlag + " 包含所有文件）"
+	}
+	return "所有文件"
+}
+
+// VideoOnlyConflictWarning 包含所有文件（--all）但 download.video_only 只接受视频时的提示，无冲突时返回空字符串
+func VideoOnlyConflictWarning(videoOnly, serviceVideoOnly bool) string {
+	if videoOnly || !serviceVideoOnly {
+		return ""
+	}
+	return "⚠️ 已开启 download.video_only，非视频文件会被拒绝下载"
+}
+
 func (mf *MessageFormatter) FormatTimeRangeDownloadPreview(data TimeRangeDownloadPreviewData) string {
 	var lines []string
 
@@ -893,6 +1112,7 @@ func (mf *MessageFormatter) FormatTimeRangeDownloadPreview(data TimeRangeDownloa
 
 	formattedPath := mf.formatLongPath(data.Path)
 	lines = append(lines, mf.FormatFieldCodeWithWrap("路径", data.EscapeHTML(formattedPath)))
+	lines = append(lines, mf.FormatField("文件类型", FileScopeLabel(data.VideoOnly)))
 	lines = append(lines, "")
 
 	// 文件统计
@@ -902,6 +1122,7 @@ func (mf *MessageFormatter) FormatTimeRangeDownloadPreview(data TimeRangeDownloa
 	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("电影: %d 个", data.MovieCount)))
 	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("剧集: %d 个", data.TVCount)))
 	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("其他: %d 个", data.OtherCount)))
+	lines = append(lines, mf.FormatExtraCategoryCounts(data.MusicCount, data.DocumentCount)...)
 
 	// 示例文件 - 使用智能换行
 	if len(data.ExampleFiles) > 0 {
@@ -936,6 +1157,8 @@ type TimeRangeDownloadResultData struct {
 	MovieCount      int
 	TVCount         int
 	OtherCount      int
+	MusicCount      int
+	DocumentCount   int
 	SuccessCount    int
 	FailCount       int
 	EscapeHTML      func(string) string
@@ -970,6 +1193,7 @@ func (mf *MessageFormatter) FormatTimeRangeDownloadResult(data TimeRangeDownload
 	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("电影: %d 个", data.MovieCount)))
 	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("剧集: %d 个", data.TVCount)))
 	lines = append(lines, mf.FormatListItem("•", fmt.Sprintf("其他: %d 个", data.OtherCount)))
+	lines = append(lines, mf.FormatExtraCategoryCounts(data.MusicCount, data.DocumentCount)...)
 	lines = append(lines, "")
 
 	// 下载结果
@@ -1090,6 +1314,18 @@ func (mf *MessageFormatter) FormatBatchDownloadResult2(data BatchDownloadResult2
 }
 
 // FormatSimpleSystemStatus 格式化简单系统状态
+// FormatAria2Status converts the aria2 status from GetSystemStatus into a display label
+func (mf *MessageFormatter) FormatAria2Status(status string) string {
+	switch status {
+	case "online":
+		return "✅ 已连接"
+	case "offline":
+		return "❌ aria2 当前不可用"
+	default:
+		return status
+	}
+}
+
 type SimpleSystemStatusData struct {
 	TelegramStatus string
 	Aria2Status    string
@@ -1175,9 +1411,12 @@ func (mf *MessageFormatter) FormatAlistConnectionResult(data AlistConnectionData
 
 // FormatDownloadCreated 格式化下载创建成功消息
 type DownloadCreatedData struct {
-	URL      string
-	GID      string
-	Filename string
+	URL        string
+	GID        string
+	Filename   string
+	SpeedLimit string // 单任务限速，如 "2M"，为空表示不限速
+	Directory  string // 用户指定的下载目录，为空表示自动分类
+	EscapeHTML func(string) string
 }
 
 func (mf *MessageFormatter) FormatDownloadCreated(data DownloadCreatedData) string {
@@ -1196,6 +1435,13 @@ func (mf *MessageFormatter) FormatDownloadCreated(data DownloadCreatedData) stri
 	wrappedFilename := mf.wrapLongText(data.Filename, mf.maxWidth)
 	lines = append(lines, mf.FormatFieldCodeWithWrap("文件名", wrappedFilename))
 
+	if data.SpeedLimit != "" {
+		lines = append(lines, mf.FormatField("限速", data.SpeedLimit+"/s"))
+	}
+	if data.Directory != "" {
+		lines = append(lines, mf.FormatFieldCodeWithWrap("目录", data.EscapeHTML(mf.wrapLongText(data.Directory, mf.maxWidth))))
+	}
+
 	message := strings.Join(lines, "\n")
 	return message
 }
@@ -1238,7 +1484,14 @@ func (mf *MessageFormatter) FormatFileDownloadSuccess(data FileDownloadSuccessDa
 }
 
 // FormatError 格式化错误消息
+// aria2 连接不可用或密钥错误时显示明确提示，而不是原始的连接错误
 func (mf *MessageFormatter) FormatError(action string, err error) string {
+	if errors.Is(err, contracts.ErrAria2Unavailable) {
+		return fmt.Sprintf("❌ %s失败: aria2 当前不可用，请检查 aria2 服务是否运行", action)
+	}
+	if errors.Is(err, contracts.ErrAria2Unauthorized) {
+		return fmt.Sprintf("❌ %s失败: aria2 RPC 密钥错误，请检查 aria2.token 配置", action)
+	}
 	return fmt.Sprintf("❌ %s失败: %v", action, err)
 }
 
@@ -8,6 +8,8 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
 	"github.com/easayliu/alist-aria2-download/internal/infrastructure/telegram"
 	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
 	"github.com/easayliu/alist-aria2-download/pkg/logger"
@@ -19,16 +21,42 @@ import (
 type MessageUtils struct {
 	telegramClient *telegram.Client
 	formatter      *MessageFormatter
+	sendQueue      *SendQueue
+	autoDelete     config.AutoDeleteConfig
+	shortcuts      []config.ShortcutConfig
 }
 
-// NewMessageUtils creates message utility instance
-func NewMessageUtils(telegramClient *telegram.Client) *MessageUtils {
+// NewMessageUtils creates message utility instance.
+// All sends and edits go through a rate-limited queue to respect Telegram limits.
+func NewMessageUtils(telegramClient *telegram.Client, sendRate config.SendRateConfig, autoDelete config.AutoDeleteConfig) *MessageUtils {
 	return &MessageUtils{
 		telegramClient: telegramClient,
 		formatter:      NewMessageFormatter(),
+		sendQueue:      NewSendQueue(sendRate),
+		autoDelete:     autoDelete,
 	}
 }
 
+// autoDeleteSeconds returns the configured delete delay for a message lifetime, 0 keeps the message
+func (mu *MessageUtils) autoDeleteSeconds(lifetime types.MessageLifetime) int {
+	if lifetime == types.MessageImportant {
+		return mu.autoDelete.ImportantSeconds
+	}
+	return mu.autoDelete.TransientSeconds
+}
+
+// withDeleteHint appends the "deleted in N seconds" hint when enabled
+func (mu *MessageUtils) withDeleteHint(text string, seconds int, html bool) string {
+	if !mu.autoDelete.ShowHint || seconds <= 0 {
+		return text
+	}
+	hint := fmt.Sprintf("（%d 秒后自动删除）", seconds)
+	if html {
+		hint = "<i>" + hint + "</i>"
+	}
+	return text + "\n\n" + hint
+}
+
 // GetFormatter gets message formatter - returns interface{} to avoid circular import
 func (mu *MessageUtils) GetFormatter() interface{} {
 	return mu.formatter
@@ -39,19 +67,28 @@ func (mu *MessageUtils) SendMessage(chatID int64, text string) {
 	if mu.telegramClient != nil {
 		messages := mu.SplitMessage(text, 4000) // 留一些余量
 		for _, msg := range messages {
-			if err := mu.telegramClient.SendMessage(chatID, msg); err != nil {
+			if err := mu.sendQueue.Do(chatID, func() error {
+				return mu.telegramClient.SendMessage(chatID, msg)
+			}); err != nil {
 				logger.Error("Failed to send telegram message", "chatID", chatID, "textLength", len(msg), "error", err)
 			}
 		}
 	}
 }
 
-// SendMessageWithAutoDelete sends basic message with auto deletion
-func (mu *MessageUtils) SendMessageWithAutoDelete(chatID int64, text string, deleteAfterSeconds int) {
+// SendMessageWithAutoDelete sends basic message that is deleted after the delay configured for its lifetime
+func (mu *MessageUtils) SendMessageWithAutoDelete(chatID int64, text string, lifetime types.MessageLifetime) {
+	deleteAfterSeconds := mu.autoDeleteSeconds(lifetime)
+	if deleteAfterSeconds <= 0 {
+		mu.SendMessage(chatID, text)
+		return
+	}
 	if mu.telegramClient != nil {
-		messages := mu.SplitMessage(text, 4000) // 留一些余量
+		messages := mu.SplitMessage(mu.withDeleteHint(text, deleteAfterSeconds, false), 4000) // 留一些余量
 		for _, msg := range messages {
-			if err := mu.telegramClient.SendMessageWithAutoDelete(chatID, msg, "", deleteAfterSeconds); err != nil {
+			if err := mu.sendQueue.Do(chatID, func() error {
+				return mu.telegramClient.SendMessageWithAutoDelete(chatID, msg, "", deleteAfterSeconds)
+			}); err != nil {
 				logger.Error("Failed to send telegram message with auto delete", "chatID", chatID, "deleteAfter", deleteAfterSeconds, "error", err)
 			}
 		}
@@ -63,19 +100,28 @@ func (mu *MessageUtils) SendMessageHTML(chatID int64, text string) {
 	if mu.telegramClient != nil {
 		messages := mu.SplitMessage(text, 4000) // 留一些余量
 		for _, msg := range messages {
-			if err := mu.telegramClient.SendMessageWithParseMode(chatID, msg, "HTML"); err != nil {
+			if err := mu.sendQueue.Do(chatID, func() error {
+				return mu.telegramClient.SendMessageWithParseMode(chatID, msg, "HTML")
+			}); err != nil {
 				logger.Error("Failed to send telegram HTML message", "chatID", chatID, "textLength", len(msg), "error", err)
 			}
 		}
 	}
 }
 
-// SendMessageHTMLWithAutoDelete sends HTML formatted message with auto deletion
-func (mu *MessageUtils) SendMessageHTMLWithAutoDelete(chatID int64, text string, deleteAfterSeconds int) {
+// SendMessageHTMLWithAutoDelete sends HTML formatted message that is deleted after the delay configured for its lifetime
+func (mu *MessageUtils) SendMessageHTMLWithAutoDelete(chatID int64, text string, lifetime types.MessageLifetime) {
+	deleteAfterSeconds := mu.autoDeleteSeconds(lifetime)
+	if deleteAfterSeconds <= 0 {
+		mu.SendMessageHTML(chatID, text)
+		return
+	}
 	if mu.telegramClient != nil {
-		messages := mu.SplitMessage(text, 4000) // 留一些余量
+		messages := mu.SplitMessage(mu.withDeleteHint(text, deleteAfterSeconds, true), 4000) // 留一些余量
 		for _, msg := range messages {
-			if err := mu.telegramClient.SendMessageWithAutoDelete(chatID, msg, "HTML", deleteAfterSeconds); err != nil {
+			if err := mu.sendQueue.Do(chatID, func() error {
+				return mu.telegramClient.SendMessageWithAutoDelete(chatID, msg, "HTML", deleteAfterSeconds)
+			}); err != nil {
 				logger.Error("Failed to send telegram HTML message with auto delete", "chatID", chatID, "deleteAfter", deleteAfterSeconds, "error", err)
 			}
 		}
@@ -85,7 +131,9 @@ func (mu *MessageUtils) SendMessageHTMLWithAutoDelete(chatID int64, text string,
 // SendMessageMarkdown sends Markdown formatted message
 func (mu *MessageUtils) SendMessageMarkdown(chatID int64, text string) {
 	if mu.telegramClient != nil {
-		if err := mu.telegramClient.SendMessageWithParseMode(chatID, text, "Markdown"); err != nil {
+		if err := mu.sendQueue.Do(chatID, func() error {
+			return mu.telegramClient.SendMessageWithParseMode(chatID, text, "Markdown")
+		}); err != nil {
 			logger.Error("Failed to send telegram markdown message", "chatID", chatID, "textLength", len(text), "error", err)
 		}
 	}
@@ -101,7 +149,12 @@ func (mu *MessageUtils) SendMessageWithKeyboard(chatID int64, text, parseMode st
 			if i == len(messages)-1 {
 				kb = keyboard
 			}
-			if msgID, err := mu.telegramClient.SendMessageWithKeyboard(chatID, msg, parseMode, kb); err != nil {
+			var msgID int
+			err := mu.sendQueue.Do(chatID, func() (err error) {
+				msgID, err = mu.telegramClient.SendMessageWithKeyboard(chatID, msg, parseMode, kb)
+				return err
+			})
+			if err != nil {
 				logger.Error("Failed to send telegram message with keyboard", "chatID", chatID, "parseMode", parseMode, "error", err)
 			} else {
 				lastMessageID = msgID
@@ -112,12 +165,67 @@ func (mu *MessageUtils) SendMessageWithKeyboard(chatID int64, text, parseMode st
 	return 0
 }
 
+// SendForceReply sends an HTML prompt that opens the reply box on the client
+func (mu *MessageUtils) SendForceReply(chatID int64, text, placeholder string) int {
+	if mu.telegramClient == nil {
+		return 0
+	}
+	var msgID int
+	err := mu.sendQueue.Do(chatID, func() (err error) {
+		msgID, err = mu.telegramClient.SendMessageWithForceReply(chatID, text, placeholder)
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed to send telegram force reply", "chatID", chatID, "error", err)
+		return 0
+	}
+	return msgID
+}
+
+// SendPhoto sends an in-memory image with an optional HTML caption
+func (mu *MessageUtils) SendPhoto(chatID int64, fileName string, data []byte, caption string) int {
+	if mu.telegramClient == nil {
+		return 0
+	}
+	var msgID int
+	err := mu.sendQueue.Do(chatID, func() (err error) {
+		msgID, err = mu.telegramClient.SendPhoto(chatID, fileName, data, caption)
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed to send telegram photo", "chatID", chatID, "size", len(data), "error", err)
+		return 0
+	}
+	return msgID
+}
+
+// SendDocument sends an in-memory file with an optional HTML caption
+func (mu *MessageUtils) SendDocument(chatID int64, fileName string, data []byte, caption string) int {
+	if mu.telegramClient == nil {
+		return 0
+	}
+	var msgID int
+	err := mu.sendQueue.Do(chatID, func() (err error) {
+		msgID, err = mu.telegramClient.SendDocument(chatID, fileName, data, caption)
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed to send telegram document", "chatID", chatID, "fileName", fileName, "size", len(data), "error", err)
+		return 0
+	}
+	return msgID
+}
+
 // SendMessageWithReplyKeyboard sends message with reply keyboard
 func (mu *MessageUtils) SendMessageWithReplyKeyboard(chatID int64, text string) {
 	if mu.telegramClient != nil && mu.telegramClient.GetBot() != nil {
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ReplyMarkup = mu.GetDefaultReplyKeyboard()
-		if _, err := mu.telegramClient.GetBot().Send(msg); err != nil {
+		err := mu.sendQueue.Do(chatID, func() error {
+			_, err := mu.telegramClient.GetBot().Send(msg)
+			return err
+		})
+		if err != nil {
 			logger.Error("Failed to send telegram message with reply keyboard", "chatID", chatID, "error", err)
 		}
 	}
@@ -142,7 +250,7 @@ func (mu *MessageUtils) EditMessageWithKeyboard(chatID int64, messageID int, tex
 			}
 		}
 
-		text = text[:cutPos]
+		text = strutil.TruncateBytes(text, cutPos)
 
 		if parseMode == "HTML" {
 			openTags := []string{}
@@ -185,7 +293,11 @@ func (mu *MessageUtils) EditMessageWithKeyboard(chatID int64, messageID int, tex
 		editMsg.ReplyMarkup = keyboard
 	}
 
-	if _, err := mu.telegramClient.GetBot().Send(editMsg); err != nil {
+	err := mu.sendQueue.Do(chatID, func() error {
+		_, err := mu.telegramClient.GetBot().Send(editMsg)
+		return err
+	})
+	if err != nil {
 		logger.Error("Failed to edit telegram message", "chatID", chatID, "messageID", messageID, "parseMode", parseMode, "error", err)
 		return false
 	}
@@ -220,8 +332,9 @@ func (mu *MessageUtils) DeleteMessage(chatID int64, messageID int) {
 	}
 }
 
-// DeleteMessageAfterDelay deletes message after specified seconds
-func (mu *MessageUtils) DeleteMessageAfterDelay(chatID int64, messageID int, delaySeconds int) {
+// DeleteMessageAfterDelay deletes a sent or edited message after the delay configured for its lifetime
+func (mu *MessageUtils) DeleteMessageAfterDelay(chatID int64, messageID int, lifetime types.MessageLifetime) {
+	delaySeconds := mu.autoDeleteSeconds(lifetime)
 	if mu.telegramClient == nil || mu.telegramClient.GetBot() == nil || delaySeconds <= 0 {
 		return
 	}
@@ -281,8 +394,27 @@ func (mu *MessageUtils) FormatFileSize(size int64) string {
 	return strutil.FormatFileSize(size)
 }
 
-// GetDefaultReplyKeyboard gets default reply keyboard
+// SetShortcuts replaces the built-in reply keyboard buttons with custom shortcuts (empty restores the defaults)
+func (mu *MessageUtils) SetShortcuts(shortcuts []config.ShortcutConfig) {
+	mu.shortcuts = shortcuts
+}
+
+// GetDefaultReplyKeyboard gets default reply keyboard, or the custom shortcuts when configured
 func (mu *MessageUtils) GetDefaultReplyKeyboard() tgbotapi.ReplyKeyboardMarkup {
+	if len(mu.shortcuts) > 0 {
+		var rows [][]tgbotapi.KeyboardButton
+		for i := 0; i < len(mu.shortcuts); i += 2 {
+			row := tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButton(mu.shortcuts[i].Label))
+			if i+1 < len(mu.shortcuts) {
+				row = append(row, tgbotapi.NewKeyboardButton(mu.shortcuts[i+1].Label))
+			}
+			rows = append(rows, row)
+		}
+		keyboard := tgbotapi.NewReplyKeyboard(rows...)
+		keyboard.ResizeKeyboard = true
+		return keyboard
+	}
+
 	keyboard := tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton("定时任务"),
@@ -384,6 +516,20 @@ type DirectoryDownloadResultData struct {
 	FailedFiles   []string
 }
 
+// FormatTaskRunSummary formats the download summary sent after a scheduled task run
+func (mu *MessageUtils) FormatTaskRunSummary(summary contracts.TaskRunSummary) string {
+	return mu.formatter.FormatTaskRunSummary(TaskRunSummaryData{
+		TaskID:       summary.TaskID,
+		TaskName:     mu.EscapeHTML(summary.TaskName),
+		Path:         mu.EscapeHTML(summary.Path),
+		FilesMatched: summary.FilesMatched,
+		SuccessCount: summary.SuccessCount,
+		FailureCount: summary.FailureCount,
+		TotalSize:    mu.FormatFileSize(summary.TotalSize),
+		Duration:     summary.Duration.Round(time.Second).String(),
+	})
+}
+
 // FormatDirectoryDownloadResult formats directory download result message (consistent with /download command)
 func (mu *MessageUtils) FormatDirectoryDownloadResult(data DirectoryDownloadResultData) string {
 	// 使用统一格式化器
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/application/contracts"
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/internal/interfaces/telegram/types"
+)
+
+func TestAutoDeleteLifetime(t *testing.T) {
+	mu := NewMessageUtils(nil, config.SendRateConfig{}, config.AutoDeleteConfig{TransientSeconds: 30, ShowHint: true})
+
+	if got := mu.autoDeleteSeconds(types.MessageTransient); got != 30 {
+		t.Errorf("transient delay = %d, want 30", got)
+	}
+	if got := mu.autoDeleteSeconds(types.MessageImportant); got != 0 {
+		t.Errorf("important delay = %d, want 0 (kept)", got)
+	}
+
+	tests := []struct {
+		name    string
+		seconds int
+		html    bool
+		want    string
+	}{
+		{"plain", 30, false, "done\n\n（30 秒后自动删除）"},
+		{"html", 30, true, "done\n\n<i>（30 秒后自动删除）</i>"},
+		{"kept message", 0, true, "done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mu.withDeleteHint("done", tt.seconds, tt.html); got != tt.want {
+				t.Errorf("withDeleteHint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTaskRunSummary(t *testing.T) {
+	mu := NewMessageUtils(nil, config.SendRateConfig{}, config.AutoDeleteConfig{})
+
+	got := mu.FormatTaskRunSummary(contracts.TaskRunSummary{
+		TaskID:       "abc",
+		TaskName:     "<每日>",
+		Path:         "/tvs",
+		FilesMatched: 5,
+		SuccessCount: 3,
+		FailureCount: 2,
+		TotalSize:    2048,
+		Duration:     90 * time.Second,
+	})
+
+	for _, want := range []string{
+		"<b>任务:</b> &lt;每日&gt;",
+		"<b>路径:</b> <code>/tvs</code>",
+		"<b>任务ID:</b> <code>abc</code>",
+		"匹配文件: 5 个",
+		"成功: 3",
+		"失败: 2",
+		"耗时: 1m30s",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatTaskRunSummary() missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "视频文件") {
+		t.Errorf("FormatTaskRunSummary() should not report matched files as video files:\n%s", got)
+	}
+}
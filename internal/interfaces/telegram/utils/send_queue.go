@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/easayliu/alist-aria2-download/pkg/logger"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/time/rate"
+)
+
+// chatQueueSize is the number of pending sends buffered per chat
+const chatQueueSize = 256
+
+// sendJob is a single queued Telegram API call
+type sendJob struct {
+	send func() error
+	done chan error
+}
+
+// chatQueue serializes sends to one chat so message order is preserved
+type chatQueue struct {
+	jobs    chan sendJob
+	limiter *rate.Limiter
+}
+
+// SendQueue rate-limits outgoing Telegram calls with a global token bucket
+// and one token bucket per chat, retrying on 429 as told by Retry-After.
+type SendQueue struct {
+	global       *rate.Limiter
+	perChatLimit rate.Limit
+	perChatBurst int
+	maxRetries   int
+
+	mu    sync.Mutex
+	chats map[int64]*chatQueue
+}
+
+// NewSendQueue creates a send queue from config, zero values mean unlimited
+func NewSendQueue(cfg config.SendRateConfig) *SendQueue {
+	q := &SendQueue{
+		global:       rate.NewLimiter(rate.Inf, 1),
+		perChatLimit: rate.Inf,
+		perChatBurst: 1,
+		maxRetries:   cfg.MaxRetries,
+		chats:        make(map[int64]*chatQueue),
+	}
+	if cfg.GlobalPerSecond > 0 {
+		q.global = rate.NewLimiter(rate.Limit(cfg.GlobalPerSecond), cfg.GlobalPerSecond)
+	}
+	if cfg.PerChatPerSecond > 0 {
+		q.perChatLimit = rate.Limit(cfg.PerChatPerSecond)
+		q.perChatBurst = max(cfg.PerChatBurst, 1)
+	}
+	return q
+}
+
+// Do enqueues send for chatID and blocks until it has been executed
+func (q *SendQueue) Do(chatID int64, send func() error) error {
+	job := sendJob{send: send, done: make(chan error, 1)}
+	q.chat(chatID).jobs <- job
+	return <-job.done
+}
+
+// chat returns the queue for chatID, starting its worker on first use
+func (q *SendQueue) chat(chatID int64) *chatQueue {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	cq, ok := q.chats[chatID]
+	if !ok {
+		cq = &chatQueue{
+			jobs:    make(chan sendJob, chatQueueSize),
+			limiter: rate.NewLimiter(q.perChatLimit, q.perChatBurst),
+		}
+		q.chats[chatID] = cq
+		go q.run(chatID, cq)
+	}
+	return cq
+}
+
+// run executes queued sends for one chat in FIFO order
+func (q *SendQueue) run(chatID int64, cq *chatQueue) {
+	ctx := context.Background()
+	for job := range cq.jobs {
+		_ = cq.limiter.Wait(ctx)
+		_ = q.global.Wait(ctx)
+		job.done <- q.sendWithRetry(chatID, job.send)
+	}
+}
+
+// sendWithRetry runs send, waiting Retry-After and retrying on 429
+func (q *SendQueue) sendWithRetry(chatID int64, send func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := send()
+		retryAfter := retryAfterFromError(err)
+		if retryAfter <= 0 || attempt >= q.maxRetries {
+			return err
+		}
+		logger.Warn("Telegram rate limited, retrying", "chatID", chatID, "retryAfter", retryAfter, "attempt", attempt+1)
+		time.Sleep(retryAfter)
+	}
+}
+
+// retryAfterFromError extracts Retry-After from a Telegram 429 error
+func retryAfterFromError(err error) time.Duration {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.RetryAfter <= 0 {
+		return 0
+	}
+	return time.Duration(tgErr.RetryAfter) * time.Second
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestRetryAfterFromError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected time.Duration
+	}{
+		{
+			name:     "无错误",
+			err:      nil,
+			expected: 0,
+		},
+		{
+			name:     "普通错误",
+			err:      errors.New("network error"),
+			expected: 0,
+		},
+		{
+			name: "包装后的429错误",
+			err: fmt.Errorf("failed to send telegram message: %w", &tgbotapi.Error{
+				Code:               429,
+				Message:            "Too Many Requests: retry after 5",
+				ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 5},
+			}),
+			expected: 5 * time.Second,
+		},
+		{
+			name:     "非429的Telegram错误",
+			err:      &tgbotapi.Error{Code: 400, Message: "Bad Request"},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfterFromError(tt.err); got != tt.expected {
+				t.Errorf("retryAfterFromError() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/easayliu/alist-aria2-download/internal/infrastructure/config"
+	"github.com/gin-gonic/gin"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// recordingMessageHandler 记录被分发的消息更新
+type recordingMessageHandler struct {
+	updates []*tgbotapi.Update
+}
+
+func (h *recordingMessageHandler) HandleMessage(update *tgbotapi.Update) {
+	h.updates = append(h.updates, update)
+}
+
+// TestWebhookSecretToken 测试配置 secret_token 后拒绝请求头不匹配的 webhook 请求
+func TestWebhookSecretToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const messageUpdate = `{"update_id":1,"message":{"message_id":1,"chat":{"id":1,"type":"private"},"text":"/help"}}`
+
+	tests := []struct {
+		name      string
+		secret    string
+		header    string
+		want      int
+		wantCalls int
+	}{
+		{name: "wrong token", secret: "s3cret", header: "guess", want: http.StatusForbidden, wantCalls: 0},
+		{name: "missing token", secret: "s3cret", want: http.StatusForbidden, wantCalls: 0},
+		{name: "matching token", secret: "s3cret", header: "s3cret", want: http.StatusOK, wantCalls: 1},
+		{name: "no secret configured", want: http.StatusOK, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Telegram.Enabled = true
+			cfg.Telegram.Webhook.SecretToken = tt.secret
+			handler := &recordingMessageHandler{}
+			c := &TelegramController{config: cfg, messageHandler: handler}
+
+			router := gin.New()
+			router.POST("/telegram/webhook", c.Webhook)
+
+			req := httptest.NewRequest(http.MethodPost, "/telegram/webhook", strings.NewReader(messageUpdate))
+			if tt.header != "" {
+				req.Header.Set(webhookSecretHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if len(handler.updates) != tt.wantCalls {
+				t.Errorf("dispatched %d message updates, want %d", len(handler.updates), tt.wantCalls)
+			}
+		})
+	}
+}
@@ -1,13 +1,18 @@
 package httpclient
 
 import (
+	"bufio"
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -59,6 +64,16 @@ func (o *Options) WithClient(client *http.Client) *Options {
 	return o
 }
 
+// StatusError HTTP状态码非2xx时返回的错误
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
 // DoJSONRequest 执行JSON请求，统一处理JSON编码/解码和HTTP请求
 func DoJSONRequest(method, url string, reqBody, respBody interface{}, opts ...*Options) error {
 	// 获取选项
@@ -98,6 +113,9 @@ func DoJSONRequest(method, url string, reqBody, respBody interface{}, opts ...*O
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	// 声明支持压缩响应，由 decodeBody 负责解压（显式设置后 Transport 不再自动解压）
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+
 	// 设置自定义头部
 	for key, value := range options.Headers {
 		req.Header.Set(key, value)
@@ -111,14 +129,18 @@ func DoJSONRequest(method, url string, reqBody, respBody interface{}, opts ...*O
 	defer resp.Body.Close()
 
 	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	bodyReader, err := decodeBody(resp)
+	if err != nil {
+		return fmt.Errorf("failed to decode response body: %w", err)
+	}
+	body, err := io.ReadAll(bodyReader)
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// 检查HTTP状态码
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("HTTP request failed with status %d: %s", resp.StatusCode, string(body))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// 解析响应体
@@ -131,6 +153,32 @@ func DoJSONRequest(method, url string, reqBody, respBody interface{}, opts ...*O
 	return nil
 }
 
+// decodeBody 根据 Content-Encoding 返回解压后的响应体读取器（支持 gzip/deflate）
+func decodeBody(resp *http.Response) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip":
+		reader, err := gzip.NewReader(resp.Body)
+		if err == io.EOF {
+			return bytes.NewReader(nil), nil // 空响应体
+		}
+		return reader, err
+	case "deflate":
+		// 标准 deflate 为 zlib 封装，部分服务器发送裸 deflate 数据，按头部区分
+		br := bufio.NewReader(resp.Body)
+		if header, err := br.Peek(2); err == nil && isZlibHeader(header[0], header[1]) {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	default:
+		return resp.Body, nil
+	}
+}
+
+// isZlibHeader 判断是否为 zlib 头（CM=8 且 CMF/FLG 校验通过）
+func isZlibHeader(cmf, flg byte) bool {
+	return cmf&0x0f == 8 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
+
 // PostJSON 发送POST JSON请求的便捷方法
 func PostJSON(url string, reqBody, respBody interface{}, opts ...*Options) error {
 	return DoJSONRequest("POST", url, reqBody, respBody, opts...)
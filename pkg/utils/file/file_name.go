@@ -0,0 +1,46 @@
+package fileutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// maxFileNameBytes 常见文件系统的文件名长度上限（字节）
+const maxFileNameBytes = 255
+
+// invalidFileNameChars 在 Windows/Samba 共享或 aria2 中不能出现在文件名里的字符
+const invalidFileNameChars = `/\:*?"<>|`
+
+// SanitizeFileName 清理用户输入的文件名：替换非法字符、去除首尾空白和点号
+// 用户省略扩展名时沿用原文件的扩展名（originalName 为空时不补全）
+// 例如：
+//
+//	("流浪地球 2", "tlde2.2160p.mkv") -> "流浪地球 2.mkv"
+//	("a/b:c.MKV", "x.mkv")            -> "a_b_c.MKV"
+func SanitizeFileName(name, originalName string) (string, error) {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		if strings.ContainsRune(invalidFileNameChars, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return "", fmt.Errorf("file name is empty")
+	}
+
+	ext := filepath.Ext(originalName)
+	if ext != "" && !strings.EqualFold(filepath.Ext(name), ext) {
+		name += ext
+	}
+
+	if len(name) > maxFileNameBytes {
+		return "", fmt.Errorf("file name too long: %d bytes (max %d)", len(name), maxFileNameBytes)
+	}
+	return name, nil
+}
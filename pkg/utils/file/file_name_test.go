@@ -0,0 +1,37 @@
+package fileutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		original string
+		want     string
+		wantErr  bool
+	}{
+		{name: "补全扩展名", input: "流浪地球 2", original: "tlde2.2160p.mkv", want: "流浪地球 2.mkv"},
+		{name: "保留相同扩展名", input: "Movie.2023.MKV", original: "x.mkv", want: "Movie.2023.MKV"},
+		{name: "不同扩展名时追加原扩展名", input: "Movie.mp4", original: "x.mkv", want: "Movie.mp4.mkv"},
+		{name: "替换非法字符", input: "a/b:c?.mkv", original: "x.mkv", want: "a_b_c_.mkv"},
+		{name: "去除首尾空白和点号", input: "  ..name.. ", original: "x.mkv", want: "name.mkv"},
+		{name: "原文件无扩展名", input: "name", original: "README", want: "name"},
+		{name: "空文件名", input: " . ", original: "x.mkv", wantErr: true},
+		{name: "文件名过长", input: strings.Repeat("长", 100), original: "x.mkv", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SanitizeFileName(tt.input, tt.original)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SanitizeFileName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SanitizeFileName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
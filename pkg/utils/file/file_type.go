@@ -40,6 +40,20 @@ func IsVideoFile(filename string, videoExts ...[]string) bool {
 	return false
 }
 
+// HasExtension 检查文件扩展名是否在给定列表中（不区分大小写）
+func HasExtension(filename string, exts []string) bool {
+	ext := ExtractExtension(filename)
+	if ext == "" {
+		return false
+	}
+	for _, e := range exts {
+		if strings.EqualFold(ext, strings.TrimPrefix(e, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
 // ExtractExtension 从文件名中提取扩展名（不带点号，小写）
 // 例如：
 //
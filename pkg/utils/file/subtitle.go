@@ -0,0 +1,41 @@
+package fileutil
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SubtitleExtensions 随视频一起下载的字幕扩展名
+var SubtitleExtensions = []string{"srt", "ass", "ssa", "vtt", "sub", "idx", "sup"}
+
+// subtitleTagPattern 字幕文件名中视频基础名和扩展名之间的语言标记，例如 en、zh-CN、chs、简体、en.forced
+var subtitleTagPattern = regexp.MustCompile(`(?i)^\p{L}{2,8}([-_][\p{L}\d]{2,4})?(\.(forced|sdh|cc|default))?$`)
+
+// MatchSubtitle 判断 candidate 是否为视频 videoName 的字幕文件，返回视频基础名之后的部分
+// 基础名需一致（不区分大小写），中间允许一段语言标记，例如：
+//
+//	"Movie.mkv", "Movie.srt"       -> ".srt"
+//	"Movie.mkv", "Movie.en.srt"    -> ".en.srt"
+//	"Movie.mkv", "Movie.zh-CN.ass" -> ".zh-CN.ass"
+func MatchSubtitle(videoName, candidate string) (string, bool) {
+	videoName, candidate = path.Base(videoName), path.Base(candidate)
+	if !HasExtension(candidate, SubtitleExtensions) {
+		return "", false
+	}
+
+	base := strings.TrimSuffix(videoName, path.Ext(videoName))
+	if base == "" || len(candidate) <= len(base) || !strings.EqualFold(candidate[:len(base)], base) {
+		return "", false
+	}
+	suffix := candidate[len(base):]
+	if !strings.HasPrefix(suffix, ".") {
+		return "", false
+	}
+
+	tag := strings.TrimSuffix(suffix[1:], path.Ext(candidate))
+	if tag != "" && !subtitleTagPattern.MatchString(tag) {
+		return "", false
+	}
+	return suffix, true
+}
@@ -0,0 +1,35 @@
+package fileutil
+
+import "testing"
+
+func TestMatchSubtitle(t *testing.T) {
+	tests := []struct {
+		name       string
+		video      string
+		candidate  string
+		wantSuffix string
+		wantOK     bool
+	}{
+		{name: "同名字幕", video: "Movie.2023.mkv", candidate: "Movie.2023.srt", wantSuffix: ".srt", wantOK: true},
+		{name: "语言后缀", video: "Movie.mkv", candidate: "Movie.en.srt", wantSuffix: ".en.srt", wantOK: true},
+		{name: "地区语言后缀", video: "Movie.mkv", candidate: "Movie.zh-CN.ass", wantSuffix: ".zh-CN.ass", wantOK: true},
+		{name: "中文语言标记", video: "流浪地球.mp4", candidate: "流浪地球.简体.srt", wantSuffix: ".简体.srt", wantOK: true},
+		{name: "强制字幕", video: "Movie.mkv", candidate: "Movie.en.forced.srt", wantSuffix: ".en.forced.srt", wantOK: true},
+		{name: "不区分大小写", video: "movie.mkv", candidate: "MOVIE.SRT", wantSuffix: ".SRT", wantOK: true},
+		{name: "完整路径", video: "/tv/Show.S01E01.mkv", candidate: "/tv/Show.S01E01.chs.ass", wantSuffix: ".chs.ass", wantOK: true},
+		{name: "其他集的字幕", video: "Show.S01E01.mkv", candidate: "Show.S01E02.srt"},
+		{name: "基础名只是前缀", video: "Movie.mkv", candidate: "Movie2.srt"},
+		{name: "不是语言标记", video: "Movie.mkv", candidate: "Movie.Part2.srt"},
+		{name: "不是字幕", video: "Movie.mkv", candidate: "Movie.nfo"},
+		{name: "视频本身", video: "Movie.mkv", candidate: "Movie.mkv"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suffix, ok := MatchSubtitle(tt.video, tt.candidate)
+			if ok != tt.wantOK || suffix != tt.wantSuffix {
+				t.Errorf("MatchSubtitle(%q, %q) = %q, %v, want %q, %v", tt.video, tt.candidate, suffix, ok, tt.wantSuffix, tt.wantOK)
+			}
+		})
+	}
+}
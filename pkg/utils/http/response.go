@@ -8,9 +8,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Response 统一响应结构：成功时 code 为 0，失败时 code 为HTTP状态码，error 为业务错误码
 type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
+	Error   string      `json:"error,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
@@ -39,6 +41,15 @@ func ErrorWithStatus(c *gin.Context, httpStatus, code int, message string) {
 	})
 }
 
+// ErrorWithCode 带HTTP状态码和业务错误码的错误响应
+func ErrorWithCode(c *gin.Context, httpStatus int, errorCode, message string) {
+	c.JSON(httpStatus, Response{
+		Code:    httpStatus,
+		Message: message,
+		Error:   errorCode,
+	})
+}
+
 // JoinPath 连接路径
 func JoinPath(paths ...string) string {
 	return filepath.Join(paths...)
@@ -0,0 +1,61 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// ErrFFProbeUnavailable 系统中未安装 ffprobe
+var ErrFFProbeUnavailable = errors.New("ffprobe not found in PATH")
+
+// ffprobeOutput ffprobe -print_format json 输出中用到的字段
+type ffprobeOutput struct {
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+	Streams []struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	} `json:"streams"`
+}
+
+// FFProbe 调用 ffprobe 读取 URL 对应文件的时长和分辨率，ffprobe 只请求所需的字节范围
+// authorization 非空时作为 Authorization 请求头；未安装 ffprobe 时返回 ErrFFProbeUnavailable
+func FFProbe(ctx context.Context, url, authorization string) (VideoInfo, error) {
+	bin, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return VideoInfo{}, ErrFFProbeUnavailable
+	}
+
+	args := []string{"-v", "error", "-print_format", "json",
+		"-show_entries", "format=duration:stream=width,height",
+		"-select_streams", "v:0"}
+	if authorization != "" {
+		args = append(args, "-headers", "Authorization: "+authorization+"\r\n")
+	}
+	args = append(args, url)
+
+	out, err := exec.CommandContext(ctx, bin, args...).Output()
+	if err != nil {
+		return VideoInfo{}, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var parsed ffprobeOutput
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		return VideoInfo{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	var info VideoInfo
+	if seconds, err := strconv.ParseFloat(parsed.Format.Duration, 64); err == nil && seconds > 0 {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	if len(parsed.Streams) > 0 {
+		info.Width, info.Height = parsed.Streams[0].Width, parsed.Streams[0].Height
+	}
+	return info, nil
+}
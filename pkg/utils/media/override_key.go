@@ -0,0 +1,48 @@
+package media
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// overrideStopRegex 标题之后常见的季集、年份、分辨率等标记，从第一个标记处截断
+var overrideStopRegex = regexp.MustCompile(`(?i)[\s._\-\[\(【]*(s\d{1,2}(e\d{1,4})?\b|e\d{1,4}\b|ep\d{1,4}|第[0-9一二三四五六七八九十百零]+[集季期话]|(19|20)\d{2}\b|\d{3,4}p\b|4k\b|bluray|web-?dl)`)
+
+// overrideSeparatorReplacer 将常见分隔符统一为空格
+var overrideSeparatorReplacer = strings.NewReplacer(".", " ", "_", " ", "-", " ", "[", " ", "]", " ", "【", " ", "】", " ", "(", " ", ")", " ")
+
+// NormalizeForMatch 规范化文件名用于分类覆盖匹配：去除扩展名、转小写、分隔符统一为单个空格
+func NormalizeForMatch(filename string) string {
+	name := strings.TrimSuffix(filename, path.Ext(filename))
+	name = overrideSeparatorReplacer.Replace(strings.ToLower(name))
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// OverrideKey 从文件名中提取分类覆盖的匹配键和显示标题
+// 取季集/年份/分辨率等标记之前的部分作为标题，使同一部剧/电影的其他文件也能匹配
+// 文件名只有季集等标记、没有标题部分时（如 S01E01.mkv）返回空键，避免匹配所有剧集的同一集
+func OverrideKey(filename string) (key, title string) {
+	name := strings.TrimSuffix(filename, path.Ext(filename))
+	if loc := overrideStopRegex.FindStringIndex(name); loc != nil && loc[0] > 0 {
+		name = name[:loc[0]]
+	}
+	title = strings.Join(strings.Fields(overrideSeparatorReplacer.Replace(name)), " ")
+
+	// 截断后过短时退回完整文件名，避免匹配范围过大
+	if utf8.RuneCountInString(title) < 2 {
+		key, title = NormalizeForMatch(filename), strings.TrimSuffix(filename, path.Ext(filename))
+	} else {
+		key = strings.ToLower(title)
+	}
+	if !hasTitlePart(key) {
+		return "", ""
+	}
+	return key, title
+}
+
+// hasTitlePart 去除季集、年份、分辨率等标记后是否仍有标题内容
+func hasTitlePart(key string) bool {
+	return strings.TrimSpace(overrideStopRegex.ReplaceAllString(key, " ")) != ""
+}
@@ -0,0 +1,31 @@
+package media
+
+import "testing"
+
+func TestOverrideKey(t *testing.T) {
+	tests := []struct {
+		filename  string
+		wantKey   string
+		wantTitle string
+	}{
+		{"The.Last.of.Us.S01E03.1080p.WEB-DL.mkv", "the last of us", "The Last of Us"},
+		{"流浪地球2.2023.2160p.mp4", "流浪地球2", "流浪地球2"},
+		{"[字幕组] 进击的巨人 第05集.mp4", "字幕组 进击的巨人", "字幕组 进击的巨人"},
+		{"A.S01E01.mkv", "a s01e01", "A.S01E01"},
+		{"S01E01.mkv", "", ""},
+		{"E05.1080p.mkv", "", ""},
+		{"第05集.mp4", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			key, title := OverrideKey(tt.filename)
+			if key != tt.wantKey || title != tt.wantTitle {
+				t.Errorf("OverrideKey(%q) = %q, %q, want %q, %q", tt.filename, key, title, tt.wantKey, tt.wantTitle)
+			}
+			if name := NormalizeForMatch(tt.filename); len(name) < len(key) || name[:len(key)] != key {
+				t.Errorf("NormalizeForMatch(%q) = %q, should start with key %q", tt.filename, name, key)
+			}
+		})
+	}
+}
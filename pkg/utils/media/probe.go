@@ -0,0 +1,175 @@
+package media
+
+import (
+	"bytes"
+	"strings"
+)
+
+// ProbeResult 媒体文件片段探测结果
+type ProbeResult struct {
+	Container string   // 容器格式，如 Matroska、MP4、MPEG-TS，无法识别时为空
+	Detail    string   // 容器细节，如 MP4 主品牌、Matroska DocType
+	Codecs    []string // 在片段中识别到的编码（按出现顺序去重）
+}
+
+// matroskaCodecNames Matroska CodecID 对应的显示名称
+var matroskaCodecNames = map[string]string{
+	"V_MPEG4/ISO/AVC":  "H.264",
+	"V_MPEGH/ISO/HEVC": "H.265/HEVC",
+	"V_AV1":            "AV1",
+	"V_VP9":            "VP9",
+	"V_VP8":            "VP8",
+	"V_MPEG2":          "MPEG-2",
+	"A_AAC":            "AAC",
+	"A_AC3":            "AC-3",
+	"A_EAC3":           "E-AC-3",
+	"A_DTS":            "DTS",
+	"A_TRUEHD":         "TrueHD",
+	"A_FLAC":           "FLAC",
+	"A_OPUS":           "Opus",
+	"A_MPEG/L3":        "MP3",
+	"S_TEXT/UTF8":      "SRT字幕",
+	"S_TEXT/ASS":       "ASS字幕",
+	"S_TEXT/SSA":       "SSA字幕",
+	"S_HDMV/PGS":       "PGS字幕",
+	"S_VOBSUB":         "VobSub字幕",
+}
+
+// mp4CodecNames MP4 样本描述（stsd）中的编码标识
+var mp4CodecNames = []struct {
+	fourCC string
+	name   string
+}{
+	{"avc1", "H.264"},
+	{"hvc1", "H.265/HEVC"},
+	{"hev1", "H.265/HEVC"},
+	{"av01", "AV1"},
+	{"vp09", "VP9"},
+	{"mp4a", "AAC"},
+	{"ac-3", "AC-3"},
+	{"ec-3", "E-AC-3"},
+	{"Opus", "Opus"},
+	{"fLaC", "FLAC"},
+}
+
+// Probe 根据文件开头的数据探测容器格式和编码
+// 只依赖魔数和常见结构做启发式识别，片段不完整时编码列表可能为空
+func Probe(data []byte) ProbeResult {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return probeMatroska(data)
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		return probeMP4(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "AVI ":
+		return ProbeResult{Container: "AVI"}
+	case bytes.HasPrefix(data, []byte("FLV")):
+		return ProbeResult{Container: "FLV"}
+	case bytes.HasPrefix(data, []byte(".RMF")):
+		return ProbeResult{Container: "RealMedia"}
+	case bytes.HasPrefix(data, []byte{0x30, 0x26, 0xB2, 0x75}):
+		return ProbeResult{Container: "ASF/WMV"}
+	case bytes.HasPrefix(data, []byte{0x00, 0x00, 0x01, 0xBA}):
+		return ProbeResult{Container: "MPEG-PS"}
+	case hasSyncBytes(data, 0, 188):
+		return ProbeResult{Container: "MPEG-TS"}
+	case hasSyncBytes(data, 4, 192):
+		return ProbeResult{Container: "M2TS"}
+	}
+	return ProbeResult{}
+}
+
+// probeMatroska 解析 Matroska/WebM 的 DocType 并扫描 CodecID 元素
+func probeMatroska(data []byte) ProbeResult {
+	result := ProbeResult{Container: "Matroska"}
+	if idx := bytes.Index(data, []byte{0x42, 0x82}); idx >= 0 {
+		if docType, ok := readEBMLString(data, idx+2); ok {
+			result.Detail = docType
+			if docType == "webm" {
+				result.Container = "WebM"
+			}
+		}
+	}
+
+	// CodecID 元素 ID 为 0x86，内容形如 V_MPEG4/ISO/AVC
+	seen := make(map[string]bool)
+	for i := 0; i < len(data)-2; i++ {
+		if data[i] != 0x86 {
+			continue
+		}
+		codecID, ok := readEBMLString(data, i+1)
+		if !ok || !isMatroskaCodecID(codecID) {
+			continue
+		}
+		name := codecID
+		if display, exists := matroskaCodecNames[codecID]; exists {
+			name = display
+		}
+		if !seen[name] {
+			seen[name] = true
+			result.Codecs = append(result.Codecs, name)
+		}
+	}
+	return result
+}
+
+// readEBMLString 读取单字节长度的 EBML 字符串元素内容
+func readEBMLString(data []byte, sizeOffset int) (string, bool) {
+	if sizeOffset >= len(data) || data[sizeOffset]&0x80 == 0 {
+		return "", false
+	}
+	size := int(data[sizeOffset] & 0x7F)
+	start := sizeOffset + 1
+	if size == 0 || start+size > len(data) {
+		return "", false
+	}
+	return strings.TrimRight(string(data[start:start+size]), "\x00"), true
+}
+
+// isMatroskaCodecID 判断是否为合法的 CodecID（V_/A_/S_ 前缀，仅包含大写字母、数字和分隔符）
+func isMatroskaCodecID(s string) bool {
+	if len(s) < 3 || len(s) > 32 || s[1] != '_' || !strings.ContainsRune("VAS", rune(s[0])) {
+		return false
+	}
+	for _, r := range s[2:] {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("/_.-", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// probeMP4 读取 ftyp 主品牌，并在片段中查找样本描述中的编码标识
+func probeMP4(data []byte) ProbeResult {
+	brand := strings.TrimSpace(string(data[8:12]))
+	result := ProbeResult{Container: "MP4", Detail: brand}
+	if brand == "qt" {
+		result.Container = "MOV"
+	}
+
+	// moov 位于文件末尾时片段中不包含 stsd，无法识别编码
+	if !bytes.Contains(data, []byte("stsd")) {
+		return result
+	}
+	seen := make(map[string]bool)
+	for _, codec := range mp4CodecNames {
+		if !seen[codec.name] && bytes.Contains(data, []byte(codec.fourCC)) {
+			seen[codec.name] = true
+			result.Codecs = append(result.Codecs, codec.name)
+		}
+	}
+	return result
+}
+
+// hasSyncBytes 检查固定包长的传输流同步字节（0x47）是否连续出现
+func hasSyncBytes(data []byte, offset, packetSize int) bool {
+	const packets = 3
+	if len(data) < offset+packetSize*(packets-1)+1 {
+		return false
+	}
+	for i := 0; i < packets; i++ {
+		if data[offset+i*packetSize] != 0x47 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package media
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProbe(t *testing.T) {
+	mkv := []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x82, 0x88}
+	mkv = append(mkv, "matroska"...)
+	mkv = append(mkv, 0x00, 0x86, 0x8F)
+	mkv = append(mkv, "V_MPEG4/ISO/AVC"...)
+	mkv = append(mkv, 0x86, 0x85)
+	mkv = append(mkv, "A_AAC"...)
+
+	mp4 := append([]byte{0x00, 0x00, 0x00, 0x18}, "ftypisom"...)
+	mp4 = append(mp4, "....moov....stsd....hvc1....mp4a"...)
+
+	ts := make([]byte, 188*3)
+	for i := 0; i < 3; i++ {
+		ts[i*188] = 0x47
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want ProbeResult
+	}{
+		{
+			name: "Matroska",
+			data: mkv,
+			want: ProbeResult{Container: "Matroska", Detail: "matroska", Codecs: []string{"H.264", "AAC"}},
+		},
+		{
+			name: "MP4",
+			data: mp4,
+			want: ProbeResult{Container: "MP4", Detail: "isom", Codecs: []string{"H.265/HEVC", "AAC"}},
+		},
+		{
+			name: "MPEG-TS",
+			data: ts,
+			want: ProbeResult{Container: "MPEG-TS"},
+		},
+		{
+			name: "无法识别",
+			data: []byte("hello world"),
+			want: ProbeResult{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Probe(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Probe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
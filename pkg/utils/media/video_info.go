@@ -0,0 +1,335 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// VideoInfo 视频时长和分辨率，无法获取的字段为零值
+type VideoInfo struct {
+	Duration time.Duration
+	Width    int
+	Height   int
+}
+
+// IsZero 是否没有获取到任何信息
+func (v VideoInfo) IsZero() bool {
+	return v.Duration <= 0 && v.Width <= 0 && v.Height <= 0
+}
+
+// Matroska 元素 ID
+const (
+	ebmlSegment       = 0x18538067
+	ebmlInfo          = 0x1549A966
+	ebmlTimecodeScale = 0x2AD7B1
+	ebmlDuration      = 0x4489
+	ebmlTracks        = 0x1654AE6B
+	ebmlTrackEntry    = 0xAE
+	ebmlVideo         = 0xE0
+	ebmlPixelWidth    = 0xB0
+	ebmlPixelHeight   = 0xBA
+	ebmlCluster       = 0x1F43B675
+)
+
+// ProbeVideoInfo 从文件开头的数据解析时长和分辨率（支持 Matroska/WebM 和 MP4/MOV）
+// MP4 的 moov 位于文件末尾时返回零值，可用 NeedsMP4Tail 判断后再用 ProbeMP4Tail 解析文件末尾
+func ProbeVideoInfo(data []byte) VideoInfo {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return probeMatroskaVideoInfo(data)
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		return probeMP4VideoInfo(data)
+	}
+	return VideoInfo{}
+}
+
+// NeedsMP4Tail 判断是否为 moov 不在开头片段中的 MP4 文件
+func NeedsMP4Tail(data []byte) bool {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	found := false
+	walkMP4Boxes(data, func(boxType string, _ []byte) bool {
+		found = boxType == "moov"
+		return !found
+	})
+	return !found
+}
+
+// ProbeMP4Tail 从 MP4 文件末尾的数据中查找 moov 并解析时长和分辨率
+func ProbeMP4Tail(tail []byte) VideoInfo {
+	for end := len(tail); end > 0; {
+		idx := bytes.LastIndex(tail[:end], []byte("moov"))
+		if idx < 4 {
+			break
+		}
+		start := idx - 4
+		size := int(binary.BigEndian.Uint32(tail[start:idx]))
+		// 只接受大小与剩余数据吻合的 box，避免匹配到媒体数据中的同名字节
+		if size >= 8 && start+size <= len(tail) {
+			if info := probeMP4VideoInfo(tail[start : start+size]); !info.IsZero() {
+				return info
+			}
+		}
+		end = idx
+	}
+	return VideoInfo{}
+}
+
+// ResolutionLabel 返回分辨率的常用名称（4K、1080p 等），宽高未知时返回空字符串
+func ResolutionLabel(width, height int) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	switch {
+	case width >= 7600:
+		return "8K"
+	case width >= 3800:
+		return "4K"
+	case width >= 2500:
+		return "1440p"
+	case width >= 1900:
+		return "1080p"
+	case width >= 1200:
+		return "720p"
+	}
+	return fmt.Sprintf("%dp", height)
+}
+
+// probeMatroskaVideoInfo 解析 Segment 下 Info 的时长和第一个视频轨道的像素尺寸，遇到 Cluster 即停止
+func probeMatroskaVideoInfo(data []byte) VideoInfo {
+	var info VideoInfo
+	walkEBML(data, func(id uint64, payload []byte) bool {
+		if id != ebmlSegment {
+			return true
+		}
+		walkEBML(payload, func(id uint64, payload []byte) bool {
+			switch id {
+			case ebmlInfo:
+				info.Duration = matroskaDuration(payload)
+			case ebmlTracks:
+				info.Width, info.Height = matroskaVideoSize(payload)
+			case ebmlCluster:
+				return false
+			}
+			return info.Duration <= 0 || info.Width <= 0
+		})
+		return false
+	})
+	return info
+}
+
+// matroskaDuration 按 TimecodeScale（默认 1ms）换算 Info 中的 Duration
+func matroskaDuration(info []byte) time.Duration {
+	scale := uint64(1_000_000)
+	var duration float64
+	walkEBML(info, func(id uint64, payload []byte) bool {
+		switch id {
+		case ebmlTimecodeScale:
+			if v, ok := ebmlUint(payload); ok && v > 0 {
+				scale = v
+			}
+		case ebmlDuration:
+			duration = ebmlFloat(payload)
+		}
+		return true
+	})
+	if duration <= 0 || math.IsNaN(duration) || math.IsInf(duration, 0) {
+		return 0
+	}
+	return time.Duration(duration * float64(scale))
+}
+
+// matroskaVideoSize 返回第一个包含 Video 元素的轨道的像素宽高
+func matroskaVideoSize(tracks []byte) (width, height int) {
+	walkEBML(tracks, func(id uint64, entry []byte) bool {
+		if id != ebmlTrackEntry {
+			return true
+		}
+		walkEBML(entry, func(id uint64, video []byte) bool {
+			if id != ebmlVideo {
+				return true
+			}
+			walkEBML(video, func(id uint64, payload []byte) bool {
+				v, _ := ebmlUint(payload)
+				switch id {
+				case ebmlPixelWidth:
+					width = int(v)
+				case ebmlPixelHeight:
+					height = int(v)
+				}
+				return true
+			})
+			return false
+		})
+		return width <= 0 || height <= 0
+	})
+	return width, height
+}
+
+// walkEBML 依次回调 data 中的 EBML 元素，回调返回 false 时停止
+// 大小未知或超出数据范围的元素截断到数据末尾，以便片段不完整时仍能解析主元素的已读部分
+func walkEBML(data []byte, fn func(id uint64, payload []byte) bool) {
+	for pos := 0; pos < len(data); {
+		id, idLen := readVint(data[pos:], false)
+		if idLen == 0 {
+			return
+		}
+		size, sizeLen := readVint(data[pos+idLen:], true)
+		if sizeLen == 0 {
+			return
+		}
+		start := pos + idLen + sizeLen
+		end := len(data)
+		if size >= 0 && uint64(size) <= uint64(len(data)-start) {
+			end = start + int(size)
+		}
+		if !fn(uint64(id), data[start:end]) {
+			return
+		}
+		pos = end
+	}
+}
+
+// readVint 读取 EBML 变长整数；stripMarker 为 true 时去掉长度标记位（元素大小），
+// 为 false 时保留（元素 ID）。大小全为 1 表示未知，返回 -1
+func readVint(data []byte, stripMarker bool) (int64, int) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0
+	}
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > len(data) || (!stripMarker && length > 4) {
+		return 0, 0
+	}
+
+	value := uint64(data[0])
+	if stripMarker {
+		value &= uint64(0xFF >> length)
+	}
+	allOnes := value == uint64(0xFF>>length)
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[i])
+		allOnes = allOnes && data[i] == 0xFF
+	}
+	if stripMarker && allOnes {
+		return -1, length
+	}
+	return int64(value), length
+}
+
+// ebmlUint 读取无符号整数元素
+func ebmlUint(payload []byte) (uint64, bool) {
+	if len(payload) == 0 || len(payload) > 8 {
+		return 0, false
+	}
+	var v uint64
+	for _, b := range payload {
+		v = v<<8 | uint64(b)
+	}
+	return v, true
+}
+
+// ebmlFloat 读取 4 或 8 字节的浮点元素
+func ebmlFloat(payload []byte) float64 {
+	switch len(payload) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(payload)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(payload))
+	}
+	return 0
+}
+
+// probeMP4VideoInfo 解析 moov 中 mvhd 的时长和各轨道 tkhd 中最大的画面尺寸（音频轨道尺寸为0）
+func probeMP4VideoInfo(data []byte) VideoInfo {
+	var info VideoInfo
+	walkMP4Boxes(data, func(boxType string, moov []byte) bool {
+		if boxType != "moov" {
+			return true
+		}
+		walkMP4Boxes(moov, func(boxType string, payload []byte) bool {
+			switch boxType {
+			case "mvhd":
+				info.Duration = mp4Duration(payload)
+			case "trak":
+				walkMP4Boxes(payload, func(boxType string, tkhd []byte) bool {
+					if boxType != "tkhd" {
+						return true
+					}
+					if w, h := mp4TrackSize(tkhd); w*h > info.Width*info.Height {
+						info.Width, info.Height = w, h
+					}
+					return false
+				})
+			}
+			return true
+		})
+		return false
+	})
+	return info
+}
+
+// mp4Duration 按 mvhd 的 timescale 换算时长
+func mp4Duration(mvhd []byte) time.Duration {
+	var timescale, duration uint64
+	switch {
+	case len(mvhd) >= 20 && mvhd[0] == 0:
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	case len(mvhd) >= 32 && mvhd[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	}
+	if timescale == 0 || duration == 0 || duration == math.MaxUint32 || duration == math.MaxUint64 {
+		return 0
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+}
+
+// mp4TrackSize 读取 tkhd 末尾的宽高（16.16 定点数）
+func mp4TrackSize(tkhd []byte) (width, height int) {
+	offset := 76
+	if len(tkhd) > 0 && tkhd[0] == 1 {
+		offset = 88
+	}
+	if len(tkhd) < offset+8 {
+		return 0, 0
+	}
+	return int(binary.BigEndian.Uint32(tkhd[offset:]) >> 16), int(binary.BigEndian.Uint32(tkhd[offset+4:]) >> 16)
+}
+
+// walkMP4Boxes 依次回调 data 中的 box，回调返回 false 时停止；超出数据范围的 box 截断到数据末尾
+func walkMP4Boxes(data []byte, fn func(boxType string, payload []byte) bool) {
+	for pos := 0; pos+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[pos:]))
+		boxType := string(data[pos+4 : pos+8])
+		header := 8
+		switch size {
+		case 0:
+			size = uint64(len(data) - pos)
+		case 1:
+			if pos+16 > len(data) {
+				return
+			}
+			size = binary.BigEndian.Uint64(data[pos+8:])
+			header = 16
+		}
+		if size < uint64(header) {
+			return
+		}
+		end := len(data)
+		if size <= uint64(len(data)-pos) {
+			end = pos + int(size)
+		}
+		if !fn(boxType, data[pos+header:end]) {
+			return
+		}
+		pos = end
+	}
+}
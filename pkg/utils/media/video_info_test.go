@@ -0,0 +1,98 @@
+package media
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+)
+
+func ebmlElement(id []byte, payload ...byte) []byte {
+	return append(append(id, 0x80|byte(len(payload))), payload...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+func mp4Box(boxType string, payload ...byte) []byte {
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+	return append(append(box, boxType...), payload...)
+}
+
+func TestProbeVideoInfo(t *testing.T) {
+	duration := binary.BigEndian.AppendUint32(nil, math.Float32bits(5000))
+	info := ebmlElement([]byte{0x15, 0x49, 0xA9, 0x66}, concat(
+		ebmlElement([]byte{0x2A, 0xD7, 0xB1}, 0x0F, 0x42, 0x40),
+		ebmlElement([]byte{0x44, 0x89}, duration...))...)
+	video := ebmlElement([]byte{0xE0}, concat(
+		ebmlElement([]byte{0xB0}, 0x07, 0x80),
+		ebmlElement([]byte{0xBA}, 0x04, 0x38))...)
+	tracks := ebmlElement([]byte{0x16, 0x54, 0xAE, 0x6B}, ebmlElement([]byte{0xAE}, video...)...)
+	mkv := ebmlElement([]byte{0x1A, 0x45, 0xDF, 0xA3}, ebmlElement([]byte{0x42, 0x82}, []byte("matroska")...)...)
+	// Segment 大小未知（直播/流式写入的文件常见）
+	segment := []byte{0x18, 0x53, 0x80, 0x67, 0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	mkv = concat(mkv, segment, info, tracks)
+
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:], 7_200_000)
+	videoTkhd := make([]byte, 84)
+	binary.BigEndian.PutUint32(videoTkhd[76:], 3840<<16)
+	binary.BigEndian.PutUint32(videoTkhd[80:], 2160<<16)
+	audioTkhd := make([]byte, 84)
+	moov := mp4Box("moov", concat(
+		mp4Box("mvhd", mvhd...),
+		mp4Box("trak", mp4Box("tkhd", audioTkhd...)...),
+		mp4Box("trak", mp4Box("tkhd", videoTkhd...)...))...)
+	ftyp := mp4Box("ftyp", []byte("isom\x00\x00\x02\x00")...)
+	mdat := mp4Box("mdat", make([]byte, 64)...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		want     VideoInfo
+		needTail bool
+	}{
+		{
+			name: "Matroska",
+			data: mkv,
+			want: VideoInfo{Duration: 5 * time.Second, Width: 1920, Height: 1080},
+		},
+		{
+			name: "MP4 moov 在开头",
+			data: concat(ftyp, moov, mdat),
+			want: VideoInfo{Duration: 2 * time.Hour, Width: 3840, Height: 2160},
+		},
+		{
+			name:     "MP4 moov 在末尾",
+			data:     concat(ftyp, mdat[:16]),
+			needTail: true,
+		},
+		{
+			name: "无法识别",
+			data: []byte("hello world"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProbeVideoInfo(tt.data); got != tt.want {
+				t.Errorf("ProbeVideoInfo() = %+v, want %+v", got, tt.want)
+			}
+			if got := NeedsMP4Tail(tt.data); got != tt.needTail {
+				t.Errorf("NeedsMP4Tail() = %v, want %v", got, tt.needTail)
+			}
+		})
+	}
+
+	tail := concat([]byte("..moov.."), mdat[20:], moov)
+	want := VideoInfo{Duration: 2 * time.Hour, Width: 3840, Height: 2160}
+	if got := ProbeMP4Tail(tail); got != want {
+		t.Errorf("ProbeMP4Tail() = %+v, want %+v", got, want)
+	}
+}
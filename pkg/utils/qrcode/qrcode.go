@@ -0,0 +1,42 @@
+package qrutil
+
+import (
+	"errors"
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// DefaultImageSize 默认二维码图片边长（像素）
+const DefaultImageSize = 512
+
+// MaxContentLength 二维码可编码的最大字节数（低纠错等级下的二进制容量）
+const MaxContentLength = 2953
+
+// ErrContentTooLong 内容超出二维码容量
+var ErrContentTooLong = errors.New("content too long to encode as QR code")
+
+// EncodePNG 将文本内容渲染为PNG格式的二维码图片
+// 短内容使用中等纠错等级，超长内容自动降级为低纠错等级以尽量容纳
+func EncodePNG(content string, size int) ([]byte, error) {
+	if content == "" {
+		return nil, fmt.Errorf("content is empty")
+	}
+	if len(content) > MaxContentLength {
+		return nil, ErrContentTooLong
+	}
+	if size <= 0 {
+		size = DefaultImageSize
+	}
+
+	level := qrcode.Medium
+	if len(content) > 1000 {
+		level = qrcode.Low
+	}
+
+	png, err := qrcode.Encode(content, level, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrContentTooLong, err)
+	}
+	return png, nil
+}
@@ -0,0 +1,31 @@
+package qrutil
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncodePNG(t *testing.T) {
+	png, err := EncodePNG("http://localhost:5244/d/movies/test.mkv?sign=abc", 256)
+	if err != nil {
+		t.Fatalf("EncodePNG() error = %v", err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("EncodePNG() did not return PNG data")
+	}
+}
+
+func TestEncodePNG_TooLong(t *testing.T) {
+	_, err := EncodePNG("http://example.com/"+strings.Repeat("a", MaxContentLength), 256)
+	if !errors.Is(err, ErrContentTooLong) {
+		t.Errorf("EncodePNG() error = %v, want ErrContentTooLong", err)
+	}
+}
+
+func TestEncodePNG_Empty(t *testing.T) {
+	if _, err := EncodePNG("", 256); err == nil {
+		t.Errorf("EncodePNG() expected error for empty content")
+	}
+}
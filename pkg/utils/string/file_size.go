@@ -0,0 +1,50 @@
+package strutil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// fileSizeUnits 文件大小单位（1K = 1024）
+var fileSizeUnits = map[byte]int64{
+	'K': 1 << 10,
+	'M': 1 << 20,
+	'G': 1 << 30,
+	'T': 1 << 40,
+}
+
+// ParseFileSize 解析文件大小（如 "500M"、"1.5G"、"800MB"，不区分大小写），返回字节数
+// 不带单位时按字节解析
+func ParseFileSize(s string) (int64, error) {
+	value := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	if value == "" {
+		return 0, fmt.Errorf("empty file size")
+	}
+
+	unit := int64(1)
+	if u, ok := fileSizeUnits[value[len(value)-1]]; ok {
+		unit = u
+		value = value[:len(value)-1]
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || !(number > 0) || math.IsInf(number, 1) || number*float64(unit) >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid file size %q: expected a positive number optionally followed by K, M, G or T", s)
+	}
+	return int64(number * float64(unit)), nil
+}
+
+// FormatSizeRange 格式化文件大小范围（0 表示不限），都不限时返回空字符串
+func FormatSizeRange(minSize, maxSize int64) string {
+	switch {
+	case minSize > 0 && maxSize > 0:
+		return FormatFileSize(minSize) + " - " + FormatFileSize(maxSize)
+	case minSize > 0:
+		return "≥ " + FormatFileSize(minSize)
+	case maxSize > 0:
+		return "≤ " + FormatFileSize(maxSize)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,38 @@
+package strutil
+
+import "testing"
+
+func TestParseFileSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{input: "500M", expected: 500 << 20},
+		{input: "5g", expected: 5 << 30},
+		{input: "1.5G", expected: 3 << 29},
+		{input: "800MB", expected: 800 << 20},
+		{input: "1T", expected: 1 << 40},
+		{input: "1024", expected: 1024},
+		{input: "0M", wantErr: true},
+		{input: "-1G", wantErr: true},
+		{input: "bigG", wantErr: true},
+		{input: "B", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFileSize(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseFileSize(%q) = %d, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil || got != tt.expected {
+				t.Errorf("ParseFileSize(%q) = %d, %v, want %d", tt.input, got, err, tt.expected)
+			}
+		})
+	}
+}
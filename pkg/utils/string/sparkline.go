@@ -0,0 +1,30 @@
+package strutil
+
+// sparkBlocks 迷你图字符，从低到高
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline 将数值序列渲染为文本迷你图
+// 按最大值等比缩放，0 渲染为最低档，空序列返回空字符串
+func Sparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	var max int64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	runes := make([]rune, len(values))
+	for i, v := range values {
+		if max <= 0 || v <= 0 {
+			runes[i] = sparkBlocks[0]
+			continue
+		}
+		idx := int(v * int64(len(sparkBlocks)-1) / max)
+		runes[i] = sparkBlocks[idx]
+	}
+	return string(runes)
+}
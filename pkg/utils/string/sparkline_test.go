@@ -0,0 +1,48 @@
+package strutil
+
+import (
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []int64
+		expected string
+	}{
+		{
+			name:     "空序列",
+			input:    nil,
+			expected: "",
+		},
+		{
+			name:     "全为0",
+			input:    []int64{0, 0, 0},
+			expected: "▁▁▁",
+		},
+		{
+			name:     "线性递增",
+			input:    []int64{0, 1, 2, 3, 4, 5, 6, 7},
+			expected: "▁▂▃▄▅▆▇█",
+		},
+		{
+			name:     "单个峰值",
+			input:    []int64{0, 100, 0},
+			expected: "▁█▁",
+		},
+		{
+			name:     "负值视为0",
+			input:    []int64{-5, 10},
+			expected: "▁█",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Sparkline(tt.input)
+			if result != tt.expected {
+				t.Errorf("Sparkline(%v) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package strutil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// speedLimitUnits 限速单位（1K = 1024）
+var speedLimitUnits = map[byte]int64{
+	'K': 1 << 10,
+	'M': 1 << 20,
+	'G': 1 << 30,
+}
+
+// ParseSpeedLimit 解析限速值（如 "500K"、"2M"、"1.5G"，不区分大小写），返回每秒字节数
+// 必须带 K/M/G 单位，避免 "500" 被误解为 500 B/s
+func ParseSpeedLimit(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	if value == "" {
+		return 0, fmt.Errorf("empty speed limit")
+	}
+
+	unit, ok := speedLimitUnits[value[len(value)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid speed limit %q: unit must be K, M or G", s)
+	}
+	number, err := strconv.ParseFloat(value[:len(value)-1], 64)
+	if err != nil || !(number > 0) || math.IsInf(number, 1) {
+		return 0, fmt.Errorf("invalid speed limit %q: expected a positive number followed by K, M or G", s)
+	}
+
+	bytes := int64(number * float64(unit))
+	if bytes <= 0 {
+		return 0, fmt.Errorf("invalid speed limit %q: too small", s)
+	}
+	return bytes, nil
+}
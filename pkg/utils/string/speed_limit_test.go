@@ -0,0 +1,37 @@
+package strutil
+
+import "testing"
+
+func TestParseSpeedLimit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{input: "500K", expected: 500 << 10},
+		{input: "2m", expected: 2 << 20},
+		{input: "1.5M", expected: 3 << 19},
+		{input: " 1G ", expected: 1 << 30},
+		{input: "500", wantErr: true},
+		{input: "2MB", wantErr: true},
+		{input: "0M", wantErr: true},
+		{input: "-1K", wantErr: true},
+		{input: "fastM", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSpeedLimit(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSpeedLimit(%q) = %d, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil || got != tt.expected {
+				t.Errorf("ParseSpeedLimit(%q) = %d, %v, want %d", tt.input, got, err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package strutil
+
+import (
+	"unicode/utf8"
+)
+
+// TruncateRunes 按字符截断字符串，超过 maxRunes 个字符时保留前 maxRunes 个并追加 ellipsis
+// 不会截断多字节字符（中文、emoji 等）
+func TruncateRunes(s string, maxRunes int, ellipsis string) string {
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max(maxRunes, 0)]) + ellipsis
+}
+
+// TruncateBytes 将字符串截断到不超过 maxBytes 字节，截断位置落在多字节字符中间时向前退到字符边界
+// 用于文件名、消息等有字节长度限制的场景
+func TruncateBytes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	if maxBytes <= 0 {
+		return ""
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -0,0 +1,58 @@
+package strutil
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxRunes int
+		expected string
+	}{
+		{name: "未超出", input: "庆余年.mkv", maxRunes: 7, expected: "庆余年.mkv"},
+		{name: "中文边界", input: "庆余年第二季.S02E01.mkv", maxRunes: 6, expected: "庆余年第二季..."},
+		{name: "emoji", input: "🎬🎬🎬🎬", maxRunes: 2, expected: "🎬🎬..."},
+		{name: "ASCII", input: "Friends.S01E01.mkv", maxRunes: 7, expected: "Friends..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TruncateRunes(tt.input, tt.maxRunes, "..."); got != tt.expected {
+				t.Errorf("TruncateRunes(%q, %d) = %q, want %q", tt.input, tt.maxRunes, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	// "庆余年" 每个字 3 字节
+	tests := []struct {
+		name     string
+		input    string
+		maxBytes int
+		expected string
+	}{
+		{name: "未超出", input: "庆余年", maxBytes: 9, expected: "庆余年"},
+		{name: "字符边界", input: "庆余年", maxBytes: 6, expected: "庆余"},
+		{name: "字符中间向前退", input: "庆余年", maxBytes: 7, expected: "庆余"},
+		{name: "字符中间向前退2", input: "庆余年", maxBytes: 8, expected: "庆余"},
+		{name: "不足一个字符", input: "庆余年", maxBytes: 2, expected: ""},
+		{name: "混合", input: "S01庆余年", maxBytes: 5, expected: "S01"},
+		{name: "零", input: "abc", maxBytes: 0, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateBytes(tt.input, tt.maxBytes)
+			if got != tt.expected {
+				t.Errorf("TruncateBytes(%q, %d) = %q, want %q", tt.input, tt.maxBytes, got, tt.expected)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("TruncateBytes(%q, %d) produced invalid UTF-8", tt.input, tt.maxBytes)
+			}
+		})
+	}
+}
@@ -206,10 +206,17 @@ func CreateWeekRange() TimeRange {
 	if weekday == 0 {
 		weekday = 7 // 将周日调整为7
 	}
-	startOfWeek := now.AddDate(0, 0, -weekday+1).Truncate(24 * time.Hour)
+	startOfWeek := time.Date(now.Year(), now.Month(), now.Day()-weekday+1, 0, 0, 0, 0, now.Location())
 	return TimeRange{Start: startOfWeek, End: now}
 }
 
+// CreateMonthRange 创建本月的时间范围
+func CreateMonthRange() TimeRange {
+	now := time.Now()
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return TimeRange{Start: startOfMonth, End: now}
+}
+
 // FormatDuration 格式化持续时间为可读字符串
 func FormatDuration(d time.Duration) string {
 	if d < time.Minute {